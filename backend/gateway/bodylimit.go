@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ============ 请求体上限 ============
+//
+// 网关不缓存请求体：代理直接把客户端的请求体流式转发给后端服务，上传文件不会整体读入内存。
+// 请求体上限按路由前缀配置（gateway.body_limits），未匹配的路由使用服务器默认上限（SERVER_MAX_BODY_SIZE）；
+// 声明的 Content-Length 超限时在读取前直接返回 413，未声明长度（chunked）的请求在转发过程中超限时中断并返回 413。
+
+// BodyLimitRule 路由的请求体上限
+type BodyLimitRule struct {
+	Path        string `mapstructure:"path"`         // 服务路由前缀（不含 /api/<版本>），如 /data/sync/import
+	MaxBytes    int64  `mapstructure:"max_bytes"`    // 请求体上限（字节），0 表示不限制
+	ReadTimeout int    `mapstructure:"read_timeout"` // 读取请求体的时限（秒），0 沿用服务器读超时；整个请求仍受服务接口超时限制
+}
+
+// defaultBodyLimitRules 内置的上传接口上限，可在配置中按相同 path 覆盖
+var defaultBodyLimitRules = []BodyLimitRule{
+	{Path: "/data/sync/import", MaxBytes: 64 << 20, ReadTimeout: 60}, // 批量导入K线（与数据服务的导入上限一致）
+}
+
+// bodyLimits 按路由前缀匹配请求体上限，最长前缀优先
+type bodyLimits struct {
+	defaultMax int64
+	rules      []BodyLimitRule
+}
+
+// loadBodyLimits 合并内置规则与配置 gateway.body_limits，defaultMax 为未匹配路由的上限
+func loadBodyLimits(defaultMax int64, logger *zap.Logger) *bodyLimits {
+	var configured []BodyLimitRule
+	if err := viper.UnmarshalKey("gateway.body_limits", &configured); err != nil {
+		logger.Warn("请求体上限配置无效，使用内置规则", zap.Error(err))
+	}
+	return newBodyLimits(defaultMax, append(append([]BodyLimitRule{}, defaultBodyLimitRules...), configured...))
+}
+
+// newBodyLimits 创建请求体上限，相同 path 的规则以后出现的为准
+func newBodyLimits(defaultMax int64, rules []BodyLimitRule) *bodyLimits {
+	byPath := make(map[string]BodyLimitRule, len(rules))
+	for _, rule := range rules {
+		rule.Path = "/" + strings.Trim(rule.Path, "/")
+		byPath[rule.Path] = rule
+	}
+	l := &bodyLimits{defaultMax: defaultMax}
+	for _, rule := range byPath {
+		l.rules = append(l.rules, rule)
+	}
+	sort.Slice(l.rules, func(i, j int) bool { return len(l.rules[i].Path) > len(l.rules[j].Path) })
+	return l
+}
+
+// match 返回路由（不含 /api/<版本>）适用的规则，前缀按路径段匹配
+func (l *bodyLimits) match(route string) BodyLimitRule {
+	for _, rule := range l.rules {
+		if route == rule.Path || strings.HasPrefix(route, rule.Path+"/") {
+			return rule
+		}
+	}
+	return BodyLimitRule{Path: "/", MaxBytes: l.defaultMax}
+}
+
+// RouteBodyLimit 按路由限制请求体大小的中间件，需注册在版本中间件之后（路径已改写为后端版本）
+func RouteBodyLimit(limits *bodyLimits) gin.HandlerFunc {
+	prefix := "/api/" + backendVersion
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		rule := limits.match(strings.TrimPrefix(c.Request.URL.Path, prefix))
+		if rule.MaxBytes > 0 {
+			if c.Request.ContentLength > rule.MaxBytes {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"code": 413, "msg": "请求体过大"})
+				return
+			}
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, rule.MaxBytes)
+		}
+		if rule.ReadTimeout > 0 {
+			http.NewResponseController(c.Writer).SetReadDeadline(time.Now().Add(time.Duration(rule.ReadTimeout) * time.Second))
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestBodyLimitsMatch(t *testing.T) {
+	limits := newBodyLimits(100, []BodyLimitRule{
+		{Path: "/data/sync/import", MaxBytes: 1000},
+		{Path: "data/sync/import/", MaxBytes: 2000}, // 相同 path 以后出现的为准
+		{Path: "/data", MaxBytes: 500},
+	})
+	tests := map[string]int64{
+		"/data/sync/import":      2000,
+		"/data/sync/import/bars": 2000,
+		"/data/sync/imports":     500,
+		"/data/sync/stocks":      500,
+		"/market/stocks":         100,
+	}
+	for route, want := range tests {
+		if got := limits.match(route).MaxBytes; got != want {
+			t.Errorf("%s: max = %d, want %d", route, got, want)
+		}
+	}
+}
+
+func TestRouteBodyLimitStreaming(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var received int
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		received = len(data)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer backend.Close()
+
+	gateway := NewAPIGateway()
+	gateway.logger = zap.NewNop()
+	gateway.services["data"] = &ServiceConfig{Name: "data-service", URL: backend.URL}
+	limits := newBodyLimits(8, []BodyLimitRule{{Path: "/data/sync/import", MaxBytes: 64}})
+
+	r := gin.New()
+	r.Group("/api/v1", RouteBodyLimit(limits)).Any("/data/*path", func(c *gin.Context) {
+		gateway.GetServiceProxy("data").ServeHTTP(c.Writer, c.Request)
+	})
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	post := func(path string, size int, chunked bool) int {
+		var body io.Reader = strings.NewReader(strings.Repeat("x", size))
+		if chunked {
+			body = io.MultiReader(body) // 隐藏长度，按 chunked 发送
+		}
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+path, body)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/api/v1/data/sync/import/bars", 50, true); code != http.StatusOK || received != 50 {
+		t.Errorf("上传接口: status = %d, received = %d", code, received)
+	}
+	if code := post("/api/v1/data/sync/import/bars", 100, false); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Content-Length 超限: status = %d", code)
+	}
+	if code := post("/api/v1/data/sync/import/bars", 100, true); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("chunked 超限: status = %d", code)
+	}
+	if code := post("/api/v1/data/sync/stocks", 50, false); code != http.StatusRequestEntityTooLarge {
+		t.Errorf("默认上限: status = %d", code)
+	}
+}
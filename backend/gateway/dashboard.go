@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"stock-analysis-system/backend/pkg/requestid"
+)
+
+// ============ 首页聚合接口 ============
+
+const (
+	dashboardTimeout     = 8 * time.Second // 聚合接口整体超时，单个服务超时只影响对应板块
+	dashboardMaxQuotes   = 50              // 自选股行情最多查询的股票数
+	dashboardQuoteFanout = 8               // 并发查询行情的数量
+	dashboardSignals     = 10              // 最近交易信号条数
+	dashboardBacktests   = 5               // 最近回测条数
+)
+
+// serviceEnvelope 后端服务的统一响应结构
+type serviceEnvelope struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// dashboardWatchlist 自选股分组（只解析查询行情需要的字段）
+type dashboardWatchlist struct {
+	ID    uint   `json:"id"`
+	Name  string `json:"name"`
+	Items []struct {
+		Symbol   string `json:"symbol"`
+		Exchange string `json:"exchange"`
+	} `json:"items"`
+}
+
+// Dashboard 首页聚合接口：并发查询自选股及其行情、最近交易信号、最近回测，合并为一次响应
+// 某个服务失败时对应板块为空并在 errors 中说明原因，其余板块照常返回。
+func (g *APIGateway) Dashboard(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dashboardTimeout)
+	defer cancel()
+	auth := c.GetHeader("Authorization")
+
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		data   = gin.H{}
+		errs   = map[string]string{}
+		status = http.StatusOK
+	)
+	section := func(name string, fetch func() (interface{}, error)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := fetch()
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				var se *serviceError
+				if errors.As(err, &se) && se.status == http.StatusUnauthorized {
+					status = http.StatusUnauthorized
+				}
+				errs[name] = err.Error()
+				g.logger.Warn("首页聚合查询失败", zap.String("section", name), zap.Error(err))
+				return
+			}
+			data[name] = value
+		}()
+	}
+
+	section("watchlists", func() (interface{}, error) {
+		return g.dashboardWatchlists(ctx, auth)
+	})
+	section("signals", func() (interface{}, error) {
+		query := url.Values{"page_size": {fmt.Sprint(dashboardSignals)}, "status": {"active"}}
+		return g.fetchService(ctx, "strategy", "/api/v1/signals?"+query.Encode(), auth)
+	})
+	section("backtests", func() (interface{}, error) {
+		query := url.Values{"page_size": {fmt.Sprint(dashboardBacktests)}}
+		return g.fetchService(ctx, "backtest", "/api/v1/backtest?"+query.Encode(), auth)
+	})
+	wg.Wait()
+
+	// 所有服务都拒绝了认证信息时直接返回 401，便于前端跳转登录
+	if status == http.StatusUnauthorized && len(data) == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "未登录或登录已过期"})
+		return
+	}
+
+	data["errors"] = errs
+	data["generated_at"] = time.Now()
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": data,
+	})
+}
+
+// dashboardWatchlists 查询自选股分组并并发补充各股票的实时行情
+func (g *APIGateway) dashboardWatchlists(ctx context.Context, auth string) (interface{}, error) {
+	raw, err := g.fetchService(ctx, "user", "/api/v1/watchlist", auth)
+	if err != nil {
+		return nil, err
+	}
+	var watchlists []dashboardWatchlist
+	if err := json.Unmarshal(raw, &watchlists); err != nil {
+		return nil, fmt.Errorf("解析自选股失败: %w", err)
+	}
+
+	// 多个分组中的同一股票只查询一次
+	type stockCode struct{ symbol, exchange string }
+	var codes []stockCode
+	seen := make(map[stockCode]bool)
+	for _, w := range watchlists {
+		for _, item := range w.Items {
+			code := stockCode{item.Symbol, item.Exchange}
+			if !seen[code] && len(codes) < dashboardMaxQuotes {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	quotes := make(map[string]json.RawMessage, len(codes))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, dashboardQuoteFanout)
+	for _, code := range codes {
+		wg.Add(1)
+		go func(code stockCode) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			path := "/api/v1/market/quote/" + url.PathEscape(code.symbol) + "?exchange=" + url.QueryEscape(code.exchange)
+			quote, err := g.fetchService(ctx, "market", path, auth)
+			if err != nil {
+				return // 单只股票行情缺失不影响整体
+			}
+			mu.Lock()
+			quotes[code.symbol+"."+code.exchange] = quote
+			mu.Unlock()
+		}(code)
+	}
+	wg.Wait()
+
+	return gin.H{
+		"list":   raw,
+		"quotes": quotes,
+	}, nil
+}
+
+// serviceError 后端服务返回的业务错误
+type serviceError struct {
+	service string
+	status  int
+	msg     string
+}
+
+func (e *serviceError) Error() string {
+	return fmt.Sprintf("%s: %s", e.service, e.msg)
+}
+
+// fetchService 以调用方的认证信息请求后端服务，返回统一响应中的 data
+func (g *APIGateway) fetchService(ctx context.Context, serviceName, path, auth string) (json.RawMessage, error) {
+	service, exists := g.services[serviceName]
+	if !exists {
+		return nil, &serviceError{service: serviceName, status: http.StatusServiceUnavailable, msg: "服务不可用"}
+	}
+
+	baseURL := service.URL
+	up := service.pick()
+	if up != nil {
+		baseURL = up.target.String()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	g.signer.Sign(req)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			up.record(true)
+		}
+		return nil, &serviceError{service: service.Name, status: http.StatusServiceUnavailable, msg: "服务暂时不可用"}
+	}
+	defer resp.Body.Close()
+	up.record(resp.StatusCode >= http.StatusInternalServerError)
+
+	var envelope serviceEnvelope
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, &serviceError{service: service.Name, status: resp.StatusCode, msg: "响应格式错误"}
+	}
+	if resp.StatusCode != http.StatusOK || envelope.Code != 0 {
+		msg := envelope.Msg
+		if msg == "" {
+			msg = http.StatusText(resp.StatusCode)
+		}
+		return nil, &serviceError{service: service.Name, status: resp.StatusCode, msg: msg}
+	}
+	return envelope.Data, nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestDashboard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":401,"msg":"未登录"}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/watchlist":
+			w.Write([]byte(`{"code":0,"data":[{"id":1,"name":"a","items":[{"symbol":"600519","exchange":"SH"}]},{"id":2,"items":[{"symbol":"600519","exchange":"SH"}]}]}`))
+		case "/api/v1/market/quote/600519":
+			w.Write([]byte(`{"code":0,"data":{"price":1700}}`))
+		case "/api/v1/signals":
+			w.Write([]byte(`{"code":0,"data":{"list":[],"total":0}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"code":500,"msg":"查询失败"}`))
+		}
+	}))
+	defer backend.Close()
+
+	g := NewAPIGateway()
+	g.logger = zap.NewNop()
+	for _, name := range []string{"user", "market", "strategy", "backtest"} {
+		g.services[name] = &ServiceConfig{Name: name + "-service", URL: backend.URL}
+	}
+	r := gin.New()
+	r.GET("/api/v1/dashboard", g.Dashboard)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil)
+	req.Header.Set("Authorization", "Bearer t")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp struct {
+		Data struct {
+			Watchlists struct {
+				Quotes map[string]json.RawMessage `json:"quotes"`
+			} `json:"watchlists"`
+			Signals json.RawMessage   `json:"signals"`
+			Errors  map[string]string `json:"errors"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data.Watchlists.Quotes) != 1 || resp.Data.Signals == nil {
+		t.Errorf("unexpected dashboard: %s", w.Body.String())
+	}
+	if resp.Data.Errors["backtests"] == "" {
+		t.Errorf("backtests error not reported: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/dashboard", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without token = %d, want 401", w.Code)
+	}
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/middleware"
+)
+
+// dataRoutes 经网关 /data 路由组开放的数据同步服务接口，其余路径返回 404
+var dataRoutes = []string{"/sync", "/snapshots", "/freshness"}
+
+// dataPath 将 /data 路由组内的路径映射为数据同步服务的接口路径，如 /sync/stocks -> /api/v1/sync/stocks
+func dataPath(p string) (string, bool) {
+	p = path.Clean("/" + p)
+	for _, prefix := range dataRoutes {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return "/api/" + backendVersion + p, true
+		}
+	}
+	return "", false
+}
+
+// proxyData 代理数据同步接口：未携带 Token 或 API Key 的请求直接返回 401，
+// 角色与 API Key 由数据同步服务校验（需 admin 角色或 DATA_API_KEYS 中的 Key）
+func (g *APIGateway) proxyData(c *gin.Context) {
+	target, ok := dataPath(c.Param("path"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "接口不存在"})
+		return
+	}
+	if c.GetHeader("Authorization") == "" && c.GetHeader(middleware.APIKeyHeader) == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "缺少认证信息"})
+		return
+	}
+	proxy := g.GetServiceProxy("data")
+	if proxy == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+		return
+	}
+	c.Request.URL.Path = target
+	c.Request.URL.RawPath = ""
+	proxy.ServeHTTP(c.Writer, c.Request)
+}
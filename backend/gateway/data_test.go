@@ -0,0 +1,74 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestDataPath(t *testing.T) {
+	tests := map[string]string{
+		"/sync/stocks":         "/api/v1/sync/stocks",
+		"/snapshots":           "/api/v1/snapshots",
+		"/snapshots/3/files/a": "/api/v1/snapshots/3/files/a",
+		"/freshness":           "/api/v1/freshness",
+		"/sync/../admin/bars":  "",
+		"/syncs":               "",
+		"/admin/sync/jobs":     "",
+		"/":                    "",
+	}
+	for p, want := range tests {
+		got, ok := dataPath(p)
+		if got != want || ok != (want != "") {
+			t.Errorf("dataPath(%q) = %q, %v, want %q", p, got, ok, want)
+		}
+	}
+}
+
+func TestProxyData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var received string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.URL.Path
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer backend.Close()
+
+	gateway := NewAPIGateway()
+	gateway.logger = zap.NewNop()
+	gateway.services["data"] = &ServiceConfig{Name: "data-service", URL: backend.URL}
+	r := gin.New()
+	r.Group("/api/v1").Any("/data/*path", gateway.proxyData)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	post := func(path string, header string) int {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+path, nil)
+		if header != "" {
+			req.Header.Set(header, "x")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if code := post("/api/v1/data/sync/stocks", ""); code != http.StatusUnauthorized || received != "" {
+		t.Errorf("未携带认证信息: status = %d, received = %q", code, received)
+	}
+	if code := post("/api/v1/data/sync/stocks", "X-API-Key"); code != http.StatusOK || received != "/api/v1/sync/stocks" {
+		t.Errorf("API Key: status = %d, received = %q", code, received)
+	}
+	if code := post("/api/v1/data/snapshots", "Authorization"); code != http.StatusOK || received != "/api/v1/snapshots" {
+		t.Errorf("Token: status = %d, received = %q", code, received)
+	}
+	if code := post("/api/v1/data/admin/bars/delete", "Authorization"); code != http.StatusNotFound {
+		t.Errorf("未开放的路径: status = %d", code)
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 修改 api/openapi 下的规范片段后执行 go generate ./gateway 重新生成合并后的规范
+//go:generate go run ../tools/openapi-merge -in ../api/openapi -out docs/openapi.json
+
+//go:embed docs/openapi.json
+var openAPISpec []byte
+
+// swaggerUIPage Swagger UI 页面（静态资源来自 CDN）
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+  <meta charset="utf-8">
+  <title>股票分析系统 API 文档</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({
+      url: "/api/docs/openapi.json",
+      dom_id: "#swagger-ui",
+      persistAuthorization: true
+    });
+  </script>
+</body>
+</html>`
+
+// registerDocs 注册 API 文档路由
+func registerDocs(r *gin.Engine) {
+	r.GET("/api/docs", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	})
+	r.GET("/api/docs/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", openAPISpec)
+	})
+}
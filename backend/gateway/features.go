@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/features"
+)
+
+// ============ 维护模式与功能开关 ============
+
+// newFeatures 创建维护模式与功能开关，返回关闭函数（停止读取 Redis 并断开连接）
+// 配置了 Redis 时定期读取其中的 features 键；连接失败时只记录日志，使用配置文件中的 features。
+func newFeatures(cfg *config.Config, live *config.Live, logger *zap.Logger) (*features.Store, func(context.Context)) {
+	var client *database.RedisClient
+	if cfg.Database.Redis.Host != "" {
+		var err error
+		if client, err = database.NewRedisClient(&cfg.Database.Redis); err != nil {
+			logger.Warn("连接 Redis 失败，功能开关只使用配置文件", zap.Error(err))
+		}
+	}
+
+	store := features.New(live, client.GetClient())
+	ctx, cancel := context.WithCancel(context.Background())
+	go store.Run(ctx, features.DefaultPollInterval)
+
+	return store, func(context.Context) {
+		cancel()
+		if client != nil {
+			client.Close()
+		}
+	}
+}
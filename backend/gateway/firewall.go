@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"stock-analysis-system/backend/pkg/metrics"
+)
+
+// ============ 访问控制与基础 WAF 规则 ============
+//
+// 在网关中间件链最前面执行：IP 白名单/黑名单（CIDR）、路径穿越与超长查询参数拦截、按路由限制请求方法。
+// 客户端 IP 取 gin 的 ClientIP，只采信 firewall.trusted_proxies（网关前代理，如 nginx）转发的 X-Forwarded-For；
+// 启用了黑白名单而未配置可信代理时不信任任何代理、以连接的对端地址判断，避免客户端伪造来源 IP 绕过名单。
+
+// 拦截原因，作为 gateway_firewall_blocked_total 的 reason 标签
+const (
+	blockDenied           = "ip_denied"      // 命中黑名单
+	blockNotAllowed       = "ip_not_allowed" // 配置了白名单且未命中
+	blockTraversal        = "path_traversal"
+	blockQueryTooLong     = "query_too_long"
+	blockMethodNotAllowed = "method_not_allowed"
+)
+
+// defaultMaxQueryLength 默认的查询参数长度上限（字节）
+const defaultMaxQueryLength = 4096
+
+// FirewallConfig 网关访问控制配置（firewall.*）
+type FirewallConfig struct {
+	Allow          []string     `mapstructure:"allow"`            // IP 或 CIDR，非空时只允许列表中的地址
+	Deny           []string     `mapstructure:"deny"`             // IP 或 CIDR，优先于白名单
+	MaxQueryLength int          `mapstructure:"max_query_length"` // 查询参数上限（字节），负数表示不限制
+	TrustedProxies []string     `mapstructure:"trusted_proxies"`  // 网关前代理的 IP 或 CIDR，只采信其转发的 X-Forwarded-For
+	Methods        []MethodRule `mapstructure:"methods"`
+}
+
+// MethodRule 路由允许的请求方法
+type MethodRule struct {
+	Path    string   `mapstructure:"path"`    // 路径前缀，按路径段匹配，如 /api/v1/admin
+	Methods []string `mapstructure:"methods"` // 允许的方法，OPTIONS（跨域预检）总是允许
+}
+
+// firewall 访问控制规则与拦截计数
+type firewall struct {
+	allow          []netip.Prefix
+	deny           []netip.Prefix
+	maxQueryLength int
+	trusted        []string
+	methods        []MethodRule // 按路径长度倒序，最长前缀优先
+
+	mu      sync.Mutex
+	blocked map[string]int64 // 拦截原因 -> 次数
+}
+
+// loadFirewall 读取配置 firewall.* 创建访问控制
+func loadFirewall() (*firewall, error) {
+	var cfg FirewallConfig
+	if err := viper.UnmarshalKey("firewall", &cfg); err != nil {
+		return nil, err
+	}
+	return newFirewall(cfg)
+}
+
+// newFirewall 解析规则并注册拦截计数指标
+func newFirewall(cfg FirewallConfig) (*firewall, error) {
+	f := &firewall{maxQueryLength: cfg.MaxQueryLength, trusted: cfg.TrustedProxies, blocked: make(map[string]int64)}
+	var err error
+	if f.allow, err = parsePrefixes(cfg.Allow); err != nil {
+		return nil, err
+	}
+	if f.deny, err = parsePrefixes(cfg.Deny); err != nil {
+		return nil, err
+	}
+	for _, rule := range cfg.Methods {
+		rule.Path = "/" + strings.Trim(rule.Path, "/")
+		for i, m := range rule.Methods {
+			rule.Methods[i] = strings.ToUpper(m)
+		}
+		f.methods = append(f.methods, rule)
+	}
+	sort.SliceStable(f.methods, func(i, j int) bool { return len(f.methods[i].Path) > len(f.methods[j].Path) })
+
+	metrics.Register("gateway_firewall", f.collectMetrics)
+	return f, nil
+}
+
+// trustedProxies 网关应信任的代理地址，ok 为 false 时保持 gin 的默认设置（信任任意来源）
+// 配置了黑白名单而没有配置可信代理时返回空列表：不采信 X-Forwarded-For，否则客户端可伪造来源 IP 绕过名单。
+func (f *firewall) trustedProxies() (proxies []string, ok bool) {
+	if len(f.trusted) > 0 {
+		return f.trusted, true
+	}
+	if len(f.allow) > 0 || len(f.deny) > 0 {
+		return nil, true
+	}
+	return nil, false
+}
+
+// parsePrefixes 解析 IP 或 CIDR 列表，单个 IP 视为完整前缀
+func parsePrefixes(items []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, fmt.Errorf("IP %q 格式错误: %w", item, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, fmt.Errorf("CIDR %q 格式错误: %w", item, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Middleware 访问控制中间件
+func (f *firewall) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if reason := f.checkIP(c.ClientIP()); reason != "" {
+			f.block(c, reason, http.StatusForbidden, "访问被拒绝")
+			return
+		}
+		if hasTraversal(c.Request) {
+			f.block(c, blockTraversal, http.StatusBadRequest, "请求路径非法")
+			return
+		}
+		if f.maxQueryLength >= 0 && len(c.Request.URL.RawQuery) > f.maxQueryLength {
+			f.block(c, blockQueryTooLong, http.StatusRequestURITooLong, "查询参数过长")
+			return
+		}
+		if allowed := f.allowedMethods(c.Request.URL.Path); allowed != nil && c.Request.Method != http.MethodOptions && !methodIn(allowed, c.Request.Method) {
+			c.Header("Allow", strings.Join(allowed, ", "))
+			f.block(c, blockMethodNotAllowed, http.StatusMethodNotAllowed, "请求方法不允许")
+			return
+		}
+		c.Next()
+	}
+}
+
+// checkIP 返回拦截原因，允许时返回空
+func (f *firewall) checkIP(ip string) string {
+	if len(f.allow) == 0 && len(f.deny) == 0 {
+		return ""
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return blockNotAllowed
+	}
+	addr = addr.Unmap() // ::ffff:1.2.3.4 按 IPv4 匹配
+	for _, p := range f.deny {
+		if p.Contains(addr) {
+			return blockDenied
+		}
+	}
+	if len(f.allow) == 0 {
+		return ""
+	}
+	for _, p := range f.allow {
+		if p.Contains(addr) {
+			return ""
+		}
+	}
+	return blockNotAllowed
+}
+
+// allowedMethods 路径匹配的方法限制，没有限制时返回 nil
+func (f *firewall) allowedMethods(path string) []string {
+	for _, rule := range f.methods {
+		if path == rule.Path || strings.HasPrefix(path, rule.Path+"/") || rule.Path == "/" {
+			return rule.Methods
+		}
+	}
+	return nil
+}
+
+// hasTraversal 路径中含 .. 段、编码的 ..（含二次编码）、反斜杠或空字节
+func hasTraversal(r *http.Request) bool {
+	for _, seg := range strings.Split(r.URL.Path, "/") {
+		if seg == ".." {
+			return true
+		}
+	}
+	raw := strings.ToLower(r.URL.EscapedPath())
+	for _, pattern := range []string{"%2e%2e", ".%2e", "%2e.", "..%2f", "%5c", "\\", "%00", "%252e"} {
+		if strings.Contains(raw, pattern) {
+			return true
+		}
+	}
+	return strings.Contains(r.URL.RawQuery, "%00")
+}
+
+// block 拦截请求并计数
+func (f *firewall) block(c *gin.Context, reason string, status int, msg string) {
+	f.mu.Lock()
+	f.blocked[reason]++
+	f.mu.Unlock()
+	c.AbortWithStatusJSON(status, gin.H{"code": status, "msg": msg})
+}
+
+func (f *firewall) collectMetrics() []metrics.Sample {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	samples := make([]metrics.Sample, 0, len(f.blocked))
+	for reason, n := range f.blocked {
+		samples = append(samples, metrics.Sample{
+			Name:   "gateway_firewall_blocked_total",
+			Help:   "网关访问控制拦截的请求数",
+			Type:   metrics.TypeCounter,
+			Labels: map[string]string{"reason": reason},
+			Value:  float64(n),
+		})
+	}
+	return samples
+}
+
+func methodIn(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFirewall(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	fw, err := newFirewall(FirewallConfig{
+		Allow:          []string{"10.0.0.0/8", "192.168.1.5"},
+		Deny:           []string{"10.1.0.0/16"},
+		MaxQueryLength: 32,
+		Methods: []MethodRule{
+			{Path: "/api/v1/admin", Methods: []string{"get"}},
+			{Path: "/api/v1/admin/jobs", Methods: []string{"GET", "POST"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := gin.New()
+	r.Use(fw.Middleware())
+	r.Any("/*path", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		ip     string
+		status int
+	}{
+		{"白名单", http.MethodGet, "/api/v1/stocks", "10.2.3.4", http.StatusOK},
+		{"单个 IP", http.MethodGet, "/api/v1/stocks", "192.168.1.5", http.StatusOK},
+		{"IPv4 映射地址", http.MethodGet, "/api/v1/stocks", "::ffff:10.2.3.4", http.StatusOK},
+		{"不在白名单", http.MethodGet, "/api/v1/stocks", "192.168.1.6", http.StatusForbidden},
+		{"黑名单优先", http.MethodGet, "/api/v1/stocks", "10.1.2.3", http.StatusForbidden},
+		{"路径穿越", http.MethodGet, "/api/v1/../etc/passwd", "10.2.3.4", http.StatusBadRequest},
+		{"编码的路径穿越", http.MethodGet, "/api/v1/%2e%2e/etc/passwd", "10.2.3.4", http.StatusBadRequest},
+		{"二次编码", http.MethodGet, "/api/v1/%252e%252e/etc", "10.2.3.4", http.StatusBadRequest},
+		{"空字节", http.MethodGet, "/api/v1/stocks?symbol=A%00", "10.2.3.4", http.StatusBadRequest},
+		{"查询参数过长", http.MethodGet, "/api/v1/stocks?symbol=" + strings.Repeat("A", 32), "10.2.3.4", http.StatusRequestURITooLong},
+		{"方法限制", http.MethodDelete, "/api/v1/admin/users/1", "10.2.3.4", http.StatusMethodNotAllowed},
+		{"允许的方法", http.MethodGet, "/api/v1/admin/users/1", "10.2.3.4", http.StatusOK},
+		{"最长前缀优先", http.MethodPost, "/api/v1/admin/jobs/run", "10.2.3.4", http.StatusOK},
+		{"按路径段匹配", http.MethodDelete, "/api/v1/administrators", "10.2.3.4", http.StatusOK},
+		{"跨域预检", http.MethodOptions, "/api/v1/admin/users", "10.2.3.4", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		req.Header.Set("X-Forwarded-For", tt.ip)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+
+	counts := map[string]float64{}
+	for _, s := range fw.collectMetrics() {
+		counts[s.Labels["reason"]] = s.Value
+	}
+	want := map[string]float64{
+		blockNotAllowed: 1, blockDenied: 1, blockTraversal: 4, blockQueryTooLong: 1, blockMethodNotAllowed: 1,
+	}
+	for reason, n := range want {
+		if counts[reason] != n {
+			t.Errorf("blocked[%s] = %v, want %v", reason, counts[reason], n)
+		}
+	}
+}
+
+func TestParsePrefixesInvalid(t *testing.T) {
+	for _, item := range []string{"10.0.0", "10.0.0.0/33", "example.com"} {
+		if _, err := parsePrefixes([]string{item}); err == nil {
+			t.Errorf("parsePrefixes(%q) 应返回错误", item)
+		}
+	}
+}
+
+// 配置了黑白名单而没有配置可信代理时不采信 X-Forwarded-For，按连接地址判断
+func TestFirewallTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		cfg     FirewallConfig
+		remote  string
+		status  int
+		trusted bool
+	}{
+		{"未配置可信代理时不能伪造来源", FirewallConfig{Allow: []string{"10.0.0.0/8"}}, "203.0.113.7:40000", http.StatusForbidden, true},
+		{"未配置可信代理时按连接地址放行", FirewallConfig{Deny: []string{"10.1.0.0/16"}}, "203.0.113.7:40000", http.StatusOK, true},
+		{"可信代理转发的来源", FirewallConfig{Allow: []string{"10.0.0.0/8"}, TrustedProxies: []string{"172.16.0.0/12"}}, "172.16.0.2:40000", http.StatusOK, true},
+		{"非可信代理转发的来源", FirewallConfig{Allow: []string{"10.0.0.0/8"}, TrustedProxies: []string{"172.16.0.0/12"}}, "203.0.113.7:40000", http.StatusForbidden, true},
+		{"没有名单时保持默认", FirewallConfig{}, "203.0.113.7:40000", http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		fw, err := newFirewall(tt.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := gin.New()
+		proxies, ok := fw.trustedProxies()
+		if ok != tt.trusted {
+			t.Fatalf("%s: trustedProxies ok = %v", tt.name, ok)
+		}
+		if ok {
+			if err := r.SetTrustedProxies(proxies); err != nil {
+				t.Fatal(err)
+			}
+		}
+		r.Use(fw.Middleware())
+		r.GET("/*path", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/stocks", nil)
+		req.RemoteAddr = tt.remote
+		req.Header.Set("X-Forwarded-For", "10.2.3.4")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.status)
+		}
+	}
+}
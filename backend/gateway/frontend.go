@@ -0,0 +1,118 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// ============ 前端静态资源 ============
+//
+// 小规模部署可由网关直接提供编译后的前端（frontend/web 的 npm run build 产物），无需单独部署 nginx：
+//   - frontend.dir 指定产物目录时从目录读取；
+//   - 未指定时使用编译进网关的资源（构建前将产物复制到 gateway/web）。
+// 未匹配到文件的页面路由返回 index.html（SPA history 模式），/api 等后端路径不做回退。
+
+//go:embed all:web
+var embeddedWeb embed.FS
+
+// 缓存策略：构建产物 assets 下的文件名带内容哈希，可长期缓存；index.html 每次校验以便发布后立即生效
+const (
+	cacheImmutable = "public, max-age=31536000, immutable"
+	cacheStatic    = "public, max-age=3600"
+	cacheNoCache   = "no-cache"
+)
+
+// frontendReserved 不做 SPA 回退的路径前缀
+var frontendReserved = []string{"/api", "/health", "/metrics"}
+
+// loadFrontend 按配置加载前端资源，未启用或没有可用产物时返回 nil
+func loadFrontend(logger *zap.Logger) fs.FS {
+	if !viper.GetBool("frontend.enabled") {
+		return nil
+	}
+
+	var fsys fs.FS
+	if dir := viper.GetString("frontend.dir"); dir != "" {
+		fsys = os.DirFS(dir)
+	} else {
+		fsys, _ = fs.Sub(embeddedWeb, "web")
+	}
+	if _, err := fs.Stat(fsys, "index.html"); err != nil {
+		logger.Warn("前端资源缺少 index.html，不提供前端页面", zap.String("dir", viper.GetString("frontend.dir")), zap.Error(err))
+		return nil
+	}
+	return fsys
+}
+
+// registerFrontend 注册前端静态资源与 SPA 回退，作为未匹配路由的处理器
+func registerFrontend(r *gin.Engine, fsys fs.FS) {
+	files := http.FileServer(http.FS(fsys))
+	r.NoRoute(func(c *gin.Context) {
+		p := c.Request.URL.Path
+		if (c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead) || isReserved(p) {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "接口不存在"})
+			return
+		}
+
+		name := strings.TrimPrefix(path.Clean(p), "/")
+		if name != "" && !hiddenPath(name) {
+			if info, err := fs.Stat(fsys, name); err == nil && !info.IsDir() {
+				if name == "index.html" {
+					serveIndex(c, files)
+					return
+				}
+				if strings.HasPrefix(name, "assets/") {
+					c.Header("Cache-Control", cacheImmutable)
+				} else {
+					c.Header("Cache-Control", cacheStatic)
+				}
+				files.ServeHTTP(c.Writer, c.Request)
+				return
+			}
+		}
+
+		// 带扩展名的路径视为缺失的静态文件，其余交给前端路由
+		if path.Ext(name) != "" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		serveIndex(c, files)
+	})
+}
+
+// serveIndex 返回 index.html（通过目录根路径，避免 FileServer 将 /index.html 重定向）
+func serveIndex(c *gin.Context, files http.Handler) {
+	c.Header("Cache-Control", cacheNoCache)
+	req := c.Request.Clone(c.Request.Context())
+	req.URL.Path = "/"
+	req.URL.RawPath = ""
+	files.ServeHTTP(c.Writer, req)
+}
+
+// isReserved 是否为后端路径
+func isReserved(p string) bool {
+	for _, prefix := range frontendReserved {
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hiddenPath 路径中含以 . 开头的段（如 .gitkeep、.env）时不提供
+func hiddenPath(name string) bool {
+	for _, seg := range strings.Split(name, "/") {
+		if strings.HasPrefix(seg, ".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestFrontend(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/ping", func(c *gin.Context) { c.String(http.StatusOK, "pong") })
+	registerFrontend(r, fstest.MapFS{
+		"index.html":         {Data: []byte("<html>app</html>")},
+		"favicon.ico":        {Data: []byte("icon")},
+		"assets/index-a1.js": {Data: []byte("console.log(1)")},
+		".gitkeep":           {Data: []byte("")},
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		cache  string
+	}{
+		{"/", http.StatusOK, "app", cacheNoCache},
+		{"/index.html", http.StatusOK, "app", cacheNoCache},
+		{"/strategies/12", http.StatusOK, "app", cacheNoCache}, // SPA 路由回退
+		{"/assets/index-a1.js", http.StatusOK, "console.log", cacheImmutable},
+		{"/favicon.ico", http.StatusOK, "icon", cacheStatic},
+		{"/assets/missing.js", http.StatusNotFound, "", ""},
+		{"/.gitkeep", http.StatusNotFound, "", ""},
+		{"/api/v1/ping", http.StatusOK, "pong", ""},
+		{"/api/v1/missing", http.StatusNotFound, "接口不存在", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s: status = %d, body = %q", tt.path, w.Code, w.Body.String())
+		}
+		if got := w.Header().Get("Cache-Control"); got != tt.cache {
+			t.Errorf("%s: Cache-Control = %q, want %q", tt.path, got, tt.cache)
+		}
+	}
+}
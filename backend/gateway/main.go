@@ -1,23 +1,25 @@
 package main
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"io"
+	"errors"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/internalauth"
+	"stock-analysis-system/backend/pkg/middleware"
+	"stock-analysis-system/backend/pkg/server"
 )
 
 // ServiceConfig 服务配置
@@ -26,6 +28,8 @@ type ServiceConfig struct {
 	URL     string `json:"url"`
 	Timeout int    `json:"timeout"`
 	Healthy bool   `json:"healthy"`
+
+	upstreams []*upstream // 加权路由的上游实例，URL 为其中第一个；未加载时只使用 URL
 }
 
 // APIGateway API网关
@@ -33,6 +37,7 @@ type APIGateway struct {
 	services map[string]*ServiceConfig
 	logger   *zap.Logger
 	client   *http.Client
+	signer   *internalauth.Signer // 为转发到服务的请求签名，未配置密钥时为 nil
 }
 
 // NewAPIGateway 创建API网关
@@ -85,8 +90,17 @@ func (g *APIGateway) GetServiceProxy(serviceName string) *httputil.ReverseProxy
 		return nil
 	}
 
+	// 按权重选择上游实例，记录请求结果用于金丝雀回滚
+	up := service.pick()
 	target, _ := url.Parse(service.URL)
+	if up != nil {
+		target = up.target
+	}
 	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.ModifyResponse = func(resp *http.Response) error {
+		up.record(resp.StatusCode >= http.StatusInternalServerError)
+		return nil
+	}
 	
 	// 自定义Director
 	originalDirector := proxy.Director
@@ -95,11 +109,27 @@ func (g *APIGateway) GetServiceProxy(serviceName string) *httputil.ReverseProxy
 		req.URL.Path = strings.TrimPrefix(req.URL.Path, "/api/v1/"+serviceName)
 		req.Header.Set("X-Forwarded-Host", req.Host)
 		req.Header.Set("X-Origin-Host", target.Host)
+		g.signer.Sign(req)
 	}
 
 	// 错误处理
 	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-		g.logger.Error("代理请求失败", zap.String("service", serviceName), zap.Error(err))
+		g.logger.Error("代理请求失败", zap.String("service", serviceName), zap.String("upstream", target.String()), zap.Error(err))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		var maxBytesErr *http.MaxBytesError
+		if !errors.As(err, &maxBytesErr) && !errors.Is(err, context.Canceled) {
+			up.record(true)
+		}
+		if errors.As(err, &maxBytesErr) {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			json.NewEncoder(w).Encode(gin.H{"code": 413, "msg": "请求体过大"})
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			w.WriteHeader(http.StatusGatewayTimeout)
+			json.NewEncoder(w).Encode(gin.H{"code": 504, "msg": "服务响应超时"})
+			return
+		}
 		w.WriteHeader(http.StatusServiceUnavailable)
 		json.NewEncoder(w).Encode(gin.H{
 			"code": 503,
@@ -110,30 +140,83 @@ func (g *APIGateway) GetServiceProxy(serviceName string) *httputil.ReverseProxy
 	return proxy
 }
 
-// HealthCheck 服务健康检查
+// Timeout 获取服务的请求超时时间
+func (g *APIGateway) Timeout(serviceName string) time.Duration {
+	if service, exists := g.services[serviceName]; exists && service.Timeout > 0 {
+		return time.Duration(service.Timeout) * time.Second
+	}
+	return 30 * time.Second
+}
+
+// streamTimeout 流式接口（路径以 /stream 结尾，如 NDJSON K线、回测进度 SSE）的超时时间
+const streamTimeout = 10 * time.Minute
+
+// serviceTimeout 服务路由的超时中间件，流式接口改用 streamTimeout 并放宽该请求的写超时
+// WebSocket 升级请求（如实时行情推送）不设置接口超时，并清除服务器读写超时，由连接两端的心跳维持。
+func serviceTimeout(d time.Duration) gin.HandlerFunc {
+	timeout := middleware.Timeout(d)
+	stream := middleware.Timeout(streamTimeout)
+	return func(c *gin.Context) {
+		if strings.EqualFold(c.GetHeader("Upgrade"), "websocket") {
+			rc := http.NewResponseController(c.Writer)
+			rc.SetReadDeadline(time.Time{})
+			rc.SetWriteDeadline(time.Time{})
+			c.Next()
+			return
+		}
+		if !strings.HasSuffix(c.Request.URL.Path, "/stream") {
+			timeout(c)
+			return
+		}
+		http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(streamTimeout))
+		stream(c)
+	}
+}
+
+// HealthCheck 服务健康检查，多实例时所有分配流量的实例均健康才视为健康
 func (g *APIGateway) HealthCheck(serviceName string) bool {
 	service, exists := g.services[serviceName]
 	if !exists {
 		return false
 	}
 
+	urls := []string{service.URL}
+	if len(service.upstreams) > 0 {
+		urls = urls[:0]
+		for _, u := range service.upstreams {
+			if u.effectiveWeight() > 0 {
+				urls = append(urls, u.target.String())
+			}
+		}
+	}
+
+	healthy := true
+	for _, u := range urls {
+		if !g.checkHealth(u) {
+			healthy = false
+		}
+	}
+	service.Healthy = healthy
+	return healthy
+}
+
+// checkHealth 请求实例的 /health
+func (g *APIGateway) checkHealth(baseURL string) bool {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", service.URL+"/health", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/health", nil)
 	if err != nil {
 		return false
 	}
 
 	resp, err := g.client.Do(req)
 	if err != nil {
-		service.Healthy = false
 		return false
 	}
 	defer resp.Body.Close()
 
-	service.Healthy = resp.StatusCode == 200
-	return service.Healthy
+	return resp.StatusCode == 200
 }
 
 // HealthCheckAll 检查所有服务
@@ -150,27 +233,44 @@ func main() {
 	initConfig()
 
 	// 初始化日志
-	logger := initLogger()
+	logger, logLevel := initLogger()
 	defer logger.Sync()
 
 	// 创建网关
 	gateway := NewAPIGateway()
 	gateway.logger = logger
 	gateway.LoadServiceConfig()
+	if err := gateway.LoadUpstreams(); err != nil {
+		logger.Fatal("上游实例配置无效", zap.Error(err))
+	}
+
+	cfg := config.LoadFromEnv()
+	cfg.Server.Mode = viper.GetString("app.mode")
+	gateway.signer = internalauth.NewSigner(cfg.Internal.Secret)
 
-	// 设置运行模式
-	if viper.GetString("app.mode") == "production" {
-		gin.SetMode(gin.ReleaseMode)
+	// 配置文件热更新：日志级别与限流
+	live, err := config.NewLive(cfg)
+	if err != nil {
+		logger.Fatal("加载配置文件失败", zap.Error(err))
 	}
+	live.OnChange(func(c *config.Config) {
+		if err := logLevel.UnmarshalText([]byte(c.Log.Level)); err != nil {
+			logger.Warn("日志级别无效", zap.String("level", c.Log.Level))
+		}
+	})
+	rateLimit := middleware.RateLimit(func() config.RateLimitConfig { return live.Get().RateLimit })
+
+	// 按用户统计调用次数与下载数据量
+	meter, keys, closeMeter := newUsageMeter(cfg, logger)
 
-	// 创建路由
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(corsMiddleware())
-	r.Use(requestLogger(logger))
+	// 维护模式与功能开关
+	flags, closeFlags := newFeatures(cfg, live, logger)
+
+	// 按路由的请求体上限，服务器不再统一限制
+	bodyLimits := loadBodyLimits(cfg.Server.MaxBodySize, logger)
 
 	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
+	health := func(c *gin.Context) {
 		results := gateway.HealthCheckAll()
 		allHealthy := true
 		for _, healthy := range results {
@@ -192,120 +292,280 @@ func main() {
 			"services":  results,
 			"timestamp": time.Now().Unix(),
 		})
-	})
+	}
 
-	// API路由组 - 服务路由
-	api := r.Group("/api/v1")
-	{
-		// 行情服务路由
-		market := api.Group("/market")
-		{
-			market.Any("/*path", func(c *gin.Context) {
-				proxy := gateway.GetServiceProxy("market")
-				if proxy == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
-					return
-				}
-				proxy.ServeHTTP(c.Writer, c.Request)
-			})
-		}
+	// IP 黑白名单、路径穿越/超长查询参数拦截与按路由的方法限制
+	fw, err := loadFirewall()
+	if err != nil {
+		logger.Fatal("访问控制配置无效", zap.Error(err))
+	}
 
-		// 用户服务路由
-		user := api.Group("/user")
-		{
-			user.Any("/*path", func(c *gin.Context) {
-				proxy := gateway.GetServiceProxy("user")
-				if proxy == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
-					return
-				}
-				proxy.ServeHTTP(c.Writer, c.Request)
-			})
-		}
+	// HTTPS（证书文件或 Let's Encrypt 自动申请）与 HTTP/2
+	tlsConfig, err := loadTLS()
+	if err != nil {
+		logger.Fatal("TLS 配置无效", zap.Error(err))
+	}
 
-		// 认证路由（映射到用户服务）
-		auth := api.Group("/auth")
-		{
-			auth.Any("/*path", func(c *gin.Context) {
-				proxy := gateway.GetServiceProxy("user")
-				if proxy == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
-					return
-				}
-				proxy.ServeHTTP(c.Writer, c.Request)
-			})
+	srv := server.New("api-gateway", cfg,
+		server.WithPort(viper.GetString("app.port")),
+		server.WithTLS(tlsConfig),
+		server.WithWriteTimeout(90*time.Second), // 需覆盖最长的服务超时（回测/数据服务 60s）
+		server.WithMaxBodySize(0),               // 由 RouteBodyLimit 按路由限制
+		server.WithoutInternalAuth(),
+		server.WithRequestLogger(requestLogger(logger)),
+		server.WithMiddleware(fw.Middleware(), middleware.CORS(cfg.CORS)),
+		server.WithHealthHandler(health),
+		server.WithShutdownHook(func(context.Context) { live.Close() }),
+		server.WithShutdownHook(closeMeter),
+		server.WithShutdownHook(closeFlags),
+	)
+
+	if proxies, ok := fw.trustedProxies(); ok {
+		if err := srv.Router().SetTrustedProxies(proxies); err != nil {
+			logger.Fatal("可信代理配置无效", zap.Error(err))
 		}
-
-		// 策略服务路由
-		strategy := api.Group("/strategy")
-		{
-			strategy.Any("/*path", func(c *gin.Context) {
-				proxy := gateway.GetServiceProxy("strategy")
-				if proxy == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
-					return
-				}
-				proxy.ServeHTTP(c.Writer, c.Request)
-			})
+		if len(proxies) == 0 {
+			logger.Warn("已启用 IP 黑白名单但未配置 firewall.trusted_proxies，忽略 X-Forwarded-For，按连接地址判断来源 IP")
 		}
+	}
 
-		// 回测服务路由
-		backtest := api.Group("/backtest")
-		{
-			backtest.Any("/*path", func(c *gin.Context) {
-				proxy := gateway.GetServiceProxy("backtest")
-				if proxy == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
-					return
-				}
-				proxy.ServeHTTP(c.Writer, c.Request)
-			})
-		}
+	// API 文档
+	registerDocs(srv.Router())
 
-		// 数据同步服务路由
-		data := api.Group("/data")
-		{
-			data.Any("/*path", func(c *gin.Context) {
-				proxy := gateway.GetServiceProxy("data")
-				if proxy == nil {
-					c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
-					return
-				}
-				proxy.ServeHTTP(c.Writer, c.Request)
-			})
-		}
+	// 前端页面（可选），未匹配的页面路由回退到 index.html
+	if web := loadFrontend(logger); web != nil {
+		registerFrontend(srv.Router(), web)
+		logger.Info("Serving frontend", zap.String("dir", viper.GetString("frontend.dir")))
 	}
 
-	// 启动HTTP服务
-	srv := &http.Server{
-		Addr:    ":" + viper.GetString("app.port"),
-		Handler: r,
+	// API 版本信息与各版本请求统计
+	versions := loadAPIVersions()
+	srv.Router().GET("/api/versions", versionsHandler(versions))
+
+	// API路由组 - 服务路由，v1 与 v2 共用同一套服务路由，v2 由版本中间件改写路径并转换响应
+	for _, name := range []string{"v1", "v2"} {
+		api := srv.Router().Group("/api/"+name, middleware.Maintenance(flags), rateLimit, usageMetering(meter, keys), Versioned(versions[name]), RouteBodyLimit(bodyLimits))
+		registerServiceRoutes(api, gateway)
 	}
 
-	// 优雅关机
-	go func() {
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
+	logger.Info("API Gateway starting", zap.String("port", viper.GetString("app.port")))
 
-	logger.Info("API Gateway started", zap.String("port", viper.GetString("app.port")))
+	if err := srv.Run(); err != nil {
+		logger.Fatal("Server exited with error", zap.Error(err))
+	}
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	logger.Info("Server exited")
+}
 
-	logger.Info("Shutting down server...")
+// registerServiceRoutes 注册各后端服务的代理路由
+func registerServiceRoutes(api *gin.RouterGroup, gateway *APIGateway) {
+	// 首页聚合接口（并发查询多个服务）
+	api.GET("/dashboard", gateway.Dashboard)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	// 行情服务路由
+	market := api.Group("/market", serviceTimeout(gateway.Timeout("market")))
+	{
+		market.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("market")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
 
-	if err := srv.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown", zap.Error(err))
+	// 用户服务路由
+	user := api.Group("/user", middleware.Timeout(gateway.Timeout("user")))
+	{
+		user.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("user")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
 	}
 
-	logger.Info("Server exited")
+	// 认证路由（映射到用户服务）
+	auth := api.Group("/auth", middleware.Timeout(gateway.Timeout("user")))
+	{
+		auth.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("user")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 标签路由（映射到用户服务）
+	tags := api.Group("/tags", middleware.Timeout(gateway.Timeout("user")))
+	{
+		tags.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("user")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 异步任务路由（映射到用户服务）
+	tasks := api.Group("/tasks", middleware.Timeout(gateway.Timeout("user")))
+	{
+		tasks.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("user")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 图表标注路由（映射到用户服务）
+	annotations := api.Group("/annotations", middleware.Timeout(gateway.Timeout("user")))
+	{
+		annotations.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("user")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 股票篮子路由（映射到用户服务）
+	baskets := api.Group("/baskets", middleware.Timeout(gateway.Timeout("user")))
+	{
+		baskets.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("user")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 策略服务路由
+	strategy := api.Group("/strategy", middleware.Timeout(gateway.Timeout("strategy")))
+	{
+		strategy.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("strategy")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 股票池路由（映射到策略服务）
+	universes := api.Group("/universes", middleware.Timeout(gateway.Timeout("strategy")))
+	{
+		universes.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("strategy")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 自定义指标路由（映射到策略服务）
+	indicators := api.Group("/indicators", middleware.Timeout(gateway.Timeout("strategy")))
+	{
+		indicators.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("strategy")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 提醒规则路由（映射到策略服务）
+	alerts := api.Group("/alerts", middleware.Timeout(gateway.Timeout("strategy")))
+	{
+		alerts.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("strategy")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 分钟K线回放路由（映射到策略服务）
+	// WebSocket 长连接不设置接口超时，并清除服务器读写超时，避免回放中途被断开
+	replay := api.Group("/replay")
+	{
+		replay.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("strategy")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			rc := http.NewResponseController(c.Writer)
+			rc.SetReadDeadline(time.Time{})
+			rc.SetWriteDeadline(time.Time{})
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 回测服务路由
+	backtest := api.Group("/backtest", serviceTimeout(gateway.Timeout("backtest")))
+	{
+		backtest.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("backtest")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 风险分析路由（映射到回测服务）
+	risk := api.Group("/risk", middleware.Timeout(gateway.Timeout("backtest")))
+	{
+		risk.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("backtest")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
+
+	// 数据同步服务路由（/data/sync、/data/snapshots、/data/freshness 映射到数据同步服务的 /sync、/snapshots、/freshness，需 admin 角色或 API Key）
+	data := api.Group("/data", middleware.Timeout(gateway.Timeout("data")))
+	{
+		data.Any("/*path", gateway.proxyData)
+	}
+
+	// 管理员数据运维路由（映射到数据同步服务，路径原样转发）
+	admin := api.Group("/admin", middleware.Timeout(gateway.Timeout("data")))
+	{
+		admin.Any("/*path", func(c *gin.Context) {
+			proxy := gateway.GetServiceProxy("data")
+			if proxy == nil {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务不可用"})
+				return
+			}
+			proxy.ServeHTTP(c.Writer, c.Request)
+		})
+	}
 }
 
 // 初始化配置
@@ -318,37 +578,35 @@ func initConfig() {
 	// 默认值
 	viper.SetDefault("app.port", "8080")
 	viper.SetDefault("app.mode", "development")
+	viper.SetDefault("api.versions.v1.deprecated", false)
+	viper.SetDefault("api.versions.v1.sunset", "")
+	viper.SetDefault("tls.enabled", false)
+	viper.SetDefault("tls.autocert.cache_dir", "./certs")
+	viper.SetDefault("tls.redirect_port", "") // 如 "80"，为空不监听 HTTP
+	viper.SetDefault("tls.http2", true)
+	viper.SetDefault("frontend.enabled", false)
+	viper.SetDefault("frontend.dir", "") // 为空时使用编译进网关的资源
+	viper.SetDefault("gateway.canary.error_rate", defaultCanaryErrorRate)
+	viper.SetDefault("gateway.canary.min_requests", defaultCanaryMinRequests)
+	viper.SetDefault("gateway.canary.window", defaultCanaryWindow)
+	viper.SetDefault("firewall.max_query_length", defaultMaxQueryLength) // 负数不限制
+	viper.BindEnv("frontend.enabled", "FRONTEND_ENABLED")
+	viper.BindEnv("frontend.dir", "FRONTEND_DIR")
 
 	if err := viper.ReadInConfig(); err != nil {
 		log.Printf("Config file not found, using defaults: %v", err)
 	}
 }
 
-// 初始化日志
-func initLogger() *zap.Logger {
+// 初始化日志，返回的级别可在运行时调整
+func initLogger() (*zap.Logger, zap.AtomicLevel) {
 	config := zap.NewProductionConfig()
 	config.OutputPaths = []string{"stdout", "./logs/api-gateway.log"}
 	logger, err := config.Build()
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
-	return logger
-}
-
-// CORS中间件
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-
-		c.Next()
-	}
+	return logger, config.Level
 }
 
 // 请求日志中间件
@@ -375,6 +633,8 @@ func requestLogger(logger *zap.Logger) gin.HandlerFunc {
 			zap.String("path", path),
 			zap.Int("status", statusCode),
 			zap.Duration("latency", latency),
+			zap.String("api_version", c.GetString("api_version")),
+			zap.String("request_id", c.GetString("request_id")),
 		)
 	}
 }
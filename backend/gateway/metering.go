@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"stock-analysis-system/backend/pkg/auth"
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/metering"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 用量计量 ============
+
+// newUsageMeter 连接 PostgreSQL 创建用量计量，返回关闭函数（写入剩余用量并断开连接）
+// 网关不依赖数据库转发请求，连接失败时只记录日志并关闭计量。
+func newUsageMeter(cfg *config.Config, logger *zap.Logger) (*metering.Meter, *auth.KeySet, func(context.Context)) {
+	keys, err := auth.NewKeySet(cfg.Auth)
+	if err != nil {
+		logger.Warn("加载 JWT 密钥失败，不统计用量", zap.Error(err))
+		return nil, nil, func(context.Context) {}
+	}
+	pg, err := database.NewPostgresClient(&cfg.Database.Postgres)
+	if err != nil {
+		logger.Warn("连接 PostgreSQL 失败，不统计用量", zap.Error(err))
+		return nil, nil, func(context.Context) {}
+	}
+
+	meter := metering.NewMeter(repository.NewUsageRepository(pg.DB))
+	ctx, cancel := context.WithCancel(context.Background())
+	go meter.Run(ctx, metering.DefaultFlushInterval)
+
+	return meter, keys, func(ctx context.Context) {
+		cancel()
+		if err := meter.Flush(ctx); err != nil {
+			logger.Error("写入用量统计失败", zap.Error(err))
+		}
+		pg.Close()
+	}
+}
+
+// usageMetering 统计已认证请求的调用次数与响应字节数
+// 只解析 Token 识别用户，不拒绝请求；Token 无效时由后端服务返回 401，不计入用量。
+func usageMetering(meter *metering.Meter, keys *auth.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if meter == nil {
+			c.Next()
+			return
+		}
+		header := c.GetHeader("Authorization")
+		if !strings.HasPrefix(header, "Bearer ") {
+			c.Next()
+			return
+		}
+		claims, err := keys.Parse(strings.TrimPrefix(header, "Bearer "))
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		// WebSocket 等被接管的连接 Size 为 -1
+		size := int64(c.Writer.Size())
+		if size < 0 {
+			size = 0
+		}
+		meter.Record(claims.UserID, metering.Counters{APICalls: 1, BytesDownloaded: size})
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/spf13/viper"
+
+	"stock-analysis-system/backend/pkg/server"
+)
+
+// loadTLS 读取网关的 HTTPS 配置（tls.*），未启用时返回 nil
+func loadTLS() (*server.TLS, error) {
+	if !viper.GetBool("tls.enabled") {
+		return nil, nil
+	}
+	t := &server.TLS{
+		CertFile:         viper.GetString("tls.cert_file"),
+		KeyFile:          viper.GetString("tls.key_file"),
+		AutocertDomains:  viper.GetStringSlice("tls.autocert.domains"),
+		AutocertEmail:    viper.GetString("tls.autocert.email"),
+		AutocertCacheDir: viper.GetString("tls.autocert.cache_dir"),
+		RedirectPort:     viper.GetString("tls.redirect_port"),
+		DisableHTTP2:     !viper.GetBool("tls.http2"),
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
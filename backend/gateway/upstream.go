@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+
+	"stock-analysis-system/backend/pkg/metrics"
+)
+
+// ============ 多实例加权路由与金丝雀发布 ============
+//
+// 每个服务可配置多个上游地址及权重（gateway.upstreams.<服务>），请求按权重随机分配，
+// 将少量流量导向标记为 canary 的新版本实例。金丝雀实例在统计窗口内的错误率（5xx 与连接失败）
+// 超过阈值时自动将其权重降为 0，流量全部回到稳定版本；恢复需修正配置后重启网关。
+// 未配置时服务只有环境变量中的一个地址。
+
+// UpstreamConfig 服务的一个上游实例
+type UpstreamConfig struct {
+	URL    string `mapstructure:"url"`
+	Weight int    `mapstructure:"weight"` // 相对权重，0 表示不分配流量
+	Canary bool   `mapstructure:"canary"` // 金丝雀实例，错误率过高时自动回滚
+}
+
+// CanaryConfig 金丝雀自动回滚条件（gateway.canary.*）
+type CanaryConfig struct {
+	ErrorRate   float64 `mapstructure:"error_rate"`   // 错误率阈值（0-1）
+	MinRequests int     `mapstructure:"min_requests"` // 窗口内请求数不少于该值才判断，避免少量请求误判
+	Window      int     `mapstructure:"window"`       // 统计窗口（秒）
+}
+
+// 默认回滚条件
+const (
+	defaultCanaryErrorRate   = 0.2
+	defaultCanaryMinRequests = 20
+	defaultCanaryWindow      = 60
+)
+
+// upstream 上游实例及其窗口内的请求统计
+type upstream struct {
+	service string
+	target  *url.URL
+	weight  int
+	canary  bool
+	rules   CanaryConfig
+	logger  *zap.Logger
+
+	mu          sync.Mutex
+	rolledBack  bool
+	windowStart time.Time
+	requests    int // 当前窗口
+	errors      int // 当前窗口
+	total       int64
+	totalErrors int64
+}
+
+// LoadUpstreams 读取 gateway.upstreams 与 gateway.canary，替换服务默认的单个地址
+func (g *APIGateway) LoadUpstreams() error {
+	var rules CanaryConfig
+	if err := viper.UnmarshalKey("gateway.canary", &rules); err != nil {
+		return err
+	}
+	if rules.ErrorRate <= 0 || rules.ErrorRate > 1 {
+		return fmt.Errorf("金丝雀错误率阈值需在 (0, 1] 之间")
+	}
+	if rules.MinRequests <= 0 || rules.Window <= 0 {
+		return fmt.Errorf("金丝雀最少请求数与统计窗口需大于 0")
+	}
+
+	var configs map[string][]UpstreamConfig
+	if err := viper.UnmarshalKey("gateway.upstreams", &configs); err != nil {
+		return err
+	}
+	for name, service := range g.services {
+		items := configs[name]
+		if len(items) == 0 {
+			items = []UpstreamConfig{{URL: service.URL, Weight: 1}}
+		}
+		upstreams, err := newUpstreams(name, items, rules, g.logger)
+		if err != nil {
+			return err
+		}
+		service.upstreams = upstreams
+		service.URL = upstreams[0].target.String()
+	}
+	for name := range configs {
+		if _, ok := g.services[name]; !ok {
+			return fmt.Errorf("未知的服务 %s", name)
+		}
+	}
+
+	metrics.Register("gateway_upstreams", g.collectUpstreamMetrics)
+	return nil
+}
+
+// newUpstreams 解析服务的上游实例，权重之和需大于 0
+func newUpstreams(service string, items []UpstreamConfig, rules CanaryConfig, logger *zap.Logger) ([]*upstream, error) {
+	var upstreams []*upstream
+	total := 0
+	for _, item := range items {
+		target, err := url.Parse(item.URL)
+		if err != nil || target.Scheme == "" || target.Host == "" {
+			return nil, fmt.Errorf("服务 %s 的上游地址 %q 格式错误", service, item.URL)
+		}
+		if item.Weight < 0 {
+			return nil, fmt.Errorf("服务 %s 的上游权重不能为负数", service)
+		}
+		total += item.Weight
+		upstreams = append(upstreams, &upstream{
+			service: service,
+			target:  target,
+			weight:  item.Weight,
+			canary:  item.Canary,
+			rules:   rules,
+			logger:  logger,
+		})
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("服务 %s 的上游权重之和需大于 0", service)
+	}
+	return upstreams, nil
+}
+
+// pick 按当前权重随机选择上游，服务未配置多实例时返回 nil
+func (s *ServiceConfig) pick() *upstream {
+	if len(s.upstreams) == 0 {
+		return nil
+	}
+	total := 0
+	for _, u := range s.upstreams {
+		total += u.effectiveWeight()
+	}
+	if total == 0 {
+		return s.upstreams[0]
+	}
+	n := rand.Intn(total)
+	for _, u := range s.upstreams {
+		if n -= u.effectiveWeight(); n < 0 {
+			return u
+		}
+	}
+	return s.upstreams[len(s.upstreams)-1]
+}
+
+// effectiveWeight 当前权重，回滚后的金丝雀实例为 0
+func (u *upstream) effectiveWeight() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.rolledBack {
+		return 0
+	}
+	return u.weight
+}
+
+// record 记录一次请求结果，金丝雀实例错误率超过阈值时回滚
+func (u *upstream) record(failed bool) {
+	if u == nil {
+		return
+	}
+	now := time.Now()
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if now.Sub(u.windowStart) >= time.Duration(u.rules.Window)*time.Second {
+		u.windowStart = now
+		u.requests, u.errors = 0, 0
+	}
+	u.requests++
+	u.total++
+	if failed {
+		u.errors++
+		u.totalErrors++
+	}
+
+	if !u.canary || u.rolledBack || u.requests < u.rules.MinRequests {
+		return
+	}
+	if rate := float64(u.errors) / float64(u.requests); rate >= u.rules.ErrorRate {
+		u.rolledBack = true
+		if u.logger != nil {
+			u.logger.Error("金丝雀实例错误率过高，已停止分配流量",
+				zap.String("service", u.service),
+				zap.String("upstream", u.target.String()),
+				zap.Int("requests", u.requests),
+				zap.Float64("error_rate", rate))
+		}
+	}
+}
+
+// collectUpstreamMetrics 各上游的请求数、错误数与当前权重
+func (g *APIGateway) collectUpstreamMetrics() []metrics.Sample {
+	var samples []metrics.Sample
+	for _, service := range g.services {
+		for _, u := range service.upstreams {
+			labels := map[string]string{"service": u.service, "upstream": u.target.String()}
+			u.mu.Lock()
+			total, errs := u.total, u.totalErrors
+			u.mu.Unlock()
+			samples = append(samples,
+				metrics.Sample{Name: "gateway_upstream_requests_total", Help: "转发到上游实例的请求数", Type: metrics.TypeCounter, Labels: labels, Value: float64(total)},
+				metrics.Sample{Name: "gateway_upstream_errors_total", Help: "上游实例返回 5xx 或连接失败的请求数", Type: metrics.TypeCounter, Labels: labels, Value: float64(errs)},
+				metrics.Sample{Name: "gateway_upstream_weight", Help: "上游实例当前权重（金丝雀回滚后为 0）", Type: metrics.TypeGauge, Labels: labels, Value: float64(u.effectiveWeight())},
+			)
+		}
+	}
+	return samples
+}
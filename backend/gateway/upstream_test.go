@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestNewUpstreamsInvalid(t *testing.T) {
+	rules := CanaryConfig{ErrorRate: 0.2, MinRequests: 10, Window: 60}
+	tests := map[string][]UpstreamConfig{
+		"地址缺少协议": {{URL: "backtest-service:8085", Weight: 1}},
+		"负数权重":   {{URL: "http://a:8085", Weight: -1}},
+		"权重之和为0": {{URL: "http://a:8085"}, {URL: "http://b:8085"}},
+	}
+	for name, items := range tests {
+		if _, err := newUpstreams("backtest", items, rules, nil); err == nil {
+			t.Errorf("%s: 应返回错误", name)
+		}
+	}
+}
+
+func TestCanaryRollback(t *testing.T) {
+	var stableHits, canaryHits atomic.Int64
+	stable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stableHits.Add(1)
+		w.Write([]byte(`{"code":0}`))
+	}))
+	defer stable.Close()
+	canary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		canaryHits.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer canary.Close()
+
+	upstreams, err := newUpstreams("backtest", []UpstreamConfig{
+		{URL: stable.URL, Weight: 1},
+		{URL: canary.URL, Weight: 1, Canary: true},
+	}, CanaryConfig{ErrorRate: 0.5, MinRequests: 5, Window: 60}, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewAPIGateway()
+	g.logger = zap.NewNop()
+	g.services["backtest"] = &ServiceConfig{Name: "backtest-service", URL: stable.URL, upstreams: upstreams}
+
+	serve := func() {
+		w := httptest.NewRecorder()
+		g.GetServiceProxy("backtest").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/backtest/list", nil))
+	}
+	for i := 0; i < 200 && canaryHits.Load() < 5; i++ {
+		serve()
+	}
+	if canaryHits.Load() < 5 {
+		t.Fatalf("金丝雀实例只收到 %d 个请求", canaryHits.Load())
+	}
+	if w := upstreams[1].effectiveWeight(); w != 0 {
+		t.Fatalf("金丝雀错误率超过阈值后权重 = %d，应回滚为 0", w)
+	}
+
+	// 回滚后流量全部回到稳定实例
+	before := canaryHits.Load()
+	for i := 0; i < 50; i++ {
+		serve()
+	}
+	if canaryHits.Load() != before {
+		t.Errorf("回滚后金丝雀实例仍收到 %d 个请求", canaryHits.Load()-before)
+	}
+	if upstreams[0].effectiveWeight() != 1 {
+		t.Error("稳定实例的权重不应变化")
+	}
+}
+
+func TestUpstreamRecordBelowMinRequests(t *testing.T) {
+	upstreams, err := newUpstreams("market", []UpstreamConfig{
+		{URL: "http://stable:8082", Weight: 9},
+		{URL: "http://canary:8082", Weight: 1, Canary: true},
+	}, CanaryConfig{ErrorRate: 0.2, MinRequests: 10, Window: 60}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	canary := upstreams[1]
+	for i := 0; i < 9; i++ {
+		canary.record(true)
+	}
+	if canary.effectiveWeight() == 0 {
+		t.Fatal("请求数未达到最少请求数时不应回滚")
+	}
+
+	// 稳定实例不自动回滚
+	for i := 0; i < 20; i++ {
+		upstreams[0].record(true)
+	}
+	if upstreams[0].effectiveWeight() != 9 {
+		t.Error("稳定实例不应回滚")
+	}
+
+	// 按权重分配
+	service := &ServiceConfig{upstreams: upstreams}
+	hits := 0
+	for i := 0; i < 10000; i++ {
+		if service.pick() == canary {
+			hits++
+		}
+	}
+	if hits < 800 || hits > 1200 {
+		t.Errorf("10%% 权重的金丝雀实例分到 %d/10000 个请求", hits)
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+)
+
+// ============ API 版本 ============
+//
+// 后端服务只实现 /api/v1，网关对外同时提供 /api/v1 与 /api/v2：
+// v2 请求改写为 v1 路径转发（附带 X-API-Version 头，服务可据此逐步演进），
+// 响应再由该版本的适配器转换结构，旧客户端继续使用 v1 不受影响。
+
+// APIVersionHeader 转发给后端服务及返回给客户端的 API 版本头
+const APIVersionHeader = "X-API-Version"
+
+// backendVersion 后端服务实现的 API 版本
+const backendVersion = "v1"
+
+// responseAdapter 将后端（v1）的 JSON 响应转换为指定版本的结构
+type responseAdapter func(body map[string]interface{}) map[string]interface{}
+
+// APIVersion API 版本定义
+type APIVersion struct {
+	Name       string          `json:"name"`
+	Deprecated bool            `json:"deprecated"`
+	Sunset     string          `json:"sunset,omitempty"`    // 计划下线日期 YYYY-MM-DD
+	Successor  string          `json:"successor,omitempty"` // 建议迁移到的版本
+	adapter    responseAdapter // 为空表示与后端结构一致
+	metrics    *versionMetrics
+}
+
+// versionMetrics 单个 API 版本的请求统计
+type versionMetrics struct {
+	mu        sync.Mutex
+	requests  int64
+	status    map[string]int64 // 按状态码类别（2xx/4xx/5xx）计数
+	latency   time.Duration
+	routes    map[string]int64 // 按路由计数，用于评估弃用版本的剩余调用方
+	lastCalls time.Time
+}
+
+// VersionMetricsSnapshot API 版本统计快照
+type VersionMetricsSnapshot struct {
+	Requests     int64            `json:"requests"`
+	Status       map[string]int64 `json:"status"`
+	AvgLatencyMs float64          `json:"avg_latency_ms"`
+	Routes       map[string]int64 `json:"routes"`
+	LastCallAt   *time.Time       `json:"last_call_at,omitempty"`
+}
+
+func newVersionMetrics() *versionMetrics {
+	return &versionMetrics{status: make(map[string]int64), routes: make(map[string]int64)}
+}
+
+// observe 记录一次请求
+func (m *versionMetrics) observe(route string, status int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests++
+	m.status[strconv.Itoa(status/100)+"xx"]++
+	m.latency += latency
+	m.routes[route]++
+	m.lastCalls = time.Now()
+}
+
+// snapshot 返回统计快照
+func (m *versionMetrics) snapshot() VersionMetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := VersionMetricsSnapshot{
+		Requests: m.requests,
+		Status:   make(map[string]int64, len(m.status)),
+		Routes:   make(map[string]int64, len(m.routes)),
+	}
+	for k, v := range m.status {
+		s.Status[k] = v
+	}
+	for k, v := range m.routes {
+		s.Routes[k] = v
+	}
+	if m.requests > 0 {
+		s.AvgLatencyMs = float64(m.latency.Milliseconds()) / float64(m.requests)
+		last := m.lastCalls
+		s.LastCallAt = &last
+	}
+	return s
+}
+
+// loadAPIVersions 加载 API 版本定义，弃用状态与下线日期来自配置 api.versions.<name>
+func loadAPIVersions() map[string]*APIVersion {
+	versions := map[string]*APIVersion{
+		"v1": {Name: "v1", Successor: "v2"},
+		"v2": {Name: "v2", adapter: adaptV2},
+	}
+	for name, v := range versions {
+		v.Deprecated = viper.GetBool("api.versions." + name + ".deprecated")
+		v.Sunset = viper.GetString("api.versions." + name + ".sunset")
+		if !v.Deprecated {
+			v.Successor = ""
+		}
+		v.metrics = newVersionMetrics()
+	}
+	return versions
+}
+
+// Versioned API 版本中间件：统计请求、为弃用版本添加弃用响应头，
+// 并将非后端版本的请求改写为后端版本路径，响应经适配器转换后返回。
+// 需注册在路由组上，位于接口超时中间件之前。
+func Versioned(v *APIVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Set("api_version", v.Name)
+		c.Header(APIVersionHeader, v.Name)
+		if v.Deprecated {
+			setDeprecationHeaders(c.Writer.Header(), v)
+		}
+
+		if v.Name != backendVersion {
+			prefix := "/api/" + v.Name
+			c.Request.URL.Path = "/api/" + backendVersion + strings.TrimPrefix(c.Request.URL.Path, prefix)
+			if c.Request.URL.RawPath != "" {
+				c.Request.URL.RawPath = "/api/" + backendVersion + strings.TrimPrefix(c.Request.URL.RawPath, prefix)
+			}
+			c.Request.Header.Set(APIVersionHeader, v.Name)
+		}
+
+		// WebSocket 等长连接不做响应转换
+		if v.adapter == nil || isUpgrade(c.Request) {
+			c.Next()
+			v.metrics.observe(c.FullPath(), c.Writer.Status(), time.Since(start))
+			return
+		}
+
+		w := &bufferedWriter{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+		w.flushTo(c.Writer, v.adapter)
+		v.metrics.observe(c.FullPath(), w.status, time.Since(start))
+	}
+}
+
+// setDeprecationHeaders 添加弃用响应头（Deprecation / Sunset / Link）
+func setDeprecationHeaders(h http.Header, v *APIVersion) {
+	h.Set("Deprecation", "true")
+	if v.Sunset != "" {
+		if t, err := time.Parse("2006-01-02", v.Sunset); err == nil {
+			h.Set("Sunset", t.UTC().Format(http.TimeFormat))
+		}
+	}
+	if v.Successor != "" {
+		h.Add("Link", `</api/`+v.Successor+`>; rel="successor-version"`)
+	}
+}
+
+// isUpgrade 是否为协议升级请求（WebSocket）
+func isUpgrade(r *http.Request) bool {
+	return strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// bufferedWriter 缓存下游的 JSON 响应，便于整体转换后再写出；
+// 其他类型的响应（文件下载、SSE 等）在写出响应头时切换为直接透传。
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status      int
+	written     bool // 已设置状态码或写出响应体
+	started     bool // 已开始写出响应体
+	passthrough bool
+	body        bytes.Buffer
+}
+
+// begin 首次写出响应体时按 Content-Type 决定缓存还是透传
+func (w *bufferedWriter) begin() {
+	if w.started {
+		return
+	}
+	w.started = true
+	w.written = true
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		w.passthrough = true
+		w.ResponseWriter.WriteHeader(w.status)
+	}
+}
+
+// WriteHeader 只记录状态码，响应头在写出响应体或转换完成后才真正写出
+func (w *bufferedWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+		w.written = true
+	}
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	w.begin()
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	if w.passthrough {
+		return w.ResponseWriter.Size()
+	}
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.written
+}
+
+// Flush 透传时向客户端刷新，缓存期间不刷新
+func (w *bufferedWriter) Flush() {
+	if w.passthrough {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// Unwrap 供 http.ResponseController 设置写超时等操作时访问底层连接
+func (w *bufferedWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flushTo 转换缓存的 JSON 响应后写出，无法解析时原样写出
+func (w *bufferedWriter) flushTo(out gin.ResponseWriter, adapter responseAdapter) {
+	if !w.written || w.passthrough {
+		return
+	}
+	body := w.body.Bytes()
+	var parsed map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber() // 保持整数原样，避免大整数按浮点数输出
+	if err := decoder.Decode(&parsed); err == nil {
+		if converted, err := json.Marshal(adapter(parsed)); err == nil {
+			body = converted
+		}
+	}
+	if len(body) == 0 {
+		out.WriteHeader(w.status)
+		out.WriteHeaderNow()
+		return
+	}
+	out.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	out.WriteHeader(w.status)
+	out.Write(body)
+}
+
+// adaptV2 v1 → v2 响应结构：
+//   - msg 改名为 message；
+//   - 分页数据 {list, total, page, page_size} 改为 {items, pagination: {total, page, page_size}}。
+func adaptV2(body map[string]interface{}) map[string]interface{} {
+	if msg, ok := body["msg"]; ok {
+		if _, exists := body["message"]; !exists {
+			body["message"] = msg
+		}
+		delete(body, "msg")
+	}
+
+	data, ok := body["data"].(map[string]interface{})
+	if !ok {
+		return body
+	}
+	list, hasList := data["list"]
+	total, hasTotal := data["total"]
+	if !hasList || !hasTotal {
+		return body
+	}
+
+	pagination := map[string]interface{}{"total": total}
+	for _, key := range []string{"page", "page_size"} {
+		if value, exists := data[key]; exists {
+			pagination[key] = value
+			delete(data, key)
+		}
+	}
+	delete(data, "list")
+	delete(data, "total")
+	data["items"] = list
+	data["pagination"] = pagination
+	return body
+}
+
+// versionsHandler 返回 API 版本列表及各版本的请求统计
+func versionsHandler(versions map[string]*APIVersion) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		names := make([]string, 0, len(versions))
+		for name := range versions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		list := make([]gin.H, 0, len(names))
+		for _, name := range names {
+			v := versions[name]
+			list = append(list, gin.H{
+				"version": v,
+				"metrics": v.metrics.snapshot(),
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code": 0,
+			"data": gin.H{
+				"backend": backendVersion,
+				"list":    list,
+			},
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVersionedV2(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	versions := loadAPIVersions()
+	versions["v1"].Deprecated = true
+	versions["v1"].Successor = "v2"
+	versions["v1"].Sunset = "2027-01-01"
+
+	r := gin.New()
+	for _, name := range []string{"v1", "v2"} {
+		r.Group("/api/"+name, Versioned(versions[name])).GET("/market/stocks", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{
+				"code": 0,
+				"msg":  "ok",
+				"data": gin.H{"list": []int{1}, "total": 12345678901, "page": 1, "page_size": 20},
+				"path": c.Request.URL.Path,
+			})
+		})
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/market/stocks", nil))
+	body := w.Body.String()
+	for _, want := range []string{`"message":"ok"`, `"items":[1]`, `"total":12345678901`, `"path":"/api/v1/market/stocks"`} {
+		if !strings.Contains(body, want) {
+			t.Errorf("v2 body %s missing %s", body, want)
+		}
+	}
+	if strings.Contains(body, `"msg"`) || strings.Contains(body, `"list"`) {
+		t.Errorf("v2 body still has v1 fields: %s", body)
+	}
+	if w.Header().Get("Deprecation") != "" {
+		t.Errorf("v2 should not be deprecated")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/market/stocks", nil))
+	if !strings.Contains(w.Body.String(), `"list":[1]`) {
+		t.Errorf("v1 body changed: %s", w.Body.String())
+	}
+	if w.Header().Get("Deprecation") != "true" || w.Header().Get("Sunset") == "" ||
+		!strings.Contains(w.Header().Get("Link"), "/api/v2") {
+		t.Errorf("missing deprecation headers: %v", w.Header())
+	}
+
+	if got := versions["v2"].metrics.snapshot(); got.Requests != 1 || got.Status["2xx"] != 1 {
+		t.Errorf("v2 metrics = %+v", got)
+	}
+}
@@ -0,0 +1,169 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/notify"
+)
+
+// 告警类型，同一类型在静默期内只通知一次
+const (
+	KeySyncFailure   = "sync_failure"
+	KeyQualityErrors = "quality_errors"
+	KeyStaleData     = "stale_data"
+)
+
+// Sender 发送通知，notify.Dispatcher 实现了该接口
+type Sender interface {
+	Send(ctx context.Context, msg notify.Message) error
+}
+
+// QualitySnapshot 一次全市场数据质量检查的汇总
+type QualitySnapshot struct {
+	Stocks int // 检查的股票数
+	Errors int // 状态为 error 的检查项数
+	Stale  int // 最新日K线滞后超过阈值（或没有数据）的股票数
+}
+
+// Monitor 数据管道告警：跟踪增量同步与数据质量检查结果，超过阈值时通知运维
+// 告警持续触发时按静默期限流；条件恢复后发送恢复通知，之后再次触发立即通知。
+type Monitor struct {
+	sender Sender
+	rules  config.AlertConfig
+	now    func() time.Time
+
+	mu           sync.Mutex
+	firing       map[string]time.Time // 告警类型 -> 最近一次通知时间
+	syncFailures int                  // 增量同步连续失败次数
+	lastErrors   int                  // 上一次质量检查的 error 数，-1 表示尚未检查
+}
+
+// NewMonitor 创建告警监控
+func NewMonitor(sender Sender, rules config.AlertConfig) *Monitor {
+	return &Monitor{
+		sender:     sender,
+		rules:      rules,
+		now:        time.Now,
+		firing:     make(map[string]time.Time),
+		lastErrors: -1,
+	}
+}
+
+// SyncResult 记录一次增量同步的结果，连续失败达到阈值时告警
+func (m *Monitor) SyncResult(ctx context.Context, err error) {
+	m.mu.Lock()
+	if err == nil {
+		m.syncFailures = 0
+	} else {
+		m.syncFailures++
+	}
+	failures := m.syncFailures
+	m.mu.Unlock()
+
+	if err == nil || m.rules.SyncFailures <= 0 || failures < m.rules.SyncFailures {
+		m.resolve(ctx, KeySyncFailure, "增量同步已恢复")
+		return
+	}
+	m.fire(ctx, KeySyncFailure, notify.Message{
+		Title: "增量同步连续失败",
+		Text:  fmt.Sprintf("增量同步已连续失败 %d 次，最近一次错误: %v", failures, err),
+		Level: notify.LevelCritical,
+	})
+}
+
+// QualityResult 记录一次全市场数据质量检查，质量 error 数激增或大面积数据滞后时告警
+func (m *Monitor) QualityResult(ctx context.Context, snap QualitySnapshot) {
+	m.mu.Lock()
+	last := m.lastErrors
+	m.lastErrors = snap.Errors
+	m.mu.Unlock()
+
+	if reason := m.qualityBreach(snap, last); reason != "" {
+		m.fire(ctx, KeyQualityErrors, notify.Message{
+			Title: "数据质量 error 激增",
+			Text:  fmt.Sprintf("检查 %d 只股票，%s", snap.Stocks, reason),
+			Level: notify.LevelWarning,
+		})
+	} else {
+		m.resolve(ctx, KeyQualityErrors, fmt.Sprintf("数据质量 error 数已回落至 %d", snap.Errors))
+	}
+
+	if ratio, ok := m.staleBreach(snap); ok {
+		m.fire(ctx, KeyStaleData, notify.Message{
+			Title: "全市场数据滞后",
+			Text: fmt.Sprintf("%d/%d 只股票（%.1f%%）的日K线滞后超过 %d 天",
+				snap.Stale, snap.Stocks, ratio*100, m.rules.StaleDays),
+			Level: notify.LevelCritical,
+		})
+	} else {
+		m.resolve(ctx, KeyStaleData, "全市场数据滞后已恢复")
+	}
+}
+
+// qualityBreach 质量 error 数超过阈值或较上一次检查激增时返回原因
+func (m *Monitor) qualityBreach(snap QualitySnapshot, last int) string {
+	if m.rules.QualityErrors > 0 && snap.Errors >= m.rules.QualityErrors {
+		return fmt.Sprintf("error 检查项 %d 个，超过阈值 %d", snap.Errors, m.rules.QualityErrors)
+	}
+	if m.rules.QualityErrorJump > 0 && last >= 0 && snap.Errors-last >= m.rules.QualityErrorJump {
+		return fmt.Sprintf("error 检查项由 %d 个增至 %d 个", last, snap.Errors)
+	}
+	return ""
+}
+
+// staleBreach 滞后股票占比是否超过阈值
+func (m *Monitor) staleBreach(snap QualitySnapshot) (float64, bool) {
+	if m.rules.StaleRatio <= 0 || snap.Stocks == 0 {
+		return 0, false
+	}
+	ratio := float64(snap.Stale) / float64(snap.Stocks)
+	return ratio, ratio >= m.rules.StaleRatio
+}
+
+// fire 触发告警，静默期内不重复通知
+func (m *Monitor) fire(ctx context.Context, key string, msg notify.Message) {
+	now := m.now()
+	m.mu.Lock()
+	if last, ok := m.firing[key]; ok && now.Sub(last) < m.silence() {
+		m.mu.Unlock()
+		return
+	}
+	m.firing[key] = now
+	m.mu.Unlock()
+
+	msg.Time = now
+	m.send(ctx, msg)
+}
+
+// resolve 告警恢复，只有处于触发状态时才发送恢复通知
+func (m *Monitor) resolve(ctx context.Context, key, text string) {
+	m.mu.Lock()
+	_, firing := m.firing[key]
+	delete(m.firing, key)
+	m.mu.Unlock()
+
+	if firing {
+		m.send(ctx, notify.Message{Title: "告警恢复", Text: text, Level: notify.LevelResolved, Time: m.now()})
+	}
+}
+
+// send 发送通知，失败只记录日志
+func (m *Monitor) send(ctx context.Context, msg notify.Message) {
+	log.Printf("数据管道告警 [%s] %s: %s", msg.Level, msg.Title, msg.Text)
+	if m.sender == nil {
+		return
+	}
+	if err := m.sender.Send(ctx, msg); err != nil {
+		log.Printf("发送告警通知失败: %v", err)
+	}
+}
+
+// silence 静默期
+func (m *Monitor) silence() time.Duration {
+	return time.Duration(m.rules.SilenceMinutes) * time.Minute
+}
@@ -0,0 +1,106 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/notify"
+)
+
+// recorder 记录发送的通知
+type recorder struct {
+	msgs []notify.Message
+}
+
+func (r *recorder) Send(_ context.Context, msg notify.Message) error {
+	r.msgs = append(r.msgs, msg)
+	return nil
+}
+
+func newTestMonitor(rules config.AlertConfig) (*Monitor, *recorder, *time.Time) {
+	rec := &recorder{}
+	now := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+	m := NewMonitor(rec, rules)
+	m.now = func() time.Time { return now }
+	return m, rec, &now
+}
+
+func TestSyncFailures(t *testing.T) {
+	m, rec, now := newTestMonitor(config.AlertConfig{SyncFailures: 2, SilenceMinutes: 60})
+	ctx := context.Background()
+	failed := errors.New("连接数据源超时")
+
+	m.SyncResult(ctx, failed)
+	if len(rec.msgs) != 0 {
+		t.Fatalf("未达到连续失败阈值不应告警，got %d", len(rec.msgs))
+	}
+	m.SyncResult(ctx, failed)
+	if len(rec.msgs) != 1 || rec.msgs[0].Level != notify.LevelCritical {
+		t.Fatalf("连续失败 2 次应告警，got %+v", rec.msgs)
+	}
+
+	// 静默期内持续失败不重复通知，过了静默期再次通知
+	*now = now.Add(30 * time.Minute)
+	m.SyncResult(ctx, failed)
+	if len(rec.msgs) != 1 {
+		t.Fatalf("静默期内不应重复通知，got %d", len(rec.msgs))
+	}
+	*now = now.Add(31 * time.Minute)
+	m.SyncResult(ctx, failed)
+	if len(rec.msgs) != 2 {
+		t.Fatalf("静默期后应再次通知，got %d", len(rec.msgs))
+	}
+
+	// 恢复后发送一次恢复通知，计数清零
+	m.SyncResult(ctx, nil)
+	m.SyncResult(ctx, nil)
+	if len(rec.msgs) != 3 || rec.msgs[2].Level != notify.LevelResolved {
+		t.Fatalf("恢复时应只发送一次恢复通知，got %+v", rec.msgs)
+	}
+	m.SyncResult(ctx, failed)
+	if len(rec.msgs) != 3 {
+		t.Fatalf("恢复后需重新累计连续失败次数，got %d", len(rec.msgs))
+	}
+}
+
+func TestQualityResult(t *testing.T) {
+	rules := config.AlertConfig{QualityErrors: 100, QualityErrorJump: 30, StaleDays: 3, StaleRatio: 0.2, SilenceMinutes: 60}
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		prev  *QualitySnapshot
+		snap  QualitySnapshot
+		wants []string
+	}{
+		{"正常", nil, QualitySnapshot{Stocks: 100, Errors: 10, Stale: 5}, nil},
+		{"超过绝对阈值", nil, QualitySnapshot{Stocks: 100, Errors: 120}, []string{"数据质量 error 激增"}},
+		{"首次检查不比较增量", nil, QualitySnapshot{Stocks: 100, Errors: 50}, nil},
+		{"较上次激增", &QualitySnapshot{Stocks: 100, Errors: 10}, QualitySnapshot{Stocks: 100, Errors: 40}, []string{"数据质量 error 激增"}},
+		{"大面积滞后", nil, QualitySnapshot{Stocks: 100, Stale: 20}, []string{"全市场数据滞后"}},
+		{"没有股票", nil, QualitySnapshot{}, nil},
+	}
+	for _, tt := range tests {
+		m, rec, _ := newTestMonitor(rules)
+		if tt.prev != nil {
+			m.QualityResult(ctx, *tt.prev)
+		}
+		m.QualityResult(ctx, tt.snap)
+		var titles []string
+		for _, msg := range rec.msgs {
+			titles = append(titles, msg.Title)
+		}
+		if len(titles) != len(tt.wants) {
+			t.Errorf("%s: 通知 = %v, want %v", tt.name, titles, tt.wants)
+			continue
+		}
+		for i := range titles {
+			if titles[i] != tt.wants[i] {
+				t.Errorf("%s: 通知 = %v, want %v", tt.name, titles, tt.wants)
+			}
+		}
+	}
+}
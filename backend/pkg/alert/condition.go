@@ -0,0 +1,265 @@
+package alert
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 提醒规则条件 ============
+
+// 提醒条件类型：above/below 为静态阈值比较，cross/new 为由指标序列相邻K线得出的事件，
+// before_event 不依赖K线，由公司事件日历触发
+const (
+	ConditionAbove       = "above"        // 左侧高于右侧
+	ConditionBelow       = "below"        // 左侧低于右侧
+	ConditionCrossAbove  = "cross_above"  // 左侧由下向上穿越右侧，如 MACD 上穿信号线
+	ConditionCrossBelow  = "cross_below"  // 左侧由上向下穿越右侧
+	ConditionNewHigh     = "new_high"     // 左侧创最近 window 根K线新高，如 52 周新高
+	ConditionNewLow      = "new_low"      // 左侧创最近 window 根K线新低
+	ConditionBeforeEvent = "before_event" // 公司事件（left 为事件类型）前 window 天，如财报披露前 3 天
+)
+
+// 新高/新低的回看K线数
+const (
+	DefaultWindow = 250 // 约 52 周交易日
+	MaxWindow     = 1000
+)
+
+// 事件前提醒的提前天数
+const (
+	DefaultEventDays = 3
+	MaxEventDays     = 30
+)
+
+// conditionLabels 条件类型的中文描述
+var conditionLabels = map[string]string{
+	ConditionAbove:       "高于",
+	ConditionBelow:       "低于",
+	ConditionCrossAbove:  "上穿",
+	ConditionCrossBelow:  "下穿",
+	ConditionNewHigh:     "新高",
+	ConditionNewLow:      "新低",
+	ConditionBeforeEvent: "前",
+}
+
+// Condition 编译后的提醒条件，左右两侧为指标表达式（数值常量也是合法表达式）；before_event 的左侧为事件类型
+type Condition struct {
+	Kind   string
+	Left   string
+	Right  string // new_high、new_low、before_event 不使用
+	Window int    // new_high、new_low 为回看K线数，before_event 为提前天数
+
+	left, right *indicator.Expr
+}
+
+// Trigger 一次条件触发
+type Trigger struct {
+	Time      time.Time
+	Value     float64 // 触发时左侧的值
+	Reference float64 // 触发时右侧的值；新高/新低为此前 window 根K线的最高/最低值
+	Title     string  // 只用于 before_event：事件标题
+}
+
+// NewCondition 校验并编译提醒条件
+// new_high 省略左侧时取最高价、new_low 取最低价，window 为 0 时取 DefaultWindow；
+// before_event 的左侧为事件类型，window 为 0 时取 DefaultEventDays；其余类型左右两侧均必填。
+func NewCondition(kind, left, right string, window int) (*Condition, error) {
+	kind = strings.ToLower(strings.TrimSpace(kind))
+	left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+	c := &Condition{Kind: kind, Left: left, Right: right}
+
+	switch kind {
+	case ConditionBeforeEvent:
+		c.Left = strings.ToLower(left)
+		if _, ok := models.CorporateEventTypes[c.Left]; !ok {
+			return nil, fmt.Errorf("before_event 的 left 应为事件类型: %s", left)
+		}
+		if right != "" {
+			return nil, fmt.Errorf("%s 不需要比较对象 right", kind)
+		}
+		c.Window = window
+		if c.Window == 0 {
+			c.Window = DefaultEventDays
+		}
+		if c.Window < 1 || c.Window > MaxEventDays {
+			return nil, fmt.Errorf("window 应在 1-%d 之间", MaxEventDays)
+		}
+		return c, nil
+	case ConditionNewHigh, ConditionNewLow:
+		if right != "" {
+			return nil, fmt.Errorf("%s 不需要比较对象 right", kind)
+		}
+		if c.Left == "" {
+			c.Left = "HIGH"
+			if kind == ConditionNewLow {
+				c.Left = "LOW"
+			}
+		}
+		c.Window = window
+		if c.Window == 0 {
+			c.Window = DefaultWindow
+		}
+		if c.Window < 2 || c.Window > MaxWindow {
+			return nil, fmt.Errorf("window 应在 2-%d 之间", MaxWindow)
+		}
+	case ConditionAbove, ConditionBelow, ConditionCrossAbove, ConditionCrossBelow:
+		if left == "" || right == "" {
+			return nil, fmt.Errorf("%s 需要 left 与 right", kind)
+		}
+		expr, err := indicator.Compile(right)
+		if err != nil {
+			return nil, fmt.Errorf("right 表达式错误: %w", err)
+		}
+		c.right = expr
+	default:
+		return nil, fmt.Errorf("不支持的条件类型: %s", kind)
+	}
+
+	expr, err := indicator.Compile(c.Left)
+	if err != nil {
+		return nil, fmt.Errorf("left 表达式错误: %w", err)
+	}
+	c.left = expr
+	return c, nil
+}
+
+// IsEvent 是否为由公司事件日历触发的条件，此类条件不检查K线
+func (c *Condition) IsEvent() bool {
+	return c.Kind == ConditionBeforeEvent
+}
+
+// Lookback 检查一根K线前需要的历史K线数，事件类条件为 0
+func (c *Condition) Lookback() int {
+	if c.IsEvent() {
+		return 0
+	}
+	n := c.left.Lookback()
+	if c.right != nil {
+		n = max(n, c.right.Lookback())
+	}
+	switch c.Kind {
+	case ConditionCrossAbove, ConditionCrossBelow:
+		n++
+	case ConditionNewHigh, ConditionNewLow:
+		n += c.Window
+	}
+	return n
+}
+
+// Describe 条件的文字描述，如 "MA(5) 上穿 MA(20)"、"HIGH 创 250 日新高"
+func (c *Condition) Describe() string {
+	switch c.Kind {
+	case ConditionNewHigh, ConditionNewLow:
+		return fmt.Sprintf("%s 创 %d 日%s", c.Left, c.Window, conditionLabels[c.Kind])
+	case ConditionBeforeEvent:
+		return fmt.Sprintf("%s%s %d 天", models.CorporateEventTypes[c.Left], conditionLabels[c.Kind], c.Window)
+	}
+	return fmt.Sprintf("%s %s %s", c.Left, conditionLabels[c.Kind], c.Right)
+}
+
+// Scan 增量检查 after 之后的每根K线，按时间顺序返回触发记录
+// after 为零值（规则首次检查）时只检查最后一根K线，不回溯历史；序列需包含 Lookback 根预热K线，数据不足的K线不触发。
+func (c *Condition) Scan(s *indicator.Series, after time.Time) []Trigger {
+	n := s.Len()
+	if n == 0 || c.IsEvent() {
+		return nil
+	}
+	from := n - 1
+	if !after.IsZero() {
+		from = n
+		for i, t := range s.Time {
+			if t.After(after) {
+				from = i
+				break
+			}
+		}
+	}
+	if from >= n {
+		return nil
+	}
+
+	left := c.left.Eval(s)
+	var right []float64
+	if c.right != nil {
+		right = c.right.Eval(s)
+	}
+
+	var out []Trigger
+	for i := from; i < n; i++ {
+		if ref, ok := c.check(left, right, i); ok {
+			out = append(out, Trigger{Time: s.Time[i], Value: left[i], Reference: ref})
+		}
+	}
+	return out
+}
+
+// Upcoming 检查 today 起 window 天内（含当天）的公司事件，按事件日期返回触发记录
+// Trigger.Time 为事件日期，Value 为剩余天数，Reference 为提前天数；同一事件每天检查都会返回，由调用方按事件日期去重。
+func (c *Condition) Upcoming(events []*models.CorporateEvent, today time.Time) []Trigger {
+	if !c.IsEvent() {
+		return nil
+	}
+	var out []Trigger
+	for _, e := range events {
+		if e.EventType != c.Left {
+			continue
+		}
+		days := int(math.Round(e.EventDate.Sub(today).Hours() / 24))
+		if days < 0 || days > c.Window {
+			continue
+		}
+		out = append(out, Trigger{Time: e.EventDate, Value: float64(days), Reference: float64(c.Window), Title: e.Title})
+	}
+	return out
+}
+
+// check 第 i 根K线是否满足条件，返回比较基准
+func (c *Condition) check(left, right []float64, i int) (float64, bool) {
+	if !valid(left[i]) {
+		return 0, false
+	}
+	switch c.Kind {
+	case ConditionAbove:
+		return right[i], valid(right[i]) && left[i] > right[i]
+	case ConditionBelow:
+		return right[i], valid(right[i]) && left[i] < right[i]
+	case ConditionCrossAbove, ConditionCrossBelow:
+		if i == 0 || !valid(right[i]) || !valid(left[i-1]) || !valid(right[i-1]) {
+			return 0, false
+		}
+		if c.Kind == ConditionCrossAbove {
+			return right[i], left[i-1] <= right[i-1] && left[i] > right[i]
+		}
+		return right[i], left[i-1] >= right[i-1] && left[i] < right[i]
+	case ConditionNewHigh, ConditionNewLow:
+		if i < c.Window {
+			return 0, false
+		}
+		ref := left[i-c.Window]
+		for _, v := range left[i-c.Window : i] {
+			if !valid(v) {
+				return 0, false
+			}
+			if c.Kind == ConditionNewHigh {
+				ref = math.Max(ref, v)
+			} else {
+				ref = math.Min(ref, v)
+			}
+		}
+		if c.Kind == ConditionNewHigh {
+			return ref, left[i] > ref
+		}
+		return ref, left[i] < ref
+	}
+	return 0, false
+}
+
+// valid 值是否可用于比较
+func valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
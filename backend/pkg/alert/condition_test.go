@@ -0,0 +1,160 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// closeSeries 由收盘价构建日K线序列，最高价、最低价与收盘价相同
+func closeSeries(closes ...float64) *indicator.Series {
+	s := &indicator.Series{}
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		s.Append(day.AddDate(0, 0, i), c, c, c, c, 1000, c*1000)
+	}
+	return s
+}
+
+func TestNewCondition(t *testing.T) {
+	c, err := NewCondition("NEW_HIGH", "", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Kind != ConditionNewHigh || c.Left != "HIGH" || c.Window != DefaultWindow {
+		t.Fatalf("new_high 默认值错误: %+v", c)
+	}
+	if c.Lookback() != DefaultWindow {
+		t.Fatalf("Lookback = %d, want %d", c.Lookback(), DefaultWindow)
+	}
+
+	c, err = NewCondition(ConditionCrossAbove, "MA(5)", "MA(20)", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Lookback() != 20 {
+		t.Fatalf("Lookback = %d, want 20", c.Lookback())
+	}
+	if got := c.Describe(); got != "MA(5) 上穿 MA(20)" {
+		t.Fatalf("Describe = %q", got)
+	}
+
+	for _, tc := range []struct{ kind, left, right string }{
+		{"unknown", "CLOSE", "1"},
+		{ConditionCrossAbove, "CLOSE", ""},
+		{ConditionAbove, "FOO(", "1"},
+		{ConditionNewLow, "CLOSE", "MA(5)"},
+	} {
+		if _, err := NewCondition(tc.kind, tc.left, tc.right, 0); err == nil {
+			t.Errorf("%+v 应返回错误", tc)
+		}
+	}
+	if _, err := NewCondition(ConditionNewHigh, "", "", MaxWindow+1); err == nil {
+		t.Error("window 超出上限应返回错误")
+	}
+}
+
+func TestScanCross(t *testing.T) {
+	c, err := NewCondition(ConditionCrossAbove, "CLOSE", "10", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := closeSeries(9, 11, 12, 9, 10, 11)
+
+	// 增量检查：第 2 根与第 6 根上穿；等于阈值不算上穿
+	got := c.Scan(s, s.Time[0])
+	if len(got) != 2 || !got[0].Time.Equal(s.Time[1]) || !got[1].Time.Equal(s.Time[5]) {
+		t.Fatalf("上穿事件错误: %+v", got)
+	}
+	if got[0].Value != 11 || got[0].Reference != 10 {
+		t.Fatalf("触发值错误: %+v", got[0])
+	}
+
+	// 游标之后没有新K线时不重复触发
+	if got := c.Scan(s, s.Time[5]); len(got) != 0 {
+		t.Fatalf("已检查的K线不应再次触发: %+v", got)
+	}
+
+	// 首次检查只看最后一根K线
+	if got := c.Scan(s, time.Time{}); len(got) != 1 || !got[0].Time.Equal(s.Time[5]) {
+		t.Fatalf("首次检查应只检查最后一根K线: %+v", got)
+	}
+
+	below, _ := NewCondition(ConditionCrossBelow, "CLOSE", "10", 0)
+	if got := below.Scan(s, s.Time[0]); len(got) != 1 || !got[0].Time.Equal(s.Time[3]) {
+		t.Fatalf("下穿事件错误: %+v", got)
+	}
+}
+
+func TestScanThreshold(t *testing.T) {
+	c, err := NewCondition(ConditionAbove, "CLOSE", "10", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := closeSeries(9, 11, 12, 9)
+	// 阈值条件在每根满足的K线上触发
+	if got := c.Scan(s, s.Time[0]); len(got) != 2 {
+		t.Fatalf("阈值条件应触发 2 次: %+v", got)
+	}
+}
+
+func TestScanNewHigh(t *testing.T) {
+	c, err := NewCondition(ConditionNewHigh, "CLOSE", "", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := closeSeries(10, 12, 11, 12, 13, 12)
+	got := c.Scan(s, s.Time[0])
+	// 前 3 根数据不足；第 4 根 12 不高于此前最高 12；第 5 根 13 创新高
+	if len(got) != 1 || !got[0].Time.Equal(s.Time[4]) || got[0].Reference != 12 {
+		t.Fatalf("新高事件错误: %+v", got)
+	}
+
+	low, _ := NewCondition(ConditionNewLow, "CLOSE", "", 2)
+	if got := low.Scan(closeSeries(10, 9, 8, 9), time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)); len(got) != 1 || got[0].Value != 8 {
+		t.Fatalf("新低事件错误: %+v", got)
+	}
+}
+
+func TestUpcoming(t *testing.T) {
+	c, err := NewCondition(ConditionBeforeEvent, "EARNINGS", "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Left != "earnings" || c.Window != DefaultEventDays || c.Lookback() != 0 {
+		t.Fatalf("before_event 默认值错误: %+v", c)
+	}
+	if got := c.Describe(); got != "定期报告披露前 3 天" {
+		t.Fatalf("Describe = %q", got)
+	}
+	if c.Scan(closeSeries(1, 2, 3), time.Time{}) != nil {
+		t.Fatal("事件类条件不应检查K线")
+	}
+
+	today := time.Date(2024, 4, 20, 0, 0, 0, 0, time.UTC)
+	events := []*models.CorporateEvent{
+		{EventType: models.EventEarnings, EventDate: today.AddDate(0, 0, -1), Title: "已过去"},
+		{EventType: models.EventEarnings, EventDate: today.AddDate(0, 0, 2), Title: "2024年一季报"},
+		{EventType: models.EventLockupExpiry, EventDate: today.AddDate(0, 0, 1), Title: "其他类型"},
+		{EventType: models.EventEarnings, EventDate: today.AddDate(0, 0, 4), Title: "超出提前天数"},
+	}
+	got := c.Upcoming(events, today)
+	if len(got) != 1 || got[0].Title != "2024年一季报" || got[0].Value != 2 || !got[0].Time.Equal(events[1].EventDate) {
+		t.Fatalf("Upcoming = %+v", got)
+	}
+
+	for _, tc := range []struct {
+		left, right string
+		window      int
+	}{
+		{"dividend", "", 0},
+		{models.EventEarnings, "1", 0},
+		{models.EventEarnings, "", MaxEventDays + 1},
+	} {
+		if _, err := NewCondition(ConditionBeforeEvent, tc.left, tc.right, tc.window); err == nil {
+			t.Errorf("%+v 应返回错误", tc)
+		}
+	}
+}
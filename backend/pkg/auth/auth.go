@@ -0,0 +1,158 @@
+// Package auth JWT 的签发与校验，各服务共用同一套密钥配置（config.AuthConfig）。
+// 支持按 kid 轮换密钥：新 Token 使用当前密钥签发并在头部写入 kid，校验时按 kid 查找密钥，轮换前签发的 Token 在过期前仍然有效。
+// 使用 RS256 时只有签发 Token 的服务需要私钥，其余服务只配置公钥。
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+const (
+	// Issuer Token 的签发方
+	Issuer = "stock-analysis-system"
+	// TokenTTL Token 有效期
+	TokenTTL = 24 * time.Hour
+)
+
+// ErrCannotIssue 只配置了公钥，不能签发 Token
+var ErrCannotIssue = errors.New("未配置签名密钥，不能签发 Token")
+
+// Claims JWT声明
+type Claims struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// key 一个 kid 对应的密钥
+type key struct {
+	method    jwt.SigningMethod
+	signKey   interface{} // []byte 或 *rsa.PrivateKey，只能校验时为 nil
+	verifyKey interface{} // []byte 或 *rsa.PublicKey
+}
+
+// KeySet 签发与校验 Token 的密钥集合
+type KeySet struct {
+	currentID string
+	keys      map[string]*key
+	methods   []string // 校验时接受的签名算法
+}
+
+// LoadKeySet 校验密钥配置后创建密钥集合，各服务启动时调用
+// 以示例密钥运行时任何人都能伪造 Token，release 模式下拒绝启动。
+func LoadKeySet(cfg config.AuthConfig, mode string) (*KeySet, error) {
+	if err := cfg.Validate(mode); err != nil {
+		return nil, err
+	}
+	return NewKeySet(cfg)
+}
+
+// NewKeySet 按配置创建密钥集合
+func NewKeySet(cfg config.AuthConfig) (*KeySet, error) {
+	s := &KeySet{currentID: cfg.KeyID, keys: make(map[string]*key)}
+
+	switch cfg.Algorithm {
+	case "", "HS256":
+		s.methods = []string{jwt.SigningMethodHS256.Alg()}
+		if cfg.JWTSecret == "" {
+			return nil, errors.New("未配置 JWT_SECRET")
+		}
+		s.keys[cfg.KeyID] = &key{method: jwt.SigningMethodHS256, signKey: []byte(cfg.JWTSecret), verifyKey: []byte(cfg.JWTSecret)}
+		for kid, secret := range cfg.PreviousSecrets {
+			if kid == cfg.KeyID {
+				return nil, fmt.Errorf("已轮换的密钥 %s 与当前 kid 相同", kid)
+			}
+			s.keys[kid] = &key{method: jwt.SigningMethodHS256, verifyKey: []byte(secret)}
+		}
+
+	case "RS256":
+		s.methods = []string{jwt.SigningMethodRS256.Alg()}
+		for kid, pem := range cfg.PublicKeys {
+			pub, err := jwt.ParseRSAPublicKeyFromPEM([]byte(pem))
+			if err != nil {
+				return nil, fmt.Errorf("解析公钥 %s 失败: %w", kid, err)
+			}
+			s.keys[kid] = &key{method: jwt.SigningMethodRS256, verifyKey: pub}
+		}
+		if cfg.PrivateKey != "" {
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(cfg.PrivateKey))
+			if err != nil {
+				return nil, fmt.Errorf("解析私钥失败: %w", err)
+			}
+			if k, ok := s.keys[cfg.KeyID]; ok && !k.verifyKey.(*rsa.PublicKey).Equal(&priv.PublicKey) {
+				return nil, fmt.Errorf("公钥 %s 与私钥不匹配", cfg.KeyID)
+			}
+			s.keys[cfg.KeyID] = &key{method: jwt.SigningMethodRS256, signKey: priv, verifyKey: &priv.PublicKey}
+		}
+		if len(s.keys) == 0 {
+			return nil, errors.New("RS256 需配置私钥或公钥")
+		}
+
+	default:
+		return nil, fmt.Errorf("不支持的签名算法: %s", cfg.Algorithm)
+	}
+	return s, nil
+}
+
+// CanIssue 是否持有当前 kid 的签名密钥
+func (s *KeySet) CanIssue() bool {
+	k, ok := s.keys[s.currentID]
+	return ok && k.signKey != nil
+}
+
+// Issue 以当前密钥签发 Token
+func (s *KeySet) Issue(userID uint, username string) (string, error) {
+	k, ok := s.keys[s.currentID]
+	if !ok || k.signKey == nil {
+		return "", ErrCannotIssue
+	}
+
+	now := time.Now()
+	claims := Claims{
+		UserID:   userID,
+		Username: username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    Issuer,
+		},
+	}
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = s.currentID
+	return token.SignedString(k.signKey)
+}
+
+// Parse 解析并校验 Token
+// 按头部的 kid 选择密钥，签名算法必须与该密钥一致；没有 kid 的 Token（引入轮换前签发）使用当前密钥校验。
+// 签发方必须为 Issuer，签名算法只接受配置的算法（拒绝 none 等）。
+func (s *KeySet) Parse(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = s.currentID
+		}
+		k, ok := s.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("未知的密钥: %s", kid)
+		}
+		if token.Method.Alg() != k.method.Alg() {
+			return nil, fmt.Errorf("不支持的签名算法: %v", token.Header["alg"])
+		}
+		return k.verifyKey, nil
+	}, jwt.WithIssuer(Issuer), jwt.WithValidMethods(s.methods))
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
+		return claims, nil
+	}
+	return nil, errors.New("invalid token")
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+var (
+	oldSecret = strings.Repeat("o", 32)
+	newSecret = strings.Repeat("n", 32)
+)
+
+func TestKeyRotation(t *testing.T) {
+	oldKeys, err := NewKeySet(config.AuthConfig{KeyID: "k1", JWTSecret: oldSecret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, err := oldKeys.Issue(7, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 轮换：k2 为当前密钥，k1 只用于校验
+	keys, err := NewKeySet(config.AuthConfig{KeyID: "k2", JWTSecret: newSecret, PreviousSecrets: map[string]string{"k1": oldSecret}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := keys.Parse(oldToken)
+	if err != nil || claims.UserID != 7 || claims.Username != "alice" {
+		t.Fatalf("轮换前签发的 Token 应仍然有效，实际 %+v %v", claims, err)
+	}
+
+	newToken, err := keys.Issue(8, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, _ := jwt.NewParser().ParseUnverified(newToken, &Claims{})
+	if token.Header["kid"] != "k2" {
+		t.Fatalf("新 Token 应使用当前 kid，实际 %v", token.Header["kid"])
+	}
+	if _, err := oldKeys.Parse(newToken); err == nil {
+		t.Fatal("未配置 k2 的服务不应接受新密钥签发的 Token")
+	}
+
+	// 删除旧密钥后旧 Token 失效
+	keys, _ = NewKeySet(config.AuthConfig{KeyID: "k2", JWTSecret: newSecret})
+	if _, err := keys.Parse(oldToken); err == nil {
+		t.Fatal("旧密钥删除后旧 Token 应失效")
+	}
+}
+
+func TestLegacyTokenWithoutKid(t *testing.T) {
+	legacy, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID:           1,
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)), Issuer: Issuer},
+	}).SignedString([]byte(newSecret))
+
+	keys, _ := NewKeySet(config.AuthConfig{KeyID: "default", JWTSecret: newSecret})
+	if claims, err := keys.Parse(legacy); err != nil || claims.UserID != 1 {
+		t.Fatalf("没有 kid 的 Token 应使用当前密钥校验，实际 %+v %v", claims, err)
+	}
+}
+
+func TestRS256(t *testing.T) {
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	privPEM := string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(priv)}))
+	pubDER, _ := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	pubPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}))
+
+	issuer, err := NewKeySet(config.AuthConfig{Algorithm: "RS256", KeyID: "rsa1", PrivateKey: privPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := issuer.Issue(3, "carol")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 只配置公钥的服务可以校验但不能签发
+	verifier, err := NewKeySet(config.AuthConfig{Algorithm: "RS256", KeyID: "rsa1", PublicKeys: map[string]string{"rsa1": pubPEM}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims, err := verifier.Parse(token); err != nil || claims.UserID != 3 {
+		t.Fatalf("公钥应能校验 RS256 Token，实际 %+v %v", claims, err)
+	}
+	if verifier.CanIssue() {
+		t.Fatal("只有公钥时不应能签发")
+	}
+	if _, err := verifier.Issue(3, "carol"); err != ErrCannotIssue {
+		t.Fatalf("只有公钥时签发应返回 ErrCannotIssue，实际 %v", err)
+	}
+
+	// 以公钥内容作为 HMAC 密钥伪造的 Token 不应通过
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1})
+	forged.Header["kid"] = "rsa1"
+	forgedToken, _ := forged.SignedString([]byte(pubPEM))
+	if _, err := verifier.Parse(forgedToken); err == nil {
+		t.Fatal("签名算法与密钥不一致的 Token 应被拒绝")
+	}
+}
+
+// 签发方不符、签名算法不在配置之列的 Token 即使密钥正确也被拒绝
+func TestParseRejectsIssuerAndMethod(t *testing.T) {
+	keys, _ := NewKeySet(config.AuthConfig{KeyID: "k1", JWTSecret: newSecret})
+	expires := jwt.NewNumericDate(time.Now().Add(time.Hour))
+	sign := func(method jwt.SigningMethod, issuer string, key interface{}) string {
+		token := jwt.NewWithClaims(method, Claims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: expires, Issuer: issuer}})
+		token.Header["kid"] = "k1"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	if _, err := keys.Parse(sign(jwt.SigningMethodHS256, Issuer, []byte(newSecret))); err != nil {
+		t.Fatalf("合法 Token 被拒绝: %v", err)
+	}
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"其他签发方", sign(jwt.SigningMethodHS256, "other-system", []byte(newSecret))},
+		{"缺少签发方", sign(jwt.SigningMethodHS256, "", []byte(newSecret))},
+		{"HS512", sign(jwt.SigningMethodHS512, Issuer, []byte(newSecret))},
+		{"none", sign(jwt.SigningMethodNone, Issuer, jwt.UnsafeAllowNoneSignatureType)},
+	}
+	for _, tt := range tests {
+		if _, err := keys.Parse(tt.token); err == nil {
+			t.Errorf("%s 的 Token 应被拒绝", tt.name)
+		}
+	}
+}
+
+func TestLoadKeySet(t *testing.T) {
+	if _, err := LoadKeySet(config.AuthConfig{KeyID: "k1", JWTSecret: "short"}, "release"); err == nil {
+		t.Error("release 模式下过短的密钥应拒绝启动")
+	}
+	keys, err := LoadKeySet(config.AuthConfig{KeyID: "k1", JWTSecret: newSecret}, "release")
+	if err != nil || !keys.CanIssue() {
+		t.Fatalf("LoadKeySet = %v, %v", keys, err)
+	}
+}
@@ -0,0 +1,425 @@
+// Package barimport 解析离线K线文件：通达信导出的 txt/csv、tushare 导出的 csv 以及带表头的通用 OHLCV 表格。
+package barimport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/quality"
+)
+
+// 文件格式
+const (
+	FormatAuto    = ""        // 按内容识别
+	FormatTDX     = "tdx"     // 通达信导出：首行为代码名称，其后为表头与数据，末行为“数据来源”
+	FormatTushare = "tushare" // tushare daily 接口导出：vol 单位为手，amount 单位为千元
+	FormatGeneric = "generic" // 带表头的通用格式，成交量单位为股，成交额单位为元
+)
+
+const (
+	IntervalDaily = "1d"
+
+	// MaxFileSize 单个文件大小上限
+	MaxFileSize = 32 << 20
+	// MaxFileRows 单个文件允许的最大数据行数
+	MaxFileRows = 200000
+)
+
+// Options 解析参数，Symbol/Exchange/Interval 为空时从文件名、首行或表头推断
+type Options struct {
+	Format   string
+	FileName string
+	Symbol   string
+	Exchange string
+	Interval string // 1d 或 1m/5m/15m/30m/60m
+}
+
+// RowError 行级错误，Row 为文件中的行号（从1开始）
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// Result 单个文件的解析结果，日线写入 DailyBars，分钟线写入 MinuteBars
+type Result struct {
+	Format     string
+	Interval   string
+	DailyBars  []*models.DailyBar
+	MinuteBars []*models.MinuteBar
+	Errors     []*RowError
+}
+
+// Rows 解析成功的行数
+func (r *Result) Rows() int {
+	return len(r.DailyBars) + len(r.MinuteBars)
+}
+
+// 表头别名 -> 标准列名
+var headerAliases = map[string]string{
+	"date": "date", "trade_date": "date", "trade_time": "date", "datetime": "date", "日期": "date", "交易日期": "date",
+	"time": "time", "时间": "time",
+	"symbol": "symbol", "code": "symbol", "ts_code": "symbol", "代码": "symbol", "股票代码": "symbol",
+	"exchange": "exchange", "market": "exchange", "交易所": "exchange",
+	"open": "open", "开盘": "open", "开盘价": "open",
+	"high": "high", "最高": "high", "最高价": "high",
+	"low": "low", "最低": "low", "最低价": "low",
+	"close": "close", "收盘": "close", "收盘价": "close",
+	"volume": "volume", "vol": "volume", "成交量": "volume",
+	"amount": "amount", "成交额": "amount", "成交金额": "amount",
+}
+
+// positionalColumns 通达信不带表头导出时的列顺序
+var (
+	positionalDaily  = []string{"date", "open", "high", "low", "close", "volume", "amount"}
+	positionalMinute = []string{"date", "time", "open", "high", "low", "close", "volume", "amount"}
+)
+
+// tdxIntervals 通达信首行中的周期名称
+var tdxIntervals = []struct{ name, interval string }{
+	{"60分钟", "60m"}, {"30分钟", "30m"}, {"15分钟", "15m"}, {"5分钟", "5m"}, {"1分钟", "1m"}, {"日线", IntervalDaily},
+}
+
+var (
+	symbolPattern   = regexp.MustCompile(`^\d{6}$`)
+	fileNamePattern = regexp.MustCompile(`(?i)^(sh|sz|bj)#?(\d{6})|^(\d{6})[._]?(sh|sz|bj)?`)
+	dateLayouts     = []string{"2006-01-02", "2006/01/02", "20060102", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006/01/02 15:04"}
+)
+
+// Parse 解析一个K线文件
+// 非 UTF-8 内容按 GB18030 解码（通达信默认导出编码）；分隔符为逗号或制表符。
+// 返回解析成功的K线与行级错误，价格逻辑按 quality 包的规则校验；文件格式无法识别时返回 error。
+func Parse(r io.Reader, opts Options) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %w", err)
+	}
+	if len(data) > MaxFileSize {
+		return nil, fmt.Errorf("文件超过 %dMB", MaxFileSize>>20)
+	}
+	if !utf8.Valid(data) {
+		if data, err = simplifiedchinese.GB18030.NewDecoder().Bytes(data); err != nil {
+			return nil, fmt.Errorf("文件编码无法识别: %w", err)
+		}
+	}
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+
+	p := &parser{opts: opts, result: &Result{Format: opts.Format, Interval: opts.Interval}}
+	p.symbolFromFileName()
+	if err := p.parse(string(data)); err != nil {
+		return nil, err
+	}
+	if p.result.Format == FormatAuto {
+		p.result.Format = FormatGeneric
+	}
+	return p.result, nil
+}
+
+var errNeedInterval = errors.New("文件包含分钟数据，需要指定 interval")
+
+type parser struct {
+	opts    Options
+	result  *Result
+	columns map[string]int
+}
+
+// symbolFromFileName 从 SH#600000.txt、sz000001.csv、600000.SH.csv 等文件名推断代码与交易所
+func (p *parser) symbolFromFileName() {
+	if p.opts.Symbol != "" || p.opts.FileName == "" {
+		return
+	}
+	base := filepath.Base(p.opts.FileName)
+	m := fileNamePattern.FindStringSubmatch(base)
+	if m == nil {
+		return
+	}
+	if m[2] != "" {
+		p.opts.Symbol, p.opts.Exchange = m[2], firstNonEmpty(p.opts.Exchange, strings.ToUpper(m[1]))
+	} else {
+		p.opts.Symbol, p.opts.Exchange = m[3], firstNonEmpty(p.opts.Exchange, strings.ToUpper(m[4]))
+	}
+}
+
+func (p *parser) parse(content string) error {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start == len(lines) {
+		return errors.New("文件为空")
+	}
+
+	// 通达信首行：600000 浦发银行 日线 前复权
+	if fields := strings.Fields(lines[start]); len(fields) >= 2 && symbolPattern.MatchString(fields[0]) && !strings.ContainsAny(lines[start], ",\t") {
+		if p.opts.Symbol == "" {
+			p.opts.Symbol = fields[0]
+		}
+		for _, t := range tdxIntervals {
+			if p.result.Interval == "" && strings.Contains(lines[start], t.name) {
+				p.result.Interval = t.interval
+			}
+		}
+		if p.result.Format == FormatAuto {
+			p.result.Format = FormatTDX
+		}
+		start++
+	}
+	if start == len(lines) {
+		return errors.New("文件没有数据行")
+	}
+
+	comma := ','
+	if strings.Count(lines[start], "\t") > strings.Count(lines[start], ",") {
+		comma = '\t'
+	}
+	reader := csv.NewReader(strings.NewReader(strings.Join(lines[start:], "\n")))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				p.rowError(parseErr.StartLine+start, parseErr.Err.Error())
+				continue
+			}
+			return fmt.Errorf("读取文件失败: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		line += start
+		if isBlank(record) || strings.HasPrefix(strings.TrimSpace(record[0]), "数据来源") {
+			continue
+		}
+		if first {
+			first = false
+			if err := p.detectColumns(record); err != nil {
+				return err
+			}
+			if p.columns != nil {
+				continue // 表头行
+			}
+			p.positionalColumns(record)
+		}
+		if p.result.Rows()+len(p.result.Errors) >= MaxFileRows {
+			return fmt.Errorf("单个文件最多 %d 行", MaxFileRows)
+		}
+		if err := p.parseRecord(record); err != nil {
+			if errors.Is(err, errNeedInterval) {
+				return err
+			}
+			p.rowError(line, err.Error())
+		}
+	}
+	if p.columns == nil {
+		return errors.New("文件没有数据行")
+	}
+	return nil
+}
+
+// detectColumns 识别表头；首行不是表头时 columns 保持为 nil
+func (p *parser) detectColumns(record []string) error {
+	columns := make(map[string]int)
+	tushare := false
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if col, ok := headerAliases[name]; ok {
+			if _, dup := columns[col]; !dup {
+				columns[col] = i
+			}
+		}
+		if name == "ts_code" || name == "trade_date" {
+			tushare = true
+		}
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+	for _, col := range []string{"date", "open", "high", "low", "close"} {
+		if _, ok := columns[col]; !ok {
+			return fmt.Errorf("缺少必需列: %s", col)
+		}
+	}
+	if tushare && p.result.Format == FormatAuto {
+		p.result.Format = FormatTushare
+	}
+	p.columns = columns
+	return nil
+}
+
+// positionalColumns 无表头时按通达信列顺序解析，8 列及以上视为带时间列的分钟数据
+func (p *parser) positionalColumns(record []string) {
+	order := positionalDaily
+	if len(record) >= len(positionalMinute) {
+		order = positionalMinute
+	}
+	p.columns = make(map[string]int, len(order))
+	for i, col := range order {
+		p.columns[col] = i
+	}
+}
+
+// parseRecord 解析一行数据
+func (p *parser) parseRecord(record []string) error {
+	field := func(col string) string {
+		i, ok := p.columns[col]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	symbol, exchange := field("symbol"), strings.ToUpper(field("exchange"))
+	if i := strings.LastIndex(symbol, "."); i > 0 {
+		symbol, exchange = symbol[:i], firstNonEmpty(exchange, strings.ToUpper(symbol[i+1:]))
+	}
+	symbol = firstNonEmpty(symbol, p.opts.Symbol)
+	exchange = firstNonEmpty(exchange, strings.ToUpper(p.opts.Exchange), InferExchange(symbol))
+	if symbol == "" || exchange == "" {
+		return errors.New("缺少股票代码或交易所")
+	}
+
+	at, hasTime, err := parseTime(field("date"), field("time"), exchange)
+	if err != nil {
+		return err
+	}
+
+	var values [6]float64
+	for i, col := range []string{"open", "high", "low", "close", "volume", "amount"} {
+		v := field(col)
+		if v == "" && (col == "volume" || col == "amount") {
+			continue
+		}
+		if values[i], err = strconv.ParseFloat(strings.ReplaceAll(v, ",", ""), 64); err != nil {
+			return fmt.Errorf("%s 不是数字: %s", col, v)
+		}
+	}
+	volume, amount := values[4], values[5]
+	if p.result.Format == FormatTushare {
+		volume, amount = volume*100, amount*1000
+	}
+
+	interval := p.result.Interval
+	if interval == "" {
+		interval = IntervalDaily
+		if hasTime {
+			return errNeedInterval
+		}
+	}
+	if interval == IntervalDaily {
+		bar := &models.DailyBar{
+			Symbol: symbol, Exchange: exchange, Date: at,
+			Open: values[0], High: values[1], Low: values[2], Close: values[3],
+			Volume: int64(volume), Amount: amount,
+		}
+		if err := quality.ValidateBarData(bar); err != nil {
+			return err
+		}
+		p.result.Interval = interval
+		p.result.DailyBars = append(p.result.DailyBars, bar)
+		return nil
+	}
+
+	bar := &models.MinuteBar{
+		Symbol: symbol, Exchange: exchange, Interval: interval, Time: at,
+		Open: values[0], High: values[1], Low: values[2], Close: values[3],
+		Volume: int64(volume), Amount: amount,
+	}
+	if err := quality.ValidateMinuteBar(bar); err != nil {
+		return err
+	}
+	p.result.Interval = interval
+	p.result.MinuteBars = append(p.result.MinuteBars, bar)
+	return nil
+}
+
+func (p *parser) rowError(row int, msg string) {
+	p.result.Errors = append(p.result.Errors, &RowError{Row: row, Error: msg})
+}
+
+// parseTime 解析日期与可选的时间列（通达信分钟线为 0935 或 09:35）
+// 带时刻的时间按交易所时区解析；只有日期时按 UTC 零点表示交易日，与同步的日K线一致。
+func parseTime(date, clock, exchange string) (time.Time, bool, error) {
+	var at time.Time
+	var err error
+	for _, layout := range dateLayouts {
+		if at, err = time.ParseInLocation(layout, date, markettime.Location(exchange)); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("日期格式错误: %s", date)
+	}
+	hasTime := at.Hour() != 0 || at.Minute() != 0
+	if clock != "" {
+		clock = strings.ReplaceAll(clock, ":", "")
+		if len(clock) == 3 {
+			clock = "0" + clock
+		}
+		hm, err := time.Parse("1504", clock)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("时间格式错误: %s", clock)
+		}
+		at = at.Add(time.Duration(hm.Hour())*time.Hour + time.Duration(hm.Minute())*time.Minute)
+		hasTime = true
+	}
+	if at.After(time.Now()) {
+		return time.Time{}, false, fmt.Errorf("日期不能晚于今天: %s", date)
+	}
+	if !hasTime {
+		at = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return at, hasTime, nil
+}
+
+// InferExchange 按A股代码段推断交易所：6/9/5 开头为上交所，0/1/2/3 开头为深交所，4/8 开头为北交所
+func InferExchange(symbol string) string {
+	if !symbolPattern.MatchString(symbol) {
+		return ""
+	}
+	switch symbol[0] {
+	case '5', '6', '9':
+		return "SH"
+	case '0', '1', '2', '3':
+		return "SZ"
+	case '4', '8':
+		return "BJ"
+	}
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
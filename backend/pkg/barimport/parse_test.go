@@ -0,0 +1,108 @@
+package barimport
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestParseTDXDaily(t *testing.T) {
+	data := "600000 浦发银行 日线 前复权\r\n" +
+		"      日期\t    开盘\t    最高\t    最低\t    收盘\t    成交量\t    成交额\r\n" +
+		"2024/01/02\t6.60\t6.65\t6.55\t6.58\t30000000\t197000000.00\r\n" +
+		"2024/01/03\t6.58\t6.50\t6.55\t6.52\t28000000\t183000000.00\r\n" +
+		"2024/01/04\t6.52\t6.60\t6.50\t6.59\t25000000\t164000000.00\r\n" +
+		"数据来源:通达信\r\n"
+	gbk, err := simplifiedchinese.GBK.NewEncoder().Bytes([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Parse(bytes.NewReader(gbk), Options{})
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if result.Format != FormatTDX || result.Interval != IntervalDaily {
+		t.Errorf("格式识别错误: format=%s interval=%s", result.Format, result.Interval)
+	}
+	if len(result.DailyBars) != 2 {
+		t.Fatalf("期望解析 2 行，实际 %d", len(result.DailyBars))
+	}
+	bar := result.DailyBars[0]
+	if bar.Symbol != "600000" || bar.Exchange != "SH" || bar.Close != 6.58 || bar.Volume != 30000000 {
+		t.Errorf("第3行解析错误: %+v", bar)
+	}
+	// 第4行最高价低于开盘价
+	if len(result.Errors) != 1 || result.Errors[0].Row != 4 {
+		t.Errorf("期望第4行校验失败，实际: %+v", result.Errors)
+	}
+}
+
+func TestParseTDXMinuteWithoutHeader(t *testing.T) {
+	data := "2024/01/02,0935,10.00,10.10,9.95,10.05,120000,1206000\n" +
+		"2024/01/02,0940,10.05,10.08,10.00,10.02,80000,802000\n"
+
+	if _, err := Parse(strings.NewReader(data), Options{FileName: "SZ#000001.txt"}); err == nil {
+		t.Fatal("分钟数据未指定周期时应返回错误")
+	}
+
+	result, err := Parse(strings.NewReader(data), Options{FileName: "SZ#000001.txt", Interval: "5m"})
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(result.MinuteBars) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("期望解析 2 根分钟K线，实际 %d，错误 %+v", len(result.MinuteBars), result.Errors)
+	}
+	bar := result.MinuteBars[0]
+	if bar.Symbol != "000001" || bar.Exchange != "SZ" || bar.Interval != "5m" || bar.Time.Hour() != 9 || bar.Time.Minute() != 35 {
+		t.Errorf("分钟K线解析错误: %+v", bar)
+	}
+	// 分钟时间按交易所时区（北京时间）解析，与服务器时区无关
+	if want := time.Date(2024, 1, 2, 1, 35, 0, 0, time.UTC); !bar.Time.Equal(want) {
+		t.Errorf("分钟K线时间 = %v，应为 %v", bar.Time, want)
+	}
+}
+
+func TestParseTushare(t *testing.T) {
+	data := "\ufeff,ts_code,trade_date,open,high,low,close,pre_close,change,pct_chg,vol,amount\n" +
+		"0,000001.SZ,20240103,9.19,9.22,9.15,9.20,9.21,-0.01,-0.11,1000.5,920.3\n" +
+		"1,600519.SH,20240103,1700,1710,1690,1705,1695,10,0.59,200,34000\n" +
+		"2,600036.SH,2024-13-01,30,31,29,30,30,0,0,100,3000\n"
+
+	result, err := Parse(strings.NewReader(data), Options{})
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if result.Format != FormatTushare {
+		t.Errorf("期望识别为 tushare，实际 %s", result.Format)
+	}
+	if len(result.DailyBars) != 2 || len(result.Errors) != 1 {
+		t.Fatalf("期望 2 行成功 1 行失败，实际 %d/%d", len(result.DailyBars), len(result.Errors))
+	}
+	bar := result.DailyBars[0]
+	if bar.Symbol != "000001" || bar.Exchange != "SZ" || bar.Volume != 100050 || bar.Amount != 920300 {
+		t.Errorf("tushare 单位换算错误: %+v", bar)
+	}
+	if result.DailyBars[1].Exchange != "SH" {
+		t.Errorf("交易所解析错误: %+v", result.DailyBars[1])
+	}
+}
+
+func TestParseMissingColumn(t *testing.T) {
+	_, err := Parse(strings.NewReader("date,open,high,close\n2024-01-02,1,2,1.5\n"), Options{Symbol: "600000"})
+	if err == nil || !strings.Contains(err.Error(), "low") {
+		t.Fatalf("期望缺少 low 列的错误，实际: %v", err)
+	}
+}
+
+func TestInferExchange(t *testing.T) {
+	cases := map[string]string{"600000": "SH", "510300": "SH", "000001": "SZ", "300750": "SZ", "830799": "BJ", "AAPL": ""}
+	for symbol, want := range cases {
+		if got := InferExchange(symbol); got != want {
+			t.Errorf("InferExchange(%s) = %q，期望 %q", symbol, got, want)
+		}
+	}
+}
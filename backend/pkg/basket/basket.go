@@ -0,0 +1,225 @@
+// Package basket 股票篮子（等权行业指数、自定义组合）的指数行情，由成分股最新价实时计算
+package basket
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stock-analysis-system/backend/pkg/pairs"
+)
+
+// MaxLegs 篮子最多包含的成分股数
+const MaxLegs = 500
+
+// BaseValue 指数在成分股前收盘价时的点位
+const BaseValue = 1000.0
+
+// ErrNoPrices 全部成分股都没有行情
+var ErrNoPrices = errors.New("成分股均无行情")
+
+// Leg 篮子成分股
+type Leg struct {
+	Symbol   string  `json:"symbol"`
+	Exchange string  `json:"exchange"`
+	Weight   float64 `json:"weight"` // 归一化后的权重，合计为 1
+}
+
+// Key 成分股代码 symbol.exchange
+func (l Leg) Key() string {
+	return l.Symbol + "." + l.Exchange
+}
+
+// NewLeg 由 symbol.exchange 与权重创建成分股，权重为 0 表示未指定
+func NewLeg(key string, weight float64) (Leg, error) {
+	symbol, exchange, ok := pairs.SplitLeg(strings.TrimSpace(key))
+	if !ok {
+		return Leg{}, fmt.Errorf("成分股 %q 格式错误，应为 symbol.exchange", key)
+	}
+	if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+		return Leg{}, fmt.Errorf("成分股 %s 的权重无效", key)
+	}
+	return Leg{Symbol: symbol, Exchange: strings.ToUpper(exchange), Weight: weight}, nil
+}
+
+// Parse 解析查询参数形式的篮子，如 600519.SH:0.3,000001.SZ:0.7；省略权重时为等权
+func Parse(spec string) ([]Leg, error) {
+	var legs []Leg
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		key, raw, hasWeight := strings.Cut(item, ":")
+		var weight float64
+		if hasWeight {
+			w, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+			if err != nil {
+				return nil, fmt.Errorf("成分股 %s 的权重格式错误", key)
+			}
+			weight = w
+		}
+		leg, err := NewLeg(key, weight)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	return Normalize(legs)
+}
+
+// Normalize 校验成分股并将权重归一化，重复的成分股合并权重，结果按代码排序
+// 全部未指定权重时为等权；只有部分成分股指定权重时返回错误。
+func Normalize(legs []Leg) ([]Leg, error) {
+	if len(legs) == 0 {
+		return nil, fmt.Errorf("篮子至少需要一只成分股")
+	}
+
+	weighted := 0
+	for _, leg := range legs {
+		if leg.Weight > 0 {
+			weighted++
+		}
+	}
+	if weighted > 0 && weighted < len(legs) {
+		return nil, fmt.Errorf("需要为全部成分股指定权重，或全部省略（等权）")
+	}
+
+	index := make(map[string]int)
+	var merged []Leg
+	for _, leg := range legs {
+		if weighted == 0 {
+			leg.Weight = 1
+		}
+		if i, ok := index[leg.Key()]; ok {
+			merged[i].Weight += leg.Weight
+			continue
+		}
+		index[leg.Key()] = len(merged)
+		merged = append(merged, leg)
+	}
+	if len(merged) > MaxLegs {
+		return nil, fmt.Errorf("篮子最多包含 %d 只成分股", MaxLegs)
+	}
+
+	var total float64
+	for _, leg := range merged {
+		total += leg.Weight
+	}
+	for i := range merged {
+		merged[i].Weight /= total
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Key() < merged[j].Key() })
+	return merged, nil
+}
+
+// EqualWeight 等权篮子，如行业内全部股票
+func EqualWeight(keys []string) ([]Leg, error) {
+	legs := make([]Leg, 0, len(keys))
+	for _, key := range keys {
+		leg, err := NewLeg(key, 0)
+		if err != nil {
+			return nil, err
+		}
+		legs = append(legs, leg)
+	}
+	return Normalize(legs)
+}
+
+// Hash 归一化后篮子的标识，成分股与权重相同的篮子（不论输入顺序）得到相同结果，用作缓存键
+func Hash(legs []Leg) string {
+	var b strings.Builder
+	for _, leg := range legs {
+		fmt.Fprintf(&b, "%s:%.6f,", leg.Key(), leg.Weight)
+	}
+	sum := sha1.Sum([]byte(b.String()))
+	return hex.EncodeToString(sum[:10])
+}
+
+// Price 成分股的最新价与前收盘价
+type Price struct {
+	Name     string
+	Price    float64
+	PreClose float64
+	DataDate string // 行情所属交易日
+}
+
+// LegQuote 成分股对篮子行情的贡献
+type LegQuote struct {
+	Symbol       string  `json:"symbol"`
+	Exchange     string  `json:"exchange"`
+	Name         string  `json:"name,omitempty"`
+	Weight       float64 `json:"weight"` // 按有行情的成分股重新归一化后的权重
+	Price        float64 `json:"price"`
+	PreClose     float64 `json:"pre_close"`
+	ChangePct    float64 `json:"change_pct"`
+	Contribution float64 `json:"contribution"` // 对篮子涨跌幅的贡献（百分点），合计等于篮子涨跌幅
+}
+
+// Quote 篮子行情
+type Quote struct {
+	Value     float64     `json:"value"`     // 指数点位，前收盘为 BaseValue
+	PreClose  float64     `json:"pre_close"` // 前收盘点位
+	Change    float64     `json:"change"`
+	ChangePct float64     `json:"change_pct"`
+	Up        int         `json:"up"`       // 上涨成分股数
+	Down      int         `json:"down"`     // 下跌成分股数
+	Flat      int         `json:"flat"`     // 平盘成分股数
+	Coverage  float64     `json:"coverage"` // 有行情的成分股原始权重之和（0~1）
+	DataDate  string      `json:"data_date,omitempty"`
+	Legs      []*LegQuote `json:"legs"`
+	Missing   []string    `json:"missing,omitempty"` // 没有行情的成分股，不参与计算
+}
+
+// Compute 由成分股最新价计算篮子行情，prices 的键为 symbol.exchange
+// 篮子按前收盘价定基（价格收益，不含分红），没有行情的成分股剔除后按剩余权重重新归一化。
+func Compute(legs []Leg, prices map[string]Price) (*Quote, error) {
+	quote := &Quote{PreClose: BaseValue, Legs: make([]*LegQuote, 0, len(legs))}
+	for _, leg := range legs {
+		p, ok := prices[leg.Key()]
+		if !ok || p.Price <= 0 || p.PreClose <= 0 {
+			quote.Missing = append(quote.Missing, leg.Key())
+			continue
+		}
+		quote.Coverage += leg.Weight
+		quote.Legs = append(quote.Legs, &LegQuote{
+			Symbol:    leg.Symbol,
+			Exchange:  leg.Exchange,
+			Name:      p.Name,
+			Weight:    leg.Weight,
+			Price:     p.Price,
+			PreClose:  p.PreClose,
+			ChangePct: (p.Price/p.PreClose - 1) * 100,
+		})
+		if p.DataDate > quote.DataDate {
+			quote.DataDate = p.DataDate
+		}
+	}
+	if len(quote.Legs) == 0 {
+		return nil, ErrNoPrices
+	}
+
+	var ratio float64
+	for _, lq := range quote.Legs {
+		lq.Weight /= quote.Coverage
+		lq.Contribution = lq.Weight * lq.ChangePct
+		ratio += lq.Weight * lq.Price / lq.PreClose
+		switch {
+		case lq.ChangePct > 0:
+			quote.Up++
+		case lq.ChangePct < 0:
+			quote.Down++
+		default:
+			quote.Flat++
+		}
+	}
+	quote.Value = BaseValue * ratio
+	quote.Change = quote.Value - BaseValue
+	quote.ChangePct = (ratio - 1) * 100
+	return quote, nil
+}
@@ -0,0 +1,126 @@
+package basket
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	legs, err := Parse("600519.SH:3, 000001.sz:1,600519.SH:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(legs) != 2 || legs[0].Key() != "000001.SZ" || legs[1].Key() != "600519.SH" {
+		t.Fatalf("成分股应合并重复并按代码排序: %+v", legs)
+	}
+	if math.Abs(legs[0].Weight-0.2) > 1e-9 || math.Abs(legs[1].Weight-0.8) > 1e-9 {
+		t.Errorf("权重应归一化: %+v", legs)
+	}
+
+	equal, err := Parse("600519.SH,000001.SZ")
+	if err != nil || equal[0].Weight != 0.5 || equal[1].Weight != 0.5 {
+		t.Errorf("省略权重应为等权: %+v, %v", equal, err)
+	}
+
+	for _, spec := range []string{"", "600519", "600519.SH:0.5,000001.SZ", "600519.SH:-1", "600519.SH:abc"} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) 应返回错误", spec)
+		}
+	}
+}
+
+func TestHashIgnoresOrder(t *testing.T) {
+	a, _ := Parse("600519.SH:1,000001.SZ:1")
+	b, _ := Parse("000001.SZ,600519.SH")
+	c, _ := Parse("000001.SZ:1,600519.SH:2")
+	if Hash(a) != Hash(b) {
+		t.Error("成分股与权重相同的篮子应得到相同的标识")
+	}
+	if Hash(a) == Hash(c) {
+		t.Error("权重不同的篮子应得到不同的标识")
+	}
+}
+
+func TestCompute(t *testing.T) {
+	legs, _ := Parse("A.SH:0.5,B.SZ:0.3,C.SZ:0.2")
+	quote, err := Compute(legs, map[string]Price{
+		"A.SH": {Price: 11, PreClose: 10, DataDate: "2024-06-03"},
+		"B.SZ": {Price: 19, PreClose: 20, DataDate: "2024-06-03"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 剔除没有行情的 C 后，A、B 的权重为 0.625、0.375
+	want := 0.625*10 + 0.375*(-5)
+	if math.Abs(quote.ChangePct-want) > 1e-9 || math.Abs(quote.Value-BaseValue*(1+want/100)) > 1e-9 {
+		t.Errorf("篮子涨跌幅 = %v，点位 = %v，应为 %v", quote.ChangePct, quote.Value, want)
+	}
+	if math.Abs(quote.Coverage-0.8) > 1e-9 || len(quote.Missing) != 1 || quote.Missing[0] != "C.SZ" {
+		t.Errorf("覆盖率或缺失成分股错误: %v %v", quote.Coverage, quote.Missing)
+	}
+	var contribution float64
+	for _, lq := range quote.Legs {
+		contribution += lq.Contribution
+	}
+	if math.Abs(contribution-quote.ChangePct) > 1e-9 {
+		t.Errorf("成分股贡献合计 %v 应等于篮子涨跌幅 %v", contribution, quote.ChangePct)
+	}
+	if quote.Up != 1 || quote.Down != 1 || quote.DataDate != "2024-06-03" {
+		t.Errorf("涨跌家数或交易日错误: %+v", quote)
+	}
+
+	if _, err := Compute(legs, nil); err != ErrNoPrices {
+		t.Errorf("全部成分股无行情时应返回 ErrNoPrices，实际 %v", err)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	legs, _ := Parse("A.SH,B.SZ,C.SZ")
+	closes := map[string]map[string]float64{
+		"A.SH": {"2024-06-03": 10, "2024-06-04": 11, "2024-06-05": 11},
+		"B.SZ": {"2024-06-03": 20, "2024-06-05": 22}, // 6月4日停牌
+		"C.SZ": {"2024-06-04": 5, "2024-06-05": 4},   // 6月4日上市
+	}
+
+	points := History(legs, closes, "2024-06-03", 1000)
+	if len(points) != 3 || points[0].Date != "2024-06-03" || points[0].Value != 1000 {
+		t.Fatalf("起点错误: %+v", points[0])
+	}
+
+	// 6月4日：A +10%，B 停牌按 0 计入，C 上市首日不计入
+	if p := points[1]; math.Abs(p.ChangePct-5) > 1e-9 || math.Abs(p.Value-1050) > 1e-9 || math.Abs(p.Coverage-2.0/3) > 1e-9 {
+		t.Errorf("6月4日点位错误: %+v", p)
+	}
+	// 6月5日：A 0%，B 相对停牌前收盘 +10%，C -20%
+	want := 1050 * (1 + (0+10-20)/3.0/100)
+	if p := points[2]; math.Abs(p.Value-want) > 1e-9 || math.Abs(p.Coverage-1) > 1e-9 {
+		t.Errorf("6月5日点位 = %v，应为 %v", p.Value, want)
+	}
+
+	// 从已保存的点位继续计算，结果与一次计算一致；起点不是交易日时取之前最近的交易日
+	next := History(legs, closes, "2024-06-04", points[1].Value)
+	if len(next) != 2 || math.Abs(next[1].Value-want) > 1e-9 {
+		t.Errorf("增量计算结果不一致: %+v", next)
+	}
+	if weekend := History(legs, closes, "2024-06-08", 1000); weekend[0].Date != "2024-06-05" || len(weekend) != 1 {
+		t.Errorf("非交易日起点应取之前最近的交易日: %+v", weekend[0])
+	}
+}
+
+func TestRebalanced(t *testing.T) {
+	closes := map[string]map[string]float64{
+		"A.SH": {"2024-06-03": 10, "2024-06-04": 11, "2024-06-05": 11},
+		"B.SZ": {"2024-06-03": 20, "2024-06-04": 30, "2024-06-05": 33},
+	}
+	// 6月4日收盘起由 A 换为 B：6月4日仍按 A 计算，6月5日按 B 相对6月4日收盘计算
+	schedule := []Rebalance{
+		{Date: "2024-06-03", Legs: []Leg{{Symbol: "A", Exchange: "SH", Weight: 1}}},
+		{Date: "2024-06-04", Legs: []Leg{{Symbol: "B", Exchange: "SZ", Weight: 1}}},
+	}
+
+	points := Rebalanced(schedule, closes, "2024-06-03", 1000)
+	if len(points) != 3 || math.Abs(points[1].Value-1100) > 1e-9 || math.Abs(points[2].Value-1210) > 1e-9 {
+		t.Errorf("调整成分后的点位错误: %+v %+v", points[1], points[2])
+	}
+}
@@ -0,0 +1,231 @@
+package basket
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/columnar"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// historyConcurrency 计算历史点位时并发查询成分股日K线的数量
+const historyConcurrency = 8
+
+// HistoryLookback 起点之前多查询的自然日数，用于取得起点当日停牌成分股的最近收盘价
+const HistoryLookback = 30
+
+// FromModel 已保存篮子的成分股
+func FromModel(legs models.BasketLegs) []Leg {
+	out := make([]Leg, len(legs))
+	for i, leg := range legs {
+		out[i] = Leg{Symbol: leg.Symbol, Exchange: leg.Exchange, Weight: leg.Weight}
+	}
+	return out
+}
+
+// ToModel 保存到数据库的成分股
+func ToModel(legs []Leg) models.BasketLegs {
+	out := make(models.BasketLegs, len(legs))
+	for i, leg := range legs {
+		out[i] = models.BasketLeg{Symbol: leg.Symbol, Exchange: leg.Exchange, Weight: leg.Weight}
+	}
+	return out
+}
+
+// Point 篮子某交易日的收盘点位
+type Point struct {
+	Date      string
+	Value     float64
+	ChangePct float64
+	Coverage  float64 // 已上市（有收盘价）的成分股权重之和
+}
+
+// Rebalance 成分调整：自 Date 收盘起按 Legs 的成分与权重持有
+type Rebalance struct {
+	Date string // YYYY-MM-DD，第一次调整之前的交易日也按第一次调整的成分计算
+	Legs []Leg
+}
+
+// History 从 start（点位为 base）起逐日计算篮子收盘点位，第一个点为不晚于 start 的最近交易日
+// 每日按目标权重再平衡：当日涨跌幅为已上市成分股相对各自最近收盘价涨跌幅的加权平均（按已上市权重归一化），
+// 停牌的成分股按涨跌幅 0 计入，尚未上市的成分股不计入。closes 的键为 symbol.exchange，值为 交易日 -> 收盘价，
+// 需包含 start 之前的收盘价以确定起点。
+func History(legs []Leg, closes map[string]map[string]float64, start string, base float64) []*Point {
+	return Rebalanced([]Rebalance{{Legs: legs}}, closes, start, base)
+}
+
+// Rebalanced 与 History 相同，但成分与权重按 schedule（按日期升序）随时间调整，用于按时点成分计算股票池指数
+// 某交易日的涨跌幅按前一交易日收盘时持有的成分计算。
+func Rebalanced(schedule []Rebalance, closes map[string]map[string]float64, start string, base float64) []*Point {
+	keys := make(map[string]bool)
+	for _, r := range schedule {
+		for _, leg := range r.Legs {
+			keys[leg.Key()] = true
+		}
+	}
+
+	last := make(map[string]float64, len(keys))
+	dateSet := make(map[string]bool)
+	first := ""
+	for key := range keys {
+		var lastDate string
+		for date, price := range closes[key] {
+			if price <= 0 {
+				continue
+			}
+			if date > start {
+				dateSet[date] = true
+			} else if date > lastDate {
+				lastDate = date
+				last[key] = price
+			}
+		}
+		if lastDate > first {
+			first = lastDate
+		}
+	}
+	if first == "" {
+		first = start
+	}
+	dates := make([]string, 0, len(dateSet))
+	for date := range dateSet {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	points := []*Point{{Date: first, Value: base, Coverage: coverage(legsAt(schedule, first), last)}}
+	value := base
+	for _, date := range dates {
+		held := legsAt(schedule, points[len(points)-1].Date)
+		var weighted, covered float64
+		for _, leg := range held {
+			prev, listed := last[leg.Key()]
+			if !listed {
+				continue
+			}
+			ratio := 1.0
+			if price := closes[leg.Key()][date]; price > 0 {
+				ratio = price / prev
+			}
+			weighted += leg.Weight * ratio
+			covered += leg.Weight
+		}
+		// 更新全部成分的最近收盘价：上市首日只作为之后的起点，调入前的收盘价作为调入后的起点
+		for key := range keys {
+			if price := closes[key][date]; price > 0 {
+				last[key] = price
+			}
+		}
+		pct := 0.0
+		if covered > 0 {
+			pct = (weighted/covered - 1) * 100
+		}
+		value *= 1 + pct/100
+		points = append(points, &Point{Date: date, Value: value, ChangePct: pct, Coverage: covered})
+	}
+	return points
+}
+
+// legsAt 指定交易日收盘时持有的成分
+func legsAt(schedule []Rebalance, date string) []Leg {
+	if len(schedule) == 0 {
+		return nil
+	}
+	i := sort.Search(len(schedule), func(i int) bool { return schedule[i].Date > date })
+	if i == 0 {
+		return schedule[0].Legs
+	}
+	return schedule[i-1].Legs
+}
+
+// coverage 已有收盘价的成分股权重之和
+func coverage(legs []Leg, last map[string]float64) float64 {
+	var total float64
+	for _, leg := range legs {
+		if _, ok := last[leg.Key()]; ok {
+			total += leg.Weight
+		}
+	}
+	return total
+}
+
+// LoadCloses 并发查询成分股在时间范围内的日收盘价，键为 symbol.exchange
+// 回测服务传入带缓存的列式K线加载器，其他调用方以 columnar.BarsLoader(marketRepo.GetDailyBars) 直接查询。
+func LoadCloses(ctx context.Context, loader columnar.Loader, legs []Leg, start, end time.Time) (map[string]map[string]float64, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	closes := make(map[string]map[string]float64, len(legs))
+	sem := make(chan struct{}, historyConcurrency)
+	for _, leg := range legs {
+		wg.Add(1)
+		go func(leg Leg) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			bars, err := loader.DailyColumns(ctx, leg.Symbol, leg.Exchange, start, end)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("查询 %s 日K线失败: %w", leg.Key(), err)
+				}
+				return
+			}
+			closes[leg.Key()] = bars.Closes()
+		}(leg)
+	}
+	wg.Wait()
+	return closes, firstErr
+}
+
+// Update 计算并保存篮子自最近保存的点位之后到 end 的每日点位，尚未计算过时从基日开始，返回新增的交易日数
+func Update(ctx context.Context, repo repository.BasketRepository, marketRepo repository.MarketRepository, b *models.Basket, end time.Time) (int, error) {
+	latest, err := repo.GetLatestValue(ctx, b.ID)
+	if err != nil {
+		return 0, err
+	}
+	start, base := b.BaseDate, BaseValue
+	if latest != nil {
+		start, base = latest.TradeDate, latest.Value
+	}
+	startDate := start.Format("2006-01-02")
+	if startDate >= end.Format("2006-01-02") && latest != nil {
+		return 0, nil
+	}
+
+	legs := FromModel(b.Legs)
+	closes, err := LoadCloses(ctx, columnar.BarsLoader(marketRepo.GetDailyBars), legs, start.AddDate(0, 0, -HistoryLookback), end)
+	if err != nil {
+		return 0, err
+	}
+	points := History(legs, closes, startDate, base)
+
+	// 已保存的起点不重复保存；基日时全部成分股均未上市时起点不保存
+	values := make([]*models.BasketValue, 0, len(points))
+	for i, p := range points {
+		if i == 0 && (latest != nil || p.Coverage == 0) {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", p.Date)
+		if err != nil {
+			return 0, err
+		}
+		values = append(values, &models.BasketValue{
+			BasketID:  b.ID,
+			TradeDate: date,
+			Value:     p.Value,
+			ChangePct: p.ChangePct,
+			Coverage:  p.Coverage,
+		})
+	}
+	if err := repo.SaveValues(ctx, values); err != nil {
+		return 0, err
+	}
+	return len(values), nil
+}
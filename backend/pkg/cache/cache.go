@@ -0,0 +1,168 @@
+// Package cache 基于 Redis 的查询结果缓存，值以 JSON 保存。
+// 缓存只用于加速读取：未配置 Redis（*Cache 为 nil）或 Redis 出错时直接回源查询，不影响接口可用性。
+// 同一进程内并发未命中同一个键时只回源一次（singleflight），避免热点键过期瞬间的缓存击穿。
+// 每次写入同时保留一份较长时间的过期副本，数据源故障时 GetOrLoadStale 可以降级返回最近一次成功的结果。
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// loadTimeout 合并回源的最长时间
+// 回源不随发起请求的上下文取消，一个请求超时或断开不影响等待同一结果的其他请求。
+const loadTimeout = 30 * time.Second
+
+// staleTTL 过期副本的保留时间
+const staleTTL = 24 * time.Hour
+
+// Cache Redis 缓存，nil 表示未启用
+type Cache struct {
+	client *redis.Client
+	prefix string
+	flight singleflight.Group
+}
+
+// New 创建缓存，键统一加上 prefix（如 market:），client 为 nil 时返回 nil
+func New(client *redis.Client, prefix string) *Cache {
+	if client == nil {
+		return nil
+	}
+	return &Cache{client: client, prefix: prefix}
+}
+
+// Enabled 是否已启用缓存
+func (c *Cache) Enabled() bool {
+	return c != nil
+}
+
+// Get 读取缓存并解析到 dst，未命中时返回 false
+func (c *Cache) Get(ctx context.Context, key string, dst interface{}) (bool, error) {
+	if c == nil {
+		return false, nil
+	}
+	data, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Set 写入缓存
+func (c *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if c == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return c.write(ctx, key, data, ttl)
+}
+
+// write 写入缓存及其过期副本
+func (c *Cache) write(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+	_, err := c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, c.prefix+key, data, ttl)
+		pipe.Set(ctx, c.prefix+"stale:"+key, data, staleTTL)
+		return nil
+	})
+	return err
+}
+
+// Delete 删除缓存
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	if c == nil || len(keys) == 0 {
+		return nil
+	}
+	full := make([]string, len(keys))
+	for i, key := range keys {
+		full[i] = c.prefix + key
+	}
+	return c.client.Del(ctx, full...).Err()
+}
+
+// GetOrLoad 读取缓存，未命中时调用 load 回源并写入缓存
+// 并发未命中同一个键时只有一个请求回源，其余请求等待并各自解析一份结果，可以安全修改返回值。
+// Redis 读写失败只记录日志；load 返回错误时不写入缓存。
+func GetOrLoad[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	var value T
+	if c == nil {
+		return load(ctx)
+	}
+
+	hit, err := c.Get(ctx, key, &value)
+	if err != nil {
+		log.Printf("读取缓存 %s 失败: %v", key, err)
+	}
+	if hit {
+		return value, nil
+	}
+
+	ch := c.flight.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+
+		loaded, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(loaded)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.write(loadCtx, key, data, ttl); err != nil {
+			log.Printf("写入缓存 %s 失败: %v", key, err)
+		}
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return value, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return value, res.Err
+		}
+		err := json.Unmarshal(res.Val.([]byte), &value)
+		return value, err
+	}
+}
+
+// GetOrLoadStale 同 GetOrLoad，回源失败时返回该键的过期副本，stale 为 true
+// 用于数据源故障时降级提供最近一次成功的结果；没有过期副本时返回回源错误。
+func GetOrLoadStale[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (value T, stale bool, err error) {
+	value, err = GetOrLoad(ctx, c, key, ttl, load)
+	if err == nil || c == nil || ctx.Err() != nil {
+		return value, false, err
+	}
+
+	var old T
+	data, getErr := c.client.Get(ctx, c.prefix+"stale:"+key).Bytes()
+	if getErr != nil || json.Unmarshal(data, &old) != nil {
+		return value, false, err
+	}
+	log.Printf("回源 %s 失败，返回过期缓存: %v", key, err)
+	return old, true, nil
+}
+
+// Refresh 回源查询并覆盖缓存，用于预热
+func Refresh[T any](ctx context.Context, c *Cache, key string, ttl time.Duration, load func(ctx context.Context) (T, error)) (T, error) {
+	value, err := load(ctx)
+	if err != nil {
+		return value, err
+	}
+	return value, c.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,246 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type quote struct {
+	Symbol string  `json:"symbol"`
+	Price  float64 `json:"price"`
+}
+
+func newTestCache(t *testing.T) (*Cache, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return New(client, "test:"), mr
+}
+
+func TestGetOrLoad(t *testing.T) {
+	c, mr := newTestCache(t)
+	ctx := context.Background()
+
+	loads := 0
+	load := func(context.Context) (*quote, error) {
+		loads++
+		return &quote{Symbol: "600000", Price: 10.5}, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		q, err := GetOrLoad(ctx, c, "quote:600000.SH", time.Minute, load)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if q.Symbol != "600000" || q.Price != 10.5 {
+			t.Fatalf("缓存值错误: %+v", q)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("期望回源 1 次，实际 %d 次", loads)
+	}
+	if !mr.Exists("test:quote:600000.SH") {
+		t.Error("键应带前缀")
+	}
+
+	// 过期后重新回源
+	mr.FastForward(2 * time.Minute)
+	if _, err := GetOrLoad(ctx, c, "quote:600000.SH", time.Minute, load); err != nil {
+		t.Fatal(err)
+	}
+	if loads != 2 {
+		t.Errorf("过期后应重新回源，实际回源 %d 次", loads)
+	}
+}
+
+func TestGetOrLoadCoalesces(t *testing.T) {
+	c, _ := newTestCache(t)
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) (*quote, error) {
+		loads.Add(1)
+		<-release
+		return &quote{Symbol: "600000", Price: 10.5}, nil
+	}
+
+	const n = 50
+	results := make([]*quote, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			q, err := GetOrLoad(ctx, c, "quote:600000.SH", time.Second, load)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			results[i] = q
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := loads.Load(); got != 1 {
+		t.Errorf("并发未命中应只回源 1 次，实际 %d 次", got)
+	}
+	// 每个请求拿到独立的副本
+	results[0].Price = 0
+	for i := 1; i < n; i++ {
+		if results[i] == nil || results[i].Price != 10.5 {
+			t.Fatalf("第 %d 个结果错误: %+v", i, results[i])
+		}
+	}
+}
+
+func TestGetOrLoadCallerCancel(t *testing.T) {
+	c, mr := newTestCache(t)
+
+	release := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		GetOrLoad(context.Background(), c, "k", time.Minute, func(ctx context.Context) (int, error) {
+			<-release
+			return 7, ctx.Err()
+		})
+	}()
+
+	// 等待的请求超时返回，不影响正在进行的回源
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	time.Sleep(10 * time.Millisecond)
+	if _, err := GetOrLoad(ctx, c, "k", time.Minute, func(context.Context) (int, error) { return 0, nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("期望超时错误，实际 %v", err)
+	}
+	close(release)
+	<-done
+	if v, _ := mr.Get("test:k"); v != "7" {
+		t.Errorf("回源结果应写入缓存，实际 %q", v)
+	}
+}
+
+func TestGetOrLoadLoadError(t *testing.T) {
+	c, mr := newTestCache(t)
+	_, err := GetOrLoad(context.Background(), c, "quote:x", time.Minute, func(context.Context) (*quote, error) {
+		return nil, errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("期望返回回源错误")
+	}
+	if mr.Exists("test:quote:x") {
+		t.Error("回源失败时不应写入缓存")
+	}
+}
+
+func TestNilCache(t *testing.T) {
+	var c *Cache
+	if New(nil, "x:") != nil || c.Enabled() {
+		t.Fatal("未配置 Redis 时缓存应为 nil")
+	}
+
+	loads := 0
+	for i := 0; i < 2; i++ {
+		if _, err := GetOrLoad(context.Background(), c, "k", time.Minute, func(context.Context) (int, error) {
+			loads++
+			return 1, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("未启用缓存时每次都应回源，实际 %d 次", loads)
+	}
+}
+
+func TestRedisDownFallsBack(t *testing.T) {
+	c, mr := newTestCache(t)
+	mr.Close()
+
+	v, err := GetOrLoad(context.Background(), c, "k", time.Minute, func(context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || v != 42 {
+		t.Fatalf("Redis 不可用时应直接回源: v=%d err=%v", v, err)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	c := NewCoalescer("test")
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	release := make(chan struct{})
+	fn := func(context.Context) ([]int, error) {
+		calls.Add(1)
+		<-release
+		return []int{1, 2, 3}, nil
+	}
+
+	const n = 20
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			bars, err := Coalesce(ctx, c, "daily:600000.SH:2024", fn)
+			if err != nil || len(bars) != 3 {
+				t.Errorf("结果错误: %v %v", bars, err)
+			}
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("相同查询应只执行 1 次，实际 %d 次", got)
+	}
+	if hits, misses := c.Stats(); hits != n-1 || misses != 1 {
+		t.Errorf("命中统计错误: hits=%d misses=%d", hits, misses)
+	}
+
+	// 不同的键各自查询
+	if _, err := Coalesce(ctx, c, "daily:000001.SZ:2024", func(context.Context) ([]int, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, misses := c.Stats(); misses != 2 {
+		t.Errorf("不同的键应各自查询，misses=%d", misses)
+	}
+}
+
+func TestGetOrLoadStale(t *testing.T) {
+	c, mr := newTestCache(t)
+	ctx := context.Background()
+
+	q, stale, err := GetOrLoadStale(ctx, c, "quote:600000.SH", time.Minute, func(context.Context) (*quote, error) {
+		return &quote{Symbol: "600000", Price: 10.5}, nil
+	})
+	if err != nil || stale || q.Price != 10.5 {
+		t.Fatalf("首次回源结果错误: %+v stale=%v err=%v", q, stale, err)
+	}
+
+	// 缓存过期后数据源故障，返回过期副本
+	mr.FastForward(2 * time.Minute)
+	failing := func(context.Context) (*quote, error) { return nil, errors.New("influx down") }
+	q, stale, err = GetOrLoadStale(ctx, c, "quote:600000.SH", time.Minute, failing)
+	if err != nil || !stale || q.Price != 10.5 {
+		t.Fatalf("应返回过期副本: %+v stale=%v err=%v", q, stale, err)
+	}
+
+	// 没有过期副本时返回回源错误
+	if _, stale, err := GetOrLoadStale(ctx, c, "quote:000001.SZ", time.Minute, failing); err == nil || stale {
+		t.Fatalf("没有过期副本时应返回错误: stale=%v err=%v", stale, err)
+	}
+}
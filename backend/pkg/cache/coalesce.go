@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+
+	"golang.org/x/sync/singleflight"
+
+	"stock-analysis-system/backend/pkg/metrics"
+)
+
+// Coalescer 合并参数相同的并发查询：同一个键同一时刻只执行一次，期间到达的请求等待并共享结果
+// 与 GetOrLoad 不同，结果不经过序列化，所有调用方拿到同一个值，调用方不能修改返回值。
+type Coalescer struct {
+	name   string
+	group  singleflight.Group
+	hits   atomic.Int64 // 共享了进行中查询结果的请求数
+	misses atomic.Int64 // 实际执行的查询数
+}
+
+// NewCoalescer 创建查询合并器，命中与未命中次数以 name 标签导出到 /metrics
+func NewCoalescer(name string) *Coalescer {
+	c := &Coalescer{name: name}
+	metrics.Register("coalesce_"+name, c.collectMetrics)
+	return c
+}
+
+// Coalesce 执行 fn，相同 key 的查询正在进行时等待其结果
+// 查询不随发起请求的上下文取消，等待中的请求各自按自己的上下文超时返回。
+func Coalesce[T any](ctx context.Context, c *Coalescer, key string, fn func(ctx context.Context) (T, error)) (T, error) {
+	var executed atomic.Bool
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		executed.Store(true)
+		c.misses.Add(1)
+
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		return fn(loadCtx)
+	})
+
+	var zero T
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if !executed.Load() {
+			c.hits.Add(1)
+		}
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(T), nil
+	}
+}
+
+// Stats 命中（共享结果）与未命中（实际查询）次数
+func (c *Coalescer) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+func (c *Coalescer) collectMetrics() []metrics.Sample {
+	labels := map[string]string{"query": c.name}
+	return []metrics.Sample{
+		{Name: "query_coalesce_hits_total", Help: "共享进行中查询结果的请求数", Type: metrics.TypeCounter, Labels: labels, Value: float64(c.hits.Load())},
+		{Name: "query_coalesce_misses_total", Help: "实际执行的查询数", Type: metrics.TypeCounter, Labels: labels, Value: float64(c.misses.Load())},
+	}
+}
@@ -0,0 +1,14 @@
+package cache
+
+// 行情服务的缓存键，数据同步服务修正历史K线后据此删除受影响的缓存
+const (
+	MarketPrefix  = "market:"
+	KeyStocks     = "stocks:all"
+	KeyMarketBars = "bars:latest"
+	KeyIndustries = "industries"
+)
+
+// QuoteKey 个股行情缓存键
+func QuoteKey(symbol, exchange string) string {
+	return "quote:" + symbol + "." + exchange
+}
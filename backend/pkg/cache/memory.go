@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"stock-analysis-system/backend/pkg/metrics"
+)
+
+// Memory 进程内 LRU 缓存，按调用方估算的字节数限制总大小，超出时淘汰最久未使用的条目
+// 与 Coalescer 相同，值不经过序列化，所有调用方拿到同一个值，调用方不能修改返回值；并发未命中同一个键时只加载一次。
+// nil 表示未启用，Load 直接调用 load。
+type Memory[V any] struct {
+	name     string
+	maxBytes int64
+	ttl      time.Duration
+	size     func(V) int64
+
+	mu    sync.Mutex
+	order *list.List // 最近使用的条目在前
+	items map[string]*list.Element
+	bytes int64
+
+	flight    singleflight.Group
+	hits      atomic.Int64
+	misses    atomic.Int64
+	evictions atomic.Int64 // 因超出内存上限被淘汰的条目数
+}
+
+// memoryEntry 缓存条目
+type memoryEntry[V any] struct {
+	key       string
+	value     V
+	size      int64
+	expiresAt time.Time
+}
+
+// MemoryStats 缓存的命中情况与当前占用
+type MemoryStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Entries   int
+	Bytes     int64
+}
+
+// NewMemory 创建进程内缓存，maxBytes 为估算的内存上限，size 估算单个值占用的字节数；
+// ttl 不大于 0 时条目不过期。maxBytes 不大于 0 时返回 nil。统计以 name 标签导出到 /metrics。
+func NewMemory[V any](name string, maxBytes int64, ttl time.Duration, size func(V) int64) *Memory[V] {
+	if maxBytes <= 0 {
+		return nil
+	}
+	m := &Memory[V]{
+		name:     name,
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		size:     size,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+	metrics.Register("memory_cache_"+name, m.collectMetrics)
+	return m
+}
+
+// Get 读取未过期的条目，命中时移到最近使用
+func (m *Memory[V]) Get(key string) (V, bool) {
+	var zero V
+	if m == nil {
+		return zero, false
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	elem, ok := m.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*memoryEntry[V])
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		m.remove(elem)
+		return zero, false
+	}
+	m.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Add 写入条目并按内存上限淘汰最久未使用的条目，单个值超过上限时不缓存
+func (m *Memory[V]) Add(key string, value V) {
+	if m == nil {
+		return
+	}
+	size := m.size(value)
+	if size > m.maxBytes {
+		return
+	}
+	entry := &memoryEntry[V]{key: key, value: value, size: size}
+	if m.ttl > 0 {
+		entry.expiresAt = time.Now().Add(m.ttl)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if elem, ok := m.items[key]; ok {
+		m.remove(elem)
+	}
+	m.items[key] = m.order.PushFront(entry)
+	m.bytes += size
+	for m.bytes > m.maxBytes {
+		m.remove(m.order.Back())
+		m.evictions.Add(1)
+	}
+}
+
+// Purge 清空缓存
+func (m *Memory[V]) Purge() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.order.Init()
+	m.items = make(map[string]*list.Element)
+	m.bytes = 0
+}
+
+// Load 读取缓存，未命中时调用 load 并写入；load 返回错误时不缓存
+// 加载不随发起请求的上下文取消，等待中的调用方各自按自己的上下文超时返回。
+func (m *Memory[V]) Load(ctx context.Context, key string, load func(ctx context.Context) (V, error)) (V, error) {
+	if m == nil {
+		return load(ctx)
+	}
+	if value, ok := m.Get(key); ok {
+		m.hits.Add(1)
+		return value, nil
+	}
+	m.misses.Add(1)
+
+	ch := m.flight.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), loadTimeout)
+		defer cancel()
+		value, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		m.Add(key, value)
+		return value, nil
+	})
+
+	var zero V
+	select {
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return zero, res.Err
+		}
+		return res.Val.(V), nil
+	}
+}
+
+// Stats 命中、未命中与淘汰次数，当前条目数与估算的字节数
+func (m *Memory[V]) Stats() MemoryStats {
+	if m == nil {
+		return MemoryStats{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return MemoryStats{
+		Hits:      m.hits.Load(),
+		Misses:    m.misses.Load(),
+		Evictions: m.evictions.Load(),
+		Entries:   len(m.items),
+		Bytes:     m.bytes,
+	}
+}
+
+// remove 删除条目，调用方持有锁
+func (m *Memory[V]) remove(elem *list.Element) {
+	entry := m.order.Remove(elem).(*memoryEntry[V])
+	delete(m.items, entry.key)
+	m.bytes -= entry.size
+}
+
+func (m *Memory[V]) collectMetrics() []metrics.Sample {
+	stats := m.Stats()
+	labels := map[string]string{"cache": m.name}
+	return []metrics.Sample{
+		{Name: "memory_cache_hits_total", Help: "进程内缓存命中次数", Type: metrics.TypeCounter, Labels: labels, Value: float64(stats.Hits)},
+		{Name: "memory_cache_misses_total", Help: "进程内缓存未命中次数", Type: metrics.TypeCounter, Labels: labels, Value: float64(stats.Misses)},
+		{Name: "memory_cache_evictions_total", Help: "超出内存上限被淘汰的条目数", Type: metrics.TypeCounter, Labels: labels, Value: float64(stats.Evictions)},
+		{Name: "memory_cache_entries", Help: "当前缓存的条目数", Type: metrics.TypeGauge, Labels: labels, Value: float64(stats.Entries)},
+		{Name: "memory_cache_bytes", Help: "当前缓存估算占用的字节数", Type: metrics.TypeGauge, Labels: labels, Value: float64(stats.Bytes)},
+	}
+}
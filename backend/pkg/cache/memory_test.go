@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func sliceSize(v []int) int64 { return int64(len(v)) }
+
+func TestMemoryLoad(t *testing.T) {
+	m := NewMemory("test_load", 100, time.Minute, sliceSize)
+	ctx := context.Background()
+
+	loads := 0
+	load := func(context.Context) ([]int, error) {
+		loads++
+		return []int{1, 2, 3}, nil
+	}
+	for i := 0; i < 3; i++ {
+		v, err := m.Load(ctx, "a", load)
+		if err != nil || len(v) != 3 {
+			t.Fatalf("Load = %v, %v", v, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("期望加载 1 次，实际 %d 次", loads)
+	}
+	if s := m.Stats(); s.Hits != 2 || s.Misses != 1 || s.Entries != 1 || s.Bytes != 3 {
+		t.Errorf("stats = %+v", s)
+	}
+
+	// 加载失败不缓存
+	if _, err := m.Load(ctx, "b", func(context.Context) ([]int, error) { return nil, errors.New("down") }); err == nil {
+		t.Fatal("应返回加载错误")
+	}
+	if _, ok := m.Get("b"); ok {
+		t.Error("加载失败不应缓存")
+	}
+}
+
+func TestMemoryEviction(t *testing.T) {
+	m := NewMemory("test_eviction", 10, 0, sliceSize)
+	m.Add("a", make([]int, 4))
+	m.Add("b", make([]int, 4))
+	m.Get("a") // a 最近使用，超出上限时先淘汰 b
+	m.Add("c", make([]int, 4))
+
+	if _, ok := m.Get("b"); ok {
+		t.Error("b 应被淘汰")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := m.Get(key); !ok {
+			t.Errorf("%s 应保留", key)
+		}
+	}
+	if s := m.Stats(); s.Evictions != 1 || s.Bytes != 8 {
+		t.Errorf("stats = %+v", s)
+	}
+
+	// 单个值超过上限时不缓存
+	m.Add("big", make([]int, 11))
+	if _, ok := m.Get("big"); ok {
+		t.Error("超过上限的值不应缓存")
+	}
+}
+
+func TestMemoryExpiry(t *testing.T) {
+	m := NewMemory("test_expiry", 100, time.Millisecond, sliceSize)
+	m.Add("a", []int{1})
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := m.Get("a"); ok {
+		t.Error("过期的条目应视为未命中")
+	}
+	if s := m.Stats(); s.Entries != 0 || s.Bytes != 0 {
+		t.Errorf("过期条目应被删除: %+v", s)
+	}
+}
+
+func TestMemoryCoalesces(t *testing.T) {
+	m := NewMemory("test_coalesce", 100, time.Minute, sliceSize)
+	ctx := context.Background()
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) ([]int, error) {
+		loads.Add(1)
+		<-release
+		return []int{1}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.Load(ctx, "a", load); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := loads.Load(); n != 1 {
+		t.Errorf("并发未命中应只加载 1 次，实际 %d 次", n)
+	}
+}
+
+func TestNilMemory(t *testing.T) {
+	m := NewMemory("test_nil", 0, time.Minute, sliceSize)
+	if m != nil {
+		t.Fatal("上限为 0 时应返回 nil")
+	}
+	v, err := m.Load(context.Background(), "a", func(context.Context) ([]int, error) { return []int{1}, nil })
+	if err != nil || len(v) != 1 {
+		t.Errorf("未启用时应直接加载: %v, %v", v, err)
+	}
+	m.Add("a", v)
+	m.Purge()
+	if s := m.Stats(); s != (MemoryStats{}) {
+		t.Errorf("stats = %+v", s)
+	}
+}
@@ -0,0 +1,185 @@
+// Package calendar 交易日历：按交易所配置连续竞价时段，判断交易日与是否处于交易时段（时间按交易所时区，见 markettime）
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/markettime"
+)
+
+// DefaultSessions 未单独配置的交易所使用的连续竞价时段
+var DefaultSessions = []string{"09:30-11:30", "13:00-15:00"}
+
+// maxSearchDays NextOpen 向后查找交易日的最大天数（覆盖春节等长假）
+const maxSearchDays = 30
+
+// Session 一个交易时段，以当天零点起的分钟数表示，包含 Start、不含 End
+type Session struct {
+	Start int
+	End   int
+}
+
+// Calendar 交易日历，创建后只读，可并发使用
+type Calendar struct {
+	defaults []Session
+	sessions map[string][]Session // 交易所（大写）-> 时段
+	holidays map[string]bool      // YYYY-MM-DD
+}
+
+// New 根据配置创建交易日历，时段或日期格式错误时返回错误
+func New(cfg config.CalendarConfig) (*Calendar, error) {
+	defaults, err := ParseSessions(DefaultSessions)
+	if err != nil {
+		return nil, err
+	}
+	c := &Calendar{
+		defaults: defaults,
+		sessions: make(map[string][]Session),
+		holidays: make(map[string]bool),
+	}
+	for exchange, specs := range cfg.Sessions {
+		sessions, err := ParseSessions(specs)
+		if err != nil {
+			return nil, fmt.Errorf("交易所 %s 的交易时段配置错误: %w", exchange, err)
+		}
+		c.sessions[strings.ToUpper(exchange)] = sessions
+	}
+	for _, day := range cfg.Holidays {
+		if _, err := time.Parse("2006-01-02", day); err != nil {
+			return nil, fmt.Errorf("休市日 %q 格式错误，应为 YYYY-MM-DD", day)
+		}
+		c.holidays[day] = true
+	}
+	return c, nil
+}
+
+// ParseSessions 解析 HH:MM-HH:MM 格式的时段，结果按开始时间排序，时段不能重叠
+func ParseSessions(specs []string) ([]Session, error) {
+	sessions := make([]Session, 0, len(specs))
+	for _, spec := range specs {
+		start, end, ok := strings.Cut(strings.TrimSpace(spec), "-")
+		if !ok {
+			return nil, fmt.Errorf("时段 %q 格式错误，应为 HH:MM-HH:MM", spec)
+		}
+		from, err := parseClock(start)
+		if err != nil {
+			return nil, fmt.Errorf("时段 %q 格式错误: %w", spec, err)
+		}
+		to, err := parseClock(end)
+		if err != nil {
+			return nil, fmt.Errorf("时段 %q 格式错误: %w", spec, err)
+		}
+		if from >= to {
+			return nil, fmt.Errorf("时段 %q 的开始时间应早于结束时间", spec)
+		}
+		sessions = append(sessions, Session{Start: from, End: to})
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("至少需要一个交易时段")
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Start < sessions[j].Start })
+	for i := 1; i < len(sessions); i++ {
+		if sessions[i].Start < sessions[i-1].End {
+			return nil, fmt.Errorf("交易时段不能重叠")
+		}
+	}
+	return sessions, nil
+}
+
+// parseClock 解析 HH:MM，返回当天零点起的分钟数
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("时间 %q 应为 HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Sessions 交易所的交易时段
+func (c *Calendar) Sessions(exchange string) []Session {
+	if sessions, ok := c.sessions[strings.ToUpper(exchange)]; ok {
+		return sessions
+	}
+	return c.defaults
+}
+
+// IsTradingDay 是否为交易日（非周末且不在休市日配置中），按默认时区划分日期
+func (c *Calendar) IsTradingDay(t time.Time) bool {
+	return c.isTradingDate(t.In(markettime.Location("")))
+}
+
+// isTradingDate t 所在时区的当天是否为交易日
+func (c *Calendar) isTradingDate(t time.Time) bool {
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays[t.Format("2006-01-02")]
+}
+
+// InSession 是否处于交易所的交易时段
+func (c *Calendar) InSession(exchange string, t time.Time) bool {
+	_, ok := c.SessionStart(exchange, t)
+	return ok
+}
+
+// SessionStart 所处交易时段的开始时间（交易所时区），不在交易时段时返回 false
+func (c *Calendar) SessionStart(exchange string, t time.Time) (time.Time, bool) {
+	t = t.In(markettime.Location(exchange))
+	if !c.isTradingDate(t) {
+		return time.Time{}, false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	for _, session := range c.Sessions(exchange) {
+		if minute >= session.Start && minute < session.End {
+			return clock(t, session.Start), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// NextOpen 交易所下一个交易时段的开始时间，t 处于交易时段时返回 t；30 天内没有交易日时返回零值
+func (c *Calendar) NextOpen(exchange string, t time.Time) time.Time {
+	if c.InSession(exchange, t) {
+		return t
+	}
+	t = t.In(markettime.Location(exchange))
+	for day := 0; day <= maxSearchDays; day++ {
+		date := t.AddDate(0, 0, day)
+		if !c.isTradingDate(date) {
+			continue
+		}
+		for _, session := range c.Sessions(exchange) {
+			if open := clock(date, session.Start); open.After(t) {
+				return open
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// LastClose 交易所在 t 及之前最近一个已结束交易时段的结束时间，30 天内没有交易日时返回零值
+func (c *Calendar) LastClose(exchange string, t time.Time) time.Time {
+	t = t.In(markettime.Location(exchange))
+	sessions := c.Sessions(exchange)
+	for day := 0; day <= maxSearchDays; day++ {
+		date := t.AddDate(0, 0, -day)
+		if !c.isTradingDate(date) {
+			continue
+		}
+		for i := len(sessions) - 1; i >= 0; i-- {
+			if end := clock(date, sessions[i].End); !end.After(t) {
+				return end
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// clock 与 t 同一天、同一时区的指定时刻（分钟数）
+func clock(t time.Time, minute int) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), minute/60, minute%60, 0, 0, t.Location())
+}
@@ -0,0 +1,106 @@
+package calendar
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/markettime"
+)
+
+func at(s string) time.Time {
+	t, err := markettime.ParseMinute(s, "")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestInSession(t *testing.T) {
+	cal, err := New(config.CalendarConfig{
+		Sessions: map[string][]string{"bj": {"09:30-11:30", "13:00-15:30"}},
+		Holidays: []string{"2024-10-01"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		exchange string
+		time     string
+		want     bool
+	}{
+		{"SH", "2024-09-30 09:29", false},
+		{"SH", "2024-09-30 09:30", true},
+		{"SH", "2024-09-30 11:30", false},
+		{"SH", "2024-09-30 14:59", true},
+		{"SH", "2024-09-30 15:10", false},
+		{"BJ", "2024-09-30 15:10", true},
+		{"SH", "2024-10-01 10:00", false}, // 休市日
+		{"SZ", "2024-10-05 10:00", false}, // 周六
+	}
+	for _, tt := range tests {
+		if got := cal.InSession(tt.exchange, at(tt.time)); got != tt.want {
+			t.Errorf("InSession(%s, %s) = %v, want %v", tt.exchange, tt.time, got, tt.want)
+		}
+	}
+
+	if start, ok := cal.SessionStart("SH", at("2024-09-30 14:00")); !ok || !start.Equal(at("2024-09-30 13:00")) {
+		t.Errorf("SessionStart = %v, %v", start, ok)
+	}
+}
+
+func TestNextOpen(t *testing.T) {
+	cal, err := New(config.CalendarConfig{Holidays: []string{"2024-10-01", "2024-10-02", "2024-10-03", "2024-10-04", "2024-10-07"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ from, want string }{
+		{"2024-09-30 08:00", "2024-09-30 09:30"},
+		{"2024-09-30 12:00", "2024-09-30 13:00"},
+		{"2024-09-30 15:00", "2024-10-08 09:30"}, // 国庆长假
+		{"2024-09-30 10:00", "2024-09-30 10:00"}, // 交易时段内
+	}
+	for _, tt := range tests {
+		if got := cal.NextOpen("SH", at(tt.from)); !got.Equal(at(tt.want)) {
+			t.Errorf("NextOpen(%s) = %v, want %s", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestLastClose(t *testing.T) {
+	cal, err := New(config.CalendarConfig{Holidays: []string{"2024-10-01", "2024-10-02", "2024-10-03", "2024-10-04", "2024-10-07"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct{ from, want string }{
+		{"2024-09-30 15:00", "2024-09-30 15:00"},
+		{"2024-09-30 14:00", "2024-09-30 11:30"},
+		{"2024-09-30 11:30", "2024-09-30 11:30"},
+		{"2024-09-30 09:00", "2024-09-27 15:00"},
+		{"2024-10-08 10:00", "2024-09-30 15:00"}, // 国庆长假
+	}
+	for _, tt := range tests {
+		if got := cal.LastClose("SH", at(tt.from)); !got.Equal(at(tt.want)) {
+			t.Errorf("LastClose(%s) = %v, want %s", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestParseSessionsErrors(t *testing.T) {
+	for _, specs := range [][]string{
+		{"9:30"},
+		{"11:30-09:30"},
+		{"09:30-11:30", "11:00-15:00"},
+		{},
+	} {
+		if _, err := ParseSessions(specs); err == nil {
+			t.Errorf("ParseSessions(%v) 应返回错误", specs)
+		}
+	}
+	if _, err := New(config.CalendarConfig{Holidays: []string{"20241001"}}); err == nil {
+		t.Error("休市日格式错误应返回错误")
+	}
+}
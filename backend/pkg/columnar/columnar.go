@@ -0,0 +1,181 @@
+// Package columnar 回测引擎使用的列式日K线：一只股票的K线按列存放在连续的切片中（交易日、开高低收、成交量额），
+// 代替 []*models.DailyBar 的逐根指针，加载时每列只分配一次，顺序读取单列时缓存局部性更好。
+// 目前读取K线的回测路径是配对交易、基准指数与股票池基准；其他策略类型仍使用不读取K线的模拟净值曲线。
+package columnar
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// Day 交易日，自 1970-01-01 起的天数
+type Day int32
+
+// secondsPerDay 一天的秒数
+const secondsPerDay = 24 * 60 * 60
+
+// DayOf 时间所在日期对应的交易日，按时间自身的时区取日期，与 Format("2006-01-02") 一致
+func DayOf(t time.Time) Day {
+	y, m, d := t.Date()
+	return Day(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / secondsPerDay)
+}
+
+// Time 交易日的 UTC 零点
+func (d Day) Time() time.Time {
+	return time.Unix(int64(d)*secondsPerDay, 0).UTC()
+}
+
+// String 格式化为 YYYY-MM-DD，逐位写入而不经过 Time.Format 的布局解析，回测逐日输出日期时调用
+func (d Day) String() string {
+	y, m, dd := d.Time().Date()
+	if y < 0 || y > 9999 {
+		return d.Time().Format("2006-01-02")
+	}
+	b := [10]byte{
+		byte('0' + y/1000), byte('0' + y/100%10), byte('0' + y/10%10), byte('0' + y%10), '-',
+		byte('0' + m/10), byte('0' + m%10), '-',
+		byte('0' + dd/10), byte('0' + dd%10),
+	}
+	return string(b[:])
+}
+
+// rowBytes 每根K线占用的字节数：交易日 4 字节，其余六列各 8 字节
+const rowBytes = 4 + 6*8
+
+// Daily 一只股票的日K线，各列按下标对齐、交易日升序
+// 由 Slice 得到的子区间与原序列共享底层数组，调用方不能修改各列。
+type Daily struct {
+	Symbol   string
+	Exchange string
+	Days     []Day
+	Open     []float64
+	High     []float64
+	Low      []float64
+	Close    []float64
+	Volume   []int64
+	Amount   []float64
+}
+
+// Loader 按股票与时间范围加载列式日K线，如回测服务带缓存的K线加载器
+type Loader interface {
+	DailyColumns(ctx context.Context, symbol, exchange string, start, end time.Time) (*Daily, error)
+}
+
+// BarsLoader 以逐根日K线的查询（如 MarketRepository.GetDailyBars）实现 Loader，每次查询后转换，不缓存
+type BarsLoader func(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.DailyBar, error)
+
+// DailyColumns 实现 Loader
+func (f BarsLoader) DailyColumns(ctx context.Context, symbol, exchange string, start, end time.Time) (*Daily, error) {
+	bars, err := f(ctx, symbol, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return FromDailyBars(symbol, exchange, bars), nil
+}
+
+// FromDailyBars 将按时间升序的日K线转换为列式存储
+func FromDailyBars(symbol, exchange string, bars []*models.DailyBar) *Daily {
+	n := len(bars)
+	d := &Daily{
+		Symbol:   symbol,
+		Exchange: exchange,
+		Days:     make([]Day, n),
+		Open:     make([]float64, n),
+		High:     make([]float64, n),
+		Low:      make([]float64, n),
+		Close:    make([]float64, n),
+		Volume:   make([]int64, n),
+		Amount:   make([]float64, n),
+	}
+	for i, bar := range bars {
+		d.Days[i] = DayOf(bar.Date)
+		d.Open[i], d.High[i], d.Low[i], d.Close[i] = bar.Open, bar.High, bar.Low, bar.Close
+		d.Volume[i], d.Amount[i] = bar.Volume, bar.Amount
+	}
+	return d
+}
+
+// Len K线条数
+func (d *Daily) Len() int {
+	return len(d.Days)
+}
+
+// Bytes 各列占用的字节数
+func (d *Daily) Bytes() int64 {
+	return int64(d.Len()) * rowBytes
+}
+
+// Closes 交易日（YYYY-MM-DD）-> 收盘价，与 risk.ClosesByDate 相同，供基准、篮子点位等按日期索引的计算使用
+func (d *Daily) Closes() map[string]float64 {
+	closes := make(map[string]float64, d.Len())
+	for i, day := range d.Days {
+		closes[day.String()] = d.Close[i]
+	}
+	return closes
+}
+
+// Slice [from, to] 区间内的K线，与原序列共享底层数组
+func (d *Daily) Slice(from, to Day) *Daily {
+	i := sort.Search(d.Len(), func(i int) bool { return d.Days[i] >= from })
+	j := sort.Search(d.Len(), func(j int) bool { return d.Days[j] > to })
+	if j < i {
+		j = i
+	}
+	return &Daily{
+		Symbol:   d.Symbol,
+		Exchange: d.Exchange,
+		Days:     d.Days[i:j],
+		Open:     d.Open[i:j],
+		High:     d.High[i:j],
+		Low:      d.Low[i:j],
+		Close:    d.Close[i:j],
+		Volume:   d.Volume[i:j],
+		Amount:   d.Amount[i:j],
+	}
+}
+
+// Index 交易日在各列中的下标，没有该交易日的K线时返回 false
+func (d *Daily) Index(day Day) (int, bool) {
+	i := sort.Search(d.Len(), func(i int) bool { return d.Days[i] >= day })
+	return i, i < d.Len() && d.Days[i] == day
+}
+
+// IntersectIndex 两只股票共同的交易日（两边收盘价均大于 0）分别在 a、b 各列中的下标，按交易日升序归并
+// 按下标读取同一交易日的其他列或与各列对齐的切片（如封板状态），不经过按日期索引的 map。
+func IntersectIndex(a, b *Daily) (ia, ib []int) {
+	n := min(a.Len(), b.Len())
+	ia = make([]int, 0, n)
+	ib = make([]int, 0, n)
+	for i, j := 0, 0; i < a.Len() && j < b.Len(); {
+		switch {
+		case a.Days[i] < b.Days[j]:
+			i++
+		case a.Days[i] > b.Days[j]:
+			j++
+		default:
+			if a.Close[i] > 0 && b.Close[j] > 0 {
+				ia = append(ia, i)
+				ib = append(ib, j)
+			}
+			i++
+			j++
+		}
+	}
+	return ia, ib
+}
+
+// Intersect 两只股票共同的交易日（两边收盘价均大于 0）及各自的收盘价，见 IntersectIndex
+func Intersect(a, b *Daily) (days []Day, closeA, closeB []float64) {
+	ia, ib := IntersectIndex(a, b)
+	days = make([]Day, len(ia))
+	closeA = make([]float64, len(ia))
+	closeB = make([]float64, len(ia))
+	for k := range ia {
+		days[k] = a.Days[ia[k]]
+		closeA[k], closeB[k] = a.Close[ia[k]], b.Close[ib[k]]
+	}
+	return days, closeA, closeB
+}
@@ -0,0 +1,231 @@
+package columnar
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+func day(s string) Day {
+	t, _ := time.Parse("2006-01-02", s)
+	return DayOf(t)
+}
+
+func TestDayOf(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	// 按时间自身的时区取日期：北京时间 1 月 2 日 00:30 即 UTC 1 月 1 日 16:30
+	tm := time.Date(2024, 1, 2, 0, 30, 0, 0, shanghai)
+	if got := DayOf(tm).String(); got != tm.Format("2006-01-02") {
+		t.Errorf("DayOf = %s，期望 %s", got, tm.Format("2006-01-02"))
+	}
+	if d := day("1970-01-02"); d != 1 {
+		t.Errorf("1970-01-02 应为第 1 天，实际 %d", d)
+	}
+	if got := day("2024-02-29").Time(); !got.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Time = %v", got)
+	}
+}
+
+func TestDayString(t *testing.T) {
+	for d := DayOf(time.Date(1899, 12, 25, 0, 0, 0, 0, time.UTC)); d < DayOf(time.Date(2100, 1, 10, 0, 0, 0, 0, time.UTC)); d++ {
+		if got, want := d.String(), d.Time().Format("2006-01-02"); got != want {
+			t.Fatalf("第 %d 天格式化为 %s，期望 %s", d, got, want)
+		}
+	}
+}
+
+func TestFromDailyBarsRoundTrip(t *testing.T) {
+	bars := []*models.DailyBar{
+		{Symbol: "600519", Exchange: "SH", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Open: 10, High: 11, Low: 9, Close: 10.5, Volume: 100, Amount: 1050},
+		{Symbol: "600519", Exchange: "SH", Date: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Open: 10.5, High: 12, Low: 10, Close: 11.8, Volume: 200, Amount: 2360},
+	}
+	d := FromDailyBars("600519", "SH", bars)
+	if d.Len() != 2 || d.Bytes() != 2*rowBytes {
+		t.Fatalf("Len = %d Bytes = %d", d.Len(), d.Bytes())
+	}
+	if d.Close[1] != 11.8 || d.Volume[1] != 200 || d.Days[1].String() != "2024-01-03" {
+		t.Errorf("列数据错误: %+v", d)
+	}
+	if closes := d.Closes(); len(closes) != 2 || closes["2024-01-02"] != 10.5 || closes["2024-01-03"] != 11.8 {
+		t.Errorf("Closes = %v", closes)
+	}
+	if n := FromDailyBars("600519", "SH", nil).Len(); n != 0 {
+		t.Errorf("空K线 Len = %d", n)
+	}
+}
+
+func TestSlice(t *testing.T) {
+	d := &Daily{
+		Days:  []Day{day("2024-01-02"), day("2024-01-03"), day("2024-01-04"), day("2024-01-05")},
+		Close: []float64{1, 2, 3, 4},
+	}
+	fill(d)
+
+	s := d.Slice(day("2024-01-03"), day("2024-01-04"))
+	if s.Len() != 2 || s.Close[0] != 2 || s.Close[1] != 3 {
+		t.Errorf("Slice = %v %v", s.Days, s.Close)
+	}
+	if s := d.Slice(day("2023-12-01"), day("2024-01-01")); s.Len() != 0 {
+		t.Errorf("区间之前应为空，实际 %d 根", s.Len())
+	}
+	if s := d.Slice(day("2024-01-05"), day("2024-01-03")); s.Len() != 0 {
+		t.Errorf("起止颠倒应为空，实际 %d 根", s.Len())
+	}
+}
+
+// fill 补齐测试中未设置的列
+func fill(d *Daily) {
+	n := d.Len()
+	d.Open, d.High, d.Low = make([]float64, n), make([]float64, n), make([]float64, n)
+	d.Volume, d.Amount = make([]int64, n), make([]float64, n)
+}
+
+func TestIntersect(t *testing.T) {
+	a := &Daily{
+		Days:  []Day{day("2024-01-02"), day("2024-01-03"), day("2024-01-04"), day("2024-01-08")},
+		Close: []float64{10, 11, 0, 13},
+	}
+	b := &Daily{
+		Days:  []Day{day("2024-01-03"), day("2024-01-04"), day("2024-01-05"), day("2024-01-08")},
+		Close: []float64{20, 21, 22, 23},
+	}
+	days, ca, cb := Intersect(a, b)
+	// 01-04 A 收盘价为 0 不计入
+	want := []string{"2024-01-03", "2024-01-08"}
+	if len(days) != len(want) {
+		t.Fatalf("共同交易日 %v，期望 %v", days, want)
+	}
+	for i := range want {
+		if days[i].String() != want[i] {
+			t.Errorf("第 %d 个交易日 %s，期望 %s", i, days[i], want[i])
+		}
+	}
+	if ca[0] != 11 || cb[0] != 20 || ca[1] != 13 || cb[1] != 23 {
+		t.Errorf("收盘价 %v %v", ca, cb)
+	}
+
+	// 下标指向各自列中的同一交易日
+	ia, ib := IntersectIndex(a, b)
+	if fmt.Sprint(ia, ib) != "[1 3] [0 3]" {
+		t.Errorf("共同交易日下标 %v %v", ia, ib)
+	}
+	if i, ok := a.Index(day("2024-01-04")); !ok || i != 2 {
+		t.Errorf("Index(2024-01-04) = %d, %v", i, ok)
+	}
+	for _, missing := range []string{"2024-01-01", "2024-01-05", "2024-01-09"} {
+		if _, ok := a.Index(day(missing)); ok {
+			t.Errorf("Index(%s) 不应找到", missing)
+		}
+	}
+}
+
+// 与按日期 map 对齐的 pairs.Spread 结果一致
+func TestIntersectMatchesSpread(t *testing.T) {
+	history := syntheticHistory(2, 300)
+	cfg := &pairs.Config{}
+	cfg.ApplyDefaults()
+
+	want := pairs.Spread(risk.ClosesByDate(history[0]), risk.ClosesByDate(history[1]), cfg)
+	a, b := FromDailyBars("A", "SH", history[0]), FromDailyBars("B", "SZ", history[1])
+	days, ca, cb := Intersect(a, b)
+	dates := make([]string, len(days))
+	for i, d := range days {
+		dates[i] = d.String()
+	}
+	got := pairs.SpreadAligned(dates, ca, cb, cfg)
+	if len(got) != len(want) || len(got) == 0 {
+		t.Fatalf("价差点数 %d，期望 %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := *got[i], *want[i]
+		if (g.ZScore == nil) != (w.ZScore == nil) || g.ZScore != nil && *g.ZScore != *w.ZScore {
+			t.Fatalf("第 %d 个价差点 z-score 不一致", i)
+		}
+		g.ZScore, w.ZScore = nil, nil
+		if g != w {
+			t.Fatalf("第 %d 个价差点 %+v，期望 %+v", i, g, w)
+		}
+	}
+}
+
+// ============ 基准：5 年、300 只股票相邻两两配对的价差对齐 ============
+
+const (
+	benchSymbols = 300
+	benchDays    = 5 * 250
+)
+
+// syntheticHistory 生成 n 只股票的工作日日K线随机游走，每只股票随机停牌约 2% 的交易日
+func syntheticHistory(n, days int) [][]*models.DailyBar {
+	rng := rand.New(rand.NewSource(42))
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := make([][]*models.DailyBar, n)
+	for s := range history {
+		price := 10 + rng.Float64()*90
+		bars := make([]*models.DailyBar, 0, days)
+		for i, date := 0, start; i < days; date = date.AddDate(0, 0, 1) {
+			if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+				continue
+			}
+			i++
+			price *= math.Exp(rng.NormFloat64() * 0.02)
+			if rng.Float64() < 0.02 {
+				continue
+			}
+			bars = append(bars, &models.DailyBar{
+				Symbol: fmt.Sprintf("%06d", s), Exchange: "SH", Date: date,
+				Open: price, High: price * 1.01, Low: price * 0.99, Close: price,
+				Volume: 1e6, Amount: price * 1e6,
+			})
+		}
+		history[s] = bars
+	}
+	return history
+}
+
+var benchResult float64
+
+// 相邻股票两两配对，按日期 map 对齐后计算价差（引擎原先的做法）
+func BenchmarkAlignMaps(b *testing.B) {
+	history := syntheticHistory(benchSymbols, benchDays)
+	cfg := &pairs.Config{}
+	cfg.ApplyDefaults()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 1; i < len(history); i++ {
+			points := pairs.Spread(risk.ClosesByDate(history[i-1]), risk.ClosesByDate(history[i]), cfg)
+			benchResult += float64(len(points))
+		}
+	}
+}
+
+// 相邻股票两两配对，按交易日列归并对齐后计算价差
+func BenchmarkAlignColumns(b *testing.B) {
+	history := syntheticHistory(benchSymbols, benchDays)
+	cols := make([]*Daily, len(history))
+	for i, bars := range history {
+		cols[i] = FromDailyBars("", "", bars)
+	}
+	cfg := &pairs.Config{}
+	cfg.ApplyDefaults()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for i := 1; i < len(cols); i++ {
+			days, ca, cb := Intersect(cols[i-1], cols[i])
+			dates := make([]string, len(days))
+			for j, d := range days {
+				dates[j] = d.String()
+			}
+			points := pairs.SpreadAligned(dates, ca, cb, cfg)
+			benchResult += float64(len(points))
+		}
+	}
+}
@@ -1,17 +1,40 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Config 全局配置
 type Config struct {
-	Database DatabaseConfig `yaml:"database"`
-	Server   ServerConfig   `yaml:"server"`
-	Log      LogConfig      `yaml:"log"`
+	Database   DatabaseConfig   `yaml:"database"`
+	Server     ServerConfig     `yaml:"server"`
+	Log        LogConfig        `yaml:"log"`
+	CORS       CORSConfig       `yaml:"cors"`
+	Export     ExportConfig     `yaml:"export"`
+	Auth       AuthConfig       `yaml:"auth"`
+	Notify     NotifyConfig     `yaml:"notify"`
+	Alert      AlertConfig      `yaml:"alert"`
+	Regression RegressionConfig `yaml:"regression"`
+	Backtest   BacktestConfig   `yaml:"backtest"`
+	Internal   InternalConfig   `yaml:"internal"`
+	Calendar   CalendarConfig   `yaml:"calendar"`
+	Intraday   IntradayConfig   `yaml:"intraday"`
+
+	// 以下配置可热更新，见 Live
+	Cache     CacheConfig     `yaml:"cache"`
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+	Features  FeaturesConfig  `yaml:"features"`
+
+	// File 热更新的配置文件（CONFIG_FILE），为空时不监听
+	File string `yaml:"-"`
 }
 
 // DatabaseConfig 数据库配置
@@ -19,6 +42,14 @@ type DatabaseConfig struct {
 	Postgres PostgresConfig `yaml:"postgres"`
 	InfluxDB InfluxDBConfig `yaml:"influxdb"`
 	Redis    RedisConfig    `yaml:"redis"`
+	Retry    RetryConfig    `yaml:"retry"`
+}
+
+// RetryConfig PostgreSQL / InfluxDB 查询遇到连接中断等瞬时错误时的重试策略
+type RetryConfig struct {
+	MaxAttempts      int `yaml:"max_attempts"`       // 最多执行次数（含首次），1 表示不重试
+	InitialBackoffMs int `yaml:"initial_backoff_ms"` // 首次重试前的等待时间，之后每次翻倍
+	MaxBackoffMs     int `yaml:"max_backoff_ms"`     // 单次等待时间上限
 }
 
 // PostgresConfig PostgreSQL配置
@@ -31,6 +62,12 @@ type PostgresConfig struct {
 	SSLMode  string `yaml:"sslmode"`
 	MaxConns int    `yaml:"max_conns"`
 	MinConns int    `yaml:"min_conns"`
+
+	// 只读副本 DSN，为空时读写都使用主库；副本与主库使用相同的连接池参数
+	Replicas             []string `yaml:"replicas"`
+	ReplicaCheckInterval int      `yaml:"replica_check_interval"` // 副本健康检查间隔（秒）
+
+	SlowQueryMs int `yaml:"slow_query_ms"` // 慢查询阈值（毫秒），超过时记录语句与耗时，负数表示不记录
 }
 
 // InfluxDBConfig InfluxDB配置
@@ -38,8 +75,17 @@ type InfluxDBConfig struct {
 	URL       string `yaml:"url"`
 	Token     string `yaml:"token"`
 	Org       string `yaml:"org"`
-	Bucket    string `yaml:"bucket"`
+	Bucket    string `yaml:"bucket"` // 默认 Bucket，未单独配置的数据类型写入这里
 	BatchSize int    `yaml:"batch_size"`
+
+	// 按数据类型拆分 Bucket，键为 ticks/minute_bars/daily_bars/indicators
+	Buckets map[string]InfluxBucketConfig `yaml:"buckets"`
+}
+
+// InfluxBucketConfig 数据类型对应的 Bucket 与保留策略
+type InfluxBucketConfig struct {
+	Name          string `yaml:"name"`           // 为空时使用默认 Bucket
+	RetentionDays int    `yaml:"retention_days"` // 保留天数，0 表示永久保留；启动时按此创建或更新 Bucket
 }
 
 // RedisConfig Redis配置
@@ -54,8 +100,10 @@ type RedisConfig struct {
 type ServerConfig struct {
 	Port         int    `yaml:"port"`
 	Mode         string `yaml:"mode"`
-	ReadTimeout  int    `yaml:"read_timeout"`
-	WriteTimeout int    `yaml:"write_timeout"`
+	ReadTimeout  int    `yaml:"read_timeout"`  // 秒
+	WriteTimeout int    `yaml:"write_timeout"` // 秒
+	IdleTimeout  int    `yaml:"idle_timeout"`  // 秒
+	MaxBodySize  int64  `yaml:"max_body_size"` // 请求体上限（字节）
 }
 
 // LogConfig 日志配置
@@ -65,6 +113,151 @@ type LogConfig struct {
 	Output string `yaml:"output"`
 }
 
+// CacheConfig 缓存有效期
+type CacheConfig struct {
+	TTLs map[string]int `yaml:"ttls"` // 缓存名 -> 有效期（秒），未配置的缓存使用服务内置的默认值
+}
+
+// TTL 缓存名对应的有效期，未配置时返回 defaultTTL
+func (c CacheConfig) TTL(name string, defaultTTL time.Duration) time.Duration {
+	if seconds, ok := c.TTLs[name]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultTTL
+}
+
+// RateLimitConfig 网关按客户端 IP 的限流配置
+type RateLimitConfig struct {
+	RPS   float64 `yaml:"rps"`   // 每秒请求数，0 表示不限流
+	Burst int     `yaml:"burst"` // 允许的突发请求数
+}
+
+// FeaturesConfig 维护模式与功能开关，Redis 中有 features 键时以其为准（见 pkg/features）
+type FeaturesConfig struct {
+	Maintenance MaintenanceConfig      `yaml:"maintenance"`
+	Flags       map[string]FeatureFlag `yaml:"flags"` // 开关名 -> 灰度范围，未配置的开关视为已全量开放
+}
+
+// MaintenanceConfig 维护模式，开启后匹配的接口返回 503
+type MaintenanceConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	Routes     []string `yaml:"routes"`      // 路径前缀（不含 /api/<版本>），为空时所有接口维护
+	Message    string   `yaml:"message"`     // 返回给用户的提示
+	Until      string   `yaml:"until"`       // 预计结束时间（RFC3339），可选
+	RetryAfter int      `yaml:"retry_after"` // 建议重试间隔（秒），0 时不返回 Retry-After
+}
+
+// FeatureFlag 功能开关的灰度范围，满足任一条件的用户开放
+type FeatureFlag struct {
+	Enabled    bool   `yaml:"enabled"`    // 对所有用户开放
+	Percentage int    `yaml:"percentage"` // 按用户 ID 哈希开放的比例（0-100）
+	Users      []uint `yaml:"users"`      // 始终开放的用户 ID
+}
+
+// Validate 检查灰度比例与维护结束时间
+func (c FeaturesConfig) Validate() error {
+	for name, flag := range c.Flags {
+		if flag.Percentage < 0 || flag.Percentage > 100 {
+			return fmt.Errorf("功能开关 %s 的灰度比例需在 0-100 之间", name)
+		}
+	}
+	if c.Maintenance.Until != "" {
+		if _, err := time.Parse(time.RFC3339, c.Maintenance.Until); err != nil {
+			return fmt.Errorf("维护结束时间格式错误: %w", err)
+		}
+	}
+	if c.Maintenance.RetryAfter < 0 {
+		return errors.New("维护重试间隔不能为负数")
+	}
+	return nil
+}
+
+// CORSConfig 跨域配置（仅在网关生效）
+type CORSConfig struct {
+	AllowedOrigins   []string `yaml:"allowed_origins"` // 支持 "*" 与 "https://*.example.com" 形式
+	AllowedMethods   []string `yaml:"allowed_methods"`
+	AllowedHeaders   []string `yaml:"allowed_headers"`
+	ExposedHeaders   []string `yaml:"exposed_headers"`
+	AllowCredentials bool     `yaml:"allow_credentials"`
+	MaxAge           int      `yaml:"max_age"` // 预检结果缓存秒数
+}
+
+// ExportConfig 数据快照导出配置（S3 兼容的对象存储，如 MinIO）
+type ExportConfig struct {
+	Endpoint     string `yaml:"endpoint"` // 为空时不启用导出
+	AccessKey    string `yaml:"access_key"`
+	SecretKey    string `yaml:"secret_key"`
+	Bucket       string `yaml:"bucket"`
+	Prefix       string `yaml:"prefix"` // 快照对象的路径前缀
+	Region       string `yaml:"region"`
+	UseSSL       bool   `yaml:"use_ssl"`
+	ScheduleHour int    `yaml:"schedule_hour"` // 每日导出上一交易日数据的时刻（0~23），负数表示只手动导出
+}
+
+// Enabled 是否配置了对象存储
+func (e *ExportConfig) Enabled() bool {
+	return e.Endpoint != ""
+}
+
+// NotifyConfig 运维通知渠道，未配置任何渠道时告警只记录日志
+type NotifyConfig struct {
+	WebhookURL     string `yaml:"webhook_url"`     // 通用 Webhook，POST JSON
+	DingTalkURL    string `yaml:"dingtalk_url"`    // 钉钉群机器人 Webhook
+	DingTalkSecret string `yaml:"dingtalk_secret"` // 钉钉机器人加签密钥（SEC 开头），为空时不签名
+	WeComURL       string `yaml:"wecom_url"`       // 企业微信群机器人 Webhook
+
+	SMTPHost     string   `yaml:"smtp_host"` // 为空时不发送邮件
+	SMTPPort     int      `yaml:"smtp_port"`
+	SMTPUsername string   `yaml:"smtp_username"`
+	SMTPPassword string   `yaml:"smtp_password"`
+	EmailFrom    string   `yaml:"email_from"`
+	EmailTo      []string `yaml:"email_to"`
+}
+
+// AlertConfig 数据管道告警阈值，阈值为 0 表示不检查该项
+type AlertConfig struct {
+	SyncFailures     int     `yaml:"sync_failures"`      // 增量同步连续失败次数
+	QualityErrors    int     `yaml:"quality_errors"`     // 全市场数据质量检查 error 数
+	QualityErrorJump int     `yaml:"quality_error_jump"` // 质量 error 数较上一次检查的增量
+	StaleDays        int     `yaml:"stale_days"`         // 最新日K线距今超过该天数视为滞后
+	StaleRatio       float64 `yaml:"stale_ratio"`        // 滞后股票占比（0~1）
+	SilenceMinutes   int     `yaml:"silence_minutes"`    // 同一告警的静默期，期间持续触发不重复通知
+}
+
+// RegressionConfig 策略定期回归回测（回测服务按滚动窗口重新回测开启了该功能的策略）
+type RegressionConfig struct {
+	ScheduleHour int `yaml:"schedule_hour"` // 每日执行的时刻（0~23），负数表示不执行
+}
+
+// BacktestConfig 回测服务加载行情的进程内缓存，参数扫描等重复回测同一批股票与区间时复用已加载的K线
+type BacktestConfig struct {
+	BarCacheMB  int `yaml:"bar_cache_mb"`  // K线缓存的内存上限（MB），不大于 0 表示不缓存
+	BarCacheTTL int `yaml:"bar_cache_ttl"` // 缓存条目的有效期（秒），过期后重新查询以读到新同步或修正的K线
+}
+
+// CalendarConfig 交易日历，见 calendar
+type CalendarConfig struct {
+	Sessions map[string][]string `yaml:"sessions"` // 交易所 -> 连续竞价时段（HH:MM-HH:MM），未配置的交易所为 09:30-11:30、13:00-15:00
+	Holidays []string            `yaml:"holidays"` // 周末以外的休市日（YYYY-MM-DD）
+
+	// 行情时间所在时区，见 markettime；服务器本地时区不参与行情时间的计算
+	Timezone  string            `yaml:"timezone"`  // 默认时区（IANA 名称），为空时为 Asia/Shanghai
+	Timezones map[string]string `yaml:"timezones"` // 交易所 -> 时区，未配置的交易所使用默认时区
+}
+
+// IntradayConfig 盘中行情同步（数据同步服务在各交易所交易时段内定期拉取最新分钟K线）
+type IntradayConfig struct {
+	Interval int      `yaml:"interval"` // 同步间隔（秒），不大于 0 表示不同步
+	Symbols  []string `yaml:"symbols"`  // 同步的股票（symbol.exchange），为空时同步指数类股票池的最新成分股
+}
+
+// InternalConfig 网关与后端服务之间的请求签名，见 internalauth
+type InternalConfig struct {
+	Secret          string   `yaml:"secret"`           // 共享密钥，为空时网关不签名、服务不校验
+	PreviousSecrets []string `yaml:"previous_secrets"` // 轮换前的密钥，服务仍接受其签名
+	MaxSkew         int      `yaml:"max_skew"`         // 允许的时间偏差（秒）
+}
+
 // DSN 生成PostgreSQL连接字符串
 func (p *PostgresConfig) DSN() string {
 	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -92,41 +285,164 @@ func Load(path string) (*Config, error) {
 // LoadFromEnv 从环境变量加载配置
 func LoadFromEnv() *Config {
 	cfg := &Config{}
+
+	// 密钥支持从文件或 Vault 读取（见 LoadSecret），读取失败时直接退出
+	var secretErrs []error
+	secret := func(key, defaultValue string) string {
+		value, err := LoadSecret(key, defaultValue)
+		if err != nil {
+			secretErrs = append(secretErrs, err)
+		}
+		return value
+	}
 	
 	// PostgreSQL
 	cfg.Database.Postgres.Host = getEnv("POSTGRES_HOST", "localhost")
 	cfg.Database.Postgres.Port = getEnvInt("POSTGRES_PORT", 5432)
 	cfg.Database.Postgres.User = getEnv("POSTGRES_USER", "stock_user")
-	cfg.Database.Postgres.Password = getEnv("POSTGRES_PASSWORD", "stock_pass")
+	cfg.Database.Postgres.Password = secret("POSTGRES_PASSWORD", "stock_pass")
 	cfg.Database.Postgres.Database = getEnv("POSTGRES_DB", "stock_analysis")
 	cfg.Database.Postgres.SSLMode = getEnv("POSTGRES_SSLMODE", "disable")
 	cfg.Database.Postgres.MaxConns = getEnvInt("POSTGRES_MAX_CONNS", 20)
 	cfg.Database.Postgres.MinConns = getEnvInt("POSTGRES_MIN_CONNS", 5)
+	cfg.Database.Postgres.Replicas = getEnvList("POSTGRES_REPLICAS", nil)
+	cfg.Database.Postgres.ReplicaCheckInterval = getEnvInt("POSTGRES_REPLICA_CHECK_INTERVAL", 10)
+	cfg.Database.Postgres.SlowQueryMs = getEnvInt("POSTGRES_SLOW_QUERY_MS", 200)
 	
 	// InfluxDB
 	cfg.Database.InfluxDB.URL = getEnv("INFLUXDB_URL", "http://localhost:8086")
-	cfg.Database.InfluxDB.Token = getEnv("INFLUXDB_TOKEN", "")
+	cfg.Database.InfluxDB.Token = secret("INFLUXDB_TOKEN", "")
 	cfg.Database.InfluxDB.Org = getEnv("INFLUXDB_ORG", "stock_org")
 	cfg.Database.InfluxDB.Bucket = getEnv("INFLUXDB_BUCKET", "stock_market")
 	cfg.Database.InfluxDB.BatchSize = getEnvInt("INFLUXDB_BATCH_SIZE", 100)
+	cfg.Database.InfluxDB.Buckets = make(map[string]InfluxBucketConfig)
+	for _, dataType := range []string{"ticks", "minute_bars", "daily_bars", "indicators"} {
+		suffix := strings.ToUpper(dataType)
+		if name := getEnv("INFLUXDB_BUCKET_"+suffix, ""); name != "" {
+			cfg.Database.InfluxDB.Buckets[dataType] = InfluxBucketConfig{
+				Name:          name,
+				RetentionDays: getEnvInt("INFLUXDB_RETENTION_"+suffix, 0),
+			}
+		}
+	}
 	
-	// Redis
-	cfg.Database.Redis.Host = getEnv("REDIS_HOST", "localhost")
+	// Redis（行情缓存，未配置 REDIS_HOST 时不启用）
+	cfg.Database.Redis.Host = getEnv("REDIS_HOST", "")
 	cfg.Database.Redis.Port = getEnvInt("REDIS_PORT", 6379)
-	cfg.Database.Redis.Password = getEnv("REDIS_PASSWORD", "")
+	cfg.Database.Redis.Password = secret("REDIS_PASSWORD", "")
 	cfg.Database.Redis.DB = getEnvInt("REDIS_DB", 0)
+
+	// 查询重试
+	cfg.Database.Retry.MaxAttempts = getEnvInt("DB_RETRY_MAX_ATTEMPTS", 3)
+	cfg.Database.Retry.InitialBackoffMs = getEnvInt("DB_RETRY_INITIAL_BACKOFF_MS", 100)
+	cfg.Database.Retry.MaxBackoffMs = getEnvInt("DB_RETRY_MAX_BACKOFF_MS", 2000)
 	
 	// Server
 	cfg.Server.Port = getEnvInt("SERVER_PORT", 8080)
 	cfg.Server.Mode = getEnv("SERVER_MODE", "release")
 	cfg.Server.ReadTimeout = getEnvInt("SERVER_READ_TIMEOUT", 30)
 	cfg.Server.WriteTimeout = getEnvInt("SERVER_WRITE_TIMEOUT", 30)
+	cfg.Server.IdleTimeout = getEnvInt("SERVER_IDLE_TIMEOUT", 120)
+	cfg.Server.MaxBodySize = int64(getEnvInt("SERVER_MAX_BODY_SIZE", 4<<20))
 	
 	// Log
 	cfg.Log.Level = getEnv("LOG_LEVEL", "info")
 	cfg.Log.Format = getEnv("LOG_FORMAT", "json")
 	cfg.Log.Output = getEnv("LOG_OUTPUT", "stdout")
 	
+	// CORS
+	cfg.CORS.AllowedOrigins = getEnvList("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"})
+	cfg.CORS.AllowedMethods = getEnvList("CORS_ALLOWED_METHODS", nil)
+	cfg.CORS.AllowedHeaders = getEnvList("CORS_ALLOWED_HEADERS", nil)
+	cfg.CORS.ExposedHeaders = getEnvList("CORS_EXPOSED_HEADERS", nil)
+	cfg.CORS.AllowCredentials = getEnvBool("CORS_ALLOW_CREDENTIALS", false)
+	cfg.CORS.MaxAge = getEnvInt("CORS_MAX_AGE", 600)
+
+	// 数据快照导出
+	cfg.Export.Endpoint = getEnv("EXPORT_S3_ENDPOINT", "")
+	cfg.Export.AccessKey = getEnv("EXPORT_S3_ACCESS_KEY", "")
+	cfg.Export.SecretKey = secret("EXPORT_S3_SECRET_KEY", "")
+	cfg.Export.Bucket = getEnv("EXPORT_S3_BUCKET", "stock-snapshots")
+	cfg.Export.Prefix = getEnv("EXPORT_S3_PREFIX", "snapshots")
+	cfg.Export.Region = getEnv("EXPORT_S3_REGION", "")
+	cfg.Export.UseSSL = getEnvBool("EXPORT_S3_USE_SSL", false)
+	cfg.Export.ScheduleHour = getEnvInt("EXPORT_SCHEDULE_HOUR", 3)
+
+	// 运维通知与数据管道告警
+	cfg.Notify.WebhookURL = secret("NOTIFY_WEBHOOK_URL", "")
+	cfg.Notify.DingTalkURL = secret("NOTIFY_DINGTALK_URL", "")
+	cfg.Notify.DingTalkSecret = secret("NOTIFY_DINGTALK_SECRET", "")
+	cfg.Notify.WeComURL = secret("NOTIFY_WECOM_URL", "")
+	cfg.Notify.SMTPHost = getEnv("NOTIFY_SMTP_HOST", "")
+	cfg.Notify.SMTPPort = getEnvInt("NOTIFY_SMTP_PORT", 587)
+	cfg.Notify.SMTPUsername = getEnv("NOTIFY_SMTP_USERNAME", "")
+	cfg.Notify.SMTPPassword = secret("NOTIFY_SMTP_PASSWORD", "")
+	cfg.Notify.EmailFrom = getEnv("NOTIFY_EMAIL_FROM", "")
+	cfg.Notify.EmailTo = getEnvList("NOTIFY_EMAIL_TO", nil)
+	cfg.Alert.SyncFailures = getEnvInt("ALERT_SYNC_FAILURES", 3)
+	cfg.Alert.QualityErrors = getEnvInt("ALERT_QUALITY_ERRORS", 500)
+	cfg.Alert.QualityErrorJump = getEnvInt("ALERT_QUALITY_ERROR_JUMP", 200)
+	cfg.Alert.StaleDays = getEnvInt("ALERT_STALE_DAYS", 3)
+	cfg.Alert.StaleRatio = getEnvFloat("ALERT_STALE_RATIO", 0.2)
+	cfg.Alert.SilenceMinutes = getEnvInt("ALERT_SILENCE_MINUTES", 360)
+
+	// 策略定期回归回测，默认凌晨 4:00（数据同步与快照导出之后）
+	cfg.Regression.ScheduleHour = getEnvInt("REGRESSION_SCHEDULE_HOUR", 4)
+
+	// 回测K线缓存，默认 256MB、10 分钟
+	cfg.Backtest.BarCacheMB = getEnvInt("BACKTEST_BAR_CACHE_MB", 256)
+	cfg.Backtest.BarCacheTTL = getEnvInt("BACKTEST_BAR_CACHE_TTL", 600)
+
+	// 交易日历与盘中同步，交易时段按交易所配置，如 TRADING_SESSIONS_BJ=09:30-11:30,13:00-15:00
+	cfg.Calendar.Sessions = make(map[string][]string)
+	for _, exchange := range []string{"SH", "SZ", "BJ"} {
+		if sessions := getEnvList("TRADING_SESSIONS_"+exchange, nil); len(sessions) > 0 {
+			cfg.Calendar.Sessions[exchange] = sessions
+		}
+	}
+	cfg.Calendar.Holidays = getEnvList("TRADING_HOLIDAYS", nil)
+	cfg.Calendar.Timezone = getEnv("TRADING_TIMEZONE", "Asia/Shanghai")
+	cfg.Calendar.Timezones = make(map[string]string)
+	for _, exchange := range []string{"SH", "SZ", "BJ"} {
+		if tz := getEnv("TRADING_TIMEZONE_"+exchange, ""); tz != "" {
+			cfg.Calendar.Timezones[exchange] = tz
+		}
+	}
+	cfg.Intraday.Interval = getEnvInt("INTRADAY_SYNC_INTERVAL", 60)
+	cfg.Intraday.Symbols = getEnvList("INTRADAY_SYNC_SYMBOLS", nil)
+
+	// 网关与服务之间的请求签名，各环境分别配置；生产环境应设置以拒绝绕过网关的请求
+	cfg.Internal.Secret = secret("INTERNAL_AUTH_SECRET", "")
+	cfg.Internal.PreviousSecrets = getEnvList("INTERNAL_AUTH_PREVIOUS_SECRETS", nil)
+	cfg.Internal.MaxSkew = getEnvInt("INTERNAL_AUTH_MAX_SKEW", 30)
+
+	// 认证
+	cfg.Auth.Algorithm = getEnv("JWT_ALGORITHM", "HS256")
+	cfg.Auth.KeyID = getEnv("JWT_KEY_ID", "default")
+	cfg.Auth.JWTSecret = secret("JWT_SECRET", "your-secret-key")
+	cfg.Auth.PreviousSecrets = parseKeyList(secret("JWT_PREVIOUS_SECRETS", ""))
+	cfg.Auth.PrivateKey = secret("JWT_PRIVATE_KEY", "")
+	cfg.Auth.PublicKeys = make(map[string]string)
+	for kid, path := range parseKeyList(getEnv("JWT_PUBLIC_KEYS", "")) {
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			secretErrs = append(secretErrs, fmt.Errorf("读取公钥 %s 失败: %w", kid, err))
+			continue
+		}
+		cfg.Auth.PublicKeys[kid] = string(pem)
+	}
+	// 运维脚本调用数据同步接口的 API Key，逗号分隔，可同时配置新旧两个以便轮换
+	cfg.Auth.APIKeys = splitList(secret("DATA_API_KEYS", ""))
+
+	// 可热更新的配置，CONFIG_FILE 中的同名配置优先
+	cfg.RateLimit.RPS = getEnvFloat("RATE_LIMIT_RPS", 0)
+	cfg.RateLimit.Burst = getEnvInt("RATE_LIMIT_BURST", 0)
+	cfg.File = getEnv("CONFIG_FILE", "")
+
+	if err := errors.Join(secretErrs...); err != nil {
+		log.Fatalf("加载密钥失败: %v", err)
+	}
+	
 	cfg.setDefaults()
 	return cfg
 }
@@ -139,6 +455,24 @@ func (c *Config) setDefaults() {
 	if c.Database.Postgres.MinConns == 0 {
 		c.Database.Postgres.MinConns = 5
 	}
+	if c.Database.Postgres.ReplicaCheckInterval <= 0 {
+		c.Database.Postgres.ReplicaCheckInterval = 10
+	}
+	if c.Database.Postgres.SlowQueryMs == 0 {
+		c.Database.Postgres.SlowQueryMs = 200
+	}
+	if c.Database.Redis.Port == 0 {
+		c.Database.Redis.Port = 6379
+	}
+	if c.Database.Retry.MaxAttempts <= 0 {
+		c.Database.Retry.MaxAttempts = 3
+	}
+	if c.Database.Retry.InitialBackoffMs <= 0 {
+		c.Database.Retry.InitialBackoffMs = 100
+	}
+	if c.Database.Retry.MaxBackoffMs < c.Database.Retry.InitialBackoffMs {
+		c.Database.Retry.MaxBackoffMs = max(2000, c.Database.Retry.InitialBackoffMs)
+	}
 	if c.Database.InfluxDB.BatchSize == 0 {
 		c.Database.InfluxDB.BatchSize = 100
 	}
@@ -151,6 +485,36 @@ func (c *Config) setDefaults() {
 	if c.Server.WriteTimeout == 0 {
 		c.Server.WriteTimeout = 30
 	}
+	if c.Server.IdleTimeout == 0 {
+		c.Server.IdleTimeout = 120
+	}
+	if c.Server.MaxBodySize == 0 {
+		c.Server.MaxBodySize = 4 << 20
+	}
+	if c.Export.Bucket == "" {
+		c.Export.Bucket = "stock-snapshots"
+	}
+	if c.Notify.SMTPPort == 0 {
+		c.Notify.SMTPPort = 587
+	}
+	if c.Alert.SilenceMinutes <= 0 {
+		c.Alert.SilenceMinutes = 360
+	}
+	if c.RateLimit.RPS > 0 && c.RateLimit.Burst <= 0 {
+		c.RateLimit.Burst = max(1, int(c.RateLimit.RPS*2))
+	}
+	if c.Auth.Algorithm == "" {
+		c.Auth.Algorithm = "HS256"
+	}
+	if c.Auth.KeyID == "" {
+		c.Auth.KeyID = "default"
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		c.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+	}
+	if len(c.CORS.AllowedHeaders) == 0 {
+		c.CORS.AllowedHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	}
 }
 
 func getEnv(key, defaultValue string) string {
@@ -169,3 +533,52 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if result, err := strconv.ParseFloat(value, 64); err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if result, err := strconv.ParseBool(value); err == nil {
+			return result
+		}
+	}
+	return defaultValue
+}
+
+// parseKeyList 解析逗号分隔的 kid=值 列表
+func parseKeyList(value string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range strings.Split(value, ",") {
+		if kid, v, ok := strings.Cut(strings.TrimSpace(item), "="); ok && kid != "" && v != "" {
+			result[kid] = v
+		}
+	}
+	return result
+}
+
+// getEnvList 读取逗号分隔的列表
+func getEnvList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	return splitList(value)
+}
+
+// splitList 拆分逗号分隔的列表，忽略空项
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoadSecret(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "jwt_secret")
+	os.WriteFile(path, []byte("from-file\n"), 0o600)
+
+	t.Setenv("TEST_SECRET", "from-env")
+	if v, err := LoadSecret("TEST_SECRET", "default"); err != nil || v != "from-env" {
+		t.Fatalf("应读取环境变量，实际 %q %v", v, err)
+	}
+	t.Setenv("TEST_SECRET_FILE", path)
+	if v, err := LoadSecret("TEST_SECRET", "default"); err != nil || v != "from-file" {
+		t.Fatalf("_FILE 应优先且去掉末尾换行，实际 %q %v", v, err)
+	}
+	t.Setenv("TEST_SECRET_FILE", filepath.Join(dir, "missing"))
+	if _, err := LoadSecret("TEST_SECRET", "default"); err == nil {
+		t.Fatal("_FILE 指向的文件不存在时应返回错误")
+	}
+	if v, err := LoadSecret("TEST_UNSET_SECRET", "default"); err != nil || v != "default" {
+		t.Fatalf("未配置时应返回默认值，实际 %q %v", v, err)
+	}
+}
+
+func TestLoadSecretFromVault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/stock": // KV v2
+			w.Write([]byte(`{"data":{"data":{"jwt_secret":"v2-secret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/stock": // KV v1
+			w.Write([]byte(`{"data":{"influx_token":"v1-token"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+	t.Setenv("TEST_JWT", "vault:secret/data/stock#jwt_secret")
+	t.Setenv("TEST_TOKEN", "vault:kv/stock#influx_token")
+	t.Setenv("TEST_MISSING", "vault:secret/data/stock#missing")
+
+	if v, err := LoadSecret("TEST_JWT", ""); err != nil || v != "v2-secret" {
+		t.Fatalf("应从 KV v2 读取，实际 %q %v", v, err)
+	}
+	if v, err := LoadSecret("TEST_TOKEN", ""); err != nil || v != "v1-token" {
+		t.Fatalf("应从 KV v1 读取，实际 %q %v", v, err)
+	}
+	if _, err := LoadSecret("TEST_MISSING", ""); err == nil {
+		t.Fatal("字段不存在时应返回错误")
+	}
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	for _, secret := range []string{"", "your-secret-key", "your-secret-key-here", "short"} {
+		a := AuthConfig{JWTSecret: secret}
+		if err := a.Validate("release"); err == nil {
+			t.Errorf("release 模式下密钥 %q 应校验失败", secret)
+		}
+		if err := a.Validate("debug"); err != nil {
+			t.Errorf("debug 模式下只应记录警告，实际 %v", err)
+		}
+	}
+	a := AuthConfig{JWTSecret: strings.Repeat("k", minJWTSecretLen)}
+	if err := a.Validate("release"); err != nil {
+		t.Errorf("足够长的随机密钥应校验通过: %v", err)
+	}
+	a.APIKeys = []string{strings.Repeat("a", minAPIKeyLen), "short"}
+	if err := a.Validate("debug"); err == nil {
+		t.Error("API Key 长度不足时应校验失败")
+	}
+}
+
+func TestLiveReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("log:\n  level: warn\ncache:\n  ttls:\n    quote: 5\n"), 0o644)
+
+	base := &Config{Log: LogConfig{Level: "info"}, RateLimit: RateLimitConfig{RPS: 10, Burst: 20}, File: path}
+	live, err := NewLive(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+
+	var mu sync.Mutex
+	var levels []string
+	live.OnChange(func(c *Config) {
+		mu.Lock()
+		levels = append(levels, c.Log.Level)
+		mu.Unlock()
+	})
+	cfg := live.Get()
+	if cfg.Log.Level != "warn" || cfg.Cache.TTL("quote", time.Minute) != 5*time.Second || cfg.RateLimit.RPS != 10 {
+		t.Fatalf("启动时应以配置文件覆盖可热更新的配置，实际 %+v", cfg)
+	}
+	if cfg.Cache.TTL("stocks", time.Hour) != time.Hour {
+		t.Fatal("未配置的缓存应使用默认有效期")
+	}
+
+	// 无效配置不生效
+	os.WriteFile(path, []byte("log:\n  level: verbose\n"), 0o644)
+	if err := live.Reload(); err == nil {
+		t.Fatal("不支持的日志级别应校验失败")
+	}
+	if live.Get().Log.Level != "warn" {
+		t.Fatal("校验失败时应保留当前配置")
+	}
+
+	// 文件变化后自动重新加载，删除的配置项恢复为启动时的值
+	os.WriteFile(path, []byte("rate_limit:\n  rps: 2\n"), 0o644)
+	deadline := time.Now().Add(2 * time.Second)
+	for live.Get().RateLimit.RPS != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("配置文件变化后应自动重新加载")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cfg = live.Get()
+	if cfg.Log.Level != "info" || cfg.RateLimit.Burst != 4 || len(cfg.Cache.TTLs) != 0 {
+		t.Fatalf("文件中未出现的配置项应恢复为启动时的值，实际 %+v", cfg)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(levels) < 2 || levels[0] != "warn" || levels[len(levels)-1] != "info" {
+		t.Fatalf("注册时与每次变化后都应通知订阅者，实际 %v", levels)
+	}
+}
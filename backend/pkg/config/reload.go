@@ -0,0 +1,176 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+)
+
+// ============ 配置热更新 ============
+
+// Live 可热更新的配置
+// 监听 File（CONFIG_FILE）所在的目录，文件变化时重新读取其中的 log.level、cache、rate_limit、features，覆盖在启动配置之上后通知订阅者；
+// 文件中的其他配置不会生效，修改后需重启服务。新配置校验失败时保留当前配置。
+type Live struct {
+	base    *Config
+	current atomic.Pointer[Config]
+	watcher *fsnotify.Watcher // 未配置文件时为空
+
+	mu    sync.Mutex
+	hooks []func(*Config)
+	last  []byte // 上次加载的文件内容，内容未变化时不重复通知
+}
+
+// reloadable 配置文件中可热更新的部分，文件中未出现的配置项使用启动时的值
+type reloadable struct {
+	Log struct {
+		Level string `yaml:"level"`
+	} `yaml:"log"`
+	Cache     *CacheConfig     `yaml:"cache"`
+	RateLimit *RateLimitConfig `yaml:"rate_limit"`
+	Features  *FeaturesConfig  `yaml:"features"`
+}
+
+// NewLive 加载配置文件并开始监听，文件无法读取或校验失败时返回错误；未配置文件时配置固定为 cfg
+func NewLive(cfg *Config) (*Live, error) {
+	l := &Live{base: cfg}
+	l.current.Store(cfg)
+	if cfg.File == "" {
+		return l, nil
+	}
+	if err := l.Reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("监听配置文件失败: %w", err)
+	}
+	// 监听所在目录而不是文件本身：编辑器保存与 Kubernetes ConfigMap 更新都以替换文件的方式完成
+	if err := watcher.Add(filepath.Dir(cfg.File)); err != nil {
+		watcher.Close()
+		return nil, fmt.Errorf("监听配置文件失败: %w", err)
+	}
+	l.watcher = watcher
+	go l.watch()
+	return l, nil
+}
+
+// Get 当前配置，返回值只读
+func (l *Live) Get() *Config {
+	return l.current.Load()
+}
+
+// OnChange 注册配置变化的回调，注册时以当前配置调用一次
+func (l *Live) OnChange(fn func(*Config)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.hooks = append(l.hooks, fn)
+	fn(l.current.Load())
+}
+
+// Reload 重新读取配置文件，内容未变化时不通知订阅者
+func (l *Live) Reload() error {
+	data, err := os.ReadFile(l.base.File)
+	if err != nil {
+		return fmt.Errorf("读取配置文件失败: %w", err)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.last != nil && bytes.Equal(data, l.last) {
+		return nil
+	}
+	next, err := l.apply(data)
+	if err != nil {
+		return err
+	}
+	if l.last != nil {
+		log.Printf("已重新加载配置文件 %s", l.base.File)
+	}
+	l.last = data
+	l.current.Store(next)
+	for _, fn := range l.hooks {
+		fn(next)
+	}
+	return nil
+}
+
+// apply 将配置文件中可热更新的部分覆盖到启动配置上
+func (l *Live) apply(data []byte) (*Config, error) {
+	var file reloadable
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+
+	next := *l.base
+	if file.Log.Level != "" {
+		next.Log.Level = file.Log.Level
+	}
+	if file.Cache != nil {
+		next.Cache = *file.Cache
+	}
+	if file.RateLimit != nil {
+		next.RateLimit = *file.RateLimit
+	}
+	if file.Features != nil {
+		next.Features = *file.Features
+	}
+	next.setDefaults()
+
+	switch strings.ToLower(next.Log.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		return nil, fmt.Errorf("不支持的日志级别 %q", next.Log.Level)
+	}
+	for name, seconds := range next.Cache.TTLs {
+		if seconds < 0 {
+			return nil, fmt.Errorf("缓存 %s 的有效期不能为负数", name)
+		}
+	}
+	if next.RateLimit.RPS < 0 {
+		return nil, fmt.Errorf("限流速率不能为负数")
+	}
+	if err := next.Features.Validate(); err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// watch 目录内有变化时重新读取配置文件，内容未变化时忽略
+func (l *Live) watch() {
+	for {
+		select {
+		case _, ok := <-l.watcher.Events:
+			if !ok {
+				return
+			}
+			// 替换文件的过程中文件可能短暂不存在，随后的创建事件会再次触发加载
+			if err := l.Reload(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				log.Printf("%v，继续使用当前配置", err)
+			}
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("监听配置文件出错: %v", err)
+		}
+	}
+}
+
+// Close 停止监听
+func (l *Live) Close() {
+	if l.watcher != nil {
+		l.watcher.Close()
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// ============ 密钥 ============
+//
+// 密钥（JWT 密钥、数据库密码、InfluxDB Token 等）按以下顺序读取：
+//   1. <KEY>_FILE 指向的文件（Docker/Kubernetes secrets 挂载），去掉末尾换行
+//   2. 环境变量 <KEY>；值为 vault:<路径>#<字段> 时从 Vault KV 读取，地址与令牌取自 VAULT_ADDR、VAULT_TOKEN（或 VAULT_TOKEN_FILE）
+//   3. 默认值
+
+// vaultPrefix Vault 引用的前缀
+const vaultPrefix = "vault:"
+
+// insecureJWTSecrets 示例配置中出现过的 JWT 密钥，生产环境不允许使用
+var insecureJWTSecrets = map[string]bool{
+	"":                     true,
+	"your-secret-key":      true,
+	"your-secret-key-here": true,
+	"secret":               true,
+	"changeme":             true,
+}
+
+// minJWTSecretLen HS256 密钥的最短长度（字节）
+const minJWTSecretLen = 32
+
+// minAPIKeyLen API Key 的最短长度（字节）
+const minAPIKeyLen = 32
+
+// vaultClient 读取密钥的 HTTP 客户端
+var vaultClient = &http.Client{Timeout: 5 * time.Second}
+
+// LoadSecret 读取密钥，未配置时返回默认值
+func LoadSecret(key, defaultValue string) (string, error) {
+	if path := os.Getenv(key + "_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("读取 %s_FILE 失败: %w", key, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	if ref, ok := strings.CutPrefix(value, vaultPrefix); ok {
+		secret, err := readVaultSecret(ref)
+		if err != nil {
+			return "", fmt.Errorf("从 Vault 读取 %s 失败: %w", key, err)
+		}
+		return secret, nil
+	}
+	return value, nil
+}
+
+// readVaultSecret 读取 <路径>#<字段> 形式的 Vault KV 密钥，兼容 KV v1 与 v2（v2 的路径包含 data/）
+func readVaultSecret(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("引用格式应为 vault:<路径>#<字段>，实际 %q", ref)
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", errors.New("未配置 VAULT_ADDR")
+	}
+	token, err := LoadSecret("VAULT_TOKEN", "")
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s 返回 %d", path, resp.StatusCode)
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	data := body.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, v2 := data["metadata"]; v2 {
+			data = nested
+		}
+	}
+	value, ok := data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("%s 中没有字段 %s", path, field)
+	}
+	return value, nil
+}
+
+// AuthConfig JWT 签发与校验配置，密钥的使用见 pkg/auth
+// 轮换密钥时先以新 kid 签发，旧密钥移入 PreviousSecrets / PublicKeys，待旧 Token 过期后再删除。
+type AuthConfig struct {
+	Algorithm       string            `yaml:"algorithm"`        // HS256（默认）或 RS256
+	KeyID           string            `yaml:"key_id"`           // 当前签名密钥的 kid，写入新签发 Token 的头部
+	JWTSecret       string            `yaml:"jwt_secret"`       // HS256 当前密钥
+	PreviousSecrets map[string]string `yaml:"previous_secrets"` // kid -> 已轮换的 HS256 密钥，只用于校验
+	PrivateKey      string            `yaml:"private_key"`      // RS256 签名私钥（PEM），只有签发 Token 的服务需要
+	PublicKeys      map[string]string `yaml:"public_keys"`      // kid -> RS256 公钥（PEM），含轮换前的公钥
+	APIKeys         []string          `yaml:"api_keys"`         // 运维脚本调用数据同步接口的 API Key（X-API-Key），为空时只接受管理员 Token
+}
+
+// Validate 检查 JWT 密钥：release/production 模式下 HS256 使用示例密钥或长度不足时返回错误，其余模式只记录警告；
+// RS256 没有任何可用于校验的密钥、API Key 长度不足时总是返回错误。签发或校验 Token 的服务在启动时调用，避免以公开的默认密钥运行。
+func (a *AuthConfig) Validate(mode string) error {
+	for _, key := range a.APIKeys {
+		if len(key) < minAPIKeyLen {
+			return fmt.Errorf("DATA_API_KEYS 中存在长度不足 %d 字节的 API Key", minAPIKeyLen)
+		}
+	}
+	if a.Algorithm == "RS256" {
+		if a.PrivateKey == "" && len(a.PublicKeys) == 0 {
+			return errors.New("JWT_ALGORITHM 为 RS256 时需配置 JWT_PRIVATE_KEY 或 JWT_PUBLIC_KEYS")
+		}
+		return nil
+	}
+
+	var problem string
+	switch {
+	case insecureJWTSecrets[a.JWTSecret]:
+		problem = "JWT_SECRET 未设置或仍为示例值"
+	case len(a.JWTSecret) < minJWTSecretLen:
+		problem = fmt.Sprintf("JWT_SECRET 长度不足 %d 字节", minJWTSecretLen)
+	}
+	for kid, secret := range a.PreviousSecrets {
+		if problem == "" && len(secret) < minJWTSecretLen {
+			problem = fmt.Sprintf("JWT_PREVIOUS_SECRETS 中 %s 的长度不足 %d 字节", kid, minJWTSecretLen)
+		}
+	}
+	if problem == "" {
+		return nil
+	}
+	if mode == "release" || mode == "production" {
+		return errors.New(problem + "，请配置随机生成的密钥（可通过 JWT_SECRET_FILE 或 Vault 提供）")
+	}
+	log.Printf("警告: %s，仅可用于开发环境", problem)
+	return nil
+}
@@ -2,105 +2,378 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	"github.com/influxdata/influxdb-client-go/v2/api"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
+	"github.com/influxdata/influxdb-client-go/v2/domain"
 
 	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/metrics"
 )
 
+// 数据类型，各自可写入独立的 Bucket 并设置不同的保留策略
+const (
+	DataTicks      = "ticks"       // 逐笔/快照行情
+	DataMinuteBars = "minute_bars" // 分钟K线
+	DataDailyBars  = "daily_bars"  // 日K线
+	DataIndicators = "indicators"  // 技术指标
+)
+
+// DataTypes 支持单独配置 Bucket 的数据类型
+var DataTypes = []string{DataTicks, DataMinuteBars, DataDailyBars, DataIndicators}
+
+// ErrInfluxUnavailable InfluxDB 尚未连接，查询直接失败而不是等待超时
+var ErrInfluxUnavailable = errors.New("InfluxDB 不可用")
+
+const influxConnectTimeout = 5 * time.Second
+
+// influxReconnectInterval 后台重连的初始间隔，之后按指数退避增加到 maxReconnectBackoff
+var influxReconnectInterval = 10 * time.Second
+
 // InfluxClient InfluxDB客户端
 type InfluxClient struct {
-	client    influxdb2.Client
-	writeAPI  api.WriteAPI
-	queryAPI  api.QueryAPI
-	deleteAPI api.DeleteAPI
+	conn      atomic.Pointer[influxConn] // 连接中断后重建，读写时取当前连接
 	org       string
 	bucket    string
 	batchSize int
+	cfg       *config.InfluxDBConfig
+	retry     *RetryPolicy
+
+	connected atomic.Bool   // 已连通并完成 Bucket 初始化
+	stop      chan struct{} // 关闭时停止后台重连
+	closeOnce sync.Once
+
+	buckets     map[string]string // 数据类型 -> Bucket，未配置的类型使用默认 Bucket
+	writeErrors atomic.Int64      // 异步写入失败次数
+}
+
+// influxConn 一个底层客户端及其读写API
+type influxConn struct {
+	client       influxdb2.Client
+	writeAPI     api.WriteAPI
+	queryAPI     api.QueryAPI
+	deleteAPI    api.DeleteAPI
+	writeAPIs    map[string]api.WriteAPI         // Bucket -> 异步写入API
+	blockingAPIs map[string]api.WriteAPIBlocking // Bucket -> 同步写入API，用于需要确认结果的批量导入
 }
 
-// NewInfluxClient 创建InfluxDB客户端
+// NewInfluxClient 创建InfluxDB客户端，连接失败时返回错误
 func NewInfluxClient(cfg *config.InfluxDBConfig) (*InfluxClient, error) {
-	// 创建客户端
-	client := influxdb2.NewClient(cfg.URL, cfg.Token)
-	
-	// 测试连接
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	c := newInfluxClient(cfg)
+
+	ctx, cancel := context.WithTimeout(context.Background(), influxConnectTimeout)
 	defer cancel()
-	
-	if _, err := client.Health(ctx); err != nil {
-		client.Close()
-		return nil, fmt.Errorf("连接InfluxDB失败: %w", err)
+	if err := c.connect(ctx, c.conn.Load()); err != nil {
+		c.conn.Load().client.Close()
+		return nil, err
 	}
 
-	// 创建写入API（异步批量写入）
-	writeAPI := client.WriteAPI(cfg.Org, cfg.Bucket)
-	
-	// 创建查询和删除API
-	queryAPI := client.QueryAPI(cfg.Org)
-	deleteAPI := client.DeleteAPI()
-
-	return &InfluxClient{
-		client:    client,
-		writeAPI:  writeAPI,
-		queryAPI:  queryAPI,
-		deleteAPI: deleteAPI,
+	c.start()
+	go c.watch()
+	return c, nil
+}
+
+// NewLazyInfluxClient 创建InfluxDB客户端，连接失败时不返回错误而是在后台定期重试
+// 连接成功前查询返回 ErrInfluxUnavailable、健康检查失败，写入由客户端缓冲并重试；用于 InfluxDB 故障时仍需启动的服务。
+func NewLazyInfluxClient(cfg *config.InfluxDBConfig) *InfluxClient {
+	c := newInfluxClient(cfg)
+	c.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), influxConnectTimeout)
+	defer cancel()
+	err := c.connect(ctx, c.conn.Load())
+	if err != nil {
+		log.Printf("%v，将在后台重试", err)
+	}
+	go c.watch()
+	return c
+}
+
+// newInfluxClient 创建客户端与各 Bucket 的读写API，不检查连接
+func newInfluxClient(cfg *config.InfluxDBConfig) *InfluxClient {
+	c := &InfluxClient{
 		org:       cfg.Org,
 		bucket:    cfg.Bucket,
 		batchSize: cfg.BatchSize,
-	}, nil
+		cfg:       cfg,
+		retry:     defaultRetryPolicy(),
+		stop:      make(chan struct{}),
+		buckets:   make(map[string]string),
+	}
+
+	// 按数据类型拆分的 Bucket
+	for dataType, bucketCfg := range cfg.Buckets {
+		if bucketCfg.Name == "" || bucketCfg.Name == cfg.Bucket {
+			continue
+		}
+		c.buckets[dataType] = bucketCfg.Name
+	}
+	c.conn.Store(c.newConn())
+	return c
 }
 
-// Close 关闭客户端
-func (c *InfluxClient) Close() {
-	if c.writeAPI != nil {
-		c.writeAPI.Flush()
+// newConn 创建底层客户端与各 Bucket 的读写API
+func (c *InfluxClient) newConn() *influxConn {
+	client := influxdb2.NewClient(c.cfg.URL, c.cfg.Token)
+
+	// 创建写入API（异步批量写入）
+	writeAPI := client.WriteAPI(c.org, c.bucket)
+
+	conn := &influxConn{
+		client:       client,
+		writeAPI:     writeAPI,
+		queryAPI:     client.QueryAPI(c.org),
+		deleteAPI:    client.DeleteAPI(),
+		writeAPIs:    map[string]api.WriteAPI{c.bucket: writeAPI},
+		blockingAPIs: map[string]api.WriteAPIBlocking{c.bucket: client.WriteAPIBlocking(c.org, c.bucket)},
+	}
+	for _, bucket := range c.buckets {
+		if _, ok := conn.writeAPIs[bucket]; !ok {
+			conn.writeAPIs[bucket] = client.WriteAPI(c.org, bucket)
+			conn.blockingAPIs[bucket] = client.WriteAPIBlocking(c.org, bucket)
+		}
+	}
+	return conn
+}
+
+// connect 检查连接，并按保留策略创建或更新按数据类型拆分的 Bucket
+func (c *InfluxClient) connect(ctx context.Context, conn *influxConn) error {
+	if _, err := conn.client.Health(ctx); err != nil {
+		return fmt.Errorf("连接InfluxDB失败: %w", err)
+	}
+	for dataType, bucketCfg := range c.cfg.Buckets {
+		if bucketCfg.Name == "" || bucketCfg.Name == c.cfg.Bucket {
+			continue
+		}
+		if err := c.ensureBucket(ctx, conn.client, bucketCfg); err != nil {
+			return fmt.Errorf("初始化 %s Bucket 失败: %w", dataType, err)
+		}
+	}
+	c.connected.Store(true)
+	return nil
+}
+
+// watch 已连接时定期检查连接，失败时重建客户端并按指数退避重连；未连接时直接进入重连
+func (c *InfluxClient) watch() {
+	rebuild := false // 启动时未连上的客户端沿用已创建的连接，此后的中断才重建
+	for {
+		if !c.connected.Load() {
+			if !c.reconnect(rebuild) {
+				return
+			}
+			log.Printf("InfluxDB 已连接")
+		}
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(connCheckInterval):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), influxConnectTimeout)
+		_, err := c.conn.Load().client.Health(ctx)
+		cancel()
+		if err != nil {
+			log.Printf("InfluxDB 连接中断: %v", err)
+			c.connected.Store(false)
+			rebuild = true
+		}
+	}
+}
+
+// reconnect 按指数退避重试连接，成功返回 true，客户端关闭时返回 false
+// rebuild 时使用新建的底层客户端（丢弃可能指向旧地址的连接池），连接成功后替换旧客户端，旧客户端刷新缓冲后关闭。
+func (c *InfluxClient) reconnect(rebuild bool) bool {
+	conn := c.conn.Load()
+	if rebuild {
+		conn = c.newConn()
+	}
+
+	b := backoff{min: influxReconnectInterval, max: maxReconnectBackoff}
+	for {
+		select {
+		case <-c.stop:
+			if rebuild {
+				conn.client.Close()
+			}
+			return false
+		case <-time.After(b.next()):
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), influxConnectTimeout)
+		err := c.connect(ctx, conn)
+		cancel()
+		if err != nil {
+			continue
+		}
+		if rebuild {
+			c.watchConn(conn)
+			old := c.conn.Swap(conn)
+			go old.client.Close()
+		}
+		return true
 	}
-	if c.client != nil {
-		c.client.Close()
+}
+
+// start 启动写入错误统计并注册指标
+func (c *InfluxClient) start() {
+	c.watchConn(c.conn.Load())
+	metrics.Register("influxdb", c.collectMetrics)
+}
+
+// watchConn 统计连接上各 Bucket 的异步写入错误
+func (c *InfluxClient) watchConn(conn *influxConn) {
+	for bucket, writeAPI := range conn.writeAPIs {
+		go c.watchWriteErrors(bucket, writeAPI.Errors())
+	}
+}
+
+// Connected 是否已连接，延迟连接的客户端在连接成功前返回 false
+func (c *InfluxClient) Connected() bool {
+	return c != nil && c.connected.Load()
+}
+
+// watchWriteErrors 读取异步写入的错误通道，客户端关闭时通道随之关闭
+func (c *InfluxClient) watchWriteErrors(bucket string, errs <-chan error) {
+	for err := range errs {
+		c.writeErrors.Add(1)
+		log.Printf("InfluxDB 写入 %s 失败: %v", bucket, err)
+	}
+}
+
+// collectMetrics 采集写入失败次数与连接状态
+func (c *InfluxClient) collectMetrics() []metrics.Sample {
+	connected := 0.0
+	if c.connected.Load() {
+		connected = 1
+	}
+	return []metrics.Sample{
+		{
+			Name: "influxdb_write_errors_total", Help: "InfluxDB 异步写入失败次数", Type: metrics.TypeCounter,
+			Value: float64(c.writeErrors.Load()),
+		},
+		{Name: "influxdb_connected", Help: "InfluxDB 是否已连接", Type: metrics.TypeGauge, Value: connected},
+		{
+			Name: "db_query_retries_total", Help: "瞬时错误导致的查询重试次数", Type: metrics.TypeCounter,
+			Labels: map[string]string{"db": "influxdb"}, Value: float64(c.retry.Retries()),
+		},
+	}
+}
+
+// ensureBucket 确保 Bucket 存在且保留策略与配置一致
+func (c *InfluxClient) ensureBucket(ctx context.Context, client influxdb2.Client, cfg config.InfluxBucketConfig) error {
+	bucketsAPI := client.BucketsAPI()
+	rule := domain.RetentionRule{EverySeconds: int64(cfg.RetentionDays) * 86400}
+
+	bucket, err := bucketsAPI.FindBucketByName(ctx, cfg.Name)
+	if err != nil {
+		org, err := client.OrganizationsAPI().FindOrganizationByName(ctx, c.org)
+		if err != nil {
+			return fmt.Errorf("查询组织失败: %w", err)
+		}
+		if _, err := bucketsAPI.CreateBucketWithName(ctx, org, cfg.Name, rule); err != nil {
+			return fmt.Errorf("创建 Bucket %s 失败: %w", cfg.Name, err)
+		}
+		return nil
+	}
+
+	if len(bucket.RetentionRules) == 1 && bucket.RetentionRules[0].EverySeconds == rule.EverySeconds {
+		return nil
+	}
+	bucket.RetentionRules = domain.RetentionRules{rule}
+	if _, err := bucketsAPI.UpdateBucket(ctx, bucket); err != nil {
+		return fmt.Errorf("更新 Bucket %s 保留策略失败: %w", cfg.Name, err)
 	}
+	return nil
+}
+
+// Close 关闭客户端
+func (c *InfluxClient) Close() {
+	c.closeOnce.Do(func() { close(c.stop) })
+	metrics.Unregister("influxdb")
+	c.Flush()
+	c.conn.Load().client.Close()
 }
 
 // HealthCheck 健康检查
 func (c *InfluxClient) HealthCheck(ctx context.Context) error {
-	_, err := c.client.Health(ctx)
+	if !c.connected.Load() {
+		return ErrInfluxUnavailable
+	}
+	_, err := c.conn.Load().client.Health(ctx)
 	return err
 }
 
 // WritePoint 写入单条数据点
 func (c *InfluxClient) WritePoint(point *write.Point) {
-	c.writeAPI.WritePoint(point)
+	c.conn.Load().writeAPI.WritePoint(point)
 }
 
 // WritePoints 批量写入数据点
 func (c *InfluxClient) WritePoints(points []*write.Point) {
+	writeAPI := c.conn.Load().writeAPI
 	for _, point := range points {
-		c.writeAPI.WritePoint(point)
+		writeAPI.WritePoint(point)
 	}
 }
 
-// Flush 刷新缓冲区
+// WritePointsTo 将数据点写入数据类型对应的 Bucket
+func (c *InfluxClient) WritePointsTo(dataType string, points ...*write.Point) {
+	writeAPI := c.conn.Load().writeAPIs[c.Bucket(dataType)]
+	for _, point := range points {
+		writeAPI.WritePoint(point)
+	}
+}
+
+// WriteBlocking 同步写入数据类型对应的 Bucket，服务端确认后返回
+// 调用方按批调用即可获得背压：上一批写入完成前不会提交下一批，失败的批次可单独重试。
+func (c *InfluxClient) WriteBlocking(ctx context.Context, dataType string, points ...*write.Point) error {
+	return c.conn.Load().blockingAPIs[c.Bucket(dataType)].WritePoint(ctx, points...)
+}
+
+// Flush 刷新全部 Bucket 的缓冲区
 func (c *InfluxClient) Flush() {
-	c.writeAPI.Flush()
+	for _, writeAPI := range c.conn.Load().writeAPIs {
+		writeAPI.Flush()
+	}
 }
 
-// Query 执行Flux查询
+// Query 执行Flux查询，网络错误与服务暂时不可用时按重试策略重试
+// 只重试发起查询本身，读取结果过程中的错误由调用方处理。
 func (c *InfluxClient) Query(ctx context.Context, query string) (*api.QueryTableResult, error) {
-	return c.queryAPI.Query(ctx, query)
+	if !c.connected.Load() {
+		return nil, ErrInfluxUnavailable
+	}
+	var result *api.QueryTableResult
+	err := c.retry.Do(ctx, isTransientInflux, func() error {
+		var err error
+		result, err = c.conn.Load().queryAPI.Query(ctx, query)
+		return err
+	})
+	return result, err
 }
 
-// QueryRaw 执行原始Flux查询
+// QueryRaw 执行原始Flux查询，重试规则同 Query
 func (c *InfluxClient) QueryRaw(ctx context.Context, query string) (string, error) {
-	return c.queryAPI.QueryRaw(ctx, query, influxdb2.DefaultDialect())
+	if !c.connected.Load() {
+		return "", ErrInfluxUnavailable
+	}
+	var result string
+	err := c.retry.Do(ctx, isTransientInflux, func() error {
+		var err error
+		result, err = c.conn.Load().queryAPI.QueryRaw(ctx, query, influxdb2.DefaultDialect())
+		return err
+	})
+	return result, err
 }
 
 // Delete 删除数据
 func (c *InfluxClient) Delete(ctx context.Context, start, stop time.Time, predicate string) error {
-	return c.deleteAPI.DeleteWithName(ctx, c.org, c.bucket, start, stop, predicate)
+	return c.conn.Load().deleteAPI.DeleteWithName(ctx, c.org, c.bucket, start, stop, predicate)
 }
 
 // GetOrg 获取组织名
@@ -108,11 +381,24 @@ func (c *InfluxClient) GetOrg() string {
 	return c.org
 }
 
-// GetBucket 获取Bucket名
+// GetBucket 获取默认Bucket名
 func (c *InfluxClient) GetBucket() string {
 	return c.bucket
 }
 
+// Bucket 获取数据类型对应的Bucket名，未单独配置时返回默认Bucket
+func (c *InfluxClient) Bucket(dataType string) string {
+	if bucket, ok := c.buckets[dataType]; ok {
+		return bucket
+	}
+	return c.bucket
+}
+
+// DeleteFrom 删除数据类型对应 Bucket 中的数据
+func (c *InfluxClient) DeleteFrom(ctx context.Context, dataType string, start, stop time.Time, predicate string) error {
+	return c.conn.Load().deleteAPI.DeleteWithName(ctx, c.org, c.Bucket(dataType), start, stop, predicate)
+}
+
 // GetBatchSize 获取批量大小
 func (c *InfluxClient) GetBatchSize() int {
 	return c.batchSize
@@ -120,10 +406,10 @@ func (c *InfluxClient) GetBatchSize() int {
 
 // GetQueryAPI 获取查询API
 func (c *InfluxClient) GetQueryAPI() api.QueryAPI {
-	return c.queryAPI
+	return c.conn.Load().queryAPI
 }
 
 // GetWriteAPI 获取写入API
 func (c *InfluxClient) GetWriteAPI() api.WriteAPI {
-	return c.writeAPI
+	return c.conn.Load().writeAPI
 }
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+func TestLazyInfluxClientReconnects(t *testing.T) {
+	var up atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			http.Error(w, `{"status":"fail"}`, http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name":"influxdb","message":"ready for queries and writes","status":"pass","checks":[]}`))
+	}))
+	defer srv.Close()
+
+	interval := influxReconnectInterval
+	influxReconnectInterval = 10 * time.Millisecond
+	defer func() { influxReconnectInterval = interval }()
+
+	c := NewLazyInfluxClient(&config.InfluxDBConfig{URL: srv.URL, Org: "org", Bucket: "bucket"})
+	defer c.Close()
+
+	ctx := context.Background()
+	if c.Connected() {
+		t.Fatal("InfluxDB 不可用时不应标记为已连接")
+	}
+	if _, err := c.Query(ctx, "buckets()"); !errors.Is(err, ErrInfluxUnavailable) {
+		t.Fatalf("未连接时查询应返回 ErrInfluxUnavailable，实际 %v", err)
+	}
+	if err := c.HealthCheck(ctx); err == nil {
+		t.Fatal("未连接时健康检查应失败")
+	}
+
+	up.Store(true)
+	deadline := time.Now().Add(2 * time.Second)
+	for !c.Connected() {
+		if time.Now().After(deadline) {
+			t.Fatal("InfluxDB 恢复后应在后台重连")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := c.HealthCheck(ctx); err != nil {
+		t.Fatalf("重连后健康检查应通过: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"stock-analysis-system/backend/pkg/requestid"
+)
+
+// queryLogger GORM 日志：超过阈值的语句按慢查询记录，日志带上请求ID以便与请求日志关联
+type queryLogger struct {
+	level         *atomic.Int64 // 日志级别，可热更新；LogMode 复制出的日志使用独立的级别
+	slowThreshold time.Duration // 为 0 时不记录慢查询
+	slowQueries   *atomic.Int64 // 慢查询累计次数，LogMode 复制后共享
+}
+
+func newQueryLogger(level logger.LogLevel, slowThreshold time.Duration) *queryLogger {
+	l := &queryLogger{level: new(atomic.Int64), slowThreshold: slowThreshold, slowQueries: new(atomic.Int64)}
+	l.setLevel(level)
+	return l
+}
+
+func (l *queryLogger) setLevel(level logger.LogLevel) {
+	l.level.Store(int64(level))
+}
+
+func (l *queryLogger) logLevel() logger.LogLevel {
+	return logger.LogLevel(l.level.Load())
+}
+
+// LogMode 实现 logger.Interface
+func (l *queryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	copied := *l
+	copied.level = new(atomic.Int64)
+	copied.setLevel(level)
+	return &copied
+}
+
+// gormLogLevel 日志级别对应的 GORM 级别，debug 与 info 都记录全部语句
+func gormLogLevel(level string) logger.LogLevel {
+	switch strings.ToLower(level) {
+	case "warn":
+		return logger.Warn
+	case "error":
+		return logger.Error
+	default:
+		return logger.Info
+	}
+}
+
+// Info 实现 logger.Interface
+func (l *queryLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.logLevel() >= logger.Info {
+		log.Printf(prefix(ctx)+msg, args...)
+	}
+}
+
+// Warn 实现 logger.Interface
+func (l *queryLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.logLevel() >= logger.Warn {
+		log.Printf(prefix(ctx)+msg, args...)
+	}
+}
+
+// Error 实现 logger.Interface
+func (l *queryLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.logLevel() >= logger.Error {
+		log.Printf(prefix(ctx)+msg, args...)
+	}
+}
+
+// Trace 实现 logger.Interface：记录出错的语句、慢查询，Info 级别下记录全部语句
+func (l *queryLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed >= l.slowThreshold
+	if slow {
+		l.slowQueries.Add(1)
+	}
+	if l.logLevel() <= logger.Silent {
+		return
+	}
+
+	ms := float64(elapsed.Nanoseconds()) / 1e6
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.logLevel() >= logger.Error:
+		sql, rows := fc()
+		log.Printf("%sSQL 执行失败: %v [%.3fms] [rows:%d] %s", prefix(ctx), err, ms, rows, sql)
+	case slow && l.logLevel() >= logger.Warn:
+		sql, rows := fc()
+		log.Printf("%s慢查询 >= %v [%.3fms] [rows:%d] %s", prefix(ctx), l.slowThreshold, ms, rows, sql)
+	case l.logLevel() >= logger.Info:
+		sql, rows := fc()
+		log.Printf("%s[%.3fms] [rows:%d] %s", prefix(ctx), ms, rows, sql)
+	}
+}
+
+// prefix 日志前缀，包含请求ID
+func prefix(ctx context.Context) string {
+	if id := requestid.FromContext(ctx); id != "" {
+		return fmt.Sprintf("[%s] ", id)
+	}
+	return ""
+}
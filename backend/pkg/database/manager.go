@@ -11,14 +11,31 @@ import (
 type Manager struct {
 	Postgres *PostgresClient
 	Influx   *InfluxClient
+	Redis    *RedisClient // 未配置时为 nil
 	config   *config.DatabaseConfig
+
+	influxOptional bool
+}
+
+// ManagerOption 数据库管理器选项
+type ManagerOption func(*Manager)
+
+// OptionalInflux InfluxDB 连接失败时不阻止启动，在后台重连（见 NewLazyInfluxClient）
+// 健康检查中 InfluxDB 作为可选项，失败时服务报告降级而不是不可用。
+func OptionalInflux() ManagerOption {
+	return func(m *Manager) {
+		m.influxOptional = true
+	}
 }
 
 // NewManager 创建数据库管理器
-func NewManager(cfg *config.DatabaseConfig) (*Manager, error) {
+func NewManager(cfg *config.DatabaseConfig, opts ...ManagerOption) (*Manager, error) {
 	manager := &Manager{
 		config: cfg,
 	}
+	for _, opt := range opts {
+		opt(manager)
+	}
 
 	// 连接PostgreSQL
 	if cfg.Postgres.Host != "" {
@@ -26,21 +43,48 @@ func NewManager(cfg *config.DatabaseConfig) (*Manager, error) {
 		if err != nil {
 			return nil, fmt.Errorf("初始化PostgreSQL失败: %w", err)
 		}
+		postgresClient.retry = NewRetryPolicy(cfg.Retry)
 		manager.Postgres = postgresClient
 	}
 
 	// 连接InfluxDB
-	if cfg.InfluxDB.URL != "" {
+	if cfg.InfluxDB.URL != "" && manager.influxOptional {
+		manager.Influx = NewLazyInfluxClient(&cfg.InfluxDB)
+	} else if cfg.InfluxDB.URL != "" {
 		influxClient, err := NewInfluxClient(&cfg.InfluxDB)
 		if err != nil {
 			return nil, fmt.Errorf("初始化InfluxDB失败: %w", err)
 		}
 		manager.Influx = influxClient
 	}
+	if manager.Influx != nil {
+		manager.Influx.retry = NewRetryPolicy(cfg.Retry)
+	}
+
+	// 连接Redis
+	if cfg.Redis.Host != "" {
+		redisClient, err := NewRedisClient(&cfg.Redis)
+		if err != nil {
+			return nil, fmt.Errorf("初始化Redis失败: %w", err)
+		}
+		manager.Redis = redisClient
+	}
 
 	return manager, nil
 }
 
+// InfluxOptional InfluxDB 是否为可选依赖
+func (m *Manager) InfluxOptional() bool {
+	return m.influxOptional
+}
+
+// SetLogLevel 调整数据库日志级别，可在配置热更新时调用
+func (m *Manager) SetLogLevel(level string) {
+	if m.Postgres != nil {
+		m.Postgres.SetLogLevel(level)
+	}
+}
+
 // Close 关闭所有数据库连接
 func (m *Manager) Close() error {
 	var errs []error
@@ -55,6 +99,12 @@ func (m *Manager) Close() error {
 		m.Influx.Close()
 	}
 
+	if m.Redis != nil {
+		if err := m.Redis.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭Redis失败: %w", err))
+		}
+	}
+
 	if len(errs) > 0 {
 		return errs[0]
 	}
@@ -81,6 +131,14 @@ func (m *Manager) HealthCheck(ctx context.Context) map[string]error {
 		}
 	}
 
+	if m.Redis != nil {
+		if err := m.Redis.HealthCheck(ctx); err != nil {
+			results["redis"] = err
+		} else {
+			results["redis"] = nil
+		}
+	}
+
 	return results
 }
 
@@ -2,7 +2,11 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"gorm.io/driver/postgres"
@@ -11,36 +15,54 @@ import (
 	"gorm.io/gorm/schema"
 
 	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/metrics"
 )
 
 // PostgresClient PostgreSQL客户端
 type PostgresClient struct {
-	DB     *gorm.DB
-	config *config.PostgresConfig
+	DB       *gorm.DB
+	config   *config.PostgresConfig
+	resolver *replicaResolver // 配置了只读副本时不为空
+	logger   *queryLogger
+	retry    *RetryPolicy
+
+	up        atomic.Bool   // 主库连接是否正常
+	stop      chan struct{} // 关闭时停止连接检查
+	closeOnce sync.Once
 }
 
 // NewPostgresClient 创建PostgreSQL客户端
+// 连接成功后在后台定期检查主库连接，中断时丢弃空闲连接并按指数退避重试，恢复后无需重启服务。
 func NewPostgresClient(cfg *config.PostgresConfig) (*PostgresClient, error) {
 	client := &PostgresClient{
 		config: cfg,
+		retry:  defaultRetryPolicy(),
+		stop:   make(chan struct{}),
 	}
 
 	if err := client.Connect(); err != nil {
 		return nil, err
 	}
 
+	sqlDB, _ := client.DB.DB()
+	client.up.Store(true)
+	go client.watch(sqlDB)
 	return client, nil
 }
 
 // Connect 连接到PostgreSQL
 func (c *PostgresClient) Connect() error {
+	// SQL 日志与慢查询记录
+	slowThreshold := time.Duration(max(c.config.SlowQueryMs, 0)) * time.Millisecond
+	c.logger = newQueryLogger(logger.Info, slowThreshold)
+
 	// GORM配置
 	gormConfig := &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			TablePrefix:   "",    // 表名前缀
 			SingularTable: false, // 使用复数表名
 		},
-		Logger: logger.Default.LogMode(logger.Info),
+		Logger: c.logger,
 	}
 
 	// 连接数据库
@@ -56,10 +78,7 @@ func (c *PostgresClient) Connect() error {
 	}
 
 	// 设置连接池
-	sqlDB.SetMaxOpenConns(c.config.MaxConns)
-	sqlDB.SetMaxIdleConns(c.config.MinConns)
-	sqlDB.SetConnMaxLifetime(time.Hour)
-	sqlDB.SetConnMaxIdleTime(30 * time.Minute)
+	c.setupPool(sqlDB)
 
 	// 测试连接
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -69,12 +88,158 @@ func (c *PostgresClient) Connect() error {
 		return fmt.Errorf("Ping PostgreSQL失败: %w", err)
 	}
 
+	// 事务外查询遇到连接中断等瞬时错误时重试
+	if err := registerQueryRetry(db, func() *RetryPolicy { return c.retry }); err != nil {
+		sqlDB.Close()
+		return fmt.Errorf("注册查询重试失败: %w", err)
+	}
+
+	// 只读副本：读请求分发到副本，写请求仍走主库
+	if len(c.config.Replicas) > 0 {
+		interval := time.Duration(c.config.ReplicaCheckInterval) * time.Second
+		resolver, err := newReplicaResolver(c.config.Replicas, gormConfig, c.setupPool, interval)
+		if err != nil {
+			sqlDB.Close()
+			return err
+		}
+		if err := db.Use(resolver); err != nil {
+			resolver.close()
+			sqlDB.Close()
+			return fmt.Errorf("注册读写分离失败: %w", err)
+		}
+		c.resolver = resolver
+	}
+
 	c.DB = db
+	metrics.Register("postgres", c.collectMetrics)
 	return nil
 }
 
+// watch 定期检查主库连接，失败时进入重连
+func (c *PostgresClient) watch(sqlDB *sql.DB) {
+	ticker := time.NewTicker(connCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := pingPrimary(sqlDB); err != nil {
+				log.Printf("PostgreSQL 连接中断: %v", err)
+				c.reconnect(sqlDB)
+			}
+		}
+	}
+}
+
+// reconnect 丢弃连接池中的空闲连接（可能指向已重启或切换的主库），按指数退避重试直到恢复或客户端关闭
+func (c *PostgresClient) reconnect(sqlDB *sql.DB) {
+	c.up.Store(false)
+	sqlDB.SetMaxIdleConns(0)
+
+	b := backoff{min: time.Second, max: maxReconnectBackoff}
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-time.After(b.next()):
+		}
+		if err := pingPrimary(sqlDB); err == nil {
+			sqlDB.SetMaxIdleConns(c.config.MinConns)
+			c.up.Store(true)
+			log.Printf("PostgreSQL 已重新连接")
+			return
+		}
+	}
+}
+
+func pingPrimary(sqlDB *sql.DB) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return sqlDB.PingContext(ctx)
+}
+
+// setupPool 设置连接池参数，主库与只读副本共用
+func (c *PostgresClient) setupPool(sqlDB *sql.DB) {
+	sqlDB.SetMaxOpenConns(c.config.MaxConns)
+	sqlDB.SetMaxIdleConns(c.config.MinConns)
+	sqlDB.SetConnMaxLifetime(time.Hour)
+	sqlDB.SetConnMaxIdleTime(30 * time.Minute)
+}
+
+// collectMetrics 采集连接池状态与慢查询次数，主库与各只读副本分别以 db 标签区分
+func (c *PostgresClient) collectMetrics() []metrics.Sample {
+	pools := map[string]*sql.DB{}
+	if sqlDB, err := c.DB.DB(); err == nil {
+		pools["primary"] = sqlDB
+	}
+	var samples []metrics.Sample
+	if c.resolver != nil {
+		for _, rep := range c.resolver.replicas {
+			pools[rep.name] = rep.db
+			up := 0.0
+			if rep.healthy.Load() {
+				up = 1
+			}
+			samples = append(samples, metrics.Sample{
+				Name: "postgres_replica_up", Help: "只读副本是否健康", Type: metrics.TypeGauge,
+				Labels: map[string]string{"db": rep.name}, Value: up,
+			})
+		}
+	}
+
+	for name, pool := range pools {
+		stats := pool.Stats()
+		labels := map[string]string{"db": name}
+		samples = append(samples,
+			metrics.Sample{Name: "postgres_pool_max_open", Help: "连接池最大连接数", Type: metrics.TypeGauge, Labels: labels, Value: float64(stats.MaxOpenConnections)},
+			metrics.Sample{Name: "postgres_pool_open", Help: "已建立的连接数", Type: metrics.TypeGauge, Labels: labels, Value: float64(stats.OpenConnections)},
+			metrics.Sample{Name: "postgres_pool_in_use", Help: "使用中的连接数", Type: metrics.TypeGauge, Labels: labels, Value: float64(stats.InUse)},
+			metrics.Sample{Name: "postgres_pool_idle", Help: "空闲连接数", Type: metrics.TypeGauge, Labels: labels, Value: float64(stats.Idle)},
+			metrics.Sample{Name: "postgres_pool_wait_count", Help: "等待空闲连接的累计次数", Type: metrics.TypeCounter, Labels: labels, Value: float64(stats.WaitCount)},
+			metrics.Sample{Name: "postgres_pool_wait_seconds_total", Help: "等待空闲连接的累计时长（秒）", Type: metrics.TypeCounter, Labels: labels, Value: stats.WaitDuration.Seconds()},
+		)
+	}
+	up := 0.0
+	if c.up.Load() {
+		up = 1
+	}
+	samples = append(samples,
+		metrics.Sample{
+			Name: "postgres_slow_queries_total", Help: "慢查询累计次数", Type: metrics.TypeCounter,
+			Value: float64(c.logger.slowQueries.Load()),
+		},
+		metrics.Sample{Name: "postgres_up", Help: "主库连接是否正常", Type: metrics.TypeGauge, Value: up},
+		metrics.Sample{
+			Name: "db_query_retries_total", Help: "瞬时错误导致的查询重试次数", Type: metrics.TypeCounter,
+			Labels: map[string]string{"db": "postgres"}, Value: float64(c.retry.Retries()),
+		},
+	)
+	return samples
+}
+
+// SetLogLevel 调整 SQL 日志级别（debug/info 记录全部语句，warn 只记录慢查询与错误，error 只记录错误）
+func (c *PostgresClient) SetLogLevel(level string) {
+	c.logger.setLevel(gormLogLevel(level))
+}
+
+// ReplicaStatus 各只读副本的健康状态，未配置副本时为空
+func (c *PostgresClient) ReplicaStatus() map[string]bool {
+	if c.resolver == nil {
+		return nil
+	}
+	return c.resolver.status()
+}
+
 // Close 关闭连接
 func (c *PostgresClient) Close() error {
+	if c.stop != nil {
+		c.closeOnce.Do(func() { close(c.stop) })
+	}
+	metrics.Unregister("postgres")
+	if c.resolver != nil {
+		c.resolver.close()
+	}
 	if c.DB != nil {
 		sqlDB, err := c.DB.DB()
 		if err != nil {
@@ -0,0 +1,54 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// RedisClient Redis客户端，用作行情缓存
+type RedisClient struct {
+	client *redis.Client
+}
+
+// NewRedisClient 创建Redis客户端
+func NewRedisClient(cfg *config.RedisConfig) (*RedisClient, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+
+	// 测试连接
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("连接Redis失败: %w", err)
+	}
+
+	return &RedisClient{client: client}, nil
+}
+
+// Close 关闭连接
+func (c *RedisClient) Close() error {
+	return c.client.Close()
+}
+
+// HealthCheck 健康检查
+func (c *RedisClient) HealthCheck(ctx context.Context) error {
+	return c.client.Ping(ctx).Err()
+}
+
+// GetClient 获取原始客户端，未配置 Redis（c 为 nil）时返回 nil
+func (c *RedisClient) GetClient() *redis.Client {
+	if c == nil {
+		return nil
+	}
+	return c.client
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	influxhttp "github.com/influxdata/influxdb-client-go/v2/api/http"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// ============ 断线重连与查询重试 ============
+
+const (
+	connCheckInterval   = 10 * time.Second // 已连接时检查连接的间隔
+	maxReconnectBackoff = 30 * time.Second // 重连等待时间上限
+)
+
+// RetryPolicy 查询重试策略：瞬时错误（连接中断、服务暂时不可用）按指数退避重试，其他错误直接返回
+type RetryPolicy struct {
+	MaxAttempts    int // 最多执行次数（含首次），小于等于 1 表示不重试
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	retries atomic.Int64 // 累计重试次数
+}
+
+// NewRetryPolicy 按配置创建重试策略
+func NewRetryPolicy(cfg config.RetryConfig) *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    cfg.MaxAttempts,
+		InitialBackoff: time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+	}
+}
+
+// defaultRetryPolicy 未通过 Manager 配置时使用的重试策略
+func defaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 3, InitialBackoff: 100 * time.Millisecond, MaxBackoff: 2 * time.Second}
+}
+
+// Do 执行 fn，返回的错误被 transient 判定为瞬时错误时等待后重试，ctx 结束时停止
+func (p *RetryPolicy) Do(ctx context.Context, transient func(error) bool, fn func() error) error {
+	b := backoff{min: p.InitialBackoff, max: p.MaxBackoff}
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !transient(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(b.next()):
+		}
+		p.retries.Add(1)
+	}
+}
+
+// Retries 累计重试次数
+func (p *RetryPolicy) Retries() int64 {
+	return p.retries.Load()
+}
+
+// backoff 指数退避：从 min 开始每次翻倍，不超过 max，附加最多 20% 的随机抖动避免多个实例同时重试
+type backoff struct {
+	min, max time.Duration
+	current  time.Duration
+}
+
+func (b *backoff) next() time.Duration {
+	if b.current == 0 {
+		b.current = b.min
+	} else {
+		b.current = min(b.current*2, b.max)
+	}
+	if b.current <= 0 {
+		return 0
+	}
+	return b.current + time.Duration(rand.Int63n(int64(b.current)/5+1))
+}
+
+// isNetworkError 连接被拒绝、重置或超时等网络错误，不含请求上下文取消
+func isNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		strings.Contains(err.Error(), "connection reset by peer") || strings.Contains(err.Error(), "broken pipe")
+}
+
+// isTransientPostgres PostgreSQL 瞬时错误：连接失效、建立连接失败、服务正在关闭或启动、连接数已满
+// 只重试语句尚未发送或连接层面的错误，不会重复执行已经生效的写入。
+func isTransientPostgres(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) || pgconn.SafeToRetry(err) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || pgErr.Code == "57P01" || pgErr.Code == "57P03" || pgErr.Code == "53300"
+	}
+	return isNetworkError(err)
+}
+
+// isTransientInflux InfluxDB 瞬时错误：网络错误、限流与网关/服务暂时不可用
+func isTransientInflux(err error) bool {
+	var httpErr *influxhttp.Error
+	if errors.As(err, &httpErr) {
+		switch httpErr.StatusCode {
+		case 0:
+			return httpErr.Err != nil && isNetworkError(httpErr.Err)
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return isNetworkError(err)
+}
+
+// registerQueryRetry 以 GORM 回调的形式为事务外的查询增加重试，仓库代码无需改动
+// 事务内的查询不重试：连接中断时事务已经失效，应由调用方整体重试。
+func registerQueryRetry(db *gorm.DB, policy func() *RetryPolicy) error {
+	return db.Callback().Query().Replace("gorm:query", func(db *gorm.DB) {
+		if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+			callbacks.Query(db)
+			return
+		}
+		policy().Do(db.Statement.Context, isTransientPostgres, func() error {
+			db.Error = nil
+			callbacks.Query(db)
+			return db.Error
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	influxhttp "github.com/influxdata/influxdb-client-go/v2/api/http"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetryPolicyDo(t *testing.T) {
+	p := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	transient := func(err error) bool { return errors.Is(err, driver.ErrBadConn) }
+	ctx := context.Background()
+
+	calls := 0
+	err := p.Do(ctx, transient, func() error {
+		calls++
+		if calls < 3 {
+			return driver.ErrBadConn
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("瞬时错误应重试至成功，err=%v calls=%d", err, calls)
+	}
+	if p.Retries() != 2 {
+		t.Fatalf("重试次数应为 2，实际 %d", p.Retries())
+	}
+
+	calls = 0
+	err = p.Do(ctx, transient, func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+	if !errors.Is(err, driver.ErrBadConn) || calls != 3 {
+		t.Fatalf("超过最大次数应返回最后一次错误，err=%v calls=%d", err, calls)
+	}
+
+	calls = 0
+	permanent := errors.New("syntax error")
+	err = p.Do(ctx, transient, func() error {
+		calls++
+		return permanent
+	})
+	if err != permanent || calls != 1 {
+		t.Fatalf("非瞬时错误不应重试，err=%v calls=%d", err, calls)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	calls = 0
+	p.Do(canceled, transient, func() error {
+		calls++
+		return driver.ErrBadConn
+	})
+	if calls != 1 {
+		t.Fatalf("上下文结束后不应重试，calls=%d", calls)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	b := backoff{min: 100 * time.Millisecond, max: 300 * time.Millisecond}
+	for i, base := range []time.Duration{100, 200, 300, 300} {
+		base *= time.Millisecond
+		if d := b.next(); d < base || d > base+base/5 {
+			t.Fatalf("第 %d 次退避应在 %s 到 %s 之间，实际 %s", i+1, base, base+base/5, d)
+		}
+	}
+}
+
+func TestTransientErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		fn   func(error) bool
+		err  error
+		want bool
+	}{
+		{"postgres 连接失效", isTransientPostgres, driver.ErrBadConn, true},
+		{"postgres 服务关闭", isTransientPostgres, &pgconn.PgError{Code: "57P01"}, true},
+		{"postgres 连接数已满", isTransientPostgres, &pgconn.PgError{Code: "53300"}, true},
+		{"postgres 唯一约束", isTransientPostgres, &pgconn.PgError{Code: "23505"}, false},
+		{"postgres 查询取消", isTransientPostgres, context.Canceled, false},
+		{"influx 网关错误", isTransientInflux, &influxhttp.Error{StatusCode: http.StatusBadGateway}, true},
+		{"influx 限流", isTransientInflux, &influxhttp.Error{StatusCode: http.StatusTooManyRequests}, true},
+		{"influx 查询语法错误", isTransientInflux, &influxhttp.Error{StatusCode: http.StatusBadRequest}, false},
+		{"influx 请求超时", isTransientInflux, &influxhttp.Error{Err: context.DeadlineExceeded}, false},
+	}
+	for _, tc := range cases {
+		if got := tc.fn(tc.err); got != tc.want {
+			t.Errorf("%s: 期望 %v，实际 %v", tc.name, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Package divergence 实盘信号表现与回测预期的偏离检查：按信号价格撮合买卖信号得到已实现表现，
+// 与策略最近一次回测（优先为定期回归回测）的绩效比较，超过阈值时给出偏离原因。
+package divergence
+
+import (
+	"fmt"
+	"sort"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 偏离处理方式
+const (
+	ActionNotify     = "notify"     // 记录偏离事件，策略所有者在偏离记录中查看
+	ActionDeactivate = "deactivate" // 记录偏离事件并停用策略
+)
+
+// DefaultMinTrades 已平仓交易少于该笔数时不做比较，避免少量交易造成误报
+const DefaultMinTrades = 5
+
+// Live 已实现的信号表现
+type Live struct {
+	Trades      int     `json:"trades"` // 已平仓交易笔数
+	TotalReturn float64 `json:"total_return"`
+	MaxDrawdown float64 `json:"max_drawdown"` // 按已实现盈亏累计的权益计算
+	WinRate     float64 `json:"win_rate"`
+}
+
+// Expected 回测预期
+type Expected struct {
+	MaxDrawdown float64 `json:"max_drawdown"`
+	WinRate     float64 `json:"win_rate"`
+}
+
+// Realize 按信号价格撮合有效信号：buy 按信号数量开仓（同一股票多次买入按加权平均成本），sell/close 平掉全部持仓
+// 已撤销的信号与平仓前没有持仓的卖出信号不计入；权益从 capital 起按每笔平仓的盈亏累计。
+func Realize(signals []*models.TradeSignal, capital float64) *Live {
+	sorted := make([]*models.TradeSignal, 0, len(signals))
+	for _, s := range signals {
+		if s.Status != models.SignalStatusCancelled && s.Price > 0 {
+			sorted = append(sorted, s)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CreatedAt.Before(sorted[j].CreatedAt) })
+
+	type position struct {
+		volume int
+		cost   float64 // 持仓总成本
+	}
+	positions := make(map[string]*position)
+	live := &Live{}
+	equity, peak := capital, capital
+	var wins int
+	for _, s := range sorted {
+		key := s.Symbol + "." + s.Exchange
+		pos := positions[key]
+		switch s.SignalType {
+		case "buy":
+			if s.Volume <= 0 {
+				continue
+			}
+			if pos == nil {
+				pos = &position{}
+				positions[key] = pos
+			}
+			pos.volume += s.Volume
+			pos.cost += s.Price * float64(s.Volume)
+		case "sell", "close":
+			if pos == nil || pos.volume == 0 {
+				continue
+			}
+			pnl := s.Price*float64(pos.volume) - pos.cost
+			delete(positions, key)
+
+			live.Trades++
+			if pnl > 0 {
+				wins++
+			}
+			equity += pnl
+			if equity > peak {
+				peak = equity
+			}
+			if peak > 0 && (peak-equity)/peak > live.MaxDrawdown {
+				live.MaxDrawdown = (peak - equity) / peak
+			}
+		}
+	}
+
+	if capital > 0 {
+		live.TotalReturn = equity/capital - 1
+	}
+	if live.Trades > 0 {
+		live.WinRate = float64(wins) / float64(live.Trades)
+	}
+	return live
+}
+
+// Check 比较已实现表现与回测预期，ratio 为允许的倍数（如 2 表示回撤超过预期的 2 倍即偏离）
+// 返回偏离原因，未偏离或交易笔数不足时返回空。
+func Check(live *Live, expected *Expected, ratio float64, minTrades int) []string {
+	if ratio <= 1 || live.Trades < minTrades {
+		return nil
+	}
+	var reasons []string
+	if expected.MaxDrawdown > 0 && live.MaxDrawdown > expected.MaxDrawdown*ratio {
+		reasons = append(reasons, fmt.Sprintf("实盘最大回撤 %.2f%% 超过回测预期 %.2f%% 的 %g 倍",
+			live.MaxDrawdown*100, expected.MaxDrawdown*100, ratio))
+	}
+	if expected.WinRate > 0 && live.WinRate < expected.WinRate/ratio {
+		reasons = append(reasons, fmt.Sprintf("实盘胜率 %.2f%% 低于回测预期 %.2f%% 的 1/%g",
+			live.WinRate*100, expected.WinRate*100, ratio))
+	}
+	return reasons
+}
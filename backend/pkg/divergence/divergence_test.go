@@ -0,0 +1,59 @@
+package divergence
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func signal(day int, symbol, signalType string, price float64, volume int) *models.TradeSignal {
+	return &models.TradeSignal{
+		Symbol: symbol, Exchange: "SZ", SignalType: signalType, Price: price, Volume: volume,
+		Status:    models.SignalStatusActive,
+		CreatedAt: time.Date(2024, 3, day, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestRealize(t *testing.T) {
+	cancelled := signal(4, "000001", "buy", 1, 100000)
+	cancelled.Status = models.SignalStatusCancelled
+
+	live := Realize([]*models.TradeSignal{
+		signal(6, "000001", "sell", 9, 0),   // 亏损 1500
+		signal(1, "000001", "buy", 10, 500), // 与下一笔合并，总成本 10500
+		signal(2, "000001", "buy", 11, 500),
+		signal(3, "000002", "sell", 5, 0), // 没有持仓，忽略
+		cancelled,
+		signal(5, "000002", "buy", 20, 100),
+		signal(7, "000002", "close", 25, 0), // 盈利 500
+		signal(8, "000001", "buy", 10, 100), // 未平仓，不计入
+	}, 100000)
+
+	if live.Trades != 2 || live.WinRate != 0.5 {
+		t.Errorf("trades = %d, win rate = %v", live.Trades, live.WinRate)
+	}
+	if math.Abs(live.TotalReturn-(-0.01)) > 1e-9 {
+		t.Errorf("total return = %v, want -0.01", live.TotalReturn)
+	}
+	if math.Abs(live.MaxDrawdown-0.015) > 1e-9 {
+		t.Errorf("max drawdown = %v, want 0.015", live.MaxDrawdown)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	expected := &Expected{MaxDrawdown: 0.1, WinRate: 0.6}
+
+	reasons := Check(&Live{Trades: 10, MaxDrawdown: 0.25, WinRate: 0.2}, expected, 2, DefaultMinTrades)
+	if len(reasons) != 2 || !strings.Contains(reasons[0], "最大回撤") || !strings.Contains(reasons[1], "胜率") {
+		t.Errorf("reasons = %v", reasons)
+	}
+	if reasons := Check(&Live{Trades: 10, MaxDrawdown: 0.15, WinRate: 0.5}, expected, 2, DefaultMinTrades); len(reasons) != 0 {
+		t.Errorf("未超过阈值时 reasons = %v", reasons)
+	}
+	if reasons := Check(&Live{Trades: 3, MaxDrawdown: 0.5}, expected, 2, DefaultMinTrades); len(reasons) != 0 {
+		t.Errorf("交易笔数不足时 reasons = %v", reasons)
+	}
+}
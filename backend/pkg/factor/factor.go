@@ -0,0 +1,244 @@
+// Package factor 多因子因子库：因子定义、原始值计算、截面标准化与组合因子暴露
+package factor
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// 因子计算参数（按交易日计）
+const (
+	MomentumLookback = 250 // 动量回看期
+	MomentumSkip     = 20  // 动量剔除最近一个月，避免短期反转
+	VolatilityWindow = 60  // 波动率窗口
+
+	// MinHistory 计算全部价格类因子所需的最少收盘价数量
+	MinHistory = MomentumLookback + 1
+
+	// HistoryDays 计算价格类因子时日K线回看的自然日数（覆盖约 MomentumLookback 个交易日）
+	HistoryDays = 400
+
+	// zscoreLimit 标准化得分截断，降低极端值影响
+	zscoreLimit = 3.0
+)
+
+// Definition 因子定义
+type Definition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Direction   int    `json:"direction"`          // 1: 原始值越大越好, -1: 越小越好
+	Optional    bool   `json:"optional,omitempty"` // 可选因子：依赖宏观数据同步，未同步时没有得分
+}
+
+var definitions = []Definition{
+	{Name: models.FactorMomentum, Description: "过去12个月收益率（剔除最近1个月）", Direction: 1},
+	{Name: models.FactorValue, Description: "盈利收益率(E/P)与账面市值比(B/P)均值，净利润按报告期年化", Direction: 1},
+	{Name: models.FactorVolatility, Description: "近60个交易日年化波动率", Direction: -1},
+	{Name: models.FactorSize, Description: "总市值自然对数", Direction: -1},
+}
+
+// Definitions 返回全部因子定义，可选的宏观因子排在最后
+func Definitions() []Definition {
+	out := make([]Definition, 0, len(definitions)+len(macroDefinitions))
+	out = append(out, definitions...)
+	return append(out, macroDefinitions...)
+}
+
+// Lookup 按名称查找因子定义
+func Lookup(name string) (Definition, bool) {
+	for _, d := range Definitions() {
+		if d.Name == name {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// Input 单只股票的因子计算输入
+type Input struct {
+	Symbol     string
+	Exchange   string
+	Closes     []float64               // 截至计算日、按时间排序的日收盘价
+	Dates      []time.Time             // 与 Closes 一一对应的交易日，只用于宏观因子
+	Report     *models.FinancialReport // 计算日已披露的最近一期财报，可为空
+	TotalShare int64                   // 总股本
+
+	// Macro 宏观序列名称 -> 按日期升序的数据点，全部股票共用；为空时不计算宏观因子
+	Macro map[string][]*models.MacroPoint
+}
+
+// Values 计算单只股票的因子原始值，数据不足的因子不返回
+func Values(in *Input) map[string]float64 {
+	values := make(map[string]float64)
+	n := len(in.Closes)
+
+	if n > MomentumLookback {
+		base := in.Closes[n-1-MomentumLookback]
+		if last := in.Closes[n-1-MomentumSkip]; base > 0 {
+			values[models.FactorMomentum] = last/base - 1
+		}
+	}
+
+	if n > VolatilityWindow {
+		values[models.FactorVolatility] = risk.Volatility(risk.Returns(in.Closes[n-1-VolatilityWindow:]))
+	}
+
+	if n > 0 && in.TotalShare > 0 && in.Closes[n-1] > 0 {
+		marketCap := in.Closes[n-1] * float64(in.TotalShare)
+		values[models.FactorSize] = math.Log(marketCap)
+
+		// 亏损公司盈利收益率为负；净资产为负时不计账面市值比
+		if r := in.Report; r != nil {
+			ep := r.AnnualizedNetProfit() / marketCap
+			if r.ShareholdersEquity > 0 {
+				values[models.FactorValue] = (ep + r.ShareholdersEquity/marketCap) / 2
+			} else {
+				values[models.FactorValue] = ep
+			}
+		}
+	}
+
+	for name, points := range in.Macro {
+		if beta, ok := MacroBeta(in.Dates, in.Closes, name, points); ok {
+			values[MacroFactorName(name)] = beta
+		}
+	}
+
+	return values
+}
+
+// Score 计算截面因子得分：按因子方向标准化、截断并排名
+func Score(tradeDate time.Time, inputs []*Input) []*models.FactorScore {
+	byFactor := make(map[string][]*models.FactorScore)
+	for _, in := range inputs {
+		for name, value := range Values(in) {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			byFactor[name] = append(byFactor[name], &models.FactorScore{
+				TradeDate: tradeDate,
+				Factor:    name,
+				Symbol:    in.Symbol,
+				Exchange:  in.Exchange,
+				Value:     value,
+			})
+		}
+	}
+
+	var scores []*models.FactorScore
+	for _, def := range Definitions() {
+		group := byFactor[def.Name]
+		standardize(group, def.Direction)
+		scores = append(scores, group...)
+	}
+	return scores
+}
+
+// standardize 计算截面 z-score 与排名
+func standardize(group []*models.FactorScore, direction int) {
+	if len(group) == 0 {
+		return
+	}
+
+	var sum float64
+	for _, s := range group {
+		sum += s.Value
+	}
+	mean := sum / float64(len(group))
+
+	var sq float64
+	for _, s := range group {
+		sq += (s.Value - mean) * (s.Value - mean)
+	}
+	var sd float64
+	if len(group) > 1 {
+		sd = math.Sqrt(sq / float64(len(group)-1))
+	}
+
+	for _, s := range group {
+		if sd > 0 {
+			s.ZScore = math.Max(-zscoreLimit, math.Min(zscoreLimit, float64(direction)*(s.Value-mean)/sd))
+		}
+	}
+
+	sort.SliceStable(group, func(i, j int) bool { return group[i].ZScore > group[j].ZScore })
+	for i, s := range group {
+		s.Rank = i + 1
+		s.Percentile = 1
+		if len(group) > 1 {
+			s.Percentile = 1 - float64(i)/float64(len(group)-1)
+		}
+	}
+}
+
+// CompositeScore 多因子合成得分
+type CompositeScore struct {
+	Symbol   string             `json:"symbol"`
+	Exchange string             `json:"exchange"`
+	Score    float64            `json:"score"` // 各因子 z-score 加权和
+	Rank     int                `json:"rank"`
+	Factors  map[string]float64 `json:"factors"` // 各因子 z-score
+}
+
+// Composite 按权重合成多因子得分并排名，缺少任一因子得分的股票不参与
+func Composite(scores []*models.FactorScore, weights map[string]float64) []*CompositeScore {
+	byKey := make(map[string]*CompositeScore)
+	var keys []string
+	for _, s := range scores {
+		if _, ok := weights[s.Factor]; !ok {
+			continue
+		}
+		key := s.Symbol + "." + s.Exchange
+		c := byKey[key]
+		if c == nil {
+			c = &CompositeScore{Symbol: s.Symbol, Exchange: s.Exchange, Factors: make(map[string]float64)}
+			byKey[key] = c
+			keys = append(keys, key)
+		}
+		c.Factors[s.Factor] = s.ZScore
+	}
+	sort.Strings(keys)
+
+	var result []*CompositeScore
+	for _, key := range keys {
+		c := byKey[key]
+		if len(c.Factors) != len(weights) {
+			continue
+		}
+		for name, w := range weights {
+			c.Score += w * c.Factors[name]
+		}
+		result = append(result, c)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Score > result[j].Score })
+	for i, c := range result {
+		c.Rank = i + 1
+	}
+	return result
+}
+
+// Exposure 组合的因子暴露：持仓股票 z-score 按权重加权平均
+// weights 为 symbol.exchange -> 权重，缺少得分的股票不计入，剩余权重重新归一。
+func Exposure(scores []*models.FactorScore, weights map[string]float64) map[string]float64 {
+	sums := make(map[string]float64)
+	totals := make(map[string]float64)
+	for _, s := range scores {
+		w, ok := weights[s.Symbol+"."+s.Exchange]
+		if !ok || w <= 0 {
+			continue
+		}
+		sums[s.Factor] += w * s.ZScore
+		totals[s.Factor] += w
+	}
+
+	exposure := make(map[string]float64, len(sums))
+	for name, sum := range sums {
+		exposure[name] = sum / totals[name]
+	}
+	return exposure
+}
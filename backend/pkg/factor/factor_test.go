@@ -0,0 +1,83 @@
+package factor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// linearCloses 生成从 start 线性变化到 end 的收盘价序列
+func linearCloses(n int, start, end float64) []float64 {
+	closes := make([]float64, n)
+	for i := range closes {
+		closes[i] = start + (end-start)*float64(i)/float64(n-1)
+	}
+	return closes
+}
+
+func TestValues(t *testing.T) {
+	in := &Input{
+		Symbol:     "600519",
+		Exchange:   "SH",
+		Closes:     linearCloses(MinHistory, 100, 200),
+		Report:     &models.FinancialReport{ReportType: models.ReportTypeQ2, NetProfit: 5000, ShareholdersEquity: 40000},
+		TotalShare: 1000,
+	}
+	values := Values(in)
+
+	// 动量：剔除最近20日后的收盘价 / 250日前收盘价 - 1
+	want := in.Closes[MinHistory-1-MomentumSkip]/100 - 1
+	if got := values[models.FactorMomentum]; math.Abs(got-want) > 1e-9 {
+		t.Errorf("动量应为 %v，实际 %v", want, got)
+	}
+	// 市值 200*1000：半年报净利润年化后 E/P=0.05，B/P=0.2
+	if got := values[models.FactorValue]; math.Abs(got-0.125) > 1e-9 {
+		t.Errorf("价值因子应为 (0.05+0.2)/2=0.125，实际 %v", got)
+	}
+	if got := values[models.FactorSize]; math.Abs(got-math.Log(200*1000)) > 1e-9 {
+		t.Errorf("市值因子错误: %v", got)
+	}
+
+	// 历史不足时不返回价格类因子
+	short := Values(&Input{Closes: linearCloses(30, 10, 11)})
+	if _, ok := short[models.FactorMomentum]; ok {
+		t.Error("历史不足时不应计算动量")
+	}
+	if _, ok := short[models.FactorVolatility]; ok {
+		t.Error("历史不足时不应计算波动率")
+	}
+}
+
+func TestScoreDirectionAndComposite(t *testing.T) {
+	date := time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)
+	inputs := []*Input{
+		{Symbol: "A", Exchange: "SH", Closes: []float64{10}, TotalShare: 100},
+		{Symbol: "B", Exchange: "SH", Closes: []float64{10}, TotalShare: 1000},
+		{Symbol: "C", Exchange: "SH", Closes: []float64{10}, TotalShare: 10000},
+	}
+	scores := Score(date, inputs)
+	if len(scores) != 3 {
+		t.Fatalf("应只有市值因子得分，实际 %d 条", len(scores))
+	}
+
+	// 市值因子方向为负：市值最小的 A 排名第一
+	best := scores[0]
+	if best.Symbol != "A" || best.Rank != 1 || best.Percentile != 1 || best.ZScore <= 0 {
+		t.Errorf("市值最小的股票应排名第一: %+v", best)
+	}
+
+	composite := Composite(scores, map[string]float64{models.FactorSize: 1})
+	if len(composite) != 3 || composite[0].Symbol != "A" {
+		t.Errorf("合成得分排名错误: %+v", composite)
+	}
+	if got := Composite(scores, map[string]float64{models.FactorSize: 1, models.FactorMomentum: 1}); len(got) != 0 {
+		t.Errorf("缺少动量得分的股票不应参与合成，实际 %d 只", len(got))
+	}
+
+	exposure := Exposure(scores, map[string]float64{"A.SH": 1, "C.SH": 1})
+	if got := exposure[models.FactorSize]; math.Abs(got) > 1e-9 {
+		t.Errorf("A、C 等权组合的市值暴露应为 0，实际 %v", got)
+	}
+}
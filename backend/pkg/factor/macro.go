@@ -0,0 +1,86 @@
+package factor
+
+import (
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// 宏观敏感度因子参数（按月计）
+const (
+	MacroLookbackMonths = 24 // 回看月数
+	minMacroMonths      = 12 // 计算所需的最少有效月份
+
+	// MacroHistoryDays 计算宏观因子时日K线回看的自然日数
+	MacroHistoryDays = (MacroLookbackMonths+1)*31 + 10
+
+	// LookbackDays 任一因子回看的最长自然日数：一根日K线最多影响其后这么多天的因子得分
+	LookbackDays = max(HistoryDays, MacroHistoryDays)
+
+	macroFactorPrefix = "macro_"
+)
+
+// macroDefinitions 宏观敏感度因子：每个宏观序列一个，依赖宏观数据同步，属于可选因子
+var macroDefinitions = func() []Definition {
+	defs := make([]Definition, 0, len(models.MacroSeries))
+	for _, s := range models.MacroSeries {
+		defs = append(defs, Definition{
+			Name:        MacroFactorName(s.Name),
+			Description: "近24个月月度收益对" + s.Description + "月度变化的 Beta",
+			Direction:   1,
+			Optional:    true,
+		})
+	}
+	return defs
+}()
+
+// MacroFactorName 宏观序列对应的因子名称，如 macro_pmi
+func MacroFactorName(series string) string {
+	return macroFactorPrefix + series
+}
+
+// MacroBeta 个股月度收益对宏观序列月度变化的 Beta
+// 每月取最后一个交易日的收盘价计算收益，宏观值取该日已公布的最新值（按序列的 ReleaseDays 判断，不使用未来数据），
+// 使用最近 MacroLookbackMonths 个月；有效月份不足或宏观序列在区间内没有变化（如 LPR 长期不变）时返回 false。
+func MacroBeta(dates []time.Time, closes []float64, series string, points []*models.MacroPoint) (float64, bool) {
+	info, ok := models.LookupMacroSeries(series)
+	if !ok || len(dates) != len(closes) || len(points) == 0 {
+		return 0, false
+	}
+
+	// 月末收盘价与当时已公布的宏观值
+	var monthCloses, monthMacro []float64
+	var known []bool
+	j := -1
+	for i, d := range dates {
+		if i+1 < len(dates) && dates[i+1].Year() == d.Year() && dates[i+1].Month() == d.Month() {
+			continue
+		}
+		for j+1 < len(points) && !points[j+1].Date.AddDate(0, 0, info.ReleaseDays).After(d) {
+			j++
+		}
+		monthCloses = append(monthCloses, closes[i])
+		if j >= 0 {
+			monthMacro = append(monthMacro, points[j].Value)
+		} else {
+			monthMacro = append(monthMacro, 0)
+		}
+		known = append(known, j >= 0)
+	}
+
+	var returns, changes []float64
+	changed := false
+	for m := max(1, len(monthCloses)-MacroLookbackMonths); m < len(monthCloses); m++ {
+		if !known[m-1] || monthCloses[m-1] <= 0 {
+			continue
+		}
+		returns = append(returns, monthCloses[m]/monthCloses[m-1]-1)
+		changes = append(changes, monthMacro[m]-monthMacro[m-1])
+		changed = changed || changes[len(changes)-1] != changes[0]
+	}
+	if len(returns) < minMacroMonths || !changed {
+		return 0, false
+	}
+	return risk.Beta(returns, changes), true
+}
@@ -0,0 +1,69 @@
+package factor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// macroFixture 生成 months 个月的月末交易日、收盘价与 LPR 数据点，月度收益恰为 LPR 月度变化的 beta 倍
+func macroFixture(months int, beta float64) ([]time.Time, []float64, []*models.MacroPoint) {
+	var dates []time.Time
+	var closes []float64
+	var points []*models.MacroPoint
+	price := 100.0
+	for m := 0; m < months; m++ {
+		first := time.Date(2022, time.Month(1+m), 1, 0, 0, 0, 0, time.UTC)
+		value := 3.5 + 0.1*float64(m%3)
+		if m > 0 {
+			price *= 1 + beta*(value-points[m-1].Value)
+		}
+		points = append(points, &models.MacroPoint{Series: models.MacroLPR1Y, Date: first, Value: value})
+		dates = append(dates, first.AddDate(0, 0, 27))
+		closes = append(closes, price)
+	}
+	return dates, closes, points
+}
+
+func TestMacroBeta(t *testing.T) {
+	dates, closes, points := macroFixture(MacroLookbackMonths+6, 0.5)
+	beta, ok := MacroBeta(dates, closes, models.MacroLPR1Y, points)
+	if !ok || math.Abs(beta-0.5) > 1e-9 {
+		t.Fatalf("MacroBeta = %v, %v, want 0.5", beta, ok)
+	}
+
+	// 月份不足
+	if _, ok := MacroBeta(dates[:minMacroMonths], closes[:minMacroMonths], models.MacroLPR1Y, points); ok {
+		t.Error("有效月份不足时不应计算")
+	}
+	// 宏观序列没有变化
+	flat := make([]*models.MacroPoint, len(points))
+	for i, p := range points {
+		flat[i] = &models.MacroPoint{Series: p.Series, Date: p.Date, Value: 3.45}
+	}
+	if _, ok := MacroBeta(dates, closes, models.MacroLPR1Y, flat); ok {
+		t.Error("宏观序列没有变化时不应计算")
+	}
+	// CPI 数据滞后 40 天公布，月末只能用到上上个月的值，与收益错位后 beta 不再是 0.5
+	if beta, ok := MacroBeta(dates, closes, models.MacroCPI, points); ok && math.Abs(beta-0.5) < 1e-6 {
+		t.Error("应按公布日期使用宏观数据")
+	}
+}
+
+func TestValuesMacro(t *testing.T) {
+	dates, closes, points := macroFixture(MacroLookbackMonths+1, -1)
+	values := Values(&Input{Closes: closes, Dates: dates, Macro: map[string][]*models.MacroPoint{models.MacroLPR1Y: points}})
+	if got, ok := values[MacroFactorName(models.MacroLPR1Y)]; !ok || math.Abs(got+1) > 1e-9 {
+		t.Fatalf("宏观因子 = %v, %v, want -1", got, ok)
+	}
+
+	def, ok := Lookup("macro_pmi")
+	if !ok || !def.Optional {
+		t.Fatalf("宏观因子应为可选因子: %+v", def)
+	}
+	if _, ok := Lookup("macro_gdp"); ok {
+		t.Error("不支持的宏观序列不应有因子")
+	}
+}
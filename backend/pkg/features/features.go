@@ -0,0 +1,153 @@
+// Package features 维护模式与功能开关。
+// 配置来自热更新配置文件的 features 部分；配置了 Redis 时定期读取 features 键（与配置文件相同结构的 JSON 或 YAML），
+// 键存在时以其为准，运维可不改文件、不重启即时开关，且对所有实例同时生效。
+// 功能开关按用户 ID 哈希分桶灰度：同一用户在比例不变时始终落在同一侧，比例调大时已开放的用户保持开放。
+package features
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gopkg.in/yaml.v3"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// RedisKey Redis 中覆盖配置文件的键
+const RedisKey = "features"
+
+// DefaultPollInterval 默认读取 Redis 的间隔
+const DefaultPollInterval = 10 * time.Second
+
+// Store 当前生效的维护模式与功能开关，nil 表示未启用（不维护，所有功能开放）
+type Store struct {
+	client *redis.Client // 未配置 Redis 时为 nil
+
+	mu       sync.RWMutex
+	file     config.FeaturesConfig
+	override *config.FeaturesConfig // Redis 中的配置，键不存在时为 nil
+}
+
+// New 创建功能开关，配置文件变化时自动更新；client 为 nil 时只使用配置文件
+func New(live *config.Live, client *redis.Client) *Store {
+	s := &Store{client: client}
+	live.OnChange(func(c *config.Config) {
+		s.mu.Lock()
+		s.file = c.Features
+		s.mu.Unlock()
+	})
+	return s
+}
+
+// Run 定期读取 Redis 中的配置直到 ctx 取消，未配置 Redis 时立即返回
+func (s *Store) Run(ctx context.Context, interval time.Duration) {
+	if s == nil || s.client == nil {
+		return
+	}
+	s.refresh(ctx)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh(ctx)
+		}
+	}
+}
+
+// refresh 读取 Redis 中的配置，读取或校验失败时保留当前配置
+func (s *Store) refresh(ctx context.Context) {
+	data, err := s.client.Get(ctx, RedisKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		s.setOverride(nil)
+		return
+	}
+	if err != nil {
+		log.Printf("读取功能开关失败，继续使用当前配置: %v", err)
+		return
+	}
+	var next config.FeaturesConfig
+	if err := yaml.Unmarshal(data, &next); err != nil {
+		log.Printf("解析 Redis 中的功能开关失败，继续使用当前配置: %v", err)
+		return
+	}
+	if err := next.Validate(); err != nil {
+		log.Printf("Redis 中的功能开关无效，继续使用当前配置: %v", err)
+		return
+	}
+	s.setOverride(&next)
+}
+
+func (s *Store) setOverride(c *config.FeaturesConfig) {
+	s.mu.Lock()
+	s.override = c
+	s.mu.Unlock()
+}
+
+// current 当前生效的配置
+func (s *Store) current() config.FeaturesConfig {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.override != nil {
+		return *s.override
+	}
+	return s.file
+}
+
+// Maintenance 路径是否处于维护中，path 为去掉 /api/<版本> 后的路径（如 /backtest/run）
+func (s *Store) Maintenance(path string) (config.MaintenanceConfig, bool) {
+	if s == nil {
+		return config.MaintenanceConfig{}, false
+	}
+	m := s.current().Maintenance
+	if !m.Enabled {
+		return m, false
+	}
+	if len(m.Routes) == 0 {
+		return m, true
+	}
+	for _, route := range m.Routes {
+		route = "/" + strings.Trim(route, "/")
+		if route == "/" || path == route || strings.HasPrefix(path, route+"/") {
+			return m, true
+		}
+	}
+	return m, false
+}
+
+// Enabled 功能是否对用户开放，未配置的开关视为已全量开放
+func (s *Store) Enabled(name string, userID uint) bool {
+	if s == nil {
+		return true
+	}
+	flag, ok := s.current().Flags[name]
+	if !ok {
+		return true
+	}
+	return flag.Enabled || containsUser(flag.Users, userID) || bucket(name, userID) < flag.Percentage
+}
+
+// bucket 用户在开关下的分桶（0-99），不同开关的分桶相互独立
+func bucket(name string, userID uint) int {
+	h := fnv.New32a()
+	h.Write([]byte(name + ":" + strconv.FormatUint(uint64(userID), 10)))
+	return int(h.Sum32() % 100)
+}
+
+func containsUser(users []uint, userID uint) bool {
+	for _, u := range users {
+		if u == userID {
+			return true
+		}
+	}
+	return false
+}
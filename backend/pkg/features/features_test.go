@@ -0,0 +1,128 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+func newTestStore(t *testing.T, fc config.FeaturesConfig, client *redis.Client) *Store {
+	t.Helper()
+	live, err := config.NewLive(&config.Config{Features: fc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return New(live, client)
+}
+
+func TestEnabled(t *testing.T) {
+	s := newTestStore(t, config.FeaturesConfig{Flags: map[string]config.FeatureFlag{
+		"off":     {},
+		"on":      {Enabled: true},
+		"half":    {Percentage: 50},
+		"beta":    {Users: []uint{7}},
+		"rollout": {Percentage: 10},
+	}}, nil)
+
+	if !s.Enabled("unknown", 1) {
+		t.Error("未配置的开关应视为全量开放")
+	}
+	if s.Enabled("off", 1) || !s.Enabled("on", 1) {
+		t.Error("off/on 开关结果错误")
+	}
+	if !s.Enabled("beta", 7) || s.Enabled("beta", 8) {
+		t.Error("指定用户开关结果错误")
+	}
+
+	// 比例接近配置值，且同一用户结果稳定
+	enabled := 0
+	for id := uint(1); id <= 2000; id++ {
+		if s.Enabled("half", id) {
+			enabled++
+		}
+		if s.Enabled("half", id) != s.Enabled("half", id) {
+			t.Fatalf("用户 %d 的结果不稳定", id)
+		}
+	}
+	if enabled < 900 || enabled > 1100 {
+		t.Errorf("50%% 灰度开放了 %d/2000 个用户", enabled)
+	}
+
+	// 调大比例时已开放的用户保持开放
+	wider := newTestStore(t, config.FeaturesConfig{Flags: map[string]config.FeatureFlag{"rollout": {Percentage: 30}}}, nil)
+	for id := uint(1); id <= 500; id++ {
+		if s.Enabled("rollout", id) && !wider.Enabled("rollout", id) {
+			t.Fatalf("用户 %d 在扩大灰度后被关闭", id)
+		}
+	}
+
+	var nilStore *Store
+	if !nilStore.Enabled("off", 1) {
+		t.Error("未启用功能开关时所有功能开放")
+	}
+}
+
+func TestMaintenance(t *testing.T) {
+	s := newTestStore(t, config.FeaturesConfig{Maintenance: config.MaintenanceConfig{
+		Enabled: true,
+		Routes:  []string{"/backtest", "data/sync/"},
+	}}, nil)
+
+	tests := map[string]bool{
+		"/backtest":          true,
+		"/backtest/run":      true,
+		"/data/sync/daily":   true,
+		"/backtesting":       false,
+		"/data/stocks":       false,
+		"/market/quote/AAPL": false,
+	}
+	for path, want := range tests {
+		if _, got := s.Maintenance(path); got != want {
+			t.Errorf("Maintenance(%q) = %v, want %v", path, got, want)
+		}
+	}
+
+	all := newTestStore(t, config.FeaturesConfig{Maintenance: config.MaintenanceConfig{Enabled: true}}, nil)
+	if _, ok := all.Maintenance("/market/quote/AAPL"); !ok {
+		t.Error("未指定路由时所有接口维护")
+	}
+}
+
+func TestRedisOverride(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	s := newTestStore(t, config.FeaturesConfig{Flags: map[string]config.FeatureFlag{"replay_ws": {}}}, client)
+	ctx := context.Background()
+
+	s.refresh(ctx)
+	if s.Enabled("replay_ws", 1) {
+		t.Fatal("Redis 中没有配置时使用配置文件")
+	}
+
+	mr.Set(RedisKey, `{"maintenance":{"enabled":true,"message":"升级中"},"flags":{"replay_ws":{"enabled":true}}}`)
+	s.refresh(ctx)
+	if !s.Enabled("replay_ws", 1) {
+		t.Error("Redis 中的配置应覆盖配置文件")
+	}
+	if m, ok := s.Maintenance("/market"); !ok || m.Message != "升级中" {
+		t.Errorf("Maintenance = %+v, %v", m, ok)
+	}
+
+	// 无效配置保留当前配置
+	mr.Set(RedisKey, `{"flags":{"replay_ws":{"percentage":150}}}`)
+	s.refresh(ctx)
+	if !s.Enabled("replay_ws", 1) {
+		t.Error("无效配置不应生效")
+	}
+
+	mr.Del(RedisKey)
+	s.refresh(ctx)
+	if s.Enabled("replay_ws", 1) {
+		t.Error("删除 Redis 中的配置后恢复配置文件")
+	}
+}
@@ -0,0 +1,37 @@
+package indicator
+
+// ============ K线衍生指标 ============
+
+// Derived 单根K线的衍生指标，无法计算时为空
+type Derived struct {
+	TurnoverRate *float64 // 换手率（%）：成交量 / 流通股本
+	Amplitude    *float64 // 振幅（%）：(最高 - 最低) / 前收盘
+	VWAP         *float64 // 成交均价：成交额 / 成交量
+}
+
+// DerivedMetrics 逐根计算换手率、振幅与成交均价
+// prevClose 为序列第一根K线之前的收盘价，未知时传 0，此时第一根K线的振幅为空；
+// floatShare 为流通股本（股，与成交量单位一致），不大于 0 时换手率为空。
+func DerivedMetrics(s *Series, floatShare int64, prevClose float64) []Derived {
+	out := make([]Derived, s.Len())
+	for i := range out {
+		if i > 0 {
+			prevClose = s.Close[i-1]
+		}
+		d := &out[i]
+		if floatShare > 0 {
+			d.TurnoverRate = ptr(s.Volume[i] / float64(floatShare) * 100)
+		}
+		if prevClose > 0 {
+			d.Amplitude = ptr((s.High[i] - s.Low[i]) / prevClose * 100)
+		}
+		if s.Volume[i] > 0 {
+			d.VWAP = ptr(s.Amount[i] / s.Volume[i])
+		}
+	}
+	return out
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
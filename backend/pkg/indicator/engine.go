@@ -0,0 +1,475 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// Series 按时间升序排列的K线序列
+type Series struct {
+	Time   []time.Time
+	Open   []float64
+	High   []float64
+	Low    []float64
+	Close  []float64
+	Volume []float64
+	Amount []float64
+}
+
+// Len K线数量
+func (s *Series) Len() int {
+	return len(s.Close)
+}
+
+// Append 追加一根K线
+func (s *Series) Append(t time.Time, open, high, low, close, volume, amount float64) {
+	s.Time = append(s.Time, t)
+	s.Open = append(s.Open, open)
+	s.High = append(s.High, high)
+	s.Low = append(s.Low, low)
+	s.Close = append(s.Close, close)
+	s.Volume = append(s.Volume, volume)
+	s.Amount = append(s.Amount, amount)
+}
+
+// Tail 返回最后 n 根K线组成的序列（共享底层数据）
+func (s *Series) Tail(n int) *Series {
+	if n >= s.Len() {
+		return s
+	}
+	from := s.Len() - n
+	return &Series{
+		Time:   s.Time[from:],
+		Open:   s.Open[from:],
+		High:   s.High[from:],
+		Low:    s.Low[from:],
+		Close:  s.Close[from:],
+		Volume: s.Volume[from:],
+		Amount: s.Amount[from:],
+	}
+}
+
+// FromDailyBars 由日K线构建序列
+func FromDailyBars(bars []*models.DailyBar) *Series {
+	s := &Series{}
+	for _, b := range bars {
+		s.Append(b.Date, b.Open, b.High, b.Low, b.Close, float64(b.Volume), b.Amount)
+	}
+	return s
+}
+
+// FromMinuteBars 由分钟K线构建序列
+func FromMinuteBars(bars []*models.MinuteBar) *Series {
+	s := &Series{}
+	for _, b := range bars {
+		s.Append(b.Time, b.Open, b.High, b.Low, b.Close, float64(b.Volume), b.Amount)
+	}
+	return s
+}
+
+// ============ 语法树节点 ============
+
+type node interface {
+	eval(s *Series) []float64
+	lookback() int
+}
+
+// fields 行情字段及其简写
+var fields = map[string]func(s *Series) []float64{
+	"OPEN": func(s *Series) []float64 { return s.Open }, "O": func(s *Series) []float64 { return s.Open },
+	"HIGH": func(s *Series) []float64 { return s.High }, "H": func(s *Series) []float64 { return s.High },
+	"LOW": func(s *Series) []float64 { return s.Low }, "L": func(s *Series) []float64 { return s.Low },
+	"CLOSE": func(s *Series) []float64 { return s.Close }, "C": func(s *Series) []float64 { return s.Close },
+	"VOLUME": func(s *Series) []float64 { return s.Volume }, "VOL": func(s *Series) []float64 { return s.Volume },
+	"V": func(s *Series) []float64 { return s.Volume }, "AMOUNT": func(s *Series) []float64 { return s.Amount },
+}
+
+type constNode float64
+
+func (n constNode) eval(s *Series) []float64 {
+	out := make([]float64, s.Len())
+	for i := range out {
+		out[i] = float64(n)
+	}
+	return out
+}
+
+func (constNode) lookback() int { return 0 }
+
+type fieldNode func(s *Series) []float64
+
+func (n fieldNode) eval(s *Series) []float64 {
+	return append([]float64(nil), n(s)...)
+}
+
+func (fieldNode) lookback() int { return 0 }
+
+type binaryNode struct {
+	op          byte
+	left, right node
+}
+
+func (n *binaryNode) eval(s *Series) []float64 {
+	a, b := n.left.eval(s), n.right.eval(s)
+	out := make([]float64, len(a))
+	for i := range a {
+		switch n.op {
+		case '+':
+			out[i] = a[i] + b[i]
+		case '-':
+			out[i] = a[i] - b[i]
+		case '*':
+			out[i] = a[i] * b[i]
+		case '/':
+			if b[i] == 0 {
+				out[i] = math.NaN()
+			} else {
+				out[i] = a[i] / b[i]
+			}
+		}
+	}
+	return out
+}
+
+func (n *binaryNode) lookback() int {
+	return max(n.left.lookback(), n.right.lookback())
+}
+
+// funcNode 函数调用，x 为输入序列（ATR、OBV 与 DMI 系列除外），period 为周期参数
+type funcNode struct {
+	name   string
+	x, y   node
+	period int
+}
+
+func (n *funcNode) eval(s *Series) []float64 {
+	switch n.name {
+	case "MA":
+		return rolling(n.x.eval(s), n.period, mean)
+	case "STD":
+		return rolling(n.x.eval(s), n.period, stddev)
+	case "AVEDEV":
+		return rolling(n.x.eval(s), n.period, avedev)
+	case "HHV":
+		return rolling(n.x.eval(s), n.period, highest)
+	case "LLV":
+		return rolling(n.x.eval(s), n.period, lowest)
+	case "EMA":
+		return emaSeries(n.x.eval(s), n.period)
+	case "RSI":
+		return rsiSeries(n.x.eval(s), n.period)
+	case "REF":
+		x := n.x.eval(s)
+		out := nanSeries(len(x))
+		for i := n.period; i < len(x); i++ {
+			out[i] = x[i-n.period]
+		}
+		return out
+	case "ATR":
+		return rolling(trueRange(s), n.period, mean)
+	case "OBV":
+		return obvSeries(s)
+	case "PDI", "MDI", "ADX":
+		pdi, mdi, adx := dmiSeries(s, n.period)
+		switch n.name {
+		case "PDI":
+			return pdi
+		case "MDI":
+			return mdi
+		}
+		return adx
+	case "ABS":
+		x := n.x.eval(s)
+		for i := range x {
+			x[i] = math.Abs(x[i])
+		}
+		return x
+	case "MAX", "MIN":
+		a, b := n.x.eval(s), n.y.eval(s)
+		for i := range a {
+			if n.name == "MAX" {
+				a[i] = math.Max(a[i], b[i])
+			} else {
+				a[i] = math.Min(a[i], b[i])
+			}
+		}
+		return a
+	}
+	return nanSeries(s.Len())
+}
+
+func (n *funcNode) lookback() int {
+	switch n.name {
+	case "MA", "STD", "AVEDEV", "HHV", "LLV", "EMA":
+		return n.x.lookback() + n.period - 1
+	case "RSI", "REF":
+		return n.x.lookback() + n.period
+	case "ATR", "PDI", "MDI":
+		return n.period
+	case "ADX":
+		return 2*n.period - 1
+	case "OBV":
+		return 0
+	case "MAX", "MIN":
+		return max(n.x.lookback(), n.y.lookback())
+	}
+	return n.x.lookback()
+}
+
+// newFunc 校验参数并创建函数节点
+// MA/EMA/STD/AVEDEV/HHV/LLV/RSI 可写作 F(n) 或 F(x, n)，省略 x 时作用于收盘价。
+func newFunc(name string, args []node) (node, error) {
+	switch name {
+	case "MA", "EMA", "STD", "AVEDEV", "HHV", "LLV", "RSI":
+		switch len(args) {
+		case 1:
+			period, err := periodArg(name, args[0])
+			if err != nil {
+				return nil, err
+			}
+			return &funcNode{name: name, x: fieldNode(fields["CLOSE"]), period: period}, nil
+		case 2:
+			period, err := periodArg(name, args[1])
+			if err != nil {
+				return nil, err
+			}
+			return &funcNode{name: name, x: args[0], period: period}, nil
+		}
+		return nil, fmt.Errorf("%s 需要 1~2 个参数：%s(n) 或 %s(x, n)", name, name, name)
+	case "REF":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("REF 需要 2 个参数：REF(x, n)")
+		}
+		period, err := periodArg(name, args[1])
+		if err != nil {
+			return nil, err
+		}
+		return &funcNode{name: name, x: args[0], period: period}, nil
+	case "ATR", "PDI", "MDI", "ADX":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s 需要 1 个参数：%s(n)", name, name)
+		}
+		period, err := periodArg(name, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return &funcNode{name: name, period: period}, nil
+	case "OBV":
+		if len(args) != 0 {
+			return nil, fmt.Errorf("OBV 不需要参数：OBV()")
+		}
+		return &funcNode{name: name}, nil
+	case "ABS":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("ABS 需要 1 个参数")
+		}
+		return &funcNode{name: name, x: args[0]}, nil
+	case "MAX", "MIN":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("%s 需要 2 个参数", name)
+		}
+		return &funcNode{name: name, x: args[0], y: args[1]}, nil
+	}
+	return nil, fmt.Errorf("未知的函数: %s", name)
+}
+
+// periodArg 周期参数必须是 1~1000 的整数常量
+func periodArg(name string, arg node) (int, error) {
+	c, ok := arg.(constNode)
+	if !ok || float64(c) != math.Trunc(float64(c)) || c < 1 || c > maxPeriod {
+		return 0, fmt.Errorf("%s 的周期应为 1~%d 的整数常量", name, maxPeriod)
+	}
+	return int(c), nil
+}
+
+// ============ 计算 ============
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
+
+// rolling 滚动窗口计算，窗口不足或含 NaN 时为 NaN
+func rolling(x []float64, n int, fn func(window []float64) float64) []float64 {
+	out := nanSeries(len(x))
+	for i := n - 1; i < len(x); i++ {
+		window := x[i-n+1 : i+1]
+		valid := true
+		for _, v := range window {
+			if math.IsNaN(v) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			out[i] = fn(window)
+		}
+	}
+	return out
+}
+
+func mean(w []float64) float64 {
+	var sum float64
+	for _, v := range w {
+		sum += v
+	}
+	return sum / float64(len(w))
+}
+
+// stddev 总体标准差（与通达信 STD 的样本标准差不同，n 较大时差异可忽略）
+func stddev(w []float64) float64 {
+	m := mean(w)
+	var sum float64
+	for _, v := range w {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(w)))
+}
+
+// avedev 平均绝对偏差
+func avedev(w []float64) float64 {
+	m := mean(w)
+	var sum float64
+	for _, v := range w {
+		sum += math.Abs(v - m)
+	}
+	return sum / float64(len(w))
+}
+
+func highest(w []float64) float64 {
+	h := w[0]
+	for _, v := range w[1:] {
+		h = math.Max(h, v)
+	}
+	return h
+}
+
+func lowest(w []float64) float64 {
+	l := w[0]
+	for _, v := range w[1:] {
+		l = math.Min(l, v)
+	}
+	return l
+}
+
+// emaSeries 指数移动平均，以首个完整窗口的简单平均作为初值；遇到 NaN 时该点为 NaN 并沿用前值
+func emaSeries(x []float64, n int) []float64 {
+	out := nanSeries(len(x))
+	k := 2 / float64(n+1)
+	seeded := false
+	var prev float64
+	run := 0
+	for i, v := range x {
+		if math.IsNaN(v) {
+			run = 0
+			continue
+		}
+		if seeded {
+			prev = v*k + prev*(1-k)
+			out[i] = prev
+			continue
+		}
+		run++
+		if run == n {
+			prev = mean(x[i-n+1 : i+1])
+			out[i] = prev
+			seeded = true
+		}
+	}
+	return out
+}
+
+// rsiSeries Wilder 平滑的相对强弱指数
+func rsiSeries(x []float64, n int) []float64 {
+	out := nanSeries(len(x))
+	var gain, loss float64
+	count := 0
+	for i := 1; i < len(x); i++ {
+		change := x[i] - x[i-1]
+		if math.IsNaN(change) {
+			continue
+		}
+		up, down := math.Max(change, 0), math.Max(-change, 0)
+		count++
+		if count <= n {
+			gain += up / float64(n)
+			loss += down / float64(n)
+			if count < n {
+				continue
+			}
+		} else {
+			gain = (gain*float64(n-1) + up) / float64(n)
+			loss = (loss*float64(n-1) + down) / float64(n)
+		}
+		if gain+loss == 0 {
+			out[i] = 50
+		} else {
+			out[i] = 100 * gain / (gain + loss)
+		}
+	}
+	return out
+}
+
+// trueRange 真实波幅，首根K线没有昨收为 NaN
+func trueRange(s *Series) []float64 {
+	out := nanSeries(s.Len())
+	for i := 1; i < s.Len(); i++ {
+		prevClose := s.Close[i-1]
+		out[i] = math.Max(s.High[i]-s.Low[i], math.Max(math.Abs(s.High[i]-prevClose), math.Abs(s.Low[i]-prevClose)))
+	}
+	return out
+}
+
+// obvSeries 能量潮：收盘上涨累加成交量、下跌累减，从序列第一根K线（记为 0）开始累计
+func obvSeries(s *Series) []float64 {
+	out := make([]float64, s.Len())
+	for i := 1; i < s.Len(); i++ {
+		out[i] = out[i-1]
+		switch {
+		case s.Close[i] > s.Close[i-1]:
+			out[i] += s.Volume[i]
+		case s.Close[i] < s.Close[i-1]:
+			out[i] -= s.Volume[i]
+		}
+	}
+	return out
+}
+
+// dmiSeries 趋向指标：n 日真实波幅与上升/下降动向之和计算 +DI、-DI，ADX 为 DX 的 n 日简单平均
+func dmiSeries(s *Series, n int) (pdi, mdi, adx []float64) {
+	size := s.Len()
+	plus, minus := nanSeries(size), nanSeries(size)
+	for i := 1; i < size; i++ {
+		up, down := s.High[i]-s.High[i-1], s.Low[i-1]-s.Low[i]
+		plus[i], minus[i] = 0, 0
+		if up > 0 && up > down {
+			plus[i] = up
+		}
+		if down > 0 && down > up {
+			minus[i] = down
+		}
+	}
+	sum := func(w []float64) float64 { return mean(w) * float64(len(w)) }
+	tr := rolling(trueRange(s), n, sum)
+	dmp, dmm := rolling(plus, n, sum), rolling(minus, n, sum)
+
+	pdi, mdi, dx := nanSeries(size), nanSeries(size), nanSeries(size)
+	for i := range tr {
+		if math.IsNaN(tr[i]) || tr[i] == 0 {
+			continue
+		}
+		pdi[i], mdi[i] = dmp[i]*100/tr[i], dmm[i]*100/tr[i]
+		if total := pdi[i] + mdi[i]; total > 0 {
+			dx[i] = math.Abs(pdi[i]-mdi[i]) / total * 100
+		} else {
+			dx[i] = 0
+		}
+	}
+	return pdi, mdi, rolling(dx, n, mean)
+}
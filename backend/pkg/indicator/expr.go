@@ -0,0 +1,249 @@
+// Package indicator 自定义指标：解析基于 OHLCV 与内置指标函数的表达式，并按K线序列计算，
+// 如 (CLOSE - MA(20)) / ATR(14)。
+package indicator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxExprLength = 500  // 表达式最大长度
+	maxPeriod     = 1000 // 函数周期上限
+)
+
+// Expr 编译后的表达式
+type Expr struct {
+	source string
+	root   node
+}
+
+// Compile 解析表达式
+// 支持 + - * / 与括号、数字常量、行情字段（OPEN/HIGH/LOW/CLOSE/VOLUME/AMOUNT，可简写为 O/H/L/C/V），
+// 以及函数 MA、EMA、STD、AVEDEV、HHV、LLV、REF、RSI、ATR、OBV、PDI、MDI、ADX、ABS、MAX、MIN，名称不区分大小写。
+func Compile(source string) (*Expr, error) {
+	source = strings.TrimSpace(source)
+	if source == "" {
+		return nil, fmt.Errorf("表达式不能为空")
+	}
+	if len(source) > maxExprLength {
+		return nil, fmt.Errorf("表达式过长，最多 %d 个字符", maxExprLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("表达式在 %q 处有多余内容", p.peek().text)
+	}
+	return &Expr{source: source, root: root}, nil
+}
+
+// String 返回原始表达式
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Lookback 计算首个有效值所需的K线数（不含当根），用于向前多取预热数据
+func (e *Expr) Lookback() int {
+	return e.root.lookback()
+}
+
+// Eval 按K线序列计算表达式，返回与序列等长的结果，数据不足或除零处为 NaN
+func (e *Expr) Eval(s *Series) []float64 {
+	return e.root.eval(s)
+}
+
+// ============ 词法分析 ============
+
+type tokenKind int
+
+const (
+	tokenNumber tokenKind = iota
+	tokenIdent
+	tokenOp
+	tokenEOF
+)
+
+type token struct {
+	kind  tokenKind
+	text  string
+	value float64
+}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || r == '.':
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			text := string(runes[i:j])
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("无效的数字: %s", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: strings.ToUpper(string(runes[i:j]))})
+			i = j
+		case strings.ContainsRune("+-*/(),", r):
+			tokens = append(tokens, token{kind: tokenOp, text: string(r)})
+			i++
+		default:
+			return nil, fmt.Errorf("无法识别的字符: %q", r)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "结尾"}), nil
+}
+
+// ============ 语法分析 ============
+
+// parser 递归下降解析：
+//
+//	expr    = term { ("+" | "-") term }
+//	term    = unary { ("*" | "/") unary }
+//	unary   = "-" unary | primary
+//	primary = number | field | func "(" args ")" | "(" expr ")"
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) done() bool { return p.peek().kind == tokenEOF }
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokenOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.accept("+") && !p.accept("-") {
+			return left, nil
+		}
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op[0], left: left, right: right}
+	}
+}
+
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.accept("*") && !p.accept("/") {
+			return left, nil
+		}
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op[0], left: left, right: right}
+	}
+}
+
+func (p *parser) unary() (node, error) {
+	if p.accept("-") {
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{op: '-', left: constNode(0), right: operand}, nil
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber:
+		return constNode(t.value), nil
+	case tokenIdent:
+		if p.accept("(") {
+			args, err := p.args()
+			if err != nil {
+				return nil, err
+			}
+			return newFunc(t.text, args)
+		}
+		if f, ok := fields[t.text]; ok {
+			return fieldNode(f), nil
+		}
+		return nil, fmt.Errorf("未知的字段: %s", t.text)
+	case tokenOp:
+		if t.text == "(" {
+			inner, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if !p.accept(")") {
+				return nil, fmt.Errorf("缺少右括号")
+			}
+			return inner, nil
+		}
+	}
+	return nil, fmt.Errorf("表达式在 %q 处不完整", t.text)
+}
+
+func (p *parser) args() ([]node, error) {
+	var args []node
+	if p.accept(")") {
+		return args, nil
+	}
+	for {
+		arg, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.accept(")") {
+			return args, nil
+		}
+		if !p.accept(",") {
+			return nil, fmt.Errorf("函数参数之间缺少逗号或右括号")
+		}
+	}
+}
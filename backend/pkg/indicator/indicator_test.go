@@ -0,0 +1,305 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func series(closes ...float64) *Series {
+	s := &Series{}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		s.Append(start.AddDate(0, 0, i), c, c+1, c-1, c, 100, c*100)
+	}
+	return s
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, src := range []string{"", "close +", "MA(close)", "MA(2.5)", "FOO(3)", "close)", "(close", "close $ 1", "xyz", "REF(close)"} {
+		if _, err := Compile(src); err == nil {
+			t.Errorf("Compile(%q) expected error", src)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	s := series(1, 2, 3, 4, 5)
+	tests := []struct {
+		src      string
+		want     []float64
+		lookback int
+	}{
+		{"close * 2 - 1", []float64{1, 3, 5, 7, 9}, 0},
+		{"-c + 10", []float64{9, 8, 7, 6, 5}, 0},
+		{"MA(3)", []float64{math.NaN(), math.NaN(), 2, 3, 4}, 2},
+		{"(close - MA(3)) / ATR(2)", []float64{math.NaN(), math.NaN(), 0.5, 0.5, 0.5}, 2},
+		{"REF(c, 1)", []float64{math.NaN(), 1, 2, 3, 4}, 1},
+		{"HHV(high, 2) - LLV(low, 2)", []float64{math.NaN(), 3, 3, 3, 3}, 1},
+		{"MAX(close, 3) + MIN(close, 3)", []float64{4, 5, 6, 7, 8}, 0},
+		{"EMA(3)", []float64{math.NaN(), math.NaN(), 2, 3, 4}, 2},
+		{"RSI(2)", []float64{math.NaN(), math.NaN(), 100, 100, 100}, 2},
+		{"close / (close - close)", []float64{math.NaN(), math.NaN(), math.NaN(), math.NaN(), math.NaN()}, 0},
+	}
+	for _, tt := range tests {
+		e, err := Compile(tt.src)
+		if err != nil {
+			t.Fatalf("Compile(%q): %v", tt.src, err)
+		}
+		if e.Lookback() != tt.lookback {
+			t.Errorf("%s lookback = %d, want %d", tt.src, e.Lookback(), tt.lookback)
+		}
+		got := e.Eval(s)
+		for i := range tt.want {
+			if math.IsNaN(tt.want[i]) != math.IsNaN(got[i]) || (!math.IsNaN(got[i]) && math.Abs(got[i]-tt.want[i]) > 1e-9) {
+				t.Errorf("%s = %v, want %v", tt.src, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestStandard(t *testing.T) {
+	closes := make([]float64, 120)
+	for i := range closes {
+		closes[i] = 10 + math.Sin(float64(i)/5)
+	}
+	s := series(closes...)
+	from := s.Time[100]
+
+	got := Standard("600000", "SH", s, from)
+	counts := map[string]int{}
+	for _, ind := range got {
+		if ind.Date.Before(from) {
+			t.Fatalf("不应返回 from 之前的指标: %v", ind.Date)
+		}
+		counts[ind.IndicatorType]++
+	}
+	for _, typ := range []string{"ma", "macd", "rsi", "kdj", "boll", "atr", "cci", "obv", "dmi", "wr", "bias"} {
+		if counts[typ] != 20 {
+			t.Errorf("%s 指标条数 = %d，期望 20", typ, counts[typ])
+		}
+	}
+
+	last := map[string]*models.Indicator{}
+	for _, ind := range got {
+		last[ind.IndicatorType] = ind
+	}
+	if ma := last["ma"]; math.Abs(ma.MA5-(closes[115]+closes[116]+closes[117]+closes[118]+closes[119])/5) > 1e-9 {
+		t.Errorf("MA5 = %v", ma.MA5)
+	}
+	if m := last["macd"]; math.Abs(m.MACDHist-2*(m.MACD-m.MACDSignal)) > 1e-9 {
+		t.Errorf("MACD 柱 = %v", m.MACDHist)
+	}
+	if b := last["boll"]; !(b.BollLower < b.BollMid && b.BollMid < b.BollUpper) {
+		t.Errorf("布林带上中下轨顺序错误: %+v", b)
+	}
+	if k := last["kdj"]; k.K < 0 || k.K > 100 || math.Abs(k.J-(3*k.K-2*k.D)) > 1e-9 {
+		t.Errorf("KDJ 错误: %+v", k)
+	}
+
+	// 数据不足时跳过对应指标，只有不需要预热的 OBV
+	for _, ind := range Standard("600000", "SH", series(1, 2, 3), time.Time{}) {
+		if ind.IndicatorType != "obv" {
+			t.Errorf("数据不足时不应返回 %s 指标", ind.IndicatorType)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	// 收盘 10,11,...,19 逐日上涨，最高/最低为收盘 ±1，成交量 100
+	closes := make([]float64, 10)
+	for i := range closes {
+		closes[i] = float64(10 + i)
+	}
+	s := series(closes...)
+
+	last := func(typ string, params ...int) *models.Indicator {
+		got, err := Compute("600000", "SH", typ, params, s, time.Time{})
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		if len(got) == 0 {
+			t.Fatalf("%s%v 没有结果", typ, params)
+		}
+		return got[len(got)-1]
+	}
+
+	if got := last("atr", 3); got.ATR != 2 || got.Period != 3 {
+		t.Errorf("ATR(3) = %v，期望 2", got.ATR)
+	}
+	if got := last("wr", 3); got.WR != 25 {
+		t.Errorf("WR(3) = %v，期望 25（最高 20、最低 16、收盘 19）", got.WR)
+	}
+	if got := last("bias", 3); math.Abs(got.BIAS-100.0/18) > 1e-9 {
+		t.Errorf("BIAS(3) = %v，期望 %v", got.BIAS, 100.0/18)
+	}
+	if got := last("obv"); got.OBV != 900 || got.Period != 0 {
+		t.Errorf("OBV = %v，期望 900", got.OBV)
+	}
+	if got := last("cci", 3); math.Abs(got.CCI-100) > 1e-9 {
+		t.Errorf("CCI(3) = %v，期望 100", got.CCI)
+	}
+	if got := last("dmi", 3); got.PDI != 50 || got.MDI != 0 || got.ADX != 100 {
+		t.Errorf("DMI(3) = %+v，单边上涨时 +DI 50、-DI 0、ADX 100", got)
+	}
+
+	if _, err := Compute("600000", "SH", "atr", []int{0}, s, time.Time{}); err == nil {
+		t.Error("周期为 0 时应报错")
+	}
+	if _, err := Compute("600000", "SH", "obv", []int{5}, s, time.Time{}); err == nil {
+		t.Error("obv 传周期时应报错")
+	}
+	if _, err := Compute("600000", "SH", "ma", []int{5}, s, time.Time{}); err == nil {
+		t.Error("不是单周期类型时应报错")
+	}
+	if days := WarmupDays("dmi", []int{14}); days != 27*3/2+10 {
+		t.Errorf("WarmupDays(dmi, 14) = %d", days)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	closes := make([]float64, 120)
+	for i := range closes {
+		closes[i] = 10 + math.Sin(float64(i)/5)
+	}
+	s := series(closes...)
+
+	// 默认参数的结果与预计算保存的指标一致
+	stored := map[string]*models.Indicator{}
+	for _, ind := range Standard("600000", "SH", s, s.Time[119]) {
+		stored[ind.IndicatorType] = ind
+	}
+	for typ, want := range map[string][]float64{
+		"macd": {stored["macd"].MACD, stored["macd"].MACDSignal, stored["macd"].MACDHist},
+		"kdj":  {stored["kdj"].K, stored["kdj"].D, stored["kdj"].J},
+		"boll": {stored["boll"].BollUpper, stored["boll"].BollMid, stored["boll"].BollLower},
+	} {
+		params, _ := DefaultParams(typ)
+		_, values, err := Evaluate(typ, params, s)
+		if err != nil {
+			t.Fatalf("%s: %v", typ, err)
+		}
+		for i, w := range want {
+			if got := values[i][119]; math.Abs(got-w) > 1e-9 {
+				t.Errorf("%s 第 %d 列 = %v，期望 %v", typ, i, got, w)
+			}
+		}
+	}
+
+	names, values, err := Evaluate("ma", []int{3, 7}, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "ma3" || names[1] != "ma7" {
+		t.Errorf("列名 = %v", names)
+	}
+	if want := (closes[117] + closes[118] + closes[119]) / 3; math.Abs(values[0][119]-want) > 1e-9 {
+		t.Errorf("MA3 = %v，期望 %v", values[0][119], want)
+	}
+
+	for _, c := range []struct {
+		typ    string
+		params []int
+	}{
+		{"macd", []int{26, 12, 9}},
+		{"macd", []int{12, 26}},
+		{"ma", nil},
+		{"rsi", []int{6, 0}},
+		{"obv", []int{5}},
+		{"xyz", []int{5}},
+	} {
+		if _, _, err := Evaluate(c.typ, c.params, s); err == nil {
+			t.Errorf("%s%v 应报错", c.typ, c.params)
+		}
+	}
+
+	if got := DescribeParams("macd", []int{12, 26, 9}); got["fast"] != 12 || got["signal"] != 9 {
+		t.Errorf("DescribeParams(macd) = %v", got)
+	}
+	if got := DescribeParams("ma", []int{5, 10}); len(got["periods"].([]int)) != 2 {
+		t.Errorf("DescribeParams(ma) = %v", got)
+	}
+}
+
+func TestDerivedMetrics(t *testing.T) {
+	// 收盘 10、20，最高/最低为收盘 ±1，成交量 100，成交额为收盘 × 100
+	s := series(10, 20)
+
+	got := DerivedMetrics(s, 1000, 8)
+	if got[0].TurnoverRate == nil || *got[0].TurnoverRate != 10 {
+		t.Errorf("换手率应为 10%%，实际 %v", got[0].TurnoverRate)
+	}
+	if got[0].Amplitude == nil || *got[0].Amplitude != 25 {
+		t.Errorf("首根振幅应按前收盘 8 计算为 25%%，实际 %v", got[0].Amplitude)
+	}
+	if got[1].Amplitude == nil || *got[1].Amplitude != 20 {
+		t.Errorf("第二根振幅应为 20%%，实际 %v", got[1].Amplitude)
+	}
+	if got[1].VWAP == nil || *got[1].VWAP != 20 {
+		t.Errorf("成交均价应为 20，实际 %v", got[1].VWAP)
+	}
+
+	got = DerivedMetrics(s, 0, 0)
+	if got[0].TurnoverRate != nil || got[0].Amplitude != nil {
+		t.Errorf("流通股本与前收盘未知时应为空: %+v", got[0])
+	}
+}
+
+func TestIntraday(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	s := &Series{}
+	open := time.Date(2024, 1, 2, 9, 31, 0, 0, loc)
+	s.Append(open, 10, 11, 9, 10, 100, 1000)                   // 典型价 10
+	s.Append(open.Add(time.Minute), 10, 13, 11, 12, 300, 3600) // 典型价 12
+	s.Append(open.AddDate(0, 0, 1), 20, 21, 19, 20, 0, 0)      // 次日重新累计
+
+	vwap := Intraday(TypeVWAP, s, loc)
+	if vwap[0] != 10 || vwap[1] != 11.5 {
+		t.Errorf("vwap = %v, want [10 11.5 NaN]", vwap)
+	}
+	if !math.IsNaN(vwap[2]) {
+		t.Errorf("无成交时 vwap 应为 NaN，实际 %v", vwap[2])
+	}
+	twap := Intraday(TypeTWAP, s, loc)
+	if twap[1] != 11 || twap[2] != 20 {
+		t.Errorf("twap = %v, want [10 11 20]", twap)
+	}
+
+	daily := DailyIntraday(TypeVWAP, s, loc)
+	if len(daily) != 1 || daily["2024-01-02"] != 11.5 {
+		t.Errorf("daily vwap = %v", daily)
+	}
+}
+
+func TestAffectedRange(t *testing.T) {
+	now := time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+	d := func(s string) time.Time {
+		t, _ := time.Parse("2006-01-02", s)
+		return t
+	}
+	cases := []struct {
+		name       string
+		start, end string
+		wantStart  string
+		wantEnd    string
+		ok         bool
+	}{
+		{"增量同步：区间延伸到当前", "2026-10-15", "2026-10-16", "2026-10-15", "2026-10-17", true},
+		{"回补早期历史：只延伸一个预热期", "2015-01-05", "2015-12-31", "2015-01-05", "2016-06-28", true},
+		{"预热期跨过当前时间", "2026-06-01", "2026-06-30", "2026-06-01", "2026-10-17", true},
+		{"未来日期不需要重算", "2026-10-20", "2026-10-21", "", "", false},
+	}
+	for _, c := range cases {
+		start, end, ok := AffectedRange(d(c.start), d(c.end), now)
+		if ok != c.ok {
+			t.Errorf("%s: ok = %v", c.name, ok)
+			continue
+		}
+		if ok && (start.Format("2006-01-02") != c.wantStart || end.Format("2006-01-02") != c.wantEnd) {
+			t.Errorf("%s: 区间 %s ~ %s，期望 %s ~ %s", c.name, start.Format("2006-01-02"), end.Format("2006-01-02"), c.wantStart, c.wantEnd)
+		}
+	}
+}
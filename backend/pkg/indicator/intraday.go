@@ -0,0 +1,66 @@
+package indicator
+
+import (
+	"math"
+	"time"
+)
+
+// ============ 分时均价（VWAP / TWAP） ============
+
+// 分时均价指标类型
+const (
+	TypeVWAP = "vwap" // 成交量加权均价
+	TypeTWAP = "twap" // 时间加权均价
+)
+
+// IsIntraday 指标类型是否为由分钟K线计算的分时均价
+func IsIntraday(indicatorType string) bool {
+	return indicatorType == TypeVWAP || indicatorType == TypeTWAP
+}
+
+// Intraday 由分钟K线计算分时均价，每个交易日（按 loc 时区划分）从开盘重新累计
+// vwap 为当日累计成交额 / 累计成交量，累计成交量为 0 时为 NaN；
+// twap 为当日每根K线典型价 (最高+最低+收盘)/3 的算术平均。
+func Intraday(indicatorType string, s *Series, loc *time.Location) []float64 {
+	out := make([]float64, s.Len())
+	var day string
+	var amount, volume, sum float64
+	var n int
+	for i, t := range s.Time {
+		if d := t.In(loc).Format("2006-01-02"); d != day {
+			day, amount, volume, sum, n = d, 0, 0, 0, 0
+		}
+		switch indicatorType {
+		case TypeVWAP:
+			amount += s.Amount[i]
+			volume += s.Volume[i]
+			out[i] = math.NaN()
+			if volume > 0 {
+				out[i] = amount / volume
+			}
+		case TypeTWAP:
+			sum += (s.High[i] + s.Low[i] + s.Close[i]) / 3
+			n++
+			out[i] = sum / float64(n)
+		default:
+			out[i] = math.NaN()
+		}
+	}
+	return out
+}
+
+// DailyIntraday 全天分时均价：交易日（YYYY-MM-DD）-> 当日最后一根分钟K线的累计值，无法计算的交易日不返回
+// 用作回测的成交基准价。
+func DailyIntraday(indicatorType string, s *Series, loc *time.Location) map[string]float64 {
+	values := Intraday(indicatorType, s, loc)
+	out := make(map[string]float64)
+	for i, t := range s.Time {
+		day := t.In(loc).Format("2006-01-02")
+		if valid(values[i]) {
+			out[day] = values[i]
+		} else {
+			delete(out, day)
+		}
+	}
+	return out
+}
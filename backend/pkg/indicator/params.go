@@ -0,0 +1,214 @@
+package indicator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============ 指标参数 ============
+
+// maxParam 指标周期参数上限
+const maxParam = 250
+
+// paramSpec 指标类型的参数规格
+// names 为空时参数是任意个周期的列表（如 MA 的 5,10,20,60），否则按位置一一对应；
+// columns 按参数生成各列的名称与表达式。
+type paramSpec struct {
+	defaults []int
+	names    []string
+	maxCount int
+	columns  func(p []int) [][2]string
+}
+
+// rsv KDJ 的未成熟随机值
+const rsv = "(CLOSE - LLV(LOW, %[1]d)) / (HHV(HIGH, %[1]d) - LLV(LOW, %[1]d)) * 100"
+
+// paramSpecs 各内置指标类型的参数规格，默认参数与 Standard 保存的预计算结果一致
+var paramSpecs = map[string]paramSpec{
+	"ma": {defaults: []int{5, 10, 20, 60}, maxCount: 6, columns: func(p []int) [][2]string {
+		return each(p, "ma%d", "MA(%d)")
+	}},
+	"rsi": {defaults: []int{6, 12, 24}, maxCount: 6, columns: func(p []int) [][2]string {
+		return each(p, "rsi%d", "RSI(%d)")
+	}},
+	"macd": {defaults: []int{12, 26, 9}, names: []string{"fast", "slow", "signal"}, columns: func(p []int) [][2]string {
+		dif := fmt.Sprintf("EMA(%d) - EMA(%d)", p[0], p[1])
+		dea := fmt.Sprintf("EMA(%s, %d)", dif, p[2])
+		return [][2]string{{"macd", dif}, {"signal", dea}, {"hist", fmt.Sprintf("2 * (%s - %s)", dif, dea)}}
+	}},
+	// SMA(x, m, 1) 的平滑系数 1/m 与 EMA(x, 2m-1) 相同
+	"kdj": {defaults: []int{9, 3, 3}, names: []string{"n", "m1", "m2"}, columns: func(p []int) [][2]string {
+		k := fmt.Sprintf("EMA("+rsv+", %[2]d)", p[0], 2*p[1]-1)
+		d := fmt.Sprintf("EMA(%s, %d)", k, 2*p[2]-1)
+		return [][2]string{{"k", k}, {"d", d}, {"j", fmt.Sprintf("3 * %s - 2 * %s", k, d)}}
+	}},
+	"boll": {defaults: []int{20}, names: []string{"n"}, columns: func(p []int) [][2]string {
+		return [][2]string{
+			{"upper", fmt.Sprintf("MA(%[1]d) + 2 * STD(%[1]d)", p[0])},
+			{"mid", fmt.Sprintf("MA(%d)", p[0])},
+			{"lower", fmt.Sprintf("MA(%[1]d) - 2 * STD(%[1]d)", p[0])},
+		}
+	}},
+	"atr": {defaults: []int{14}, names: []string{"n"}, columns: func(p []int) [][2]string {
+		return each(p, "atr", "ATR(%d)")
+	}},
+	"cci": {defaults: []int{14}, names: []string{"n"}, columns: func(p []int) [][2]string {
+		return each(p, "cci", "((HIGH + LOW + CLOSE) / 3 - MA((HIGH + LOW + CLOSE) / 3, %[1]d)) / (0.015 * AVEDEV((HIGH + LOW + CLOSE) / 3, %[1]d))")
+	}},
+	"obv": {columns: func([]int) [][2]string {
+		return [][2]string{{"obv", "OBV()"}}
+	}},
+	"dmi": {defaults: []int{14}, names: []string{"n"}, columns: func(p []int) [][2]string {
+		return [][2]string{
+			{"pdi", fmt.Sprintf("PDI(%d)", p[0])},
+			{"mdi", fmt.Sprintf("MDI(%d)", p[0])},
+			{"adx", fmt.Sprintf("ADX(%d)", p[0])},
+		}
+	}},
+	"wr": {defaults: []int{14}, names: []string{"n"}, columns: func(p []int) [][2]string {
+		return each(p, "wr", "(HHV(HIGH, %[1]d) - CLOSE) / (HHV(HIGH, %[1]d) - LLV(LOW, %[1]d)) * 100")
+	}},
+	"bias": {defaults: []int{6}, names: []string{"n"}, columns: func(p []int) [][2]string {
+		return each(p, "bias", "(CLOSE - MA(%[1]d)) / MA(%[1]d) * 100")
+	}},
+}
+
+// each 每个周期一列；列名不含周期占位符时（单周期类型）直接使用
+func each(p []int, name, expr string) [][2]string {
+	out := make([][2]string, len(p))
+	for i, n := range p {
+		col := name
+		if strings.Contains(name, "%") {
+			col = fmt.Sprintf(name, n)
+		}
+		out[i] = [2]string{col, fmt.Sprintf(expr, n)}
+	}
+	return out
+}
+
+// DefaultParams 指标类型的默认参数（即预计算保存的参数），类型不支持时 ok 为 false；obv 没有参数
+func DefaultParams(indicatorType string) (params []int, ok bool) {
+	spec, ok := paramSpecs[indicatorType]
+	return spec.defaults, ok
+}
+
+// SameParams 两组参数是否相同
+func SameParams(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// DescribeParams 参数的名称与取值，用于响应元数据：按位置的参数以名称为键，周期列表为 periods
+func DescribeParams(indicatorType string, params []int) map[string]interface{} {
+	spec := paramSpecs[indicatorType]
+	out := make(map[string]interface{}, len(params))
+	if spec.names == nil {
+		if len(params) > 0 {
+			out["periods"] = params
+		}
+		return out
+	}
+	for i, name := range spec.names {
+		if i < len(params) {
+			out[name] = params[i]
+		}
+	}
+	return out
+}
+
+// validateParams 校验参数个数与取值
+func validateParams(indicatorType string, spec paramSpec, params []int) error {
+	switch {
+	case spec.defaults == nil:
+		if len(params) > 0 {
+			return fmt.Errorf("%s 没有周期参数", indicatorType)
+		}
+		return nil
+	case spec.names != nil && len(params) != len(spec.names):
+		return fmt.Errorf("%s 需要 %d 个参数: %s", indicatorType, len(spec.names), strings.Join(spec.names, ","))
+	case spec.names == nil && (len(params) < 1 || len(params) > spec.maxCount):
+		return fmt.Errorf("%s 的周期个数应在 1-%d 之间", indicatorType, spec.maxCount)
+	}
+	for _, p := range params {
+		if p < 1 || p > maxParam {
+			return fmt.Errorf("%s 的参数应在 1-%d 之间", indicatorType, maxParam)
+		}
+	}
+	if indicatorType == "macd" && params[0] >= params[1] {
+		return fmt.Errorf("macd 的快线周期应小于慢线周期")
+	}
+	return nil
+}
+
+// column 编译后的指标列
+type column struct {
+	name string
+	expr *Expr
+}
+
+// compileParams 校验参数并编译指标类型的各列
+func compileParams(indicatorType string, params []int) ([]column, error) {
+	spec, ok := paramSpecs[indicatorType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的指标类型: %s", indicatorType)
+	}
+	if err := validateParams(indicatorType, spec, params); err != nil {
+		return nil, err
+	}
+	var out []column
+	for _, c := range spec.columns(params) {
+		expr, err := Compile(c[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s 表达式错误: %w", indicatorType, err)
+		}
+		out = append(out, column{name: c[0], expr: expr})
+	}
+	return out, nil
+}
+
+// Columns 校验参数并返回指标类型在该参数下的列名
+func Columns(indicatorType string, params []int) ([]string, error) {
+	columns, err := compileParams(indicatorType, params)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names, nil
+}
+
+// WarmupDays 按指定参数计算指标需要向前多取的日历天数，参数无效时返回 StandardWarmupDays
+func WarmupDays(indicatorType string, params []int) int {
+	columns, err := compileParams(indicatorType, params)
+	if err != nil {
+		return StandardWarmupDays
+	}
+	lookback := 0
+	for _, c := range columns {
+		lookback = max(lookback, c.expr.Lookback())
+	}
+	// 交易日约为自然日的 2/3，按 1.5 倍换算并留出节假日余量
+	return lookback*3/2 + 10
+}
+
+// Evaluate 按指定参数计算指标的各列，返回列名及与序列等长的列值，数据不足处为 NaN
+func Evaluate(indicatorType string, params []int, s *Series) (names []string, values [][]float64, err error) {
+	columns, err := compileParams(indicatorType, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range columns {
+		names = append(names, c.name)
+		values = append(values, c.expr.Eval(s))
+	}
+	return names, values, nil
+}
@@ -0,0 +1,172 @@
+package indicator
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 内置技术指标 ============
+
+// StandardWarmupDays 计算内置指标需要向前多取的日历天数（覆盖 MA60 与 MACD 慢线的收敛）
+const StandardWarmupDays = 180
+
+// AffectedRange 写入或修正 [start, end] 的日K线后需要重算的内置指标区间
+// 滚动指标（均线、MACD 等）在 end 之后一个预热期内的值也依赖这些K线；区间不超过 now，start 晚于 now 时返回 false。
+func AffectedRange(start, end, now time.Time) (time.Time, time.Time, bool) {
+	if start.After(now) {
+		return start, end, false
+	}
+	end = end.AddDate(0, 0, StandardWarmupDays)
+	if end.After(now) {
+		end = now
+	}
+	return start, end, true
+}
+
+// 内置指标的表达式，与 MarketRepository.SaveIndicator 保存的字段对应
+// KDJ 的 SMA(x,3,1) 平滑系数为 1/3，与 EMA(x,5) 相同。
+var standardExprs = map[string]string{
+	"ma5":        "MA(5)",
+	"ma10":       "MA(10)",
+	"ma20":       "MA(20)",
+	"ma60":       "MA(60)",
+	"dif":        "EMA(12) - EMA(26)",
+	"dea":        "EMA(EMA(12) - EMA(26), 9)",
+	"rsi6":       "RSI(6)",
+	"rsi12":      "RSI(12)",
+	"rsi24":      "RSI(24)",
+	"k":          "EMA((CLOSE - LLV(LOW, 9)) / (HHV(HIGH, 9) - LLV(LOW, 9)) * 100, 5)",
+	"d":          "EMA(EMA((CLOSE - LLV(LOW, 9)) / (HHV(HIGH, 9) - LLV(LOW, 9)) * 100, 5), 5)",
+	"boll_mid":   "MA(20)",
+	"boll_upper": "MA(20) + 2 * STD(20)",
+	"boll_lower": "MA(20) - 2 * STD(20)",
+}
+
+// compiledStandard 编译后的内置指标表达式
+var compiledStandard = func() map[string]*Expr {
+	out := make(map[string]*Expr, len(standardExprs))
+	for name, source := range standardExprs {
+		expr, err := Compile(source)
+		if err != nil {
+			panic("内置指标表达式错误 " + name + ": " + err.Error())
+		}
+		out[name] = expr
+	}
+	return out
+}()
+
+// Standard 按日K线序列计算全部内置指标，只返回日期不早于 from 的结果
+// MA/MACD/RSI/KDJ/BOLL 五类使用固定参数，PeriodTypes 按各自的默认参数计算；
+// 每根K线每类指标一条记录；数据不足（预热期内）的指标类型跳过。
+func Standard(symbol, exchange string, s *Series, from time.Time) []*models.Indicator {
+	values := make(map[string][]float64, len(compiledStandard))
+	for name, expr := range compiledStandard {
+		values[name] = expr.Eval(s)
+	}
+
+	var out []*models.Indicator
+	for i, t := range s.Time {
+		if t.Before(from) {
+			continue
+		}
+		v := func(name string) float64 { return values[name][i] }
+		add := func(indicatorType string, ready bool, fill func(ind *models.Indicator)) {
+			if !ready {
+				return
+			}
+			ind := &models.Indicator{Symbol: symbol, Exchange: exchange, Date: t, IndicatorType: indicatorType}
+			fill(ind)
+			out = append(out, ind)
+		}
+
+		add("ma", valid(v("ma60")), func(ind *models.Indicator) {
+			ind.MA5, ind.MA10, ind.MA20, ind.MA60 = v("ma5"), v("ma10"), v("ma20"), v("ma60")
+		})
+		add("macd", valid(v("dea")), func(ind *models.Indicator) {
+			ind.MACD, ind.MACDSignal = v("dif"), v("dea")
+			ind.MACDHist = 2 * (ind.MACD - ind.MACDSignal)
+		})
+		add("rsi", valid(v("rsi24")), func(ind *models.Indicator) {
+			ind.RSI6, ind.RSI12, ind.RSI24 = v("rsi6"), v("rsi12"), v("rsi24")
+		})
+		add("kdj", valid(v("d")), func(ind *models.Indicator) {
+			ind.K, ind.D = v("k"), v("d")
+			ind.J = 3*ind.K - 2*ind.D
+		})
+		add("boll", valid(v("boll_upper")), func(ind *models.Indicator) {
+			ind.BollUpper, ind.BollMid, ind.BollLower = v("boll_upper"), v("boll_mid"), v("boll_lower")
+		})
+	}
+
+	for _, t := range PeriodTypes {
+		params, _ := DefaultParams(t)
+		extra, _ := Compute(symbol, exchange, t, params, s, from)
+		out = append(out, extra...)
+	}
+	return out
+}
+
+// ============ 带周期参数的内置指标 ============
+
+// PeriodTypes 只有一个周期参数（obv 没有参数）的指标类型，按默认参数计算的结果随内置指标一起保存，Period 字段记录周期
+var PeriodTypes = []string{"atr", "bias", "cci", "dmi", "obv", "wr"}
+
+// Compute 按指定参数计算 PeriodTypes 中的一种指标，只返回日期不早于 from 且各字段均有值的结果
+// OBV 从序列第一根K线起累计，数值取决于序列起点，宜看变化趋势而非绝对值。
+func Compute(symbol, exchange, indicatorType string, params []int, s *Series, from time.Time) ([]*models.Indicator, error) {
+	if !slices.Contains(PeriodTypes, indicatorType) {
+		return nil, fmt.Errorf("%s 不是单周期指标类型", indicatorType)
+	}
+	names, columns, err := Evaluate(indicatorType, params, s)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]float64, len(names))
+	for i, name := range names {
+		values[name] = columns[i]
+	}
+	period := 0
+	if len(params) == 1 {
+		period = params[0]
+	}
+
+	var out []*models.Indicator
+	for i, t := range s.Time {
+		if t.Before(from) {
+			continue
+		}
+		ready := true
+		for _, v := range values {
+			ready = ready && valid(v[i])
+		}
+		if !ready {
+			continue
+		}
+		ind := &models.Indicator{Symbol: symbol, Exchange: exchange, Date: t, IndicatorType: indicatorType, Period: period}
+		v := func(name string) float64 { return values[name][i] }
+		switch indicatorType {
+		case "atr":
+			ind.ATR = v("atr")
+		case "cci":
+			ind.CCI = v("cci")
+		case "obv":
+			ind.OBV = v("obv")
+		case "dmi":
+			ind.PDI, ind.MDI, ind.ADX = v("pdi"), v("mdi"), v("adx")
+		case "wr":
+			ind.WR = v("wr")
+		case "bias":
+			ind.BIAS = v("bias")
+		}
+		out = append(out, ind)
+	}
+	return out, nil
+}
+
+func valid(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
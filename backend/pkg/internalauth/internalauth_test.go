@@ -0,0 +1,51 @@
+package internalauth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSignVerify(t *testing.T) {
+	signer := NewSigner("new-secret")
+	v := NewVerifier([]string{"new-secret", "old-secret"}, 30*time.Second)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/market/stocks?page=2", nil)
+	signer.Sign(req)
+	now := time.Now()
+	if err := v.Verify(req, now); err != nil {
+		t.Fatalf("签名应通过校验: %v", err)
+	}
+	if err := v.Verify(req, now); !errors.Is(err, ErrReplayed) {
+		t.Errorf("重复使用随机数: err = %v", err)
+	}
+
+	// 轮换前的密钥仍可校验
+	old := httptest.NewRequest(http.MethodPost, "/api/v1/strategy", nil)
+	NewSigner("old-secret").Sign(old)
+	if err := v.Verify(old, now); err != nil {
+		t.Errorf("旧密钥: err = %v", err)
+	}
+
+	tampered := httptest.NewRequest(http.MethodGet, "/api/v1/market/stocks?page=2", nil)
+	signer.Sign(tampered)
+	tampered.RequestURI = "/api/v1/user/profile"
+	if err := v.Verify(tampered, now); !errors.Is(err, ErrSignature) {
+		t.Errorf("URI 不一致: err = %v", err)
+	}
+
+	expired := httptest.NewRequest(http.MethodGet, "/api/v1/market/stocks", nil)
+	signer.Sign(expired)
+	if err := v.Verify(expired, now.Add(time.Minute)); !errors.Is(err, ErrExpired) {
+		t.Errorf("过期: err = %v", err)
+	}
+
+	if err := v.Verify(httptest.NewRequest(http.MethodGet, "/", nil), now); !errors.Is(err, ErrMissing) {
+		t.Errorf("未签名: err = %v", err)
+	}
+
+	var none *Signer = NewSigner("")
+	none.Sign(req) // 未配置密钥时不签名也不报错
+}
@@ -0,0 +1,243 @@
+// Package jobs 异步任务登记：数据同步、回测等长时间运行的操作在开始时登记任务，
+// 执行中上报进度，结束时记录结果所在的资源（如回测记录ID）或错误，客户端统一通过 GET /api/v1/tasks/:id 查询。
+// 任务状态只用于展示，写入失败时只记录日志，不影响任务本身的执行。
+package jobs
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// progressInterval 两次写入进度的最短间隔，避免逐根K线等高频回调频繁写库
+const progressInterval = time.Second
+
+// Store 任务状态存储，由 repository.TaskRepository 实现
+type Store interface {
+	CreateTask(ctx context.Context, task *models.Task) error
+	UpdateTask(ctx context.Context, task *models.Task) error
+}
+
+// Tracker 登记一个服务发起的任务，nil 表示不登记
+type Tracker struct {
+	store   Store
+	service string
+	now     func() time.Time
+}
+
+// NewTracker 创建任务登记，service 为执行任务的服务名
+func NewTracker(store Store, service string) *Tracker {
+	return &Tracker{store: store, service: service, now: time.Now}
+}
+
+// Spec 新任务的描述
+type Spec struct {
+	ID      string // 为空时生成 UUID；已有任务ID（如回测 job_id）时沿用，便于两种接口互查
+	Type    string // models.TaskType*
+	Name    string
+	OwnerID uint // 0 表示系统任务
+}
+
+// Task 执行中的任务，方法均可在 nil 上调用（登记失败或未启用时）
+type Task struct {
+	tracker *Tracker
+
+	mu        sync.Mutex
+	model     models.Task
+	lastWrite time.Time
+}
+
+// Start 登记任务并标记为运行中，登记失败时返回 nil
+func (t *Tracker) Start(ctx context.Context, spec Spec) *Task {
+	if t == nil {
+		return nil
+	}
+	id := spec.ID
+	if id == "" {
+		id = uuid.New().String()
+	}
+	now := t.now()
+	task := &Task{tracker: t, lastWrite: now, model: models.Task{
+		ID:        id,
+		Type:      spec.Type,
+		Name:      spec.Name,
+		Service:   t.service,
+		OwnerID:   spec.OwnerID,
+		Status:    models.TaskStatusRunning,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+	if err := t.store.CreateTask(ctx, &task.model); err != nil {
+		log.Printf("登记任务 %s/%s 失败: %v", spec.Type, spec.Name, err)
+		return nil
+	}
+	return task
+}
+
+// ID 任务ID，nil 时为空
+func (t *Task) ID() string {
+	if t == nil {
+		return ""
+	}
+	return t.model.ID
+}
+
+// Progress 上报进度（0-100）与当前阶段说明
+// 取整后的进度与说明都未变化，或距上次写入不足 progressInterval 时不写入。
+func (t *Task) Progress(percent float64, message string) {
+	if t == nil {
+		return
+	}
+	percent = math.Max(0, math.Min(percent, 100))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.model.Finished() {
+		return
+	}
+	now := t.tracker.now()
+	unchanged := math.Floor(percent) == math.Floor(t.model.Progress) && message == t.model.Message
+	if unchanged || now.Sub(t.lastWrite) < progressInterval {
+		return
+	}
+	t.model.Progress = percent
+	t.model.Message = message
+	t.save(now)
+}
+
+// Succeed 标记任务成功，resultType/resultID 指向任务产出的资源（如 backtest 与回测记录ID）
+func (t *Task) Succeed(resultType, resultID string) {
+	t.SucceedWithMessage("", resultType, resultID)
+}
+
+// SucceedWithMessage 标记任务成功并以 message 记录结果摘要（如写入条数）
+func (t *Task) SucceedWithMessage(message, resultType, resultID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.model.Status = models.TaskStatusSucceeded
+	t.model.Progress = 100
+	t.model.Message = message
+	t.setResult(resultType, resultID)
+	t.finish()
+}
+
+// Fail 标记任务失败，resultType/resultID 可指向记录了失败明细的资源，没有时传空（沿用 SetResult 记录的结果）
+// err 为 context.Canceled 时标记为已取消。
+func (t *Task) Fail(err error, resultType, resultID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.model.Status = models.TaskStatusFailed
+	if errors.Is(err, context.Canceled) {
+		t.model.Status = models.TaskStatusCanceled
+	}
+	if err != nil {
+		t.model.Error = err.Error()
+	}
+	t.setResult(resultType, resultID)
+	t.finish()
+}
+
+// SetResult 在任务执行中记录产出的资源（如后台同步生成的同步任务记录），结束时一并写入
+// 结束时 Succeed/Fail 传空的 resultType 则沿用此处记录的结果。
+func (t *Task) SetResult(resultType, resultID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setResult(resultType, resultID)
+}
+
+// setResult 非空时更新任务结果，调用方需持有 mu
+func (t *Task) setResult(resultType, resultID string) {
+	if resultType != "" {
+		t.model.ResultType, t.model.ResultID = resultType, resultID
+	}
+}
+
+// finish 记录结束时间并写入，调用方需持有 mu
+func (t *Task) finish() {
+	now := t.tracker.now()
+	t.model.FinishedAt = &now
+	t.save(now)
+}
+
+// save 写入当前状态，调用方需持有 mu
+// 使用独立的 context，保证请求被取消时任务状态仍能落库。
+func (t *Task) save(now time.Time) {
+	t.model.UpdatedAt = now
+	t.lastWrite = now
+	if err := t.tracker.store.UpdateTask(context.Background(), &t.model); err != nil {
+		log.Printf("更新任务 %s 状态失败: %v", t.model.ID, err)
+	}
+}
+
+type taskKey struct{}
+
+// WithTask 将任务放入 context，任务内部调用的同步等操作据此上报进度，不再单独登记任务
+func WithTask(ctx context.Context, t *Task) context.Context {
+	return context.WithValue(ctx, taskKey{}, t)
+}
+
+// FromContext 取出 WithTask 放入的任务，没有时返回 nil
+func FromContext(ctx context.Context) *Task {
+	t, _ := ctx.Value(taskKey{}).(*Task)
+	return t
+}
+
+// Registry 本实例后台运行中的任务，用于按任务ID取消
+// 任务只能在执行它的实例上取消，多实例部署时取消请求需到达该实例。
+type Registry struct {
+	mu      sync.Mutex
+	cancels map[string]context.CancelFunc
+}
+
+// NewRegistry 创建任务取消登记
+func NewRegistry() *Registry {
+	return &Registry{cancels: make(map[string]context.CancelFunc)}
+}
+
+// Go 以 parent 派生的 context 在后台执行 fn，与发起任务的请求无关；执行期间可通过 Cancel(id) 取消
+// id 为空（任务登记失败）时仍执行，但无法取消。
+func (r *Registry) Go(parent context.Context, id string, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(parent)
+	if id != "" {
+		r.mu.Lock()
+		r.cancels[id] = cancel
+		r.mu.Unlock()
+	}
+	go func() {
+		defer func() {
+			if id != "" {
+				r.mu.Lock()
+				delete(r.cancels, id)
+				r.mu.Unlock()
+			}
+			cancel()
+		}()
+		fn(ctx)
+	}()
+}
+
+// Cancel 取消本实例运行中的任务，任务不存在、已结束或不在本实例运行时返回 false
+func (r *Registry) Cancel(id string) bool {
+	r.mu.Lock()
+	cancel, ok := r.cancels[id]
+	r.mu.Unlock()
+	if ok {
+		cancel()
+	}
+	return ok
+}
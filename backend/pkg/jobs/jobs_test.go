@@ -0,0 +1,144 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+type memoryStore struct {
+	tasks   map[string]models.Task
+	updates int
+	fail    bool
+}
+
+func (m *memoryStore) CreateTask(_ context.Context, task *models.Task) error {
+	if m.fail {
+		return errors.New("db down")
+	}
+	m.tasks[task.ID] = *task
+	return nil
+}
+
+func (m *memoryStore) UpdateTask(_ context.Context, task *models.Task) error {
+	m.updates++
+	m.tasks[task.ID] = *task
+	return nil
+}
+
+func TestTaskLifecycle(t *testing.T) {
+	store := &memoryStore{tasks: map[string]models.Task{}}
+	tracker := NewTracker(store, "backtest-service")
+	now := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	task := tracker.Start(context.Background(), Spec{ID: "job-1", Type: models.TaskTypeBacktest, OwnerID: 7})
+	if task.ID() != "job-1" || store.tasks["job-1"].Status != models.TaskStatusRunning {
+		t.Fatalf("登记的任务 = %+v", store.tasks["job-1"])
+	}
+
+	// 间隔不足或取整后进度未变化时不写入
+	task.Progress(10.2, "")
+	if store.updates != 0 {
+		t.Fatal("间隔不足时不应写入进度")
+	}
+	now = now.Add(2 * time.Second)
+	task.Progress(10.2, "")
+	now = now.Add(2 * time.Second)
+	task.Progress(10.8, "")
+	if store.updates != 1 || store.tasks["job-1"].Progress != 10.2 {
+		t.Fatalf("updates = %d, progress = %v", store.updates, store.tasks["job-1"].Progress)
+	}
+
+	task.Succeed("backtest", "12")
+	got := store.tasks["job-1"]
+	if got.Status != models.TaskStatusSucceeded || got.Progress != 100 || got.ResultID != "12" || got.FinishedAt == nil {
+		t.Fatalf("完成后的任务 = %+v", got)
+	}
+
+	// 结束后的进度上报被忽略
+	now = now.Add(time.Minute)
+	task.Progress(50, "late")
+	if store.tasks["job-1"].Progress != 100 {
+		t.Error("结束后不应再更新进度")
+	}
+}
+
+func TestTaskFail(t *testing.T) {
+	store := &memoryStore{tasks: map[string]models.Task{}}
+	task := NewTracker(store, "data-service").Start(context.Background(), Spec{Type: models.TaskTypeSync, Name: "daily_bars"})
+	if task.ID() == "" {
+		t.Fatal("未指定ID时应生成")
+	}
+	task.Fail(errors.New("数据源超时"), "sync_job", "3")
+	got := store.tasks[task.ID()]
+	if got.Status != models.TaskStatusFailed || got.Error != "数据源超时" || got.ResultType != "sync_job" {
+		t.Fatalf("失败的任务 = %+v", got)
+	}
+}
+
+func TestTaskSetResult(t *testing.T) {
+	store := &memoryStore{tasks: map[string]models.Task{}}
+	task := NewTracker(store, "data-service").Start(context.Background(), Spec{Type: models.TaskTypeSync, Name: "incremental"})
+	task.SetResult("sync_job", "9")
+	if store.updates != 0 {
+		t.Fatal("SetResult 不应单独写入")
+	}
+	task.SucceedWithMessage("成功 2 只，失败 1 只（共 3 只），写入 10 条", "", "")
+	got := store.tasks[task.ID()]
+	if got.Status != models.TaskStatusSucceeded || got.ResultType != "sync_job" || got.ResultID != "9" {
+		t.Fatalf("完成后的任务 = %+v", got)
+	}
+}
+
+func TestTaskNilSafe(t *testing.T) {
+	store := &memoryStore{tasks: map[string]models.Task{}, fail: true}
+	task := NewTracker(store, "data-service").Start(context.Background(), Spec{Type: models.TaskTypeSync})
+	if task != nil {
+		t.Fatal("登记失败时应返回 nil")
+	}
+	var tracker *Tracker
+	task = tracker.Start(context.Background(), Spec{Type: models.TaskTypeSync})
+	task.Progress(50, "")
+	task.Succeed("", "")
+	task.Fail(errors.New("x"), "", "")
+	if task.ID() != "" {
+		t.Error("nil 任务的ID应为空")
+	}
+}
+
+func TestRegistryCancel(t *testing.T) {
+	store := &memoryStore{tasks: map[string]models.Task{}}
+	task := NewTracker(store, "data-service").Start(context.Background(), Spec{Type: models.TaskTypeSync, Name: "incremental"})
+	registry := NewRegistry()
+
+	started, done := make(chan struct{}), make(chan struct{})
+	registry.Go(context.Background(), task.ID(), func(ctx context.Context) {
+		defer close(done)
+		if FromContext(WithTask(ctx, task)) != task {
+			t.Error("context 中应能取出任务")
+		}
+		close(started)
+		<-ctx.Done()
+		task.Fail(ctx.Err(), "", "")
+	})
+	<-started
+	if !registry.Cancel(task.ID()) {
+		t.Fatal("运行中的任务应能取消")
+	}
+	<-done
+	if got := store.tasks[task.ID()]; got.Status != models.TaskStatusCanceled || !got.Finished() {
+		t.Errorf("取消后的任务 = %+v", got)
+	}
+
+	// 任务结束后注销，不能再取消
+	for deadline := time.Now().Add(time.Second); registry.Cancel(task.ID()) && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	if registry.Cancel(task.ID()) || registry.Cancel("missing") {
+		t.Error("已结束或不存在的任务不应能取消")
+	}
+}
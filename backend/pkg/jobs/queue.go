@@ -0,0 +1,145 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Dependent 排队的下游任务，如日K线同步写入后需要重算的技术指标
+type Dependent struct {
+	ID       uint   // 保存的排队记录（状态为 queued 的同步任务），未保存时为 0
+	Type     string // 与执行它的同步任务的 job_type 相同
+	Symbol   string // 为空表示全市场
+	Exchange string
+	ParentID *uint     // 登记它的上游同步任务，合并后为最近一次登记的上游
+	Start    time.Time // 需要重算的区间，合并时取并集
+	End      time.Time
+}
+
+// key 同一类型、同一股票的下游任务合并为一个
+func (d *Dependent) key() string {
+	return d.Type + ":" + d.Symbol + "." + d.Exchange
+}
+
+// DependentStore 保存排队的下游任务：登记后即出现在同步任务记录中，进程重启后以 Restore 重新排队
+type DependentStore interface {
+	// Save 保存登记或合并后的任务：d.ID 为 0 时新建记录并设置 d.ID，否则更新区间与上游
+	Save(ctx context.Context, d *Dependent) error
+}
+
+// Queue 下游任务队列：上游任务登记后立即返回，下游任务在后台按登记顺序逐个执行
+// 同一下游任务（类型与股票相同）尚未开始执行时再次登记只合并区间，全市场同步时每只股票只执行一次。
+// 配置 DependentStore 时每次登记与合并都会保存，保存失败只打印日志，任务仍在内存中排队；未配置时进程退出后尚未执行的任务丢失。
+type Queue struct {
+	run   func(ctx context.Context, d Dependent)
+	store DependentStore
+
+	mu      sync.Mutex
+	pending map[string]*Dependent // 可合并的任务，同一 key 有多个（如重新排队的记录）时为最后一个
+	order   []*Dependent
+	wake    chan struct{}
+}
+
+// NewQueue 创建下游任务队列，run 执行一个下游任务，store 为 nil 时只保存在内存中；Run 启动后才开始执行
+func NewQueue(run func(ctx context.Context, d Dependent), store DependentStore) *Queue {
+	return &Queue{
+		run:     run,
+		store:   store,
+		pending: make(map[string]*Dependent),
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// Enqueue 登记下游任务，与尚未执行的同一任务合并
+func (q *Queue) Enqueue(d Dependent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := d.key()
+	p, ok := q.pending[key]
+	if ok {
+		if d.Start.Before(p.Start) {
+			p.Start = d.Start
+		}
+		if d.End.After(p.End) {
+			p.End = d.End
+		}
+		if d.ParentID != nil {
+			p.ParentID = d.ParentID
+		}
+	} else {
+		p = &d
+		q.pending[key] = p
+		q.order = append(q.order, p)
+	}
+	// 持有锁保存，执行前一定已拿到记录ID，合并结果按登记顺序写入
+	if q.store != nil {
+		if err := q.store.Save(context.Background(), p); err != nil {
+			log.Printf("保存下游任务 %s（%s.%s）失败: %v", p.Type, p.Symbol, p.Exchange, err)
+		}
+	}
+	q.notify()
+}
+
+// Restore 重新排队已保存、尚未执行的下游任务（如进程退出前登记的），按给定顺序排在队尾，不再保存
+// 之后登记的同一任务合并到其中最后一个。
+func (q *Queue) Restore(ds []Dependent) {
+	if len(ds) == 0 {
+		return
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i := range ds {
+		d := ds[i]
+		q.pending[d.key()] = &d
+		q.order = append(q.order, &d)
+	}
+	q.notify()
+}
+
+// notify 唤醒 Run，调用方持有锁
+func (q *Queue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Pending 尚未开始执行的下游任务数
+func (q *Queue) Pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.order)
+}
+
+// Run 逐个执行下游任务直到 ctx 取消，执行中的任务收到同一个 ctx；取消后剩余的任务不再执行
+func (q *Queue) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		if d, ok := q.next(); ok {
+			q.run(ctx, d)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		}
+	}
+}
+
+// next 取出最早登记的下游任务
+func (q *Queue) next() (Dependent, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.order) == 0 {
+		return Dependent{}, false
+	}
+	d := q.order[0]
+	q.order = q.order[1:]
+	if key := d.key(); q.pending[key] == d {
+		delete(q.pending, key)
+	}
+	return *d, true
+}
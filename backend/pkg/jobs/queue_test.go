@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func day(d int) time.Time {
+	return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestQueueMergesPending(t *testing.T) {
+	var ran []Dependent
+	q := NewQueue(func(_ context.Context, d Dependent) { ran = append(ran, d) }, nil)
+
+	sync1, sync2, sync3 := uint(1), uint(2), uint(3)
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "600519", Exchange: "SH", ParentID: &sync1, Start: day(10), End: day(12)})
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "000001", Exchange: "SZ", ParentID: &sync2, Start: day(11), End: day(11)})
+	// 同一股票尚未执行时合并区间，上游为最近一次登记的同步任务
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "600519", Exchange: "SH", ParentID: &sync3, Start: day(5), End: day(8)})
+	if n := q.Pending(); n != 2 {
+		t.Fatalf("Pending = %d，期望 2", n)
+	}
+
+	for {
+		d, ok := q.next()
+		if !ok {
+			break
+		}
+		q.run(context.Background(), d)
+	}
+	if len(ran) != 2 {
+		t.Fatalf("执行了 %d 个任务，期望 2", len(ran))
+	}
+	first := ran[0]
+	if first.Symbol != "600519" || !first.Start.Equal(day(5)) || !first.End.Equal(day(12)) || *first.ParentID != 3 {
+		t.Errorf("合并后的任务 = %+v，期望 600519 10-05~10-12 上游 3", first)
+	}
+	if ran[1].Symbol != "000001" || *ran[1].ParentID != 2 {
+		t.Errorf("第二个任务 = %+v", ran[1])
+	}
+}
+
+// 上游登记后立即返回，下游在 Run 中按登记顺序执行；执行开始后再登记的同一任务重新排队
+func TestQueueRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan Dependent)
+	release := make(chan struct{})
+	q := NewQueue(func(_ context.Context, d Dependent) {
+		started <- d
+		<-release
+	}, nil)
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "600519", Exchange: "SH", Start: day(10), End: day(12)})
+	if d := <-started; d.Symbol != "600519" {
+		t.Fatalf("执行的任务 = %+v", d)
+	}
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "600519", Exchange: "SH", Start: day(13), End: day(13)})
+	if n := q.Pending(); n != 1 {
+		t.Fatalf("执行中再次登记应重新排队，Pending = %d", n)
+	}
+	release <- struct{}{}
+	if d := <-started; !d.Start.Equal(day(13)) {
+		t.Errorf("重新排队的任务 = %+v", d)
+	}
+	release <- struct{}{}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("取消后 Run 应返回")
+	}
+}
+
+// queueStore 记录每次保存时的任务
+type queueStore struct {
+	saved  []Dependent
+	nextID uint
+}
+
+func (s *queueStore) Save(_ context.Context, d *Dependent) error {
+	if d.ID == 0 {
+		s.nextID++
+		d.ID = s.nextID
+	}
+	s.saved = append(s.saved, *d)
+	return nil
+}
+
+// 登记时新建记录，合并时更新同一条记录；重新排队的记录不再保存，之后登记的同一任务合并到其中最后一个
+func TestQueueStore(t *testing.T) {
+	var ran []Dependent
+	store := &queueStore{nextID: 100}
+	q := NewQueue(func(_ context.Context, d Dependent) { ran = append(ran, d) }, store)
+
+	q.Restore([]Dependent{
+		{ID: 7, Type: "indicators", Symbol: "600519", Exchange: "SH", Start: day(1), End: day(2)},
+		{ID: 8, Type: "factor_scores", Start: day(3), End: day(3)},
+		{ID: 9, Type: "indicators", Symbol: "600519", Exchange: "SH", Start: day(4), End: day(4)},
+	})
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "000001", Exchange: "SZ", Start: day(10), End: day(11)})
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "000001", Exchange: "SZ", Start: day(9), End: day(9)})
+	q.Enqueue(Dependent{Type: "indicators", Symbol: "600519", Exchange: "SH", Start: day(5), End: day(6)})
+
+	if len(store.saved) != 3 {
+		t.Fatalf("保存了 %d 次，期望 3 次: %+v", len(store.saved), store.saved)
+	}
+	if a, b := store.saved[0], store.saved[1]; a.ID != 101 || b.ID != 101 || !b.Start.Equal(day(9)) || !b.End.Equal(day(11)) {
+		t.Errorf("合并后应更新同一条记录: %+v, %+v", a, b)
+	}
+	if c := store.saved[2]; c.ID != 9 || !c.Start.Equal(day(4)) || !c.End.Equal(day(6)) {
+		t.Errorf("应合并到最后一个重新排队的记录: %+v", c)
+	}
+
+	for {
+		d, ok := q.next()
+		if !ok {
+			break
+		}
+		q.run(context.Background(), d)
+	}
+	var ids []uint
+	for _, d := range ran {
+		ids = append(ids, d.ID)
+	}
+	if fmt.Sprint(ids) != "[7 8 9 101]" {
+		t.Errorf("执行顺序 %v，期望 [7 8 9 101]", ids)
+	}
+	if n := q.Pending(); n != 0 {
+		t.Errorf("Pending = %d", n)
+	}
+}
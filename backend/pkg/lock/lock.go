@@ -0,0 +1,302 @@
+// Package lock 基于 Redis 的分布式锁与主节点选举，用于服务水平扩展后只在一个实例上执行定时任务。
+// 锁带 TTL，持有期间后台按 TTL 的 1/3 续期，续期失败（键已过期被他人获取，或超过 TTL 未能联系 Redis）时视为失去锁，
+// 锁的 Context 随之取消，执行中的任务应尽快停止。每次获取锁都会得到单调递增的隔离令牌（fencing token），
+// 写入方可据此拒绝已失去锁的旧持有者（如 GC 停顿后恢复的实例）的迟到写入。
+// 未配置 Redis 时按单实例处理：获取锁总是成功，当前实例始终是主节点。
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"stock-analysis-system/backend/pkg/metrics"
+)
+
+// keyPrefix 锁在 Redis 中的键前缀，隔离令牌计数器为 <前缀><name>:fence
+const keyPrefix = "lock:"
+
+// releaseTimeout 释放锁时访问 Redis 的超时
+const releaseTimeout = 2 * time.Second
+
+// ErrLocked 锁已被其他实例持有
+var ErrLocked = errors.New("锁已被其他实例持有")
+
+// ErrFenced 写入被拒绝：已有更新的隔离令牌写入过，当前持有者已失去锁
+var ErrFenced = errors.New("隔离令牌已过期，锁已被其他实例获取")
+
+// fenceKey context 键：隔离令牌
+type fenceKey struct{}
+
+// WithFence 返回携带隔离令牌的 context，写入方通过 FenceFrom 取出后随数据保存并拒绝更旧的令牌
+func WithFence(ctx context.Context, token int64) context.Context {
+	return context.WithValue(ctx, fenceKey{}, token)
+}
+
+// FenceFrom ctx 携带的隔离令牌，没有时为 0（不参与隔离检查）
+func FenceFrom(ctx context.Context) int64 {
+	token, _ := ctx.Value(fenceKey{}).(int64)
+	return token
+}
+
+// acquireScript 键不存在时设置并返回新的隔离令牌，已存在时返回 0
+var acquireScript = redis.NewScript(`
+if redis.call('SET', KEYS[1], ARGV[1], 'NX', 'PX', ARGV[2]) then
+	return redis.call('INCR', KEYS[2])
+end
+return 0`)
+
+// renewScript 仍由自己持有时续期
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript 仍由自己持有时删除，避免误删他人在过期后获取的锁
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0`)
+
+// Locker 分布式锁，client 为 nil 时按单实例处理
+type Locker struct {
+	client *redis.Client
+	owner  string // 实例标识，写入锁的值便于排查
+}
+
+// New 创建分布式锁，service 为服务名，与主机名、进程号组成实例标识
+func New(client *redis.Client, service string) *Locker {
+	host, _ := os.Hostname()
+	return &Locker{client: client, owner: fmt.Sprintf("%s@%s:%d", service, host, os.Getpid())}
+}
+
+// Lock 已获取的锁
+type Lock struct {
+	locker *Locker
+	name   string
+	value  string
+	token  int64
+	ttl    time.Duration
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	release sync.Once
+}
+
+// Acquire 尝试获取锁（不等待），已被持有时返回 ErrLocked。
+// 获取成功后后台自动续期，直到 Release、ctx 取消或续期失败。
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	lockCtx, cancel := context.WithCancel(ctx)
+	lock := &Lock{
+		locker: l,
+		name:   name,
+		value:  l.owner + "/" + uuid.New().String(),
+		ttl:    ttl,
+		ctx:    lockCtx,
+		cancel: cancel,
+	}
+	if l.client == nil {
+		return lock, nil
+	}
+
+	token, err := acquireScript.Run(ctx, l.client, []string{keyPrefix + name, keyPrefix + name + ":fence"},
+		lock.value, ttl.Milliseconds()).Int64()
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("获取锁 %s 失败: %w", name, err)
+	}
+	if token == 0 {
+		cancel()
+		return nil, ErrLocked
+	}
+	lock.token = token
+	go lock.keepAlive()
+	return lock, nil
+}
+
+// Claim 标记一次性事项（如某个定时任务的某个运行时段）已由当前实例处理，key 在 ttl 内已被标记时返回 false
+func (l *Locker) Claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if l.client == nil {
+		return true, nil
+	}
+	return l.client.SetNX(ctx, keyPrefix+"claim:"+key, l.owner, ttl).Result()
+}
+
+// Token 隔离令牌，每次获取锁递增；未配置 Redis 时为 0
+func (k *Lock) Token() int64 {
+	return k.token
+}
+
+// Context 持有锁期间有效，失去锁或释放后取消
+func (k *Lock) Context() context.Context {
+	return k.ctx
+}
+
+// Release 释放锁，已失去锁时不影响当前持有者；重复调用无副作用
+func (k *Lock) Release() {
+	k.cancel()
+	if k.locker.client == nil {
+		return
+	}
+	k.release.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+		defer cancel()
+		if err := releaseScript.Run(ctx, k.locker.client, []string{keyPrefix + k.name}, k.value).Err(); err != nil {
+			log.Printf("释放锁 %s 失败: %v", k.name, err)
+		}
+	})
+}
+
+// keepAlive 每隔 TTL 的 1/3 续期；锁已不属于自己，或距上次成功续期已超过 TTL 时取消 Context
+func (k *Lock) keepAlive() {
+	ticker := time.NewTicker(k.ttl / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-k.ctx.Done():
+			return
+		case now := <-ticker.C:
+			ok, err := renewScript.Run(k.ctx, k.locker.client, []string{keyPrefix + k.name}, k.value, k.ttl.Milliseconds()).Int64()
+			switch {
+			case err == nil && ok == 1:
+				renewed = now
+			case err == nil:
+				log.Printf("锁 %s 已过期并被其他实例获取（令牌 %d）", k.name, k.token)
+				k.cancel()
+				return
+			case now.Sub(renewed) >= k.ttl:
+				log.Printf("锁 %s 超过 %s 未能续期，视为失去锁: %v", k.name, k.ttl, err)
+				k.cancel()
+				return
+			default:
+				log.Printf("续期锁 %s 失败，稍后重试: %v", k.name, err)
+			}
+		}
+	}
+}
+
+// ============ 主节点选举 ============
+
+// Leader 主节点选举：持续竞争同名锁，持有期间当前实例为主节点
+type Leader struct {
+	locker *Locker
+	name   string
+
+	mu   sync.RWMutex
+	lock *Lock // 当前任期，不是主节点时为 nil
+
+	done chan struct{} // 竞选结束并已释放锁后关闭
+}
+
+// Elect 开始竞争主节点直到 ctx 取消，ctx 取消时主动释放以便其他实例尽快接替。
+// 未当选时每隔 TTL 的 1/3 重试；未配置 Redis 时当前实例立即成为主节点。
+func (l *Locker) Elect(ctx context.Context, name string, ttl time.Duration) *Leader {
+	leader := &Leader{locker: l, name: name, done: make(chan struct{})}
+	metrics.Register("lock_leader_"+name, leader.collect)
+	if l.client == nil {
+		leader.lock, _ = l.Acquire(ctx, name, ttl)
+		close(leader.done)
+		return leader
+	}
+	go leader.campaign(ctx, ttl)
+	return leader
+}
+
+// campaign 竞选循环
+func (ld *Leader) campaign(ctx context.Context, ttl time.Duration) {
+	defer close(ld.done)
+	for {
+		lock, err := ld.locker.Acquire(ctx, ld.name, ttl)
+		switch {
+		case err == nil:
+			log.Printf("当选 %s 主节点（令牌 %d）", ld.name, lock.Token())
+			ld.setLock(lock)
+			<-lock.Context().Done()
+			ld.setLock(nil)
+			if ctx.Err() != nil {
+				lock.Release()
+				return
+			}
+			log.Printf("失去 %s 主节点身份（令牌 %d）", ld.name, lock.Token())
+		case !errors.Is(err, ErrLocked) && ctx.Err() == nil:
+			log.Printf("竞选 %s 主节点失败: %v", ld.name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(ttl / 3):
+		}
+	}
+}
+
+// Wait 等待竞选结束，在 Elect 的 ctx 取消后调用，保证关闭 Redis 连接前已释放锁；ld 为 nil（未启动选举）时直接返回
+func (ld *Leader) Wait() {
+	if ld == nil {
+		return
+	}
+	<-ld.done
+}
+
+func (ld *Leader) setLock(lock *Lock) {
+	ld.mu.Lock()
+	ld.lock = lock
+	ld.mu.Unlock()
+}
+
+// Term 当前任期，不是主节点时返回 nil
+func (ld *Leader) Term() *Lock {
+	ld.mu.RLock()
+	defer ld.mu.RUnlock()
+	return ld.lock
+}
+
+// IsLeader 当前实例是否为主节点
+func (ld *Leader) IsLeader() bool {
+	return ld.Term() != nil
+}
+
+// RunOnce 当前实例为主节点，且本任务在 slot 时段尚未被任何实例执行时，同步执行 fn 并返回 true。
+// slot 标识一次运行（如按小时调度的任务传 "2006010215" 格式的时间），用于避免主节点切换后新主节点重复执行同一时段；
+// 标记保留 claimTTL，应不短于调度间隔。fn 的 ctx 在失去主节点身份时取消，token 为本任期的隔离令牌。
+func (ld *Leader) RunOnce(job, slot string, claimTTL time.Duration, fn func(ctx context.Context, token int64)) bool {
+	term := ld.Term()
+	if term == nil {
+		return false
+	}
+	claimed, err := ld.locker.Claim(term.Context(), ld.name+":"+job+":"+slot, claimTTL)
+	if err != nil {
+		log.Printf("标记定时任务 %s（%s）失败，跳过本次执行: %v", job, slot, err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+	fn(term.Context(), term.Token())
+	return true
+}
+
+// collect 主节点身份指标
+func (ld *Leader) collect() []metrics.Sample {
+	value := 0.0
+	if ld.IsLeader() {
+		value = 1
+	}
+	return []metrics.Sample{{
+		Name:   "lock_leader",
+		Help:   "当前实例是否为定时任务主节点",
+		Type:   metrics.TypeGauge,
+		Labels: map[string]string{"name": ld.name},
+		Value:  value,
+	}}
+}
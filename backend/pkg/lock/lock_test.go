@@ -0,0 +1,132 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestLockers(t *testing.T) (*miniredis.Miniredis, *Locker, *Locker) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	a := New(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "a")
+	b := New(redis.NewClient(&redis.Options{Addr: mr.Addr()}), "b")
+	return mr, a, b
+}
+
+func TestAcquireRelease(t *testing.T) {
+	_, a, b := newTestLockers(t)
+	ctx := context.Background()
+
+	first, err := a.Acquire(ctx, "sync", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Acquire(ctx, "sync", time.Minute); !errors.Is(err, ErrLocked) {
+		t.Fatalf("锁被持有时应返回 ErrLocked, got %v", err)
+	}
+
+	first.Release()
+	if first.Context().Err() == nil {
+		t.Error("释放后 Context 应取消")
+	}
+	second, err := b.Acquire(ctx, "sync", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Release()
+	if second.Token() <= first.Token() {
+		t.Errorf("隔离令牌应递增: %d -> %d", first.Token(), second.Token())
+	}
+}
+
+func TestLockLostAfterExpiry(t *testing.T) {
+	mr, a, b := newTestLockers(t)
+	ctx := context.Background()
+
+	stale, err := a.Acquire(ctx, "sync", 300*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟实例停顿导致未能续期：键过期后被其他实例获取
+	mr.FastForward(time.Second)
+	current, err := b.Acquire(ctx, "sync", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-stale.Context().Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("锁被他人获取后旧持有者的 Context 应取消")
+	}
+
+	// 旧持有者释放不能删除新持有者的锁
+	stale.Release()
+	if got, _ := mr.Get(keyPrefix + "sync"); got != current.value {
+		t.Errorf("锁的值 = %q，应仍属于新持有者", got)
+	}
+	current.Release()
+}
+
+func TestLeaderRunOnce(t *testing.T) {
+	_, a, b := newTestLockers(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	leaders := []*Leader{a.Elect(ctx, "scheduler", time.Minute), b.Elect(ctx, "scheduler", time.Minute)}
+	deadline := time.Now().Add(2 * time.Second)
+	for !leaders[0].IsLeader() && !leaders[1].IsLeader() {
+		if time.Now().After(deadline) {
+			t.Fatal("应有一个实例当选")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if leaders[0].IsLeader() && leaders[1].IsLeader() {
+		t.Fatal("不应同时有两个主节点")
+	}
+
+	runs := 0
+	for _, leader := range leaders {
+		for i := 0; i < 2; i++ {
+			leader.RunOnce("daily", "2026101702", time.Hour, func(ctx context.Context, token int64) {
+				if token == 0 || ctx.Err() != nil {
+					t.Errorf("任期无效: token=%d err=%v", token, ctx.Err())
+				}
+				runs++
+			})
+		}
+	}
+	if runs != 1 {
+		t.Errorf("同一时段应只执行一次, got %d", runs)
+	}
+}
+
+func TestWithoutRedis(t *testing.T) {
+	locker := New(nil, "single")
+	leader := locker.Elect(context.Background(), "scheduler", time.Minute)
+	if !leader.IsLeader() {
+		t.Fatal("未配置 Redis 时应始终为主节点")
+	}
+	ran := leader.RunOnce("daily", "2026101702", time.Hour, func(context.Context, int64) {})
+	if !ran {
+		t.Error("未配置 Redis 时应直接执行")
+	}
+
+	var none *Leader
+	none.Wait()
+}
+
+func TestFenceContext(t *testing.T) {
+	ctx := context.Background()
+	if token := FenceFrom(ctx); token != 0 {
+		t.Errorf("未携带令牌时应为 0, got %d", token)
+	}
+	if token := FenceFrom(WithFence(ctx, 7)); token != 7 {
+		t.Errorf("FenceFrom = %d, 期望 7", token)
+	}
+}
@@ -0,0 +1,116 @@
+// Package markettime 行情时间：按交易所所在时区（默认 Asia/Shanghai）划分交易日、解析与格式化行情时间
+//
+// 服务器本地时区不参与行情时间的计算；对外接口中的时间带有明确的时区偏移，或同时给出毫秒时间戳。
+package markettime
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // 内嵌时区数据，镜像中没有 tzdata 时同样可用
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// DefaultTimezone 未配置时交易所使用的时区
+const DefaultTimezone = "Asia/Shanghai"
+
+const (
+	DateLayout   = "2006-01-02"       // 交易日
+	MinuteLayout = "2006-01-02 15:04" // 分钟K线时间（交易所时区）
+)
+
+var (
+	defaultLocation = mustLoad(DefaultTimezone)
+
+	mu       sync.RWMutex
+	fallback = defaultLocation
+	zones    = make(map[string]*time.Location) // 交易所（大写）-> 时区
+)
+
+func mustLoad(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		panic(err)
+	}
+	return loc
+}
+
+// Configure 按配置设置默认时区与各交易所时区，服务启动时调用；时区名称错误时返回错误且不修改当前设置
+func Configure(cfg config.CalendarConfig) error {
+	def := defaultLocation
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return fmt.Errorf("时区 %q 无效: %w", cfg.Timezone, err)
+		}
+		def = loc
+	}
+	configured := make(map[string]*time.Location, len(cfg.Timezones))
+	for exchange, name := range cfg.Timezones {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return fmt.Errorf("交易所 %s 的时区 %q 无效: %w", exchange, name, err)
+		}
+		configured[strings.ToUpper(exchange)] = loc
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = def
+	zones = configured
+	return nil
+}
+
+// Location 交易所所在时区，exchange 为空或未单独配置时为默认时区
+func Location(exchange string) *time.Location {
+	mu.RLock()
+	defer mu.RUnlock()
+	if loc, ok := zones[strings.ToUpper(exchange)]; ok {
+		return loc
+	}
+	return fallback
+}
+
+// Now 交易所时区的当前时间
+func Now(exchange string) time.Time {
+	return time.Now().In(Location(exchange))
+}
+
+// Today 交易所时区的当前日期（YYYY-MM-DD）
+func Today(exchange string) string {
+	return Now(exchange).Format(DateLayout)
+}
+
+// TradeDate 时间点在交易所时区所属的日期（YYYY-MM-DD）
+func TradeDate(t time.Time, exchange string) string {
+	return t.In(Location(exchange)).Format(DateLayout)
+}
+
+// StartOfDate 交易日在交易所时区的零点
+// date 为只表示日期的时间（日K线等按 UTC 零点保存），只取其年月日。
+func StartOfDate(date time.Time, exchange string) time.Time {
+	y, m, d := date.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, Location(exchange))
+}
+
+// ParseDate 按交易所时区解析 YYYY-MM-DD，返回当日零点
+func ParseDate(value, exchange string) (time.Time, error) {
+	return time.ParseInLocation(DateLayout, value, Location(exchange))
+}
+
+// ParseMinute 按交易所时区解析不带时区的分钟时间（YYYY-MM-DD HH:MM）
+func ParseMinute(value, exchange string) (time.Time, error) {
+	return time.ParseInLocation(MinuteLayout, value, Location(exchange))
+}
+
+// FormatMinute 以交易所时区格式化分钟时间（YYYY-MM-DD HH:MM）
+func FormatMinute(t time.Time, exchange string) string {
+	return t.In(Location(exchange)).Format(MinuteLayout)
+}
+
+// Format 以交易所时区格式化为带时区偏移的 RFC3339 时间，如 2024-06-03T09:35:00+08:00
+func Format(t time.Time, exchange string) string {
+	return t.In(Location(exchange)).Format(time.RFC3339)
+}
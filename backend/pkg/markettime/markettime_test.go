@@ -0,0 +1,54 @@
+package markettime
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+func TestDefaultTimezone(t *testing.T) {
+	// 2024-06-03 01:35 UTC 为北京时间 09:35
+	at := time.Date(2024, 6, 3, 1, 35, 0, 0, time.UTC)
+	if got := FormatMinute(at, "SH"); got != "2024-06-03 09:35" {
+		t.Errorf("FormatMinute = %s", got)
+	}
+	if got := Format(at, "SZ"); got != "2024-06-03T09:35:00+08:00" {
+		t.Errorf("Format = %s", got)
+	}
+
+	parsed, err := ParseMinute("2024-06-03 09:35", "SH")
+	if err != nil || !parsed.Equal(at) {
+		t.Errorf("ParseMinute = %v, %v", parsed, err)
+	}
+
+	// 北京时间 6月4日 00:30 仍为 UTC 6月3日
+	late := time.Date(2024, 6, 3, 16, 30, 0, 0, time.UTC)
+	if got := TradeDate(late, ""); got != "2024-06-04" {
+		t.Errorf("TradeDate = %s", got)
+	}
+
+	// 按 UTC 零点保存的日K线日期，对应交易所时区的当日零点
+	day := StartOfDate(time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), "SH")
+	if day.UnixMilli() != time.Date(2024, 6, 2, 16, 0, 0, 0, time.UTC).UnixMilli() {
+		t.Errorf("StartOfDate = %v", day)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(config.CalendarConfig{})
+
+	if err := Configure(config.CalendarConfig{Timezone: "UTC", Timezones: map[string]string{"hk": "Asia/Hong_Kong"}}); err != nil {
+		t.Fatal(err)
+	}
+	if Location("SH").String() != "UTC" || Location("HK").String() != "Asia/Hong_Kong" {
+		t.Errorf("时区配置未生效: %v %v", Location("SH"), Location("HK"))
+	}
+
+	if err := Configure(config.CalendarConfig{Timezone: "Mars/Olympus"}); err == nil {
+		t.Error("无效时区应返回错误")
+	}
+	if Location("HK").String() != "Asia/Hong_Kong" {
+		t.Error("配置失败时不应修改当前设置")
+	}
+}
@@ -0,0 +1,143 @@
+// Package metering 用量计量：按用户与自然日累计 API 调用次数、下载数据量与回测计算时长，定期汇总写入 PostgreSQL。
+// 网关记录每个已认证请求的调用次数与响应字节数，回测服务记录每个回测任务的执行时长；
+// 用户服务的 GET /api/v1/user/usage/daily 展示每日用量，tools/usage-export 导出全部用户的用量 CSV 用于计费。
+package metering
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// DefaultFlushInterval 默认的写入间隔
+const DefaultFlushInterval = time.Minute
+
+// Counters 一段时间内的用量增量
+type Counters struct {
+	APICalls        int64
+	BytesDownloaded int64
+	BacktestSeconds float64
+}
+
+// Store 累加每日用量，由 repository.UsageRepository 实现
+// 同一用户同一天的记录已存在时在原值上累加，多个服务实例可同时写入。
+type Store interface {
+	AddDailyUsage(ctx context.Context, usage []models.UsageDaily) error
+}
+
+// key 用量汇总的维度
+type key struct {
+	userID uint
+	date   string
+}
+
+// Meter 在内存中累计用量，按固定间隔批量写入 Store
+type Meter struct {
+	store   Store
+	mu      sync.Mutex
+	pending map[key]Counters
+	now     func() time.Time
+}
+
+// NewMeter 创建用量计量
+func NewMeter(store Store) *Meter {
+	return &Meter{store: store, pending: make(map[key]Counters), now: time.Now}
+}
+
+// Record 记录用户的一次用量，计入当前自然日
+func (m *Meter) Record(userID uint, c Counters) {
+	if userID == 0 {
+		return
+	}
+	k := key{userID: userID, date: m.now().Format("2006-01-02")}
+	m.mu.Lock()
+	m.pending[k] = m.pending[k].add(c)
+	m.mu.Unlock()
+}
+
+// Flush 将累计的用量写入 Store，写入失败时保留到下次重试
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = make(map[key]Counters)
+	m.mu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	rows := make([]models.UsageDaily, 0, len(pending))
+	for k, c := range pending {
+		date, _ := time.ParseInLocation("2006-01-02", k.date, time.Local)
+		rows = append(rows, models.UsageDaily{
+			UserID:          k.userID,
+			Date:            date,
+			APICalls:        c.APICalls,
+			BytesDownloaded: c.BytesDownloaded,
+			BacktestSeconds: c.BacktestSeconds,
+		})
+	}
+	if err := m.store.AddDailyUsage(ctx, rows); err != nil {
+		m.mu.Lock()
+		for k, c := range pending {
+			m.pending[k] = m.pending[k].add(c)
+		}
+		m.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Run 每隔 interval 写入一次累计的用量，ctx 取消后返回；退出前应再调用一次 Flush
+func (m *Meter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Flush(ctx); err != nil {
+				log.Printf("写入用量统计失败: %v", err)
+			}
+		}
+	}
+}
+
+func (c Counters) add(o Counters) Counters {
+	return Counters{
+		APICalls:        c.APICalls + o.APICalls,
+		BytesDownloaded: c.BytesDownloaded + o.BytesDownloaded,
+		BacktestSeconds: c.BacktestSeconds + o.BacktestSeconds,
+	}
+}
+
+// csvHeader 用量 CSV 的表头
+var csvHeader = []string{"user_id", "username", "date", "api_calls", "bytes_downloaded", "backtest_seconds"}
+
+// WriteCSV 按日期与用户输出用量 CSV，usernames 为空时用户名列留空
+func WriteCSV(w io.Writer, usage []models.UsageDaily, usernames map[uint]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, u := range usage {
+		if err := cw.Write([]string{
+			strconv.FormatUint(uint64(u.UserID), 10),
+			usernames[u.UserID],
+			u.Date.Format("2006-01-02"),
+			strconv.FormatInt(u.APICalls, 10),
+			strconv.FormatInt(u.BytesDownloaded, 10),
+			strconv.FormatFloat(u.BacktestSeconds, 'f', 1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
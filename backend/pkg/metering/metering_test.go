@@ -0,0 +1,76 @@
+package metering
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+type fakeStore struct {
+	rows []models.UsageDaily
+	err  error
+}
+
+func (s *fakeStore) AddDailyUsage(ctx context.Context, usage []models.UsageDaily) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.rows = append(s.rows, usage...)
+	return nil
+}
+
+func TestMeterFlush(t *testing.T) {
+	store := &fakeStore{}
+	m := NewMeter(store)
+	m.now = func() time.Time { return time.Date(2024, 6, 10, 9, 30, 0, 0, time.Local) }
+
+	m.Record(1, Counters{APICalls: 1, BytesDownloaded: 100})
+	m.Record(1, Counters{APICalls: 1, BytesDownloaded: 50})
+	m.Record(1, Counters{BacktestSeconds: 2.5})
+	m.Record(0, Counters{APICalls: 1}) // 未识别用户不计入
+
+	// 写入失败时保留用量，下次一并写入
+	store.err = errors.New("db down")
+	if err := m.Flush(context.Background()); err == nil {
+		t.Fatal("写入失败时应返回错误")
+	}
+	m.Record(1, Counters{APICalls: 1})
+	store.err = nil
+	if err := m.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(store.rows) != 1 {
+		t.Fatalf("同一用户同一天应合并为 1 条，实际 %d 条", len(store.rows))
+	}
+	row := store.rows[0]
+	if row.UserID != 1 || row.Date.Format("2006-01-02") != "2024-06-10" {
+		t.Errorf("用户或日期错误: %+v", row)
+	}
+	if row.APICalls != 3 || row.BytesDownloaded != 150 || row.BacktestSeconds != 2.5 {
+		t.Errorf("用量累计错误: %+v", row)
+	}
+
+	// 已写入的用量不会重复写入
+	if err := m.Flush(context.Background()); err != nil || len(store.rows) != 1 {
+		t.Errorf("没有新用量时不应写入，实际 %d 条, err=%v", len(store.rows), err)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	usage := []models.UsageDaily{
+		{UserID: 1, Date: time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local), APICalls: 12, BytesDownloaded: 2048, BacktestSeconds: 3.5},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, usage, map[uint]string{1: "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "user_id,username,date,api_calls,bytes_downloaded,backtest_seconds\n1,alice,2024-06-10,12,2048,3.5\n"
+	if got := buf.String(); got != want {
+		t.Errorf("CSV 内容错误:\n%s\n期望:\n%s", got, want)
+	}
+}
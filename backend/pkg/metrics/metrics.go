@@ -0,0 +1,126 @@
+// Package metrics 进程内指标注册表：各模块注册采集函数，由服务以 Prometheus 文本格式在 /metrics 输出。
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 指标类型
+const (
+	TypeGauge   = "gauge"
+	TypeCounter = "counter"
+)
+
+// Sample 单个指标值
+type Sample struct {
+	Name   string
+	Help   string
+	Type   string // gauge/counter
+	Labels map[string]string
+	Value  float64
+}
+
+// Collector 采集函数，每次抓取时调用，应只读取内存中的状态
+type Collector func() []Sample
+
+var (
+	mu         sync.RWMutex
+	collectors = make(map[string]Collector)
+)
+
+// Register 注册采集函数，同名时替换
+func Register(name string, collector Collector) {
+	mu.Lock()
+	defer mu.Unlock()
+	collectors[name] = collector
+}
+
+// Unregister 注销采集函数
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(collectors, name)
+}
+
+// Gather 调用全部采集函数，结果按指标名称与标签排序
+func Gather() []Sample {
+	mu.RLock()
+	var samples []Sample
+	for _, collector := range collectors {
+		samples = append(samples, collector()...)
+	}
+	mu.RUnlock()
+
+	sort.SliceStable(samples, func(i, j int) bool {
+		if samples[i].Name != samples[j].Name {
+			return samples[i].Name < samples[j].Name
+		}
+		return formatLabels(samples[i].Labels) < formatLabels(samples[j].Labels)
+	})
+	return samples
+}
+
+// WriteText 以 Prometheus 文本格式输出，同名指标只输出一次 HELP/TYPE
+func WriteText(w io.Writer, samples []Sample) error {
+	last := ""
+	for _, s := range samples {
+		if s.Name != last {
+			if s.Help != "" {
+				if _, err := fmt.Fprintf(w, "# HELP %s %s\n", s.Name, s.Help); err != nil {
+					return err
+				}
+			}
+			if s.Type != "" {
+				if _, err := fmt.Fprintf(w, "# TYPE %s %s\n", s.Name, s.Type); err != nil {
+					return err
+				}
+			}
+			last = s.Name
+		}
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", s.Name, formatLabels(s.Labels), strconv.FormatFloat(s.Value, 'g', -1, 64)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Handler 输出全部指标的 HTTP 处理函数
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		WriteText(w, Gather())
+	})
+}
+
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(labels[k]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
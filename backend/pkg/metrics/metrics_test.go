@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	Register("test", func() []Sample {
+		return []Sample{
+			{Name: "pool_in_use", Help: "使用中的连接数", Type: TypeGauge, Labels: map[string]string{"db": "replica"}, Value: 3},
+			{Name: "pool_in_use", Help: "使用中的连接数", Type: TypeGauge, Labels: map[string]string{"db": "primary"}, Value: 5},
+			{Name: "slow_queries_total", Type: TypeCounter, Labels: map[string]string{"note": "a\"b\\c"}, Value: 12},
+		}
+	})
+	defer Unregister("test")
+
+	var b strings.Builder
+	if err := WriteText(&b, Gather()); err != nil {
+		t.Fatalf("输出失败: %v", err)
+	}
+	want := `# HELP pool_in_use 使用中的连接数
+# TYPE pool_in_use gauge
+pool_in_use{db="primary"} 5
+pool_in_use{db="replica"} 3
+# TYPE slow_queries_total counter
+slow_queries_total{note="a\"b\\c"} 12
+`
+	if b.String() != want {
+		t.Errorf("输出不正确:\n%s\n期望:\n%s", b.String(), want)
+	}
+}
+
+func TestUnregister(t *testing.T) {
+	Register("tmp", func() []Sample { return []Sample{{Name: "tmp", Value: 1}} })
+	Unregister("tmp")
+	for _, s := range Gather() {
+		if s.Name == "tmp" {
+			t.Fatalf("注销后仍输出了指标")
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/auth"
+)
+
+// APIKeyHeader 运维脚本等非用户调用方携带 API Key 的请求头
+const APIKeyHeader = "X-API-Key"
+
+// RoleOrAPIKey 允许携带有效 API Key 的调用方，或 JWT 认证且具有指定角色的用户访问
+// 携带 X-API-Key 时只按 API Key 校验，通过后上下文 api_key 为 true、user_id 为 0；
+// 未配置 API Key 时等同于 JWTAuth + RequireRole。
+func RoleOrAPIKey(keys *auth.KeySet, users RoleLookup, role string, apiKeys []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if key := c.GetHeader(APIKeyHeader); key != "" {
+			if !validAPIKey(apiKeys, key) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "无效的 API Key"})
+				return
+			}
+			c.Set("api_key", true)
+			c.Next()
+			return
+		}
+		if !authenticate(c, keys) || !hasRole(c, users, role) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// validAPIKey 按常量时间逐个比较，避免通过响应时间猜测 Key
+func validAPIKey(apiKeys []string, key string) bool {
+	valid := false
+	for _, k := range apiKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
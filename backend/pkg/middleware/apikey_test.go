@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/auth"
+	"stock-analysis-system/backend/pkg/config"
+)
+
+type roles map[uint]string
+
+func (r roles) GetRole(_ context.Context, userID uint) (string, error) {
+	return r[userID], nil
+}
+
+func TestRoleOrAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.NewKeySet(config.AuthConfig{JWTSecret: strings.Repeat("k", 32)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	apiKey := strings.Repeat("a", 32)
+
+	r := gin.New()
+	r.POST("/sync", RoleOrAPIKey(keys, roles{1: "admin", 2: "user"}, "admin", []string{apiKey}), func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	call := func(header, value string) int {
+		req := httptest.NewRequest(http.MethodPost, "/sync", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+	token := func(userID uint) string {
+		s, err := keys.Issue(userID, "u")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + s
+	}
+
+	tests := []struct {
+		name, header, value string
+		want                int
+	}{
+		{"无认证信息", "", "", http.StatusUnauthorized},
+		{"有效 API Key", APIKeyHeader, apiKey, http.StatusOK},
+		{"无效 API Key", APIKeyHeader, "wrong", http.StatusUnauthorized},
+		{"管理员 Token", "Authorization", token(1), http.StatusOK},
+		{"普通用户 Token", "Authorization", token(2), http.StatusForbidden},
+		{"无效 Token", "Authorization", "Bearer bad", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		if got := call(tt.header, tt.value); got != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/auth"
+)
+
+// JWTAuth JWT认证中间件，校验通过后将 user_id、username 写入上下文
+func JWTAuth(keys *auth.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authenticate(c, keys) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate 校验 Bearer Token 并写入 user_id、username，失败时已写入 401 响应并中止
+func authenticate(c *gin.Context, keys *auth.KeySet) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "缺少认证信息"})
+		c.Abort()
+		return false
+	}
+
+	// Bearer Token
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+
+	claims, err := keys.Parse(tokenString)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "无效的认证信息"})
+		c.Abort()
+		return false
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("username", claims.Username)
+	return true
+}
+
+// RoleLookup 按用户ID查询角色，由 repository.UserRepository 实现
+type RoleLookup interface {
+	GetRole(ctx context.Context, userID uint) (string, error)
+}
+
+// RequireRole 要求当前用户具有指定角色，需放在 JWTAuth 之后
+// 角色不写入 Token，每次请求从数据库读取，撤销权限后立即生效。
+func RequireRole(users RoleLookup, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasRole(c, users, role) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasRole 校验当前用户角色，失败时已写入 403 响应并中止
+func hasRole(c *gin.Context, users RoleLookup, role string) bool {
+	got, err := users.GetRole(c.Request.Context(), c.GetUint("user_id"))
+	if err != nil || got != role {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return false
+	}
+	return true
+}
+
+// WebSocketBearer 浏览器 WebSocket 无法设置请求头，允许通过子协议 "bearer, <token>" 传递 Token
+// 需放在 JWTAuth 之前；已携带 Authorization 时不做处理。
+func WebSocketBearer() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token, ok := BearerProtocolToken(c.Request); ok {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+		c.Next()
+	}
+}
+
+// BearerProtocolToken 从 Sec-WebSocket-Protocol 中提取 "bearer, <token>" 形式的 Token
+func BearerProtocolToken(r *http.Request) (string, bool) {
+	var protocols []string
+	for _, value := range r.Header.Values("Sec-WebSocket-Protocol") {
+		for _, p := range strings.Split(value, ",") {
+			protocols = append(protocols, strings.TrimSpace(p))
+		}
+	}
+	for i := 0; i+1 < len(protocols); i++ {
+		if protocols[i] == "bearer" && protocols[i+1] != "" {
+			return protocols[i+1], true
+		}
+	}
+	return "", false
+}
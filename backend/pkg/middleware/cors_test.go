@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+func newCORSRouter(cfg config.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CORS(cfg))
+	r.GET("/ping", func(c *gin.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+	return r
+}
+
+func TestCORS_AllowedOriginWithCredentials(t *testing.T) {
+	r := newCORSRouter(config.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/ping", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("预检状态码错误，期望: %d, 实际: %d", http.StatusNoContent, w.Code)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://app.example.com" {
+		t.Errorf("Allow-Origin 不正确: %s", got)
+	}
+	if got := w.Header().Get("Access-Control-Allow-Credentials"); got != "true" {
+		t.Errorf("Allow-Credentials 不正确: %s", got)
+	}
+	if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+		t.Errorf("Max-Age 不正确: %s", got)
+	}
+}
+
+func TestCORS_RejectsUnknownOrigin(t *testing.T) {
+	r := newCORSRouter(config.CORSConfig{AllowedOrigins: []string{"https://app.example.com"}})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Origin", "https://evil.example.org")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("未授权来源不应返回 Allow-Origin，实际: %s", got)
+	}
+
+	req = httptest.NewRequest(http.MethodOptions, "/ping", nil)
+	req.Header.Set("Origin", "https://evil.example.org")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("未授权来源预检应返回 403，实际: %d", w.Code)
+	}
+}
+
+func TestCORS_WildcardNeverCombinedWithCredentials(t *testing.T) {
+	r := newCORSRouter(config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Origin", "https://a.example.com")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got == "*" {
+		t.Errorf("携带凭证时不应返回通配符 Allow-Origin")
+	}
+}
+
+func TestOriginAllowed_SubdomainWildcard(t *testing.T) {
+	allowed := []string{"https://*.example.com"}
+
+	cases := map[string]bool{
+		"https://app.example.com":       true,
+		"https://a.b.example.com":       true,
+		"https://example.com":           false,
+		"http://app.example.com":        false,
+		"https://evil.com/.example.com": false,
+	}
+	for origin, want := range cases {
+		if got := originAllowed(origin, allowed); got != want {
+			t.Errorf("originAllowed(%s) = %v, 期望 %v", origin, got, want)
+		}
+	}
+}
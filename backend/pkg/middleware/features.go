@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/features"
+)
+
+// defaultMaintenanceMessage 未配置提示时返回的维护说明
+const defaultMaintenanceMessage = "系统维护中，请稍后再试"
+
+// Maintenance 维护中的接口返回 503 及维护说明，健康检查与监控指标接口不受影响
+func Maintenance(store *features.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := c.Request.URL.Path
+		if p == "/health" || p == "/metrics" {
+			c.Next()
+			return
+		}
+		m, ok := store.Maintenance(routePath(p))
+		if !ok {
+			c.Next()
+			return
+		}
+
+		msg := m.Message
+		if msg == "" {
+			msg = defaultMaintenanceMessage
+		}
+		if m.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(m.RetryAfter))
+		}
+		data := gin.H{"maintenance": true}
+		if m.Until != "" {
+			data["until"] = m.Until
+		}
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": msg, "data": data})
+	}
+}
+
+// FeatureGate 功能未对当前用户开放时返回 404，需放在 JWTAuth 之后
+func FeatureGate(store *features.Store, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !store.Enabled(name, c.GetUint("user_id")) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"code": 404, "msg": "接口不存在"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// routePath 去掉 /api/<版本> 前缀，如 /api/v1/backtest/run -> /backtest/run
+func routePath(p string) string {
+	if !strings.HasPrefix(p, "/api/") {
+		return p
+	}
+	rest := strings.TrimPrefix(p, "/api/")
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		return rest[i:]
+	}
+	return "/"
+}
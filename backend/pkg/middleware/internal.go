@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/internalauth"
+)
+
+// InternalAuth 只接受网关签名转发的请求，签名缺失、无效、过期或重复时返回 401
+// 健康检查与监控指标接口（/health、/metrics）供编排与采集系统直接访问，不校验。
+func InternalAuth(v *internalauth.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p := c.Request.URL.Path; p == "/health" || p == "/metrics" {
+			c.Next()
+			return
+		}
+		if err := v.Verify(c.Request, time.Now()); err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
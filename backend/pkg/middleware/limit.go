@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// BodyLimit 请求体大小限制中间件
+// Content-Length 超限直接返回 413；未声明长度的请求在读取超限时由 handler 的解析报错。
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"code": 413, "msg": "请求体过大"})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// Timeout 接口超时中间件
+// 为请求上下文设置截止时间，handler 中基于 c.Request.Context() 的数据库、下游调用会随之取消；
+// 超时且 handler 尚未写出响应时返回 504。
+func Timeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{"code": 504, "msg": "请求超时"})
+		}
+	}
+}
+
+// RateLimit 按客户端 IP 限流的中间件（令牌桶），超限返回 429 并在 Retry-After 中给出等待秒数
+// 每个请求都调用 limits 读取当前配置，配置热更新后立即按新的速率生效；RPS 为 0 时不限流。
+func RateLimit(limits func() config.RateLimitConfig) gin.HandlerFunc {
+	l := &ipLimiter{buckets: make(map[string]*tokenBucket)}
+	return func(c *gin.Context) {
+		cfg := limits()
+		if cfg.RPS <= 0 {
+			c.Next()
+			return
+		}
+		if wait, ok := l.allow(c.ClientIP(), cfg, time.Now()); !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"code": 429, "msg": "请求过于频繁，请稍后重试"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// rateLimitSweepInterval 清理已回满的令牌桶的间隔
+const rateLimitSweepInterval = time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type ipLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+// allow 取一个令牌，令牌不足时返回需要等待的时间
+func (l *ipLimiter) allow(key string, cfg config.RateLimitConfig, now time.Time) (time.Duration, bool) {
+	burst := float64(max(cfg.Burst, 1))
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) >= rateLimitSweepInterval {
+		// 已回满的桶与新建的桶等价，删除以免长时间运行后占用内存
+		for k, b := range l.buckets {
+			if now.Sub(b.last).Seconds()*cfg.RPS >= burst {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*cfg.RPS)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / cfg.RPS * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
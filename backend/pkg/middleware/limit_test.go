@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+func TestBodyLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(BodyLimit(8))
+	r.POST("/echo", func(c *gin.Context) {
+		if _, err := io.ReadAll(c.Request.Body); err != nil {
+			c.Status(http.StatusRequestEntityTooLarge)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("small")))
+	if w.Code != http.StatusOK {
+		t.Errorf("未超限请求应通过，实际: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("this body is too large")))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("超限请求应返回 413，实际: %d", w.Code)
+	}
+
+	// 未声明 Content-Length 时由 MaxBytesReader 兜底
+	req := httptest.NewRequest(http.MethodPost, "/echo", io.NopCloser(strings.NewReader("this body is too large")))
+	req.ContentLength = -1
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("分块请求超限应读取失败，实际: %d", w.Code)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/slow", Timeout(20*time.Millisecond), func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+	r.GET("/fast", Timeout(time.Second), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("超时请求应返回 504，实际: %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("正常请求应返回 200，实际: %d", w.Code)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	limit := config.RateLimitConfig{RPS: 1, Burst: 2}
+	r := gin.New()
+	r.Use(RateLimit(func() config.RateLimitConfig { return limit }))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := request("10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("突发额度内的第 %d 个请求应通过，实际: %d", i+1, w.Code)
+		}
+	}
+	w := request("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("超出额度应返回 429 与 Retry-After: 1，实际: %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := request("10.0.0.2"); w.Code != http.StatusOK {
+		t.Errorf("不同 IP 应分别限流，实际: %d", w.Code)
+	}
+
+	// 配置改为不限流后立即生效
+	limit = config.RateLimitConfig{}
+	if w := request("10.0.0.1"); w.Code != http.StatusOK {
+		t.Errorf("关闭限流后请求应通过，实际: %d", w.Code)
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/quota"
+)
+
+// Quota 套餐配额中间件，需放在 JWTAuth 之后
+// 再创建一个 resource 会超出当前用户套餐上限时返回 403，提示升级套餐。
+func Quota(checker *quota.Checker, resource quota.Resource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid := c.GetUint("user_id")
+		if err := checker.Check(c.Request.Context(), uid, resource); err != nil {
+			AbortQuota(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AbortQuota 按配额检查的错误中止请求：超出配额返回 403，其他错误返回 500
+func AbortQuota(c *gin.Context, err error) {
+	var exceeded *quota.ExceededError
+	var outOfRange *quota.RangeError
+	if errors.As(err, &exceeded) || errors.As(err, &outOfRange) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"code": 403, "msg": err.Error()})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询套餐配额失败"})
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/requestid"
+)
+
+// RequestID 请求ID中间件
+// 沿用请求头中的 X-Request-ID（由网关生成），没有或不合法时生成新的ID；
+// ID 写入请求头（经网关代理时转发给后端服务）、响应头、gin 上下文（request_id）与请求上下文。
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+			c.Request.Header.Set(requestid.Header, id)
+		}
+
+		c.Set("request_id", id)
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.NewContext(c.Request.Context(), id))
+		c.Next()
+	}
+}
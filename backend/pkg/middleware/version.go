@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// AbortVersionConflict 乐观锁冲突时中止请求并返回 409，data.current_version 为当前版本号，供客户端重新读取后合并修改
+func AbortVersionConflict(c *gin.Context, current int) {
+	c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+		"code": 409,
+		"msg":  repository.ErrVersionConflict.Error(),
+		"data": gin.H{"current_version": current},
+	})
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAbortVersionConflict(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	handled := false
+	r.PUT("/item", func(c *gin.Context) {
+		AbortVersionConflict(c, 3)
+	}, func(c *gin.Context) {
+		handled = true
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/item", nil))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("状态码 %d，期望 409", w.Code)
+	}
+	if handled {
+		t.Error("中止后不应执行后续处理函数")
+	}
+	var body struct {
+		Code int `json:"code"`
+		Data struct {
+			CurrentVersion int `json:"current_version"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Code != 409 || body.Data.CurrentVersion != 3 {
+		t.Errorf("响应 %s", w.Body.String())
+	}
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// AlertRule 用户提醒规则：单只股票上的阈值条件、指标事件（上穿/下穿、N 日新高/新低）或公司事件前提醒
+// 每次增量同步日K线后由数据同步服务从 LastBarAt 之后增量检查，触发时写入 AlertEvent。
+type AlertRule struct {
+	ID              uint       `gorm:"primaryKey" json:"id"`
+	UserID          uint       `gorm:"not null;index" json:"user_id"`
+	Name            string     `gorm:"size:50;not null" json:"name"`
+	Symbol          string     `gorm:"size:10;not null;index:idx_alert_rule_symbol" json:"symbol"`
+	Exchange        string     `gorm:"size:10;not null;index:idx_alert_rule_symbol" json:"exchange"`
+	Condition       string     `gorm:"size:20;not null" json:"condition"`              // above/below/cross_above/cross_below/new_high/new_low/before_event
+	Left            string     `gorm:"column:left_expr;size:500;not null" json:"left"` // 指标表达式，如 MACD 的 EMA(12) - EMA(26)；before_event 为事件类型
+	Right           string     `gorm:"column:right_expr;size:500" json:"right"`        // 比较对象（表达式或常量），新高/新低为空
+	Window          int        `gorm:"column:window_size" json:"window"`               // 新高/新低的回看K线数，事件前提醒的提前天数
+	Enabled         bool       `gorm:"not null;default:true" json:"enabled"`
+	LastBarAt       *time.Time `json:"last_bar_at"`       // 已检查到的最后一根日K线（事件前提醒为最后检查日期），为空时下次只检查最新一根
+	LastTriggeredAt *time.Time `json:"last_triggered_at"` // 最近一次触发的K线日期（事件前提醒为事件日期）
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (AlertRule) TableName() string {
+	return "alert_rules"
+}
+
+// AlertEvent 提醒规则的一次触发
+type AlertEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	RuleID    uint      `gorm:"not null;uniqueIndex:idx_alert_event_rule_date" json:"rule_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Symbol    string    `gorm:"size:10;not null" json:"symbol"`
+	Exchange  string    `gorm:"size:10;not null" json:"exchange"`
+	Condition string    `gorm:"size:20;not null" json:"condition"`
+	TradeDate time.Time `gorm:"type:date;not null;uniqueIndex:idx_alert_event_rule_date" json:"trade_date"`
+	Value     float64   `json:"value"`     // 触发时左侧的值；事件前提醒为剩余天数
+	Reference float64   `json:"reference"` // 比较基准：右侧的值，或新高/新低前的最高/最低值；事件前提醒为提前天数
+	Message   string    `gorm:"size:500" json:"message"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (AlertEvent) TableName() string {
+	return "alert_events"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// 图表标注类型
+const (
+	AnnotationTrendLine  = "trend_line" // 趋势线，两个锚点
+	AnnotationHorizontal = "horizontal" // 水平价位线，一个锚点（只用价格）
+	AnnotationText       = "text"       // 文字备注，一个锚点
+)
+
+// ChartAnnotation 用户在K线图上的标注，按股票与周期保存，跨会话、跨设备同步
+type ChartAnnotation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index:idx_annotation_user_symbol" json:"user_id"`
+	Symbol    string    `gorm:"size:10;not null;index:idx_annotation_user_symbol" json:"symbol"`
+	Exchange  string    `gorm:"size:10;not null;index:idx_annotation_user_symbol" json:"exchange"`
+	Period    string    `gorm:"size:10;not null;index:idx_annotation_user_symbol" json:"period"` // 1m/5m/15m/30m/60m/1d/1w/1M
+	Type      string    `gorm:"size:20;not null" json:"type"`                                    // trend_line/horizontal/text
+	Points    string    `gorm:"type:jsonb;not null" json:"points"`                               // 锚点 JSON：[{"time":"2024-01-02","price":10.5}]
+	Text      string    `gorm:"size:500" json:"text"`
+	Style     string    `gorm:"type:jsonb" json:"style"` // 样式 JSON（颜色、线宽等），由前端定义
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (ChartAnnotation) TableName() string {
+	return "chart_annotations"
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// StringArray PostgreSQL text[] 列，按数组字面量编码与解析（元素统一加引号并转义，含逗号、引号、空格的元素也能正确保存）
+type StringArray []string
+
+// GormDataType 列类型
+func (StringArray) GormDataType() string {
+	return "text[]"
+}
+
+// Value 编码为数组字面量，nil 保存为空数组
+func (a StringArray) Value() (driver.Value, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, item := range a {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('"')
+		for _, r := range item {
+			if r == '"' || r == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteRune(r)
+		}
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String(), nil
+}
+
+// Scan 解析数组字面量，如 {600519.SH,"a,b","x\"y"}；NULL 解析为 nil
+func (a *StringArray) Scan(src interface{}) error {
+	var literal string
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case string:
+		literal = v
+	case []byte:
+		literal = string(v)
+	default:
+		return fmt.Errorf("无法将 %T 解析为 text[]", src)
+	}
+	items, err := parseArrayLiteral(literal)
+	if err != nil {
+		return err
+	}
+	*a = items
+	return nil
+}
+
+// parseArrayLiteral 解析一维 PostgreSQL 数组字面量；未加引号的 NULL 元素跳过
+func parseArrayLiteral(literal string) (StringArray, error) {
+	literal = strings.TrimSpace(literal)
+	if len(literal) < 2 || literal[0] != '{' || literal[len(literal)-1] != '}' {
+		return nil, fmt.Errorf("数组字面量格式错误: %q", literal)
+	}
+	body := literal[1 : len(literal)-1]
+
+	items := StringArray{}
+	for i := 0; i < len(body); {
+		for i < len(body) && body[i] == ' ' {
+			i++
+		}
+		if i == len(body) {
+			break
+		}
+
+		var item strings.Builder
+		quoted := body[i] == '"'
+		if quoted {
+			i++
+			for ; i < len(body) && body[i] != '"'; i++ {
+				if body[i] == '\\' && i+1 < len(body) {
+					i++
+				}
+				item.WriteByte(body[i])
+			}
+			if i == len(body) {
+				return nil, fmt.Errorf("数组字面量引号未闭合: %q", literal)
+			}
+			i++ // 跳过结束引号
+		}
+		for ; i < len(body) && body[i] != ','; i++ {
+			if !quoted {
+				item.WriteByte(body[i])
+			}
+		}
+		i++ // 跳过逗号
+
+		value := item.String()
+		if !quoted {
+			value = strings.TrimSpace(value)
+			if strings.EqualFold(value, "NULL") {
+				continue
+			}
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStringArrayRoundTrip(t *testing.T) {
+	for _, items := range []StringArray{
+		{},
+		{"600519.SH", "000001.SZ"},
+		{"a,b", `x"y`, `back\slash`, " padded ", "NULL", ""},
+	} {
+		value, err := items.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got StringArray
+		if err := got.Scan(value); err != nil {
+			t.Fatalf("Scan(%v): %v", value, err)
+		}
+		if !reflect.DeepEqual(got, items) {
+			t.Errorf("Scan(%v) = %q, want %q", value, got, items)
+		}
+	}
+}
+
+func TestStringArrayScan(t *testing.T) {
+	for _, tc := range []struct {
+		src  interface{}
+		want StringArray
+	}{
+		{nil, nil},
+		{"{}", StringArray{}},
+		{[]byte("{600519.SH, 000001.SZ}"), StringArray{"600519.SH", "000001.SZ"}},
+		{`{"a b",NULL,c}`, StringArray{"a b", "c"}},
+	} {
+		var got StringArray
+		if err := got.Scan(tc.src); err != nil {
+			t.Fatalf("Scan(%v): %v", tc.src, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Scan(%v) = %q, want %q", tc.src, got, tc.want)
+		}
+	}
+
+	var got StringArray
+	for _, bad := range []interface{}{"600519.SH", `{"open}`, 42} {
+		if err := got.Scan(bad); err == nil {
+			t.Errorf("Scan(%v) 应返回错误", bad)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// 管理员操作类型
+const (
+	AuditSyncTrigger         = "sync_trigger"         // 手动触发同步任务
+	AuditBarsDelete          = "bars_delete"          // 删除一段K线/指标数据
+	AuditBarsRepair          = "bars_repair"          // 删除并从数据源重新同步一段日K线
+	AuditBarsCorrect         = "bars_correct"         // 人工修正一根日K线
+	AuditBarsDedupe          = "bars_dedupe"          // 清理重复日K线
+	AuditIndicatorsRecompute = "indicators_recompute" // 重新计算一段技术指标
+	AuditQualityReport       = "quality_report"       // 生成全市场数据质量报告
+)
+
+// AuditLog 管理员数据运维操作的审计日志，记录操作人、对象、参数与执行结果
+type AuditLog struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Username  string    `gorm:"size:50" json:"username"`
+	Action    string    `gorm:"size:30;not null;index" json:"action"`
+	Symbol    string    `gorm:"size:10" json:"symbol"` // 为空表示全市场操作
+	Exchange  string    `gorm:"size:10" json:"exchange"`
+	Params    string    `gorm:"type:jsonb" json:"params"` // 请求参数 JSON
+	Reason    string    `gorm:"size:200" json:"reason"`
+	Status    string    `gorm:"size:20;not null" json:"status"` // success/failed
+	Result    string    `gorm:"type:text" json:"result"`        // 执行结果摘要或错误信息
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// TableName 指定表名
+func (AuditLog) TableName() string {
+	return "admin_audit_logs"
+}
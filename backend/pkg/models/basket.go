@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BasketLeg 股票篮子的成分股
+type BasketLeg struct {
+	Symbol   string  `json:"symbol"`
+	Exchange string  `json:"exchange"`
+	Weight   float64 `json:"weight"` // 归一化后的权重，合计为 1
+}
+
+// BasketLegs PostgreSQL jsonb 列，保存篮子的成分股与权重
+type BasketLegs []BasketLeg
+
+// GormDataType 列类型
+func (BasketLegs) GormDataType() string {
+	return "jsonb"
+}
+
+// Value 编码为 JSON，nil 保存为空数组
+func (l BasketLegs) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan 解析 JSON；NULL 解析为 nil
+func (l *BasketLegs) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return fmt.Errorf("无法将 %T 解析为篮子成分", src)
+	}
+	return json.Unmarshal(data, l)
+}
+
+// Basket 用户自定义股票篮子（虚拟指数）
+// 每日收盘后按成分股涨跌幅计算点位并保存，可作为图表标的或回测基准。
+type Basket struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	Name        string     `gorm:"size:50;not null" json:"name"`
+	Description string     `json:"description"`
+	Legs        BasketLegs `gorm:"not null" json:"legs"`
+	BaseDate    time.Time  `gorm:"type:date;not null" json:"base_date"` // 基日，指数在基日（非交易日时为之前最近的交易日）收盘为 1000 点
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Basket) TableName() string {
+	return "baskets"
+}
+
+// BasketValue 篮子某交易日的收盘点位
+type BasketValue struct {
+	BasketID  uint      `gorm:"primaryKey" json:"-"`
+	TradeDate time.Time `gorm:"type:date;primaryKey" json:"trade_date"`
+	Value     float64   `gorm:"not null" json:"value"`
+	ChangePct float64   `json:"change_pct"`
+	Coverage  float64   `json:"coverage"` // 当日有行情（已上市）的成分股权重之和
+}
+
+// TableName 指定表名
+func (BasketValue) TableName() string {
+	return "basket_values"
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// 公司事件类型
+const (
+	EventEarnings           = "earnings"            // 定期报告披露
+	EventShareholderMeeting = "shareholder_meeting" // 股东大会
+	EventLockupExpiry       = "lockup_expiry"       // 限售股解禁
+)
+
+// CorporateEventTypes 全部公司事件类型及其中文名称
+var CorporateEventTypes = map[string]string{
+	EventEarnings:           "定期报告披露",
+	EventShareholderMeeting: "股东大会",
+	EventLockupExpiry:       "限售股解禁",
+}
+
+// CorporateEvent 公司事件日历：预约披露日、股东大会、限售股解禁等即将发生的事件
+// 由数据同步服务从数据源同步，同一股票同一类型同一日期只保留一条，日期变更时旧日期的记录保留。
+type CorporateEvent struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Symbol    string    `gorm:"size:10;not null;uniqueIndex:idx_corporate_event_unique" json:"symbol"`
+	Exchange  string    `gorm:"size:10;not null;uniqueIndex:idx_corporate_event_unique" json:"exchange"`
+	Name      string    `gorm:"size:100" json:"name"`
+	EventType string    `gorm:"size:30;not null;uniqueIndex:idx_corporate_event_unique" json:"event_type"`
+	EventDate time.Time `gorm:"type:date;not null;index;uniqueIndex:idx_corporate_event_unique" json:"event_date"`
+	Title     string    `gorm:"size:200" json:"title"`  // 如 "2024年年度报告"、"2024年第一次临时股东大会"
+	Detail    string    `gorm:"size:500" json:"detail"` // 补充说明，如解禁股数与占总股本比例
+	Source    string    `gorm:"size:50" json:"source"`  // 数据来源
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CorporateEvent) TableName() string {
+	return "corporate_events"
+}
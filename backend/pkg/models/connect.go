@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// 沪深港通资金方向
+const (
+	ConnectNorthbound = "north" // 北向：香港投资者经沪股通、深股通买卖 A 股
+	ConnectSouthbound = "south" // 南向：内地投资者经港股通买卖港股
+)
+
+// 沪深港通通道：北向为沪股通/深股通，南向为港股通（沪）/港股通（深）
+const (
+	ConnectChannelSH = "sh"
+	ConnectChannelSZ = "sz"
+)
+
+// ConnectFlow 沪深港通单个通道的每日成交资金 (用于InfluxDB)
+type ConnectFlow struct {
+	Date       time.Time `json:"date"`
+	Direction  string    `json:"direction"`   // north/south
+	Channel    string    `json:"channel"`     // sh/sz
+	NetInflow  float64   `json:"net_inflow"`  // 成交净买入（元）
+	BuyAmount  float64   `json:"buy_amount"`  // 买入成交额（元）
+	SellAmount float64   `json:"sell_amount"` // 卖出成交额（元）
+}
+
+// NorthboundHolding 北向资金个股持股 (用于InfluxDB)
+type NorthboundHolding struct {
+	Symbol      string    `json:"symbol"`
+	Exchange    string    `json:"exchange"`
+	Date        time.Time `json:"date"`
+	Shares      int64     `json:"shares"`       // 持股数（股）
+	MarketValue float64   `json:"market_value"` // 持股市值（元）
+	Ratio       float64   `json:"ratio"`        // 持股占流通股比例（%）
+}
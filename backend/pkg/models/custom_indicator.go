@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// CustomIndicator 用户自定义指标，表达式基于 OHLCV 与内置指标函数，如 (CLOSE - MA(20)) / ATR(14)
+// 策略参数中通过 custom_indicators 按名称引用。
+type CustomIndicator struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_custom_indicator_user_name" json:"user_id"`
+	Name        string    `gorm:"size:50;not null;uniqueIndex:idx_custom_indicator_user_name" json:"name"` // 字母、数字、下划线，同一用户内唯一
+	Expression  string    `gorm:"size:500;not null" json:"expression"`
+	Description string    `gorm:"size:200" json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (CustomIndicator) TableName() string {
+	return "custom_indicators"
+}
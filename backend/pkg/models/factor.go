@@ -0,0 +1,92 @@
+package models
+
+import (
+	"time"
+)
+
+// 因子名称
+const (
+	FactorMomentum   = "momentum"   // 动量：过去12个月（剔除最近1个月）收益率
+	FactorValue      = "value"      // 价值：盈利收益率(E/P)与账面市值比(B/P)均值
+	FactorVolatility = "volatility" // 波动率：近60日年化波动率
+	FactorSize       = "size"       // 市值：总市值对数
+)
+
+// 财报类型
+const (
+	ReportTypeQ1     = "Q1"
+	ReportTypeQ2     = "Q2"
+	ReportTypeQ3     = "Q3"
+	ReportTypeAnnual = "annual"
+)
+
+// FinancialReport 财务报告数据（利润表为报告期累计值）
+type FinancialReport struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	Symbol             string    `gorm:"size:10;not null;index" json:"symbol"`
+	Exchange           string    `gorm:"size:10;not null" json:"exchange"`
+	ReportType         string    `gorm:"size:10;not null" json:"report_type"` // Q1/Q2/Q3/annual
+	ReportDate         time.Time `gorm:"type:date;not null;index" json:"report_date"`
+	TotalRevenue       float64   `json:"total_revenue"`
+	NetProfit          float64   `json:"net_profit"`
+	GrossProfit        float64   `json:"gross_profit"`
+	TotalAssets        float64   `json:"total_assets"`
+	TotalLiabilities   float64   `json:"total_liabilities"`
+	ShareholdersEquity float64   `json:"shareholders_equity"`
+	ROE                float64   `json:"roe"`
+	ROA                float64   `json:"roa"`
+	GrossMargin        float64   `json:"gross_margin"`
+	DebtRatio          float64   `json:"debt_ratio"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (FinancialReport) TableName() string {
+	return "financial_reports"
+}
+
+// DisclosureDeadline 法定披露截止日，此前财报可能尚未公布
+// 一季报、三季报为报告期后1个月，半年报2个月，年报4个月。
+func (r *FinancialReport) DisclosureDeadline() time.Time {
+	switch r.ReportType {
+	case ReportTypeQ2:
+		return r.ReportDate.AddDate(0, 2, 0)
+	case ReportTypeAnnual:
+		return r.ReportDate.AddDate(0, 4, 0)
+	default:
+		return r.ReportDate.AddDate(0, 1, 0)
+	}
+}
+
+// AnnualizedNetProfit 按报告期累计净利润折算的年化净利润
+func (r *FinancialReport) AnnualizedNetProfit() float64 {
+	switch r.ReportType {
+	case ReportTypeQ1:
+		return r.NetProfit * 4
+	case ReportTypeQ2:
+		return r.NetProfit * 2
+	case ReportTypeQ3:
+		return r.NetProfit * 4 / 3
+	default:
+		return r.NetProfit
+	}
+}
+
+// FactorScore 单只股票在某交易日的因子得分
+type FactorScore struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	TradeDate  time.Time `gorm:"type:date;not null;uniqueIndex:idx_factor_score_unique" json:"trade_date"`
+	Factor     string    `gorm:"size:20;not null;uniqueIndex:idx_factor_score_unique" json:"factor"`
+	Symbol     string    `gorm:"size:10;not null;uniqueIndex:idx_factor_score_unique" json:"symbol"`
+	Exchange   string    `gorm:"size:10;not null;uniqueIndex:idx_factor_score_unique" json:"exchange"`
+	Value      float64   `json:"value"`      // 因子原始值
+	ZScore     float64   `json:"zscore"`     // 截面标准化得分（已按因子方向调整，越大越好）
+	Rank       int       `json:"rank"`       // 截面排名，1 为最优
+	Percentile float64   `json:"percentile"` // 截面分位（0~1，越大越好）
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (FactorScore) TableName() string {
+	return "factor_scores"
+}
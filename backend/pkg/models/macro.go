@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// 宏观序列名称
+const (
+	MacroCPI   = "cpi_yoy" // CPI 同比
+	MacroPMI   = "pmi"     // 制造业 PMI
+	MacroLPR1Y = "lpr_1y"  // 1 年期 LPR
+	MacroLPR5Y = "lpr_5y"  // 5 年期以上 LPR
+	MacroM2    = "m2_yoy"  // M2 同比
+)
+
+// MacroSeriesInfo 宏观序列定义
+// 月度统计数据的日期为统计月份的第一天，LPR 为报价日；ReleaseDays 为日期之后多少天数据才公布，用于按时点计算因子时避免使用未来数据。
+type MacroSeriesInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Unit        string `json:"unit"`      // %，指数为空
+	Frequency   string `json:"frequency"` // monthly
+	ReleaseDays int    `json:"release_days"`
+}
+
+// MacroSeries 全部支持的宏观序列
+var MacroSeries = []MacroSeriesInfo{
+	{Name: MacroCPI, Description: "居民消费价格指数同比", Unit: "%", Frequency: "monthly", ReleaseDays: 40},
+	{Name: MacroPMI, Description: "制造业采购经理指数", Unit: "", Frequency: "monthly", ReleaseDays: 31},
+	{Name: MacroLPR1Y, Description: "1 年期贷款市场报价利率", Unit: "%", Frequency: "monthly", ReleaseDays: 0},
+	{Name: MacroLPR5Y, Description: "5 年期以上贷款市场报价利率", Unit: "%", Frequency: "monthly", ReleaseDays: 0},
+	{Name: MacroM2, Description: "广义货币供应量 M2 同比", Unit: "%", Frequency: "monthly", ReleaseDays: 45},
+}
+
+// LookupMacroSeries 按名称查找宏观序列定义
+func LookupMacroSeries(name string) (MacroSeriesInfo, bool) {
+	for _, s := range MacroSeries {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return MacroSeriesInfo{}, false
+}
+
+// MacroPoint 宏观序列的一个数据点，保存在 InfluxDB 的 macro 测量中（series 为标签）
+type MacroPoint struct {
+	Series string    `json:"series"`
+	Date   time.Time `json:"date"`
+	Value  float64   `json:"value"`
+}
@@ -15,9 +15,12 @@ type Stock struct {
 	Industry     string    `gorm:"size:50;index" json:"industry"`
 	FullName     string    `gorm:"size:200" json:"full_name"`
 	ListDate     *time.Time `json:"list_date"`
-	TotalShare   int64     `json:"total_share"`
-	FloatShare   int64     `json:"float_share"`
+	TotalShare   int64     `json:"total_share"` // 总股本（股），由股票资料同步写入，未同步时为 0
+	FloatShare   int64     `json:"float_share"` // 流通股本（股）
 	Status       string    `gorm:"size:10;default:'active'" json:"status"`
+	RiskWarning  string    `gorm:"size:10;index" json:"risk_warning"` // 当前风险警示：空/ST/*ST，历史见 StockRiskWarning
+	QualityScore *int      `gorm:"index" json:"quality_score"`      // 数据质量评分 0~100，每晚计算，未评分时为空
+	QualityScoredAt *time.Time `json:"quality_scored_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -32,6 +35,29 @@ func (s *Stock) IsActive() bool {
 	return s.Status == "active"
 }
 
+// MarketCap 按价格计算总市值（元），总股本未知时返回 nil
+func (s *Stock) MarketCap(price float64) *float64 {
+	if s.TotalShare <= 0 {
+		return nil
+	}
+	v := price * float64(s.TotalShare)
+	return &v
+}
+
+// FloatCap 按价格计算流通市值（元），流通股本未知时返回 nil
+func (s *Stock) FloatCap(price float64) *float64 {
+	if s.FloatShare <= 0 {
+		return nil
+	}
+	v := price * float64(s.FloatShare)
+	return &v
+}
+
+// IsST 是否处于风险警示（ST/*ST）
+func (s *Stock) IsST() bool {
+	return s.RiskWarning != ""
+}
+
 // GetFullCode 获取完整代码 (symbol.exchange)
 func (s *Stock) GetFullCode() string {
 	return s.Symbol + "." + s.Exchange
@@ -69,7 +95,8 @@ type Indicator struct {
 	Symbol        string    `json:"symbol"`
 	Exchange      string    `json:"exchange"`
 	Date          time.Time `json:"date"`
-	IndicatorType string    `json:"indicator_type"` // ma, macd, rsi, kdj, boll
+	IndicatorType string    `json:"indicator_type"`   // ma, macd, rsi, kdj, boll, atr, cci, obv, dmi, wr, bias
+	Period        int       `json:"period,omitempty"` // 计算周期，atr、cci、dmi、wr、bias 使用
 	// MA指标
 	MA5   float64 `json:"ma5,omitempty"`
 	MA10  float64 `json:"ma10,omitempty"`
@@ -94,21 +121,36 @@ type Indicator struct {
 	BollUpper float64 `json:"boll_upper,omitempty"`
 	BollMid   float64 `json:"boll_mid,omitempty"`
 	BollLower float64 `json:"boll_lower,omitempty"`
+	// ATR、CCI、OBV 指标
+	ATR float64 `json:"atr,omitempty"`
+	CCI float64 `json:"cci,omitempty"`
+	OBV float64 `json:"obv,omitempty"`
+	// DMI指标
+	PDI float64 `json:"pdi,omitempty"`
+	MDI float64 `json:"mdi,omitempty"`
+	ADX float64 `json:"adx,omitempty"`
+	// WR、BIAS 指标
+	WR   float64 `json:"wr,omitempty"`
+	BIAS float64 `json:"bias,omitempty"`
 }
 
 // User 用户模型
 type User struct {
-	ID           uint       `gorm:"primaryKey" json:"id"`
-	Username     string     `gorm:"size:50;not null;uniqueIndex" json:"username"`
-	Email        string     `gorm:"size:100;not null;uniqueIndex" json:"email"`
-	PasswordHash string     `gorm:"size:255;not null" json:"-"`
-	AvatarURL    string     `gorm:"size:500" json:"avatar_url"`
-	Phone        string     `gorm:"size:20" json:"phone"`
-	Status       string     `gorm:"size:10;default:'active'" json:"status"`
-	LastLoginAt  *time.Time `json:"last_login_at"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+	ID            uint           `gorm:"primaryKey" json:"id"`
+	Username      string         `gorm:"size:50;not null;uniqueIndex" json:"username"`
+	Email         string         `gorm:"size:100;not null;uniqueIndex" json:"email"`
+	PasswordHash  string         `gorm:"size:255;not null" json:"-"`
+	AvatarURL     string         `gorm:"size:500" json:"avatar_url"`
+	Phone         string         `gorm:"size:20" json:"phone"`
+	Status        string         `gorm:"size:10;default:'active'" json:"status"`
+	Role          string         `gorm:"size:20;default:'user'" json:"role"` // user/admin，admin 可访问数据运维接口
+	Plan          string         `gorm:"size:20;default:'free'" json:"plan"` // 订阅套餐 free/pro，见 Plan*
+	PlanExpiresAt *time.Time     `json:"plan_expires_at"`                    // 付费套餐到期时间，为空表示长期有效
+	LastLoginAt   *time.Time     `json:"last_login_at"`
+	Version       int            `gorm:"not null;default:1" json:"version"` // 乐观锁版本号，更新资料时加一
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 // TableName 指定表名
@@ -116,6 +158,26 @@ func (User) TableName() string {
 	return "users"
 }
 
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户
+	RoleAdmin = "admin" // 管理员，可访问 /api/v1/admin 数据运维接口
+)
+
+// 订阅套餐，各套餐的配额见 pkg/quota
+const (
+	PlanFree = "free" // 免费版
+	PlanPro  = "pro"  // 专业版
+)
+
+// CurrentPlan 用户当前生效的套餐，付费套餐到期后按免费版处理
+func (u *User) CurrentPlan(now time.Time) string {
+	if u.Plan == "" || (u.PlanExpiresAt != nil && !now.Before(*u.PlanExpiresAt)) {
+		return PlanFree
+	}
+	return u.Plan
+}
+
 // Strategy 策略模型
 type Strategy struct {
 	ID          uint           `gorm:"primaryKey" json:"id"`
@@ -125,11 +187,21 @@ type Strategy struct {
 	Type        string         `gorm:"size:50;not null;index" json:"type"`
 	ClassName   string         `gorm:"size:100;not null" json:"class_name"`
 	Params      string         `gorm:"type:jsonb" json:"params"`
-	Symbols     string         `gorm:"type:text[]" json:"symbols"`
+	Symbols     StringArray    `gorm:"type:text[]" json:"symbols"`
+	UniverseID  *uint          `gorm:"index" json:"universe_id,omitempty"` // 引用的股票池，回测时按交易日成分代替 Symbols
+	ExcludeST   bool           `gorm:"default:false" json:"exclude_st"`    // 排除风险警示（ST/*ST）股票，按交易日的时点状态判断
+	Priority    int            `gorm:"default:0" json:"priority"`          // 信号冲突按优先级处理时使用，数值越大优先级越高
 	IsActive    bool           `gorm:"default:true" json:"is_active"`
 	IsPublic    bool           `gorm:"default:false" json:"is_public"`
+	Tags        []*Tag         `gorm:"many2many:strategy_tags" json:"tags,omitempty"`
+	Version     int            `gorm:"not null;default:1" json:"version"` // 乐观锁版本号，每次更新加一
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
+
+	RegressionEnabled bool    `gorm:"default:false" json:"regression_enabled"`     // 每晚按滚动窗口重新回测，结果见 StrategyPerformance
+	RegressionWindow  int     `gorm:"default:365" json:"regression_window"`        // 回归回测的滚动窗口（自然日）
+	DivergenceAction  string  `gorm:"size:20;default:''" json:"divergence_action"` // 实盘信号表现偏离回测预期时的处理：空（不检查）、notify、deactivate
+	DivergenceRatio   float64 `gorm:"default:2" json:"divergence_ratio"`           // 偏离阈值倍数，如实盘回撤超过预期回撤的 2 倍
 }
 
 // TableName 指定表名
@@ -137,6 +209,11 @@ func (Strategy) TableName() string {
 	return "strategies"
 }
 
+// SymbolList 策略的股票列表（symbol.exchange）
+func (s *Strategy) SymbolList() []string {
+	return s.Symbols
+}
+
 // TradeSignal 交易信号模型
 type TradeSignal struct {
 	ID         uint      `gorm:"primaryKey" json:"id"`
@@ -149,6 +226,8 @@ type TradeSignal struct {
 	Reason     string    `json:"reason"`
 	Confidence float64   `json:"confidence"`
 	IsExecuted bool      `gorm:"default:false" json:"is_executed"`
+	Status     string    `gorm:"size:20;default:'active';index" json:"status"` // active/cancelled，见 SignalStatus*
+	Note       string    `gorm:"size:200" json:"note,omitempty"`               // 撤销原因
 	ExecutedAt *time.Time `json:"executed_at"`
 	CreatedAt  time.Time `json:"created_at"`
 }
@@ -176,6 +255,8 @@ type BacktestRecord struct {
 	Params         string     `gorm:"type:jsonb" json:"params"`
 	ResultData     string     `gorm:"type:jsonb" json:"result_data"`
 	Status         string     `gorm:"size:20;default:'running'" json:"status"`
+	StaleAt        *time.Time `json:"stale_at,omitempty"`                     // 回测使用的日K线被修正的时间，结果已过期需重新回测
+	StaleReason    string     `gorm:"size:200" json:"stale_reason,omitempty"` // 过期原因，如被修正的股票与日期
 	CreatedAt      time.Time  `json:"created_at"`
 	CompletedAt    *time.Time `json:"completed_at"`
 }
@@ -192,7 +273,9 @@ type Watchlist struct {
 	Name        string          `gorm:"size:50;not null" json:"name"`
 	Description string          `json:"description"`
 	Items       []*WatchlistItem `json:"items,omitempty"`
+	Tags        []*Tag          `gorm:"many2many:watchlist_tags" json:"tags,omitempty"`
 	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"` // 分组信息或明细变化时更新（数据库触发器维护）
 }
 
 // TableName 指定表名
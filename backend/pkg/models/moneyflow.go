@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// MoneyFlow 个股资金流向模型 (用于InfluxDB)
+type MoneyFlow struct {
+	Symbol              string    `json:"symbol"`
+	Exchange            string    `json:"exchange"`
+	Date                time.Time `json:"date"`
+	MainNetInflow       float64   `json:"main_net_inflow"`        // 主力净流入(超大单+大单)
+	MainNetInflowPct    float64   `json:"main_net_inflow_pct"`    // 主力净流入占比(%)
+	SuperLargeNetInflow float64   `json:"super_large_net_inflow"` // 超大单净流入
+	LargeNetInflow      float64   `json:"large_net_inflow"`       // 大单净流入
+	MediumNetInflow     float64   `json:"medium_net_inflow"`      // 中单净流入
+	SmallNetInflow      float64   `json:"small_net_inflow"`       // 小单净流入
+}
+
+// DragonTiger 龙虎榜记录模型
+type DragonTiger struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Symbol      string    `gorm:"size:10;not null;index;uniqueIndex:idx_dragon_tiger_unique" json:"symbol"`
+	Exchange    string    `gorm:"size:10;not null;uniqueIndex:idx_dragon_tiger_unique" json:"exchange"`
+	Name        string    `gorm:"size:100" json:"name"`
+	TradeDate   time.Time `gorm:"type:date;not null;index;uniqueIndex:idx_dragon_tiger_unique" json:"trade_date"`
+	Reason      string    `gorm:"size:200;not null;uniqueIndex:idx_dragon_tiger_unique" json:"reason"` // 上榜原因
+	Close       float64   `json:"close"`
+	ChangePct   float64   `json:"change_pct"`
+	BuyAmount   float64   `json:"buy_amount"`              // 龙虎榜买入额
+	SellAmount  float64   `json:"sell_amount"`             // 龙虎榜卖出额
+	NetAmount   float64   `json:"net_amount"`              // 龙虎榜净买额
+	TotalAmount float64   `json:"total_amount"`            // 当日总成交额
+	Seats       string    `gorm:"type:jsonb" json:"seats"` // 买卖席位明细
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (DragonTiger) TableName() string {
+	return "dragon_tiger_lists"
+}
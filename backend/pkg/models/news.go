@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// NewsArticle 新闻/公告模型
+type NewsArticle struct {
+	ID          uint          `gorm:"primaryKey" json:"id"`
+	Title       string        `gorm:"size:500;not null" json:"title"`
+	Summary     string        `json:"summary"`
+	Content     string        `json:"content,omitempty"`
+	Source      string        `gorm:"size:100;index" json:"source"`  // 来源：akshare, rss:xxx 等
+	Category    string        `gorm:"size:20;index" json:"category"` // news, announcement
+	URL         string        `gorm:"size:1000;not null;uniqueIndex" json:"url"`
+	PublishedAt time.Time     `gorm:"not null;index" json:"published_at"`
+	Symbols     []*NewsSymbol `gorm:"foreignKey:NewsID" json:"symbols,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// TableName 指定表名
+func (NewsArticle) TableName() string {
+	return "news_articles"
+}
+
+// NewsSymbol 新闻与股票的关联（标签）
+type NewsSymbol struct {
+	ID       uint   `gorm:"primaryKey" json:"-"`
+	NewsID   uint   `gorm:"not null;uniqueIndex:idx_news_symbol" json:"-"`
+	Symbol   string `gorm:"size:10;not null;index;uniqueIndex:idx_news_symbol" json:"symbol"`
+	Exchange string `gorm:"size:10;not null;uniqueIndex:idx_news_symbol" json:"exchange"`
+}
+
+// TableName 指定表名
+func (NewsSymbol) TableName() string {
+	return "news_symbols"
+}
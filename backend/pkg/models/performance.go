@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+)
+
+// 策略定期回归回测的滚动窗口（自然日）
+const (
+	DefaultRegressionWindow = 365
+	MinRegressionWindow     = 30
+	MaxRegressionWindow     = 3650
+)
+
+// StrategyPerformance 策略定期回归回测结果：开启后回测服务每晚按滚动窗口重新回测，每个运行日一条
+type StrategyPerformance struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	StrategyID   uint      `gorm:"not null;uniqueIndex:idx_strategy_performance_run" json:"strategy_id"`
+	RunDate      time.Time `gorm:"type:date;not null;uniqueIndex:idx_strategy_performance_run" json:"run_date"`
+	StartDate    time.Time `gorm:"type:date;not null" json:"start_date"` // 回测窗口
+	EndDate      time.Time `gorm:"type:date;not null" json:"end_date"`
+	TotalReturn  float64   `json:"total_return"`
+	AnnualReturn float64   `json:"annual_return"`
+	MaxDrawdown  float64   `json:"max_drawdown"`
+	SharpeRatio  float64   `json:"sharpe_ratio"`
+	WinRate      float64   `json:"win_rate"`
+	TradeCount   int       `json:"trade_count"`
+	Fence        int64     `gorm:"not null;default:0" json:"-"` // 写入时回归回测主节点的隔离令牌，更旧的令牌不能覆盖
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (StrategyPerformance) TableName() string {
+	return "strategy_performance"
+}
+
+// StrategyDivergence 策略实盘信号表现偏离回测预期的记录
+type StrategyDivergence struct {
+	ID               uint      `gorm:"primaryKey" json:"id"`
+	StrategyID       uint      `gorm:"not null;index" json:"strategy_id"`
+	Reasons          string    `gorm:"type:text" json:"reasons"` // 偏离原因，多条以换行分隔
+	Action           string    `gorm:"size:20" json:"action"`    // notify / deactivate
+	LiveTrades       int       `json:"live_trades"`
+	LiveReturn       float64   `json:"live_return"`
+	LiveDrawdown     float64   `json:"live_drawdown"`
+	LiveWinRate      float64   `json:"live_win_rate"`
+	ExpectedDrawdown float64   `json:"expected_drawdown"`
+	ExpectedWinRate  float64   `json:"expected_win_rate"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (StrategyDivergence) TableName() string {
+	return "strategy_divergences"
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// 模拟交易方向
+const (
+	TradeSideBuy  = "buy"
+	TradeSideSell = "sell"
+)
+
+// Portfolio 模拟交易组合模型
+type Portfolio struct {
+	ID          uint              `gorm:"primaryKey" json:"id"`
+	UserID      uint              `gorm:"not null;index" json:"user_id"`
+	Name        string            `gorm:"size:50;not null" json:"name"`
+	Description string            `json:"description"`
+	InitialCash float64           `gorm:"not null" json:"initial_cash"`                 // 初始资金
+	Benchmark   string            `gorm:"size:20;default:'000300.SH'" json:"benchmark"` // 业绩基准，symbol.exchange
+	Trades      []*PortfolioTrade `json:"trades,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Portfolio) TableName() string {
+	return "portfolios"
+}
+
+// PortfolioTrade 模拟交易成交记录模型
+type PortfolioTrade struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PortfolioID uint      `gorm:"not null;index" json:"portfolio_id"`
+	Symbol      string    `gorm:"size:10;not null" json:"symbol"`
+	Exchange    string    `gorm:"size:10;not null" json:"exchange"`
+	Side        string    `gorm:"size:10;not null" json:"side"` // buy/sell
+	Quantity    int64     `gorm:"not null" json:"quantity"`
+	Price       float64   `gorm:"not null" json:"price"`
+	Fee         float64   `json:"fee"`
+	TradedAt    time.Time `gorm:"not null;index" json:"traded_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (PortfolioTrade) TableName() string {
+	return "portfolio_trades"
+}
+
+// Amount 成交金额（不含费用）
+func (t *PortfolioTrade) Amount() float64 {
+	return float64(t.Quantity) * t.Price
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// 风险警示类型（A股 ST 制度）
+const (
+	RiskWarningST     = "ST"  // 其他风险警示
+	RiskWarningStarST = "*ST" // 退市风险警示
+)
+
+// StockRiskWarning 股票风险警示记录，按生效区间保存 ST/*ST 的实施与撤销
+type StockRiskWarning struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Symbol    string     `gorm:"size:10;not null;index:idx_risk_warning_symbol" json:"symbol"`
+	Exchange  string     `gorm:"size:10;not null;index:idx_risk_warning_symbol" json:"exchange"`
+	Warning   string     `gorm:"size:10;not null" json:"warning"`      // ST / *ST
+	StartDate time.Time  `gorm:"type:date;not null" json:"start_date"` // 实施日期
+	EndDate   *time.Time `gorm:"type:date" json:"end_date"`            // 撤销或变更日期，为空表示仍在警示中
+	Reason    string     `gorm:"size:200" json:"reason,omitempty"`     // 实施原因，如 连续两年亏损
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName 指定表名
+func (StockRiskWarning) TableName() string {
+	return "stock_risk_warnings"
+}
+
+// ActiveOn 判断警示在指定日期是否生效（实施日生效，撤销日起不再生效）
+func (w *StockRiskWarning) ActiveOn(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	if w.StartDate.Format("2006-01-02") > day {
+		return false
+	}
+	return w.EndDate == nil || w.EndDate.Format("2006-01-02") > day
+}
+
+// RiskWarningFromName 根据证券简称识别风险警示类型，非 ST 股票返回空字符串
+// 兼容未完成股改的 SST、S*ST 前缀。
+func RiskWarningFromName(name string) string {
+	name = strings.TrimPrefix(strings.TrimSpace(name), "S")
+	switch {
+	case strings.HasPrefix(name, "*ST"):
+		return RiskWarningStarST
+	case strings.HasPrefix(name, "ST"):
+		return RiskWarningST
+	}
+	return ""
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// 交易信号状态
+const (
+	SignalStatusActive    = "active"    // 有效信号
+	SignalStatusCancelled = "cancelled" // 被冲突处理撤销（对冲抵消或被更高优先级策略覆盖）
+)
+
+// 信号冲突处理策略
+const (
+	ConflictPolicyNone     = "none"     // 不处理，全部保留
+	ConflictPolicyNet      = "net"      // 同一股票同日方向相反的信号相互抵消
+	ConflictPolicyPriority = "priority" // 保留策略优先级更高的信号，同级保留先产生的信号
+)
+
+// SignalPolicy 用户的交易信号聚合规则，在信号保存前应用
+type SignalPolicy struct {
+	ID             uint      `gorm:"primaryKey" json:"-"`
+	UserID         uint      `gorm:"not null;uniqueIndex" json:"user_id"`
+	ConflictPolicy string    `gorm:"size:20;not null;default:'none'" json:"conflict_policy"` // none/net/priority
+	DedupHours     int       `gorm:"default:0" json:"dedup_hours"`                           // 该小时数内同一股票同方向的重复信号不再保存，0 表示不去重
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (SignalPolicy) TableName() string {
+	return "signal_policies"
+}
+
+// DefaultSignalPolicy 用户未配置时使用的规则：不处理冲突、不去重
+func DefaultSignalPolicy(userID uint) *SignalPolicy {
+	return &SignalPolicy{UserID: userID, ConflictPolicy: ConflictPolicyNone}
+}
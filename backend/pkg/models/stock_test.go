@@ -0,0 +1,18 @@
+package models
+
+import "testing"
+
+func TestStockCaps(t *testing.T) {
+	stock := &Stock{TotalShare: 1000, FloatShare: 400}
+	if got := stock.MarketCap(12.5); got == nil || *got != 12500 {
+		t.Errorf("MarketCap = %v, want 12500", got)
+	}
+	if got := stock.FloatCap(12.5); got == nil || *got != 5000 {
+		t.Errorf("FloatCap = %v, want 5000", got)
+	}
+
+	unknown := &Stock{}
+	if unknown.MarketCap(12.5) != nil || unknown.FloatCap(12.5) != nil {
+		t.Error("股本未知时市值应为空")
+	}
+}
@@ -0,0 +1,126 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// 同步任务类型
+const (
+	SyncJobStockList    = "stock_list"
+	SyncJobStockMeta    = "stock_metadata"
+	SyncJobDailyBars    = "daily_bars"
+	SyncJobIndicators   = "indicators" // 回补技术指标，依赖于触发它的日K线同步或K线修正任务
+	SyncJobMinuteBars   = "minute_bars"
+	SyncJobMoneyFlow    = "money_flow"
+	SyncJobDragonTiger  = "dragon_tiger"
+	SyncJobNews         = "news"
+	SyncJobFinancials   = "financial_reports"
+	SyncJobFactors      = "factor_scores"
+	SyncJobUniverses    = "universe_snapshots"
+	SyncJobRiskWarnings = "risk_warnings"
+	SyncJobSnapshot     = "snapshot_export"
+	SyncJobDedupeBars   = "dedupe_bars"
+	SyncJobQualityScore = "quality_scores"
+	SyncJobBasketValues = "basket_values"
+	SyncJobCalendar     = "corporate_events"
+	SyncJobMacro        = "macro_series"
+	SyncJobStockConnect = "stock_connect"
+	SyncJobIncremental  = "incremental"
+
+	SyncJobRestatement    = "restatement"     // 历史K线修正后使下游数据失效，下游任务依赖于它
+	SyncJobMarketCache    = "market_cache"    // 删除受K线修正影响的行情缓存
+	SyncJobStaleBacktests = "stale_backtests" // 标记使用了被修正K线的回测结果为过期
+)
+
+// 同步任务状态
+const (
+	SyncStatusQueued  = "queued" // 已登记、尚未开始执行的下游任务
+	SyncStatusRunning = "running"
+	SyncStatusSuccess = "success"
+	SyncStatusPartial = "partial" // 逐只股票处理的任务中部分股票失败
+	SyncStatusFailed  = "failed"
+)
+
+// SyncJob 数据同步任务记录
+type SyncJob struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	JobType    string      `gorm:"size:30;not null;index:idx_sync_jobs_lookup" json:"job_type"`
+	Source     string      `gorm:"size:50;not null" json:"source"`                   // 数据来源，如 akshare
+	Symbol     string      `gorm:"size:10;index:idx_sync_jobs_lookup" json:"symbol"` // 为空表示全市场任务
+	Exchange   string      `gorm:"size:10;index:idx_sync_jobs_lookup" json:"exchange"`
+	Status     string      `gorm:"size:20;not null;default:'running'" json:"status"`
+	Records    int         `json:"records"`
+	Error      string      `json:"error,omitempty"`
+	Report     *SyncReport `gorm:"type:jsonb;serializer:json" json:"report,omitempty"` // 逐只股票处理的任务的结果明细
+	ParentID   *uint       `gorm:"index" json:"parent_id,omitempty"`                   // 触发本任务的上游任务，如指标回补所依赖的日K线同步
+	RangeStart *time.Time  `json:"range_start,omitempty"`                              // 下游任务重算的区间，排队期间再次登记时取并集
+	RangeEnd   *time.Time  `json:"range_end,omitempty"`
+	Fence      int64       `gorm:"not null;default:0" json:"fence,omitempty"` // 定时任务主节点的隔离令牌，手动触发的任务为 0
+	StartedAt  time.Time   `gorm:"not null" json:"started_at"`                // 排队中的下游任务为登记时间
+	FinishedAt *time.Time  `json:"finished_at"`
+	Dependents []*SyncJob  `gorm:"-" json:"dependents,omitempty"` // 依赖于本任务的下游任务，仅任务详情返回
+}
+
+// TableName 指定表名
+func (SyncJob) TableName() string {
+	return "data_sync_jobs"
+}
+
+// SyncFailure 单只股票的失败原因
+type SyncFailure struct {
+	Symbol   string `json:"symbol"`
+	Exchange string `json:"exchange"`
+	Reason   string `json:"reason"`
+}
+
+// SyncReport 逐只股票处理的同步任务（全市场K线、增量更新、全市场财报）的结果
+// 任务中途取消时 Succeeded 与 Failed 之和小于 Total。
+type SyncReport struct {
+	Total      int           `json:"total"`
+	Succeeded  []string      `json:"succeeded"` // 成功的股票代码，如 600000.SH
+	Failed     []SyncFailure `json:"failed"`
+	Records    int           `json:"records"` // 写入的记录数，如K线条数
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// NewSyncReport 创建待处理 total 只股票的结果
+func NewSyncReport(total int) *SyncReport {
+	return &SyncReport{Total: total, Succeeded: []string{}, Failed: []SyncFailure{}}
+}
+
+// Succeed 记录一只股票成功及写入的记录数
+func (r *SyncReport) Succeed(symbol, exchange string, records int) {
+	r.Succeeded = append(r.Succeeded, symbol+"."+exchange)
+	r.Records += records
+}
+
+// Fail 记录一只股票失败及原因
+func (r *SyncReport) Fail(symbol, exchange string, err error) {
+	r.Failed = append(r.Failed, SyncFailure{Symbol: symbol, Exchange: exchange, Reason: err.Error()})
+}
+
+// Status 全部成功为 success，全部失败为 failed，其余为 partial；没有处理任何股票时为 success
+func (r *SyncReport) Status() string {
+	switch {
+	case len(r.Failed) == 0:
+		return SyncStatusSuccess
+	case len(r.Succeeded) == 0:
+		return SyncStatusFailed
+	default:
+		return SyncStatusPartial
+	}
+}
+
+// Err 全部股票都失败时返回错误，部分成功视为任务成功
+func (r *SyncReport) Err() error {
+	if r.Status() != SyncStatusFailed {
+		return nil
+	}
+	return fmt.Errorf("%d 只股票全部同步失败，首个错误: %s", len(r.Failed), r.Failed[0].Reason)
+}
+
+// Summary 结果摘要，写入异步任务的 message
+func (r *SyncReport) Summary() string {
+	return fmt.Sprintf("成功 %d 只，失败 %d 只（共 %d 只），写入 %d 条", len(r.Succeeded), len(r.Failed), r.Total, r.Records)
+}
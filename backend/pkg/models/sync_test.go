@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSyncReportStatus(t *testing.T) {
+	failure := errors.New("HTTP 502")
+
+	report := NewSyncReport(3)
+	if got := report.Status(); got != SyncStatusSuccess {
+		t.Errorf("empty report status = %s, want %s", got, SyncStatusSuccess)
+	}
+
+	report.Succeed("600000", "SH", 5)
+	report.Succeed("000001", "SZ", 0)
+	if got := report.Status(); got != SyncStatusSuccess || report.Err() != nil {
+		t.Errorf("status = %s, err = %v, want success", got, report.Err())
+	}
+	if report.Records != 5 || report.Succeeded[0] != "600000.SH" {
+		t.Errorf("report = %+v", report)
+	}
+
+	report.Fail("600519", "SH", failure)
+	if got := report.Status(); got != SyncStatusPartial || report.Err() != nil {
+		t.Errorf("status = %s, err = %v, want partial without error", got, report.Err())
+	}
+	if f := report.Failed[0]; f.Symbol != "600519" || f.Exchange != "SH" || f.Reason != "HTTP 502" {
+		t.Errorf("failure = %+v", f)
+	}
+
+	failedAll := NewSyncReport(2)
+	failedAll.Fail("600000", "SH", failure)
+	failedAll.Fail("000001", "SZ", failure)
+	if got := failedAll.Status(); got != SyncStatusFailed || failedAll.Err() == nil {
+		t.Errorf("status = %s, err = %v, want failed with error", got, failedAll.Err())
+	}
+}
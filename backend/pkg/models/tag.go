@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag 用户自定义标签模型，可同时用于策略与自选股分组
+type Tag struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	Color     string    `gorm:"size:20" json:"color"` // 前端展示颜色，如 #2563eb
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagUsage 标签及其使用次数
+type TagUsage struct {
+	Tag
+	StrategyCount  int64 `json:"strategy_count"`
+	WatchlistCount int64 `json:"watchlist_count"`
+}
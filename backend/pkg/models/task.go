@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+)
+
+// 异步任务类型
+const (
+	TaskTypeSync     = "sync"     // 数据同步、导入与快照导出（data-service）
+	TaskTypeBacktest = "backtest" // 回测（backtest-service）
+	TaskTypeAdmin    = "admin"    // 管理员触发的后台运维任务，结果见审计日志（data-service）
+)
+
+// 异步任务状态
+const (
+	TaskStatusRunning   = "running"
+	TaskStatusSucceeded = "succeeded"
+	TaskStatusFailed    = "failed"
+	TaskStatusCanceled  = "canceled" // 被取消接口或服务关闭中止
+)
+
+// Task 长时间运行的异步任务，各服务通过 pkg/jobs 登记，统一由 GET /api/v1/tasks/:id 查询状态
+type Task struct {
+	ID         string     `gorm:"primaryKey;size:36" json:"id"`
+	Type       string     `gorm:"size:30;not null;index:idx_tasks_owner" json:"type"`
+	Name       string     `gorm:"size:50" json:"name"`                            // 任务细分，如同步任务类型 daily_bars
+	Service    string     `gorm:"size:30;not null" json:"service"`                // 执行任务的服务
+	OwnerID    uint       `gorm:"not null;index:idx_tasks_owner" json:"owner_id"` // 发起任务的用户，0 表示系统任务
+	Status     string     `gorm:"size:20;not null" json:"status"`
+	Progress   float64    `gorm:"not null;default:0" json:"progress"`   // 0-100
+	Message    string     `json:"message,omitempty"`                    // 当前阶段说明
+	ResultType string     `gorm:"size:30" json:"result_type,omitempty"` // 结果所在的资源类型，如 backtest、sync_job
+	ResultID   string     `gorm:"size:64" json:"result_id,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+}
+
+// TableName 指定表名
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// Finished 任务是否已结束
+func (t *Task) Finished() bool {
+	return t.Status == TaskStatusSucceeded || t.Status == TaskStatusFailed || t.Status == TaskStatusCanceled
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// 墓碑记录的实体类型
+const (
+	TombstoneStrategy = "strategy"
+)
+
+// 墓碑记录的原因
+const (
+	TombstoneDeleted  = "deleted"  // 记录已删除
+	TombstoneUnshared = "unshared" // 公开策略改为私有，其他用户不再可见
+)
+
+// Tombstone 已删除或不再可见的记录，供客户端增量同步（updated_since）获知需要移除的本地数据
+// 由数据库触发器写入，见 init_postgres.sql「增量同步」。
+type Tombstone struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Entity    string    `gorm:"size:20;not null;index:idx_tombstones_entity" json:"entity"`
+	EntityID  uint      `gorm:"not null" json:"entity_id"`
+	OwnerID   uint      `gorm:"not null;index:idx_tombstones_entity" json:"owner_id"`
+	Public    bool      `gorm:"not null;default:false" json:"public"` // 事件发生前其他用户是否可见
+	Reason    string    `gorm:"size:10;not null" json:"reason"`
+	DeletedAt time.Time `gorm:"not null;index:idx_tombstones_entity" json:"deleted_at"`
+}
+
+// TableName 指定表名
+func (Tombstone) TableName() string {
+	return "sync_tombstones"
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// 股票池类型
+const (
+	UniverseTypeIndex    = "index"    // 指数成分股，Source 为指数代码，如 000300.SH
+	UniverseTypeScreener = "screener" // 选股器结果，Criteria 为筛选条件
+	UniverseTypeManual   = "manual"   // 手工维护的股票列表
+)
+
+// Universe 股票池定义，成分按交易日快照保存，回测可引用股票池以避免幸存者偏差
+type Universe struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	UserID      uint        `gorm:"not null;index" json:"user_id"`
+	Name        string      `gorm:"size:100;not null" json:"name"`
+	Description string      `json:"description"`
+	Type        string      `gorm:"size:20;not null" json:"type"`  // index/screener/manual
+	Source      string      `gorm:"size:20" json:"source"`         // 指数代码 symbol.exchange（index 类型）
+	Criteria    string      `gorm:"type:jsonb" json:"criteria"`    // 选股条件 JSON（screener 类型）
+	MaxMembers  int         `json:"max_members"`                   // 选股结果按排序取前 N 只，0 表示不限制
+	Symbols     StringArray `gorm:"type:text[]" json:"symbols"`    // 股票列表（manual 类型）
+	IsActive    bool        `gorm:"default:true" json:"is_active"` // 是否参与每日快照
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Universe) TableName() string {
+	return "universes"
+}
+
+// SymbolList 股票池的股票列表（manual 类型）
+func (u *Universe) SymbolList() []string {
+	return u.Symbols
+}
+
+// UniverseMember 股票池在某交易日的成分
+type UniverseMember struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	UniverseID uint      `gorm:"not null;uniqueIndex:idx_universe_member_unique" json:"universe_id"`
+	TradeDate  time.Time `gorm:"type:date;not null;uniqueIndex:idx_universe_member_unique" json:"trade_date"`
+	Symbol     string    `gorm:"size:10;not null;uniqueIndex:idx_universe_member_unique" json:"symbol"`
+	Exchange   string    `gorm:"size:10;not null;uniqueIndex:idx_universe_member_unique" json:"exchange"`
+	Weight     float64   `json:"weight,omitempty"` // 指数权重，其他类型为 0
+}
+
+// TableName 指定表名
+func (UniverseMember) TableName() string {
+	return "universe_members"
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// UsageDaily 用户每日用量，网关与回测服务按用户、自然日累加写入，用于用量报表与计费导出
+type UsageDaily struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	UserID          uint      `gorm:"not null;uniqueIndex:idx_usage_daily_user_date" json:"user_id"`
+	Date            time.Time `gorm:"type:date;not null;uniqueIndex:idx_usage_daily_user_date;index:idx_usage_daily_date" json:"date"`
+	APICalls        int64     `gorm:"not null;default:0" json:"api_calls"`        // 经网关的已认证请求数
+	BytesDownloaded int64     `gorm:"not null;default:0" json:"bytes_downloaded"` // 网关返回的响应体字节数
+	BacktestSeconds float64   `gorm:"not null;default:0" json:"backtest_seconds"` // 回测任务执行时长（秒）
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UsageDaily) TableName() string {
+	return "usage_daily"
+}
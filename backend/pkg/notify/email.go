@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// Email 通过 SMTP 发送邮件通知
+type Email struct {
+	addr string
+	auth smtp.Auth
+	from string
+	to   []string
+}
+
+// NewEmail 创建邮件渠道，未配置用户名时不做 SMTP 认证
+func NewEmail(cfg *config.NotifyConfig) *Email {
+	e := &Email{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		from: cfg.EmailFrom,
+		to:   cfg.EmailTo,
+	}
+	if e.from == "" {
+		e.from = cfg.SMTPUsername
+	}
+	if cfg.SMTPUsername != "" {
+		e.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return e
+}
+
+// Name 渠道名称
+func (e *Email) Name() string { return "email" }
+
+// Send 发送纯文本邮件
+// net/smtp 不支持 context，取消只在发送前生效。
+func (e *Email) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(e.addr, e.auth, e.from, e.to, e.build(msg))
+}
+
+// build 生成邮件内容，标题按 RFC 2047 编码以支持中文
+func (e *Email) build(msg Message) []byte {
+	subject := fmt.Sprintf("[%s] %s", levelLabel(msg.Level), msg.Title)
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", e.from)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(e.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", msg.Time.Format("Mon, 02 Jan 2006 15:04:05 -0700"))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Text, "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,92 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+// 通知级别
+const (
+	LevelWarning  = "warning"
+	LevelCritical = "critical"
+	LevelResolved = "resolved" // 告警恢复
+)
+
+// Message 运维通知
+type Message struct {
+	Title string
+	Text  string
+	Level string
+	Time  time.Time
+}
+
+// Notifier 通知渠道
+type Notifier interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Dispatcher 将通知发送到全部已配置的渠道，单个渠道失败不影响其余渠道
+type Dispatcher struct {
+	channels []Notifier
+}
+
+// NewDispatcher 创建通知分发器
+func NewDispatcher(channels ...Notifier) *Dispatcher {
+	return &Dispatcher{channels: channels}
+}
+
+// FromConfig 按配置创建通知渠道，未配置的渠道跳过
+func FromConfig(cfg *config.NotifyConfig) *Dispatcher {
+	client := &http.Client{Timeout: 10 * time.Second}
+	var channels []Notifier
+	if cfg.WebhookURL != "" {
+		channels = append(channels, NewWebhook(cfg.WebhookURL, client))
+	}
+	if cfg.DingTalkURL != "" {
+		channels = append(channels, NewDingTalk(cfg.DingTalkURL, cfg.DingTalkSecret, client))
+	}
+	if cfg.WeComURL != "" {
+		channels = append(channels, NewWeCom(cfg.WeComURL, client))
+	}
+	if cfg.SMTPHost != "" && len(cfg.EmailTo) > 0 {
+		channels = append(channels, NewEmail(cfg))
+	}
+	return NewDispatcher(channels...)
+}
+
+// Enabled 是否配置了至少一个渠道
+func (d *Dispatcher) Enabled() bool {
+	return len(d.channels) > 0
+}
+
+// Send 依次发送到各渠道，返回全部失败渠道的错误
+func (d *Dispatcher) Send(ctx context.Context, msg Message) error {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	var errs []error
+	for _, ch := range d.channels {
+		if err := ch.Send(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ch.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// levelLabel 通知级别的中文标签
+func levelLabel(level string) string {
+	switch level {
+	case LevelCritical:
+		return "严重"
+	case LevelResolved:
+		return "恢复"
+	default:
+		return "警告"
+	}
+}
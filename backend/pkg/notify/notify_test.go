@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+func TestDispatcher(t *testing.T) {
+	var received webhookPayload
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("解析请求体失败: %v", err)
+		}
+	}))
+	defer webhook.Close()
+
+	dingtalk := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errcode":310000,"errmsg":"keywords not in content"}`))
+	}))
+	defer dingtalk.Close()
+
+	d := FromConfig(&config.NotifyConfig{WebhookURL: webhook.URL, DingTalkURL: dingtalk.URL})
+	if !d.Enabled() {
+		t.Fatal("配置了渠道时应启用")
+	}
+	err := d.Send(context.Background(), Message{Title: "增量同步连续失败", Text: "连续失败 3 次", Level: LevelCritical})
+
+	if received.Title != "增量同步连续失败" || received.Level != LevelCritical || received.Time.IsZero() {
+		t.Errorf("webhook 收到 %+v", received)
+	}
+	// 钉钉返回业务错误时整体返回错误，但不影响 webhook 发送
+	if err == nil || !strings.Contains(err.Error(), "dingtalk") {
+		t.Errorf("err = %v, want dingtalk 错误", err)
+	}
+
+	if FromConfig(&config.NotifyConfig{SMTPHost: "smtp.example.com"}).Enabled() {
+		t.Error("未配置收件人时不应启用邮件渠道")
+	}
+}
+
+func TestEmailBuild(t *testing.T) {
+	e := NewEmail(&config.NotifyConfig{SMTPHost: "smtp.example.com", SMTPPort: 465,
+		SMTPUsername: "ops@example.com", EmailTo: []string{"a@example.com", "b@example.com"}})
+	if e.addr != "smtp.example.com:465" || e.from != "ops@example.com" {
+		t.Fatalf("addr = %s, from = %s", e.addr, e.from)
+	}
+
+	data := string(e.build(Message{Title: "数据滞后", Text: "第一行\n第二行", Level: LevelWarning,
+		Time: time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)}))
+	for _, want := range []string{
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: =?UTF-8?b?",
+		"\r\n\r\n第一行\r\n第二行\r\n",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("邮件内容缺少 %q:\n%s", want, data)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+)
+
+// ============ 钉钉 / 企业微信群机器人 ============
+
+// 钉钉与企业微信机器人均限制每个机器人每分钟最多发送 20 条消息，超过后一段时间内拒绝发送
+const (
+	robotRateLimit  = 20
+	robotRateWindow = time.Minute
+)
+
+// 机器人 markdown 消息模板
+var (
+	dingTalkTemplate = template.Must(template.New("dingtalk").Funcs(templateFuncs).Parse(
+		"### {{label .Level}}：{{.Title}}\n\n{{.Text}}\n\n###### {{.Time.Format \"2006-01-02 15:04:05\"}}"))
+	weComTemplate = template.Must(template.New("wecom").Funcs(templateFuncs).Parse(
+		"### <font color=\"{{color .Level}}\">{{label .Level}}</font> {{.Title}}\n{{.Text}}\n" +
+			"> <font color=\"comment\">{{.Time.Format \"2006-01-02 15:04:05\"}}</font>"))
+)
+
+var templateFuncs = template.FuncMap{
+	"label": levelLabel,
+	"color": weComColor,
+}
+
+// DingTalk 钉钉群机器人，发送 markdown 消息
+type DingTalk struct {
+	url     string
+	secret  string // 加签密钥，为空时不签名（机器人使用关键词或 IP 白名单校验）
+	client  *http.Client
+	limiter *windowLimiter
+}
+
+// NewDingTalk 创建钉钉机器人渠道，url 为带 access_token 的完整 Webhook 地址
+func NewDingTalk(url, secret string, client *http.Client) *DingTalk {
+	return &DingTalk{url: url, secret: secret, client: client, limiter: newWindowLimiter(robotRateLimit, robotRateWindow)}
+}
+
+// Name 渠道名称
+func (d *DingTalk) Name() string { return "dingtalk" }
+
+// Send 发送 markdown 消息，超过频率限制时等待
+func (d *DingTalk) Send(ctx context.Context, msg Message) error {
+	text, err := render(dingTalkTemplate, msg)
+	if err != nil {
+		return err
+	}
+	target, err := d.signedURL(time.Now())
+	if err != nil {
+		return err
+	}
+	if err := d.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return postRobot(ctx, d.client, target, map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"title": msg.Title, "text": text},
+	})
+}
+
+// signedURL 加签：对 "timestamp\nsecret" 做 HmacSHA256 后 Base64，与毫秒时间戳一起附加到 URL
+func (d *DingTalk) signedURL(now time.Time) (string, error) {
+	if d.secret == "" {
+		return d.url, nil
+	}
+	u, err := url.Parse(d.url)
+	if err != nil {
+		return "", fmt.Errorf("钉钉 Webhook 地址无效: %w", err)
+	}
+	timestamp := strconv.FormatInt(now.UnixMilli(), 10)
+	mac := hmac.New(sha256.New, []byte(d.secret))
+	mac.Write([]byte(timestamp + "\n" + d.secret))
+
+	query := u.Query()
+	query.Set("timestamp", timestamp)
+	query.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// WeCom 企业微信群机器人，发送 markdown 消息
+type WeCom struct {
+	url     string
+	client  *http.Client
+	limiter *windowLimiter
+}
+
+// NewWeCom 创建企业微信机器人渠道，url 为带 key 的完整 Webhook 地址
+func NewWeCom(url string, client *http.Client) *WeCom {
+	return &WeCom{url: url, client: client, limiter: newWindowLimiter(robotRateLimit, robotRateWindow)}
+}
+
+// Name 渠道名称
+func (w *WeCom) Name() string { return "wecom" }
+
+// Send 发送 markdown 消息，超过频率限制时等待
+func (w *WeCom) Send(ctx context.Context, msg Message) error {
+	content, err := render(weComTemplate, msg)
+	if err != nil {
+		return err
+	}
+	if err := w.limiter.Wait(ctx); err != nil {
+		return err
+	}
+	return postRobot(ctx, w.client, w.url, map[string]interface{}{
+		"msgtype":  "markdown",
+		"markdown": map[string]string{"content": content},
+	})
+}
+
+// weComColor 企业微信 markdown 支持的字体颜色：info 绿色、warning 橙红色、comment 灰色
+func weComColor(level string) string {
+	if level == LevelResolved {
+		return "info"
+	}
+	return "warning"
+}
+
+// render 按模板生成 markdown 文本
+func render(tmpl *template.Template, msg Message) (string, error) {
+	if msg.Time.IsZero() {
+		msg.Time = time.Now()
+	}
+	var b strings.Builder
+	if err := tmpl.Execute(&b, msg); err != nil {
+		return "", fmt.Errorf("生成消息失败: %w", err)
+	}
+	return b.String(), nil
+}
+
+// postRobot 发送机器人消息，钉钉与企业微信均以 HTTP 200 + errcode 返回业务错误
+func postRobot(ctx context.Context, client *http.Client, url string, payload interface{}) error {
+	body, err := postJSON(ctx, client, url, payload)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("errcode=%d: %s", result.ErrCode, result.ErrMsg)
+	}
+	return nil
+}
+
+// windowLimiter 滑动窗口限流：任意 window 时长内最多发送 limit 条
+type windowLimiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	sent   []time.Time // 窗口内的发送时间，按时间升序
+}
+
+func newWindowLimiter(limit int, window time.Duration) *windowLimiter {
+	return &windowLimiter{limit: limit, window: window}
+}
+
+// reserve 有空余额度时占用并返回 0，否则返回需要等待的时间
+func (l *windowLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expired := 0
+	for expired < len(l.sent) && now.Sub(l.sent[expired]) >= l.window {
+		expired++
+	}
+	l.sent = l.sent[expired:]
+	if len(l.sent) >= l.limit {
+		return l.sent[0].Add(l.window).Sub(now)
+	}
+	l.sent = append(l.sent, now)
+	return 0
+}
+
+// Wait 等待到有空余额度，ctx 结束时返回其错误
+func (l *windowLimiter) Wait(ctx context.Context) error {
+	for {
+		wait := l.reserve(time.Now())
+		if wait <= 0 {
+			return nil
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDingTalkSigned(t *testing.T) {
+	const secret = "SEC0123456789"
+	var body struct {
+		MsgType  string `json:"msgtype"`
+		Markdown struct {
+			Title string `json:"title"`
+			Text  string `json:"text"`
+		} `json:"markdown"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("access_token") != "abc" {
+			t.Errorf("access_token = %q", query.Get("access_token"))
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(query.Get("timestamp") + "\n" + secret))
+		if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); query.Get("sign") != want {
+			t.Errorf("sign = %q, want %q", query.Get("sign"), want)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	d := NewDingTalk(server.URL+"/robot/send?access_token=abc", secret, server.Client())
+	msg := Message{Title: "全市场数据滞后", Text: "30/100 只股票滞后", Level: LevelCritical,
+		Time: time.Date(2024, 3, 1, 2, 0, 0, 0, time.Local)}
+	if err := d.Send(context.Background(), msg); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if body.MsgType != "markdown" || body.Markdown.Title != "全市场数据滞后" {
+		t.Errorf("body = %+v", body)
+	}
+	want := "### 严重：全市场数据滞后\n\n30/100 只股票滞后\n\n###### 2024-03-01 02:00:00"
+	if body.Markdown.Text != want {
+		t.Errorf("text = %q, want %q", body.Markdown.Text, want)
+	}
+}
+
+func TestWeCom(t *testing.T) {
+	var content string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Markdown struct {
+				Content string `json:"content"`
+			} `json:"markdown"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		content = body.Markdown.Content
+		w.Write([]byte(`{"errcode":93000,"errmsg":"invalid webhook url"}`))
+	}))
+	defer server.Close()
+
+	err := NewWeCom(server.URL, server.Client()).Send(context.Background(), Message{Title: "告警恢复", Text: "增量同步已恢复", Level: LevelResolved})
+	if err == nil || !strings.Contains(err.Error(), "93000") {
+		t.Errorf("err = %v, want errcode 93000", err)
+	}
+	if !strings.HasPrefix(content, `### <font color="info">恢复</font> 告警恢复`) {
+		t.Errorf("content = %q", content)
+	}
+}
+
+func TestWindowLimiter(t *testing.T) {
+	l := newWindowLimiter(2, time.Minute)
+	start := time.Date(2024, 3, 1, 2, 0, 0, 0, time.UTC)
+
+	if l.reserve(start) != 0 || l.reserve(start.Add(10*time.Second)) != 0 {
+		t.Fatal("额度内应直接放行")
+	}
+	if wait := l.reserve(start.Add(20 * time.Second)); wait != 40*time.Second {
+		t.Errorf("wait = %v, want 40s", wait)
+	}
+	// 第一条移出窗口后放行
+	if wait := l.reserve(start.Add(time.Minute)); wait != 0 {
+		t.Errorf("wait = %v, want 0", wait)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	full := newWindowLimiter(1, time.Hour)
+	full.reserve(time.Now())
+	if err := full.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,74 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// ============ 通用 Webhook ============
+
+// Webhook 以 JSON POST 通知到任意 HTTP 接口
+type Webhook struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhook 创建通用 Webhook 渠道
+func NewWebhook(url string, client *http.Client) *Webhook {
+	return &Webhook{url: url, client: client}
+}
+
+// Name 渠道名称
+func (w *Webhook) Name() string { return "webhook" }
+
+// webhookPayload 通用 Webhook 的请求体
+type webhookPayload struct {
+	Title string    `json:"title"`
+	Text  string    `json:"text"`
+	Level string    `json:"level"`
+	Time  time.Time `json:"time"`
+}
+
+// Send 发送通知，非 2xx 响应视为失败
+func (w *Webhook) Send(ctx context.Context, msg Message) error {
+	_, err := postJSON(ctx, w.client, w.url, webhookPayload{
+		Title: msg.Title,
+		Text:  msg.Text,
+		Level: msg.Level,
+		Time:  msg.Time,
+	})
+	return err
+}
+
+// postJSON POST JSON 请求并返回响应体，非 2xx 响应视为失败
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}) ([]byte, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,273 @@
+package pairs
+
+import (
+	"math"
+
+	"stock-analysis-system/backend/pkg/progress"
+)
+
+// 信号动作
+const (
+	ActionOpenLong  = "open_long"  // 做多价差：买入 A、卖出 B
+	ActionOpenShort = "open_short" // 做空价差：卖出 A、买入 B
+	ActionClose     = "close"      // 价差回归，平仓
+	ActionStop      = "stop"       // 价差继续偏离超过止损阈值，平仓
+)
+
+// 交易方向
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// Leg 信号中的单腿指令
+type Leg struct {
+	Symbol string  `json:"symbol"` // symbol.exchange
+	Side   string  `json:"side"`   // buy | sell
+	Price  float64 `json:"price"`
+	Weight float64 `json:"weight"` // 相对 A 腿市值的比例，A 腿为 1，B 腿为对冲比率
+}
+
+// Signal 配对交易信号，同时包含两腿指令
+type Signal struct {
+	Date       string  `json:"date"`
+	Action     string  `json:"action"`
+	Direction  int     `json:"direction"` // 开仓或所平仓位方向：1 多价差，-1 空价差
+	ZScore     float64 `json:"zscore"`
+	HedgeRatio float64 `json:"hedge_ratio"`
+	Legs       []Leg   `json:"legs"`
+}
+
+// Signals 按 z-score 阈值生成开平仓信号
+// 空仓时 z <= -entry 做多价差、z >= entry 做空价差；持仓时 |z| <= exit 平仓，|z| >= stop 止损。
+func Signals(points []*Point, cfg *Config) []*Signal {
+	var signals []*Signal
+	position := 0
+	for _, p := range points {
+		if p.ZScore == nil {
+			continue
+		}
+		z := *p.ZScore
+
+		var action string
+		direction := position
+		switch {
+		case position == 0 && z <= -cfg.EntryZ:
+			action, direction = ActionOpenLong, 1
+		case position == 0 && z >= cfg.EntryZ:
+			action, direction = ActionOpenShort, -1
+		case position != 0 && cfg.StopZ > 0 && math.Abs(z) >= cfg.StopZ:
+			action = ActionStop
+		case position != 0 && math.Abs(z) <= cfg.ExitZ:
+			action = ActionClose
+		default:
+			continue
+		}
+
+		// 开仓按方向下单，平仓反向下单
+		sideA := SideBuy
+		if (action == ActionOpenShort) || ((action == ActionClose || action == ActionStop) && direction == 1) {
+			sideA = SideSell
+		}
+		sideB := SideSell
+		if sideA == SideSell {
+			sideB = SideBuy
+		}
+
+		fillA, fillB := fillPrices(p, cfg)
+		signals = append(signals, &Signal{
+			Date:       p.Date,
+			Action:     action,
+			Direction:  direction,
+			ZScore:     z,
+			HedgeRatio: p.HedgeRatio,
+			Legs: []Leg{
+				{Symbol: cfg.LegA, Side: sideA, Price: fillA, Weight: 1},
+				{Symbol: cfg.LegB, Side: sideB, Price: fillB, Weight: p.HedgeRatio},
+			},
+		})
+
+		if action == ActionOpenLong || action == ActionOpenShort {
+			position = direction
+		} else {
+			position = 0
+		}
+	}
+	return signals
+}
+
+// Trade 一次完整的开平仓
+type Trade struct {
+	OpenDate   string  `json:"open_date"`
+	CloseDate  string  `json:"close_date,omitempty"` // 回测结束时仍持仓则为空
+	Direction  int     `json:"direction"`
+	EntryZ     float64 `json:"entry_z"`
+	ExitZ      float64 `json:"exit_z"`
+	HedgeRatio float64 `json:"hedge_ratio"`
+	QuantityA  float64 `json:"quantity_a"` // 正数为多头，负数为空头
+	QuantityB  float64 `json:"quantity_b"`
+	PnL        float64 `json:"pnl"` // 扣除双边费用
+	Stopped    bool    `json:"stopped"`
+}
+
+// Blocked 因封板未能成交的信号
+type Blocked struct {
+	Date   string `json:"date"`
+	Action string `json:"action"`
+	Leg    string `json:"leg"`  // A 或 B
+	Side   string `json:"side"` // 被拒绝的方向：涨停买入或跌停卖出
+}
+
+// Result 配对交易回测结果
+type Result struct {
+	Dates   []string   `json:"dates"`
+	Equity  []float64  `json:"equity"`
+	Trades  []*Trade   `json:"trades"`
+	Signals []*Signal  `json:"signals"`
+	Blocked []*Blocked `json:"blocked,omitempty"`
+	Fees    float64    `json:"fees"`
+
+	FillFallbacks int `json:"fill_fallbacks,omitempty"` // 按 vwap/twap 成交但当日缺少分钟数据、改按收盘价成交的腿次
+}
+
+// blockedLeg 检查两腿按数量 qa、qb 下单时是否遇到涨停买入或跌停卖出，返回被拒绝的腿与方向
+func blockedLeg(p *Point, qa, qb float64) (leg, side string) {
+	for _, l := range []struct {
+		name string
+		qty  float64
+		lock int
+	}{{"A", qa, p.LockA}, {"B", qb, p.LockB}} {
+		if l.qty > 0 && l.lock == 1 {
+			return l.name, SideBuy
+		}
+		if l.qty < 0 && l.lock == -1 {
+			return l.name, SideSell
+		}
+	}
+	return "", ""
+}
+
+// Backtest 回测双腿价差交易，按 cfg.FillPrice 选择的成交价成交，权益按收盘价计算
+// 开仓时 A 腿市值为当前权益的一半，B 腿市值为 A 腿市值 × 对冲比率，方向相反；
+// 做空一腿按融券处理，卖出所得计入现金。
+// 任一腿需在涨停价买入或跌停价卖出时两腿均不成交：开仓信号放弃，平仓与止损顺延到下一个可成交的交易日。
+// 每处理完一个交易日向 reporter 报告进度与当日权益，reporter 可为 nil。
+func Backtest(points []*Point, cfg *Config, initialCapital float64, reporter progress.Reporter) *Result {
+	reporter = progress.OrNop(reporter)
+	signals := Signals(points, cfg)
+	byDate := make(map[string]*Signal, len(signals))
+	for _, s := range signals {
+		byDate[s.Date] = s
+	}
+
+	result := &Result{Signals: signals}
+	cash := initialCapital
+	var qa, qb, openEquity float64
+	var open *Trade
+	var pendingExit *Signal // 因封板顺延的平仓信号
+
+	for _, p := range points {
+		equity := cash + qa*p.PriceA + qb*p.PriceB
+		fa, fb := fillPrices(p, cfg)
+
+		s := byDate[p.Date]
+		if pendingExit != nil && (s == nil || s.Action == ActionOpenLong || s.Action == ActionOpenShort) {
+			s = &Signal{Date: p.Date, Action: pendingExit.Action, Direction: pendingExit.Direction, ZScore: pendingExit.ZScore}
+			if p.ZScore != nil {
+				s.ZScore = *p.ZScore
+			}
+		}
+		if s != nil {
+			switch s.Action {
+			case ActionOpenLong, ActionOpenShort:
+				if qa != 0 || qb != 0 {
+					break
+				}
+				notional := equity / 2
+				na := float64(s.Direction) * notional / fa
+				nb := -float64(s.Direction) * notional * s.HedgeRatio / fb
+				if leg, side := blockedLeg(p, na, nb); leg != "" {
+					result.Blocked = append(result.Blocked, &Blocked{Date: p.Date, Action: s.Action, Leg: leg, Side: side})
+					break
+				}
+				qa, qb = na, nb
+				result.FillFallbacks += fillFallbacks(p, cfg)
+				fee := cfg.FeeRate * (math.Abs(qa)*fa + math.Abs(qb)*fb)
+				cash -= qa*fa + qb*fb + fee
+				result.Fees += fee
+				openEquity = equity
+				open = &Trade{
+					OpenDate:   p.Date,
+					Direction:  s.Direction,
+					EntryZ:     s.ZScore,
+					HedgeRatio: s.HedgeRatio,
+					QuantityA:  qa,
+					QuantityB:  qb,
+				}
+
+			case ActionClose, ActionStop:
+				if leg, side := blockedLeg(p, -qa, -qb); leg != "" {
+					result.Blocked = append(result.Blocked, &Blocked{Date: p.Date, Action: s.Action, Leg: leg, Side: side})
+					pendingExit = s
+					break
+				}
+				pendingExit = nil
+				result.FillFallbacks += fillFallbacks(p, cfg)
+				fee := cfg.FeeRate * (math.Abs(qa)*fa + math.Abs(qb)*fb)
+				cash += qa*fa + qb*fb - fee
+				result.Fees += fee
+				qa, qb = 0, 0
+				if open != nil {
+					open.CloseDate = p.Date
+					open.ExitZ = s.ZScore
+					open.Stopped = s.Action == ActionStop
+					open.PnL = cash - openEquity
+					result.Trades = append(result.Trades, open)
+					open = nil
+				}
+			}
+			equity = cash + qa*p.PriceA + qb*p.PriceB
+		}
+
+		result.Dates = append(result.Dates, p.Date)
+		result.Equity = append(result.Equity, equity)
+		reporter.Bar(p.Date, len(result.Dates), len(points), equity)
+	}
+
+	// 未平仓的交易按最后收盘价计算浮动盈亏
+	if open != nil && len(result.Equity) > 0 {
+		open.PnL = result.Equity[len(result.Equity)-1] - openEquity
+		result.Trades = append(result.Trades, open)
+	}
+	return result
+}
+
+// fillPrices 两腿的成交价：按 vwap/twap 成交且有成交基准价时使用基准价，否则使用收盘价
+func fillPrices(p *Point, cfg *Config) (a, b float64) {
+	a, b = p.PriceA, p.PriceB
+	if cfg.FillPrice == FillClose {
+		return a, b
+	}
+	if p.FillA > 0 {
+		a = p.FillA
+	}
+	if p.FillB > 0 {
+		b = p.FillB
+	}
+	return a, b
+}
+
+// fillFallbacks 按 vwap/twap 成交时缺少成交基准价、改按收盘价成交的腿数
+func fillFallbacks(p *Point, cfg *Config) int {
+	if cfg.FillPrice == FillClose {
+		return 0
+	}
+	n := 0
+	if p.FillA <= 0 {
+		n++
+	}
+	if p.FillB <= 0 {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,254 @@
+// Package pairs 配对交易：对冲比率估计、价差 z-score 与双腿信号/回测
+package pairs
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// StrategyType 配对交易策略类型
+const StrategyType = "pair_trading"
+
+// 对冲比率估计方法
+const (
+	HedgeOLS     = "ols"     // 全样本 OLS 回归
+	HedgeRolling = "rolling" // 截至当日的滚动窗口 OLS 回归
+)
+
+// 回测成交价模型
+const (
+	FillClose = "close" // 按当日收盘价成交
+	FillVWAP  = "vwap"  // 按当日全天成交量加权均价成交
+	FillTWAP  = "twap"  // 按当日全天时间加权均价成交
+)
+
+// Config 配对交易参数，保存在策略 params 中
+type Config struct {
+	LegA        string  `json:"leg_a"`        // 第一腿 symbol.exchange
+	LegB        string  `json:"leg_b"`        // 第二腿 symbol.exchange
+	HedgeMethod string  `json:"hedge_method"` // ols | rolling，默认 rolling
+	HedgeWindow int     `json:"hedge_window"` // 滚动对冲比率窗口（交易日），默认 60
+	ZWindow     int     `json:"z_window"`     // 价差 z-score 窗口（交易日），默认 20
+	EntryZ      float64 `json:"entry_z"`      // 开仓阈值，默认 2
+	ExitZ       float64 `json:"exit_z"`       // 平仓阈值，默认 0.5
+	StopZ       float64 `json:"stop_z"`       // 止损阈值，默认 4，0 表示不止损
+	FeeRate     float64 `json:"fee_rate"`     // 单边交易费率，默认 0.0005
+	FillPrice   string  `json:"fill_price"`   // 回测成交价：close | vwap | twap，默认 close
+}
+
+// ParseConfig 解析策略参数并补齐默认值
+// params 中未指定两腿时使用策略股票列表的前两只。
+func ParseConfig(params string, symbols []string) (*Config, error) {
+	cfg := &Config{}
+	if strings.TrimSpace(params) != "" {
+		if err := json.Unmarshal([]byte(params), cfg); err != nil {
+			return nil, fmt.Errorf("配对交易参数格式错误: %w", err)
+		}
+	}
+	if cfg.LegA == "" && cfg.LegB == "" && len(symbols) == 2 {
+		cfg.LegA, cfg.LegB = symbols[0], symbols[1]
+	}
+	cfg.ApplyDefaults()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyDefaults 为未设置的参数补齐默认值
+func (c *Config) ApplyDefaults() {
+	if c.HedgeMethod == "" {
+		c.HedgeMethod = HedgeRolling
+	}
+	if c.HedgeWindow == 0 {
+		c.HedgeWindow = 60
+	}
+	if c.ZWindow == 0 {
+		c.ZWindow = 20
+	}
+	if c.EntryZ == 0 {
+		c.EntryZ = 2
+	}
+	if c.ExitZ == 0 {
+		c.ExitZ = 0.5
+	}
+	if c.StopZ == 0 {
+		c.StopZ = 4
+	}
+	if c.FeeRate == 0 {
+		c.FeeRate = 0.0005
+	}
+	if c.FillPrice == "" {
+		c.FillPrice = FillClose
+	}
+}
+
+// Validate 校验参数
+func (c *Config) Validate() error {
+	for _, leg := range []string{c.LegA, c.LegB} {
+		if _, _, ok := SplitLeg(leg); !ok {
+			return fmt.Errorf("配对交易两腿应为 symbol.exchange 格式: %q", leg)
+		}
+	}
+	if c.LegA == c.LegB {
+		return fmt.Errorf("配对交易两腿不能相同")
+	}
+	if c.HedgeMethod != HedgeOLS && c.HedgeMethod != HedgeRolling {
+		return fmt.Errorf("不支持的对冲比率估计方法: %s", c.HedgeMethod)
+	}
+	if c.HedgeWindow < 20 || c.HedgeWindow > 500 {
+		return fmt.Errorf("hedge_window 应在 20~500 之间")
+	}
+	if c.ZWindow < 5 || c.ZWindow > 250 {
+		return fmt.Errorf("z_window 应在 5~250 之间")
+	}
+	if c.ExitZ < 0 || c.EntryZ <= c.ExitZ {
+		return fmt.Errorf("开仓阈值 entry_z 应大于平仓阈值 exit_z")
+	}
+	if c.StopZ > 0 && c.StopZ <= c.EntryZ {
+		return fmt.Errorf("止损阈值 stop_z 应大于开仓阈值 entry_z")
+	}
+	if c.FeeRate < 0 || c.FeeRate > 0.01 {
+		return fmt.Errorf("fee_rate 应在 0~0.01 之间")
+	}
+	if c.FillPrice != FillClose && c.FillPrice != FillVWAP && c.FillPrice != FillTWAP {
+		return fmt.Errorf("不支持的成交价模型: %s", c.FillPrice)
+	}
+	return nil
+}
+
+// Warmup 产生第一个 z-score 所需的交易日数
+func (c *Config) Warmup() int {
+	if c.HedgeMethod == HedgeRolling {
+		return c.HedgeWindow + c.ZWindow - 1
+	}
+	return c.ZWindow
+}
+
+// SplitLeg 拆分 symbol.exchange
+func SplitLeg(leg string) (symbol, exchange string, ok bool) {
+	symbol, exchange, ok = strings.Cut(leg, ".")
+	if !ok || symbol == "" || exchange == "" || strings.Contains(exchange, ".") {
+		return "", "", false
+	}
+	return symbol, exchange, true
+}
+
+// HedgeRatio 最小二乘回归 y = alpha + beta*x，x 方差为0时 beta 为 0
+func HedgeRatio(y, x []float64) (beta, alpha float64) {
+	n := float64(len(x))
+	if len(x) == 0 || len(x) != len(y) {
+		return 0, 0
+	}
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= n
+	my /= n
+
+	var cov, vx float64
+	for i := range x {
+		dx := x[i] - mx
+		cov += dx * (y[i] - my)
+		vx += dx * dx
+	}
+	if vx > 0 {
+		beta = cov / vx
+	}
+	return beta, my - beta*mx
+}
+
+// Point 价差序列中的一个交易日
+type Point struct {
+	Date       string   `json:"date"`
+	PriceA     float64  `json:"price_a"`
+	PriceB     float64  `json:"price_b"`
+	HedgeRatio float64  `json:"hedge_ratio"`      // 对数价格回归系数，即每单位 A 对冲的 B 市值比例
+	Spread     float64  `json:"spread"`           // ln(A) - hedge_ratio*ln(B) - alpha
+	ZScore     *float64 `json:"zscore"`           // 窗口未满时为空
+	LockA      int      `json:"lock_a,omitempty"` // A 腿封板状态：1 收于涨停，-1 收于跌停（见 pricelimit）
+	LockB      int      `json:"lock_b,omitempty"`
+	FillA      float64  `json:"fill_a,omitempty"` // A 腿成交基准价（vwap/twap），当日缺少分钟数据时为 0，按收盘价成交
+	FillB      float64  `json:"fill_b,omitempty"`
+}
+
+
+// Spread 根据两腿 交易日 -> 收盘价 计算对数价差与 z-score，只使用两腿共同的交易日
+// rolling 方法在对冲比率窗口填满之前不输出。
+func Spread(a, b map[string]float64, cfg *Config) []*Point {
+	var dates []string
+	for date, price := range a {
+		if pb, ok := b[date]; ok && price > 0 && pb > 0 {
+			dates = append(dates, date)
+		}
+	}
+	sort.Strings(dates)
+
+	pa := make([]float64, len(dates))
+	pb := make([]float64, len(dates))
+	for i, date := range dates {
+		pa[i], pb[i] = a[date], b[date]
+	}
+	return SpreadAligned(dates, pa, pb, cfg)
+}
+
+// SpreadAligned 同 Spread，两腿收盘价已按共同交易日对齐（升序、均大于 0），如 columnar.Intersect 的结果
+func SpreadAligned(dates []string, a, b []float64, cfg *Config) []*Point {
+	points := SpreadSeries(a, b, cfg)
+	offset := len(dates) - len(points)
+	for k, p := range points {
+		p.Date = dates[offset+k]
+	}
+	return points
+}
+
+// SpreadSeries 同 SpreadAligned，但不填写 Date：返回的价差点是输入序列的末尾一段，
+// 第 k 个点对应输入下标 len(a)-len(points)+k，调用方按下标取交易日等与输入对齐的数据。
+func SpreadSeries(a, b []float64, cfg *Config) []*Point {
+	la := make([]float64, len(a))
+	lb := make([]float64, len(a))
+	for i := range a {
+		la[i], lb[i] = math.Log(a[i]), math.Log(b[i])
+	}
+
+	var points []*Point
+	fullBeta, fullAlpha := HedgeRatio(la, lb)
+	for i := range a {
+		beta, alpha := fullBeta, fullAlpha
+		if cfg.HedgeMethod == HedgeRolling {
+			if i+1 < cfg.HedgeWindow {
+				continue
+			}
+			beta, alpha = HedgeRatio(la[i+1-cfg.HedgeWindow:i+1], lb[i+1-cfg.HedgeWindow:i+1])
+		}
+		points = append(points, &Point{
+			PriceA:     a[i],
+			PriceB:     b[i],
+			HedgeRatio: beta,
+			Spread:     la[i] - beta*lb[i] - alpha,
+		})
+	}
+
+	for i := cfg.ZWindow - 1; i < len(points); i++ {
+		var sum, sq float64
+		for _, p := range points[i+1-cfg.ZWindow : i+1] {
+			sum += p.Spread
+		}
+		m := sum / float64(cfg.ZWindow)
+		for _, p := range points[i+1-cfg.ZWindow : i+1] {
+			sq += (p.Spread - m) * (p.Spread - m)
+		}
+		sd := math.Sqrt(sq / float64(cfg.ZWindow-1))
+		if sd == 0 {
+			continue
+		}
+		z := (points[i].Spread - m) / sd
+		points[i].ZScore = &z
+	}
+	return points
+}
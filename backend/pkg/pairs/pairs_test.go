@@ -0,0 +1,138 @@
+package pairs
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestHedgeRatio(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	y := make([]float64, len(x))
+	for i := range x {
+		y[i] = 2*x[i] + 1
+	}
+	beta, alpha := HedgeRatio(y, x)
+	if math.Abs(beta-2) > 1e-9 || math.Abs(alpha-1) > 1e-9 {
+		t.Errorf("回归系数应为 beta=2 alpha=1，实际 %v %v", beta, alpha)
+	}
+}
+
+func TestParseConfig(t *testing.T) {
+	cfg, err := ParseConfig(`{"entry_z": 2.5}`, []string{"600519.SH", "000858.SZ"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LegA != "600519.SH" || cfg.LegB != "000858.SZ" || cfg.EntryZ != 2.5 || cfg.HedgeMethod != HedgeRolling {
+		t.Errorf("参数解析或默认值错误: %+v", cfg)
+	}
+	if _, err := ParseConfig(`{"entry_z": 0.3}`, []string{"600519.SH", "000858.SZ"}); err == nil {
+		t.Error("开仓阈值小于平仓阈值时应报错")
+	}
+	if _, err := ParseConfig("", []string{"600519"}); err == nil {
+		t.Error("缺少两腿时应报错")
+	}
+}
+
+// 价差在第 30~32 日短暂偏离后回归：应做空价差并在回归后平仓获利
+func TestSignalsAndBacktest(t *testing.T) {
+	a := make(map[string]float64)
+	b := make(map[string]float64)
+	for i := 0; i < 60; i++ {
+		date := fmt.Sprintf("2024-01-%02d", i+1)
+		if i >= 31 {
+			date = fmt.Sprintf("2024-02-%02d", i-30)
+		}
+		pb := 100 * math.Exp(0.01*float64(i))
+		dev := 0.0
+		if i >= 30 && i <= 32 {
+			dev = 0.05
+		}
+		a[date] = pb * math.Exp(dev)
+		b[date] = pb
+	}
+
+	cfg := &Config{LegA: "A.SH", LegB: "B.SH", HedgeMethod: HedgeOLS, StopZ: 10}
+	cfg.ApplyDefaults()
+
+	points := Spread(a, b, cfg)
+	if len(points) != 60 {
+		t.Fatalf("OLS 方法应输出全部交易日，实际 %d", len(points))
+	}
+
+	signals := Signals(points, cfg)
+	if len(signals) != 2 || signals[0].Action != ActionOpenShort || signals[1].Action != ActionClose {
+		t.Fatalf("应先做空价差再平仓: %+v", signals)
+	}
+	if legs := signals[0].Legs; legs[0].Side != SideSell || legs[1].Side != SideBuy {
+		t.Errorf("做空价差应卖出 A、买入 B: %+v", legs)
+	}
+	if legs := signals[1].Legs; legs[0].Side != SideBuy || legs[1].Side != SideSell {
+		t.Errorf("平空价差应买入 A、卖出 B: %+v", legs)
+	}
+
+	var bars []string
+	result := Backtest(points, cfg, 100000, barRecorder(func(date string, done, total int, equity float64) {
+		if done != len(bars)+1 || total != len(points) {
+			t.Errorf("进度 %d/%d，应为 %d/%d", done, total, len(bars)+1, len(points))
+		}
+		bars = append(bars, date)
+	}))
+	if len(bars) != len(points) || bars[len(bars)-1] != points[len(points)-1].Date {
+		t.Errorf("应逐个交易日报告进度，实际 %d 次", len(bars))
+	}
+	if len(result.Trades) != 1 || result.Trades[0].PnL <= 0 {
+		t.Fatalf("价差回归应获利: %+v", result.Trades)
+	}
+	if last := result.Equity[len(result.Equity)-1]; math.Abs(last-100000-result.Trades[0].PnL) > 1e-6 {
+		t.Errorf("期末权益应等于初始资金加交易盈亏: %v", last)
+	}
+
+	// 平仓日 A 腿收于涨停：买入 A 被拒绝，平仓顺延到下一交易日
+	for _, p := range points {
+		if p.Date == signals[1].Date {
+			p.LockA = 1
+		}
+	}
+	locked := Backtest(points, cfg, 100000, nil)
+	if len(locked.Blocked) != 1 || locked.Blocked[0].Leg != "A" || locked.Blocked[0].Side != SideBuy {
+		t.Fatalf("涨停买入应被拒绝: %+v", locked.Blocked)
+	}
+	if len(locked.Trades) != 1 || locked.Trades[0].CloseDate <= signals[1].Date {
+		t.Errorf("平仓应顺延到涨停次日: %+v", locked.Trades)
+	}
+}
+
+// 开仓日 A 腿的 VWAP 高于收盘价：做空 A 按 VWAP 成交获利，平仓日缺少分钟数据按收盘价成交
+func TestBacktestFillPrice(t *testing.T) {
+	z := func(v float64) *float64 { return &v }
+	points := []*Point{
+		{Date: "2024-01-02", PriceA: 10, PriceB: 10, HedgeRatio: 1, ZScore: z(3), FillA: 11, FillB: 10},
+		{Date: "2024-01-03", PriceA: 10, PriceB: 10, HedgeRatio: 1, ZScore: z(0), FillB: 10},
+	}
+	cfg := &Config{LegA: "A.SH", LegB: "B.SH", FillPrice: FillVWAP}
+	cfg.ApplyDefaults()
+
+	result := Backtest(points, cfg, 100000, nil)
+	if legs := result.Signals[0].Legs; legs[0].Price != 11 || legs[1].Price != 10 {
+		t.Errorf("信号应使用成交基准价: %+v", legs)
+	}
+	if len(result.Trades) != 1 || result.Trades[0].PnL <= 0 {
+		t.Fatalf("按 VWAP 卖出 A 应获利: %+v", result.Trades)
+	}
+	if result.FillFallbacks != 1 {
+		t.Errorf("平仓日 A 腿应改按收盘价成交 1 次，实际 %d", result.FillFallbacks)
+	}
+
+	cfg.FillPrice = FillClose
+	if result := Backtest(points, cfg, 100000, nil); result.Trades[0].PnL >= 0 || result.FillFallbacks != 0 {
+		t.Errorf("按收盘价成交时只有手续费亏损: %+v", result.Trades[0])
+	}
+}
+
+// barRecorder 只关心交易日进度的 progress.Reporter
+type barRecorder func(date string, done, total int, equity float64)
+
+func (barRecorder) Symbol(string, int, int) {}
+
+func (r barRecorder) Bar(date string, done, total int, equity float64) { r(date, done, total, equity) }
@@ -0,0 +1,280 @@
+// Package portfolio 模拟交易组合记账与绩效分析
+package portfolio
+
+import (
+	"math"
+	"sort"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// dateLayout 交易日格式
+const dateLayout = "2006-01-02"
+
+// tradingDaysPerYear 年化使用的交易日数
+const tradingDaysPerYear = 252
+
+// Input 组合分析输入
+type Input struct {
+	InitialCash float64
+	Trades      []*models.PortfolioTrade      // 全部成交记录，无需预先排序
+	Closes      map[string]map[string]float64 // symbol.exchange -> 交易日 -> 收盘价
+	Benchmark   map[string]float64            // 基准指数 交易日 -> 收盘价
+	Industries  map[string]string             // symbol.exchange -> 行业
+	Start       string                        // 分析区间 YYYY-MM-DD
+	End         string
+}
+
+// DailyPoint 每日净值与盈亏
+type DailyPoint struct {
+	Date             string  `json:"date"`
+	Cash             float64 `json:"cash"`
+	MarketValue      float64 `json:"market_value"`
+	TotalValue       float64 `json:"total_value"`
+	PnL              float64 `json:"pnl"`               // 当日盈亏
+	Return           float64 `json:"return"`            // 当日收益率
+	CumulativeReturn float64 `json:"cumulative_return"` // 区间内时间加权累计收益率
+}
+
+// IndustryWeight 行业配置
+type IndustryWeight struct {
+	Industry    string  `json:"industry"`
+	MarketValue float64 `json:"market_value"`
+	Weight      float64 `json:"weight"`
+}
+
+// Allocation 资产配置
+type Allocation struct {
+	TotalValue float64           `json:"total_value"`
+	Cash       float64           `json:"cash"`
+	CashWeight float64           `json:"cash_weight"`
+	Industries []*IndustryWeight `json:"industries"`
+}
+
+// Gains 已实现与未实现盈亏
+type Gains struct {
+	RealizedPnL   float64     `json:"realized_pnl"`
+	UnrealizedPnL float64     `json:"unrealized_pnl"`
+	TotalPnL      float64     `json:"total_pnl"`
+	Fees          float64     `json:"fees"`
+	Positions     []*Position `json:"positions"`
+}
+
+// Exposure 相对基准的敞口
+type Exposure struct {
+	PortfolioReturn float64  `json:"portfolio_return"`
+	BenchmarkReturn *float64 `json:"benchmark_return"` // 基准数据不足时为 null
+	ExcessReturn    *float64 `json:"excess_return"`
+	Beta            *float64 `json:"beta"`
+	Correlation     *float64 `json:"correlation"`
+	NetExposure     float64  `json:"net_exposure"`  // 持仓市值 / 总资产
+	BetaExposure    *float64 `json:"beta_exposure"` // Beta × 净敞口
+}
+
+// Result 组合分析结果
+type Result struct {
+	Start              string        `json:"start"`
+	End                string        `json:"end"`
+	TimeWeightedReturn float64       `json:"time_weighted_return"`
+	AnnualizedReturn   float64       `json:"annualized_return"`
+	Series             []*DailyPoint `json:"series"`
+	Allocation         *Allocation   `json:"allocation"`
+	Gains              *Gains        `json:"gains"`
+	Exposure           *Exposure     `json:"exposure"`
+}
+
+// Analyze 按交易日回放成交记录，计算区间内的时间加权收益、每日盈亏、资产配置、
+// 已实现/未实现盈亏以及相对基准的敞口。
+// 晚于 End 的成交不计入；非交易日的成交计入下一个交易日。
+func Analyze(in Input) (*Result, error) {
+	trades := make([]*models.PortfolioTrade, 0, len(in.Trades))
+	for _, t := range in.Trades {
+		if t.TradedAt.Format(dateLayout) <= in.End {
+			trades = append(trades, t)
+		}
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].TradedAt.Before(trades[j].TradedAt) })
+
+	book := NewBook(in.InitialCash)
+	result := &Result{Start: in.Start, End: in.End}
+
+	prevValue := in.InitialCash
+	cumulative := 1.0
+	var returns []float64
+	var returnDays []string
+
+	next := 0
+	for _, day := range tradingDays(in) {
+		for next < len(trades) && trades[next].TradedAt.Format(dateLayout) <= day {
+			if err := book.Apply(trades[next]); err != nil {
+				return nil, err
+			}
+			next++
+		}
+		for key, closes := range in.Closes {
+			if price, ok := closes[day]; ok {
+				book.MarkPrice(key, price)
+			}
+		}
+
+		marketValue := book.MarketValue()
+		value := book.Cash + marketValue
+		if day >= in.Start {
+			var ret float64
+			if prevValue > 0 {
+				ret = value/prevValue - 1
+			}
+			cumulative *= 1 + ret
+			returns = append(returns, ret)
+			returnDays = append(returnDays, day)
+			result.Series = append(result.Series, &DailyPoint{
+				Date:             day,
+				Cash:             book.Cash,
+				MarketValue:      marketValue,
+				TotalValue:       value,
+				PnL:              value - prevValue,
+				Return:           ret,
+				CumulativeReturn: cumulative - 1,
+			})
+		}
+		prevValue = value
+	}
+
+	// 区间内没有交易日数据时，剩余成交按成交价记账
+	for ; next < len(trades); next++ {
+		if err := book.Apply(trades[next]); err != nil {
+			return nil, err
+		}
+	}
+
+	result.TimeWeightedReturn = cumulative - 1
+	if n := len(returns); n > 0 {
+		result.AnnualizedReturn = math.Pow(cumulative, float64(tradingDaysPerYear)/float64(n)) - 1
+	}
+
+	positions := book.Positions()
+	for _, pos := range positions {
+		pos.Industry = in.Industries[Key(pos.Symbol, pos.Exchange)]
+	}
+
+	result.Gains = gains(book, positions)
+	result.Allocation = allocation(book, positions)
+	result.Exposure = exposure(in.Benchmark, returns, returnDays, result.TimeWeightedReturn, result.Allocation)
+	return result, nil
+}
+
+// tradingDays 汇总行情与基准中出现的交易日（不晚于 End，升序）
+func tradingDays(in Input) []string {
+	set := make(map[string]struct{})
+	for _, closes := range in.Closes {
+		for day := range closes {
+			set[day] = struct{}{}
+		}
+	}
+	for day := range in.Benchmark {
+		set[day] = struct{}{}
+	}
+
+	days := make([]string, 0, len(set))
+	for day := range set {
+		if day <= in.End {
+			days = append(days, day)
+		}
+	}
+	sort.Strings(days)
+	return days
+}
+
+// gains 汇总已实现与未实现盈亏
+func gains(book *Book, positions []*Position) *Gains {
+	g := &Gains{Fees: book.Fees, Positions: positions}
+	for _, pos := range positions {
+		g.RealizedPnL += pos.RealizedPnL
+		g.UnrealizedPnL += pos.UnrealizedPnL
+	}
+	g.TotalPnL = g.RealizedPnL + g.UnrealizedPnL
+	return g
+}
+
+// allocation 按行业汇总当前持仓市值
+func allocation(book *Book, positions []*Position) *Allocation {
+	a := &Allocation{Cash: book.Cash, TotalValue: book.Cash + book.MarketValue()}
+
+	byIndustry := make(map[string]*IndustryWeight)
+	for _, pos := range positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		industry := pos.Industry
+		if industry == "" {
+			industry = "未分类"
+		}
+		w := byIndustry[industry]
+		if w == nil {
+			w = &IndustryWeight{Industry: industry}
+			byIndustry[industry] = w
+			a.Industries = append(a.Industries, w)
+		}
+		w.MarketValue += pos.MarketValue
+	}
+
+	if a.TotalValue > 0 {
+		a.CashWeight = a.Cash / a.TotalValue
+		for _, w := range a.Industries {
+			w.Weight = w.MarketValue / a.TotalValue
+		}
+	}
+	sort.Slice(a.Industries, func(i, j int) bool { return a.Industries[i].MarketValue > a.Industries[j].MarketValue })
+	return a
+}
+
+// exposure 计算组合相对基准的收益、Beta 与相关系数
+func exposure(benchmark map[string]float64, returns []float64, days []string, portfolioReturn float64, alloc *Allocation) *Exposure {
+	e := &Exposure{PortfolioReturn: portfolioReturn}
+	if alloc.TotalValue > 0 {
+		e.NetExposure = (alloc.TotalValue - alloc.Cash) / alloc.TotalValue
+	}
+
+	// 对齐组合与基准均有收盘价的交易日
+	var pr, br []float64
+	var first, last float64
+	prevClose := 0.0
+	for i, day := range days {
+		price, ok := benchmark[day]
+		if !ok {
+			continue
+		}
+		if first == 0 {
+			first = price
+		}
+		last = price
+		if prevClose > 0 {
+			pr = append(pr, returns[i])
+			br = append(br, price/prevClose-1)
+		}
+		prevClose = price
+	}
+	if first == 0 {
+		return e
+	}
+
+	benchReturn := last/first - 1
+	excess := portfolioReturn - benchReturn
+	e.BenchmarkReturn = &benchReturn
+	e.ExcessReturn = &excess
+
+	if len(br) < 2 {
+		return e
+	}
+	// 基准无波动时 Beta 无意义
+	if risk.Volatility(br) == 0 {
+		return e
+	}
+	beta, corr := risk.Beta(pr, br), risk.Correlation(pr, br)
+	betaExposure := beta * e.NetExposure
+	e.Beta = &beta
+	e.Correlation = &corr
+	e.BetaExposure = &betaExposure
+	return e
+}
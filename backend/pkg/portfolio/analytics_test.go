@@ -0,0 +1,82 @@
+package portfolio
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func trade(side string, qty int64, price float64, date string) *models.PortfolioTrade {
+	tradedAt, _ := time.Parse(dateLayout, date)
+	return &models.PortfolioTrade{Symbol: "600519", Exchange: "SH", Side: side, Quantity: qty, Price: price, TradedAt: tradedAt}
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAnalyze(t *testing.T) {
+	in := Input{
+		InitialCash: 10000,
+		Trades: []*models.PortfolioTrade{
+			trade(models.TradeSideBuy, 100, 50, "2024-01-02"),
+			trade(models.TradeSideSell, 50, 60, "2024-01-04"),
+		},
+		Closes: map[string]map[string]float64{
+			"600519.SH": {"2024-01-02": 50, "2024-01-03": 55, "2024-01-04": 60},
+		},
+		Benchmark:  map[string]float64{"2024-01-02": 100, "2024-01-03": 101, "2024-01-04": 103},
+		Industries: map[string]string{"600519.SH": "白酒"},
+		Start:      "2024-01-01",
+		End:        "2024-01-31",
+	}
+
+	r, err := Analyze(in)
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+
+	if len(r.Series) != 3 {
+		t.Fatalf("期望 3 个交易日，实际 %d", len(r.Series))
+	}
+	// 期末：现金 5000+3000，持仓 50 股 @60
+	last := r.Series[2]
+	if !almostEqual(last.TotalValue, 11000) {
+		t.Errorf("期末总资产应为 11000，实际 %v", last.TotalValue)
+	}
+	if !almostEqual(r.TimeWeightedReturn, 0.1) {
+		t.Errorf("时间加权收益应为 10%%，实际 %v", r.TimeWeightedReturn)
+	}
+	if !almostEqual(r.Gains.RealizedPnL, 500) || !almostEqual(r.Gains.UnrealizedPnL, 500) {
+		t.Errorf("已实现/未实现盈亏应为 500/500，实际 %v/%v", r.Gains.RealizedPnL, r.Gains.UnrealizedPnL)
+	}
+	if len(r.Allocation.Industries) != 1 || r.Allocation.Industries[0].Industry != "白酒" {
+		t.Errorf("行业配置错误: %+v", r.Allocation.Industries)
+	}
+	if r.Exposure.BenchmarkReturn == nil || !almostEqual(*r.Exposure.BenchmarkReturn, 0.03) {
+		t.Errorf("基准收益应为 3%%，实际 %v", r.Exposure.BenchmarkReturn)
+	}
+	if r.Exposure.Beta == nil {
+		t.Error("基准数据充足时应计算 Beta")
+	}
+}
+
+func TestBookRejectsOversell(t *testing.T) {
+	book := NewBook(10000)
+	if err := book.Apply(trade(models.TradeSideBuy, 100, 50, "2024-01-02")); err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	err := book.Apply(trade(models.TradeSideSell, 200, 50, "2024-01-03"))
+	if err == nil || !strings.Contains(err.Error(), "持仓不足") {
+		t.Fatalf("期望持仓不足错误，实际: %v", err)
+	}
+	if err := book.Apply(trade(models.TradeSideBuy, 1000, 50, "2024-01-03")); err == nil {
+		t.Fatal("资金不足时应返回错误")
+	}
+	if book.Quantity("600519", "SH") != 100 || !almostEqual(book.Cash, 5000) {
+		t.Errorf("失败的成交不应修改账本: 持仓 %d 现金 %v", book.Quantity("600519", "SH"), book.Cash)
+	}
+}
@@ -0,0 +1,138 @@
+package portfolio
+
+import (
+	"fmt"
+	"sort"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// Key 持仓键 symbol.exchange
+func Key(symbol, exchange string) string {
+	return symbol + "." + exchange
+}
+
+// Position 单只股票持仓
+type Position struct {
+	Symbol        string  `json:"symbol"`
+	Exchange      string  `json:"exchange"`
+	Industry      string  `json:"industry,omitempty"`
+	Quantity      int64   `json:"quantity"`
+	AvgCost       float64 `json:"avg_cost"` // 持仓均价（含买入费用）
+	LastPrice     float64 `json:"last_price"`
+	MarketValue   float64 `json:"market_value"`
+	RealizedPnL   float64 `json:"realized_pnl"`   // 已实现盈亏（扣除卖出费用）
+	UnrealizedPnL float64 `json:"unrealized_pnl"` // 浮动盈亏
+	Weight        float64 `json:"weight"`         // 占组合总资产比例
+}
+
+// Book 组合账本，按成交顺序逐笔记账（移动加权平均成本法）
+type Book struct {
+	Cash      float64
+	Fees      float64
+	positions map[string]*Position
+}
+
+// NewBook 创建账本
+func NewBook(initialCash float64) *Book {
+	return &Book{Cash: initialCash, positions: make(map[string]*Position)}
+}
+
+// Apply 记入一笔成交，资金或持仓不足时返回错误且不修改账本
+func (b *Book) Apply(t *models.PortfolioTrade) error {
+	if t.Quantity <= 0 || t.Price <= 0 || t.Fee < 0 {
+		return fmt.Errorf("成交数量、价格必须大于0，费用不能为负")
+	}
+
+	key := Key(t.Symbol, t.Exchange)
+	pos := b.positions[key]
+
+	switch t.Side {
+	case models.TradeSideBuy:
+		cost := t.Amount() + t.Fee
+		if cost > b.Cash+1e-6 {
+			return fmt.Errorf("可用资金不足: 需要 %.2f，可用 %.2f", cost, b.Cash)
+		}
+		if pos == nil {
+			pos = &Position{Symbol: t.Symbol, Exchange: t.Exchange}
+			b.positions[key] = pos
+		}
+		pos.AvgCost = (pos.AvgCost*float64(pos.Quantity) + cost) / float64(pos.Quantity+t.Quantity)
+		pos.Quantity += t.Quantity
+		b.Cash -= cost
+
+	case models.TradeSideSell:
+		if pos == nil || pos.Quantity < t.Quantity {
+			var held int64
+			if pos != nil {
+				held = pos.Quantity
+			}
+			return fmt.Errorf("%s 持仓不足: 卖出 %d，持有 %d", key, t.Quantity, held)
+		}
+		pos.RealizedPnL += (t.Price-pos.AvgCost)*float64(t.Quantity) - t.Fee
+		pos.Quantity -= t.Quantity
+		if pos.Quantity == 0 {
+			pos.AvgCost = 0
+		}
+		b.Cash += t.Amount() - t.Fee
+
+	default:
+		return fmt.Errorf("不支持的交易方向: %s", t.Side)
+	}
+
+	// 没有收盘价时以最近成交价估值
+	pos.LastPrice = t.Price
+	b.Fees += t.Fee
+	return nil
+}
+
+// Quantity 返回当前持仓数量
+func (b *Book) Quantity(symbol, exchange string) int64 {
+	if pos := b.positions[Key(symbol, exchange)]; pos != nil {
+		return pos.Quantity
+	}
+	return 0
+}
+
+// MarkPrice 更新最新价格
+func (b *Book) MarkPrice(key string, price float64) {
+	if pos := b.positions[key]; pos != nil && price > 0 {
+		pos.LastPrice = price
+	}
+}
+
+// MarketValue 持仓总市值
+func (b *Book) MarketValue() float64 {
+	var total float64
+	for _, pos := range b.positions {
+		total += float64(pos.Quantity) * pos.LastPrice
+	}
+	return total
+}
+
+// Keys 曾经持有过的全部股票（按键排序）
+func (b *Book) Keys() []string {
+	keys := make([]string, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Positions 返回按当前价格估值后的持仓快照（含已清仓但有已实现盈亏的股票）
+func (b *Book) Positions() []*Position {
+	totalValue := b.Cash + b.MarketValue()
+
+	var positions []*Position
+	for _, key := range b.Keys() {
+		pos := *b.positions[key]
+		pos.MarketValue = float64(pos.Quantity) * pos.LastPrice
+		pos.UnrealizedPnL = (pos.LastPrice - pos.AvgCost) * float64(pos.Quantity)
+		if totalValue > 0 {
+			pos.Weight = pos.MarketValue / totalValue
+		}
+		positions = append(positions, &pos)
+	}
+	return positions
+}
@@ -0,0 +1,190 @@
+package portfolio
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// MaxImportRows 单次导入允许的最大行数
+const MaxImportRows = 10000
+
+// ImportRow 导入的一行成交记录，Row 为 CSV 中的行号（表头为第1行）
+type ImportRow struct {
+	Row   int
+	Trade *models.PortfolioTrade
+}
+
+// RowError 行级错误
+type RowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// 表头别名 -> 标准列名
+var headerAliases = map[string]string{
+	"date": "date", "traded_at": "date", "日期": "date", "成交日期": "date",
+	"symbol": "symbol", "code": "symbol", "代码": "symbol", "证券代码": "symbol",
+	"exchange": "exchange", "market": "exchange", "交易所": "exchange",
+	"side": "side", "action": "side", "方向": "side", "买卖方向": "side",
+	"quantity": "quantity", "qty": "quantity", "数量": "quantity", "成交数量": "quantity",
+	"price": "price", "价格": "price", "成交价格": "price",
+	"fee": "fee", "commission": "fee", "费用": "fee", "手续费": "fee",
+}
+
+// 交易方向别名
+var sideAliases = map[string]string{
+	"buy": models.TradeSideBuy, "b": models.TradeSideBuy, "买入": models.TradeSideBuy, "买": models.TradeSideBuy,
+	"sell": models.TradeSideSell, "s": models.TradeSideSell, "卖出": models.TradeSideSell, "卖": models.TradeSideSell,
+}
+
+var importDateLayouts = []string{"2006-01-02", "2006/01/02", "20060102", time.RFC3339}
+
+// ParseTradesCSV 解析成交记录 CSV
+// 首行为表头，必需列：date、symbol、side、quantity、price；exchange 可省略（此时 symbol 需写作 600519.SH），fee 可省略。
+// 返回解析成功的行与行级错误；表头或文件格式错误时返回 error。
+func ParseTradesCSV(r io.Reader) ([]*ImportRow, []*RowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("文件为空")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取表头失败: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if col, ok := headerAliases[name]; ok {
+			columns[col] = i
+		}
+	}
+	for _, col := range []string{"date", "symbol", "side", "quantity", "price"} {
+		if _, ok := columns[col]; !ok {
+			return nil, nil, fmt.Errorf("缺少必需列: %s", col)
+		}
+	}
+
+	var rows []*ImportRow
+	var rowErrors []*RowError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, &RowError{Row: parseErr.StartLine, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("读取文件失败: %w", err)
+		}
+		if isBlank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		if len(rows)+len(rowErrors) >= MaxImportRows {
+			return nil, nil, fmt.Errorf("单次最多导入 %d 行", MaxImportRows)
+		}
+
+		trade, err := parseRecord(record, columns)
+		if err != nil {
+			rowErrors = append(rowErrors, &RowError{Row: line, Error: err.Error()})
+			continue
+		}
+		rows = append(rows, &ImportRow{Row: line, Trade: trade})
+	}
+
+	return rows, rowErrors, nil
+}
+
+// parseRecord 解析单行记录
+func parseRecord(record []string, columns map[string]int) (*models.PortfolioTrade, error) {
+	field := func(col string) string {
+		i, ok := columns[col]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	tradedAt, err := parseImportDate(field("date"))
+	if err != nil {
+		return nil, err
+	}
+
+	symbol, exchange := field("symbol"), strings.ToUpper(field("exchange"))
+	if exchange == "" {
+		if i := strings.LastIndex(symbol, "."); i > 0 {
+			symbol, exchange = symbol[:i], strings.ToUpper(symbol[i+1:])
+		}
+	}
+	if symbol == "" || exchange == "" {
+		return nil, errors.New("缺少股票代码或交易所")
+	}
+
+	side, ok := sideAliases[strings.ToLower(field("side"))]
+	if !ok {
+		return nil, fmt.Errorf("无法识别的交易方向: %s", field("side"))
+	}
+
+	quantity, err := strconv.ParseInt(field("quantity"), 10, 64)
+	if err != nil || quantity <= 0 {
+		return nil, fmt.Errorf("数量错误: %s", field("quantity"))
+	}
+	price, err := strconv.ParseFloat(field("price"), 64)
+	if err != nil || price <= 0 {
+		return nil, fmt.Errorf("价格错误: %s", field("price"))
+	}
+
+	var fee float64
+	if v := field("fee"); v != "" {
+		if fee, err = strconv.ParseFloat(v, 64); err != nil || fee < 0 {
+			return nil, fmt.Errorf("费用错误: %s", v)
+		}
+	}
+
+	return &models.PortfolioTrade{
+		Symbol:   symbol,
+		Exchange: exchange,
+		Side:     side,
+		Quantity: quantity,
+		Price:    price,
+		Fee:      fee,
+		TradedAt: tradedAt,
+	}, nil
+}
+
+// parseImportDate 解析成交日期
+func parseImportDate(value string) (time.Time, error) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			if t.After(time.Now()) {
+				return time.Time{}, fmt.Errorf("成交日期不能晚于今天: %s", value)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("日期格式错误: %s", value)
+}
+
+// isBlank 判断是否为空行
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,51 @@
+package portfolio
+
+import (
+	"strings"
+	"testing"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestParseTradesCSV(t *testing.T) {
+	data := "\ufeff日期,代码,方向,数量,价格,手续费\n" +
+		"2024-01-02,600519.SH,买入,100,1700.5,5\n" +
+		"2024/01/05,000001.SZ,sell,200,10.2,\n" +
+		"\n" +
+		"2024-01-08,600000,buy,100,8,0\n" +
+		"2024-01-09,600036.SH,hold,100,30,0\n" +
+		"bad-date,600036.SH,buy,100,30,0\n"
+
+	rows, rowErrors, err := ParseTradesCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("期望解析 2 行，实际 %d", len(rows))
+	}
+	first := rows[0].Trade
+	if rows[0].Row != 2 || first.Symbol != "600519" || first.Exchange != "SH" || first.Side != models.TradeSideBuy || first.Fee != 5 {
+		t.Errorf("第2行解析错误: row=%d %+v", rows[0].Row, first)
+	}
+	if rows[1].Trade.Side != models.TradeSideSell || rows[1].Trade.Fee != 0 {
+		t.Errorf("第3行解析错误: %+v", rows[1].Trade)
+	}
+
+	wantRows := []int{5, 6, 7} // 缺交易所、方向错误、日期错误
+	if len(rowErrors) != len(wantRows) {
+		t.Fatalf("期望 %d 个行级错误，实际 %d: %+v", len(wantRows), len(rowErrors), rowErrors)
+	}
+	for i, row := range wantRows {
+		if rowErrors[i].Row != row {
+			t.Errorf("第 %d 个错误行号应为 %d，实际 %d", i, row, rowErrors[i].Row)
+		}
+	}
+}
+
+func TestParseTradesCSVMissingColumn(t *testing.T) {
+	_, _, err := ParseTradesCSV(strings.NewReader("date,symbol,side,price\n"))
+	if err == nil || !strings.Contains(err.Error(), "quantity") {
+		t.Fatalf("期望缺少 quantity 列的错误，实际: %v", err)
+	}
+}
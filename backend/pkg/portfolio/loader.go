@@ -0,0 +1,72 @@
+package portfolio
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// SplitKey 拆分 symbol.exchange
+func SplitKey(key string) (symbol, exchange string, ok bool) {
+	parts := strings.Split(key, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// LoadInput 加载组合分析所需的行情、行业与基准数据
+// 区间开始前的成交决定期初持仓，行情从第一笔成交开始加载；benchmark 为空时不加载基准。
+func LoadInput(ctx context.Context, marketRepo repository.MarketRepository, stockRepo repository.StockRepository,
+	p *models.Portfolio, trades []*models.PortfolioTrade, start, end time.Time, benchmark string) (Input, error) {
+
+	from := start
+	for _, t := range trades {
+		if t.TradedAt.Before(from) {
+			from = t.TradedAt
+		}
+	}
+
+	in := Input{
+		InitialCash: p.InitialCash,
+		Trades:      trades,
+		Closes:      make(map[string]map[string]float64),
+		Industries:  make(map[string]string),
+		Start:       start.Format(dateLayout),
+		End:         end.Format(dateLayout),
+	}
+
+	for _, t := range trades {
+		key := Key(t.Symbol, t.Exchange)
+		if _, loaded := in.Closes[key]; loaded {
+			continue
+		}
+
+		bars, err := marketRepo.GetDailyBars(ctx, t.Symbol, t.Exchange, from, end)
+		if err != nil {
+			return Input{}, fmt.Errorf("查询 %s 行情失败: %w", key, err)
+		}
+		in.Closes[key] = risk.ClosesByDate(bars)
+
+		if stock, err := stockRepo.GetBySymbol(ctx, t.Symbol, t.Exchange); err == nil {
+			in.Industries[key] = stock.Industry
+		}
+	}
+
+	// 基准行情缺失时只影响敞口分析
+	if symbol, exchange, ok := SplitKey(benchmark); ok {
+		if bars, err := marketRepo.GetDailyBars(ctx, symbol, exchange, from, end); err != nil {
+			log.Printf("查询基准 %s 行情失败: %v", benchmark, err)
+		} else {
+			in.Benchmark = risk.ClosesByDate(bars)
+		}
+	}
+
+	return in, nil
+}
@@ -0,0 +1,78 @@
+// Package pricelimit A股涨跌停价格计算：按板块与风险警示状态确定涨跌幅限制，判断是否封板
+package pricelimit
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 涨跌幅限制比例
+const (
+	RatioMain   = 0.10 // 沪深主板
+	RatioGrowth = 0.20 // 创业板、科创板（含 ST）
+	RatioST     = 0.05 // 主板风险警示股票
+	RatioBSE    = 0.30 // 北交所
+)
+
+// 封板状态
+const (
+	LockedUp   = 1  // 收于涨停价，买单无法成交
+	LockedDown = -1 // 收于跌停价，卖单无法成交
+)
+
+// Ratio 返回股票的涨跌幅限制比例
+// 创业板（300/301）与科创板（688/689）为 20%，北交所为 30%，主板为 10%，主板 ST/*ST 为 5%。
+// 新股上市初期不设涨跌幅限制的情形不在此处理。
+func Ratio(symbol, exchange string, st bool) float64 {
+	switch {
+	case exchange == "BJ":
+		return RatioBSE
+	case exchange == "SZ" && (strings.HasPrefix(symbol, "300") || strings.HasPrefix(symbol, "301")):
+		return RatioGrowth
+	case exchange == "SH" && (strings.HasPrefix(symbol, "688") || strings.HasPrefix(symbol, "689")):
+		return RatioGrowth
+	case st:
+		return RatioST
+	}
+	return RatioMain
+}
+
+// Prices 根据前收盘价计算涨停价与跌停价（四舍五入到分）
+func Prices(preClose, ratio float64) (up, down float64) {
+	return round(preClose * (1 + ratio)), round(preClose * (1 - ratio))
+}
+
+// Lock 判断价格是否处于涨停（LockedUp）或跌停（LockedDown），否则返回 0
+func Lock(price, preClose, ratio float64) int {
+	if price <= 0 || preClose <= 0 {
+		return 0
+	}
+	up, down := Prices(preClose, ratio)
+	switch {
+	case price >= up-0.001:
+		return LockedUp
+	case price <= down+0.001:
+		return LockedDown
+	}
+	return 0
+}
+
+// Locks 计算日K线中收于涨停或跌停的交易日，键为 YYYY-MM-DD
+// bars 按时间升序；ratioOn 返回指定交易日适用的涨跌幅限制比例（可随风险警示状态变化）。
+func Locks(bars []*models.DailyBar, ratioOn func(date time.Time) float64) map[string]int {
+	locks := make(map[string]int)
+	for i := 1; i < len(bars); i++ {
+		if lock := Lock(bars[i].Close, bars[i-1].Close, ratioOn(bars[i].Date)); lock != 0 {
+			locks[bars[i].Date.Format("2006-01-02")] = lock
+		}
+	}
+	return locks
+}
+
+// round 四舍五入到分，加微小偏移以抵消浮点误差（如 9.95 × 1.1）
+func round(v float64) float64 {
+	return math.Floor(v*100+0.5+1e-9) / 100
+}
@@ -0,0 +1,67 @@
+package pricelimit
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		symbol, exchange string
+		st               bool
+		want             float64
+	}{
+		{"600519", "SH", false, RatioMain},
+		{"600519", "SH", true, RatioST},
+		{"300750", "SZ", false, RatioGrowth},
+		{"300750", "SZ", true, RatioGrowth},
+		{"688981", "SH", false, RatioGrowth},
+		{"430047", "BJ", false, RatioBSE},
+	}
+	for _, tt := range tests {
+		if got := Ratio(tt.symbol, tt.exchange, tt.st); got != tt.want {
+			t.Errorf("Ratio(%s.%s, st=%v) = %v, want %v", tt.symbol, tt.exchange, tt.st, got, tt.want)
+		}
+	}
+}
+
+func TestPricesAndLock(t *testing.T) {
+	up, down := Prices(9.95, RatioMain)
+	if up != 10.95 || down != 8.96 {
+		t.Fatalf("Prices(9.95) = %v/%v, want 10.95/8.96", up, down)
+	}
+	if Lock(10.95, 9.95, RatioMain) != LockedUp {
+		t.Error("收于涨停价应为 LockedUp")
+	}
+	if Lock(8.96, 9.95, RatioMain) != LockedDown {
+		t.Error("收于跌停价应为 LockedDown")
+	}
+	if Lock(10.5, 9.95, RatioMain) != 0 {
+		t.Error("未触及涨跌停应为 0")
+	}
+}
+
+func TestLocks(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	bars := []*models.DailyBar{
+		{Date: day(2), Close: 10},
+		{Date: day(3), Close: 11},
+		{Date: day(4), Close: 10.45},
+	}
+	locks := Locks(bars, func(time.Time) float64 { return RatioMain })
+	if locks["2024-01-03"] != LockedUp || len(locks) != 1 {
+		t.Errorf("Locks = %v", locks)
+	}
+	// 按日切换为 ST 后 5% 跌停
+	st := Locks(bars, func(d time.Time) float64 {
+		if d.After(day(3)) {
+			return RatioST
+		}
+		return RatioMain
+	})
+	if st["2024-01-04"] != LockedDown {
+		t.Errorf("ST 跌停未识别: %v", st)
+	}
+}
@@ -0,0 +1,25 @@
+// Package progress 回测引擎的进度回调：引擎按实际处理的股票与K线报告进度，
+// 由调用方决定如何展示（如更新回测任务状态、推送给客户端）。
+package progress
+
+// Reporter 进度回调，由引擎所在的 goroutine 同步调用，实现不应阻塞
+type Reporter interface {
+	// Symbol 第 done 只（共 total 只）股票的行情加载完成
+	Symbol(symbol string, done, total int)
+	// Bar 已处理 done 个交易日（共 total 个），date 为刚处理完的交易日，equity 为当日收盘后的权益
+	Bar(date string, done, total int, equity float64)
+}
+
+// Nop 忽略全部进度
+type Nop struct{}
+
+func (Nop) Symbol(string, int, int)       {}
+func (Nop) Bar(string, int, int, float64) {}
+
+// OrNop reporter 为 nil 时返回 Nop，引擎内部无需判空
+func OrNop(reporter Reporter) Reporter {
+	if reporter == nil {
+		return Nop{}
+	}
+	return reporter
+}
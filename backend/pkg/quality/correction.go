@@ -0,0 +1,52 @@
+package quality
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// BarChange 人工修正一根K线时某个字段的变化
+type BarChange struct {
+	Field string  `json:"field"`
+	Old   float64 `json:"old"`
+	New   float64 `json:"new"`
+}
+
+// DiffBars 比较已保存的K线与修正后的K线，按 open/high/low/close/volume/amount 顺序返回有变化的字段
+// 价格与成交额差异小于 1e-9 视为相同，避免浮点误差产生无意义的修正。
+func DiffBars(stored, corrected *models.DailyBar) []BarChange {
+	fields := []struct {
+		name     string
+		old, new float64
+	}{
+		{"open", stored.Open, corrected.Open},
+		{"high", stored.High, corrected.High},
+		{"low", stored.Low, corrected.Low},
+		{"close", stored.Close, corrected.Close},
+		{"volume", float64(stored.Volume), float64(corrected.Volume)},
+		{"amount", stored.Amount, corrected.Amount},
+	}
+
+	changes := make([]BarChange, 0, len(fields))
+	for _, f := range fields {
+		if math.Abs(f.old-f.new) >= 1e-9 {
+			changes = append(changes, BarChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+	return changes
+}
+
+// FormatChanges 将字段变化格式化为 "close: 10.5 -> 10.8" 形式的摘要，用于审计日志
+func FormatChanges(changes []BarChange) string {
+	if len(changes) == 0 {
+		return "无变化"
+	}
+	parts := make([]string, len(changes))
+	for i, c := range changes {
+		parts[i] = fmt.Sprintf("%s: %g -> %g", c.Field, c.Old, c.New)
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,43 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestDiffBars(t *testing.T) {
+	date := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	stored := &models.DailyBar{Symbol: "600519", Exchange: "SH", Date: date,
+		Open: 10, High: 11, Low: 9.5, Close: 10.5, Volume: 1000, Amount: 10500}
+
+	same := *stored
+	if changes := DiffBars(stored, &same); len(changes) != 0 {
+		t.Fatalf("相同K线不应有变化: %+v", changes)
+	}
+	if got := FormatChanges(nil); got != "无变化" {
+		t.Errorf("FormatChanges(nil) = %q", got)
+	}
+
+	corrected := *stored
+	corrected.Close = 10.8
+	corrected.High = 11 + 1e-12 // 浮点误差忽略
+	corrected.Volume = 1200
+	changes := DiffBars(stored, &corrected)
+	want := []BarChange{
+		{Field: "close", Old: 10.5, New: 10.8},
+		{Field: "volume", Old: 1000, New: 1200},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+	if got := FormatChanges(changes); got != "close: 10.5 -> 10.8; volume: 1000 -> 1200" {
+		t.Errorf("FormatChanges = %q", got)
+	}
+}
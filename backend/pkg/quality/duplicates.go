@@ -0,0 +1,167 @@
+package quality
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 重复K线检查与清理 ============
+
+// DuplicateGroup 同一交易日的多根日K线
+type DuplicateGroup struct {
+	Date     string             `json:"date"`
+	Bars     []*models.DailyBar `json:"bars"`     // 按时间戳升序
+	Keep     *models.DailyBar   `json:"keep"`     // 保留的K线：时间戳最新且通过校验的一根，都不合法时为 nil
+	Conflict bool               `json:"conflict"` // 各根K线的 OHLCV 不一致
+}
+
+// Removed 清理时删除的K线
+func (g *DuplicateGroup) Removed() []*models.DailyBar {
+	removed := make([]*models.DailyBar, 0, len(g.Bars))
+	for _, bar := range g.Bars {
+		if bar != g.Keep {
+			removed = append(removed, bar)
+		}
+	}
+	return removed
+}
+
+// GroupDuplicates 按交易日分组，返回包含多根K线的交易日（按日期升序）
+func GroupDuplicates(bars []*models.DailyBar) []DuplicateGroup {
+	byDay := make(map[string][]*models.DailyBar)
+	for _, bar := range bars {
+		day := markettime.TradeDate(bar.Date, bar.Exchange)
+		byDay[day] = append(byDay[day], bar)
+	}
+
+	var groups []DuplicateGroup
+	for day, dayBars := range byDay {
+		if len(dayBars) < 2 {
+			continue
+		}
+		sort.SliceStable(dayBars, func(i, j int) bool { return dayBars[i].Date.Before(dayBars[j].Date) })
+
+		g := DuplicateGroup{Date: day, Bars: dayBars}
+		for i := len(dayBars) - 1; i >= 0; i-- {
+			if ValidateBarData(dayBars[i]) == nil {
+				g.Keep = dayBars[i]
+				break
+			}
+		}
+		for _, bar := range dayBars[1:] {
+			if len(DiffBars(dayBars[0], bar)) > 0 {
+				g.Conflict = true
+				break
+			}
+		}
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Date < groups[j].Date })
+	return groups
+}
+
+// findDuplicates 查找 [start, end] 内的重复K线：先用 Flux 分组找出重复的交易日，再取这些交易日的全部数据点
+func (c *DataQualityChecker) findDuplicates(ctx context.Context, symbol, exchange string, start, end time.Time) ([]DuplicateGroup, error) {
+	days, err := c.marketRepo.DuplicateDailyBarDays(ctx, symbol, exchange, start, end)
+	if err != nil || len(days) == 0 {
+		return nil, err
+	}
+
+	var bars []*models.DailyBar
+	for _, day := range days {
+		dayBars, err := c.marketRepo.GetDailyBars(ctx, symbol, exchange, day, day.AddDate(0, 0, 1))
+		if err != nil {
+			return nil, err
+		}
+		bars = append(bars, dayBars...)
+	}
+	return GroupDuplicates(bars), nil
+}
+
+// CheckDuplicates 检查同一交易日是否有多根日K线：值相同为 warning，值冲突为 error
+func (c *DataQualityChecker) CheckDuplicates(ctx context.Context, symbol, exchange string, days int) (*CheckResult, error) {
+	end := time.Now()
+	start := end.AddDate(0, 0, -days)
+
+	groups, err := c.findDuplicates(ctx, symbol, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts := 0
+	dates := make([]string, 0, len(groups))
+	for _, g := range groups {
+		dates = append(dates, g.Date)
+		if g.Conflict {
+			conflicts++
+		}
+	}
+
+	result := &CheckResult{
+		Symbol:    symbol,
+		Exchange:  exchange,
+		CheckType: "duplicates",
+		CheckedAt: time.Now(),
+		Details: map[string]interface{}{
+			"duplicate_days": len(groups),
+			"conflict_days":  conflicts,
+			"dates":          dates,
+		},
+	}
+	switch {
+	case len(groups) == 0:
+		result.Status = "pass"
+		result.Message = "未发现重复K线"
+	case conflicts == 0:
+		result.Status = "warning"
+		result.Message = fmt.Sprintf("%d 个交易日存在重复K线（数值一致）", len(groups))
+	default:
+		result.Status = "error"
+		result.Message = fmt.Sprintf("%d 个交易日存在重复K线，其中 %d 个数值冲突", len(groups), conflicts)
+	}
+	return result, nil
+}
+
+// DedupeResult 单只股票的重复K线清理结果
+type DedupeResult struct {
+	Symbol   string           `json:"symbol"`
+	Exchange string           `json:"exchange"`
+	Groups   []DuplicateGroup `json:"groups"`
+	Removed  int              `json:"removed"` // 删除的数据点数
+	Skipped  []string         `json:"skipped"` // 没有合法K线可保留、未清理的交易日
+}
+
+// CleanupDuplicates 清理 [start, end] 内的重复日K线，每个交易日保留时间戳最新且通过校验的一根
+// 先删除该交易日的全部数据点再写回保留的K线；dryRun 时只返回将删除的内容。
+func (c *DataQualityChecker) CleanupDuplicates(ctx context.Context, symbol, exchange string, start, end time.Time, dryRun bool) (*DedupeResult, error) {
+	groups, err := c.findDuplicates(ctx, symbol, exchange, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DedupeResult{Symbol: symbol, Exchange: exchange, Groups: groups, Skipped: []string{}}
+	for _, g := range groups {
+		if g.Keep == nil {
+			result.Skipped = append(result.Skipped, g.Date)
+			continue
+		}
+		if !dryRun {
+			dayStart, _ := markettime.ParseDate(g.Date, exchange)
+			dayEnd := dayStart.AddDate(0, 0, 1).Add(-time.Second)
+			if err := c.marketRepo.DeleteSeries(ctx, database.DataDailyBars, symbol, exchange, dayStart, dayEnd); err != nil {
+				return result, err
+			}
+			if err := c.marketRepo.SaveDailyBar(ctx, g.Keep); err != nil {
+				return result, fmt.Errorf("写回 %s 的K线失败: %w", g.Date, err)
+			}
+		}
+		result.Removed += len(g.Removed())
+	}
+	return result, nil
+}
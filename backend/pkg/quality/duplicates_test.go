@@ -0,0 +1,56 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestGroupDuplicates(t *testing.T) {
+	bar := func(ts time.Time, close float64) *models.DailyBar {
+		return &models.DailyBar{Symbol: "000001", Exchange: "SZ", Date: ts,
+			Open: 10, High: 11, Low: 9, Close: close, Volume: 100}
+	}
+	// 3 月 1 日：北京时间零点（UTC 前一天 16:00）与 UTC 零点各一根，数值冲突
+	cstMidnight := time.Date(2024, 2, 29, 16, 0, 0, 0, time.UTC)
+	utcMidnight := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	// 3 月 4 日：两根数值一致，较新的一根价格不合法
+	mar4 := time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+	invalid := bar(mar4.Add(time.Hour), 10)
+	invalid.Low = 12
+
+	bars := []*models.DailyBar{
+		bar(utcMidnight, 10.5),
+		bar(cstMidnight, 10.2),
+		bar(time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), 10), // 无重复
+		bar(mar4, 10),
+		invalid,
+	}
+	groups := GroupDuplicates(bars)
+	if len(groups) != 2 {
+		t.Fatalf("groups = %d, want 2", len(groups))
+	}
+
+	g := groups[0]
+	if g.Date != "2024-03-01" || len(g.Bars) != 2 || !g.Conflict {
+		t.Fatalf("groups[0] = %s bars=%d conflict=%v", g.Date, len(g.Bars), g.Conflict)
+	}
+	if g.Keep == nil || !g.Keep.Date.Equal(utcMidnight) {
+		t.Errorf("应保留时间戳最新的一根，got %+v", g.Keep)
+	}
+	if removed := g.Removed(); len(removed) != 1 || !removed[0].Date.Equal(cstMidnight) {
+		t.Errorf("Removed = %+v", removed)
+	}
+
+	g = groups[1]
+	if g.Date != "2024-03-04" {
+		t.Fatalf("groups[1].Date = %s", g.Date)
+	}
+	if g.Keep == nil || !g.Keep.Date.Equal(mar4) {
+		t.Errorf("最新一根不合法时应保留较早的合法K线，got %+v", g.Keep)
+	}
+	if !g.Conflict {
+		t.Error("low 不同应视为冲突")
+	}
+}
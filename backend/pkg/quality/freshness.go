@@ -0,0 +1,205 @@
+package quality
+
+import (
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/calendar"
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 全市场数据新鲜度 ============
+
+const (
+	// DailySyncDelay 日K线与指标在交易日次日凌晨 2:00 的增量更新中写入，留 1 小时余量
+	DailySyncDelay = 27 * time.Hour
+	// MinuteSyncGrace 盘中同步分钟K线的额外余量
+	MinuteSyncGrace = 5 * time.Minute
+)
+
+// MeasurementFreshness 一类数据的全市场新鲜度：最新数据时间、相对预期的落后程度与统计日期的数据量
+type MeasurementFreshness struct {
+	Measurement    string     `json:"measurement"`
+	Status         string     `json:"status"` // pass, warning, error
+	Message        string     `json:"message"`
+	Latest         *time.Time `json:"latest"`           // 全市场最新的数据时间，没有数据时为空
+	Expected       *time.Time `json:"expected"`         // 按交易日历此时应已同步到的数据时间，未启用同步时为空
+	LagSeconds     int64      `json:"lag_seconds"`      // 最新数据落后预期的时长，未落后为 0
+	LagTradingDays int        `json:"lag_trading_days"` // 落后的交易日数，财报为报告期数
+	CountDate      string     `json:"count_date"`       // 统计数据量的日期（财报为报告期）
+	Symbols        int        `json:"symbols"`          // 统计日期有数据的股票数
+	Points         int64      `json:"points"`           // 统计日期的数据点数
+}
+
+// ExpectedDailyDate 此时应已同步到的最近交易日（默认时区零点）：交易日收盘后次日凌晨的增量更新完成才算到期
+func ExpectedDailyDate(cal *calendar.Calendar, now time.Time) time.Time {
+	day := markettime.StartOfDate(now.Add(-DailySyncDelay).In(markettime.Location("")), "")
+	for i := 0; i < 30 && !cal.IsTradingDay(day); i++ {
+		day = day.AddDate(0, 0, -1)
+	}
+	return day
+}
+
+// TradingDaysBetween from 之后到 to（含）之间的交易日数，按默认时区的日期计算，to 不晚于 from 时为 0
+func TradingDaysBetween(cal *calendar.Calendar, from, to time.Time) int {
+	start, end := tradeDay(from), tradeDay(to)
+	days := 0
+	for day := start.AddDate(0, 0, 1); !day.After(end); day = day.AddDate(0, 0, 1) {
+		if cal.IsTradingDay(day) {
+			days++
+		}
+	}
+	return days
+}
+
+// DailyFreshness 日K线、指标等按交易日写入的数据的新鲜度，统计日期为预期交易日
+func DailyFreshness(cal *calendar.Calendar, measurement string, latest *time.Time, now time.Time) *MeasurementFreshness {
+	expected := ExpectedDailyDate(cal, now)
+	f := &MeasurementFreshness{
+		Measurement: measurement,
+		Latest:      latest,
+		Expected:    &expected,
+		CountDate:   expected.Format(markettime.DateLayout),
+	}
+	if latest == nil {
+		f.Status, f.Message = "error", "没有数据"
+		return f
+	}
+	day := tradeDay(*latest)
+	if day.Before(expected) {
+		f.LagSeconds = int64(expected.Sub(day).Seconds())
+		f.LagTradingDays = TradingDaysBetween(cal, day, expected)
+	}
+	f.Status, f.Message = lagStatus(f.LagTradingDays, day.Format(markettime.DateLayout))
+	return f
+}
+
+// MinuteFreshness 分钟K线的新鲜度：交易时段内应同步到最近一个同步间隔，收盘后应同步到收盘；
+// 统计日期为最近一个交易日，interval 不大于 0（未启用盘中同步）时不判断落后。
+func MinuteFreshness(cal *calendar.Calendar, latest *time.Time, now time.Time, interval time.Duration) *MeasurementFreshness {
+	start, inSession := cal.SessionStart("", now)
+	ref := now
+	if !inSession || now.Sub(start) < interval+MinuteSyncGrace {
+		// 非交易时段或刚开盘时，应同步到上一个交易时段收盘
+		ref = cal.LastClose("", now)
+	}
+	f := &MeasurementFreshness{
+		Measurement: database.DataMinuteBars,
+		Latest:      latest,
+		CountDate:   markettime.TradeDate(ref, ""),
+	}
+	if inSession {
+		f.CountDate = markettime.TradeDate(now, "")
+	}
+	if interval <= 0 {
+		f.Status, f.Message = "pass", "未启用盘中同步"
+		return f
+	}
+	if latest == nil {
+		f.Status, f.Message = "error", "没有数据"
+		return f
+	}
+
+	expected := ref.Add(-(interval + MinuteSyncGrace))
+	f.Expected = &expected
+	if latest.Before(expected) {
+		f.LagSeconds = int64(expected.Sub(*latest).Seconds())
+		f.LagTradingDays = TradingDaysBetween(cal, *latest, expected)
+	}
+	last := markettime.FormatMinute(*latest, "")
+	switch {
+	case f.LagSeconds == 0:
+		f.Status, f.Message = "pass", fmt.Sprintf("数据最新，最新: %s", last)
+	case f.LagTradingDays == 0:
+		f.Status, f.Message = "warning", fmt.Sprintf("盘中同步延迟 %d 秒，最新: %s", f.LagSeconds, last)
+	default:
+		f.Status, f.Message = "error", fmt.Sprintf("落后 %d 个交易日，最新: %s", f.LagTradingDays, last)
+	}
+	return f
+}
+
+// LatestDisclosedPeriod 此时已过法定披露截止日的最近一个报告期（季度末，默认时区零点）
+func LatestDisclosedPeriod(now time.Time) time.Time {
+	now = now.In(markettime.Location(""))
+	// 本季度末
+	period := time.Date(now.Year(), (now.Month()-1)/3*3+4, 1, 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	for i := 0; i < 8; i++ {
+		report := models.FinancialReport{ReportType: periodReportType(period), ReportDate: period}
+		if !report.DisclosureDeadline().After(now) {
+			break
+		}
+		period = previousPeriod(period)
+	}
+	return period
+}
+
+// FundamentalsFreshness 财报的新鲜度：latest 为全市场最新的报告期，应不早于已过披露截止日的最近报告期
+func FundamentalsFreshness(latest *time.Time, now time.Time) *MeasurementFreshness {
+	expected := LatestDisclosedPeriod(now)
+	f := &MeasurementFreshness{
+		Measurement: "fundamentals",
+		Latest:      latest,
+		Expected:    &expected,
+		CountDate:   expected.Format(markettime.DateLayout),
+	}
+	if latest == nil {
+		f.Status, f.Message = "error", "没有数据"
+		return f
+	}
+	period := tradeDay(*latest)
+	for p := expected; period.Before(p) && f.LagTradingDays < 8; p = previousPeriod(p) {
+		f.LagTradingDays++
+	}
+	if f.LagTradingDays > 0 {
+		f.LagSeconds = int64(expected.Sub(period).Seconds())
+	}
+	last := period.Format(markettime.DateLayout)
+	switch f.LagTradingDays {
+	case 0:
+		f.Status, f.Message = "pass", fmt.Sprintf("已同步到最近报告期 %s", last)
+	case 1:
+		f.Status, f.Message = "warning", fmt.Sprintf("落后 1 个报告期，最新: %s", last)
+	default:
+		f.Status, f.Message = "error", fmt.Sprintf("落后 %d 个报告期，最新: %s", f.LagTradingDays, last)
+	}
+	return f
+}
+
+// tradeDay 时间点在默认时区所属日期的零点（日K线按 UTC 或北京时间零点保存都归入同一天）
+func tradeDay(t time.Time) time.Time {
+	day, _ := markettime.ParseDate(markettime.TradeDate(t, ""), "")
+	return day
+}
+
+// lagStatus 按落后的交易日数判断状态：当天为 pass，落后 1 天为 warning，更多为 error
+func lagStatus(days int, latest string) (string, string) {
+	switch {
+	case days <= 0:
+		return "pass", fmt.Sprintf("数据最新，最新: %s", latest)
+	case days == 1:
+		return "warning", fmt.Sprintf("落后 1 个交易日，最新: %s", latest)
+	default:
+		return "error", fmt.Sprintf("落后 %d 个交易日，最新: %s", days, latest)
+	}
+}
+
+// periodReportType 季度末对应的报告类型
+func periodReportType(period time.Time) string {
+	switch period.Month() {
+	case time.March:
+		return models.ReportTypeQ1
+	case time.June:
+		return models.ReportTypeQ2
+	case time.September:
+		return models.ReportTypeQ3
+	default:
+		return models.ReportTypeAnnual
+	}
+}
+
+// previousPeriod 上一个季度末
+func previousPeriod(period time.Time) time.Time {
+	return time.Date(period.Year(), period.Month()-2, 1, 0, 0, 0, 0, period.Location()).AddDate(0, 0, -1)
+}
@@ -0,0 +1,114 @@
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/calendar"
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/markettime"
+)
+
+func minute(s string) time.Time {
+	t, err := markettime.ParseMinute(s, "")
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func newCalendar(t *testing.T) *calendar.Calendar {
+	cal, err := calendar.New(config.CalendarConfig{Holidays: []string{"2024-10-01", "2024-10-02", "2024-10-03", "2024-10-04", "2024-10-07"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cal
+}
+
+func TestExpectedDailyDate(t *testing.T) {
+	cal := newCalendar(t)
+	tests := []struct{ now, want string }{
+		{"2024-09-27 20:00", "2024-09-26"}, // 周五收盘后，当天的日K线次日凌晨才同步
+		{"2024-09-28 03:00", "2024-09-27"},
+		{"2024-09-30 10:00", "2024-09-27"}, // 周末之后
+		{"2024-10-08 10:00", "2024-09-30"}, // 国庆长假之后
+	}
+	for _, tt := range tests {
+		if got := ExpectedDailyDate(cal, minute(tt.now)).Format(markettime.DateLayout); got != tt.want {
+			t.Errorf("ExpectedDailyDate(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestDailyFreshness(t *testing.T) {
+	cal := newCalendar(t)
+	now := minute("2024-10-09 10:00") // 预期 2024-10-08
+	tests := []struct {
+		latest string
+		status string
+		days   int
+	}{
+		{"2024-10-08 00:00", "pass", 0},
+		{"2024-09-30 00:00", "warning", 1}, // 隔着长假只落后一个交易日
+		{"2024-09-26 08:00", "error", 3},
+	}
+	for _, tt := range tests {
+		latest := minute(tt.latest)
+		f := DailyFreshness(cal, "daily_bars", &latest, now)
+		if f.Status != tt.status || f.LagTradingDays != tt.days || f.CountDate != "2024-10-08" {
+			t.Errorf("latest %s: status = %s, lag = %d, count_date = %s", tt.latest, f.Status, f.LagTradingDays, f.CountDate)
+		}
+	}
+	if f := DailyFreshness(cal, "daily_bars", nil, now); f.Status != "error" {
+		t.Errorf("没有数据: status = %s", f.Status)
+	}
+}
+
+func TestMinuteFreshness(t *testing.T) {
+	cal := newCalendar(t)
+	tests := []struct {
+		now, latest string
+		status      string
+		countDate   string
+	}{
+		{"2024-09-30 10:00", "2024-09-30 09:58", "pass", "2024-09-30"},
+		{"2024-09-30 10:00", "2024-09-30 09:40", "warning", "2024-09-30"},
+		{"2024-09-30 09:32", "2024-09-27 14:59", "pass", "2024-09-30"}, // 刚开盘，上一交易日已同步到收盘
+		{"2024-09-30 20:00", "2024-09-27 14:59", "error", "2024-09-30"},
+		{"2024-10-05 10:00", "2024-09-30 14:59", "pass", "2024-09-30"},
+	}
+	for _, tt := range tests {
+		latest := minute(tt.latest)
+		f := MinuteFreshness(cal, &latest, minute(tt.now), time.Minute)
+		if f.Status != tt.status || f.CountDate != tt.countDate {
+			t.Errorf("now %s latest %s: status = %s, count_date = %s", tt.now, tt.latest, f.Status, f.CountDate)
+		}
+	}
+	if f := MinuteFreshness(cal, nil, minute("2024-09-30 10:00"), 0); f.Status != "pass" || f.Expected != nil {
+		t.Errorf("未启用盘中同步: status = %s, expected = %v", f.Status, f.Expected)
+	}
+}
+
+func TestFundamentalsFreshness(t *testing.T) {
+	tests := []struct{ now, want string }{
+		{"2024-10-17 10:00", "2024-06-30"}, // 三季报 10 月底才到截止日
+		{"2024-11-01 10:00", "2024-09-30"},
+		{"2024-04-15 10:00", "2023-09-30"}, // 年报与一季报都未到截止日
+		{"2024-05-01 10:00", "2024-03-31"},
+	}
+	for _, tt := range tests {
+		if got := LatestDisclosedPeriod(minute(tt.now)).Format(markettime.DateLayout); got != tt.want {
+			t.Errorf("LatestDisclosedPeriod(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+
+	now := minute("2024-10-17 10:00")
+	latest := minute("2024-03-31 00:00")
+	if f := FundamentalsFreshness(&latest, now); f.Status != "warning" || f.LagTradingDays != 1 {
+		t.Errorf("落后一个报告期: status = %s, lag = %d", f.Status, f.LagTradingDays)
+	}
+	latest = minute("2024-09-30 00:00")
+	if f := FundamentalsFreshness(&latest, now); f.Status != "pass" || f.LagSeconds != 0 {
+		t.Errorf("已披露下一期: status = %s, lag = %d", f.Status, f.LagSeconds)
+	}
+}
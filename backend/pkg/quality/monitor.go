@@ -263,19 +263,24 @@ func (c *DataQualityChecker) CheckStock(ctx context.Context, symbol, exchange st
 
 	// 完整性检查（最近30天）
 	end := time.Now()
-	start := end.AddDate(0, 0, -30)
+	start := end.AddDate(0, 0, -CheckWindowDays)
 	
 	if result, err := c.CheckCompleteness(ctx, symbol, exchange, start, end); err == nil {
 		results = append(results, *result)
 	}
 
 	// 连续性检查
-	if result, err := c.CheckContinuity(ctx, symbol, exchange, 30); err == nil {
+	if result, err := c.CheckContinuity(ctx, symbol, exchange, CheckWindowDays); err == nil {
 		results = append(results, *result)
 	}
 
 	// 异常值检查
-	if result, err := c.CheckAnomalies(ctx, symbol, exchange, 30); err == nil {
+	if result, err := c.CheckAnomalies(ctx, symbol, exchange, CheckWindowDays); err == nil {
+		results = append(results, *result)
+	}
+
+	// 重复K线检查
+	if result, err := c.CheckDuplicates(ctx, symbol, exchange, 30); err == nil {
 		results = append(results, *result)
 	}
 
@@ -411,3 +416,24 @@ func ValidateBarData(bar *models.DailyBar) error {
 
 	return nil
 }
+
+// ValidateMinuteBar 验证分钟K线数据有效性，价格与成交量规则同日K线
+func ValidateMinuteBar(bar *models.MinuteBar) error {
+	if bar == nil {
+		return fmt.Errorf("数据为空")
+	}
+	if bar.Interval == "" {
+		return fmt.Errorf("K线周期为空")
+	}
+	return ValidateBarData(&models.DailyBar{
+		Symbol:   bar.Symbol,
+		Exchange: bar.Exchange,
+		Date:     bar.Time,
+		Open:     bar.Open,
+		High:     bar.High,
+		Low:      bar.Low,
+		Close:    bar.Close,
+		Volume:   bar.Volume,
+		Amount:   bar.Amount,
+	})
+}
@@ -0,0 +1,82 @@
+package quality
+
+import (
+	"context"
+	"math"
+)
+
+// ============ 数据质量评分 ============
+
+// CheckWindowDays 单只股票检查（CheckStock）覆盖的最近天数，更早的K线不影响质量评分
+const CheckWindowDays = 30
+
+// scoreWeights 各检查项在质量评分中的权重，合计 100
+var scoreWeights = map[string]float64{
+	"completeness": 30,
+	"continuity":   25,
+	"anomalies":    25,
+	"freshness":    20,
+}
+
+// statusScores 检查状态对应的得分比例
+var statusScores = map[string]float64{
+	"pass":    1,
+	"warning": 0.5,
+	"error":   0,
+}
+
+// Score 将完整性、连续性、异常值与新鲜度检查汇总为 0~100 的质量评分
+// 未参与评分的检查类型（如 duplicates）忽略；缺少的检查项按剩余权重折算，一项都没有时 ok 为 false。
+func Score(results []CheckResult) (score int, ok bool) {
+	var total, weights float64
+	for _, r := range results {
+		weight, scored := scoreWeights[r.CheckType]
+		if !scored {
+			continue
+		}
+		total += weight * statusScores[r.Status]
+		weights += weight
+	}
+	if weights == 0 {
+		return 0, false
+	}
+	return int(math.Round(total / weights * 100)), true
+}
+
+// StockChecks 单只股票的全部检查结果，在 CheckStock 的基础上加入新鲜度检查
+func (c *DataQualityChecker) StockChecks(ctx context.Context, symbol, exchange string) ([]CheckResult, error) {
+	results, err := c.CheckStock(ctx, symbol, exchange)
+	if err != nil {
+		return nil, err
+	}
+	if freshness, err := c.CheckDataFreshness(ctx, symbol, exchange); err == nil {
+		results = append(results, *freshness)
+	}
+	return results, nil
+}
+
+// ScoreStock 检查单只股票并计算质量评分
+func (c *DataQualityChecker) ScoreStock(ctx context.Context, symbol, exchange string) (int, bool, error) {
+	results, err := c.StockChecks(ctx, symbol, exchange)
+	if err != nil {
+		return 0, false, err
+	}
+	score, ok := Score(results)
+	return score, ok, nil
+}
+
+// Stale 新鲜度检查结果中最新日K线是否滞后超过 days 天，没有数据也视为滞后
+// 结果中没有新鲜度检查（如查询失败）时返回 false。
+func Stale(results []CheckResult, days int) bool {
+	for _, r := range results {
+		if r.CheckType != "freshness" {
+			continue
+		}
+		delay, ok := r.Details["delay_days"].(float64)
+		if !ok {
+			return r.Status == "error"
+		}
+		return delay > float64(days)
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package quality
+
+import "testing"
+
+func TestScore(t *testing.T) {
+	check := func(checkType, status string) CheckResult {
+		return CheckResult{CheckType: checkType, Status: status}
+	}
+
+	tests := []struct {
+		name    string
+		results []CheckResult
+		want    int
+		ok      bool
+	}{
+		{"无检查结果", nil, 0, false},
+		{"全部通过", []CheckResult{
+			check("completeness", "pass"), check("continuity", "pass"),
+			check("anomalies", "pass"), check("freshness", "pass"),
+		}, 100, true},
+		{"警告与错误", []CheckResult{
+			check("completeness", "pass"),  // 30
+			check("continuity", "warning"), // 12.5
+			check("anomalies", "error"),    // 0
+			check("freshness", "warning"),  // 10
+			check("duplicates", "error"),   // 不参与评分
+		}, 53, true},
+		{"缺少检查项按剩余权重折算", []CheckResult{
+			check("completeness", "pass"), check("anomalies", "error"),
+		}, 55, true},
+	}
+	for _, tt := range tests {
+		got, ok := Score(tt.results)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("%s: Score = %d, %v; want %d, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestStale(t *testing.T) {
+	freshness := func(status string, delayDays float64) CheckResult {
+		r := CheckResult{CheckType: "freshness", Status: status}
+		if delayDays >= 0 {
+			r.Details = map[string]interface{}{"delay_days": delayDays}
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		results []CheckResult
+		want    bool
+	}{
+		{"没有新鲜度检查", []CheckResult{{CheckType: "completeness", Status: "error"}}, false},
+		{"未超过天数", []CheckResult{freshness("warning", 2.5)}, false},
+		{"超过天数", []CheckResult{freshness("error", 4)}, true},
+		{"没有数据", []CheckResult{freshness("error", -1)}, true},
+	}
+	for _, tt := range tests {
+		if got := Stale(tt.results, 3); got != tt.want {
+			t.Errorf("%s: Stale = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// Package quota 订阅套餐的配额：各套餐的资源上限、当前用量统计与超限检查。
+// 各服务通过 middleware.Quota 在创建类接口前检查配额，用户服务的 GET /api/v1/user/usage 展示当前用量。
+package quota
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// Resource 受配额限制的资源
+type Resource string
+
+const (
+	Strategies      Resource = "strategies"        // 策略数
+	BacktestsPerDay Resource = "backtests_per_day" // 每日回测次数（按自然日）
+	WatchlistItems  Resource = "watchlist_items"   // 自选股数（所有分组合计）
+	AlertRules      Resource = "alert_rules"       // 提醒规则数
+)
+
+// resourceNames 资源的中文名称，用于超限提示
+var resourceNames = map[Resource]string{
+	Strategies:      "策略数",
+	BacktestsPerDay: "每日回测次数",
+	WatchlistItems:  "自选股数",
+	AlertRules:      "提醒规则数",
+}
+
+// Limits 套餐的配额，0 表示不限制
+type Limits struct {
+	MaxStrategies      int `json:"max_strategies"`
+	MaxBacktestsPerDay int `json:"max_backtests_per_day"`
+	MaxWatchlistItems  int `json:"max_watchlist_items"`
+	MaxAlertRules      int `json:"max_alert_rules"`
+	MaxHistoryDays     int `json:"max_history_days"` // 回测等可访问的历史数据深度（天），从当天向前计算
+}
+
+// plans 各套餐的配额
+var plans = map[string]Limits{
+	models.PlanFree: {
+		MaxStrategies:      5,
+		MaxBacktestsPerDay: 10,
+		MaxWatchlistItems:  50,
+		MaxAlertRules:      10,
+		MaxHistoryDays:     365 * 3,
+	},
+	models.PlanPro: {
+		MaxStrategies:      100,
+		MaxBacktestsPerDay: 500,
+		MaxWatchlistItems:  1000,
+		MaxAlertRules:      200,
+		MaxHistoryDays:     0,
+	},
+}
+
+// ForPlan 套餐的配额，未知套餐按免费版处理
+func ForPlan(plan string) Limits {
+	if l, ok := plans[plan]; ok {
+		return l
+	}
+	return plans[models.PlanFree]
+}
+
+// Max 资源的上限，0 表示不限制
+func (l Limits) Max(r Resource) int {
+	switch r {
+	case Strategies:
+		return l.MaxStrategies
+	case BacktestsPerDay:
+		return l.MaxBacktestsPerDay
+	case WatchlistItems:
+		return l.MaxWatchlistItems
+	case AlertRules:
+		return l.MaxAlertRules
+	}
+	return 0
+}
+
+// Store 读取用户套餐与资源用量，由 repository.QuotaRepository 实现
+type Store interface {
+	GetUser(ctx context.Context, userID uint) (*models.User, error)
+	CountStrategies(ctx context.Context, userID uint) (int64, error)
+	CountBacktestsSince(ctx context.Context, userID uint, since time.Time) (int64, error)
+	CountWatchlistItems(ctx context.Context, userID uint) (int64, error)
+	CountAlertRules(ctx context.Context, userID uint) (int64, error)
+}
+
+// ExceededError 资源用量已达到套餐上限
+type ExceededError struct {
+	Plan     string
+	Resource Resource
+	Limit    int
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s已达到当前套餐（%s）上限 %d，请升级套餐", resourceNames[e.Resource], e.Plan, e.Limit)
+}
+
+// RangeError 请求的数据区间超出套餐可访问的历史深度
+type RangeError struct {
+	Plan     string
+	MaxDays  int
+	Earliest time.Time
+}
+
+func (e *RangeError) Error() string {
+	return fmt.Sprintf("当前套餐（%s）只能访问最近 %d 天的历史数据（%s 起），请升级套餐", e.Plan, e.MaxDays, e.Earliest.Format("2006-01-02"))
+}
+
+// Usage 用户当前套餐、配额与用量
+type Usage struct {
+	Plan          string             `json:"plan"`
+	PlanExpiresAt *time.Time         `json:"plan_expires_at,omitempty"`
+	Limits        Limits             `json:"limits"`
+	Used          map[Resource]int64 `json:"used"`
+}
+
+// Checker 配额检查
+type Checker struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewChecker 创建配额检查
+func NewChecker(store Store) *Checker {
+	return &Checker{store: store, now: time.Now}
+}
+
+// Plan 用户当前生效的套餐及其配额
+func (c *Checker) Plan(ctx context.Context, userID uint) (string, Limits, error) {
+	user, err := c.store.GetUser(ctx, userID)
+	if err != nil {
+		return "", Limits{}, err
+	}
+	plan := user.CurrentPlan(c.now())
+	return plan, ForPlan(plan), nil
+}
+
+// Check 检查再创建一个资源是否超出套餐上限，超限时返回 *ExceededError
+// 检查与创建之间没有加锁，并发请求可能短暂超出上限一两个，配额只用于限制正常使用，不要求严格一致。
+func (c *Checker) Check(ctx context.Context, userID uint, r Resource) error {
+	plan, limits, err := c.Plan(ctx, userID)
+	if err != nil {
+		return err
+	}
+	limit := limits.Max(r)
+	if limit == 0 {
+		return nil
+	}
+	used, err := c.count(ctx, userID, r)
+	if err != nil {
+		return err
+	}
+	if used >= int64(limit) {
+		return &ExceededError{Plan: plan, Resource: r, Limit: limit}
+	}
+	return nil
+}
+
+// CheckRange 检查区间开始日期是否在套餐可访问的历史深度内，超出时返回 *RangeError
+func (c *Checker) CheckRange(ctx context.Context, userID uint, start time.Time) error {
+	plan, limits, err := c.Plan(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if limits.MaxHistoryDays == 0 {
+		return nil
+	}
+	earliest := startOfDay(c.now()).AddDate(0, 0, -limits.MaxHistoryDays)
+	if start.Before(earliest) {
+		return &RangeError{Plan: plan, MaxDays: limits.MaxHistoryDays, Earliest: earliest}
+	}
+	return nil
+}
+
+// Usage 用户当前套餐、配额与各资源用量
+func (c *Checker) Usage(ctx context.Context, userID uint) (*Usage, error) {
+	user, err := c.store.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	plan := user.CurrentPlan(c.now())
+	usage := &Usage{Plan: plan, Limits: ForPlan(plan), Used: make(map[Resource]int64)}
+	if plan != models.PlanFree {
+		usage.PlanExpiresAt = user.PlanExpiresAt
+	}
+	for _, r := range []Resource{Strategies, BacktestsPerDay, WatchlistItems, AlertRules} {
+		if usage.Used[r], err = c.count(ctx, userID, r); err != nil {
+			return nil, err
+		}
+	}
+	return usage, nil
+}
+
+// count 资源的当前用量
+func (c *Checker) count(ctx context.Context, userID uint, r Resource) (int64, error) {
+	switch r {
+	case Strategies:
+		return c.store.CountStrategies(ctx, userID)
+	case BacktestsPerDay:
+		return c.store.CountBacktestsSince(ctx, userID, startOfDay(c.now()))
+	case WatchlistItems:
+		return c.store.CountWatchlistItems(ctx, userID)
+	case AlertRules:
+		return c.store.CountAlertRules(ctx, userID)
+	}
+	return 0, nil
+}
+
+// startOfDay 当天零点（本地时区）
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,135 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+type fakeStore struct {
+	user       models.User
+	strategies int64
+	backtests  int64
+	watchlist  int64
+	alertRules int64
+	since      time.Time
+}
+
+func (s *fakeStore) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	u := s.user
+	return &u, nil
+}
+
+func (s *fakeStore) CountStrategies(ctx context.Context, userID uint) (int64, error) {
+	return s.strategies, nil
+}
+
+func (s *fakeStore) CountBacktestsSince(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	s.since = since
+	return s.backtests, nil
+}
+
+func (s *fakeStore) CountWatchlistItems(ctx context.Context, userID uint) (int64, error) {
+	return s.watchlist, nil
+}
+
+func (s *fakeStore) CountAlertRules(ctx context.Context, userID uint) (int64, error) {
+	return s.alertRules, nil
+}
+
+func newTestChecker(store Store, now time.Time) *Checker {
+	c := NewChecker(store)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 6, 10, 15, 30, 0, 0, time.Local)
+	free := ForPlan(models.PlanFree)
+	store := &fakeStore{user: models.User{Plan: models.PlanFree}, strategies: int64(free.MaxStrategies) - 1}
+	c := newTestChecker(store, now)
+	ctx := context.Background()
+
+	if err := c.Check(ctx, 1, Strategies); err != nil {
+		t.Fatalf("未达上限时应通过: %v", err)
+	}
+
+	store.strategies++
+	err := c.Check(ctx, 1, Strategies)
+	var exceeded *ExceededError
+	if !errors.As(err, &exceeded) || exceeded.Limit != free.MaxStrategies {
+		t.Fatalf("达到上限时应返回 ExceededError，实际: %v", err)
+	}
+
+	// 回测次数按自然日统计
+	store.backtests = int64(free.MaxBacktestsPerDay)
+	if err := c.Check(ctx, 1, BacktestsPerDay); !errors.As(err, &exceeded) {
+		t.Errorf("今日回测次数达到上限时应返回 ExceededError，实际: %v", err)
+	}
+	if want := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local); !store.since.Equal(want) {
+		t.Errorf("回测次数应从当天零点统计，实际: %v", store.since)
+	}
+
+	store.alertRules = int64(free.MaxAlertRules)
+	if err := c.Check(ctx, 1, AlertRules); !errors.As(err, &exceeded) {
+		t.Errorf("提醒规则数达到上限时应返回 ExceededError，实际: %v", err)
+	}
+
+	// 升级专业版后上限提高
+	store.user = models.User{Plan: models.PlanPro}
+	if err := c.Check(ctx, 1, Strategies); err != nil {
+		t.Errorf("专业版未达上限时应通过: %v", err)
+	}
+}
+
+func TestExpiredPlanFallsBackToFree(t *testing.T) {
+	now := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local)
+	expired := now.Add(-time.Hour)
+	store := &fakeStore{
+		user:       models.User{Plan: models.PlanPro, PlanExpiresAt: &expired},
+		strategies: int64(ForPlan(models.PlanFree).MaxStrategies),
+	}
+	c := newTestChecker(store, now)
+
+	var exceeded *ExceededError
+	if err := c.Check(context.Background(), 1, Strategies); !errors.As(err, &exceeded) || exceeded.Plan != models.PlanFree {
+		t.Errorf("到期的专业版应按免费版检查，实际: %v", err)
+	}
+
+	usage, err := c.Usage(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Plan != models.PlanFree || usage.PlanExpiresAt != nil {
+		t.Errorf("到期后用量应显示免费版，实际: %+v", usage)
+	}
+	if usage.Used[Strategies] != store.strategies {
+		t.Errorf("策略用量 = %d，期望 %d", usage.Used[Strategies], store.strategies)
+	}
+}
+
+func TestCheckRange(t *testing.T) {
+	now := time.Date(2024, 6, 10, 15, 0, 0, 0, time.Local)
+	store := &fakeStore{user: models.User{Plan: models.PlanFree}}
+	c := newTestChecker(store, now)
+	ctx := context.Background()
+	maxDays := ForPlan(models.PlanFree).MaxHistoryDays
+
+	earliest := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local).AddDate(0, 0, -maxDays)
+	if err := c.CheckRange(ctx, 1, earliest); err != nil {
+		t.Errorf("历史深度内的区间应通过: %v", err)
+	}
+
+	var outOfRange *RangeError
+	if err := c.CheckRange(ctx, 1, earliest.AddDate(0, 0, -1)); !errors.As(err, &outOfRange) {
+		t.Errorf("超出历史深度应返回 RangeError，实际: %v", err)
+	}
+
+	store.user = models.User{Plan: models.PlanPro}
+	if err := c.CheckRange(ctx, 1, time.Date(2005, 1, 1, 0, 0, 0, 0, time.Local)); err != nil {
+		t.Errorf("专业版不限制历史深度: %v", err)
+	}
+}
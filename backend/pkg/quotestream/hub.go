@@ -0,0 +1,249 @@
+package quotestream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/metrics"
+	"stock-analysis-system/backend/pkg/pairs"
+)
+
+// DefaultPollInterval 未指定间隔函数时每个股票行情源的检查间隔
+const DefaultPollInterval = 3 * time.Second
+
+// Hub 按股票汇聚订阅：每只被订阅的股票只有一个行情源，定期加载一次后推送给全部订阅的连接，
+// 连接数增加不会增加查询次数。第一个连接订阅时启动行情源，最后一个连接取消订阅时停止。
+type Hub struct {
+	service  string
+	load     Loader
+	interval func(now time.Time) time.Duration
+
+	mu     sync.Mutex
+	feeds  map[string]*feed
+	loads  uint64
+	closed bool
+}
+
+// feed 一只股票的行情源
+type feed struct {
+	key, symbol, exchange string
+	cancel                context.CancelFunc
+	wake                  chan struct{}
+
+	// 以下字段由 Hub.mu 保护
+	subs map[*Session]struct{}
+	last []byte // 最近加载的行情 JSON，尚未加载成功时为 nil
+}
+
+// NewHub 创建行情汇聚，interval 返回当前时刻的检查间隔，为 nil 时使用 DefaultPollInterval
+// 订阅数与查询次数以 service 为标签导出到 /metrics。
+func NewHub(service string, load Loader, interval func(now time.Time) time.Duration) *Hub {
+	if interval == nil {
+		interval = func(time.Time) time.Duration { return DefaultPollInterval }
+	}
+	h := &Hub{
+		service:  service,
+		load:     load,
+		interval: interval,
+		feeds:    make(map[string]*feed),
+	}
+	metrics.Register("quotestream_"+service, h.collect)
+	return h
+}
+
+// Refresh 立即重新加载全部行情源（如数据同步完成后），有变化的推送给订阅的连接
+func (h *Hub) Refresh() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, f := range h.feeds {
+		select {
+		case f.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close 停止全部行情源，之后的订阅返回错误
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for key, f := range h.feeds {
+		f.cancel()
+		delete(h.feeds, key)
+	}
+}
+
+// subscribe 登记连接并返回当前行情，行情源尚未加载成功时同步加载一次
+// 股票不存在（ErrUnknownSymbol）时不登记；其他加载失败仍登记，行情源下次加载成功时推送。
+func (h *Hub) subscribe(ctx context.Context, s *Session, key string) ([]byte, error) {
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		return nil, errors.New("行情推送已关闭")
+	}
+	if f, ok := h.feeds[key]; ok && f.last != nil {
+		f.subs[s] = struct{}{}
+		last := f.last
+		h.mu.Unlock()
+		return last, nil
+	}
+	h.mu.Unlock()
+
+	symbol, exchange, _ := pairs.SplitLeg(key)
+	encoded, err := h.fetch(ctx, symbol, exchange)
+	if errors.Is(err, ErrUnknownSymbol) {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, errors.New("行情推送已关闭")
+	}
+	f, ok := h.feeds[key]
+	if !ok {
+		f = h.startFeed(key, symbol, exchange)
+	}
+	f.subs[s] = struct{}{}
+	if err != nil {
+		return nil, err
+	}
+	if f.last == nil {
+		f.last = encoded
+	}
+	return f.last, nil
+}
+
+// unsubscribe 注销连接，股票没有订阅的连接时停止行情源
+func (h *Hub) unsubscribe(s *Session, key string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	f, ok := h.feeds[key]
+	if !ok {
+		return
+	}
+	delete(f.subs, s)
+	if len(f.subs) == 0 {
+		f.cancel()
+		delete(h.feeds, key)
+	}
+}
+
+// latest 行情源最近加载的行情，用于重新推送快照
+func (h *Hub) latest(key string) []byte {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if f, ok := h.feeds[key]; ok {
+		return f.last
+	}
+	return nil
+}
+
+// startFeed 创建并启动行情源，调用方需持有 mu
+func (h *Hub) startFeed(key, symbol, exchange string) *feed {
+	ctx, cancel := context.WithCancel(context.Background())
+	f := &feed{
+		key:      key,
+		symbol:   symbol,
+		exchange: exchange,
+		cancel:   cancel,
+		wake:     make(chan struct{}, 1),
+		subs:     make(map[*Session]struct{}),
+	}
+	h.feeds[key] = f
+	go h.runFeed(ctx, f)
+	return f
+}
+
+// runFeed 按间隔加载行情，直到最后一个连接取消订阅
+func (h *Hub) runFeed(ctx context.Context, f *feed) {
+	timer := time.NewTimer(h.interval(time.Now()))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-f.wake:
+			if !timer.Stop() {
+				<-timer.C
+			}
+		}
+		h.refresh(ctx, f)
+		timer.Reset(h.interval(time.Now()))
+	}
+}
+
+// refresh 加载一次行情，有变化时推送给全部订阅的连接
+func (h *Hub) refresh(ctx context.Context, f *feed) {
+	encoded, err := h.fetch(ctx, f.symbol, f.exchange)
+	if err != nil {
+		return
+	}
+
+	h.mu.Lock()
+	if bytes.Equal(f.last, encoded) {
+		h.mu.Unlock()
+		return
+	}
+	f.last = encoded
+	subs := make([]*Session, 0, len(f.subs))
+	for s := range f.subs {
+		subs = append(subs, s)
+	}
+	h.mu.Unlock()
+
+	for _, s := range subs {
+		s.send(f.key, encoded, false)
+	}
+}
+
+// fetch 加载行情并编码为 JSON，编码结果用于比较是否变化
+func (h *Hub) fetch(ctx context.Context, symbol, exchange string) ([]byte, error) {
+	h.mu.Lock()
+	h.loads++
+	h.mu.Unlock()
+	data, err := h.load(ctx, symbol, exchange)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// collect 导出行情源数、每只股票的订阅连接数与累计查询次数
+func (h *Hub) collect() []metrics.Sample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := make([]metrics.Sample, 0, len(h.feeds)+2)
+	samples = append(samples,
+		metrics.Sample{
+			Name:   "quotestream_feeds",
+			Help:   "当前有订阅的股票数（每只一个行情源）",
+			Type:   metrics.TypeGauge,
+			Labels: map[string]string{"service": h.service},
+			Value:  float64(len(h.feeds)),
+		},
+		metrics.Sample{
+			Name:   "quotestream_loads_total",
+			Help:   "行情源累计加载次数",
+			Type:   metrics.TypeCounter,
+			Labels: map[string]string{"service": h.service},
+			Value:  float64(h.loads),
+		},
+	)
+	for key, f := range h.feeds {
+		samples = append(samples, metrics.Sample{
+			Name:   "quotestream_subscribers",
+			Help:   "订阅该股票的连接数",
+			Type:   metrics.TypeGauge,
+			Labels: map[string]string{"service": h.service, "symbol": key},
+			Value:  float64(len(f.subs)),
+		})
+	}
+	return samples
+}
@@ -0,0 +1,289 @@
+// Package quotestream 行情 WebSocket 的消息协议、单个连接的订阅状态与按股票汇聚的行情源。
+//
+// 客户端发送 subscribe/unsubscribe/resync/ping 请求；服务端在订阅时先推送 snapshot（完整行情），
+// 之后行情变化时推送 update，并定期发送 heartbeat。snapshot 与 update 在同一连接内按 seq 逐条递增，
+// heartbeat 携带最近一条的 seq：客户端发现 seq 不连续（发送缓冲已满时服务端会丢弃消息而不是阻塞），
+// 或超过两个心跳周期没有收到任何消息时，发送 resync 重新获取快照，重连后重新 subscribe 即可恢复，无需刷新页面。
+//
+// 连接不自行查询行情：同一股票的全部订阅共用 Hub 中的一个行情源，由行情源加载后推送给各连接。
+package quotestream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/pairs"
+)
+
+// 客户端请求类型
+const (
+	OpSubscribe   = "subscribe"
+	OpUnsubscribe = "unsubscribe"
+	OpResync      = "resync" // 重新推送指定（为空时全部）已订阅股票的快照
+	OpPing        = "ping"
+)
+
+// 服务端消息类型
+const (
+	TypeSnapshot  = "snapshot"
+	TypeUpdate    = "update"
+	TypeHeartbeat = "heartbeat"
+	TypeAck       = "ack"
+	TypePong      = "pong"
+	TypeError     = "error"
+	TypeReconnect = "reconnect" // 服务正在重启，客户端应按提示稍后重连并重新订阅
+)
+
+// 默认值
+const (
+	DefaultMaxSymbols = 50               // 单个连接最多订阅的股票数
+	DefaultBuffer     = 256              // 单个连接的发送缓冲，已满时丢弃消息
+	HeartbeatInterval = 15 * time.Second // 服务端心跳间隔
+)
+
+// ErrUnknownSymbol Loader 找不到股票时返回，该股票不会被订阅
+var ErrUnknownSymbol = errors.New("股票不存在")
+
+// Request 客户端请求
+type Request struct {
+	Op      string   `json:"op"`
+	ID      string   `json:"id,omitempty"`      // 客户端请求ID，原样回传于 ack/pong/error
+	Symbols []string `json:"symbols,omitempty"` // symbol.exchange，如 600519.SH
+}
+
+// Message 服务端消息
+type Message struct {
+	Type    string      `json:"type"`
+	Seq     uint64      `json:"seq"` // snapshot/update 逐条递增，其他消息为最近一条 snapshot/update 的序号
+	ID      string      `json:"id,omitempty"`
+	Symbol  string      `json:"symbol,omitempty"`
+	Symbols []string    `json:"symbols,omitempty"` // ack 时为当前全部订阅
+	Data    interface{} `json:"data,omitempty"`
+	Msg     string      `json:"msg,omitempty"`
+	Time    int64       `json:"time"` // 毫秒时间戳
+}
+
+// Loader 加载一只股票的最新行情，不应包含每次加载都会变化的字段（如查询时间），否则每次轮询都会推送 update
+type Loader func(ctx context.Context, symbol, exchange string) (interface{}, error)
+
+// Session 一个连接的订阅状态，方法可并发调用，连接结束时需调用 Close
+type Session struct {
+	hub        *Hub
+	maxSymbols int
+	out        chan Message
+	now        func() time.Time
+
+	mu      sync.Mutex
+	seq     uint64
+	subs    map[string][]byte // symbol.exchange -> 最近推送行情的 JSON，尚未推送时为 nil
+	dropped uint64
+	closed  bool
+}
+
+// NewSession 创建连接的订阅状态，行情由 hub 推送，maxSymbols、buffer 不大于 0 时使用默认值
+func NewSession(hub *Hub, maxSymbols, buffer int) *Session {
+	if maxSymbols <= 0 {
+		maxSymbols = DefaultMaxSymbols
+	}
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Session{
+		hub:        hub,
+		maxSymbols: maxSymbols,
+		out:        make(chan Message, buffer),
+		now:        time.Now,
+		subs:       make(map[string][]byte),
+	}
+}
+
+// Messages 待发送的消息
+func (s *Session) Messages() <-chan Message {
+	return s.out
+}
+
+// Dropped 因发送缓冲已满而丢弃的消息数
+func (s *Session) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Handle 处理一条客户端请求
+func (s *Session) Handle(ctx context.Context, req Request) {
+	switch strings.ToLower(req.Op) {
+	case OpSubscribe:
+		s.subscribe(ctx, req)
+	case OpUnsubscribe:
+		for _, key := range s.subscribed(req.Symbols) {
+			s.mu.Lock()
+			delete(s.subs, key)
+			s.mu.Unlock()
+			s.hub.unsubscribe(s, key)
+		}
+		s.ack(req.ID)
+	case OpResync:
+		for _, key := range s.subscribed(req.Symbols) {
+			if last := s.hub.latest(key); last != nil {
+				s.send(key, last, true)
+			}
+		}
+		s.ack(req.ID)
+	case OpPing:
+		s.push(Message{Type: TypePong, ID: req.ID})
+	default:
+		s.push(Message{Type: TypeError, ID: req.ID, Msg: "未知的请求类型: " + req.Op})
+	}
+}
+
+// subscribe 订阅并推送快照，已订阅的股票重新推送快照（重连后重复订阅是安全的）
+func (s *Session) subscribe(ctx context.Context, req Request) {
+	type loaded struct {
+		key  string
+		data []byte
+	}
+	var snapshots []loaded
+	for _, raw := range req.Symbols {
+		key := strings.ToUpper(strings.TrimSpace(raw))
+		if _, _, ok := pairs.SplitLeg(key); !ok {
+			s.push(Message{Type: TypeError, ID: req.ID, Symbol: raw, Msg: "symbol 格式错误，应为 symbol.exchange"})
+			continue
+		}
+
+		s.mu.Lock()
+		_, exists := s.subs[key]
+		full := !exists && len(s.subs) >= s.maxSymbols
+		s.mu.Unlock()
+		if full {
+			s.push(Message{Type: TypeError, ID: req.ID, Symbol: key, Msg: "订阅数量超过上限"})
+			continue
+		}
+
+		data, err := s.hub.subscribe(ctx, s, key)
+		if errors.Is(err, ErrUnknownSymbol) {
+			s.push(Message{Type: TypeError, ID: req.ID, Symbol: key, Msg: err.Error()})
+			continue
+		}
+		s.mu.Lock()
+		closed := s.closed
+		if _, exists := s.subs[key]; !exists && !closed {
+			s.subs[key] = nil
+		}
+		s.mu.Unlock()
+		if closed {
+			// 连接已结束，撤销刚才的登记
+			s.hub.unsubscribe(s, key)
+			return
+		}
+		if err != nil {
+			// 暂时加载失败仍保持订阅，行情源下次加载成功时推送快照
+			s.push(Message{Type: TypeError, ID: req.ID, Symbol: key, Msg: "加载行情失败，稍后推送"})
+			continue
+		}
+		snapshots = append(snapshots, loaded{key, data})
+	}
+
+	s.ack(req.ID)
+	for _, snap := range snapshots {
+		s.send(snap.key, snap.data, true)
+	}
+}
+
+// Close 取消全部订阅，连接结束时调用
+func (s *Session) Close() {
+	s.mu.Lock()
+	keys := make([]string, 0, len(s.subs))
+	for key := range s.subs {
+		keys = append(keys, key)
+	}
+	s.subs = make(map[string][]byte)
+	s.closed = true
+	s.mu.Unlock()
+	for _, key := range keys {
+		s.hub.unsubscribe(s, key)
+	}
+}
+
+// Heartbeat 推送心跳，seq 为最近一条 snapshot/update 的序号
+func (s *Session) Heartbeat() {
+	s.push(Message{Type: TypeHeartbeat})
+}
+
+// Reject 推送错误消息，用于无法解析的请求
+func (s *Session) Reject(msg string) {
+	s.push(Message{Type: TypeError, Msg: msg})
+}
+
+// Notice 构造由调用方立即发送、不经发送缓冲的消息（如 reconnect），seq 为最近一条的序号
+func (s *Session) Notice(msgType string, data interface{}) Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Message{Type: msgType, Seq: s.seq, Data: data, Time: s.now().UnixMilli()}
+}
+
+// send 与上次推送的行情比较后推送，有变化时推送 update（尚未推送过快照或 force 时推送 snapshot），已取消订阅时忽略
+func (s *Session) send(key string, encoded []byte, force bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, ok := s.subs[key]
+	if !ok || (!force && bytes.Equal(last, encoded)) {
+		return
+	}
+	msgType := TypeUpdate
+	if force || last == nil {
+		msgType = TypeSnapshot
+	}
+	s.subs[key] = encoded
+	s.seq++
+	s.enqueue(Message{Type: msgType, Symbol: key, Data: json.RawMessage(encoded)})
+}
+
+// ack 确认请求，附带当前全部订阅
+func (s *Session) ack(id string) {
+	s.push(Message{Type: TypeAck, ID: id, Symbols: s.subscribed(nil)})
+}
+
+// subscribed 已订阅的股票（按代码排序），filter 非空时只返回其中已订阅的
+func (s *Session) subscribed(filter []string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var keys []string
+	if len(filter) == 0 {
+		for key := range s.subs {
+			keys = append(keys, key)
+		}
+	} else {
+		for _, raw := range filter {
+			key := strings.ToUpper(strings.TrimSpace(raw))
+			if _, ok := s.subs[key]; ok {
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// push 推送不占用序号的消息
+func (s *Session) push(msg Message) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enqueue(msg)
+}
+
+// enqueue 写入发送缓冲，已满时丢弃（snapshot/update 已占用序号，客户端据此发现遗漏），调用方需持有 mu
+func (s *Session) enqueue(msg Message) {
+	msg.Seq = s.seq
+	msg.Time = s.now().UnixMilli()
+	select {
+	case s.out <- msg:
+	default:
+		s.dropped++
+	}
+}
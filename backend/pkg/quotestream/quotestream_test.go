@@ -0,0 +1,216 @@
+package quotestream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeQuotes map[string]float64
+
+func (q fakeQuotes) load(_ context.Context, symbol, exchange string) (interface{}, error) {
+	price, ok := q[symbol+"."+exchange]
+	if !ok {
+		return nil, ErrUnknownSymbol
+	}
+	return map[string]float64{"price": price}, nil
+}
+
+// newTestHub 行情源不自动加载，由测试调用 refreshAll 触发
+func newTestHub(load Loader) *Hub {
+	return NewHub("test", load, func(time.Time) time.Duration { return time.Hour })
+}
+
+// refreshAll 同步加载全部行情源一次
+func refreshAll(h *Hub) {
+	h.mu.Lock()
+	feeds := make([]*feed, 0, len(h.feeds))
+	for _, f := range h.feeds {
+		feeds = append(feeds, f)
+	}
+	h.mu.Unlock()
+	for _, f := range feeds {
+		h.refresh(context.Background(), f)
+	}
+}
+
+// drain 取出当前全部待发送消息
+func drain(s *Session) []Message {
+	var msgs []Message
+	for {
+		select {
+		case msg := <-s.Messages():
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func types(msgs []Message) []string {
+	var out []string
+	for _, m := range msgs {
+		out = append(out, m.Type)
+	}
+	return out
+}
+
+func TestSubscribeSnapshotAndUpdates(t *testing.T) {
+	quotes := fakeQuotes{"600519.SH": 1700, "000001.SZ": 10}
+	hub := newTestHub(quotes.load)
+	defer hub.Close()
+	s := NewSession(hub, 0, 0)
+	ctx := context.Background()
+
+	s.Handle(ctx, Request{Op: OpSubscribe, ID: "1", Symbols: []string{"600519.sh", "000001.SZ", "bad", "999999.SZ"}})
+	msgs := drain(s)
+	want := []string{TypeError, TypeError, TypeAck, TypeSnapshot, TypeSnapshot}
+	if got := types(msgs); len(got) != len(want) {
+		t.Fatalf("消息 = %v, want %v", got, want)
+	}
+	ack := msgs[2]
+	if ack.ID != "1" || len(ack.Symbols) != 2 || ack.Symbols[0] != "000001.SZ" {
+		t.Errorf("ack = %+v", ack)
+	}
+	if msgs[3].Seq != 1 || msgs[4].Seq != 2 || msgs[3].Symbol != "600519.SH" {
+		t.Errorf("快照序号 = %d, %d", msgs[3].Seq, msgs[4].Seq)
+	}
+
+	// 未变化时不推送，变化时推送 update
+	refreshAll(hub)
+	if msgs := drain(s); len(msgs) != 0 {
+		t.Fatalf("行情未变化时不应推送: %v", types(msgs))
+	}
+	quotes["600519.SH"] = 1710
+	refreshAll(hub)
+	msgs = drain(s)
+	if len(msgs) != 1 || msgs[0].Type != TypeUpdate || msgs[0].Seq != 3 {
+		t.Fatalf("update = %+v", msgs)
+	}
+	var data map[string]float64
+	json.Unmarshal(msgs[0].Data.(json.RawMessage), &data)
+	if data["price"] != 1710 {
+		t.Errorf("update data = %v", data)
+	}
+
+	// 心跳携带最近一条的序号，不占用序号
+	s.Heartbeat()
+	if msgs := drain(s); msgs[0].Type != TypeHeartbeat || msgs[0].Seq != 3 {
+		t.Errorf("heartbeat = %+v", msgs[0])
+	}
+
+	// resync 重新推送快照；取消订阅后不再推送
+	s.Handle(ctx, Request{Op: OpResync, Symbols: []string{"600519.SH"}})
+	if got := types(drain(s)); len(got) != 2 || got[0] != TypeSnapshot || got[1] != TypeAck {
+		t.Errorf("resync = %v", got)
+	}
+	s.Handle(ctx, Request{Op: OpUnsubscribe, Symbols: []string{"600519.SH"}})
+	quotes["600519.SH"] = 1720
+	drain(s)
+	refreshAll(hub)
+	if msgs := drain(s); len(msgs) != 0 {
+		t.Errorf("取消订阅后不应推送: %v", types(msgs))
+	}
+}
+
+func TestDroppedMessagesLeaveSeqGap(t *testing.T) {
+	quotes := fakeQuotes{"600519.SH": 1700, "000001.SZ": 10}
+	hub := newTestHub(quotes.load)
+	defer hub.Close()
+	s := NewSession(hub, 0, 2)
+	ctx := context.Background()
+
+	s.Handle(ctx, Request{Op: OpSubscribe, Symbols: []string{"600519.SH", "000001.SZ"}})
+	msgs := drain(s)
+	if len(msgs) != 2 || s.Dropped() != 1 {
+		t.Fatalf("msgs = %v, dropped = %d", types(msgs), s.Dropped())
+	}
+	// 客户端收到心跳时发现最近序号大于已收到的序号，发送 resync
+	s.Heartbeat()
+	hb := drain(s)[0]
+	if hb.Seq != 2 || msgs[1].Seq != 1 {
+		t.Errorf("心跳序号 = %d，已收到 %d，应能发现遗漏", hb.Seq, msgs[1].Seq)
+	}
+}
+
+func TestSubscribeLimitAndErrors(t *testing.T) {
+	quotes := fakeQuotes{"600519.SH": 1700, "000001.SZ": 10}
+	failing := func(ctx context.Context, symbol, exchange string) (interface{}, error) {
+		if symbol == "000001" {
+			return nil, errors.New("influx down")
+		}
+		return quotes.load(ctx, symbol, exchange)
+	}
+	hub := newTestHub(failing)
+	defer hub.Close()
+	s := NewSession(hub, 1, 0)
+	ctx := context.Background()
+
+	s.Handle(ctx, Request{Op: OpSubscribe, Symbols: []string{"000001.SZ", "600519.SH"}})
+	msgs := drain(s)
+	// 暂时加载失败仍保持订阅，占用名额
+	if got := types(msgs); len(got) != 3 || got[0] != TypeError || got[1] != TypeError || got[2] != TypeAck {
+		t.Fatalf("消息 = %v", got)
+	}
+	if msgs[1].Msg != "订阅数量超过上限" || len(msgs[2].Symbols) != 1 {
+		t.Errorf("消息 = %+v", msgs)
+	}
+
+	s.Handle(ctx, Request{Op: OpPing, ID: "p"})
+	s.Handle(ctx, Request{Op: "noop"})
+	if got := drain(s); got[0].Type != TypePong || got[0].ID != "p" || got[1].Type != TypeError {
+		t.Errorf("消息 = %v", types(got))
+	}
+}
+
+func TestHubSharesFeedAcrossSessions(t *testing.T) {
+	var mu sync.Mutex
+	quotes := fakeQuotes{"600519.SH": 1700}
+	loads := 0
+	load := func(ctx context.Context, symbol, exchange string) (interface{}, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		return quotes.load(ctx, symbol, exchange)
+	}
+	hub := newTestHub(load)
+	defer hub.Close()
+	ctx := context.Background()
+
+	sessions := make([]*Session, 3)
+	for i := range sessions {
+		sessions[i] = NewSession(hub, 0, 0)
+		sessions[i].Handle(ctx, Request{Op: OpSubscribe, Symbols: []string{"600519.SH"}})
+		drain(sessions[i])
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, 同一股票的订阅应共用一次加载", loads)
+	}
+
+	mu.Lock()
+	quotes["600519.SH"] = 1710
+	mu.Unlock()
+	refreshAll(hub)
+	if loads != 2 {
+		t.Fatalf("loads = %d, 行情源每次只加载一次", loads)
+	}
+	for i, s := range sessions {
+		if got := types(drain(s)); len(got) != 1 || got[0] != TypeUpdate {
+			t.Errorf("连接 %d 消息 = %v", i, got)
+		}
+	}
+
+	sessions[0].Handle(ctx, Request{Op: OpUnsubscribe, Symbols: []string{"600519.SH"}})
+	sessions[1].Close()
+	samples := hub.collect()
+	if samples[0].Value != 1 || samples[2].Value != 1 {
+		t.Errorf("feeds = %v, subscribers = %v", samples[0].Value, samples[2].Value)
+	}
+	sessions[2].Close()
+	if len(hub.collect()) != 2 || hub.latest("600519.SH") != nil {
+		t.Error("最后一个连接取消订阅后应停止行情源")
+	}
+}
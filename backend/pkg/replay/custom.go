@@ -0,0 +1,58 @@
+package replay
+
+import (
+	"math"
+
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// customHistory 自定义指标保留的最少K线数，EMA、RSI 等递推指标在截断后仍能收敛
+const customHistory = 250
+
+// customStrategy 在策略自身指标之外附加自定义指标的最新值，不影响交易动作
+type customStrategy struct {
+	Strategy
+	exprs  map[string]*indicator.Expr
+	series *indicator.Series
+	keep   int
+}
+
+// WithCustomIndicators 为策略附加自定义指标，K线事件的 indicators 中以指标名输出其最新值
+func WithCustomIndicators(strategy Strategy, exprs map[string]*indicator.Expr) Strategy {
+	if len(exprs) == 0 {
+		return strategy
+	}
+	keep := customHistory
+	for _, e := range exprs {
+		keep = max(keep, (e.Lookback()+1)*4)
+	}
+	return &customStrategy{Strategy: strategy, exprs: exprs, series: &indicator.Series{}, keep: keep}
+}
+
+func (s *customStrategy) Warmup() int {
+	warmup := s.Strategy.Warmup()
+	for _, e := range s.exprs {
+		warmup = max(warmup, e.Lookback()+1)
+	}
+	return warmup
+}
+
+func (s *customStrategy) OnBar(bar *models.MinuteBar, position int64) (map[string]float64, string) {
+	s.series.Append(bar.Time, bar.Open, bar.High, bar.Low, bar.Close, float64(bar.Volume), bar.Amount)
+	if s.series.Len() > s.keep*2 {
+		s.series = s.series.Tail(s.keep)
+	}
+
+	indicators, action := s.Strategy.OnBar(bar, position)
+	if indicators == nil {
+		indicators = make(map[string]float64, len(s.exprs))
+	}
+	for name, e := range s.exprs {
+		values := e.Eval(s.series)
+		if v := values[len(values)-1]; !math.IsNaN(v) {
+			indicators[name] = v
+		}
+	}
+	return indicators, action
+}
@@ -0,0 +1,264 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 回放速度范围：倍速，60 表示 1 分钟K线每秒推送一根；0 表示不等待
+const (
+	DefaultSpeed = 60
+	MaxSpeed     = 3600
+)
+
+// 事件类型
+const (
+	EventStart  = "start"
+	EventBar    = "bar"
+	EventSignal = "signal"
+	EventState  = "state"
+	EventDone   = "done"
+	EventError  = "error"
+
+	EventReconnect = "reconnect" // 服务正在重启，客户端应按提示稍后重连
+)
+
+// Event 推送给客户端的回放事件
+type Event struct {
+	Type string      `json:"type"`
+	Data interface{} `json:"data,omitempty"`
+	Msg  string      `json:"msg,omitempty"`
+}
+
+// BarEvent 一根K线及其对应的策略状态
+type BarEvent struct {
+	Index      int                `json:"index"` // 从 1 开始
+	Total      int                `json:"total"`
+	Bar        *models.MinuteBar  `json:"bar"`
+	Indicators map[string]float64 `json:"indicators,omitempty"`
+	Position   int64              `json:"position"`
+	PnL        float64            `json:"pnl"` // 已实现 + 浮动盈亏
+}
+
+// Signal 回放中产生的交易信号，按当根K线收盘价成交
+type Signal struct {
+	Time       time.Time          `json:"time"`
+	Action     string             `json:"action"`
+	Price      float64            `json:"price"`
+	Quantity   int64              `json:"quantity"`
+	Indicators map[string]float64 `json:"indicators,omitempty"`
+}
+
+// State 回放控制状态
+type State struct {
+	Paused bool    `json:"paused"`
+	Speed  float64 `json:"speed"`
+}
+
+// Summary 回放结束时的汇总
+type Summary struct {
+	Bars          int     `json:"bars"`
+	Signals       int     `json:"signals"`
+	Position      int64   `json:"position"`
+	RealizedPnL   float64 `json:"realized_pnl"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Completed     bool    `json:"completed"` // 是否推送完全部K线
+}
+
+// Player 分钟K线回放器，支持暂停、继续与调整速度
+type Player struct {
+	strategy Strategy
+	size     int64
+	bars     []*models.MinuteBar
+	interval time.Duration
+
+	mu     sync.Mutex
+	speed  float64
+	paused bool
+	wake   chan struct{}
+
+	position    int64
+	avgCost     float64
+	realizedPnL float64
+}
+
+// NewPlayer 创建回放器
+// warmup 为回放日之前的K线，只用于预热策略指标，不推送；interval 为K线周期。
+func NewPlayer(strategy Strategy, size int64, warmup, bars []*models.MinuteBar, interval time.Duration, speed float64) (*Player, error) {
+	if err := validateSpeed(speed); err != nil {
+		return nil, err
+	}
+	p := &Player{
+		strategy: strategy,
+		size:     size,
+		bars:     bars,
+		interval: interval,
+		speed:    speed,
+		wake:     make(chan struct{}, 1),
+	}
+	// 预热阶段只计算指标，不开仓
+	for _, bar := range warmup {
+		strategy.OnBar(bar, 0)
+	}
+	return p, nil
+}
+
+// Total 待回放的K线数
+func (p *Player) Total() int {
+	return len(p.bars)
+}
+
+// Pause 暂停回放
+func (p *Player) Pause() State {
+	return p.update(func() { p.paused = true })
+}
+
+// Resume 继续回放
+func (p *Player) Resume() State {
+	return p.update(func() { p.paused = false })
+}
+
+// SetSpeed 调整回放速度，从下一根K线开始生效
+func (p *Player) SetSpeed(speed float64) (State, error) {
+	if err := validateSpeed(speed); err != nil {
+		return p.State(), err
+	}
+	return p.update(func() { p.speed = speed }), nil
+}
+
+// State 当前控制状态
+func (p *Player) State() State {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return State{Paused: p.paused, Speed: p.speed}
+}
+
+func (p *Player) update(fn func()) State {
+	p.mu.Lock()
+	fn()
+	state := State{Paused: p.paused, Speed: p.speed}
+	p.mu.Unlock()
+
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+	return state
+}
+
+// Run 逐根推送K线并驱动策略，emit 返回错误或 ctx 取消时停止
+// 结束时总会推送 done 事件（emit 失败除外）。
+func (p *Player) Run(ctx context.Context, emit func(*Event) error) error {
+	var signals int
+	var last float64
+	completed := true
+
+	for i, bar := range p.bars {
+		if i > 0 {
+			if err := p.wait(ctx); err != nil {
+				completed = false
+				break
+			}
+		}
+
+		indicators, action := p.strategy.OnBar(bar, p.position)
+		if signal := p.execute(bar, action, indicators); signal != nil {
+			signals++
+			if err := emit(&Event{Type: EventSignal, Data: signal}); err != nil {
+				return err
+			}
+		}
+		last = bar.Close
+
+		if err := emit(&Event{Type: EventBar, Data: &BarEvent{
+			Index:      i + 1,
+			Total:      len(p.bars),
+			Bar:        bar,
+			Indicators: indicators,
+			Position:   p.position,
+			PnL:        p.realizedPnL + p.unrealized(last),
+		}}); err != nil {
+			return err
+		}
+	}
+
+	return emit(&Event{Type: EventDone, Data: &Summary{
+		Bars:          len(p.bars),
+		Signals:       signals,
+		Position:      p.position,
+		RealizedPnL:   p.realizedPnL,
+		UnrealizedPnL: p.unrealized(last),
+		Completed:     completed,
+	}})
+}
+
+// execute 按收盘价执行策略动作：买入固定数量，卖出平掉全部持仓
+func (p *Player) execute(bar *models.MinuteBar, action string, indicators map[string]float64) *Signal {
+	switch {
+	case action == ActionBuy:
+		cost := p.avgCost*float64(p.position) + bar.Close*float64(p.size)
+		p.position += p.size
+		p.avgCost = cost / float64(p.position)
+		return &Signal{Time: bar.Time, Action: action, Price: bar.Close, Quantity: p.size, Indicators: indicators}
+
+	case action == ActionSell && p.position > 0:
+		quantity := p.position
+		p.realizedPnL += (bar.Close - p.avgCost) * float64(quantity)
+		p.position, p.avgCost = 0, 0
+		return &Signal{Time: bar.Time, Action: action, Price: bar.Close, Quantity: quantity, Indicators: indicators}
+	}
+	return nil
+}
+
+func (p *Player) unrealized(price float64) float64 {
+	return (price - p.avgCost) * float64(p.position)
+}
+
+// wait 按当前速度等待下一根K线；暂停时阻塞直到继续，速度变化时重新计时
+func (p *Player) wait(ctx context.Context) error {
+	for {
+		state := p.State()
+		if state.Paused {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-p.wake:
+				continue
+			}
+		}
+		if state.Speed == 0 {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(time.Duration(float64(p.interval) / state.Speed))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-p.wake:
+			timer.Stop()
+		case <-timer.C:
+			return nil
+		}
+	}
+}
+
+// ParseInterval 解析K线周期，如 1m、5m、60m
+func ParseInterval(interval string) (time.Duration, error) {
+	switch interval {
+	case "1m", "5m", "15m", "30m", "60m":
+		return time.ParseDuration(interval)
+	}
+	return 0, fmt.Errorf("不支持的K线周期: %s，可选 1m/5m/15m/30m/60m", interval)
+}
+
+func validateSpeed(speed float64) error {
+	if speed < 0 || speed > MaxSpeed {
+		return fmt.Errorf("回放速度应在 0~%d 之间", MaxSpeed)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package replay
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func minuteBars(start time.Time, closes ...float64) []*models.MinuteBar {
+	bars := make([]*models.MinuteBar, len(closes))
+	for i, c := range closes {
+		bars[i] = &models.MinuteBar{Symbol: "600519", Exchange: "SH", Interval: "1m", Time: start.Add(time.Duration(i) * time.Minute), Close: c}
+	}
+	return bars
+}
+
+func TestIndicators(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6}
+	if got := sma(values, 3); got != 5 {
+		t.Errorf("SMA(3) 应为 5，实际 %v", got)
+	}
+	if got := ema(values, 3); len(got) != 4 || got[0] != 2 || got[3] != 5 {
+		t.Errorf("线性序列的 EMA 应滞后 1，实际 %v", got)
+	}
+	if got := rsi([]float64{1, 2, 3, 4, 5}, 3); got != 100 {
+		t.Errorf("单边上涨 RSI 应为 100，实际 %v", got)
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	if _, size, err := NewStrategy("DualMAStrategy", `{"fast_window": 5, "slow_window": 20, "volume_threshold": 1000}`); err != nil || size != defaultFixedSize {
+		t.Errorf("创建双均线策略失败: size=%d err=%v", size, err)
+	}
+	if _, _, err := NewStrategy("UnknownStrategy", ""); err == nil {
+		t.Error("未知策略类应报错")
+	}
+}
+
+// 先跌后涨再跌：双均线应在上涨段买入、回落后平仓
+func TestPlayerRun(t *testing.T) {
+	strategy, size, err := NewStrategy("DualMAStrategy", `{"fast_window": 2, "slow_window": 3, "fixed_size": 200}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2024, 1, 5, 9, 30, 0, 0, time.Local)
+	warmup := minuteBars(start.AddDate(0, 0, -1), 10, 9.9, 9.8)
+	bars := minuteBars(start, 9.7, 9.8, 10.2, 10.5, 10.3, 9.9, 9.6)
+
+	player, err := NewPlayer(strategy, size, warmup, bars, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []*Event
+	if err := player.Run(context.Background(), func(e *Event) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	var signals []*Signal
+	var barCount int
+	for _, e := range events {
+		switch e.Type {
+		case EventSignal:
+			signals = append(signals, e.Data.(*Signal))
+		case EventBar:
+			barCount++
+		}
+	}
+	if barCount != len(bars) {
+		t.Fatalf("应推送 %d 根K线，实际 %d", len(bars), barCount)
+	}
+	if len(signals) != 2 || signals[0].Action != ActionBuy || signals[1].Action != ActionSell {
+		t.Fatalf("应先买入后卖出，实际 %+v", signals)
+	}
+
+	summary := events[len(events)-1].Data.(*Summary)
+	want := (signals[1].Price - signals[0].Price) * 200
+	if !summary.Completed || summary.Position != 0 || math.Abs(summary.RealizedPnL-want) > 1e-9 {
+		t.Errorf("汇总错误: %+v，期望已实现盈亏 %v", summary, want)
+	}
+}
+
+func TestPlayerPauseAndCancel(t *testing.T) {
+	strategy, size, _ := NewStrategy("RSIStrategy", "")
+	bars := minuteBars(time.Now(), 1, 2, 3)
+	player, err := NewPlayer(strategy, size, nil, bars, time.Minute, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := player.SetSpeed(MaxSpeed + 1); err == nil {
+		t.Error("超出范围的速度应报错")
+	}
+	player.Pause()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var last *Event
+	if err := player.Run(ctx, func(e *Event) error {
+		last = e
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if summary := last.Data.(*Summary); summary.Completed {
+		t.Error("暂停后取消的回放不应标记为完成")
+	}
+}
@@ -0,0 +1,279 @@
+// Package replay 分钟K线回放：按指定速度逐根推送历史分钟K线并驱动策略，便于逐根观察策略行为
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 交易动作，与交易信号的 signal_type 一致
+const (
+	ActionBuy  = "buy"
+	ActionSell = "sell"
+)
+
+// defaultFixedSize 默认每次开仓数量（股）
+const defaultFixedSize = 100
+
+// Strategy 回放使用的策略，逻辑与 vnpy_strategies 中的同名策略一致
+type Strategy interface {
+	// Warmup 指标计算所需的最少K线数
+	Warmup() int
+	// OnBar 推入一根K线，返回最新指标值与交易动作（无动作时为空）
+	// position 为推入前的持仓数量。
+	OnBar(bar *models.MinuteBar, position int64) (indicators map[string]float64, action string)
+}
+
+type builder func(p params) Strategy
+
+var builders = map[string]builder{
+	"DualMAStrategy": newDualMA,
+	"MACDStrategy":   newMACD,
+	"RSIStrategy":    newRSI,
+}
+
+// Supported 支持回放的策略类名
+func Supported() []string {
+	names := make([]string, 0, len(builders))
+	for name := range builders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewStrategy 按策略类名与 JSON 参数创建策略，返回策略与每次开仓数量
+func NewStrategy(className, rawParams string) (Strategy, int64, error) {
+	build, ok := builders[className]
+	if !ok {
+		return nil, 0, fmt.Errorf("回放暂不支持策略类 %s，支持: %s", className, strings.Join(Supported(), ", "))
+	}
+
+	p := params{}
+	if strings.TrimSpace(rawParams) != "" {
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(rawParams), &raw); err != nil {
+			return nil, 0, fmt.Errorf("策略参数不是有效的 JSON: %w", err)
+		}
+		for k, v := range raw {
+			if f, ok := v.(float64); ok {
+				p[k] = f
+			}
+		}
+	}
+
+	size := int64(p.get(defaultFixedSize, "fixed_size"))
+	if size <= 0 {
+		return nil, 0, fmt.Errorf("fixed_size 必须大于0")
+	}
+	return build(p), size, nil
+}
+
+// params 策略数值参数
+type params map[string]float64
+
+// get 按顺序查找参数名，均未设置时返回默认值
+func (p params) get(def float64, names ...string) float64 {
+	for _, name := range names {
+		if v, ok := p[name]; ok {
+			return v
+		}
+	}
+	return def
+}
+
+func (p params) period(def int, names ...string) int {
+	if n := int(p.get(float64(def), names...)); n > 0 {
+		return n
+	}
+	return def
+}
+
+// ============ 双均线 ============
+
+// dualMA 快线在慢线之上且空仓时买入，快线跌破慢线时平仓
+type dualMA struct {
+	fast, slow int
+	closes     []float64
+}
+
+func newDualMA(p params) Strategy {
+	return &dualMA{fast: p.period(10, "fast_window"), slow: p.period(30, "slow_window")}
+}
+
+func (s *dualMA) Warmup() int {
+	if s.fast > s.slow {
+		return s.fast
+	}
+	return s.slow
+}
+
+func (s *dualMA) OnBar(bar *models.MinuteBar, position int64) (map[string]float64, string) {
+	s.closes = append(s.closes, bar.Close)
+	if len(s.closes) < s.Warmup() {
+		return nil, ""
+	}
+
+	fastMA, slowMA := sma(s.closes, s.fast), sma(s.closes, s.slow)
+	indicators := map[string]float64{"fast_ma": fastMA, "slow_ma": slowMA}
+	switch diff := fastMA - slowMA; {
+	case diff > 0 && position == 0:
+		return indicators, ActionBuy
+	case diff < 0 && position > 0:
+		return indicators, ActionSell
+	}
+	return indicators, ""
+}
+
+// ============ MACD ============
+
+// macdStrategy 柱线转正且空仓时买入，柱线转负时平仓
+type macdStrategy struct {
+	fast, slow, signal int
+	closes             []float64
+}
+
+func newMACD(p params) Strategy {
+	return &macdStrategy{
+		fast:   p.period(12, "fast_period"),
+		slow:   p.period(26, "slow_period"),
+		signal: p.period(9, "signal_period"),
+	}
+}
+
+func (s *macdStrategy) Warmup() int {
+	return s.slow + s.signal - 1
+}
+
+func (s *macdStrategy) OnBar(bar *models.MinuteBar, position int64) (map[string]float64, string) {
+	s.closes = append(s.closes, bar.Close)
+	if len(s.closes) < s.Warmup() {
+		return nil, ""
+	}
+
+	dif, dea, hist := macd(s.closes, s.fast, s.slow, s.signal)
+	indicators := map[string]float64{"dif": dif, "dea": dea, "hist": hist}
+	switch {
+	case hist > 0 && position == 0:
+		return indicators, ActionBuy
+	case hist < 0 && position > 0:
+		return indicators, ActionSell
+	}
+	return indicators, ""
+}
+
+// ============ RSI ============
+
+// rsiStrategy RSI 低于超卖线且空仓时买入，高于超买线时平仓
+type rsiStrategy struct {
+	period               int
+	oversold, overbought float64
+	closes               []float64
+}
+
+func newRSI(p params) Strategy {
+	return &rsiStrategy{
+		period:     p.period(14, "rsi_period"),
+		oversold:   p.get(30, "rsi_oversold", "oversold"),
+		overbought: p.get(70, "rsi_overbought", "overbought"),
+	}
+}
+
+func (s *rsiStrategy) Warmup() int {
+	return s.period + 1
+}
+
+func (s *rsiStrategy) OnBar(bar *models.MinuteBar, position int64) (map[string]float64, string) {
+	s.closes = append(s.closes, bar.Close)
+	if len(s.closes) < s.Warmup() {
+		return nil, ""
+	}
+
+	value := rsi(s.closes, s.period)
+	indicators := map[string]float64{"rsi": value}
+	switch {
+	case value < s.oversold && position == 0:
+		return indicators, ActionBuy
+	case value > s.overbought && position > 0:
+		return indicators, ActionSell
+	}
+	return indicators, ""
+}
+
+// ============ 指标 ============
+
+// sma 最近 n 个值的简单移动平均
+func sma(values []float64, n int) float64 {
+	if n <= 0 || len(values) < n {
+		return 0
+	}
+	var sum float64
+	for _, v := range values[len(values)-n:] {
+		sum += v
+	}
+	return sum / float64(n)
+}
+
+// ema 指数移动平均序列，以前 n 个值的简单平均作为初值（与 TA-Lib 一致）
+// 返回序列从第 n 个值开始，长度为 len(values)-n+1。
+func ema(values []float64, n int) []float64 {
+	if n <= 0 || len(values) < n {
+		return nil
+	}
+	k := 2 / float64(n+1)
+	out := make([]float64, 0, len(values)-n+1)
+	prev := sma(values[:n], n)
+	out = append(out, prev)
+	for _, v := range values[n:] {
+		prev = v*k + prev*(1-k)
+		out = append(out, prev)
+	}
+	return out
+}
+
+// macd 返回最新的 DIF、DEA 与柱线（DIF-DEA）
+func macd(values []float64, fast, slow, signal int) (dif, dea, hist float64) {
+	fastEMA, slowEMA := ema(values, fast), ema(values, slow)
+	if len(slowEMA) == 0 || len(fastEMA) < len(slowEMA) {
+		return 0, 0, 0
+	}
+	offset := len(fastEMA) - len(slowEMA)
+	difs := make([]float64, len(slowEMA))
+	for i := range slowEMA {
+		difs[i] = fastEMA[i+offset] - slowEMA[i]
+	}
+	deas := ema(difs, signal)
+	if len(deas) == 0 {
+		return difs[len(difs)-1], 0, 0
+	}
+	dif, dea = difs[len(difs)-1], deas[len(deas)-1]
+	return dif, dea, dif - dea
+}
+
+// rsi Wilder 平滑的相对强弱指数
+func rsi(values []float64, n int) float64 {
+	if n <= 0 || len(values) <= n {
+		return 0
+	}
+	var gain, loss float64
+	for i := 1; i <= n; i++ {
+		change := values[i] - values[i-1]
+		gain += math.Max(change, 0)
+		loss += math.Max(-change, 0)
+	}
+	gain, loss = gain/float64(n), loss/float64(n)
+	for i := n + 1; i < len(values); i++ {
+		change := values[i] - values[i-1]
+		gain = (gain*float64(n-1) + math.Max(change, 0)) / float64(n)
+		loss = (loss*float64(n-1) + math.Max(-change, 0)) / float64(n)
+	}
+	if gain+loss == 0 {
+		return 50
+	}
+	return 100 * gain / (gain + loss)
+}
@@ -0,0 +1,198 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+)
+
+// 图表尺寸（SVG 视图坐标）
+const (
+	chartWidth     = 760
+	equityHeight   = 220
+	drawdownHeight = 110
+)
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"label":   func(key string) string { return label(key, false) },
+	"percent": percent,
+	"money":   money,
+	"cell":    heatColor,
+	"deref":   func(v *float64) float64 { return *v },
+	"pnlClass": func(v float64) string {
+		if v > 0 {
+			return "up"
+		}
+		if v < 0 {
+			return "down"
+		}
+		return ""
+	},
+	"truncated": func(shown, total int) string { return fmt.Sprintf(label("truncated", false), shown, total) },
+}).Parse(htmlPage))
+
+// pageData HTML 模板数据
+type pageData struct {
+	*Report
+	MetricRows   [][]Metric // 每行两项
+	EquityPath   string
+	DrawdownPath string
+	MinEquity    string
+	MaxEquity    string
+	MaxDrawdown  string
+	Months       []int
+}
+
+// HTML 渲染报告为独立的 HTML 页面（无外部资源依赖）
+func HTML(w io.Writer, r *Report) error {
+	minV, maxV := bounds(r.Equity)
+	minDD, _ := bounds(r.Drawdown)
+	data := &pageData{
+		Report:       r,
+		MetricRows:   metricRows(r.Metrics, 2),
+		EquityPath:   linePath(r.Equity, minV, maxV, chartWidth, equityHeight),
+		DrawdownPath: areaPath(r.Drawdown, math.Min(minDD, -0.0001), drawdownHeight),
+		MinEquity:    money(minV),
+		MaxEquity:    money(maxV),
+		MaxDrawdown:  percent(minDD),
+		Months:       []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12},
+	}
+	return htmlTemplate.Execute(w, data)
+}
+
+// metricRows 将指标按每行 n 项分组
+func metricRows(metrics []Metric, n int) [][]Metric {
+	var rows [][]Metric
+	for i := 0; i < len(metrics); i += n {
+		end := i + n
+		if end > len(metrics) {
+			end = len(metrics)
+		}
+		rows = append(rows, metrics[i:end])
+	}
+	return rows
+}
+
+// bounds 序列最小值与最大值
+func bounds(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	minV, maxV := values[0], values[0]
+	for _, v := range values {
+		minV, maxV = math.Min(minV, v), math.Max(maxV, v)
+	}
+	return minV, maxV
+}
+
+// linePath 将序列映射为 SVG 折线路径
+func linePath(values []float64, minV, maxV float64, width, height int) string {
+	if len(values) < 2 {
+		return ""
+	}
+	span := maxV - minV
+	if span == 0 {
+		span = 1
+	}
+	var b strings.Builder
+	for i, v := range values {
+		x := float64(i) / float64(len(values)-1) * float64(width)
+		y := (maxV - v) / span * float64(height)
+		if i == 0 {
+			fmt.Fprintf(&b, "M%.1f,%.1f", x, y)
+		} else {
+			fmt.Fprintf(&b, " L%.1f,%.1f", x, y)
+		}
+	}
+	return b.String()
+}
+
+// areaPath 回撤面积图路径（0 在顶部，minV 在底部）
+func areaPath(values []float64, minV float64, height int) string {
+	line := linePath(values, minV, 0, chartWidth, height)
+	if line == "" {
+		return ""
+	}
+	return fmt.Sprintf("M0,0 %s L%d,0 Z", strings.Replace(line, "M", "L", 1), chartWidth)
+}
+
+// heatColor 热力图单元格背景色：上涨为红、下跌为绿，±10% 时颜色最深
+func heatColor(v *float64) template.CSS {
+	if v == nil {
+		return "background:#f5f5f5"
+	}
+	alpha := math.Min(math.Abs(*v)/0.1, 1)*0.75 + 0.1
+	if *v >= 0 {
+		return template.CSS(fmt.Sprintf("background:rgba(220,38,38,%.2f)", alpha))
+	}
+	return template.CSS(fmt.Sprintf("background:rgba(22,163,74,%.2f)", alpha))
+}
+
+const htmlPage = `<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{label "title"}} #{{.ID}} {{.Strategy}}</title>
+<style>
+  body { font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; color: #1f2937; max-width: 820px; margin: 24px auto; padding: 0 16px; }
+  h1 { font-size: 22px; margin-bottom: 4px; }
+  h2 { font-size: 16px; border-left: 4px solid #2563eb; padding-left: 8px; margin-top: 28px; }
+  .meta { color: #6b7280; font-size: 13px; }
+  .note { background: #fef3c7; padding: 8px 12px; font-size: 13px; border-radius: 4px; }
+  table { border-collapse: collapse; width: 100%; font-size: 13px; }
+  th, td { border: 1px solid #e5e7eb; padding: 4px 6px; text-align: right; }
+  th { background: #f9fafb; }
+  td.left, th.left { text-align: left; }
+  .metrics td { width: 25%; }
+  .heatmap td { text-align: center; }
+  .up { color: #dc2626; }
+  .down { color: #16a34a; }
+  svg { width: 100%; height: auto; background: #fafafa; }
+</style>
+</head>
+<body>
+<h1>{{label "title"}} #{{.ID}}</h1>
+<div class="meta">
+  {{label "strategy"}}：{{.Strategy}}{{if .ClassName}}（{{.ClassName}}）{{end}} ·
+  {{label "period"}}：{{.Start}} ~ {{.End}} ·
+  {{label "generated_at"}}：{{.GeneratedAt.Format "2006-01-02 15:04"}}
+</div>
+{{if .Simulated}}<p class="note">{{label "simulated"}}</p>{{end}}
+
+<h2>{{label "metrics"}}</h2>
+<table class="metrics">
+{{range .MetricRows}}<tr>{{range .}}<th class="left">{{label .Key}}</th><td>{{.Value}}</td>{{end}}</tr>
+{{end}}
+</table>
+
+<h2>{{label "equity"}}</h2>
+<svg viewBox="0 -10 760 240" preserveAspectRatio="none">
+  <path d="{{.EquityPath}}" fill="none" stroke="#2563eb" stroke-width="1.5"/>
+  <text x="4" y="4" font-size="11" fill="#6b7280">{{.MaxEquity}}</text>
+  <text x="4" y="216" font-size="11" fill="#6b7280">{{.MinEquity}}</text>
+</svg>
+
+<h2>{{label "drawdown"}}</h2>
+<svg viewBox="0 0 760 120" preserveAspectRatio="none">
+  <path d="{{.DrawdownPath}}" fill="rgba(22,163,74,0.35)" stroke="#16a34a" stroke-width="1"/>
+  <text x="4" y="106" font-size="11" fill="#6b7280">{{.MaxDrawdown}}</text>
+</svg>
+
+<h2>{{label "monthly"}}</h2>
+<table class="heatmap">
+<tr><th>{{label "year"}}</th>{{range .Months}}<th>{{.}}</th>{{end}}<th>{{label "year_total"}}</th></tr>
+{{range .Heatmap}}<tr><th>{{.Year}}</th>{{range .Months}}<td style="{{cell .}}">{{if .}}{{percent (deref .)}}{{end}}</td>{{end}}<td class="{{pnlClass .Total}}">{{percent .Total}}</td></tr>
+{{end}}</table>
+
+<h2>{{label "trades"}}</h2>
+{{if .Trades}}
+<table>
+<tr><th class="left">{{label "open_date"}}</th><th class="left">{{label "close_date"}}</th><th class="left">{{label "side"}}</th><th class="left">{{label "detail"}}</th><th>{{label "pnl"}}</th></tr>
+{{range .Trades}}<tr><td class="left">{{.OpenDate}}</td><td class="left">{{.CloseDate}}</td><td class="left">{{label .Side}}{{if .Stopped}}（{{label "stopped"}}）{{end}}</td><td class="left">{{.Detail}}</td><td class="{{pnlClass .PnL}}">{{money .PnL}}</td></tr>
+{{end}}</table>
+{{if gt .TotalTrades (len .Trades)}}<p class="meta">{{truncated (len .Trades) .TotalTrades}}</p>{{end}}
+{{else}}<p class="meta">{{label "no_trades"}}</p>{{end}}
+</body>
+</html>`
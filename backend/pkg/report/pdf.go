@@ -0,0 +1,288 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// pdfFont 中文字体族名
+const pdfFont = "cjk"
+
+// PDF 布局（毫米，A4 纵向）
+const (
+	pdfMargin   = 15.0
+	pdfWidth    = 180.0
+	pdfRowH     = 6.0
+	pdfChartH   = 55.0
+	pdfDDChartH = 28.0
+)
+
+// PDF 渲染报告为 A4 PDF
+// fontPath 为 TrueType 中文字体文件（如 NotoSansSC-Regular.ttf）；为空或不存在时使用内置字体与英文文字。
+func PDF(w io.Writer, r *Report, fontPath string) error {
+	hasFont := false
+	if fontPath != "" {
+		_, err := os.Stat(fontPath)
+		hasFont = err == nil
+	}
+
+	// 字体文件按字体目录的相对路径加载
+	pdf := gofpdf.New("P", "mm", "A4", filepath.Dir(fontPath))
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+
+	font, en := "Helvetica", true
+	if hasFont {
+		pdf.AddUTF8Font(pdfFont, "", filepath.Base(fontPath))
+		font, en = pdfFont, false
+	}
+	p := &pdfWriter{pdf: pdf, font: font, en: en}
+	pdf.AddPage()
+
+	// 标题
+	p.text(18, fmt.Sprintf("%s #%d", p.label("title"), r.ID))
+	strategy := r.Strategy
+	if en && !isLatin(strategy) {
+		strategy = r.ClassName
+	}
+	pdf.SetTextColor(107, 114, 128)
+	p.text(9, fmt.Sprintf("%s: %s   %s: %s ~ %s   %s: %s",
+		p.label("strategy"), strategy, p.label("period"), r.Start, r.End,
+		p.label("generated_at"), r.GeneratedAt.Format("2006-01-02 15:04")))
+	pdf.SetTextColor(31, 41, 55)
+	if r.Simulated {
+		pdf.SetFillColor(254, 243, 199)
+		p.setFont(9)
+		pdf.CellFormat(pdfWidth, pdfRowH, p.label("simulated"), "", 1, "L", true, 0, "")
+	}
+
+	// 指标表，每行两项
+	p.heading("metrics")
+	p.setFont(9)
+	pdf.SetFillColor(249, 250, 251)
+	colW := pdfWidth / 4
+	for i, m := range r.Metrics {
+		ln := 0
+		if i%2 == 1 || i == len(r.Metrics)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(colW, pdfRowH, p.label(m.Key), "1", 0, "L", true, 0, "")
+		pdf.CellFormat(colW, pdfRowH, m.Value, "1", ln, "R", false, 0, "")
+	}
+
+	// 净值与回撤曲线
+	p.heading("equity")
+	p.chart(r.Equity, pdfChartH, false)
+	p.heading("drawdown")
+	p.chart(r.Drawdown, pdfDDChartH, true)
+
+	// 月度收益热力图
+	p.heading("monthly")
+	p.heatmap(r.Heatmap)
+
+	// 交易明细
+	p.heading("trades")
+	p.trades(r)
+
+	return pdf.Output(w)
+}
+
+// pdfWriter PDF 绘制辅助
+type pdfWriter struct {
+	pdf  *gofpdf.Fpdf
+	font string
+	en   bool
+}
+
+func (p *pdfWriter) label(key string) string {
+	return label(key, p.en)
+}
+
+func (p *pdfWriter) setFont(size float64) {
+	p.pdf.SetFont(p.font, "", size)
+}
+
+func (p *pdfWriter) text(size float64, s string) {
+	p.setFont(size)
+	p.pdf.CellFormat(pdfWidth, size*0.5+2, s, "", 1, "L", false, 0, "")
+}
+
+func (p *pdfWriter) heading(key string) {
+	p.pdf.Ln(4)
+	x, y := p.pdf.GetXY()
+	p.pdf.SetFillColor(37, 99, 235)
+	p.pdf.Rect(x, y+1, 1.2, 5, "F")
+	p.pdf.SetX(x + 3)
+	p.text(12, p.label(key))
+	p.pdf.Ln(1)
+}
+
+// chart 绘制折线图，area 为 true 时绘制 0 轴以下的面积（回撤）
+func (p *pdfWriter) chart(values []float64, height float64, area bool) {
+	pdf := p.pdf
+	if pdf.GetY()+height > 297-pdfMargin {
+		pdf.AddPage()
+	}
+	x0, y0 := pdf.GetXY()
+	pdf.SetFillColor(250, 250, 250)
+	pdf.Rect(x0, y0, pdfWidth, height, "F")
+
+	minV, maxV := bounds(values)
+	if area {
+		maxV = 0
+		minV = math.Min(minV, -0.0001)
+	}
+	span := maxV - minV
+	if span == 0 {
+		span = 1
+	}
+	point := func(i int, v float64) gofpdf.PointType {
+		return gofpdf.PointType{
+			X: x0 + float64(i)/float64(len(values)-1)*pdfWidth,
+			Y: y0 + (maxV-v)/span*height,
+		}
+	}
+
+	if len(values) > 1 {
+		if area {
+			points := []gofpdf.PointType{{X: x0, Y: y0}}
+			for i, v := range values {
+				points = append(points, point(i, v))
+			}
+			points = append(points, gofpdf.PointType{X: x0 + pdfWidth, Y: y0})
+			pdf.SetFillColor(167, 214, 184)
+			pdf.SetDrawColor(22, 163, 74)
+			pdf.Polygon(points, "DF")
+		} else {
+			pdf.SetDrawColor(37, 99, 235)
+			pdf.SetLineWidth(0.4)
+			prev := point(0, values[0])
+			for i := 1; i < len(values); i++ {
+				cur := point(i, values[i])
+				pdf.Line(prev.X, prev.Y, cur.X, cur.Y)
+				prev = cur
+			}
+			pdf.SetLineWidth(0.2)
+		}
+	}
+
+	// 坐标标注
+	p.setFont(7)
+	pdf.SetTextColor(107, 114, 128)
+	if area {
+		pdf.SetXY(x0+1, y0+height-4)
+		pdf.CellFormat(40, 4, percent(minV), "", 0, "L", false, 0, "")
+	} else {
+		pdf.SetXY(x0+1, y0+1)
+		pdf.CellFormat(40, 4, money(maxV), "", 0, "L", false, 0, "")
+		pdf.SetXY(x0+1, y0+height-4)
+		pdf.CellFormat(40, 4, money(minV), "", 0, "L", false, 0, "")
+	}
+	pdf.SetTextColor(31, 41, 55)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetXY(x0, y0+height+1)
+}
+
+// heatmap 绘制月度收益热力图
+func (p *pdfWriter) heatmap(rows []*HeatmapRow) {
+	pdf := p.pdf
+	p.setFont(7)
+	yearW, totalW := 14.0, 18.0
+	cellW := (pdfWidth - yearW - totalW) / 12
+
+	pdf.SetFillColor(249, 250, 251)
+	pdf.CellFormat(yearW, pdfRowH, p.label("year"), "1", 0, "C", true, 0, "")
+	for m := 1; m <= 12; m++ {
+		pdf.CellFormat(cellW, pdfRowH, strconv.Itoa(m), "1", 0, "C", true, 0, "")
+	}
+	pdf.CellFormat(totalW, pdfRowH, p.label("year_total"), "1", 1, "C", true, 0, "")
+
+	for _, row := range rows {
+		pdf.SetFillColor(249, 250, 251)
+		pdf.CellFormat(yearW, pdfRowH, row.Year, "1", 0, "C", true, 0, "")
+		for _, v := range row.Months {
+			red, green, blue := heatRGB(v)
+			pdf.SetFillColor(red, green, blue)
+			text := ""
+			if v != nil {
+				text = percent(*v)
+			}
+			pdf.CellFormat(cellW, pdfRowH, text, "1", 0, "C", true, 0, "")
+		}
+		pdf.CellFormat(totalW, pdfRowH, percent(row.Total), "1", 1, "C", false, 0, "")
+	}
+}
+
+// trades 绘制交易明细表
+func (p *pdfWriter) trades(r *Report) {
+	pdf := p.pdf
+	p.setFont(8)
+	if len(r.Trades) == 0 {
+		pdf.CellFormat(pdfWidth, pdfRowH, p.label("no_trades"), "", 1, "L", false, 0, "")
+		return
+	}
+
+	widths := []float64{25, 25, 32, 68, 30}
+	headers := []string{"open_date", "close_date", "side", "detail", "pnl"}
+	pdf.SetFillColor(249, 250, 251)
+	for i, h := range headers {
+		align := "L"
+		if i == len(headers)-1 {
+			align = "R"
+		}
+		pdf.CellFormat(widths[i], pdfRowH, p.label(h), "1", 0, align, true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	for _, t := range r.Trades {
+		side := p.label(t.Side)
+		if t.Stopped {
+			side += " (" + p.label("stopped") + ")"
+		}
+		pdf.CellFormat(widths[0], pdfRowH, t.OpenDate, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], pdfRowH, t.CloseDate, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], pdfRowH, side, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], pdfRowH, t.Detail, "1", 0, "L", false, 0, "")
+		switch {
+		case t.PnL > 0:
+			pdf.SetTextColor(220, 38, 38)
+		case t.PnL < 0:
+			pdf.SetTextColor(22, 163, 74)
+		}
+		pdf.CellFormat(widths[4], pdfRowH, money(t.PnL), "1", 1, "R", false, 0, "")
+		pdf.SetTextColor(31, 41, 55)
+	}
+	if r.TotalTrades > len(r.Trades) {
+		p.setFont(7)
+		pdf.CellFormat(pdfWidth, pdfRowH, fmt.Sprintf(p.label("truncated"), len(r.Trades), r.TotalTrades), "", 1, "L", false, 0, "")
+	}
+}
+
+// heatRGB 热力图单元格颜色（与 HTML 一致，按透明度与白色混合）
+func heatRGB(v *float64) (int, int, int) {
+	if v == nil {
+		return 245, 245, 245
+	}
+	alpha := math.Min(math.Abs(*v)/0.1, 1)*0.75 + 0.1
+	blend := func(c int) int { return int(255 - (255-float64(c))*alpha) }
+	if *v >= 0 {
+		return blend(220), blend(38), blend(38)
+	}
+	return blend(22), blend(163), blend(74)
+}
+
+// isLatin 是否只包含内置字体可显示的字符
+func isLatin(s string) bool {
+	for _, r := range s {
+		if r > 0xff {
+			return false
+		}
+	}
+	return true
+}
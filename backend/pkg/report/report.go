@@ -0,0 +1,212 @@
+// Package report 回测报告（tear sheet）：指标表、净值与回撤曲线、月度收益热力图与交易明细，支持 HTML 与 PDF 输出
+package report
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// MaxTrades 报告中最多列出的交易笔数
+const MaxTrades = 200
+
+// 报告格式
+const (
+	FormatHTML = "html"
+	FormatPDF  = "pdf"
+)
+
+// Report 回测报告数据
+type Report struct {
+	ID          uint
+	Strategy    string
+	ClassName   string
+	Start       string
+	End         string
+	Simulated   bool // 净值为模拟曲线
+	GeneratedAt time.Time
+
+	Metrics     []Metric
+	Dates       []string
+	Equity      []float64
+	Drawdown    []float64 // 相对前期高点的回撤（负数）
+	Heatmap     []*HeatmapRow
+	Trades      []*Trade
+	TotalTrades int // 交易总数，超过 MaxTrades 时只列出前 MaxTrades 笔
+}
+
+// Metric 指标表中的一项，Key 对应 labels 中的名称
+type Metric struct {
+	Key   string
+	Value string
+}
+
+// HeatmapRow 月度收益热力图的一行（一年）
+type HeatmapRow struct {
+	Year   string
+	Months [12]*float64 // 无数据的月份为空
+	Total  float64      // 年度收益
+}
+
+// Trade 交易明细
+type Trade struct {
+	OpenDate  string
+	CloseDate string
+	Side      string // labels 中的方向名称，如 long_spread
+	Detail    string // 数量、价格等补充信息
+	PnL       float64
+	Stopped   bool
+}
+
+// New 根据回测记录与净值曲线生成报告数据
+// dates 与 equity 一一对应，trades 为逐笔交易（没有时为空）。
+func New(record *models.BacktestRecord, strategy *models.Strategy, dates []string, equity []float64, trades []*Trade, simulated bool) *Report {
+	r := &Report{
+		ID:          record.ID,
+		Start:       record.StartDate.Format("2006-01-02"),
+		End:         record.EndDate.Format("2006-01-02"),
+		Simulated:   simulated,
+		GeneratedAt: time.Now(),
+		Dates:       dates,
+		Equity:      equity,
+		Drawdown:    drawdowns(equity),
+		TotalTrades: len(trades),
+	}
+	cal := risk.NewCalendar(dates, equity)
+	r.Heatmap = heatmap(cal.Monthly, cal.Yearly)
+	if strategy != nil {
+		r.Strategy, r.ClassName = strategy.Name, strategy.ClassName
+	}
+	if len(trades) > MaxTrades {
+		trades = trades[:MaxTrades]
+	}
+	r.Trades = trades
+
+	metrics := risk.Compute(equity, 0.95)
+	r.Metrics = []Metric{
+		{"initial_capital", money(record.InitialCapital)},
+		{"final_capital", money(record.FinalCapital)},
+		{"total_return", percent(record.TotalReturn)},
+		{"annual_return", percent(record.AnnualReturn)},
+		{"max_drawdown", percent(record.MaxDrawdown)},
+		{"sharpe_ratio", strconv.FormatFloat(record.SharpeRatio, 'f', 2, 64)},
+		{"volatility", percent(metrics.Volatility)},
+		{"var", percent(metrics.VaR)},
+		{"win_rate", percent(record.WinRate)},
+		{"profit_loss_ratio", strconv.FormatFloat(record.ProfitLossRatio, 'f', 2, 64)},
+		{"trade_count", strconv.Itoa(record.TradeCount)},
+	}
+	if cal.BestMonth != nil {
+		r.Metrics = append(r.Metrics,
+			Metric{"best_month", cal.BestMonth.Period + " " + percent(cal.BestMonth.Return)},
+			Metric{"worst_month", cal.WorstMonth.Period + " " + percent(cal.WorstMonth.Return)},
+		)
+	}
+	if dd := cal.LongestDrawdown; dd != nil {
+		r.Metrics = append(r.Metrics, Metric{"longest_drawdown", strconv.Itoa(dd.Days)})
+	}
+	return r
+}
+
+// drawdowns 逐日回撤序列
+func drawdowns(equity []float64) []float64 {
+	out := make([]float64, len(equity))
+	var peak float64
+	for i, v := range equity {
+		peak = math.Max(peak, v)
+		if peak > 0 {
+			out[i] = v/peak - 1
+		}
+	}
+	return out
+}
+
+// heatmap 将月度收益按年排列，并附上年度收益
+func heatmap(monthly, yearly []*risk.PeriodReturn) []*HeatmapRow {
+	var rows []*HeatmapRow
+	for _, m := range monthly {
+		year := m.Period[:4]
+		month, err := strconv.Atoi(m.Period[5:7])
+		if err != nil || month < 1 || month > 12 {
+			continue
+		}
+		if len(rows) == 0 || rows[len(rows)-1].Year != year {
+			rows = append(rows, &HeatmapRow{Year: year})
+		}
+		ret := m.Return
+		rows[len(rows)-1].Months[month-1] = &ret
+	}
+	totals := make(map[string]float64, len(yearly))
+	for _, y := range yearly {
+		totals[y.Period] = y.Return
+	}
+	for _, row := range rows {
+		row.Total = totals[row.Year]
+	}
+	return rows
+}
+
+func percent(v float64) string {
+	return strconv.FormatFloat(v*100, 'f', 2, 64) + "%"
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", v)
+}
+
+// labels 报告文字（中文 / 英文）
+// PDF 未配置中文字体时使用英文。
+var labels = map[string][2]string{
+	"title":             {"回测报告", "Backtest Report"},
+	"strategy":          {"策略", "Strategy"},
+	"period":            {"回测区间", "Period"},
+	"generated_at":      {"生成时间", "Generated"},
+	"simulated":         {"注：该策略类型尚未接入回测引擎，净值曲线为模拟数据。", "Note: equity curve is simulated for this strategy type."},
+	"metrics":           {"绩效指标", "Performance"},
+	"initial_capital":   {"初始资金", "Initial Capital"},
+	"final_capital":     {"期末资金", "Final Capital"},
+	"total_return":      {"总收益率", "Total Return"},
+	"annual_return":     {"年化收益率", "Annual Return"},
+	"max_drawdown":      {"最大回撤", "Max Drawdown"},
+	"sharpe_ratio":      {"夏普比率", "Sharpe Ratio"},
+	"volatility":        {"年化波动率", "Volatility"},
+	"var":               {"单日 VaR(95%)", "Daily VaR (95%)"},
+	"win_rate":          {"胜率", "Win Rate"},
+	"profit_loss_ratio": {"盈亏比", "Profit/Loss Ratio"},
+	"trade_count":       {"交易次数", "Trades"},
+	"best_month":        {"最佳月份", "Best Month"},
+	"worst_month":       {"最差月份", "Worst Month"},
+	"longest_drawdown":  {"最长回撤（交易日）", "Longest Drawdown (days)"},
+	"equity":            {"净值曲线", "Equity Curve"},
+	"drawdown":          {"回撤", "Drawdown"},
+	"monthly":           {"月度收益", "Monthly Returns"},
+	"year":              {"年份", "Year"},
+	"year_total":        {"全年", "Year"},
+	"trades":            {"交易明细", "Trades"},
+	"no_trades":         {"无逐笔交易记录", "No trades recorded"},
+	"truncated":         {"仅列出前 %d 笔，共 %d 笔", "Showing first %d of %d trades"},
+	"open_date":         {"开仓日", "Opened"},
+	"close_date":        {"平仓日", "Closed"},
+	"side":              {"方向", "Side"},
+	"detail":            {"明细", "Detail"},
+	"pnl":               {"盈亏", "P&L"},
+	"long_spread":       {"做多价差", "Long spread"},
+	"short_spread":      {"做空价差", "Short spread"},
+	"stopped":           {"止损", "stop"},
+}
+
+// label 返回指定语言的文字，en 为 true 时使用英文
+func label(key string, en bool) string {
+	l, ok := labels[key]
+	if !ok {
+		return key
+	}
+	if en {
+		return l[1]
+	}
+	return l[0]
+}
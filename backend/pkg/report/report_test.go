@@ -0,0 +1,64 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func sampleReport() *Report {
+	record := &models.BacktestRecord{
+		ID:             7,
+		StartDate:      time.Date(2023, 12, 28, 0, 0, 0, 0, time.Local),
+		EndDate:        time.Date(2024, 2, 1, 0, 0, 0, 0, time.Local),
+		InitialCapital: 100000,
+		FinalCapital:   99000,
+		TotalReturn:    -0.01,
+		TradeCount:     1,
+	}
+	strategy := &models.Strategy{Name: "双均线策略", ClassName: "DualMAStrategy"}
+	dates := []string{"2023-12-28", "2023-12-29", "2024-01-02", "2024-01-31", "2024-02-01"}
+	equity := []float64{100000, 110000, 104500, 99000, 99000}
+	trades := []*Trade{{OpenDate: "2024-01-02", CloseDate: "2024-01-31", Side: "short_spread", Detail: "100 / 80", PnL: -5500}}
+	return New(record, strategy, dates, equity, trades, true)
+}
+
+func TestNew(t *testing.T) {
+	r := sampleReport()
+	if len(r.Heatmap) != 2 || r.Heatmap[0].Year != "2023" || r.Heatmap[1].Year != "2024" {
+		t.Fatalf("热力图应按年分行: %+v", r.Heatmap)
+	}
+	jan := r.Heatmap[1].Months[0]
+	if jan == nil || math.Abs(*jan+0.1) > 1e-9 || r.Heatmap[1].Months[1] == nil || r.Heatmap[1].Months[2] != nil {
+		t.Errorf("2024 年月度收益错误: %+v", r.Heatmap[1].Months)
+	}
+	if dd := r.Drawdown[3]; math.Abs(dd+0.1) > 1e-9 {
+		t.Errorf("回撤应为 -10%%，实际 %v", dd)
+	}
+}
+
+func TestRender(t *testing.T) {
+	r := sampleReport()
+
+	var html bytes.Buffer
+	if err := HTML(&html, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"回测报告 #7", "双均线策略", "做空价差", "-10.00%", "<path d=\"M0.0,"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("HTML 报告缺少 %q", want)
+		}
+	}
+
+	var pdf bytes.Buffer
+	if err := PDF(&pdf, r, ""); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(pdf.Bytes(), []byte("%PDF")) {
+		t.Error("PDF 输出格式错误")
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// AlertRuleRepository 提醒规则仓库接口
+type AlertRuleRepository interface {
+	Create(ctx context.Context, rule *models.AlertRule) error
+	Update(ctx context.Context, rule *models.AlertRule) error
+	Delete(ctx context.Context, id uint) error
+	GetByID(ctx context.Context, id uint) (*models.AlertRule, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.AlertRule, error)
+	GetEnabled(ctx context.Context) ([]*models.AlertRule, error)
+
+	// 增量检查
+	Advance(ctx context.Context, rule *models.AlertRule, lastBarAt time.Time, events []*models.AlertEvent) error
+	GetEvents(ctx context.Context, userID, ruleID uint, since time.Time, limit int) ([]*models.AlertEvent, error)
+}
+
+// alertRuleRepository 提醒规则仓库实现
+type alertRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewAlertRuleRepository 创建提醒规则仓库
+func NewAlertRuleRepository(db *gorm.DB) AlertRuleRepository {
+	return &alertRuleRepository{db: db}
+}
+
+// Create 创建提醒规则
+func (r *alertRuleRepository) Create(ctx context.Context, rule *models.AlertRule) error {
+	return r.db.WithContext(ctx).Create(rule).Error
+}
+
+// Update 更新提醒规则
+func (r *alertRuleRepository) Update(ctx context.Context, rule *models.AlertRule) error {
+	return r.db.WithContext(ctx).Save(rule).Error
+}
+
+// Delete 删除提醒规则及其触发记录
+func (r *alertRuleRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("rule_id = ?", id).Delete(&models.AlertEvent{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.AlertRule{}, id).Error
+	})
+}
+
+// GetByID 根据ID获取提醒规则
+func (r *alertRuleRepository) GetByID(ctx context.Context, id uint) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	if err := r.db.WithContext(ctx).First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetByUserID 获取用户的全部提醒规则
+func (r *alertRuleRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetEnabled 获取全部启用的提醒规则，按股票排序便于分组加载K线
+func (r *alertRuleRepository) GetEnabled(ctx context.Context) ([]*models.AlertRule, error) {
+	var rules []*models.AlertRule
+	if err := r.db.WithContext(ctx).Where("enabled = ?", true).
+		Order("symbol, exchange, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// Advance 把规则的检查游标推进到 lastBarAt 并保存触发记录，同一规则同一交易日的重复触发忽略
+// 检查期间规则被用户修改（updated_at 变化）时放弃本次结果，下次按新条件检查。
+func (r *alertRuleRepository) Advance(ctx context.Context, rule *models.AlertRule, lastBarAt time.Time, events []*models.AlertEvent) error {
+	updates := map[string]interface{}{"last_bar_at": lastBarAt}
+	if len(events) > 0 {
+		updates["last_triggered_at"] = events[len(events)-1].TradeDate
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.AlertRule{}).Where("id = ? AND updated_at = ?", rule.ID, rule.UpdatedAt).
+			UpdateColumns(updates)
+		if result.Error != nil || result.RowsAffected == 0 || len(events) == 0 {
+			return result.Error
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "rule_id"}, {Name: "trade_date"}},
+			DoNothing: true,
+		}).Create(events).Error
+	})
+}
+
+// GetEvents 获取用户的触发记录，按K线日期倒序；ruleID 为 0 时不限规则，since 为零值时不限时间
+func (r *alertRuleRepository) GetEvents(ctx context.Context, userID, ruleID uint, since time.Time, limit int) ([]*models.AlertEvent, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if ruleID > 0 {
+		query = query.Where("rule_id = ?", ruleID)
+	}
+	if !since.IsZero() {
+		query = query.Where("trade_date >= ?", since.Format("2006-01-02"))
+	}
+	var events []*models.AlertEvent
+	if err := query.Order("trade_date DESC, id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
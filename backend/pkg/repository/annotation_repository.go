@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// AnnotationRepository 图表标注仓库接口
+type AnnotationRepository interface {
+	Create(ctx context.Context, annotation *models.ChartAnnotation) error
+	Update(ctx context.Context, annotation *models.ChartAnnotation) error
+	Delete(ctx context.Context, id uint) error
+	GetByID(ctx context.Context, id uint) (*models.ChartAnnotation, error)
+	List(ctx context.Context, userID uint, symbol, exchange, period string) ([]*models.ChartAnnotation, error)
+	Count(ctx context.Context, userID uint, symbol, exchange, period string) (int64, error)
+	DeleteBySymbol(ctx context.Context, userID uint, symbol, exchange, period string) (int64, error)
+}
+
+// annotationRepository 图表标注仓库实现
+type annotationRepository struct {
+	db *gorm.DB
+}
+
+// NewAnnotationRepository 创建图表标注仓库
+func NewAnnotationRepository(db *gorm.DB) AnnotationRepository {
+	return &annotationRepository{db: db}
+}
+
+// Create 创建标注
+func (r *annotationRepository) Create(ctx context.Context, annotation *models.ChartAnnotation) error {
+	return r.db.WithContext(ctx).Create(annotation).Error
+}
+
+// Update 更新标注
+func (r *annotationRepository) Update(ctx context.Context, annotation *models.ChartAnnotation) error {
+	return r.db.WithContext(ctx).Save(annotation).Error
+}
+
+// Delete 删除标注
+func (r *annotationRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.ChartAnnotation{}, id).Error
+}
+
+// GetByID 根据ID获取标注
+func (r *annotationRepository) GetByID(ctx context.Context, id uint) (*models.ChartAnnotation, error) {
+	var annotation models.ChartAnnotation
+	if err := r.db.WithContext(ctx).First(&annotation, id).Error; err != nil {
+		return nil, err
+	}
+	return &annotation, nil
+}
+
+// List 获取用户在某只股票上的标注，period 为空时返回全部周期
+func (r *annotationRepository) List(ctx context.Context, userID uint, symbol, exchange, period string) ([]*models.ChartAnnotation, error) {
+	var annotations []*models.ChartAnnotation
+	err := r.scope(ctx, userID, symbol, exchange, period).Order("created_at").Find(&annotations).Error
+	if err != nil {
+		return nil, err
+	}
+	return annotations, nil
+}
+
+// Count 统计用户在某只股票上的标注数量，period 为空时统计全部周期
+func (r *annotationRepository) Count(ctx context.Context, userID uint, symbol, exchange, period string) (int64, error) {
+	var count int64
+	err := r.scope(ctx, userID, symbol, exchange, period).Model(&models.ChartAnnotation{}).Count(&count).Error
+	return count, err
+}
+
+// DeleteBySymbol 清除用户在某只股票上的标注，返回删除条数；period 为空时清除全部周期
+func (r *annotationRepository) DeleteBySymbol(ctx context.Context, userID uint, symbol, exchange, period string) (int64, error) {
+	result := r.scope(ctx, userID, symbol, exchange, period).Delete(&models.ChartAnnotation{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *annotationRepository) scope(ctx context.Context, userID uint, symbol, exchange, period string) *gorm.DB {
+	query := r.db.WithContext(ctx).Where("user_id = ? AND symbol = ? AND exchange = ?", userID, symbol, exchange)
+	if period != "" {
+		query = query.Where("period = ?", period)
+	}
+	return query
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// AuditRepository 管理员操作审计日志仓库接口
+type AuditRepository interface {
+	Create(ctx context.Context, log *models.AuditLog) error
+	List(ctx context.Context, action string, page, pageSize int) ([]*models.AuditLog, int64, error)
+}
+
+// auditRepository 管理员操作审计日志仓库实现
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository 创建管理员操作审计日志仓库
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create 记录一次操作
+func (r *auditRepository) Create(ctx context.Context, log *models.AuditLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+// List 按时间倒序分页查询审计日志，action 为空时返回全部类型
+func (r *auditRepository) List(ctx context.Context, action string, page, pageSize int) ([]*models.AuditLog, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.AuditLog{})
+	if action != "" {
+		query = query.Where("action = ?", action)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*models.AuditLog
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&logs).Error; err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 	"stock-analysis-system/backend/pkg/models"
@@ -14,6 +15,9 @@ type BacktestRepository interface {
 	GetByID(ctx context.Context, id uint) (*models.BacktestRecord, error)
 	GetByStrategyID(ctx context.Context, strategyID uint, page, pageSize int) ([]*models.BacktestRecord, int64, error)
 	GetByUserID(ctx context.Context, userID uint, page, pageSize int) ([]*models.BacktestRecord, int64, error)
+	GetBySymbol(ctx context.Context, userID, strategyID uint, symbol, exchange string, page, pageSize int) ([]*models.BacktestRecord, int64, error)
+	FailRunning(ctx context.Context) (int64, error)
+	MarkStale(ctx context.Context, symbol, exchange string, start, end time.Time, reason string) (int64, error)
 }
 
 // backtestRepository 回测数据仓库实现
@@ -79,3 +83,54 @@ func (r *backtestRepository) GetByUserID(ctx context.Context, userID uint, page,
 
 	return records, total, nil
 }
+
+// backtestSymbols 展开回测参数中的股票列表，params.symbols 为 JSON 数组，旧记录可能为 null
+const backtestSymbols = "jsonb_array_elements_text(CASE WHEN jsonb_typeof(params->'symbols') = 'array' THEN params->'symbols' ELSE '[]'::jsonb END)"
+
+// GetBySymbol 获取用户回测股票中包含指定股票的回测记录，strategyID 为 0 时不限策略
+// 回测股票取自回测参数（引用股票池时为回测结束日的时点成分）；未指定交易所时按代码匹配任意交易所。
+func (r *backtestRepository) GetBySymbol(ctx context.Context, userID, strategyID uint, symbol, exchange string, page, pageSize int) ([]*models.BacktestRecord, int64, error) {
+	var records []*models.BacktestRecord
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.BacktestRecord{})
+	if strategyID != 0 {
+		query = query.Where("strategy_id = ?", strategyID)
+	} else {
+		query = query.Where("strategy_id IN (?)", r.db.Model(&models.Strategy{}).Where("user_id = ?", userID).Select("id"))
+	}
+	if exchange != "" {
+		query = query.Where("EXISTS (SELECT 1 FROM "+backtestSymbols+" AS s WHERE s = ?)", symbol+"."+exchange)
+	} else {
+		query = query.Where("EXISTS (SELECT 1 FROM "+backtestSymbols+" AS s WHERE split_part(s, '.', 1) = ?)", symbol)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// FailRunning 将仍处于 running 状态的回测标记为失败（服务异常退出后恢复时使用）
+func (r *backtestRepository) FailRunning(ctx context.Context) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.BacktestRecord{}).
+		Where("status = ?", "running").
+		Updates(map[string]interface{}{"status": "failed", "completed_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
+// MarkStale 将回测股票包含指定股票、回测区间与 [start, end] 有交集的已完成回测标记为过期，返回标记的条数
+// 已标记过的回测保留最早一次的标记时间与原因。
+func (r *backtestRepository) MarkStale(ctx context.Context, symbol, exchange string, start, end time.Time, reason string) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&models.BacktestRecord{}).
+		Where("status = ? AND stale_at IS NULL", "completed").
+		Where("start_date <= ? AND end_date >= ?", end, start).
+		Where("EXISTS (SELECT 1 FROM "+backtestSymbols+" AS s WHERE s = ?)", symbol+"."+exchange).
+		Updates(map[string]interface{}{"stale_at": time.Now(), "stale_reason": reason})
+	return result.RowsAffected, result.Error
+}
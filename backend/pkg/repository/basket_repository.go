@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// BasketRepository 股票篮子仓库接口
+type BasketRepository interface {
+	Create(ctx context.Context, basket *models.Basket) error
+	Update(ctx context.Context, basket *models.Basket, resetValues bool) error
+	Delete(ctx context.Context, id uint) error
+	GetByID(ctx context.Context, id uint) (*models.Basket, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.Basket, error)
+	GetAll(ctx context.Context) ([]*models.Basket, error)
+
+	// 每日点位
+	SaveValues(ctx context.Context, values []*models.BasketValue) error
+	GetValues(ctx context.Context, basketID uint, start, end time.Time) ([]*models.BasketValue, error)
+	GetLatestValue(ctx context.Context, basketID uint) (*models.BasketValue, error)
+}
+
+// basketRepository 股票篮子仓库实现
+type basketRepository struct {
+	db *gorm.DB
+}
+
+// NewBasketRepository 创建股票篮子仓库
+func NewBasketRepository(db *gorm.DB) BasketRepository {
+	return &basketRepository{db: db}
+}
+
+// Create 创建篮子
+func (r *basketRepository) Create(ctx context.Context, basket *models.Basket) error {
+	return r.db.WithContext(ctx).Create(basket).Error
+}
+
+// Update 更新篮子，resetValues 为 true 时（成分或基日变化）同时删除已保存的点位，之后按新的成分重新计算
+func (r *basketRepository) Update(ctx context.Context, basket *models.Basket, resetValues bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if resetValues {
+			if err := tx.Where("basket_id = ?", basket.ID).Delete(&models.BasketValue{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(basket).Error
+	})
+}
+
+// Delete 删除篮子及其点位
+func (r *basketRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("basket_id = ?", id).Delete(&models.BasketValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Basket{}, id).Error
+	})
+}
+
+// GetByID 根据ID获取篮子
+func (r *basketRepository) GetByID(ctx context.Context, id uint) (*models.Basket, error) {
+	var basket models.Basket
+	if err := r.db.WithContext(ctx).First(&basket, id).Error; err != nil {
+		return nil, err
+	}
+	return &basket, nil
+}
+
+// GetByUserID 获取用户的全部篮子
+func (r *basketRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.Basket, error) {
+	var baskets []*models.Basket
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&baskets).Error; err != nil {
+		return nil, err
+	}
+	return baskets, nil
+}
+
+// GetAll 获取全部篮子，用于每日计算点位
+func (r *basketRepository) GetAll(ctx context.Context) ([]*models.Basket, error) {
+	var baskets []*models.Basket
+	if err := r.db.WithContext(ctx).Order("id ASC").Find(&baskets).Error; err != nil {
+		return nil, err
+	}
+	return baskets, nil
+}
+
+// SaveValues 保存每日点位，同一交易日已有点位时覆盖
+func (r *basketRepository) SaveValues(ctx context.Context, values []*models.BasketValue) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "basket_id"}, {Name: "trade_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "change_pct", "coverage"}),
+	}).CreateInBatches(values, 500).Error
+}
+
+// GetValues 获取时间范围内的每日点位，按交易日排序
+func (r *basketRepository) GetValues(ctx context.Context, basketID uint, start, end time.Time) ([]*models.BasketValue, error) {
+	var values []*models.BasketValue
+	if err := r.db.WithContext(ctx).
+		Where("basket_id = ?", basketID).
+		Where("trade_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("trade_date ASC").
+		Find(&values).Error; err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// GetLatestValue 获取最近一个交易日的点位，尚未计算时返回 nil
+func (r *basketRepository) GetLatestValue(ctx context.Context, basketID uint) (*models.BasketValue, error) {
+	var value models.BasketValue
+	err := r.db.WithContext(ctx).
+		Where("basket_id = ?", basketID).
+		Order("trade_date DESC").
+		First(&value).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// CalendarQuery 公司事件查询条件，Symbol、Exchange、Types 为空时不限
+type CalendarQuery struct {
+	Start    time.Time
+	End      time.Time
+	Symbol   string
+	Exchange string
+	Types    []string
+	Page     int
+	PageSize int
+}
+
+// CalendarRepository 公司事件日历仓库接口
+type CalendarRepository interface {
+	SaveBatch(ctx context.Context, events []*models.CorporateEvent) error
+	Query(ctx context.Context, q CalendarQuery) ([]*models.CorporateEvent, int64, error)
+	GetBySymbol(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.CorporateEvent, error)
+}
+
+// calendarRepository 公司事件日历仓库实现
+type calendarRepository struct {
+	db *gorm.DB
+}
+
+// NewCalendarRepository 创建公司事件日历仓库
+func NewCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &calendarRepository{db: db}
+}
+
+// SaveBatch 批量保存公司事件（同一股票同一类型同一日期重复同步时覆盖）
+func (r *calendarRepository) SaveBatch(ctx context.Context, events []*models.CorporateEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "symbol"}, {Name: "exchange"}, {Name: "event_type"}, {Name: "event_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "title", "detail", "source", "updated_at"}),
+		}).
+		CreateInBatches(events, 200).Error
+}
+
+// Query 按日期范围查询公司事件，按日期、股票排序并分页
+func (r *calendarRepository) Query(ctx context.Context, q CalendarQuery) ([]*models.CorporateEvent, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.CorporateEvent{}).
+		Where("event_date BETWEEN ? AND ?", q.Start.Format("2006-01-02"), q.End.Format("2006-01-02"))
+	if q.Symbol != "" {
+		query = query.Where("symbol = ?", q.Symbol)
+	}
+	if q.Exchange != "" {
+		query = query.Where("exchange = ?", q.Exchange)
+	}
+	if len(q.Types) > 0 {
+		query = query.Where("event_type IN ?", q.Types)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var events []*models.CorporateEvent
+	if err := query.Order("event_date, symbol, event_type").
+		Offset((q.Page - 1) * q.PageSize).Limit(q.PageSize).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// GetBySymbol 获取个股在日期范围内的公司事件，按日期排序
+func (r *calendarRepository) GetBySymbol(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.CorporateEvent, error) {
+	var events []*models.CorporateEvent
+	if err := r.db.WithContext(ctx).
+		Where("symbol = ? AND exchange = ?", symbol, exchange).
+		Where("event_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("event_date, event_type").
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// StockConnectRepository 沪深港通资金仓库接口
+type StockConnectRepository interface {
+	// 每日成交资金
+	SaveFlows(ctx context.Context, flows []*models.ConnectFlow) error
+	GetFlows(ctx context.Context, direction string, start, end time.Time) ([]*models.ConnectFlow, error)
+
+	// 北向个股持股
+	SaveHoldings(ctx context.Context, holdings []*models.NorthboundHolding) error
+	GetHoldings(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.NorthboundHolding, error)
+	GetMarketHoldings(ctx context.Context, start, end time.Time) (map[string][]*models.NorthboundHolding, error)
+}
+
+// stockConnectRepository 沪深港通资金仓库实现
+type stockConnectRepository struct {
+	influx *database.InfluxClient
+}
+
+// NewStockConnectRepository 创建沪深港通资金仓库
+func NewStockConnectRepository(influx *database.InfluxClient) StockConnectRepository {
+	return &stockConnectRepository{influx: influx}
+}
+
+// ============ 每日成交资金 ============
+
+// SaveFlows 批量保存沪深港通每日成交资金
+func (r *stockConnectRepository) SaveFlows(ctx context.Context, flows []*models.ConnectFlow) error {
+	points := make([]*write.Point, 0, len(flows))
+	for _, flow := range flows {
+		points = append(points, write.NewPoint(
+			"connect_flow",
+			map[string]string{
+				"direction": flow.Direction,
+				"channel":   flow.Channel,
+			},
+			map[string]interface{}{
+				"net_inflow":  flow.NetInflow,
+				"buy_amount":  flow.BuyAmount,
+				"sell_amount": flow.SellAmount,
+			},
+			flow.Date,
+		))
+	}
+
+	r.influx.WritePoints(points)
+	r.influx.Flush()
+	return nil
+}
+
+// GetFlows 查询沪深港通每日成交资金，按日期、通道排序；direction 为空时返回南北两个方向
+func (r *stockConnectRepository) GetFlows(ctx context.Context, direction string, start, end time.Time) ([]*models.ConnectFlow, error) {
+	filter := ""
+	if direction != "" {
+		filter = fmt.Sprintf(`|> filter(fn: (r) => r.direction == "%s")`, direction)
+	}
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "connect_flow")
+		%s
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> group()
+		|> sort(columns: ["_time", "direction", "channel"])
+	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), filter)
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询沪深港通资金失败: %w", err)
+	}
+	defer result.Close()
+
+	var flows []*models.ConnectFlow
+	for result.Next() {
+		record := result.Record()
+		flow := &models.ConnectFlow{Date: record.Time()}
+		if v, ok := record.ValueByKey("direction").(string); ok {
+			flow.Direction = v
+		}
+		if v, ok := record.ValueByKey("channel").(string); ok {
+			flow.Channel = v
+		}
+		if v, ok := record.ValueByKey("net_inflow").(float64); ok {
+			flow.NetInflow = v
+		}
+		if v, ok := record.ValueByKey("buy_amount").(float64); ok {
+			flow.BuyAmount = v
+		}
+		if v, ok := record.ValueByKey("sell_amount").(float64); ok {
+			flow.SellAmount = v
+		}
+		flows = append(flows, flow)
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return flows, nil
+}
+
+// ============ 北向个股持股 ============
+
+// SaveHoldings 批量保存北向资金个股持股
+func (r *stockConnectRepository) SaveHoldings(ctx context.Context, holdings []*models.NorthboundHolding) error {
+	points := make([]*write.Point, 0, len(holdings))
+	for _, h := range holdings {
+		points = append(points, write.NewPoint(
+			"northbound_holdings",
+			map[string]string{
+				"symbol":   h.Symbol,
+				"exchange": h.Exchange,
+			},
+			map[string]interface{}{
+				"shares":       h.Shares,
+				"market_value": h.MarketValue,
+				"ratio":        h.Ratio,
+			},
+			h.Date,
+		))
+	}
+
+	r.influx.WritePoints(points)
+	r.influx.Flush()
+	return nil
+}
+
+// GetHoldings 查询个股北向持股，按日期升序
+func (r *stockConnectRepository) GetHoldings(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.NorthboundHolding, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "northbound_holdings")
+		|> filter(fn: (r) => r.symbol == "%s")
+		|> filter(fn: (r) => r.exchange == "%s")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"])
+	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange)
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询北向持股失败: %w", err)
+	}
+	defer result.Close()
+
+	var holdings []*models.NorthboundHolding
+	for result.Next() {
+		h := parseHoldingRecord(result.Record())
+		h.Symbol = symbol
+		h.Exchange = exchange
+		holdings = append(holdings, h)
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return holdings, nil
+}
+
+// GetMarketHoldings 获取全市场时间范围内的北向持股，按 symbol.exchange 分组、日期升序
+// 用于选股等截面计算，避免逐只股票查询。
+func (r *stockConnectRepository) GetMarketHoldings(ctx context.Context, start, end time.Time) (map[string][]*models.NorthboundHolding, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "northbound_holdings")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"])
+	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询北向持股失败: %w", err)
+	}
+	defer result.Close()
+
+	holdings := make(map[string][]*models.NorthboundHolding)
+	for result.Next() {
+		record := result.Record()
+		h := parseHoldingRecord(record)
+		if v, ok := record.ValueByKey("symbol").(string); ok {
+			h.Symbol = v
+		}
+		if v, ok := record.ValueByKey("exchange").(string); ok {
+			h.Exchange = v
+		}
+		key := h.Symbol + "." + h.Exchange
+		holdings[key] = append(holdings[key], h)
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return holdings, nil
+}
+
+// parseHoldingRecord 解析北向持股记录的字段
+func parseHoldingRecord(record *query.FluxRecord) *models.NorthboundHolding {
+	h := &models.NorthboundHolding{Date: record.Time()}
+
+	if v, ok := record.ValueByKey("shares").(int64); ok {
+		h.Shares = v
+	}
+	if v, ok := record.ValueByKey("market_value").(float64); ok {
+		h.MarketValue = v
+	}
+	if v, ok := record.ValueByKey("ratio").(float64); ok {
+		h.Ratio = v
+	}
+
+	return h
+}
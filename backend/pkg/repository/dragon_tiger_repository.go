@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// DragonTigerRepository 龙虎榜数据仓库接口
+type DragonTigerRepository interface {
+	SaveBatch(ctx context.Context, records []*models.DragonTiger) error
+	GetByDate(ctx context.Context, date time.Time, page, pageSize int) ([]*models.DragonTiger, int64, error)
+	GetBySymbol(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.DragonTiger, error)
+}
+
+// dragonTigerRepository 龙虎榜数据仓库实现
+type dragonTigerRepository struct {
+	db *gorm.DB
+}
+
+// NewDragonTigerRepository 创建龙虎榜数据仓库
+func NewDragonTigerRepository(db *gorm.DB) DragonTigerRepository {
+	return &dragonTigerRepository{db: db}
+}
+
+// SaveBatch 批量保存龙虎榜记录（同一股票同日同原因的记录重复同步时覆盖）
+func (r *dragonTigerRepository) SaveBatch(ctx context.Context, records []*models.DragonTiger) error {
+	if len(records) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "symbol"}, {Name: "exchange"}, {Name: "trade_date"}, {Name: "reason"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"name", "close", "change_pct", "buy_amount", "sell_amount", "net_amount", "total_amount", "seats",
+			}),
+		}).
+		CreateInBatches(records, 100).Error
+}
+
+// GetByDate 获取指定交易日的龙虎榜（按净买额降序）
+func (r *dragonTigerRepository) GetByDate(ctx context.Context, date time.Time, page, pageSize int) ([]*models.DragonTiger, int64, error) {
+	var records []*models.DragonTiger
+	var total int64
+
+	day := date.Format("2006-01-02")
+
+	if err := r.db.WithContext(ctx).Model(&models.DragonTiger{}).Where("trade_date = ?", day).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := r.db.WithContext(ctx).
+		Where("trade_date = ?", day).
+		Order("net_amount DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// GetBySymbol 获取个股在时间范围内的上榜记录
+func (r *dragonTigerRepository) GetBySymbol(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.DragonTiger, error) {
+	var records []*models.DragonTiger
+	if err := r.db.WithContext(ctx).
+		Where("symbol = ? AND exchange = ?", symbol, exchange).
+		Where("trade_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("trade_date DESC").
+		Find(&records).Error; err != nil {
+		return nil, err
+	}
+	return records, nil
+}
@@ -0,0 +1,206 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// FactorRepository 财报与因子得分仓库接口
+type FactorRepository interface {
+	SaveFinancialReports(ctx context.Context, reports []*models.FinancialReport) error
+	GetDisclosedReports(ctx context.Context, asOf time.Time) (map[string]*models.FinancialReport, error)
+	GetLatestReportDate(ctx context.Context, onOrBefore time.Time) (*time.Time, error)
+	CountReports(ctx context.Context, reportDate time.Time) (int64, error)
+	SaveScores(ctx context.Context, tradeDate time.Time, scores []*models.FactorScore) error
+	GetLatestTradeDate(ctx context.Context, onOrBefore time.Time) (*time.Time, error)
+	GetTradeDates(ctx context.Context, start, end time.Time) ([]time.Time, error)
+	GetRanking(ctx context.Context, tradeDate time.Time, factor string, page, pageSize int) ([]*models.FactorScore, int64, error)
+	GetScores(ctx context.Context, tradeDate time.Time, factors []string) ([]*models.FactorScore, error)
+	GetScoresForSymbols(ctx context.Context, tradeDate time.Time, keys []string) ([]*models.FactorScore, error)
+	GetSymbolHistory(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.FactorScore, error)
+}
+
+// factorRepository 财报与因子得分仓库实现
+type factorRepository struct {
+	db *gorm.DB
+}
+
+// NewFactorRepository 创建财报与因子得分仓库
+func NewFactorRepository(db *gorm.DB) FactorRepository {
+	return &factorRepository{db: db}
+}
+
+// SaveFinancialReports 批量保存财报（同一股票同一报告期重复同步时覆盖）
+func (r *factorRepository) SaveFinancialReports(ctx context.Context, reports []*models.FinancialReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "symbol"}, {Name: "exchange"}, {Name: "report_type"}, {Name: "report_date"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"total_revenue", "net_profit", "gross_profit", "total_assets", "total_liabilities",
+				"shareholders_equity", "roe", "roa", "gross_margin", "debt_ratio",
+			}),
+		}).
+		CreateInBatches(reports, 100).Error
+}
+
+// GetDisclosedReports 获取每只股票在指定日期已披露（超过法定披露截止日）的最近一期财报
+// 返回 symbol.exchange -> 财报，避免使用尚未公布的财务数据。
+func (r *factorRepository) GetDisclosedReports(ctx context.Context, asOf time.Time) (map[string]*models.FinancialReport, error) {
+	var reports []*models.FinancialReport
+	if err := r.db.WithContext(ctx).
+		Where("report_date BETWEEN ? AND ?", asOf.AddDate(-2, 0, 0).Format("2006-01-02"), asOf.Format("2006-01-02")).
+		Order("report_date DESC").
+		Find(&reports).Error; err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]*models.FinancialReport)
+	for _, report := range reports {
+		key := report.Symbol + "." + report.Exchange
+		if _, ok := latest[key]; ok || report.DisclosureDeadline().After(asOf) {
+			continue
+		}
+		latest[key] = report
+	}
+	return latest, nil
+}
+
+// GetLatestReportDate 获取不晚于指定日期的最近报告期（全市场），没有财报时返回 nil
+func (r *factorRepository) GetLatestReportDate(ctx context.Context, onOrBefore time.Time) (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.db.WithContext(ctx).
+		Model(&models.FinancialReport{}).
+		Where("report_date <= ?", onOrBefore.Format("2006-01-02")).
+		Select("MAX(report_date)").
+		Row().Scan(&date); err != nil {
+		return nil, err
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// CountReports 统计某报告期已同步财报的条数
+func (r *factorRepository) CountReports(ctx context.Context, reportDate time.Time) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.FinancialReport{}).
+		Where("report_date = ?", reportDate.Format("2006-01-02")).
+		Count(&count).Error
+	return count, err
+}
+
+// SaveScores 保存某交易日的全部因子得分（覆盖该日已有得分）
+func (r *factorRepository) SaveScores(ctx context.Context, tradeDate time.Time, scores []*models.FactorScore) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("trade_date = ?", tradeDate.Format("2006-01-02")).
+			Delete(&models.FactorScore{}).Error; err != nil {
+			return err
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(scores, 500).Error
+	})
+}
+
+// GetLatestTradeDate 获取不晚于指定日期的最近一个有因子得分的交易日，不存在时返回 nil
+func (r *factorRepository) GetLatestTradeDate(ctx context.Context, onOrBefore time.Time) (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.db.WithContext(ctx).
+		Model(&models.FactorScore{}).
+		Where("trade_date <= ?", onOrBefore.Format("2006-01-02")).
+		Select("MAX(trade_date)").
+		Row().Scan(&date); err != nil {
+		return nil, err
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// GetTradeDates 获取 [start, end] 内有因子得分的交易日，按时间升序
+func (r *factorRepository) GetTradeDates(ctx context.Context, start, end time.Time) ([]time.Time, error) {
+	var dates []time.Time
+	err := r.db.WithContext(ctx).
+		Model(&models.FactorScore{}).
+		Where("trade_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Distinct("trade_date").
+		Order("trade_date").
+		Pluck("trade_date", &dates).Error
+	return dates, err
+}
+
+// GetRanking 获取某交易日单个因子的排名
+func (r *factorRepository) GetRanking(ctx context.Context, tradeDate time.Time, factor string, page, pageSize int) ([]*models.FactorScore, int64, error) {
+	var scores []*models.FactorScore
+	var total int64
+
+	query := r.db.WithContext(ctx).
+		Model(&models.FactorScore{}).
+		Where("trade_date = ? AND factor = ?", tradeDate.Format("2006-01-02"), factor)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Order("rank ASC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&scores).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return scores, total, nil
+}
+
+// GetScores 获取某交易日指定因子的全部得分
+func (r *factorRepository) GetScores(ctx context.Context, tradeDate time.Time, factors []string) ([]*models.FactorScore, error) {
+	var scores []*models.FactorScore
+	if err := r.db.WithContext(ctx).
+		Where("trade_date = ? AND factor IN ?", tradeDate.Format("2006-01-02"), factors).
+		Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// GetScoresForSymbols 获取某交易日指定股票（symbol.exchange）的全部因子得分
+func (r *factorRepository) GetScoresForSymbols(ctx context.Context, tradeDate time.Time, keys []string) ([]*models.FactorScore, error) {
+	var scores []*models.FactorScore
+	if len(keys) == 0 {
+		return scores, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("trade_date = ?", tradeDate.Format("2006-01-02")).
+		Where("symbol || '.' || exchange IN ?", keys).
+		Order("factor, rank").
+		Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// GetSymbolHistory 获取个股在时间范围内的因子得分
+func (r *factorRepository) GetSymbolHistory(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.FactorScore, error) {
+	var scores []*models.FactorScore
+	if err := r.db.WithContext(ctx).
+		Where("symbol = ? AND exchange = ?", symbol, exchange).
+		Where("trade_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("trade_date ASC, factor ASC").
+		Find(&scores).Error; err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
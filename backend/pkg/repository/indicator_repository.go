@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// CustomIndicatorRepository 自定义指标仓库接口
+type CustomIndicatorRepository interface {
+	Create(ctx context.Context, indicator *models.CustomIndicator) error
+	Update(ctx context.Context, indicator *models.CustomIndicator) error
+	Delete(ctx context.Context, id uint) error
+	GetByID(ctx context.Context, id uint) (*models.CustomIndicator, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.CustomIndicator, error)
+	GetByNames(ctx context.Context, userID uint, names []string) ([]*models.CustomIndicator, error)
+}
+
+// customIndicatorRepository 自定义指标仓库实现
+type customIndicatorRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomIndicatorRepository 创建自定义指标仓库
+func NewCustomIndicatorRepository(db *gorm.DB) CustomIndicatorRepository {
+	return &customIndicatorRepository{db: db}
+}
+
+// Create 创建自定义指标
+func (r *customIndicatorRepository) Create(ctx context.Context, indicator *models.CustomIndicator) error {
+	return r.db.WithContext(ctx).Create(indicator).Error
+}
+
+// Update 更新自定义指标
+func (r *customIndicatorRepository) Update(ctx context.Context, indicator *models.CustomIndicator) error {
+	return r.db.WithContext(ctx).Save(indicator).Error
+}
+
+// Delete 删除自定义指标
+func (r *customIndicatorRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.CustomIndicator{}, id).Error
+}
+
+// GetByID 根据ID获取自定义指标
+func (r *customIndicatorRepository) GetByID(ctx context.Context, id uint) (*models.CustomIndicator, error) {
+	var indicator models.CustomIndicator
+	if err := r.db.WithContext(ctx).First(&indicator, id).Error; err != nil {
+		return nil, err
+	}
+	return &indicator, nil
+}
+
+// GetByUserID 获取用户的全部自定义指标
+func (r *customIndicatorRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.CustomIndicator, error) {
+	var indicators []*models.CustomIndicator
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("name").Find(&indicators).Error; err != nil {
+		return nil, err
+	}
+	return indicators, nil
+}
+
+// GetByNames 按名称获取用户的自定义指标
+func (r *customIndicatorRepository) GetByNames(ctx context.Context, userID uint, names []string) ([]*models.CustomIndicator, error) {
+	var indicators []*models.CustomIndicator
+	if len(names) == 0 {
+		return indicators, nil
+	}
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND name IN ?", userID, names).Find(&indicators).Error; err != nil {
+		return nil, err
+	}
+	return indicators, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// MacroRepository 宏观序列仓库接口
+type MacroRepository interface {
+	SavePoints(ctx context.Context, points []*models.MacroPoint) error
+	GetSeries(ctx context.Context, name string, start, end time.Time) ([]*models.MacroPoint, error)
+	GetLatest(ctx context.Context) (map[string]*models.MacroPoint, error)
+}
+
+// macroRepository 宏观序列仓库实现
+type macroRepository struct {
+	influx *database.InfluxClient
+}
+
+// NewMacroRepository 创建宏观序列仓库
+func NewMacroRepository(influx *database.InfluxClient) MacroRepository {
+	return &macroRepository{influx: influx}
+}
+
+// SavePoints 批量保存宏观数据点，同一序列同一日期重复写入时覆盖（数据修订）
+func (r *macroRepository) SavePoints(ctx context.Context, points []*models.MacroPoint) error {
+	writes := make([]*write.Point, 0, len(points))
+	for _, p := range points {
+		writes = append(writes, write.NewPoint(
+			"macro",
+			map[string]string{"series": p.Series},
+			map[string]interface{}{"value": p.Value},
+			p.Date,
+		))
+	}
+
+	r.influx.WritePoints(writes)
+	r.influx.Flush()
+	return nil
+}
+
+// GetSeries 查询宏观序列在日期范围内的数据点，按日期升序
+func (r *macroRepository) GetSeries(ctx context.Context, name string, start, end time.Time) ([]*models.MacroPoint, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "macro")
+		|> filter(fn: (r) => r.series == "%s")
+		|> filter(fn: (r) => r._field == "value")
+		|> sort(columns: ["_time"])
+	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), name)
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询宏观序列失败: %w", err)
+	}
+	defer result.Close()
+
+	var points []*models.MacroPoint
+	for result.Next() {
+		record := result.Record()
+		point := &models.MacroPoint{Series: name, Date: record.Time()}
+		if v, ok := record.Value().(float64); ok {
+			point.Value = v
+		}
+		points = append(points, point)
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return points, nil
+}
+
+// GetLatest 获取各宏观序列的最新数据点，按序列名称索引
+func (r *macroRepository) GetLatest(ctx context.Context) (map[string]*models.MacroPoint, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: 0)
+		|> filter(fn: (r) => r._measurement == "macro")
+		|> filter(fn: (r) => r._field == "value")
+		|> last()
+	`, r.influx.GetBucket())
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询宏观序列最新值失败: %w", err)
+	}
+	defer result.Close()
+
+	latest := make(map[string]*models.MacroPoint)
+	for result.Next() {
+		record := result.Record()
+		name, _ := record.ValueByKey("series").(string)
+		point := &models.MacroPoint{Series: name, Date: record.Time()}
+		if v, ok := record.Value().(float64); ok {
+			point.Value = v
+		}
+		latest[name] = point
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return latest, nil
+}
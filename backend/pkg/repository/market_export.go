@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 全量导出 ============
+
+// ScanDailyBars 逐条读取全市场时间范围内的完整日K线，按股票分组、组内按日期升序
+// 结果不在内存中汇总，适用于导出多年数据；fn 返回错误时停止读取。
+func (r *marketRepository) ScanDailyBars(ctx context.Context, start, end time.Time, fn func(bar *models.DailyBar) error) error {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "daily_bars")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+	`, r.influx.Bucket(database.DataDailyBars), start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("查询日K线失败: %w", err)
+	}
+	defer result.Close()
+
+	for result.Next() {
+		record := result.Record()
+		bar := &models.DailyBar{Date: record.Time()}
+		if v, ok := record.ValueByKey("symbol").(string); ok {
+			bar.Symbol = v
+		}
+		if v, ok := record.ValueByKey("exchange").(string); ok {
+			bar.Exchange = v
+		}
+		if v, ok := record.ValueByKey("open").(float64); ok {
+			bar.Open = v
+		}
+		if v, ok := record.ValueByKey("high").(float64); ok {
+			bar.High = v
+		}
+		if v, ok := record.ValueByKey("low").(float64); ok {
+			bar.Low = v
+		}
+		if v, ok := record.ValueByKey("close").(float64); ok {
+			bar.Close = v
+		}
+		if v, ok := record.ValueByKey("volume").(int64); ok {
+			bar.Volume = v
+		}
+		if v, ok := record.ValueByKey("amount").(float64); ok {
+			bar.Amount = v
+		}
+		if err := fn(bar); err != nil {
+			return err
+		}
+	}
+	return result.Err()
+}
+
+// ScanIndicators 逐条读取全市场时间范围内的技术指标，每条记录对应一只股票一天的一类指标
+func (r *marketRepository) ScanIndicators(ctx context.Context, start, end time.Time, fn func(indicator *models.Indicator) error) error {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "indicators")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+	`, r.influx.Bucket(database.DataIndicators), start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return fmt.Errorf("查询技术指标失败: %w", err)
+	}
+	defer result.Close()
+
+	for result.Next() {
+		record := result.Record()
+		indicator := &models.Indicator{Date: record.Time()}
+		if v, ok := record.ValueByKey("symbol").(string); ok {
+			indicator.Symbol = v
+		}
+		if v, ok := record.ValueByKey("exchange").(string); ok {
+			indicator.Exchange = v
+		}
+		if v, ok := record.ValueByKey("indicator_type").(string); ok {
+			indicator.IndicatorType = v
+		}
+		parseIndicatorFields(record, indicator)
+		if err := fn(indicator); err != nil {
+			return err
+		}
+	}
+	return result.Err()
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/influxdb-client-go/v2/api/write"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 历史数据批量导入 ============
+
+const (
+	defaultImportBatchSize = 5000
+	maxImportBatchSize     = 50000
+	defaultImportRetries   = 3
+	defaultImportBackoff   = 500 * time.Millisecond
+)
+
+// BulkImportOptions 批量导入参数，零值使用默认值
+type BulkImportOptions struct {
+	BatchSize    int           // 每批数据点数，默认取 InfluxDB 配置的 batch_size
+	MaxRetries   int           // 单批失败后的重试次数，默认 3，负数表示不重试
+	RetryBackoff time.Duration // 首次重试前的等待时间，之后每次翻倍
+	StopOnError  bool          // 某批重试后仍失败时跳过剩余批次
+}
+
+// BulkChunkResult 单批写入结果
+type BulkChunkResult struct {
+	Index    int       `json:"index"`
+	Points   int       `json:"points"`
+	Start    time.Time `json:"start"` // 本批数据的最早时间
+	End      time.Time `json:"end"`   // 本批数据的最晚时间
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// BulkImportResult 批量导入结果
+type BulkImportResult struct {
+	Total   int               `json:"total"`
+	Written int               `json:"written"`
+	Failed  int               `json:"failed"`  // 重试后仍写入失败的数据点数
+	Skipped int               `json:"skipped"` // StopOnError 或请求取消后未写入的数据点数
+	Chunks  []BulkChunkResult `json:"chunks"`
+}
+
+// ImportDailyBars 分批同步写入日K线，适用于首次导入多年历史数据
+func (r *marketRepository) ImportDailyBars(ctx context.Context, bars []*models.DailyBar, opts BulkImportOptions) (*BulkImportResult, error) {
+	points := make([]*write.Point, len(bars))
+	times := make([]time.Time, len(bars))
+	for i, bar := range bars {
+		points[i] = dailyBarPoint(bar)
+		times[i] = bar.Date
+	}
+	return r.bulkWrite(ctx, database.DataDailyBars, points, times, opts)
+}
+
+// ImportMinuteBars 分批同步写入分钟K线
+func (r *marketRepository) ImportMinuteBars(ctx context.Context, bars []*models.MinuteBar, opts BulkImportOptions) (*BulkImportResult, error) {
+	points := make([]*write.Point, len(bars))
+	times := make([]time.Time, len(bars))
+	for i, bar := range bars {
+		points[i] = minuteBarPoint(bar)
+		times[i] = bar.Time
+	}
+	return r.bulkWrite(ctx, database.DataMinuteBars, points, times, opts)
+}
+
+// bulkWrite 按批同步写入并逐批重试
+// 每批等服务端确认后才提交下一批，写入速度受 InfluxDB 处理能力约束，不会在内存中堆积未发送的数据。
+// 单批失败只记录在结果中；请求取消时停止写入并返回已完成的结果与 ctx 错误。
+func (r *marketRepository) bulkWrite(ctx context.Context, dataType string, points []*write.Point, times []time.Time, opts BulkImportOptions) (*BulkImportResult, error) {
+	opts = r.importDefaults(opts)
+	result := &BulkImportResult{Total: len(points), Chunks: []BulkChunkResult{}}
+
+	for from, index := 0, 0; from < len(points); from, index = from+opts.BatchSize, index+1 {
+		to := min(from+opts.BatchSize, len(points))
+		if err := ctx.Err(); err != nil {
+			result.Skipped += len(points) - from
+			return result, err
+		}
+
+		chunk := BulkChunkResult{Index: index, Points: to - from}
+		chunk.Start, chunk.End = timeBounds(times[from:to])
+		err := r.writeWithRetry(ctx, dataType, points[from:to], opts, &chunk.Attempts)
+		if err != nil {
+			chunk.Error = err.Error()
+			result.Failed += chunk.Points
+		} else {
+			result.Written += chunk.Points
+		}
+		result.Chunks = append(result.Chunks, chunk)
+
+		if err != nil && (opts.StopOnError || ctx.Err() != nil) {
+			result.Skipped += len(points) - to
+			return result, ctx.Err()
+		}
+	}
+	return result, nil
+}
+
+// writeWithRetry 写入一批数据，失败时按指数退避重试
+func (r *marketRepository) writeWithRetry(ctx context.Context, dataType string, points []*write.Point, opts BulkImportOptions, attempts *int) error {
+	backoff := opts.RetryBackoff
+	for {
+		*attempts++
+		err := r.influx.WriteBlocking(ctx, dataType, points...)
+		if err == nil || *attempts > opts.MaxRetries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// importDefaults 填充导入参数默认值
+func (r *marketRepository) importDefaults(opts BulkImportOptions) BulkImportOptions {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = r.influx.GetBatchSize()
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+	opts.BatchSize = min(opts.BatchSize, maxImportBatchSize)
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultImportRetries
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = defaultImportBackoff
+	}
+	return opts
+}
+
+// timeBounds 返回最早与最晚时间，数据不要求有序
+func timeBounds(times []time.Time) (first, last time.Time) {
+	for i, t := range times {
+		if i == 0 || t.Before(first) {
+			first = t
+		}
+		if i == 0 || t.After(last) {
+			last = t
+		}
+	}
+	return first, last
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/database"
+)
+
+// countFields 统计数据点数时使用的字段：K线每根一个 close，指标每类每天取一个代表字段
+var countFields = map[string][]string{
+	database.DataDailyBars:  {"close"},
+	database.DataMinuteBars: {"close"},
+	database.DataIndicators: {"ma5", "macd", "rsi6", "k", "boll_mid"},
+}
+
+// CountSeries 统计一只股票在 [start, end] 内某类数据的条数（K线根数或每日每类指标条数），用于删除前预览
+func (r *marketRepository) CountSeries(ctx context.Context, dataType, symbol, exchange string, start, end time.Time) (int64, error) {
+	fields, ok := countFields[dataType]
+	if !ok {
+		return 0, fmt.Errorf("不支持的数据类型: %s", dataType)
+	}
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "%s" and r.symbol == "%s" and r.exchange == "%s")
+		|> filter(fn: (r) => %s)
+		|> count()
+		|> group()
+		|> sum()
+	`, r.influx.Bucket(dataType), start.Format(time.RFC3339), end.Add(time.Second).Format(time.RFC3339),
+		dataType, symbol, exchange, countFieldFilter(fields))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("统计数据条数失败: %w", err)
+	}
+	defer result.Close()
+
+	var total int64
+	for result.Next() {
+		if v, ok := result.Record().Value().(int64); ok {
+			total += v
+		}
+	}
+	return total, result.Err()
+}
+
+// DeleteSeries 删除一只股票在 [start, end] 内某类数据的全部数据点
+// measurement 与数据类型同名；symbol、exchange 由调用方校验，谓词中不能出现引号。
+func (r *marketRepository) DeleteSeries(ctx context.Context, dataType, symbol, exchange string, start, end time.Time) error {
+	if _, ok := countFields[dataType]; !ok {
+		return fmt.Errorf("不支持的数据类型: %s", dataType)
+	}
+	predicate := fmt.Sprintf(`_measurement="%s" AND symbol="%s" AND exchange="%s"`, dataType, symbol, exchange)
+	if err := r.influx.DeleteFrom(ctx, dataType, start, end, predicate); err != nil {
+		return fmt.Errorf("删除数据失败: %w", err)
+	}
+	return nil
+}
+
+// DuplicateDailyBarDays 查找 [start, end] 内同一交易日存在多个日K线数据点的日期，返回各交易日的北京时间零点
+// 重复同步时时间戳不一致（UTC 零点与北京时间零点）或标签不同都会在同一天写入多个点；
+// 按北京时间（UTC+8）截断到天后分组计数，只返回数据点多于一个的交易日。
+func (r *marketRepository) DuplicateDailyBarDays(ctx context.Context, symbol, exchange string, start, end time.Time) ([]time.Time, error) {
+	query := fmt.Sprintf(`
+		import "date"
+
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "daily_bars" and r.symbol == "%s" and r.exchange == "%s" and r._field == "close")
+		|> timeShift(duration: 8h)
+		|> map(fn: (r) => ({r with day: date.truncate(t: r._time, unit: 1d)}))
+		|> group(columns: ["day"])
+		|> count()
+		|> filter(fn: (r) => r._value > 1)
+		|> group()
+		|> sort(columns: ["day"])
+	`, r.influx.Bucket(database.DataDailyBars), start.Format(time.RFC3339), end.Add(time.Second).Format(time.RFC3339),
+		symbol, exchange)
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询重复日K线失败: %w", err)
+	}
+	defer result.Close()
+
+	var days []time.Time
+	for result.Next() {
+		if day, ok := result.Record().ValueByKey("day").(time.Time); ok {
+			days = append(days, day.Add(-8*time.Hour))
+		}
+	}
+	return days, result.Err()
+}
+
+// ============ 全市场数据新鲜度 ============
+
+// LatestTime 全市场某类数据在 since 之后最新的数据点时间，没有数据时返回 nil
+func (r *marketRepository) LatestTime(ctx context.Context, dataType string, since time.Time) (*time.Time, error) {
+	fields, ok := countFields[dataType]
+	if !ok {
+		return nil, fmt.Errorf("不支持的数据类型: %s", dataType)
+	}
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s)
+		|> filter(fn: (r) => r._measurement == "%s")
+		|> filter(fn: (r) => %s)
+		|> last()
+		|> group()
+		|> max(column: "_time")
+	`, r.influx.Bucket(dataType), since.Format(time.RFC3339), dataType, countFieldFilter(fields))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询最新数据时间失败: %w", err)
+	}
+	defer result.Close()
+
+	var latest *time.Time
+	for result.Next() {
+		t := result.Record().Time()
+		latest = &t
+	}
+	return latest, result.Err()
+}
+
+// CountMarket 统计全市场 [start, end) 内某类数据的股票数与数据点数（K线根数或每日每类指标条数）
+func (r *marketRepository) CountMarket(ctx context.Context, dataType string, start, end time.Time) (int, int64, error) {
+	fields, ok := countFields[dataType]
+	if !ok {
+		return 0, 0, fmt.Errorf("不支持的数据类型: %s", dataType)
+	}
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "%s")
+		|> filter(fn: (r) => %s)
+		|> count()
+		|> group(columns: ["symbol", "exchange"])
+		|> sum()
+	`, r.influx.Bucket(dataType), start.Format(time.RFC3339), end.Format(time.RFC3339), dataType, countFieldFilter(fields))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return 0, 0, fmt.Errorf("统计全市场数据条数失败: %w", err)
+	}
+	defer result.Close()
+
+	symbols, points := 0, int64(0)
+	for result.Next() {
+		if v, ok := result.Record().Value().(int64); ok && v > 0 {
+			symbols++
+			points += v
+		}
+	}
+	return symbols, points, result.Err()
+}
+
+// countFieldFilter 统计字段的 Flux 过滤条件，如 r._field == "ma5" or r._field == "macd"
+func countFieldFilter(fields []string) string {
+	filter := ""
+	for i, f := range fields {
+		if i > 0 {
+			filter += " or "
+		}
+		filter += fmt.Sprintf(`r._field == "%s"`, f)
+	}
+	return filter
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/influxdata/influxdb-client-go/v2/api/query"
 	"github.com/influxdata/influxdb-client-go/v2/api/write"
 
 	"stock-analysis-system/backend/pkg/database"
@@ -17,12 +18,17 @@ type MarketRepository interface {
 	SaveDailyBar(ctx context.Context, bar *models.DailyBar) error
 	SaveDailyBars(ctx context.Context, bars []*models.DailyBar) error
 	GetDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.DailyBar, error)
+	StreamDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time, fn func(bar *models.DailyBar) error) error
 	GetLatestDailyBar(ctx context.Context, symbol, exchange string) (*models.DailyBar, error)
+	GetMarketDailyBars(ctx context.Context, start, end time.Time) (map[string][]*models.DailyBar, error)
+	ImportDailyBars(ctx context.Context, bars []*models.DailyBar, opts BulkImportOptions) (*BulkImportResult, error)
 	
 	// 分钟K线数据操作
 	SaveMinuteBar(ctx context.Context, bar *models.MinuteBar) error
 	SaveMinuteBars(ctx context.Context, bars []*models.MinuteBar) error
 	GetMinuteBars(ctx context.Context, symbol, exchange, interval string, start, end time.Time) ([]*models.MinuteBar, error)
+	StreamMinuteBars(ctx context.Context, symbol, exchange, interval string, start, end time.Time, fn func(bar *models.MinuteBar) error) error
+	ImportMinuteBars(ctx context.Context, bars []*models.MinuteBar, opts BulkImportOptions) (*BulkImportResult, error)
 	
 	// 技术指标操作
 	SaveIndicator(ctx context.Context, indicator *models.Indicator) error
@@ -30,8 +36,26 @@ type MarketRepository interface {
 	GetIndicators(ctx context.Context, symbol, exchange string, indicatorType string, start, end time.Time) ([]*models.Indicator, error)
 	GetLatestIndicator(ctx context.Context, symbol, exchange string, indicatorType string) (*models.Indicator, error)
 	
+	// 资金流向操作
+	SaveMoneyFlows(ctx context.Context, flows []*models.MoneyFlow) error
+	GetMoneyFlows(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.MoneyFlow, error)
+	GetMoneyFlowRanking(ctx context.Context, date time.Time, limit int, desc bool) ([]*models.MoneyFlow, error)
+	
+	// 全量导出
+	ScanDailyBars(ctx context.Context, start, end time.Time, fn func(bar *models.DailyBar) error) error
+	ScanIndicators(ctx context.Context, start, end time.Time, fn func(indicator *models.Indicator) error) error
+	
 	// 数据完整性检查
 	CheckDataIntegrity(ctx context.Context, symbol, exchange string, start, end time.Time) (map[string]interface{}, error)
+
+	// 数据维护（管理员删除/修复错误数据）
+	CountSeries(ctx context.Context, dataType, symbol, exchange string, start, end time.Time) (int64, error)
+	DeleteSeries(ctx context.Context, dataType, symbol, exchange string, start, end time.Time) error
+	DuplicateDailyBarDays(ctx context.Context, symbol, exchange string, start, end time.Time) ([]time.Time, error)
+
+	// 全市场数据新鲜度
+	LatestTime(ctx context.Context, dataType string, since time.Time) (*time.Time, error)
+	CountMarket(ctx context.Context, dataType string, start, end time.Time) (int, int64, error)
 }
 
 // marketRepository 行情数据仓库实现
@@ -48,7 +72,27 @@ func NewMarketRepository(influx *database.InfluxClient) MarketRepository {
 
 // SaveDailyBar 保存单条日K线
 func (r *marketRepository) SaveDailyBar(ctx context.Context, bar *models.DailyBar) error {
-	point := write.NewPoint(
+	r.influx.WritePointsTo(database.DataDailyBars, dailyBarPoint(bar))
+	r.influx.Flush()
+	return nil
+}
+
+// SaveDailyBars 批量保存日K线
+func (r *marketRepository) SaveDailyBars(ctx context.Context, bars []*models.DailyBar) error {
+	points := make([]*write.Point, 0, len(bars))
+	
+	for _, bar := range bars {
+		points = append(points, dailyBarPoint(bar))
+	}
+	
+	r.influx.WritePointsTo(database.DataDailyBars, points...)
+	r.influx.Flush()
+	return nil
+}
+
+// dailyBarPoint 日K线数据点
+func dailyBarPoint(bar *models.DailyBar) *write.Point {
+	return write.NewPoint(
 		"daily_bars",
 		map[string]string{
 			"symbol":   bar.Symbol,
@@ -64,43 +108,23 @@ func (r *marketRepository) SaveDailyBar(ctx context.Context, bar *models.DailyBa
 		},
 		bar.Date,
 	)
-	
-	r.influx.WritePoint(point)
-	r.influx.Flush()
-	return nil
 }
 
-// SaveDailyBars 批量保存日K线
-func (r *marketRepository) SaveDailyBars(ctx context.Context, bars []*models.DailyBar) error {
-	points := make([]*write.Point, 0, len(bars))
-	
-	for _, bar := range bars {
-		point := write.NewPoint(
-			"daily_bars",
-			map[string]string{
-				"symbol":   bar.Symbol,
-				"exchange": bar.Exchange,
-			},
-			map[string]interface{}{
-				"open":   bar.Open,
-				"high":   bar.High,
-				"low":    bar.Low,
-				"close":  bar.Close,
-				"volume": bar.Volume,
-				"amount": bar.Amount,
-			},
-			bar.Date,
-		)
-		points = append(points, point)
+// GetDailyBars 查询日K线数据
+func (r *marketRepository) GetDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.DailyBar, error) {
+	var bars []*models.DailyBar
+	err := r.StreamDailyBars(ctx, symbol, exchange, start, end, func(bar *models.DailyBar) error {
+		bars = append(bars, bar)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
-	r.influx.WritePoints(points)
-	r.influx.Flush()
-	return nil
+	return bars, nil
 }
 
-// GetDailyBars 查询日K线数据
-func (r *marketRepository) GetDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.DailyBar, error) {
+// StreamDailyBars 按日期升序逐条读取日K线，结果不在内存中汇总；fn 返回错误时停止读取
+func (r *marketRepository) StreamDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time, fn func(bar *models.DailyBar) error) error {
 	query := fmt.Sprintf(`
 		from(bucket: "%s")
 		|> range(start: %s, stop: %s)
@@ -109,15 +133,14 @@ func (r *marketRepository) GetDailyBars(ctx context.Context, symbol, exchange st
 		|> filter(fn: (r) => r.exchange == "%s")
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
 		|> sort(columns: ["_time"])
-	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange)
+	`, r.influx.Bucket(database.DataDailyBars), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange)
 
 	result, err := r.influx.Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("查询日K线失败: %w", err)
+		return fmt.Errorf("查询日K线失败: %w", err)
 	}
 	defer result.Close()
 
-	var bars []*models.DailyBar
 	for result.Next() {
 		record := result.Record()
 		bar := &models.DailyBar{
@@ -145,7 +168,53 @@ func (r *marketRepository) GetDailyBars(ctx context.Context, symbol, exchange st
 			bar.Amount = v
 		}
 		
-		bars = append(bars, bar)
+		if err := fn(bar); err != nil {
+			return err
+		}
+	}
+
+	return result.Err()
+}
+
+// GetMarketDailyBars 获取全市场时间范围内的日K线（仅收盘价、成交量与成交额），按 symbol.exchange 分组
+// 用于选股等截面计算，避免逐只股票查询。
+func (r *marketRepository) GetMarketDailyBars(ctx context.Context, start, end time.Time) (map[string][]*models.DailyBar, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "daily_bars")
+		|> filter(fn: (r) => r._field == "close" or r._field == "volume" or r._field == "amount")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"])
+	`, r.influx.Bucket(database.DataDailyBars), start.Format(time.RFC3339), end.Format(time.RFC3339))
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询全市场日K线失败: %w", err)
+	}
+	defer result.Close()
+
+	bars := make(map[string][]*models.DailyBar)
+	for result.Next() {
+		record := result.Record()
+		bar := &models.DailyBar{Date: record.Time()}
+		if v, ok := record.ValueByKey("symbol").(string); ok {
+			bar.Symbol = v
+		}
+		if v, ok := record.ValueByKey("exchange").(string); ok {
+			bar.Exchange = v
+		}
+		if v, ok := record.ValueByKey("close").(float64); ok {
+			bar.Close = v
+		}
+		if v, ok := record.ValueByKey("volume").(int64); ok {
+			bar.Volume = v
+		}
+		if v, ok := record.ValueByKey("amount").(float64); ok {
+			bar.Amount = v
+		}
+		key := bar.Symbol + "." + bar.Exchange
+		bars[key] = append(bars[key], bar)
 	}
 
 	if result.Err() != nil {
@@ -166,7 +235,7 @@ func (r *marketRepository) GetLatestDailyBar(ctx context.Context, symbol, exchan
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
 		|> sort(columns: ["_time"], desc: true)
 		|> limit(n: 1)
-	`, r.influx.GetBucket(), symbol, exchange)
+	`, r.influx.Bucket(database.DataDailyBars), symbol, exchange)
 
 	result, err := r.influx.Query(ctx, query)
 	if err != nil {
@@ -211,7 +280,27 @@ func (r *marketRepository) GetLatestDailyBar(ctx context.Context, symbol, exchan
 
 // SaveMinuteBar 保存单条分钟K线
 func (r *marketRepository) SaveMinuteBar(ctx context.Context, bar *models.MinuteBar) error {
-	point := write.NewPoint(
+	r.influx.WritePointsTo(database.DataMinuteBars, minuteBarPoint(bar))
+	r.influx.Flush()
+	return nil
+}
+
+// SaveMinuteBars 批量保存分钟K线
+func (r *marketRepository) SaveMinuteBars(ctx context.Context, bars []*models.MinuteBar) error {
+	points := make([]*write.Point, 0, len(bars))
+	
+	for _, bar := range bars {
+		points = append(points, minuteBarPoint(bar))
+	}
+	
+	r.influx.WritePointsTo(database.DataMinuteBars, points...)
+	r.influx.Flush()
+	return nil
+}
+
+// minuteBarPoint 分钟K线数据点
+func minuteBarPoint(bar *models.MinuteBar) *write.Point {
+	return write.NewPoint(
 		"minute_bars",
 		map[string]string{
 			"symbol":   bar.Symbol,
@@ -228,44 +317,23 @@ func (r *marketRepository) SaveMinuteBar(ctx context.Context, bar *models.Minute
 		},
 		bar.Time,
 	)
-	
-	r.influx.WritePoint(point)
-	r.influx.Flush()
-	return nil
 }
 
-// SaveMinuteBars 批量保存分钟K线
-func (r *marketRepository) SaveMinuteBars(ctx context.Context, bars []*models.MinuteBar) error {
-	points := make([]*write.Point, 0, len(bars))
-	
-	for _, bar := range bars {
-		point := write.NewPoint(
-			"minute_bars",
-			map[string]string{
-				"symbol":   bar.Symbol,
-				"exchange": bar.Exchange,
-				"interval": bar.Interval,
-			},
-			map[string]interface{}{
-				"open":   bar.Open,
-				"high":   bar.High,
-				"low":    bar.Low,
-				"close":  bar.Close,
-				"volume": bar.Volume,
-				"amount": bar.Amount,
-			},
-			bar.Time,
-		)
-		points = append(points, point)
+// GetMinuteBars 查询分钟K线数据
+func (r *marketRepository) GetMinuteBars(ctx context.Context, symbol, exchange, interval string, start, end time.Time) ([]*models.MinuteBar, error) {
+	var bars []*models.MinuteBar
+	err := r.StreamMinuteBars(ctx, symbol, exchange, interval, start, end, func(bar *models.MinuteBar) error {
+		bars = append(bars, bar)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	
-	r.influx.WritePoints(points)
-	r.influx.Flush()
-	return nil
+	return bars, nil
 }
 
-// GetMinuteBars 查询分钟K线数据
-func (r *marketRepository) GetMinuteBars(ctx context.Context, symbol, exchange, interval string, start, end time.Time) ([]*models.MinuteBar, error) {
+// StreamMinuteBars 按时间升序逐条读取分钟K线，结果不在内存中汇总；fn 返回错误时停止读取
+func (r *marketRepository) StreamMinuteBars(ctx context.Context, symbol, exchange, interval string, start, end time.Time, fn func(bar *models.MinuteBar) error) error {
 	query := fmt.Sprintf(`
 		from(bucket: "%s")
 		|> range(start: %s, stop: %s)
@@ -275,15 +343,14 @@ func (r *marketRepository) GetMinuteBars(ctx context.Context, symbol, exchange,
 		|> filter(fn: (r) => r.interval == "%s")
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
 		|> sort(columns: ["_time"])
-	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange, interval)
+	`, r.influx.Bucket(database.DataMinuteBars), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange, interval)
 
 	result, err := r.influx.Query(ctx, query)
 	if err != nil {
-		return nil, fmt.Errorf("查询分钟K线失败: %w", err)
+		return fmt.Errorf("查询分钟K线失败: %w", err)
 	}
 	defer result.Close()
 
-	var bars []*models.MinuteBar
 	for result.Next() {
 		record := result.Record()
 		bar := &models.MinuteBar{
@@ -312,14 +379,12 @@ func (r *marketRepository) GetMinuteBars(ctx context.Context, symbol, exchange,
 			bar.Amount = v
 		}
 		
-		bars = append(bars, bar)
-	}
-
-	if result.Err() != nil {
-		return nil, result.Err()
+		if err := fn(bar); err != nil {
+			return err
+		}
 	}
 
-	return bars, nil
+	return result.Err()
 }
 
 // ============ 技术指标操作 ============
@@ -359,6 +424,23 @@ func (r *marketRepository) SaveIndicator(ctx context.Context, indicator *models.
 		fields["boll_upper"] = indicator.BollUpper
 		fields["boll_mid"] = indicator.BollMid
 		fields["boll_lower"] = indicator.BollLower
+	case "atr":
+		fields["atr"] = indicator.ATR
+	case "cci":
+		fields["cci"] = indicator.CCI
+	case "obv":
+		fields["obv"] = indicator.OBV
+	case "dmi":
+		fields["pdi"] = indicator.PDI
+		fields["mdi"] = indicator.MDI
+		fields["adx"] = indicator.ADX
+	case "wr":
+		fields["wr"] = indicator.WR
+	case "bias":
+		fields["bias"] = indicator.BIAS
+	}
+	if indicator.Period > 0 {
+		fields["period"] = indicator.Period
 	}
 	
 	point := write.NewPoint(
@@ -372,7 +454,7 @@ func (r *marketRepository) SaveIndicator(ctx context.Context, indicator *models.
 		indicator.Date,
 	)
 	
-	r.influx.WritePoint(point)
+	r.influx.WritePointsTo(database.DataIndicators, point)
 	r.influx.Flush()
 	return nil
 }
@@ -398,7 +480,7 @@ func (r *marketRepository) GetIndicators(ctx context.Context, symbol, exchange s
 		|> filter(fn: (r) => r.indicator_type == "%s")
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
 		|> sort(columns: ["_time"])
-	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange, indicatorType)
+	`, r.influx.Bucket(database.DataIndicators), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange, indicatorType)
 
 	result, err := r.influx.Query(ctx, query)
 	if err != nil {
@@ -416,62 +498,7 @@ func (r *marketRepository) GetIndicators(ctx context.Context, symbol, exchange s
 			IndicatorType: indicatorType,
 		}
 		
-		// 根据指标类型解析字段
-		switch indicatorType {
-		case "ma":
-			if v, ok := record.ValueByKey("ma5").(float64); ok {
-				indicator.MA5 = v
-			}
-			if v, ok := record.ValueByKey("ma10").(float64); ok {
-				indicator.MA10 = v
-			}
-			if v, ok := record.ValueByKey("ma20").(float64); ok {
-				indicator.MA20 = v
-			}
-			if v, ok := record.ValueByKey("ma60").(float64); ok {
-				indicator.MA60 = v
-			}
-		case "macd":
-			if v, ok := record.ValueByKey("macd").(float64); ok {
-				indicator.MACD = v
-			}
-			if v, ok := record.ValueByKey("macd_signal").(float64); ok {
-				indicator.MACDSignal = v
-			}
-			if v, ok := record.ValueByKey("macd_hist").(float64); ok {
-				indicator.MACDHist = v
-			}
-		case "rsi":
-			if v, ok := record.ValueByKey("rsi6").(float64); ok {
-				indicator.RSI6 = v
-			}
-			if v, ok := record.ValueByKey("rsi12").(float64); ok {
-				indicator.RSI12 = v
-			}
-			if v, ok := record.ValueByKey("rsi24").(float64); ok {
-				indicator.RSI24 = v
-			}
-		case "kdj":
-			if v, ok := record.ValueByKey("k").(float64); ok {
-				indicator.K = v
-			}
-			if v, ok := record.ValueByKey("d").(float64); ok {
-				indicator.D = v
-			}
-			if v, ok := record.ValueByKey("j").(float64); ok {
-				indicator.J = v
-			}
-		case "boll":
-			if v, ok := record.ValueByKey("boll_upper").(float64); ok {
-				indicator.BollUpper = v
-			}
-			if v, ok := record.ValueByKey("boll_mid").(float64); ok {
-				indicator.BollMid = v
-			}
-			if v, ok := record.ValueByKey("boll_lower").(float64); ok {
-				indicator.BollLower = v
-			}
-		}
+		parseIndicatorFields(record, indicator)
 		
 		indicators = append(indicators, indicator)
 	}
@@ -483,6 +510,98 @@ func (r *marketRepository) GetIndicators(ctx context.Context, symbol, exchange s
 	return indicators, nil
 }
 
+// parseIndicatorFields 按指标类型从 pivot 后的记录中解析字段
+func parseIndicatorFields(record *query.FluxRecord, indicator *models.Indicator) {
+	switch indicator.IndicatorType {
+	case "ma":
+		if v, ok := record.ValueByKey("ma5").(float64); ok {
+			indicator.MA5 = v
+		}
+		if v, ok := record.ValueByKey("ma10").(float64); ok {
+			indicator.MA10 = v
+		}
+		if v, ok := record.ValueByKey("ma20").(float64); ok {
+			indicator.MA20 = v
+		}
+		if v, ok := record.ValueByKey("ma60").(float64); ok {
+			indicator.MA60 = v
+		}
+	case "macd":
+		if v, ok := record.ValueByKey("macd").(float64); ok {
+			indicator.MACD = v
+		}
+		if v, ok := record.ValueByKey("macd_signal").(float64); ok {
+			indicator.MACDSignal = v
+		}
+		if v, ok := record.ValueByKey("macd_hist").(float64); ok {
+			indicator.MACDHist = v
+		}
+	case "rsi":
+		if v, ok := record.ValueByKey("rsi6").(float64); ok {
+			indicator.RSI6 = v
+		}
+		if v, ok := record.ValueByKey("rsi12").(float64); ok {
+			indicator.RSI12 = v
+		}
+		if v, ok := record.ValueByKey("rsi24").(float64); ok {
+			indicator.RSI24 = v
+		}
+	case "kdj":
+		if v, ok := record.ValueByKey("k").(float64); ok {
+			indicator.K = v
+		}
+		if v, ok := record.ValueByKey("d").(float64); ok {
+			indicator.D = v
+		}
+		if v, ok := record.ValueByKey("j").(float64); ok {
+			indicator.J = v
+		}
+	case "boll":
+		if v, ok := record.ValueByKey("boll_upper").(float64); ok {
+			indicator.BollUpper = v
+		}
+		if v, ok := record.ValueByKey("boll_mid").(float64); ok {
+			indicator.BollMid = v
+		}
+		if v, ok := record.ValueByKey("boll_lower").(float64); ok {
+			indicator.BollLower = v
+		}
+	case "atr":
+		if v, ok := record.ValueByKey("atr").(float64); ok {
+			indicator.ATR = v
+		}
+	case "cci":
+		if v, ok := record.ValueByKey("cci").(float64); ok {
+			indicator.CCI = v
+		}
+	case "obv":
+		if v, ok := record.ValueByKey("obv").(float64); ok {
+			indicator.OBV = v
+		}
+	case "dmi":
+		if v, ok := record.ValueByKey("pdi").(float64); ok {
+			indicator.PDI = v
+		}
+		if v, ok := record.ValueByKey("mdi").(float64); ok {
+			indicator.MDI = v
+		}
+		if v, ok := record.ValueByKey("adx").(float64); ok {
+			indicator.ADX = v
+		}
+	case "wr":
+		if v, ok := record.ValueByKey("wr").(float64); ok {
+			indicator.WR = v
+		}
+	case "bias":
+		if v, ok := record.ValueByKey("bias").(float64); ok {
+			indicator.BIAS = v
+		}
+	}
+	if v, ok := record.ValueByKey("period").(int64); ok {
+		indicator.Period = int(v)
+	}
+}
+
 // GetLatestIndicator 获取最新技术指标
 func (r *marketRepository) GetLatestIndicator(ctx context.Context, symbol, exchange string, indicatorType string) (*models.Indicator, error) {
 	query := fmt.Sprintf(`
@@ -495,7 +614,7 @@ func (r *marketRepository) GetLatestIndicator(ctx context.Context, symbol, excha
 		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
 		|> sort(columns: ["_time"], desc: true)
 		|> limit(n: 1)
-	`, r.influx.GetBucket(), symbol, exchange, indicatorType)
+	`, r.influx.Bucket(database.DataIndicators), symbol, exchange, indicatorType)
 
 	result, err := r.influx.Query(ctx, query)
 	if err != nil {
@@ -512,36 +631,144 @@ func (r *marketRepository) GetLatestIndicator(ctx context.Context, symbol, excha
 			IndicatorType: indicatorType,
 		}
 		
-		// 解析字段（简化版）
-		switch indicatorType {
-		case "ma":
-			if v, ok := record.ValueByKey("ma5").(float64); ok {
-				indicator.MA5 = v
-			}
-		case "macd":
-			if v, ok := record.ValueByKey("macd").(float64); ok {
-				indicator.MACD = v
-			}
-		case "rsi":
-			if v, ok := record.ValueByKey("rsi6").(float64); ok {
-				indicator.RSI6 = v
-			}
-		case "kdj":
-			if v, ok := record.ValueByKey("k").(float64); ok {
-				indicator.K = v
-			}
-		case "boll":
-			if v, ok := record.ValueByKey("boll_upper").(float64); ok {
-				indicator.BollUpper = v
-			}
-		}
-		
+		parseIndicatorFields(record, indicator)
 		return indicator, nil
 	}
 
 	return nil, nil
 }
 
+// ============ 资金流向操作 ============
+
+// SaveMoneyFlows 批量保存资金流向
+func (r *marketRepository) SaveMoneyFlows(ctx context.Context, flows []*models.MoneyFlow) error {
+	points := make([]*write.Point, 0, len(flows))
+
+	for _, flow := range flows {
+		point := write.NewPoint(
+			"money_flow",
+			map[string]string{
+				"symbol":   flow.Symbol,
+				"exchange": flow.Exchange,
+			},
+			map[string]interface{}{
+				"main_net_inflow":        flow.MainNetInflow,
+				"main_net_inflow_pct":    flow.MainNetInflowPct,
+				"super_large_net_inflow": flow.SuperLargeNetInflow,
+				"large_net_inflow":       flow.LargeNetInflow,
+				"medium_net_inflow":      flow.MediumNetInflow,
+				"small_net_inflow":       flow.SmallNetInflow,
+			},
+			flow.Date,
+		)
+		points = append(points, point)
+	}
+
+	r.influx.WritePoints(points)
+	r.influx.Flush()
+	return nil
+}
+
+// GetMoneyFlows 查询个股资金流向
+func (r *marketRepository) GetMoneyFlows(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.MoneyFlow, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "money_flow")
+		|> filter(fn: (r) => r.symbol == "%s")
+		|> filter(fn: (r) => r.exchange == "%s")
+		|> pivot(rowKey:["_time"], columnKey: ["_field"], valueColumn: "_value")
+		|> sort(columns: ["_time"])
+	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange)
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询资金流向失败: %w", err)
+	}
+	defer result.Close()
+
+	var flows []*models.MoneyFlow
+	for result.Next() {
+		flow := parseMoneyFlowRecord(result.Record())
+		flow.Symbol = symbol
+		flow.Exchange = exchange
+		flows = append(flows, flow)
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return flows, nil
+}
+
+// GetMoneyFlowRanking 按主力净流入对指定交易日的全市场股票排名
+func (r *marketRepository) GetMoneyFlowRanking(ctx context.Context, date time.Time, limit int, desc bool) ([]*models.MoneyFlow, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 0, 1)
+
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "money_flow")
+		|> pivot(rowKey:["_time", "symbol", "exchange"], columnKey: ["_field"], valueColumn: "_value")
+		|> group()
+		|> sort(columns: ["main_net_inflow"], desc: %t)
+		|> limit(n: %d)
+	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), desc, limit)
+
+	result, err := r.influx.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("查询资金流向排名失败: %w", err)
+	}
+	defer result.Close()
+
+	var flows []*models.MoneyFlow
+	for result.Next() {
+		record := result.Record()
+		flow := parseMoneyFlowRecord(record)
+		if v, ok := record.ValueByKey("symbol").(string); ok {
+			flow.Symbol = v
+		}
+		if v, ok := record.ValueByKey("exchange").(string); ok {
+			flow.Exchange = v
+		}
+		flows = append(flows, flow)
+	}
+
+	if result.Err() != nil {
+		return nil, result.Err()
+	}
+
+	return flows, nil
+}
+
+// parseMoneyFlowRecord 解析资金流向记录的字段
+func parseMoneyFlowRecord(record *query.FluxRecord) *models.MoneyFlow {
+	flow := &models.MoneyFlow{Date: record.Time()}
+
+	if v, ok := record.ValueByKey("main_net_inflow").(float64); ok {
+		flow.MainNetInflow = v
+	}
+	if v, ok := record.ValueByKey("main_net_inflow_pct").(float64); ok {
+		flow.MainNetInflowPct = v
+	}
+	if v, ok := record.ValueByKey("super_large_net_inflow").(float64); ok {
+		flow.SuperLargeNetInflow = v
+	}
+	if v, ok := record.ValueByKey("large_net_inflow").(float64); ok {
+		flow.LargeNetInflow = v
+	}
+	if v, ok := record.ValueByKey("medium_net_inflow").(float64); ok {
+		flow.MediumNetInflow = v
+	}
+	if v, ok := record.ValueByKey("small_net_inflow").(float64); ok {
+		flow.SmallNetInflow = v
+	}
+
+	return flow
+}
+
 // ============ 数据完整性检查 ============
 
 // CheckDataIntegrity 检查数据完整性
@@ -554,7 +781,7 @@ func (r *marketRepository) CheckDataIntegrity(ctx context.Context, symbol, excha
 		|> filter(fn: (r) => r.symbol == "%s")
 		|> filter(fn: (r) => r.exchange == "%s")
 		|> count()
-	`, r.influx.GetBucket(), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange)
+	`, r.influx.Bucket(database.DataDailyBars), start.Format(time.RFC3339), end.Format(time.RFC3339), symbol, exchange)
 
 	result, err := r.influx.Query(ctx, query)
 	if err != nil {
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// NewsFilter 新闻查询条件
+type NewsFilter struct {
+	Symbol   string
+	Exchange string
+	Keyword  string // 全文检索关键词
+	Category string
+	From     time.Time
+	To       time.Time
+}
+
+// NewsRepository 新闻数据仓库接口
+type NewsRepository interface {
+	SaveArticles(ctx context.Context, articles []*models.NewsArticle) (int, error)
+	List(ctx context.Context, filter NewsFilter, page, pageSize int) ([]*models.NewsArticle, int64, error)
+	GetLatestBySymbol(ctx context.Context, symbol, exchange string, limit int) ([]*models.NewsArticle, error)
+}
+
+// newsRepository 新闻数据仓库实现
+type newsRepository struct {
+	db *gorm.DB
+}
+
+// NewNewsRepository 创建新闻数据仓库
+func NewNewsRepository(db *gorm.DB) NewsRepository {
+	return &newsRepository{db: db}
+}
+
+// SaveArticles 保存新闻及其股票标签，按URL去重，返回新增条数
+func (r *newsRepository) SaveArticles(ctx context.Context, articles []*models.NewsArticle) (int, error) {
+	created := 0
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, article := range articles {
+			symbols := article.Symbols
+			article.Symbols = nil
+
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "url"}},
+				DoNothing: true,
+			}).Create(article)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				// 已存在的文章仍补充新的标签
+				if err := tx.Model(&models.NewsArticle{}).Where("url = ?", article.URL).Pluck("id", &article.ID).Error; err != nil {
+					return err
+				}
+			} else {
+				created++
+			}
+
+			for _, sym := range symbols {
+				sym.NewsID = article.ID
+			}
+			if len(symbols) > 0 {
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&symbols).Error; err != nil {
+					return err
+				}
+			}
+			article.Symbols = symbols
+		}
+		return nil
+	})
+
+	return created, err
+}
+
+// List 按条件查询新闻
+func (r *newsRepository) List(ctx context.Context, filter NewsFilter, page, pageSize int) ([]*models.NewsArticle, int64, error) {
+	var articles []*models.NewsArticle
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.NewsArticle{})
+
+	if filter.Symbol != "" {
+		sub := r.db.Model(&models.NewsSymbol{}).Select("news_id").Where("symbol = ?", filter.Symbol)
+		if filter.Exchange != "" {
+			sub = sub.Where("exchange = ?", filter.Exchange)
+		}
+		query = query.Where("id IN (?)", sub)
+	}
+	if filter.Keyword != "" {
+		// simple 分词对中文按整句切分，补充标题模糊匹配
+		query = query.Where("search_vector @@ plainto_tsquery('simple', ?) OR title ILIKE ?",
+			filter.Keyword, "%"+filter.Keyword+"%")
+	}
+	if filter.Category != "" {
+		query = query.Where("category = ?", filter.Category)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("published_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("published_at <= ?", filter.To)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Preload("Symbols").
+		Omit("content").
+		Order("published_at DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&articles).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return articles, total, nil
+}
+
+// GetLatestBySymbol 获取个股最新的新闻
+func (r *newsRepository) GetLatestBySymbol(ctx context.Context, symbol, exchange string, limit int) ([]*models.NewsArticle, error) {
+	articles, _, err := r.List(ctx, NewsFilter{Symbol: symbol, Exchange: exchange}, 1, limit)
+	return articles, err
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// PortfolioRepository 模拟交易组合仓库接口
+type PortfolioRepository interface {
+	Create(ctx context.Context, portfolio *models.Portfolio) error
+	GetByID(ctx context.Context, id uint) (*models.Portfolio, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.Portfolio, error)
+	Delete(ctx context.Context, id uint) error
+
+	// 成交记录相关
+	AddTrade(ctx context.Context, trade *models.PortfolioTrade) error
+	AddTrades(ctx context.Context, portfolioID uint, trades []*models.PortfolioTrade) error
+	GetTrades(ctx context.Context, portfolioID uint) ([]*models.PortfolioTrade, error)
+}
+
+// portfolioRepository 模拟交易组合仓库实现
+type portfolioRepository struct {
+	db *gorm.DB
+}
+
+// NewPortfolioRepository 创建模拟交易组合仓库
+func NewPortfolioRepository(db *gorm.DB) PortfolioRepository {
+	return &portfolioRepository{db: db}
+}
+
+// Create 创建组合
+func (r *portfolioRepository) Create(ctx context.Context, portfolio *models.Portfolio) error {
+	return r.db.WithContext(ctx).Create(portfolio).Error
+}
+
+// GetByID 根据ID获取组合
+func (r *portfolioRepository) GetByID(ctx context.Context, id uint) (*models.Portfolio, error) {
+	var portfolio models.Portfolio
+	if err := r.db.WithContext(ctx).First(&portfolio, id).Error; err != nil {
+		return nil, err
+	}
+	return &portfolio, nil
+}
+
+// GetByUserID 获取用户的全部组合
+func (r *portfolioRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.Portfolio, error) {
+	var portfolios []*models.Portfolio
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&portfolios).Error; err != nil {
+		return nil, err
+	}
+	return portfolios, nil
+}
+
+// Delete 删除组合及其成交记录
+func (r *portfolioRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("portfolio_id = ?", id).Delete(&models.PortfolioTrade{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Portfolio{}, id).Error
+	})
+}
+
+// AddTrade 添加成交记录，并刷新组合的更新时间（分析结果缓存以此失效）
+func (r *portfolioRepository) AddTrade(ctx context.Context, trade *models.PortfolioTrade) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(trade).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Portfolio{}).
+			Where("id = ?", trade.PortfolioID).
+			Update("updated_at", time.Now()).Error
+	})
+}
+
+// AddTrades 批量添加成交记录（同一事务内，全部成功或全部失败）
+func (r *portfolioRepository) AddTrades(ctx context.Context, portfolioID uint, trades []*models.PortfolioTrade) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, trade := range trades {
+			trade.PortfolioID = portfolioID
+		}
+		if err := tx.CreateInBatches(trades, 500).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Portfolio{}).
+			Where("id = ?", portfolioID).
+			Update("updated_at", time.Now()).Error
+	})
+}
+
+// GetTrades 获取组合的成交记录（按成交时间升序）
+func (r *portfolioRepository) GetTrades(ctx context.Context, portfolioID uint) ([]*models.PortfolioTrade, error) {
+	var trades []*models.PortfolioTrade
+	if err := r.db.WithContext(ctx).
+		Where("portfolio_id = ?", portfolioID).
+		Order("traded_at ASC, id ASC").
+		Find(&trades).Error; err != nil {
+		return nil, err
+	}
+	return trades, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// QuotaRepository 套餐配额用量统计，实现 quota.Store
+// 统计读主库：配额在每次创建前检查，需要计入刚创建的记录，副本的复制延迟会让连续创建超出上限。
+type QuotaRepository interface {
+	GetUser(ctx context.Context, userID uint) (*models.User, error)
+	CountStrategies(ctx context.Context, userID uint) (int64, error)
+	CountBacktestsSince(ctx context.Context, userID uint, since time.Time) (int64, error)
+	CountWatchlistItems(ctx context.Context, userID uint) (int64, error)
+	CountAlertRules(ctx context.Context, userID uint) (int64, error)
+}
+
+// quotaRepository 套餐配额用量统计实现
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+// NewQuotaRepository 创建套餐配额用量统计
+func NewQuotaRepository(db *gorm.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// primary 读主库的查询
+func (r *quotaRepository) primary(ctx context.Context) *gorm.DB {
+	return database.UsePrimary(r.db.WithContext(ctx))
+}
+
+// GetUser 获取用户（含套餐信息）
+func (r *quotaRepository) GetUser(ctx context.Context, userID uint) (*models.User, error) {
+	var user models.User
+	if err := r.primary(ctx).Select("id", "plan", "plan_expires_at").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CountStrategies 用户拥有的策略数
+func (r *quotaRepository) CountStrategies(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.primary(ctx).Model(&models.Strategy{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// CountBacktestsSince 用户自 since 起提交的回测数
+func (r *quotaRepository) CountBacktestsSince(ctx context.Context, userID uint, since time.Time) (int64, error) {
+	var count int64
+	subQuery := r.db.Model(&models.Strategy{}).Where("user_id = ?", userID).Select("id")
+	err := r.primary(ctx).Model(&models.BacktestRecord{}).
+		Where("strategy_id IN (?) AND created_at >= ?", subQuery, since).
+		Count(&count).Error
+	return count, err
+}
+
+// CountWatchlistItems 用户所有自选股分组中的股票总数
+func (r *quotaRepository) CountWatchlistItems(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	subQuery := r.db.Model(&models.Watchlist{}).Where("user_id = ?", userID).Select("id")
+	err := r.primary(ctx).Model(&models.WatchlistItem{}).Where("watchlist_id IN (?)", subQuery).Count(&count).Error
+	return count, err
+}
+
+// CountAlertRules 用户的提醒规则数（含已停用的规则）
+func (r *quotaRepository) CountAlertRules(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.primary(ctx).Model(&models.AlertRule{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
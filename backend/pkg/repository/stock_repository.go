@@ -2,7 +2,12 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -22,7 +27,23 @@ type StockRepository interface {
 	GetByIndustry(ctx context.Context, industry string, offset, limit int) ([]*models.Stock, int64, error)
 	Search(ctx context.Context, keyword string) ([]*models.Stock, error)
 	GetActiveStocks(ctx context.Context) ([]*models.Stock, error)
+	GetListedAsOf(ctx context.Context, asOf time.Time) ([]*models.Stock, error)
+	GetByRiskWarning(ctx context.Context, st bool, offset, limit int) ([]*models.Stock, int64, error)
 	SymbolExists(ctx context.Context, symbol, exchange string) (bool, error)
+	ListStocks(ctx context.Context, query StockListQuery) ([]*models.Stock, int64, error)
+
+	// 股票资料（上市日期、股本）
+	UpdateMetadata(ctx context.Context, symbol, exchange string, listDate *time.Time, totalShare, floatShare int64) (bool, error)
+
+	// 数据质量评分
+	UpdateQualityScore(ctx context.Context, symbol, exchange string, score int, scoredAt time.Time) error
+	GetQualityScores(ctx context.Context) (map[string]int, error)
+
+	// 风险警示相关
+	GetRiskWarningHistory(ctx context.Context, symbol, exchange string) ([]*models.StockRiskWarning, error)
+	GetRiskWarningsAsOf(ctx context.Context, asOf time.Time) (map[string]string, error)
+	UpdateRiskWarning(ctx context.Context, symbol, exchange, warning string, date time.Time) error
+	ReplaceRiskWarnings(ctx context.Context, warnings []*models.StockRiskWarning) error
 }
 
 // stockRepository 股票数据仓库实现
@@ -80,78 +101,29 @@ func (r *stockRepository) GetBySymbol(ctx context.Context, symbol, exchange stri
 
 // GetAll 获取所有股票
 func (r *stockRepository) GetAll(ctx context.Context, offset, limit int) ([]*models.Stock, int64, error) {
-	var stocks []*models.Stock
-	var total int64
-
-	if err := r.db.WithContext(ctx).Model(&models.Stock{}).Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	if err := r.db.WithContext(ctx).
-		Offset(offset).Limit(limit).
-		Order("symbol ASC").
-		Find(&stocks).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return stocks, total, nil
+	return r.ListStocks(ctx, StockListQuery{Offset: offset, Limit: limit})
 }
 
 // GetByExchange 根据交易所获取股票
 func (r *stockRepository) GetByExchange(ctx context.Context, exchange string, offset, limit int) ([]*models.Stock, int64, error) {
-	var stocks []*models.Stock
-	var total int64
-
-	if err := r.db.WithContext(ctx).
-		Model(&models.Stock{}).
-		Where("exchange = ?", exchange).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	if err := r.db.WithContext(ctx).
-		Where("exchange = ?", exchange).
-		Offset(offset).Limit(limit).
-		Order("symbol ASC").
-		Find(&stocks).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return stocks, total, nil
+	return r.ListStocks(ctx, StockListQuery{StockFilter: StockFilter{Exchange: exchange}, Offset: offset, Limit: limit})
 }
 
 // GetByIndustry 根据行业获取股票
 func (r *stockRepository) GetByIndustry(ctx context.Context, industry string, offset, limit int) ([]*models.Stock, int64, error) {
-	var stocks []*models.Stock
-	var total int64
-
-	if err := r.db.WithContext(ctx).
-		Model(&models.Stock{}).
-		Where("industry = ?", industry).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
-
-	if err := r.db.WithContext(ctx).
-		Where("industry = ?", industry).
-		Offset(offset).Limit(limit).
-		Order("symbol ASC").
-		Find(&stocks).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return stocks, total, nil
+	return r.ListStocks(ctx, StockListQuery{StockFilter: StockFilter{Industry: industry}, Offset: offset, Limit: limit})
 }
 
 // Search 搜索股票
 func (r *stockRepository) Search(ctx context.Context, keyword string) ([]*models.Stock, error) {
 	var stocks []*models.Stock
 	
+	pattern := containsPattern(keyword)
 	query := r.db.WithContext(ctx).
-		Where("symbol LIKE ? OR name LIKE ? OR full_name LIKE ?",
-			"%"+keyword+"%",
-			"%"+keyword+"%",
-			"%"+keyword+"%").
+		Where(`symbol LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\' OR full_name LIKE ? ESCAPE '\'`,
+			pattern,
+			pattern,
+			pattern).
 		Order("symbol ASC")
 
 	if err := query.Find(&stocks).Error; err != nil {
@@ -173,6 +145,19 @@ func (r *stockRepository) GetActiveStocks(ctx context.Context) ([]*models.Stock,
 	return stocks, nil
 }
 
+// GetListedAsOf 获取指定日期已上市的全部股票（含此后退市的股票，避免幸存者偏差）
+// 上市日期未知的股票同样返回。
+func (r *stockRepository) GetListedAsOf(ctx context.Context, asOf time.Time) ([]*models.Stock, error) {
+	var stocks []*models.Stock
+	if err := r.db.WithContext(ctx).
+		Where("list_date IS NULL OR list_date <= ?", asOf.Format("2006-01-02")).
+		Order("symbol ASC").
+		Find(&stocks).Error; err != nil {
+		return nil, err
+	}
+	return stocks, nil
+}
+
 // SymbolExists 检查股票代码是否存在
 func (r *stockRepository) SymbolExists(ctx context.Context, symbol, exchange string) (bool, error) {
 	var count int64
@@ -184,3 +169,338 @@ func (r *stockRepository) SymbolExists(ctx context.Context, symbol, exchange str
 	}
 	return count > 0, nil
 }
+
+// GetByRiskWarning 按风险警示状态获取股票，st 为 true 时返回 ST/*ST 股票，否则返回非 ST 股票
+func (r *stockRepository) GetByRiskWarning(ctx context.Context, st bool, offset, limit int) ([]*models.Stock, int64, error) {
+	return r.ListStocks(ctx, StockListQuery{StockFilter: StockFilter{ST: &st}, Offset: offset, Limit: limit})
+}
+
+// StockFilter 股票筛选条件，各条件之间为 AND 关系，零值表示不限
+type StockFilter struct {
+	Exchange     string
+	Industry     string
+	Status       string     // active 等上市状态
+	ST           *bool      // true 只看 ST/*ST，false 排除 ST/*ST
+	ListedAfter  *time.Time // 上市日期不早于该日期，上市日期未知的股票不返回
+	Keyword      string     // 匹配代码、名称或公司全称
+	MinQuality   *int       // 数据质量评分不低于该值，未评分的股票不排除
+	UpdatedSince *time.Time // 增量同步：只返回该时间之后有变化的股票
+}
+
+// StockListQuery 股票列表查询条件
+type StockListQuery struct {
+	StockFilter
+	Sort   string       // 排序字段，见 StockSortFields，默认 symbol
+	Desc   bool         // 是否降序
+	Cursor *StockCursor // 游标翻页：只返回排在游标之后的记录，此时忽略 Offset
+	Offset int
+	Limit  int // 不大于 0 时不限制条数
+}
+
+// stockSortField 可在数据库中排序的字段
+type stockSortField struct {
+	expr  string                     // 排序表达式，空值按最小值处理，保证游标比较与排序一致
+	cast  string                     // 游标值的类型转换
+	value func(*models.Stock) string // 取记录的排序值，用于生成游标
+}
+
+var stockSortFields = map[string]stockSortField{
+	"symbol": {expr: "symbol", cast: "text", value: func(s *models.Stock) string { return s.Symbol }},
+	"name":   {expr: "name", cast: "text", value: func(s *models.Stock) string { return s.Name }},
+	"list_date": {expr: "COALESCE(list_date, DATE '1900-01-01')", cast: "date", value: func(s *models.Stock) string {
+		if s.ListDate == nil {
+			return "1900-01-01"
+		}
+		return s.ListDate.Format("2006-01-02")
+	}},
+	"total_share": {expr: "COALESCE(total_share, 0)", cast: "bigint", value: func(s *models.Stock) string { return strconv.FormatInt(s.TotalShare, 10) }},
+	"float_share": {expr: "COALESCE(float_share, 0)", cast: "bigint", value: func(s *models.Stock) string { return strconv.FormatInt(s.FloatShare, 10) }},
+	"quality_score": {expr: "COALESCE(quality_score, -1)", cast: "integer", value: func(s *models.Stock) string {
+		if s.QualityScore == nil {
+			return "-1"
+		}
+		return strconv.Itoa(*s.QualityScore)
+	}},
+}
+
+// StockSortFields 是否为可在数据库中排序的字段
+func StockSortFields(field string) bool {
+	_, ok := stockSortFields[field]
+	return ok
+}
+
+// StockCursor 游标翻页位置：上一页最后一条记录的排序值与代码
+// 排序值相同时按 symbol、exchange 区分先后，翻页期间新增或删除记录不会导致重复或遗漏。
+type StockCursor struct {
+	Sort     string `json:"o"`
+	Desc     bool   `json:"d,omitempty"`
+	Value    string `json:"v"`
+	Symbol   string `json:"s"`
+	Exchange string `json:"e"`
+}
+
+// Encode 编码为可放在查询参数中的字符串
+func (c *StockCursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeStockCursor 解析游标字符串
+func DecodeStockCursor(raw string) (*StockCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("游标格式错误")
+	}
+	var cursor StockCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.Symbol == "" {
+		return nil, fmt.Errorf("游标格式错误")
+	}
+	return &cursor, nil
+}
+
+// StockCursorAt 生成指向指定记录之后的游标，仅适用于数据库排序字段
+func StockCursorAt(stock *models.Stock, sort string, desc bool) *StockCursor {
+	field, ok := stockSortFields[sort]
+	if !ok {
+		return nil
+	}
+	return &StockCursor{Sort: sort, Desc: desc, Value: field.value(stock), Symbol: stock.Symbol, Exchange: stock.Exchange}
+}
+
+// ListStocks 按组合条件查询股票，返回当前页与满足筛选条件的总数（不受游标影响）
+func (r *stockRepository) ListStocks(ctx context.Context, query StockListQuery) ([]*models.Stock, int64, error) {
+	if query.Sort == "" {
+		query.Sort = "symbol"
+	}
+	field, ok := stockSortFields[query.Sort]
+	if !ok {
+		return nil, 0, fmt.Errorf("不支持的排序字段: %s", query.Sort)
+	}
+
+	db := applyStockFilter(r.db.WithContext(ctx).Model(&models.Stock{}), query.StockFilter)
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	dir, cmp := "ASC", ">"
+	if query.Desc {
+		dir, cmp = "DESC", "<"
+	}
+	if c := query.Cursor; c != nil {
+		db = db.Where(fmt.Sprintf("(%s, symbol, exchange) %s (CAST(? AS %s), ?, ?)", field.expr, cmp, field.cast),
+			c.Value, c.Symbol, c.Exchange)
+	} else if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+
+	var stocks []*models.Stock
+	order := fmt.Sprintf("%s %s, symbol %s, exchange %s", field.expr, dir, dir, dir)
+	if err := db.Order(order).Find(&stocks).Error; err != nil {
+		return nil, 0, err
+	}
+	return stocks, total, nil
+}
+
+// applyStockFilter 将筛选条件组合为 WHERE 子句
+func applyStockFilter(db *gorm.DB, filter StockFilter) *gorm.DB {
+	if filter.Exchange != "" {
+		db = db.Where("exchange = ?", filter.Exchange)
+	}
+	if filter.Industry != "" {
+		db = db.Where("industry = ?", filter.Industry)
+	}
+	if filter.Status != "" {
+		db = db.Where("status = ?", filter.Status)
+	}
+	if filter.ST != nil {
+		if *filter.ST {
+			db = db.Where("risk_warning <> ''")
+		} else {
+			db = db.Where("risk_warning IS NULL OR risk_warning = ''")
+		}
+	}
+	if filter.ListedAfter != nil {
+		db = db.Where("list_date >= ?", filter.ListedAfter.Format("2006-01-02"))
+	}
+	if filter.MinQuality != nil {
+		db = db.Where("quality_score IS NULL OR quality_score >= ?", *filter.MinQuality)
+	}
+	if filter.UpdatedSince != nil {
+		db = db.Where("updated_at > ?", *filter.UpdatedSince)
+	}
+	if keyword := strings.TrimSpace(filter.Keyword); keyword != "" {
+		pattern := containsPattern(keyword)
+		db = db.Where(`symbol LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\' OR full_name LIKE ? ESCAPE '\'`, pattern, pattern, pattern)
+	}
+	return db
+}
+
+// likeEscaper 转义 LIKE 通配符与转义符本身，配合 ESCAPE '\' 使用
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern 按字面匹配包含关键词的 LIKE 模式：关键词中的 %、_ 不作为通配符
+func containsPattern(keyword string) string {
+	return "%" + likeEscaper.Replace(keyword) + "%"
+}
+
+// UpdateMetadata 保存股票的上市日期与股本，listDate 为空或股本不大于 0 的字段保持不变
+// 返回股票是否存在（资料与已保存的一致时同样返回 true）。
+func (r *stockRepository) UpdateMetadata(ctx context.Context, symbol, exchange string, listDate *time.Time, totalShare, floatShare int64) (bool, error) {
+	updates := map[string]interface{}{}
+	if listDate != nil {
+		updates["list_date"] = listDate.Format("2006-01-02")
+	}
+	if totalShare > 0 {
+		updates["total_share"] = totalShare
+	}
+	if floatShare > 0 {
+		updates["float_share"] = floatShare
+	}
+	if len(updates) == 0 {
+		return r.SymbolExists(ctx, symbol, exchange)
+	}
+	result := r.db.WithContext(ctx).Model(&models.Stock{}).
+		Where("symbol = ? AND exchange = ?", symbol, exchange).
+		Updates(updates)
+	return result.RowsAffected > 0, result.Error
+}
+
+// UpdateQualityScore 保存股票的数据质量评分
+func (r *stockRepository) UpdateQualityScore(ctx context.Context, symbol, exchange string, score int, scoredAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.Stock{}).
+		Where("symbol = ? AND exchange = ?", symbol, exchange).
+		Updates(map[string]interface{}{"quality_score": score, "quality_scored_at": scoredAt}).Error
+}
+
+// GetQualityScores 获取已评分股票的数据质量评分，键为 symbol.exchange
+func (r *stockRepository) GetQualityScores(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		Symbol       string
+		Exchange     string
+		QualityScore int
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Stock{}).
+		Select("symbol, exchange, quality_score").
+		Where("quality_score IS NOT NULL").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]int, len(rows))
+	for _, row := range rows {
+		scores[row.Symbol+"."+row.Exchange] = row.QualityScore
+	}
+	return scores, nil
+}
+
+// GetRiskWarningHistory 获取股票的风险警示历史，按实施日期倒序
+func (r *stockRepository) GetRiskWarningHistory(ctx context.Context, symbol, exchange string) ([]*models.StockRiskWarning, error) {
+	var warnings []*models.StockRiskWarning
+	if err := r.db.WithContext(ctx).
+		Where("symbol = ? AND exchange = ?", symbol, exchange).
+		Order("start_date DESC").
+		Find(&warnings).Error; err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// GetRiskWarningsAsOf 获取指定日期处于风险警示的股票，键为 symbol.exchange，值为 ST/*ST
+func (r *stockRepository) GetRiskWarningsAsOf(ctx context.Context, asOf time.Time) (map[string]string, error) {
+	day := asOf.Format("2006-01-02")
+	var warnings []*models.StockRiskWarning
+	if err := r.db.WithContext(ctx).
+		Where("start_date <= ? AND (end_date IS NULL OR end_date > ?)", day, day).
+		Order("start_date ASC").
+		Find(&warnings).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string, len(warnings))
+	for _, w := range warnings {
+		result[w.Symbol+"."+w.Exchange] = w.Warning
+	}
+	return result, nil
+}
+
+// UpdateRiskWarning 记录股票风险警示变化：结束当前警示区间，warning 非空时自指定日期开始新的区间
+// 状态未变化时不做修改。
+func (r *stockRepository) UpdateRiskWarning(ctx context.Context, symbol, exchange, warning string, date time.Time) error {
+	day := date.Format("2006-01-02")
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.StockRiskWarning
+		err := tx.Where("symbol = ? AND exchange = ? AND end_date IS NULL", symbol, exchange).
+			Order("start_date DESC").
+			First(&current).Error
+		switch {
+		case err == nil && current.Warning == warning:
+			return nil
+		case err == nil:
+			if err := tx.Model(&models.StockRiskWarning{}).
+				Where("symbol = ? AND exchange = ? AND end_date IS NULL", symbol, exchange).
+				Update("end_date", day).Error; err != nil {
+				return err
+			}
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return err
+		case warning == "":
+			return nil
+		}
+
+		if warning != "" {
+			if err := tx.Create(&models.StockRiskWarning{
+				Symbol:    symbol,
+				Exchange:  exchange,
+				Warning:   warning,
+				StartDate: date,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.Stock{}).
+			Where("symbol = ? AND exchange = ?", symbol, exchange).
+			Update("risk_warning", warning).Error
+	})
+}
+
+// ReplaceRiskWarnings 以完整历史替换涉及股票的风险警示记录，并按未撤销的记录刷新股票当前状态
+func (r *stockRepository) ReplaceRiskWarnings(ctx context.Context, warnings []*models.StockRiskWarning) error {
+	if len(warnings) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		current := make(map[string]string)
+		for _, w := range warnings {
+			key := w.Symbol + "." + w.Exchange
+			if _, ok := current[key]; !ok {
+				current[key] = ""
+				if err := tx.Where("symbol = ? AND exchange = ?", w.Symbol, w.Exchange).
+					Delete(&models.StockRiskWarning{}).Error; err != nil {
+					return err
+				}
+			}
+			if w.EndDate == nil {
+				current[key] = w.Warning
+			}
+		}
+
+		if err := tx.CreateInBatches(warnings, 500).Error; err != nil {
+			return err
+		}
+
+		for key, warning := range current {
+			parts := strings.SplitN(key, ".", 2)
+			if err := tx.Model(&models.Stock{}).
+				Where("symbol = ? AND exchange = ?", parts[0], parts[1]).
+				Update("risk_warning", warning).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
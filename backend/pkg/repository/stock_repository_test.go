@@ -0,0 +1,295 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestStockCursorRoundTrip(t *testing.T) {
+	cursors := []*StockCursor{
+		{Sort: "symbol", Value: "600519", Symbol: "600519", Exchange: "SH"},
+		{Sort: "name", Desc: true, Value: "贵州茅台", Symbol: "600519", Exchange: "SH"},
+		{Sort: "quality_score", Value: "-1", Symbol: "000001", Exchange: "SZ"},
+		// 行情排序缺少数据的记录取值为空
+		{Sort: "change_pct", Desc: true, Value: "", Symbol: "830799", Exchange: "BJ"},
+		{Sort: "market_cap", Value: "2.1e+12", Symbol: "600519", Exchange: "SH"},
+	}
+	for _, want := range cursors {
+		raw := want.Encode()
+		if strings.ContainsAny(raw, "+/=") {
+			t.Errorf("游标 %q 不能直接放在查询参数中", raw)
+		}
+		got, err := DecodeStockCursor(raw)
+		if err != nil {
+			t.Fatalf("DecodeStockCursor(%q): %v", raw, err)
+		}
+		if *got != *want {
+			t.Errorf("往返后 %+v，期望 %+v", got, want)
+		}
+	}
+}
+
+func TestDecodeStockCursorInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"",
+		"不是base64",
+		base64.StdEncoding.EncodeToString([]byte(`{"s":"600519"}`)), // 带填充
+		base64.RawURLEncoding.EncodeToString([]byte(`not json`)),
+		base64.RawURLEncoding.EncodeToString([]byte(`{"o":"symbol","v":"1"}`)), // 缺少代码
+	} {
+		if _, err := DecodeStockCursor(raw); err == nil {
+			t.Errorf("DecodeStockCursor(%q) 应返回错误", raw)
+		}
+	}
+}
+
+func TestStockCursorAt(t *testing.T) {
+	listed := time.Date(2001, 8, 27, 0, 0, 0, 0, time.UTC)
+	score := 87
+	scored := &models.Stock{Symbol: "600519", Exchange: "SH", Name: "贵州茅台", ListDate: &listed, TotalShare: 1256197800, FloatShare: 1256197800, QualityScore: &score}
+	bare := &models.Stock{Symbol: "000001", Exchange: "SZ", Name: "平安银行"}
+	tests := []struct {
+		stock *models.Stock
+		sort  string
+		want  string
+	}{
+		{scored, "symbol", "600519"},
+		{scored, "name", "贵州茅台"},
+		{scored, "list_date", "2001-08-27"},
+		{scored, "total_share", "1256197800"},
+		{scored, "float_share", "1256197800"},
+		{scored, "quality_score", "87"},
+		// 空值与排序表达式中的 COALESCE 一致
+		{bare, "list_date", "1900-01-01"},
+		{bare, "total_share", "0"},
+		{bare, "quality_score", "-1"},
+	}
+	for _, tt := range tests {
+		cursor := StockCursorAt(tt.stock, tt.sort, true)
+		if cursor == nil || cursor.Value != tt.want || cursor.Sort != tt.sort || !cursor.Desc || cursor.Symbol != tt.stock.Symbol || cursor.Exchange != tt.stock.Exchange {
+			t.Errorf("StockCursorAt(%s, %s) = %+v，期望取值 %s", tt.stock.Symbol, tt.sort, cursor, tt.want)
+		}
+	}
+	// 行情字段不在数据库中排序
+	if cursor := StockCursorAt(scored, "change_pct", false); cursor != nil {
+		t.Errorf("行情排序字段不应生成数据库游标: %+v", cursor)
+	}
+}
+
+// listTestStocks 排序值有重复、有空值，同一代码在不同交易所各有一条
+func listTestStocks() []*models.Stock {
+	score := func(v int) *int { return &v }
+	return []*models.Stock{
+		{Symbol: "600519", Exchange: "SH", Name: "贵州茅台", TotalShare: 1256, FloatShare: 1256, QualityScore: score(90)},
+		{Symbol: "000001", Exchange: "SZ", Name: "平安银行", TotalShare: 19406, FloatShare: 19405, QualityScore: score(90)},
+		{Symbol: "000001", Exchange: "SH", Name: "上证指数", QualityScore: score(75)},
+		{Symbol: "000858", Exchange: "SZ", Name: "五粮液", TotalShare: 3882, FloatShare: 3881},
+		{Symbol: "601318", Exchange: "SH", Name: "中国平安", TotalShare: 18210, FloatShare: 10762, QualityScore: score(75)},
+		{Symbol: "300750", Exchange: "SZ", Name: "宁德时代", TotalShare: 3882, FloatShare: 3881},
+		{Symbol: "830799", Exchange: "BJ", Name: "艾融软件", TotalShare: 1256, FloatShare: 0, QualityScore: score(60), Status: "delisted"},
+	}
+}
+
+// stockSortValues 测试中按排序字段比较记录，空值与 COALESCE 后的值一致
+var stockSortValues = map[string]func(*models.Stock) interface{}{
+	"symbol":      func(s *models.Stock) interface{} { return s.Symbol },
+	"name":        func(s *models.Stock) interface{} { return s.Name },
+	"total_share": func(s *models.Stock) interface{} { return s.TotalShare },
+	"float_share": func(s *models.Stock) interface{} { return s.FloatShare },
+	"quality_score": func(s *models.Stock) interface{} {
+		if s.QualityScore == nil {
+			return int64(-1)
+		}
+		return int64(*s.QualityScore)
+	},
+}
+
+// expectedOrder 按排序值、symbol、exchange 排序后的 symbol.exchange
+func expectedOrder(stocks []*models.Stock, field string, desc bool) []string {
+	value := stockSortValues[field]
+	compare := func(a, b *models.Stock) int {
+		switch va, vb := value(a), value(b); va := va.(type) {
+		case string:
+			if c := strings.Compare(va, vb.(string)); c != 0 {
+				return c
+			}
+		case int64:
+			if va != vb.(int64) {
+				if va < vb.(int64) {
+					return -1
+				}
+				return 1
+			}
+		}
+		if c := strings.Compare(a.Symbol, b.Symbol); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Exchange, b.Exchange)
+	}
+	sorted := append([]*models.Stock(nil), stocks...)
+	sort.Slice(sorted, func(i, j int) bool {
+		c := compare(sorted[i], sorted[j])
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	return stockKeys(sorted)
+}
+
+func stockKeys(stocks []*models.Stock) []string {
+	keys := make([]string, len(stocks))
+	for i, s := range stocks {
+		keys[i] = s.Symbol + "." + s.Exchange
+	}
+	return keys
+}
+
+// pageAll 按游标逐页读取直到最后一页
+func pageAll(t *testing.T, repo StockRepository, query StockListQuery) []string {
+	t.Helper()
+	var keys []string
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("翻页没有结束")
+		}
+		stocks, _, err := repo.ListStocks(context.Background(), query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, stockKeys(stocks)...)
+		if len(stocks) < query.Limit {
+			return keys
+		}
+		query.Cursor = StockCursorAt(stocks[len(stocks)-1], query.Sort, query.Desc)
+	}
+}
+
+// list_date 的排序表达式用到 PostgreSQL 的 DATE 字面量与类型转换，不在 SQLite 上测试
+func TestListStocksCursor(t *testing.T) {
+	stocks := listTestStocks()
+	repo := NewStockRepository(newTestDB(t, &models.Stock{}))
+	if err := repo.CreateBatch(context.Background(), stocks); err != nil {
+		t.Fatal(err)
+	}
+
+	for field := range stockSortValues {
+		for _, desc := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/desc=%v", field, desc), func(t *testing.T) {
+				want := expectedOrder(stocks, field, desc)
+				all, total, err := repo.ListStocks(context.Background(), StockListQuery{Sort: field, Desc: desc})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := stockKeys(all); strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Fatalf("排序 %v，期望 %v", got, want)
+				}
+				if total != int64(len(stocks)) {
+					t.Errorf("total = %d", total)
+				}
+				// 每页两条，相同排序值跨页时既不重复也不遗漏
+				if got := pageAll(t, repo, StockListQuery{Sort: field, Desc: desc, Limit: 2}); strings.Join(got, ",") != strings.Join(want, ",") {
+					t.Errorf("游标翻页 %v，期望 %v", got, want)
+				}
+			})
+		}
+	}
+}
+
+// 游标与筛选条件组合，总数不受游标影响；游标忽略 Offset
+func TestListStocksCursorWithFilter(t *testing.T) {
+	repo := NewStockRepository(newTestDB(t, &models.Stock{}))
+	ctx := context.Background()
+	if err := repo.CreateBatch(ctx, listTestStocks()); err != nil {
+		t.Fatal(err)
+	}
+
+	query := StockListQuery{
+		StockFilter: StockFilter{Status: "active"},
+		Sort:        "total_share",
+		Desc:        true,
+		Cursor:      &StockCursor{Sort: "total_share", Desc: true, Value: "3882", Symbol: "300750", Exchange: "SZ"},
+		Offset:      100,
+		Limit:       10,
+	}
+	page, total, err := repo.ListStocks(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(stockKeys(page), ","); got != "000858.SZ,600519.SH,000001.SH" {
+		t.Errorf("游标之后 %s", got)
+	}
+	if total != 6 {
+		t.Errorf("total = %d，期望 6（不含退市股票）", total)
+	}
+
+	// 翻页期间删除已读过的记录、新增排在游标之前的记录，后续页不受影响
+	if err := repo.Delete(ctx, page[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Create(ctx, &models.Stock{Symbol: "688981", Exchange: "SH", Name: "中芯国际", TotalShare: 7945, Status: "active"}); err != nil {
+		t.Fatal(err)
+	}
+	query.Cursor = StockCursorAt(page[0], "total_share", true)
+	page, _, err = repo.ListStocks(ctx, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(stockKeys(page), ","); got != "600519.SH,000001.SH" {
+		t.Errorf("删除与新增后游标之后 %s", got)
+	}
+
+	if _, _, err := repo.ListStocks(ctx, StockListQuery{Sort: "change_pct"}); err == nil {
+		t.Error("行情排序字段应返回错误")
+	}
+}
+
+// 关键词中的 %、_ 与反斜杠按字面匹配，不作为 LIKE 通配符
+func TestStockKeywordEscapesWildcards(t *testing.T) {
+	repo := NewStockRepository(newTestDB(t, &models.Stock{}))
+	ctx := context.Background()
+	stocks := []*models.Stock{
+		{Symbol: "600519", Exchange: "SH", Name: "贵州茅台"},
+		{Symbol: "000001", Exchange: "SZ", Name: "平安银行"},
+		{Symbol: "900901", Exchange: "SH", Name: "云赛B股", FullName: "云赛智联_B股"},
+		{Symbol: "688001", Exchange: "SH", Name: "华兴源创", FullName: "华兴源创100%控股"},
+		{Symbol: "688002", Exchange: "SH", Name: `睿创\微纳`},
+	}
+	if err := repo.CreateBatch(ctx, stocks); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		keyword string
+		want    string
+	}{
+		{"%", "688001.SH"},
+		{"_", "900901.SH"},
+		{`\`, "688002.SH"},
+		{`创\微`, "688002.SH"},
+		{"茅台", "600519.SH"},
+	}
+	for _, tt := range tests {
+		listed, _, err := repo.ListStocks(ctx, StockListQuery{StockFilter: StockFilter{Keyword: tt.keyword}, Sort: "symbol"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(stockKeys(listed), ","); got != tt.want {
+			t.Errorf("ListStocks 关键词 %q 匹配 %s，期望 %s", tt.keyword, got, tt.want)
+		}
+		found, err := repo.Search(ctx, tt.keyword)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(stockKeys(found), ","); got != tt.want {
+			t.Errorf("Search 关键词 %q 匹配 %s，期望 %s", tt.keyword, got, tt.want)
+		}
+	}
+}
@@ -2,8 +2,12 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"stock-analysis-system/backend/pkg/lock"
 	"stock-analysis-system/backend/pkg/models"
 )
 
@@ -13,12 +17,35 @@ type StrategyRepository interface {
 	Update(ctx context.Context, strategy *models.Strategy) error
 	Delete(ctx context.Context, id uint) error
 	GetByID(ctx context.Context, id uint) (*models.Strategy, error)
-	GetByUserID(ctx context.Context, userID uint, strategyType string, page, pageSize int) ([]*models.Strategy, int64, error)
+	GetByUserID(ctx context.Context, userID uint, strategyType string, tags []string, updatedSince *time.Time, page, pageSize int) ([]*models.Strategy, int64, error)
+	GetDeletedIDs(ctx context.Context, userID uint, since time.Time) ([]uint, error)
+	GetBySymbol(ctx context.Context, userID uint, symbol, exchange, strategyType string, tags []string, page, pageSize int) ([]*models.Strategy, int64, error)
+	GetRecentSignalsBySymbol(ctx context.Context, strategyIDs []uint, symbol, exchange string, since time.Time) ([]*models.TradeSignal, error)
 	
 	// 交易信号相关
 	GetSignalsByStrategyID(ctx context.Context, strategyID uint, page, pageSize int) ([]*models.TradeSignal, int64, error)
-	GetSignalsByUserID(ctx context.Context, userID uint, symbol, signalType string, page, pageSize int) ([]*models.TradeSignal, int64, error)
+	GetSignalsByUserID(ctx context.Context, userID uint, symbol, signalType, status string, page, pageSize int) ([]*models.TradeSignal, int64, error)
 	CreateSignal(ctx context.Context, signal *models.TradeSignal) error
+	GetRecentSignalsByUserID(ctx context.Context, userID uint, since time.Time) ([]*models.TradeSignal, error)
+	CancelSignal(ctx context.Context, id uint, note string) error
+	GetPriorities(ctx context.Context, userID uint) (map[uint]int, error)
+
+	// 信号聚合规则
+	GetSignalPolicy(ctx context.Context, userID uint) (*models.SignalPolicy, error)
+	SaveSignalPolicy(ctx context.Context, policy *models.SignalPolicy) error
+
+	// 定期回归回测
+	GetRegressionStrategies(ctx context.Context) ([]*models.Strategy, error)
+	SavePerformance(ctx context.Context, perf *models.StrategyPerformance) error
+	GetPerformanceHistory(ctx context.Context, strategyID uint, start, end time.Time) ([]*models.StrategyPerformance, error)
+	GetLatestPerformance(ctx context.Context, strategyID uint) (*models.StrategyPerformance, error)
+
+	// 实盘偏离检查
+	GetDivergenceStrategies(ctx context.Context) ([]*models.Strategy, error)
+	GetSignalsSince(ctx context.Context, strategyID uint, since time.Time) ([]*models.TradeSignal, error)
+	CreateDivergence(ctx context.Context, d *models.StrategyDivergence) error
+	GetLatestDivergence(ctx context.Context, strategyID uint) (*models.StrategyDivergence, error)
+	GetDivergences(ctx context.Context, strategyID uint, page, pageSize int) ([]*models.StrategyDivergence, int64, error)
 }
 
 // strategyRepository 策略数据仓库实现
@@ -36,9 +63,9 @@ func (r *strategyRepository) Create(ctx context.Context, strategy *models.Strate
 	return r.db.WithContext(ctx).Create(strategy).Error
 }
 
-// Update 更新策略
+// Update 更新策略，strategy.Version 为读取时的版本号，已被其他请求修改时返回 ErrVersionConflict
 func (r *strategyRepository) Update(ctx context.Context, strategy *models.Strategy) error {
-	return r.db.WithContext(ctx).Save(strategy).Error
+	return updateVersioned(r.db.WithContext(ctx), strategy, &strategy.Version, "Tags")
 }
 
 // Delete 删除策略
@@ -49,14 +76,15 @@ func (r *strategyRepository) Delete(ctx context.Context, id uint) error {
 // GetByID 根据ID获取策略
 func (r *strategyRepository) GetByID(ctx context.Context, id uint) (*models.Strategy, error) {
 	var strategy models.Strategy
-	if err := r.db.WithContext(ctx).First(&strategy, id).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Tags").First(&strategy, id).Error; err != nil {
 		return nil, err
 	}
 	return &strategy, nil
 }
 
-// GetByUserID 获取用户的策略列表
-func (r *strategyRepository) GetByUserID(ctx context.Context, userID uint, strategyType string, page, pageSize int) ([]*models.Strategy, int64, error) {
+// GetByUserID 获取用户的策略列表（含其他用户的公开策略）
+// updatedSince 不为空时只返回该时间之后有变化的策略，按更新时间排序，便于客户端分页增量同步。
+func (r *strategyRepository) GetByUserID(ctx context.Context, userID uint, strategyType string, tags []string, updatedSince *time.Time, page, pageSize int) ([]*models.Strategy, int64, error) {
 	var strategies []*models.Strategy
 	var total int64
 
@@ -65,18 +93,82 @@ func (r *strategyRepository) GetByUserID(ctx context.Context, userID uint, strat
 	if strategyType != "" {
 		query = query.Where("type = ?", strategyType)
 	}
+	if len(tags) > 0 {
+		query = query.Where("id IN (?)", taggedWith(r.db, strategyTagsTable, "strategy_id", userID, tags))
+	}
+	if updatedSince != nil {
+		query = query.Where("updated_at > ?", *updatedSince).Order("updated_at, id")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Preload("Tags").Offset((page - 1) * pageSize).Limit(pageSize).Find(&strategies).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return strategies, total, nil
+}
+
+// GetDeletedIDs 获取 since 之后对用户不再可见的策略ID：用户自己删除的策略，以及其他用户删除或取消公开的策略
+// 之后又重新公开的策略仍然可见，不返回。
+func (r *strategyRepository) GetDeletedIDs(ctx context.Context, userID uint, since time.Time) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).Model(&models.Tombstone{}).
+		Distinct("entity_id").
+		Where("entity = ? AND deleted_at > ?", models.TombstoneStrategy, since).
+		Where("(owner_id = ? AND reason = ?) OR (public AND owner_id <> ?)", userID, models.TombstoneDeleted, userID).
+		Where("NOT EXISTS (SELECT 1 FROM strategies WHERE strategies.id = sync_tombstones.entity_id AND (strategies.user_id = ? OR strategies.is_public))", userID).
+		Pluck("entity_id", &ids).Error
+	return ids, err
+}
+
+// GetBySymbol 获取用户股票列表中包含指定股票的策略，按优先级排序
+// 指定交易所时精确匹配 symbol.exchange（可使用 symbols 列的 GIN 索引），否则按代码匹配任意交易所。
+func (r *strategyRepository) GetBySymbol(ctx context.Context, userID uint, symbol, exchange, strategyType string, tags []string, page, pageSize int) ([]*models.Strategy, int64, error) {
+	var strategies []*models.Strategy
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.Strategy{}).Where("user_id = ?", userID)
+	if exchange != "" {
+		query = query.Where("symbols @> ARRAY[?]::text[]", symbol+"."+exchange)
+	} else {
+		query = query.Where("EXISTS (SELECT 1 FROM unnest(symbols) AS s WHERE split_part(s, '.', 1) = ?)", symbol)
+	}
+	if strategyType != "" {
+		query = query.Where("type = ?", strategyType)
+	}
+	if len(tags) > 0 {
+		query = query.Where("id IN (?)", taggedWith(r.db, strategyTagsTable, "strategy_id", userID, tags))
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&strategies).Error; err != nil {
+	if err := query.Preload("Tags").Order("priority DESC, id").Offset((page - 1) * pageSize).Limit(pageSize).Find(&strategies).Error; err != nil {
 		return nil, 0, err
 	}
 
 	return strategies, total, nil
 }
 
+// GetRecentSignalsBySymbol 获取策略在 since 之后对指定股票产生的信号，按时间倒序
+func (r *strategyRepository) GetRecentSignalsBySymbol(ctx context.Context, strategyIDs []uint, symbol, exchange string, since time.Time) ([]*models.TradeSignal, error) {
+	var signals []*models.TradeSignal
+	if len(strategyIDs) == 0 {
+		return signals, nil
+	}
+
+	query := r.db.WithContext(ctx).Where("strategy_id IN ? AND symbol = ? AND created_at >= ?", strategyIDs, symbol, since)
+	if exchange != "" {
+		query = query.Where("exchange = ?", exchange)
+	}
+	err := query.Order("created_at DESC").Find(&signals).Error
+	return signals, err
+}
+
 // GetSignalsByStrategyID 获取策略的交易信号
 func (r *strategyRepository) GetSignalsByStrategyID(ctx context.Context, strategyID uint, page, pageSize int) ([]*models.TradeSignal, int64, error) {
 	var signals []*models.TradeSignal
@@ -86,7 +178,7 @@ func (r *strategyRepository) GetSignalsByStrategyID(ctx context.Context, strateg
 		return nil, 0, err
 	}
 
-	if err := r.db.WithContext(ctx).Where("strategy_id = ?", strategyID).Offset((page - 1) * pageSize).Limit(pageSize).Find(&signals).Error; err != nil {
+	if err := r.db.WithContext(ctx).Where("strategy_id = ?", strategyID).Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&signals).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -94,7 +186,7 @@ func (r *strategyRepository) GetSignalsByStrategyID(ctx context.Context, strateg
 }
 
 // GetSignalsByUserID 获取用户的交易信号
-func (r *strategyRepository) GetSignalsByUserID(ctx context.Context, userID uint, symbol, signalType string, page, pageSize int) ([]*models.TradeSignal, int64, error) {
+func (r *strategyRepository) GetSignalsByUserID(ctx context.Context, userID uint, symbol, signalType, status string, page, pageSize int) ([]*models.TradeSignal, int64, error) {
 	var signals []*models.TradeSignal
 	var total int64
 
@@ -112,12 +204,15 @@ func (r *strategyRepository) GetSignalsByUserID(ctx context.Context, userID uint
 	if signalType != "" {
 		query = query.Where("signal_type = ?", signalType)
 	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
-	if err := query.Offset((page - 1) * pageSize).Limit(pageSize).Find(&signals).Error; err != nil {
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&signals).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -128,3 +223,154 @@ func (r *strategyRepository) GetSignalsByUserID(ctx context.Context, userID uint
 func (r *strategyRepository) CreateSignal(ctx context.Context, signal *models.TradeSignal) error {
 	return r.db.WithContext(ctx).Create(signal).Error
 }
+
+// GetRecentSignalsByUserID 获取用户所有策略在 since 之后生成的有效信号，按生成时间排序
+func (r *strategyRepository) GetRecentSignalsByUserID(ctx context.Context, userID uint, since time.Time) ([]*models.TradeSignal, error) {
+	var signals []*models.TradeSignal
+	err := r.db.WithContext(ctx).
+		Where("strategy_id IN (?)", r.db.Model(&models.Strategy{}).Select("id").Where("user_id = ?", userID)).
+		Where("status = ? AND created_at >= ?", models.SignalStatusActive, since).
+		Order("created_at").
+		Find(&signals).Error
+	return signals, err
+}
+
+// CancelSignal 撤销交易信号并记录原因
+func (r *strategyRepository) CancelSignal(ctx context.Context, id uint, note string) error {
+	return r.db.WithContext(ctx).Model(&models.TradeSignal{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"status": models.SignalStatusCancelled, "note": note}).Error
+}
+
+// GetPriorities 获取用户各策略的优先级
+func (r *strategyRepository) GetPriorities(ctx context.Context, userID uint) (map[uint]int, error) {
+	var strategies []*models.Strategy
+	if err := r.db.WithContext(ctx).Select("id", "priority").Where("user_id = ?", userID).Find(&strategies).Error; err != nil {
+		return nil, err
+	}
+	priorities := make(map[uint]int, len(strategies))
+	for _, s := range strategies {
+		priorities[s.ID] = s.Priority
+	}
+	return priorities, nil
+}
+
+// GetSignalPolicy 获取用户的信号聚合规则，未设置时返回默认规则
+func (r *strategyRepository) GetSignalPolicy(ctx context.Context, userID uint) (*models.SignalPolicy, error) {
+	var policy models.SignalPolicy
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&policy).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultSignalPolicy(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// SaveSignalPolicy 保存用户的信号聚合规则（按 user_id 覆盖）
+func (r *strategyRepository) SaveSignalPolicy(ctx context.Context, policy *models.SignalPolicy) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"conflict_policy", "dedup_hours", "updated_at"}),
+	}).Create(policy).Error
+}
+
+// GetRegressionStrategies 获取开启定期回归回测的策略
+func (r *strategyRepository) GetRegressionStrategies(ctx context.Context) ([]*models.Strategy, error) {
+	var strategies []*models.Strategy
+	err := r.db.WithContext(ctx).Where("regression_enabled = true").Order("id").Find(&strategies).Error
+	return strategies, err
+}
+
+// SavePerformance 保存回归回测结果（同一策略同一运行日重复执行时覆盖）
+// 已保存的结果带有比 perf.Fence 更新的隔离令牌时不覆盖并返回 lock.ErrFenced：已失去主节点身份的实例不能覆盖新主节点的结果。
+func (r *strategyRepository) SavePerformance(ctx context.Context, perf *models.StrategyPerformance) error {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "strategy_id"}, {Name: "run_date"}},
+		Where:   clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "strategy_performance.fence <= excluded.fence"}}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"start_date", "end_date", "total_return", "annual_return", "max_drawdown",
+			"sharpe_ratio", "win_rate", "trade_count", "fence",
+		}),
+	}).Create(perf)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return lock.ErrFenced
+	}
+	return nil
+}
+
+// GetPerformanceHistory 获取策略在运行日区间内的回归回测结果，按运行日升序
+func (r *strategyRepository) GetPerformanceHistory(ctx context.Context, strategyID uint, start, end time.Time) ([]*models.StrategyPerformance, error) {
+	var history []*models.StrategyPerformance
+	err := r.db.WithContext(ctx).
+		Where("strategy_id = ? AND run_date BETWEEN ? AND ?", strategyID, start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("run_date").
+		Find(&history).Error
+	return history, err
+}
+
+// GetLatestPerformance 获取策略最近一次回归回测结果，没有时返回 nil
+func (r *strategyRepository) GetLatestPerformance(ctx context.Context, strategyID uint) (*models.StrategyPerformance, error) {
+	var perf models.StrategyPerformance
+	err := r.db.WithContext(ctx).Where("strategy_id = ?", strategyID).Order("run_date DESC").First(&perf).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &perf, nil
+}
+
+// GetDivergenceStrategies 获取开启实盘偏离检查的启用中策略
+func (r *strategyRepository) GetDivergenceStrategies(ctx context.Context) ([]*models.Strategy, error) {
+	var strategies []*models.Strategy
+	err := r.db.WithContext(ctx).Where("divergence_action <> '' AND is_active = true").Order("id").Find(&strategies).Error
+	return strategies, err
+}
+
+// GetSignalsSince 获取策略自指定时间以来的交易信号，按生成时间升序
+func (r *strategyRepository) GetSignalsSince(ctx context.Context, strategyID uint, since time.Time) ([]*models.TradeSignal, error) {
+	var signals []*models.TradeSignal
+	err := r.db.WithContext(ctx).
+		Where("strategy_id = ? AND created_at >= ?", strategyID, since).
+		Order("created_at").
+		Find(&signals).Error
+	return signals, err
+}
+
+// CreateDivergence 保存偏离记录
+func (r *strategyRepository) CreateDivergence(ctx context.Context, d *models.StrategyDivergence) error {
+	return r.db.WithContext(ctx).Create(d).Error
+}
+
+// GetLatestDivergence 获取策略最近一次偏离记录，没有时返回 nil
+func (r *strategyRepository) GetLatestDivergence(ctx context.Context, strategyID uint) (*models.StrategyDivergence, error) {
+	var d models.StrategyDivergence
+	err := r.db.WithContext(ctx).Where("strategy_id = ?", strategyID).Order("created_at DESC").First(&d).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// GetDivergences 分页获取策略的偏离记录，按时间倒序
+func (r *strategyRepository) GetDivergences(ctx context.Context, strategyID uint, page, pageSize int) ([]*models.StrategyDivergence, int64, error) {
+	var list []*models.StrategyDivergence
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.StrategyDivergence{}).Where("strategy_id = ?", strategyID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("created_at DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&list).Error; err != nil {
+		return nil, 0, err
+	}
+	return list, total, nil
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestSavePerformanceFence(t *testing.T) {
+	repo := NewStrategyRepository(newTestDB(t, &models.StrategyPerformance{}))
+	ctx := context.Background()
+	runDate := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	save := func(fence int64, totalReturn float64) error {
+		return repo.SavePerformance(ctx, &models.StrategyPerformance{
+			StrategyID: 1, RunDate: runDate, StartDate: runDate.AddDate(0, 0, -90), EndDate: runDate.AddDate(0, 0, -1),
+			TotalReturn: totalReturn, Fence: fence,
+		})
+	}
+	latest := func() float64 {
+		t.Helper()
+		perf, err := repo.GetLatestPerformance(ctx, 1)
+		if err != nil || perf == nil {
+			t.Fatalf("读取回归结果失败: %v", err)
+		}
+		return perf.TotalReturn
+	}
+
+	if err := save(2, 0.1); err != nil {
+		t.Fatal(err)
+	}
+	// 旧主节点的迟到写入不能覆盖新主节点的结果
+	if err := save(1, 0.5); !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("旧令牌覆盖应返回 ErrFenced，实际 %v", err)
+	}
+	if r := latest(); r != 0.1 {
+		t.Fatalf("旧令牌不应覆盖结果，total_return = %v", r)
+	}
+	// 同一任期重复执行与更新的令牌可以覆盖
+	for _, c := range []struct {
+		fence int64
+		ret   float64
+	}{{2, 0.2}, {3, 0.3}} {
+		if err := save(c.fence, c.ret); err != nil {
+			t.Fatalf("令牌 %d 覆盖失败: %v", c.fence, err)
+		}
+		if r := latest(); r != c.ret {
+			t.Fatalf("令牌 %d 覆盖后 total_return = %v，期望 %v", c.fence, r, c.ret)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// SyncJobRepository 数据同步任务记录仓库接口
+type SyncJobRepository interface {
+	Start(ctx context.Context, job *models.SyncJob) error
+	Enqueue(ctx context.Context, job *models.SyncJob) error
+	UpdateQueued(ctx context.Context, job *models.SyncJob) error
+	StartQueued(ctx context.Context, job *models.SyncJob) error
+	ListQueued(ctx context.Context) ([]*models.SyncJob, error)
+	CheckFence(ctx context.Context, fence int64) error
+	Finish(ctx context.Context, job *models.SyncJob, records int, jobErr error) error
+	FinishWithReport(ctx context.Context, job *models.SyncJob, report *models.SyncReport, jobErr error) error
+	GetLatestSuccess(ctx context.Context, jobType, symbol, exchange string) (*models.SyncJob, error)
+	GetLatestFinishedAt(ctx context.Context, jobTypes ...string) (*time.Time, error)
+	GetLatestID(ctx context.Context, jobType, symbol, exchange string) (uint, error)
+	GetByID(ctx context.Context, id uint) (*models.SyncJob, error)
+	GetDependents(ctx context.Context, parentID uint) ([]*models.SyncJob, error)
+	List(ctx context.Context, filter SyncJobFilter, page, pageSize int) ([]*models.SyncJob, int64, error)
+}
+
+// SyncJobFilter 同步任务查询条件，空字段不过滤
+type SyncJobFilter struct {
+	JobType string
+	Status  string
+	Symbol  string
+}
+
+// syncJobRepository 数据同步任务记录仓库实现
+type syncJobRepository struct {
+	db *gorm.DB
+}
+
+// NewSyncJobRepository 创建数据同步任务记录仓库
+func NewSyncJobRepository(db *gorm.DB) SyncJobRepository {
+	return &syncJobRepository{db: db}
+}
+
+// noNewerFence 没有任务记录带有比参数更新的隔离令牌
+// 带令牌的任务都由数据同步服务的定时任务主节点（同一把锁、同一个令牌计数器）发起，令牌在全表范围内可比较。
+const noNewerFence = "NOT EXISTS (SELECT 1 FROM data_sync_jobs newer WHERE newer.fence > ?)"
+
+// Start 记录任务开始
+// job.Fence 不为 0 时，已有更新的令牌写入过任务记录（主节点已切换）则不记录并返回 lock.ErrFenced；
+// 检查与插入在同一条 INSERT ... SELECT ... WHERE NOT EXISTS 语句中完成，两者之间没有其他主节点插入的窗口。
+func (r *syncJobRepository) Start(ctx context.Context, job *models.SyncJob) error {
+	job.Status = models.SyncStatusRunning
+	job.StartedAt = time.Now()
+	if job.Fence == 0 {
+		return r.db.WithContext(ctx).Create(job).Error
+	}
+	var ids []uint
+	if err := r.db.WithContext(ctx).Raw(`INSERT INTO data_sync_jobs (job_type, source, symbol, exchange, status, records, error, parent_id, fence, started_at)
+		SELECT ?, ?, ?, ?, ?, 0, '', ?, ?, ? WHERE `+noNewerFence+` RETURNING id`,
+		job.JobType, job.Source, job.Symbol, job.Exchange, job.Status, job.ParentID, job.Fence, job.StartedAt, job.Fence).
+		Scan(&ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return lock.ErrFenced
+	}
+	job.ID = ids[0]
+	return nil
+}
+
+// Enqueue 记录排队等待执行的下游任务，started_at 为登记时间，开始执行时由 StartQueued 更新
+func (r *syncJobRepository) Enqueue(ctx context.Context, job *models.SyncJob) error {
+	job.Status = models.SyncStatusQueued
+	job.StartedAt = time.Now()
+	return r.db.WithContext(ctx).Create(job).Error
+}
+
+// UpdateQueued 更新排队中的下游任务合并后的区间与上游任务，记录已开始执行时返回 gorm.ErrRecordNotFound
+func (r *syncJobRepository) UpdateQueued(ctx context.Context, job *models.SyncJob) error {
+	result := r.db.WithContext(ctx).Model(&models.SyncJob{}).
+		Where("id = ? AND status = ?", job.ID, models.SyncStatusQueued).
+		Updates(map[string]interface{}{"parent_id": job.ParentID, "range_start": job.RangeStart, "range_end": job.RangeEnd})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// StartQueued 排队中的下游任务开始执行，记录已被其他实例开始执行时返回 gorm.ErrRecordNotFound
+// 以 status = queued 为条件更新，多个实例启动时重新排队同一批记录，每条只有一个实例执行。
+func (r *syncJobRepository) StartQueued(ctx context.Context, job *models.SyncJob) error {
+	job.Status = models.SyncStatusRunning
+	job.StartedAt = time.Now()
+	result := r.db.WithContext(ctx).Model(&models.SyncJob{}).
+		Where("id = ? AND status = ?", job.ID, models.SyncStatusQueued).
+		Updates(map[string]interface{}{"status": job.Status, "started_at": job.StartedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ListQueued 排队中的下游任务，按登记顺序，服务启动时重新排队
+func (r *syncJobRepository) ListQueued(ctx context.Context) ([]*models.SyncJob, error) {
+	var jobs []*models.SyncJob
+	// 刚登记的任务可能尚未复制到只读副本
+	if err := database.UsePrimary(r.db.WithContext(ctx)).
+		Where("status = ?", models.SyncStatusQueued).
+		Order("id").
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// CheckFence 已有更新的令牌写入过任务记录（主节点已切换）时返回 lock.ErrFenced，fence 为 0 时不检查
+func (r *syncJobRepository) CheckFence(ctx context.Context, fence int64) error {
+	if fence == 0 {
+		return nil
+	}
+	var newer int64
+	// 新主节点的记录可能尚未复制到只读副本
+	if err := database.UsePrimary(r.db.WithContext(ctx)).Model(&models.SyncJob{}).Where("fence > ?", fence).Count(&newer).Error; err != nil {
+		return err
+	}
+	if newer > 0 {
+		return lock.ErrFenced
+	}
+	return nil
+}
+
+// save 保存任务的全部字段；带隔离令牌的任务只在没有更新的令牌写入过时保存，否则返回 lock.ErrFenced
+func (r *syncJobRepository) save(ctx context.Context, job *models.SyncJob) error {
+	if job.Fence == 0 {
+		return r.db.WithContext(ctx).Save(job).Error
+	}
+	result := r.db.WithContext(ctx).Model(job).Where(noNewerFence, job.Fence).Select("*").Updates(job)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return lock.ErrFenced
+	}
+	return nil
+}
+
+// Finish 记录任务结束
+func (r *syncJobRepository) Finish(ctx context.Context, job *models.SyncJob, records int, jobErr error) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	job.Records = records
+	job.Status = models.SyncStatusSuccess
+	if jobErr != nil {
+		job.Status = models.SyncStatusFailed
+		job.Error = jobErr.Error()
+	}
+	return r.save(ctx, job)
+}
+
+// FinishWithReport 记录逐只股票处理的任务结束，状态按结果明细判定
+// jobErr 不为空（如任务被取消）时，已有股票成功则记为 partial，否则记为 failed。
+func (r *syncJobRepository) FinishWithReport(ctx context.Context, job *models.SyncJob, report *models.SyncReport, jobErr error) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	report.DurationMs = now.Sub(job.StartedAt).Milliseconds()
+	job.Report = report
+	job.Records = report.Records
+	job.Status = report.Status()
+	if jobErr != nil {
+		job.Error = jobErr.Error()
+		if len(report.Succeeded) == 0 {
+			job.Status = models.SyncStatusFailed
+		} else {
+			job.Status = models.SyncStatusPartial
+		}
+	}
+	return r.save(ctx, job)
+}
+
+// GetLatestID 股票最近一次该类任务（不论状态）的ID，没有时返回 0
+// 任务ID单调递增，回测行情缓存以最近一次 restatement 任务的ID作为K线的数据版本。
+func (r *syncJobRepository) GetLatestID(ctx context.Context, jobType, symbol, exchange string) (uint, error) {
+	var id uint
+	err := r.db.WithContext(ctx).Model(&models.SyncJob{}).
+		Select("COALESCE(MAX(id), 0)").
+		Where("job_type = ? AND symbol = ? AND exchange = ?", jobType, symbol, exchange).
+		Scan(&id).Error
+	return id, err
+}
+
+// GetLatestSuccess 获取最近一次成功的同步任务（个股任务或全市场任务），不存在时返回 nil
+func (r *syncJobRepository) GetLatestSuccess(ctx context.Context, jobType, symbol, exchange string) (*models.SyncJob, error) {
+	var job models.SyncJob
+	err := r.db.WithContext(ctx).
+		Where("job_type = ? AND status = ?", jobType, models.SyncStatusSuccess).
+		Where("(symbol = ? AND exchange = ?) OR symbol = ''", symbol, exchange).
+		Order("finished_at DESC").
+		First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetLatestFinishedAt 获取指定类型的任务（含个股任务）最近一次成功完成的时间，没有记录时返回 nil
+func (r *syncJobRepository) GetLatestFinishedAt(ctx context.Context, jobTypes ...string) (*time.Time, error) {
+	var finishedAt sql.NullTime
+	if err := r.db.WithContext(ctx).
+		Model(&models.SyncJob{}).
+		Where("job_type IN ? AND status = ?", jobTypes, models.SyncStatusSuccess).
+		Select("MAX(finished_at)").
+		Row().Scan(&finishedAt); err != nil {
+		return nil, err
+	}
+	if !finishedAt.Valid {
+		return nil, nil
+	}
+	return &finishedAt.Time, nil
+}
+
+// GetByID 根据ID获取同步任务
+func (r *syncJobRepository) GetByID(ctx context.Context, id uint) (*models.SyncJob, error) {
+	var job models.SyncJob
+	if err := r.db.WithContext(ctx).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// GetDependents 获取依赖于指定任务的下游任务，按开始时间排序
+func (r *syncJobRepository) GetDependents(ctx context.Context, parentID uint) ([]*models.SyncJob, error) {
+	var jobs []*models.SyncJob
+	if err := r.db.WithContext(ctx).
+		Where("parent_id = ?", parentID).
+		Order("started_at, id").
+		Find(&jobs).Error; err != nil {
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// List 按开始时间倒序分页查询同步任务
+func (r *syncJobRepository) List(ctx context.Context, filter SyncJobFilter, page, pageSize int) ([]*models.SyncJob, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.SyncJob{})
+	if filter.JobType != "" {
+		query = query.Where("job_type = ?", filter.JobType)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Symbol != "" {
+		query = query.Where("symbol = ?", filter.Symbol)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var jobs []*models.SyncJob
+	if err := query.Order("started_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
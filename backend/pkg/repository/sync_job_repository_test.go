@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestSyncJobFence(t *testing.T) {
+	repo := NewSyncJobRepository(newTestDB(t, &models.SyncJob{}))
+	ctx := context.Background()
+
+	stale := &models.SyncJob{JobType: models.SyncJobDailyBars, Source: "akshare", Fence: 5}
+	if err := repo.Start(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+	// 主节点切换，新主节点以更新的令牌开始任务
+	current := &models.SyncJob{JobType: models.SyncJobIndicators, Source: "akshare", Fence: 6}
+	if err := repo.Start(ctx, current); err != nil {
+		t.Fatal(err)
+	}
+
+	if stale.ID == 0 || current.ID == stale.ID {
+		t.Fatalf("带令牌的任务应返回新记录的ID: %d, %d", stale.ID, current.ID)
+	}
+	if got, err := repo.GetByID(ctx, current.ID); err != nil || got.Fence != 6 || got.Status != models.SyncStatusRunning || got.Source != "akshare" {
+		t.Fatalf("带令牌插入的记录 %+v, err = %v", got, err)
+	}
+	if err := repo.CheckFence(ctx, 5); !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("旧令牌 CheckFence 应返回 ErrFenced，实际 %v", err)
+	}
+	if err := repo.CheckFence(ctx, 6); err != nil {
+		t.Fatalf("当前令牌 CheckFence: %v", err)
+	}
+	if err := repo.CheckFence(ctx, 0); err != nil {
+		t.Fatalf("不带令牌时不检查: %v", err)
+	}
+
+	// 旧主节点的迟到写入被拒绝，记录保持运行中
+	if err := repo.Finish(ctx, stale, 10, nil); !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("旧令牌结束任务应返回 ErrFenced，实际 %v", err)
+	}
+	if got, err := repo.GetByID(ctx, stale.ID); err != nil || got.Status != models.SyncStatusRunning || got.Records != 0 {
+		t.Fatalf("旧令牌的写入不应生效: %+v, err = %v", got, err)
+	}
+	if err := repo.Start(ctx, &models.SyncJob{JobType: models.SyncJobDailyBars, Source: "akshare", Fence: 4}); !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("旧令牌开始任务应返回 ErrFenced，实际 %v", err)
+	}
+
+	// 当前令牌与手动触发（令牌为 0）的任务正常写入
+	if err := repo.FinishWithReport(ctx, current, &models.SyncReport{Total: 1, Succeeded: []string{"600519.SH"}, Records: 3}, nil); err != nil {
+		t.Fatalf("当前令牌结束任务失败: %v", err)
+	}
+	if got, err := repo.GetByID(ctx, current.ID); err != nil || got.Status != models.SyncStatusSuccess || got.Records != 3 {
+		t.Fatalf("当前令牌的写入应生效: %+v, err = %v", got, err)
+	}
+	manual := &models.SyncJob{JobType: models.SyncJobDailyBars, Source: "akshare"}
+	if err := repo.Start(ctx, manual); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Finish(ctx, manual, 1, nil); err != nil {
+		t.Fatalf("不带令牌的任务不参与隔离检查: %v", err)
+	}
+}
+
+// 排队的下游任务：合并时更新区间，开始执行后不再更新，每条记录只能开始一次
+func TestSyncJobQueued(t *testing.T) {
+	repo := NewSyncJobRepository(newTestDB(t, &models.SyncJob{}))
+	ctx := context.Background()
+	day := func(d int) *time.Time {
+		v := time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC)
+		return &v
+	}
+
+	upstream := &models.SyncJob{JobType: models.SyncJobDailyBars, Source: "akshare"}
+	if err := repo.Start(ctx, upstream); err != nil {
+		t.Fatal(err)
+	}
+	first := &models.SyncJob{JobType: models.SyncJobIndicators, Source: "akshare", Symbol: "600519", Exchange: "SH", ParentID: &upstream.ID, RangeStart: day(10), RangeEnd: day(12)}
+	second := &models.SyncJob{JobType: models.SyncJobFactors, Source: "akshare", RangeStart: day(11), RangeEnd: day(11)}
+	for _, job := range []*models.SyncJob{first, second} {
+		if err := repo.Enqueue(ctx, job); err != nil {
+			t.Fatal(err)
+		}
+	}
+	first.RangeStart = day(5)
+	if err := repo.UpdateQueued(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	queued, err := repo.ListQueued(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queued) != 2 || queued[0].ID != first.ID || queued[1].ID != second.ID {
+		t.Fatalf("排队中的任务 %+v", queued)
+	}
+	if got := queued[0]; got.Status != models.SyncStatusQueued || !got.RangeStart.Equal(*day(5)) || !got.RangeEnd.Equal(*day(12)) || *got.ParentID != upstream.ID {
+		t.Errorf("合并后的排队记录 %+v", got)
+	}
+	if dependents, err := repo.GetDependents(ctx, upstream.ID); err != nil || len(dependents) != 1 || dependents[0].Status != models.SyncStatusQueued {
+		t.Errorf("排队中的下游任务应出现在上游任务详情中: %+v, err = %v", dependents, err)
+	}
+
+	if err := repo.StartQueued(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.StartQueued(ctx, first); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("重复开始应返回 ErrRecordNotFound，实际 %v", err)
+	}
+	if err := repo.UpdateQueued(ctx, first); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("开始执行后更新区间应返回 ErrRecordNotFound，实际 %v", err)
+	}
+	if err := repo.Finish(ctx, first, 5, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(ctx, first.ID)
+	if err != nil || got.Status != models.SyncStatusSuccess || got.Records != 5 || got.RangeStart == nil || !got.RangeStart.Equal(*day(5)) {
+		t.Errorf("执行完成的记录 %+v, err = %v", got, err)
+	}
+	if queued, err := repo.ListQueued(ctx); err != nil || len(queued) != 1 || queued[0].ID != second.ID {
+		t.Errorf("仍在排队的任务 %+v, err = %v", queued, err)
+	}
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 标签关联表
+const (
+	strategyTagsTable  = "strategy_tags"
+	watchlistTagsTable = "watchlist_tags"
+)
+
+// TagRepository 标签仓库接口
+type TagRepository interface {
+	GetByUserID(ctx context.Context, userID uint) ([]*models.TagUsage, error)
+	GetByID(ctx context.Context, id uint) (*models.Tag, error)
+	GetByName(ctx context.Context, userID uint, name string) (*models.Tag, error)
+	Create(ctx context.Context, tag *models.Tag) error
+	Update(ctx context.Context, tag *models.Tag) error
+	Delete(ctx context.Context, id uint) error
+
+	// EnsureByNames 按名称获取用户的标签，不存在的自动创建
+	EnsureByNames(ctx context.Context, userID uint, names []string) ([]*models.Tag, error)
+	// SetStrategyTags 替换策略的全部标签
+	SetStrategyTags(ctx context.Context, strategyID uint, tags []*models.Tag) error
+	// SetWatchlistTags 替换自选股分组的全部标签
+	SetWatchlistTags(ctx context.Context, watchlistID uint, tags []*models.Tag) error
+}
+
+// tagRepository 标签仓库实现
+type tagRepository struct {
+	db *gorm.DB
+}
+
+// NewTagRepository 创建标签仓库
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// GetByUserID 获取用户的全部标签及使用次数
+func (r *tagRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.TagUsage, error) {
+	var tags []*models.TagUsage
+	if err := r.db.WithContext(ctx).
+		Model(&models.Tag{}).
+		Select("tags.*, "+
+			"(SELECT COUNT(*) FROM strategy_tags st WHERE st.tag_id = tags.id) AS strategy_count, "+
+			"(SELECT COUNT(*) FROM watchlist_tags wt WHERE wt.tag_id = tags.id) AS watchlist_count").
+		Where("user_id = ?", userID).
+		Order("name").
+		Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetByID 根据ID获取标签
+func (r *tagRepository) GetByID(ctx context.Context, id uint) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.WithContext(ctx).First(&tag, id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// GetByName 根据名称获取用户的标签
+func (r *tagRepository) GetByName(ctx context.Context, userID uint, name string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND name = ?", userID, name).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Create 创建标签
+func (r *tagRepository) Create(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Create(tag).Error
+}
+
+// Update 更新标签
+func (r *tagRepository) Update(ctx context.Context, tag *models.Tag) error {
+	return r.db.WithContext(ctx).Save(tag).Error
+}
+
+// Delete 删除标签及其全部关联
+func (r *tagRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, table := range []string{strategyTagsTable, watchlistTagsTable} {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE tag_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Tag{}, id).Error
+	})
+}
+
+// EnsureByNames 按名称获取用户的标签，不存在的自动创建
+func (r *tagRepository) EnsureByNames(ctx context.Context, userID uint, names []string) ([]*models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	var tags []*models.Tag
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing []*models.Tag
+		if err := tx.Where("user_id = ? AND name IN ?", userID, names).Find(&existing).Error; err != nil {
+			return err
+		}
+		byName := make(map[string]*models.Tag, len(existing))
+		for _, t := range existing {
+			byName[t.Name] = t
+		}
+
+		for _, name := range names {
+			tag, ok := byName[name]
+			if !ok {
+				tag = &models.Tag{UserID: userID, Name: name}
+				if err := tx.Create(tag).Error; err != nil {
+					return err
+				}
+				byName[name] = tag
+			}
+			tags = append(tags, tag)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// SetStrategyTags 替换策略的全部标签
+func (r *tagRepository) SetStrategyTags(ctx context.Context, strategyID uint, tags []*models.Tag) error {
+	return r.setTags(ctx, strategyTagsTable, "strategy_id", strategyID, tags)
+}
+
+// SetWatchlistTags 替换自选股分组的全部标签
+func (r *tagRepository) SetWatchlistTags(ctx context.Context, watchlistID uint, tags []*models.Tag) error {
+	return r.setTags(ctx, watchlistTagsTable, "watchlist_id", watchlistID, tags)
+}
+
+// setTags 在关联表中替换某个对象的全部标签
+func (r *tagRepository) setTags(ctx context.Context, table, column string, id uint, tags []*models.Tag) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM "+table+" WHERE "+column+" = ?", id).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+		rows := make([]map[string]interface{}, len(tags))
+		for i, t := range tags {
+			rows[i] = map[string]interface{}{column: id, "tag_id": t.ID}
+		}
+		return tx.Table(table).Create(rows).Error
+	})
+}
+
+// taggedWith 返回带有全部指定标签（按用户的标签名匹配）的对象ID子查询
+func taggedWith(db *gorm.DB, table, column string, userID uint, names []string) *gorm.DB {
+	return db.Table(table+" AS jt").
+		Select("jt."+column).
+		Joins("JOIN tags t ON t.id = jt.tag_id").
+		Where("t.user_id = ? AND t.name IN ?", userID, names).
+		Group("jt."+column).
+		Having("COUNT(DISTINCT t.name) = ?", len(names))
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// TaskRepository 异步任务仓库接口，实现 jobs.Store
+type TaskRepository interface {
+	CreateTask(ctx context.Context, task *models.Task) error
+	UpdateTask(ctx context.Context, task *models.Task) error
+	GetByID(ctx context.Context, id string) (*models.Task, error)
+	List(ctx context.Context, filter TaskFilter, page, pageSize int) ([]*models.Task, int64, error)
+}
+
+// TaskFilter 异步任务查询条件，空字段不过滤
+type TaskFilter struct {
+	OwnerID *uint
+	Type    string
+	Status  string
+}
+
+// taskRepository 异步任务仓库实现
+type taskRepository struct {
+	db *gorm.DB
+}
+
+// NewTaskRepository 创建异步任务仓库
+func NewTaskRepository(db *gorm.DB) TaskRepository {
+	return &taskRepository{db: db}
+}
+
+// CreateTask 登记任务
+func (r *taskRepository) CreateTask(ctx context.Context, task *models.Task) error {
+	return r.db.WithContext(ctx).Create(task).Error
+}
+
+// UpdateTask 更新任务状态、进度与结果
+func (r *taskRepository) UpdateTask(ctx context.Context, task *models.Task) error {
+	return r.db.WithContext(ctx).Model(task).Select(
+		"status", "progress", "message", "result_type", "result_id", "error", "updated_at", "finished_at",
+	).Updates(task).Error
+}
+
+// GetByID 根据ID获取任务
+func (r *taskRepository) GetByID(ctx context.Context, id string) (*models.Task, error) {
+	var task models.Task
+	if err := r.db.WithContext(ctx).Where("id = ?", id).First(&task).Error; err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// List 按创建时间倒序分页查询任务
+func (r *taskRepository) List(ctx context.Context, filter TaskFilter, page, pageSize int) ([]*models.Task, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Task{})
+	if filter.OwnerID != nil {
+		query = query.Where("owner_id = ?", *filter.OwnerID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var tasks []*models.Task
+	if err := query.Order("created_at DESC, id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&tasks).Error; err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// UniverseRepository 股票池仓库接口
+type UniverseRepository interface {
+	Create(ctx context.Context, universe *models.Universe) error
+	Update(ctx context.Context, universe *models.Universe) error
+	Delete(ctx context.Context, id uint) error
+	GetByID(ctx context.Context, id uint) (*models.Universe, error)
+	GetByUserID(ctx context.Context, userID uint) ([]*models.Universe, error)
+	GetActive(ctx context.Context) ([]*models.Universe, error)
+
+	// 成分快照相关
+	SaveSnapshot(ctx context.Context, universeID uint, tradeDate time.Time, members []*models.UniverseMember) error
+	GetLatestSnapshotDate(ctx context.Context, universeID uint, onOrBefore time.Time) (*time.Time, error)
+	GetMembers(ctx context.Context, universeID uint, tradeDate time.Time) ([]*models.UniverseMember, error)
+	GetMembersBetween(ctx context.Context, universeID uint, start, end time.Time) ([]*models.UniverseMember, error)
+}
+
+// universeRepository 股票池仓库实现
+type universeRepository struct {
+	db *gorm.DB
+}
+
+// NewUniverseRepository 创建股票池仓库
+func NewUniverseRepository(db *gorm.DB) UniverseRepository {
+	return &universeRepository{db: db}
+}
+
+// Create 创建股票池
+func (r *universeRepository) Create(ctx context.Context, universe *models.Universe) error {
+	return r.db.WithContext(ctx).Create(universe).Error
+}
+
+// Update 更新股票池
+func (r *universeRepository) Update(ctx context.Context, universe *models.Universe) error {
+	return r.db.WithContext(ctx).Save(universe).Error
+}
+
+// Delete 删除股票池及其全部成分快照，并解除策略引用
+func (r *universeRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("universe_id = ?", id).Delete(&models.UniverseMember{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Strategy{}).Where("universe_id = ?", id).
+			Update("universe_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Universe{}, id).Error
+	})
+}
+
+// GetByID 根据ID获取股票池
+func (r *universeRepository) GetByID(ctx context.Context, id uint) (*models.Universe, error) {
+	var universe models.Universe
+	if err := r.db.WithContext(ctx).First(&universe, id).Error; err != nil {
+		return nil, err
+	}
+	return &universe, nil
+}
+
+// GetByUserID 获取用户的全部股票池
+func (r *universeRepository) GetByUserID(ctx context.Context, userID uint) ([]*models.Universe, error) {
+	var universes []*models.Universe
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&universes).Error; err != nil {
+		return nil, err
+	}
+	return universes, nil
+}
+
+// GetActive 获取参与每日快照的股票池
+func (r *universeRepository) GetActive(ctx context.Context) ([]*models.Universe, error) {
+	var universes []*models.Universe
+	if err := r.db.WithContext(ctx).
+		Where("is_active = ?", true).
+		Order("id ASC").
+		Find(&universes).Error; err != nil {
+		return nil, err
+	}
+	return universes, nil
+}
+
+// SaveSnapshot 保存股票池某交易日的成分（覆盖该日已有快照）
+func (r *universeRepository) SaveSnapshot(ctx context.Context, universeID uint, tradeDate time.Time, members []*models.UniverseMember) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("universe_id = ? AND trade_date = ?", universeID, tradeDate.Format("2006-01-02")).
+			Delete(&models.UniverseMember{}).Error; err != nil {
+			return err
+		}
+		if len(members) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(members, 500).Error
+	})
+}
+
+// GetLatestSnapshotDate 获取不晚于指定日期的最近一次快照日期，不存在时返回 nil
+func (r *universeRepository) GetLatestSnapshotDate(ctx context.Context, universeID uint, onOrBefore time.Time) (*time.Time, error) {
+	var date sql.NullTime
+	if err := r.db.WithContext(ctx).
+		Model(&models.UniverseMember{}).
+		Where("universe_id = ? AND trade_date <= ?", universeID, onOrBefore.Format("2006-01-02")).
+		Select("MAX(trade_date)").
+		Row().Scan(&date); err != nil {
+		return nil, err
+	}
+	if !date.Valid {
+		return nil, nil
+	}
+	return &date.Time, nil
+}
+
+// GetMembers 获取股票池某交易日的成分
+func (r *universeRepository) GetMembers(ctx context.Context, universeID uint, tradeDate time.Time) ([]*models.UniverseMember, error) {
+	var members []*models.UniverseMember
+	if err := r.db.WithContext(ctx).
+		Where("universe_id = ? AND trade_date = ?", universeID, tradeDate.Format("2006-01-02")).
+		Order("symbol ASC, exchange ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+// GetMembersBetween 获取股票池在时间范围内的全部成分快照，按交易日排序
+func (r *universeRepository) GetMembersBetween(ctx context.Context, universeID uint, start, end time.Time) ([]*models.UniverseMember, error) {
+	var members []*models.UniverseMember
+	if err := r.db.WithContext(ctx).
+		Where("universe_id = ?", universeID).
+		Where("trade_date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("trade_date ASC, symbol ASC").
+		Find(&members).Error; err != nil {
+		return nil, err
+	}
+	return members, nil
+}
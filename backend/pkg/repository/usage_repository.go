@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// UsageRepository 用户每日用量仓库，实现 metering.Store
+type UsageRepository interface {
+	AddDailyUsage(ctx context.Context, usage []models.UsageDaily) error
+	ListDaily(ctx context.Context, userID uint, start, end time.Time) ([]models.UsageDaily, error)
+	ListAllDaily(ctx context.Context, start, end time.Time) ([]models.UsageDaily, error)
+	Usernames(ctx context.Context, userIDs []uint) (map[uint]string, error)
+}
+
+// usageRepository 用户每日用量仓库实现
+type usageRepository struct {
+	db *gorm.DB
+}
+
+// NewUsageRepository 创建用户每日用量仓库
+func NewUsageRepository(db *gorm.DB) UsageRepository {
+	return &usageRepository{db: db}
+}
+
+// AddDailyUsage 累加每日用量：同一用户同一天的记录已存在时在原值上累加
+func (r *usageRepository) AddDailyUsage(ctx context.Context, usage []models.UsageDaily) error {
+	if len(usage) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "date"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"api_calls":        gorm.Expr("usage_daily.api_calls + excluded.api_calls"),
+				"bytes_downloaded": gorm.Expr("usage_daily.bytes_downloaded + excluded.bytes_downloaded"),
+				"backtest_seconds": gorm.Expr("usage_daily.backtest_seconds + excluded.backtest_seconds"),
+				"updated_at":       gorm.Expr("excluded.updated_at"),
+			}),
+		}).
+		CreateInBatches(usage, 100).Error
+}
+
+// ListDaily 用户在 [start, end] 内的每日用量（按日期升序）
+func (r *usageRepository) ListDaily(ctx context.Context, userID uint, start, end time.Time) ([]models.UsageDaily, error) {
+	var usage []models.UsageDaily
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND date BETWEEN ? AND ?", userID, start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("date").
+		Find(&usage).Error
+	return usage, err
+}
+
+// ListAllDaily 全部用户在 [start, end] 内的每日用量（按日期、用户升序）
+func (r *usageRepository) ListAllDaily(ctx context.Context, start, end time.Time) ([]models.UsageDaily, error) {
+	var usage []models.UsageDaily
+	err := r.db.WithContext(ctx).
+		Where("date BETWEEN ? AND ?", start.Format("2006-01-02"), end.Format("2006-01-02")).
+		Order("date, user_id").
+		Find(&usage).Error
+	return usage, err
+}
+
+// Usernames 按用户ID查询用户名（含已注销用户）
+func (r *usageRepository) Usernames(ctx context.Context, userIDs []uint) (map[uint]string, error) {
+	names := make(map[uint]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return names, nil
+	}
+	var users []models.User
+	if err := r.db.WithContext(ctx).Unscoped().Select("id", "username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		names[u.ID] = u.Username
+	}
+	return names, nil
+}
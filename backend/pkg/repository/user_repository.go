@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 	"stock-analysis-system/backend/pkg/models"
@@ -11,12 +12,14 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	Update(ctx context.Context, user *models.User) error
+	UpdateLastLogin(ctx context.Context, id uint, at time.Time) error
 	GetByID(ctx context.Context, id uint) (*models.User, error)
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetRole(ctx context.Context, id uint) (string, error)
 	
 	// 自选股相关
-	GetWatchlists(ctx context.Context, userID uint) ([]*models.Watchlist, error)
+	GetWatchlists(ctx context.Context, userID uint, tags []string, updatedSince *time.Time) ([]*models.Watchlist, error)
 	GetWatchlistByID(ctx context.Context, id uint) (*models.Watchlist, error)
 	CreateWatchlist(ctx context.Context, watchlist *models.Watchlist) error
 	AddToWatchlist(ctx context.Context, item *models.WatchlistItem) error
@@ -38,9 +41,14 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
-// Update 更新用户
+// Update 更新用户，user.Version 为读取时的版本号，已被其他请求修改时返回 ErrVersionConflict
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return updateVersioned(r.db.WithContext(ctx), user, &user.Version)
+}
+
+// UpdateLastLogin 更新最后登录时间，不修改版本号，避免登录使客户端持有的资料版本失效
+func (r *userRepository) UpdateLastLogin(ctx context.Context, id uint, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.User{}).Where("id = ?", id).UpdateColumn("last_login_at", at).Error
 }
 
 // GetByID 根据ID获取用户
@@ -52,6 +60,15 @@ func (r *userRepository) GetByID(ctx context.Context, id uint) (*models.User, er
 	return &user, nil
 }
 
+// GetRole 获取用户角色，供 middleware.RequireRole 校验
+func (r *userRepository) GetRole(ctx context.Context, id uint) (string, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Select("id", "role").First(&user, id).Error; err != nil {
+		return "", err
+	}
+	return user.Role, nil
+}
+
 // GetByUsername 根据用户名获取用户
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
 	var user models.User
@@ -70,13 +87,21 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return &user, nil
 }
 
-// GetWatchlists 获取用户的自选股分组
-func (r *userRepository) GetWatchlists(ctx context.Context, userID uint) ([]*models.Watchlist, error) {
+// GetWatchlists 获取用户的自选股分组，tags 不为空时只返回带有全部指定标签的分组；
+// updatedSince 不为空时只返回该时间之后分组信息、明细或标签有变化的分组
+func (r *userRepository) GetWatchlists(ctx context.Context, userID uint, tags []string, updatedSince *time.Time) ([]*models.Watchlist, error) {
 	var watchlists []*models.Watchlist
-	if err := r.db.WithContext(ctx).
+	query := r.db.WithContext(ctx).
 		Preload("Items").
-		Where("user_id = ?", userID).
-		Find(&watchlists).Error; err != nil {
+		Preload("Tags").
+		Where("user_id = ?", userID)
+	if len(tags) > 0 {
+		query = query.Where("id IN (?)", taggedWith(r.db, watchlistTagsTable, "watchlist_id", userID, tags))
+	}
+	if updatedSince != nil {
+		query = query.Where("updated_at > ?", *updatedSince)
+	}
+	if err := query.Find(&watchlists).Error; err != nil {
 		return nil, err
 	}
 	return watchlists, nil
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestGetWatchlistsFiltersByTags(t *testing.T) {
+	db := newTestDB(t, &models.Tag{}, &models.Watchlist{}, &models.WatchlistItem{})
+	users := NewUserRepository(db)
+	tags := NewTagRepository(db)
+	ctx := context.Background()
+
+	create := func(userID uint, name string, tagNames ...string) {
+		w := &models.Watchlist{UserID: userID, Name: name}
+		if err := users.CreateWatchlist(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+		ts, err := tags.EnsureByNames(ctx, userID, tagNames)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tags.SetWatchlistTags(ctx, w.ID, ts); err != nil {
+			t.Fatal(err)
+		}
+	}
+	create(1, "白酒", "消费", "长线")
+	create(1, "券商", "金融")
+	create(1, "银行", "金融", "长线")
+	create(2, "他人的分组", "长线") // 其他用户的同名标签不匹配
+
+	cases := []struct {
+		tags []string
+		want []string
+	}{
+		{nil, []string{"白酒", "券商", "银行"}},
+		{[]string{"长线"}, []string{"白酒", "银行"}},
+		{[]string{"金融", "长线"}, []string{"银行"}},
+		{[]string{"不存在"}, nil},
+	}
+	for _, c := range cases {
+		got, err := users.GetWatchlists(ctx, 1, c.tags, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, w := range got {
+			names = append(names, w.Name)
+		}
+		if len(names) != len(c.want) {
+			t.Errorf("tags=%v 返回 %v，期望 %v", c.tags, names, c.want)
+			continue
+		}
+		for i := range names {
+			if names[i] != c.want[i] {
+				t.Errorf("tags=%v 返回 %v，期望 %v", c.tags, names, c.want)
+				break
+			}
+		}
+	}
+
+	// 返回的分组带有标签
+	got, err := users.GetWatchlists(ctx, 1, []string{"消费"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || len(got[0].Tags) != 2 {
+		t.Fatalf("应返回带 2 个标签的分组: %+v", got)
+	}
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict 乐观锁冲突：记录在读取之后已被其他请求修改
+var ErrVersionConflict = errors.New("数据已被修改，请刷新后重试")
+
+// updateVersioned 以乐观锁保存 model 的全部字段（omit 中的字段除外）
+// 只有数据库中的 version 仍等于 *version 时才更新，成功后 *version 加一；否则不修改任何数据并返回 ErrVersionConflict。
+func updateVersioned(db *gorm.DB, model interface{}, version *int, omit ...string) error {
+	expected := *version
+	*version = expected + 1
+	result := db.Model(model).Where("version = ?", expected).Select("*").Omit(append(omit, "CreatedAt")...).Updates(model)
+	if result.Error == nil && result.RowsAffected == 0 {
+		result.Error = ErrVersionConflict
+	}
+	if result.Error != nil {
+		*version = expected
+		return result.Error
+	}
+	return nil
+}
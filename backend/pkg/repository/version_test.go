@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// versionedItem 带乐观锁版本号的测试模型
+type versionedItem struct {
+	ID        uint
+	Name      string
+	Note      string
+	Version   int `gorm:"not null;default:1"`
+	CreatedAt time.Time
+}
+
+func TestUpdateVersioned(t *testing.T) {
+	db := newTestDB(t, &versionedItem{})
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	item := &versionedItem{Name: "a", Note: "n", CreatedAt: created}
+	if err := db.Create(item).Error; err != nil {
+		t.Fatal(err)
+	}
+	stale := *item
+
+	// 版本一致时更新全部字段（omit 与 CreatedAt 除外）并加一
+	item.Name, item.Note, item.CreatedAt = "b", "changed", time.Now()
+	if err := updateVersioned(db, item, &item.Version, "Note"); err != nil {
+		t.Fatal(err)
+	}
+	if item.Version != 2 {
+		t.Errorf("更新后 version = %d，期望 2", item.Version)
+	}
+	var saved versionedItem
+	if err := db.First(&saved, item.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.Name != "b" || saved.Version != 2 {
+		t.Errorf("保存的记录 %+v，期望 name=b version=2", saved)
+	}
+	if saved.Note != "n" || !saved.CreatedAt.Equal(created) {
+		t.Errorf("omit 的字段与 CreatedAt 不应更新: %+v", saved)
+	}
+
+	// 读取之后已被修改：不修改数据，返回 ErrVersionConflict 且版本号恢复为读取时的值
+	stale.Name = "c"
+	if err := updateVersioned(db, &stale, &stale.Version); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("版本冲突应返回 ErrVersionConflict，实际 %v", err)
+	}
+	if stale.Version != 1 {
+		t.Errorf("冲突后 version = %d，应恢复为 1", stale.Version)
+	}
+	if err := db.First(&saved, item.ID).Error; err != nil || saved.Name != "b" || saved.Version != 2 {
+		t.Errorf("冲突时不应修改数据: %+v, err = %v", saved, err)
+	}
+
+	// 数据库出错时同样恢复版本号
+	if err := updateVersioned(db.Table("missing_table"), item, &item.Version); err == nil || errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("表不存在时应返回数据库错误，实际 %v", err)
+	}
+	if item.Version != 2 {
+		t.Errorf("出错后 version = %d，应恢复为 2", item.Version)
+	}
+}
+
+func TestUserRepositoryUpdateVersion(t *testing.T) {
+	repo := NewUserRepository(newTestDB(t, &models.User{}))
+	ctx := context.Background()
+	if err := repo.Create(ctx, &models.User{Username: "alice", Email: "alice@example.com", PasswordHash: "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 两个请求读取同一版本，先提交的成功，后提交的冲突
+	first, err := repo.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := repo.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Phone = "13800000000"
+	if err := repo.Update(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	second.AvatarURL = "https://example.com/a.png"
+	if err := repo.Update(ctx, second); !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("后提交的修改应冲突，实际 %v", err)
+	}
+
+	current, err := repo.GetByID(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Version != 2 || current.Phone != "13800000000" || current.AvatarURL != "" {
+		t.Fatalf("当前记录 %+v，期望只有第一次修改生效且 version=2", current)
+	}
+	// 以当前版本重试成功
+	current.AvatarURL = second.AvatarURL
+	if err := repo.Update(ctx, current); err != nil || current.Version != 3 {
+		t.Fatalf("重试 version = %d, err = %v", current.Version, err)
+	}
+}
+
+func TestStrategyRepositoryUpdateVersion(t *testing.T) {
+	repo := NewStrategyRepository(newTestDB(t, &models.Tag{}, &models.Strategy{}))
+	ctx := context.Background()
+	strategy := &models.Strategy{UserID: 1, Name: "均线", Type: "ma_cross", ClassName: "MACross", Params: "{}"}
+	if err := repo.Create(ctx, strategy); err != nil {
+		t.Fatal(err)
+	}
+	stale := *strategy
+
+	strategy.Name = "均线交叉"
+	if err := repo.Update(ctx, strategy); err != nil || strategy.Version != 2 {
+		t.Fatalf("更新 version = %d, err = %v", strategy.Version, err)
+	}
+	stale.IsActive = false
+	if err := repo.Update(ctx, &stale); !errors.Is(err, ErrVersionConflict) || stale.Version != 1 {
+		t.Fatalf("旧版本更新应冲突且版本号不变: version = %d, err = %v", stale.Version, err)
+	}
+	current, err := repo.GetByID(ctx, strategy.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Name != "均线交叉" || current.Version != 2 || !current.IsActive {
+		t.Fatalf("当前记录 %+v，期望只有第一次修改生效", current)
+	}
+}
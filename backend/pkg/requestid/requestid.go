@@ -0,0 +1,49 @@
+// Package requestid 请求ID：在网关生成并随请求头传递到各服务，写入请求上下文，
+// 用于关联网关日志、服务请求日志与 SQL 日志。
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Header 请求ID请求头/响应头
+const Header = "X-Request-ID"
+
+// maxLength 接受的外部请求ID最大长度，超出或含非法字符时重新生成
+const maxLength = 64
+
+type contextKey struct{}
+
+// New 生成请求ID
+func New() string {
+	return uuid.NewString()
+}
+
+// Valid 外部传入的请求ID是否可用：非空、不超过 64 个字符且只含可打印 ASCII 字符
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// NewContext 返回携带请求ID的上下文
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 取出上下文中的请求ID，没有时返回空字符串
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
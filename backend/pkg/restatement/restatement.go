@@ -0,0 +1,109 @@
+// Package restatement 历史日K线被修正（人工修正、从数据源修复、清理重复K线）后的下游失效
+// 按衍生数据之间的依赖关系列出需要重算或标记过期的数据及其受影响区间，由数据同步服务逐项执行。
+package restatement
+
+import (
+	"time"
+
+	"stock-analysis-system/backend/pkg/factor"
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/quality"
+)
+
+// RecentDays 行情缓存覆盖的最近自然日数（全市场最近行情与个股行情回看的天数），更早的修正不影响缓存
+const RecentDays = 15
+
+// Change 被修正的一段日K线
+type Change struct {
+	Symbol   string
+	Exchange string
+	Start    time.Time // 第一根被修正的K线日期
+	End      time.Time // 最后一根被修正的K线日期
+}
+
+// Impact 一项受影响的衍生数据
+type Impact struct {
+	Artifact  string    // 衍生数据，与执行失效的下游同步任务的 job_type 相同
+	DependsOn string    // 所依赖的上游数据，直接依赖日K线时为 daily_bars
+	Start     time.Time // 受影响区间
+	End       time.Time
+}
+
+// node 依赖图中的一项衍生数据，affected 由上游的受影响区间推出本项的区间，不受影响时返回 false
+type node struct {
+	artifact  string
+	dependsOn string
+	affected  func(start, end, now time.Time) (time.Time, time.Time, bool)
+}
+
+// graph 日K线的下游依赖，按拓扑顺序排列：上游总在下游之前
+// 技术指标与回测都按滚动窗口使用K线，修正影响其后一个预热期内的值；因子得分按截面计算，
+// 修正影响其后一个因子回看期内全部股票的得分；行情缓存只保存最近的K线，质量评分只检查最近的K线。
+var graph = []node{
+	{artifact: models.SyncJobIndicators, dependsOn: models.SyncJobDailyBars, affected: rolling},
+	{artifact: models.SyncJobMarketCache, dependsOn: models.SyncJobDailyBars, affected: recent(RecentDays)},
+	{artifact: models.SyncJobStaleBacktests, dependsOn: models.SyncJobDailyBars, affected: rolling},
+	{artifact: models.SyncJobFactors, dependsOn: models.SyncJobDailyBars, affected: lookback(factor.LookbackDays)},
+	{artifact: models.SyncJobQualityScore, dependsOn: models.SyncJobDailyBars, affected: recent(quality.CheckWindowDays)},
+}
+
+// Plan 按依赖图列出修正影响的衍生数据，上游在下游之前
+func Plan(c Change, now time.Time) []Impact {
+	ranges := map[string][2]time.Time{models.SyncJobDailyBars: {c.Start, c.End}}
+	var impacts []Impact
+	for _, n := range graph {
+		up, ok := ranges[n.dependsOn]
+		if !ok {
+			continue
+		}
+		start, end, ok := n.affected(up[0], up[1], now)
+		if !ok {
+			continue
+		}
+		ranges[n.artifact] = [2]time.Time{start, end}
+		impacts = append(impacts, Impact{Artifact: n.artifact, DependsOn: n.dependsOn, Start: start, End: end})
+	}
+	return impacts
+}
+
+// Blocked 上游失败时跳过的下游：impact 依赖的数据（直接或间接）在 failed 中时返回 true
+func Blocked(impacts []Impact, impact Impact, failed map[string]bool) bool {
+	for dep := impact.DependsOn; dep != ""; {
+		if failed[dep] {
+			return true
+		}
+		next := ""
+		for _, i := range impacts {
+			if i.Artifact == dep {
+				next = i.DependsOn
+				break
+			}
+		}
+		dep = next
+	}
+	return false
+}
+
+// rolling 按滚动窗口计算的数据：区间延长一个预热期，不超过当前时间
+func rolling(start, end, now time.Time) (time.Time, time.Time, bool) {
+	return indicator.AffectedRange(start, end, now)
+}
+
+// lookback 按回看期计算的数据：区间延长 days 个自然日，不超过当前时间
+func lookback(days int) func(start, end, now time.Time) (time.Time, time.Time, bool) {
+	return func(start, end, now time.Time) (time.Time, time.Time, bool) {
+		end = end.AddDate(0, 0, days)
+		if end.After(now) {
+			end = now
+		}
+		return start, end, !start.After(now)
+	}
+}
+
+// recent 只覆盖最近数据的缓存或检查：修正区间在最近 days 天内才受影响
+func recent(days int) func(start, end, now time.Time) (time.Time, time.Time, bool) {
+	return func(start, end, now time.Time) (time.Time, time.Time, bool) {
+		return start, end, !end.Before(now.AddDate(0, 0, -days))
+	}
+}
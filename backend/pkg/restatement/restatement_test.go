@@ -0,0 +1,103 @@
+package restatement
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/factor"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// artifacts 按顺序列出受影响的衍生数据
+func artifacts(impacts []Impact) []string {
+	out := make([]string, len(impacts))
+	for i, impact := range impacts {
+		out[i] = impact.Artifact
+	}
+	return out
+}
+
+func find(impacts []Impact, artifact string) (Impact, bool) {
+	for _, impact := range impacts {
+		if impact.Artifact == artifact {
+			return impact, true
+		}
+	}
+	return Impact{}, false
+}
+
+func TestPlan(t *testing.T) {
+	now := date("2024-10-17")
+
+	// 半年前的修正：指标与回测延长一个预热期，因子得分延长一个回看期（截止到当前），不影响行情缓存与质量评分
+	impacts := Plan(Change{Symbol: "600519", Exchange: "SH", Start: date("2024-03-01"), End: date("2024-03-05")}, now)
+	want := []string{models.SyncJobIndicators, models.SyncJobStaleBacktests, models.SyncJobFactors}
+	if got := artifacts(impacts); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("受影响的数据 = %v，期望 %v", got, want)
+	}
+	if want := date("2024-09-01"); !impacts[0].End.Equal(want) || !impacts[0].Start.Equal(date("2024-03-01")) {
+		t.Errorf("指标区间 = %s ~ %s, 结束日应为 %s", impacts[0].Start, impacts[0].End, want)
+	}
+	if f, _ := find(impacts, models.SyncJobFactors); !f.Start.Equal(date("2024-03-01")) || !f.End.Equal(now) {
+		t.Errorf("因子得分区间 = %s ~ %s", f.Start, f.End)
+	}
+
+	// 三年前的修正：因子得分只影响其后一个回看期
+	impacts = Plan(Change{Start: date("2021-06-01"), End: date("2021-06-01")}, now)
+	f, ok := find(impacts, models.SyncJobFactors)
+	if want := date("2021-06-01").AddDate(0, 0, factor.LookbackDays); !ok || !f.End.Equal(want) {
+		t.Errorf("因子得分区间结束日 = %s，期望 %s", f.End, want)
+	}
+
+	// 最近的修正：影响行情缓存与质量评分，滚动区间截止到当前
+	impacts = Plan(Change{Start: date("2024-10-15"), End: date("2024-10-15")}, now)
+	want = []string{models.SyncJobIndicators, models.SyncJobMarketCache, models.SyncJobStaleBacktests, models.SyncJobFactors, models.SyncJobQualityScore}
+	if got := artifacts(impacts); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("受影响的数据 = %v，期望 %v", got, want)
+	}
+	if !impacts[0].End.Equal(now) {
+		t.Errorf("指标区间应截止到当前，实际 %s", impacts[0].End)
+	}
+
+	// 20 天前的修正：超出行情缓存范围，仍在质量检查窗口内
+	impacts = Plan(Change{Start: date("2024-09-27"), End: date("2024-09-27")}, now)
+	if _, ok := find(impacts, models.SyncJobMarketCache); ok {
+		t.Error("20 天前的修正不应影响行情缓存")
+	}
+	if _, ok := find(impacts, models.SyncJobQualityScore); !ok {
+		t.Error("质量检查窗口内的修正应重算质量评分")
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	impacts := []Impact{
+		{Artifact: "a", DependsOn: models.SyncJobDailyBars},
+		{Artifact: "b", DependsOn: "a"},
+		{Artifact: "c", DependsOn: "b"},
+		{Artifact: "d", DependsOn: models.SyncJobDailyBars},
+	}
+	failed := map[string]bool{"a": true}
+	for _, tt := range []struct {
+		artifact string
+		want     bool
+	}{{"a", false}, {"b", true}, {"c", true}, {"d", false}} {
+		var impact Impact
+		for _, i := range impacts {
+			if i.Artifact == tt.artifact {
+				impact = i
+			}
+		}
+		if got := Blocked(impacts, impact, failed); got != tt.want {
+			t.Errorf("Blocked(%s) = %v, want %v", tt.artifact, got, tt.want)
+		}
+	}
+}
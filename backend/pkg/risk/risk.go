@@ -0,0 +1,387 @@
+// Package risk 风险指标计算：历史 VaR、年化波动率、最大回撤、夏普比率、Beta 与相关系数
+package risk
+
+import (
+	"math"
+	"sort"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// TradingDaysPerYear 年化使用的交易日数
+const TradingDaysPerYear = 252
+
+// Metrics 单条净值/价格序列的风险指标
+type Metrics struct {
+	Observations int     `json:"observations"` // 收益率样本数
+	TotalReturn  float64 `json:"total_return"` // 区间收益率
+	Volatility   float64 `json:"volatility"`   // 年化波动率
+	VaR          float64 `json:"var"`          // 单日历史 VaR（损失比例，正数）
+	MaxDrawdown  float64 `json:"max_drawdown"` // 最大回撤（正数）
+	SharpeRatio  float64 `json:"sharpe_ratio"` // 年化夏普比率（无风险利率按0计）
+}
+
+// Compute 根据按时间排序的净值或价格序列计算风险指标
+// confidence 为 VaR 置信度，如 0.95。
+func Compute(values []float64, confidence float64) Metrics {
+	returns := Returns(values)
+	m := Metrics{
+		Observations: len(returns),
+		Volatility:   Volatility(returns),
+		VaR:          HistoricalVaR(returns, confidence),
+		MaxDrawdown:  MaxDrawdown(values),
+		SharpeRatio:  Sharpe(returns, 0),
+	}
+	if len(values) > 1 && values[0] > 0 {
+		m.TotalReturn = values[len(values)-1]/values[0] - 1
+	}
+	return m
+}
+
+// Returns 计算逐期简单收益率，跳过非正的前值
+func Returns(values []float64) []float64 {
+	if len(values) < 2 {
+		return nil
+	}
+	returns := make([]float64, 0, len(values)-1)
+	for i := 1; i < len(values); i++ {
+		if values[i-1] <= 0 {
+			continue
+		}
+		returns = append(returns, values[i]/values[i-1]-1)
+	}
+	return returns
+}
+
+// Volatility 年化波动率（样本标准差 × √252）
+func Volatility(returns []float64) float64 {
+	return stddev(returns) * math.Sqrt(TradingDaysPerYear)
+}
+
+// HistoricalVaR 历史模拟法 VaR，返回给定置信度下的单期最大损失比例（正数）
+// 样本不足或分位数为收益时返回 0。
+func HistoricalVaR(returns []float64, confidence float64) float64 {
+	if len(returns) == 0 || confidence <= 0 || confidence >= 1 {
+		return 0
+	}
+	sorted := make([]float64, len(returns))
+	copy(sorted, returns)
+	sort.Float64s(sorted)
+
+	idx := int(math.Floor((1 - confidence) * float64(len(sorted))))
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	if loss := -sorted[idx]; loss > 0 {
+		return loss
+	}
+	return 0
+}
+
+// MaxDrawdown 最大回撤（相对前期高点的最大跌幅，正数）
+func MaxDrawdown(values []float64) float64 {
+	var peak, maxDD float64
+	for _, v := range values {
+		if v > peak {
+			peak = v
+		}
+		if peak > 0 {
+			if dd := (peak - v) / peak; dd > maxDD {
+				maxDD = dd
+			}
+		}
+	}
+	return maxDD
+}
+
+// Sharpe 年化夏普比率，riskFree 为年化无风险利率
+func Sharpe(returns []float64, riskFree float64) float64 {
+	sd := stddev(returns)
+	if sd == 0 {
+		return 0
+	}
+	excess := mean(returns) - riskFree/TradingDaysPerYear
+	return excess / sd * math.Sqrt(TradingDaysPerYear)
+}
+
+// Correlation 皮尔逊相关系数，长度不一致或方差为0时返回 0
+func Correlation(x, y []float64) float64 {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0
+	}
+	mx, my := mean(x), mean(y)
+	var cov, vx, vy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		cov += dx * dy
+		vx += dx * dx
+		vy += dy * dy
+	}
+	if vx == 0 || vy == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// Beta 序列 x 相对 y 的 Beta（cov(x,y)/var(y)），长度不一致或 y 方差为0时返回 0
+func Beta(x, y []float64) float64 {
+	if len(x) != len(y) || len(x) < 2 {
+		return 0
+	}
+	mx, my := mean(x), mean(y)
+	var cov, vy float64
+	for i := range x {
+		dy := y[i] - my
+		cov += (x[i] - mx) * dy
+		vy += dy * dy
+	}
+	if vy == 0 {
+		return 0
+	}
+	return cov / vy
+}
+
+// RollingPoint 滚动窗口统计值，Date 为窗口最后一个交易日
+type RollingPoint struct {
+	Date        string  `json:"date"`
+	Correlation float64 `json:"correlation"`
+	Beta        float64 `json:"beta"`
+}
+
+// Rolling 计算 x 相对 y 的滚动相关系数与 Beta
+// dates 与收益率一一对应，窗口未满的位置不输出。
+func Rolling(dates []string, x, y []float64, window int) []*RollingPoint {
+	if window < 2 || len(x) != len(y) || len(dates) != len(x) {
+		return nil
+	}
+	var points []*RollingPoint
+	for end := window; end <= len(x); end++ {
+		wx, wy := x[end-window:end], y[end-window:end]
+		points = append(points, &RollingPoint{
+			Date:        dates[end-1],
+			Correlation: Correlation(wx, wy),
+			Beta:        Beta(wx, wy),
+		})
+	}
+	return points
+}
+
+// CorrelationMatrix 计算各序列收益率的两两相关系数矩阵
+// series 为 名称 -> 交易日 -> 价格，每一对序列只使用两者共同的交易日。
+// 返回的 names 按字典序排列，与矩阵行列一一对应。
+func CorrelationMatrix(series map[string]map[string]float64) (names []string, matrix [][]float64) {
+	for name := range series {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	matrix = make([][]float64, len(names))
+	for i := range names {
+		matrix[i] = make([]float64, len(names))
+		matrix[i][i] = 1
+	}
+	for i := 0; i < len(names); i++ {
+		for j := i + 1; j < len(names); j++ {
+			_, x, y := AlignedReturns(series[names[i]], series[names[j]])
+			corr := Correlation(x, y)
+			matrix[i][j] = corr
+			matrix[j][i] = corr
+		}
+	}
+	return names, matrix
+}
+
+// ClosesByDate 将日K线转换为 交易日(YYYY-MM-DD) -> 收盘价
+func ClosesByDate(bars []*models.DailyBar) map[string]float64 {
+	closes := make(map[string]float64, len(bars))
+	for _, bar := range bars {
+		closes[bar.Date.Format("2006-01-02")] = bar.Close
+	}
+	return closes
+}
+
+// SortedValues 将 交易日 -> 价格 按日期排序后返回价格序列
+func SortedValues(byDate map[string]float64) []float64 {
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	values := make([]float64, len(dates))
+	for i, date := range dates {
+		values[i] = byDate[date]
+	}
+	return values
+}
+
+// PeriodReturn 区间收益率
+type PeriodReturn struct {
+	Period string  `json:"period"` // 月度为 YYYY-MM，年度为 YYYY
+	Return float64 `json:"return"`
+}
+
+// Calendar 收益日历：月度与年度收益、最佳/最差月份、最长回撤持续期
+type Calendar struct {
+	Monthly         []*PeriodReturn `json:"monthly"`
+	Yearly          []*PeriodReturn `json:"yearly"`
+	BestMonth       *PeriodReturn   `json:"best_month,omitempty"`
+	WorstMonth      *PeriodReturn   `json:"worst_month,omitempty"`
+	LongestDrawdown *DrawdownPeriod `json:"longest_drawdown,omitempty"` // 从未回撤时为空
+}
+
+// DrawdownPeriod 一段回撤：从前期高点跌破开始，到重新回到高点结束
+type DrawdownPeriod struct {
+	Start     string  `json:"start"`         // 回撤前的高点日
+	Trough    string  `json:"trough"`        // 期间最低点
+	End       string  `json:"end,omitempty"` // 回到前高的日期，未恢复时为空
+	Days      int     `json:"days"`          // 高点至恢复（或序列结束）的交易日数
+	Depth     float64 `json:"depth"`         // 期间最大回撤（正数）
+	Recovered bool    `json:"recovered"`
+}
+
+// NewCalendar 根据按时间排序的净值序列生成收益日历
+func NewCalendar(dates []string, values []float64) *Calendar {
+	cal := &Calendar{
+		Monthly:         MonthlyReturns(dates, values),
+		Yearly:          AnnualReturns(dates, values),
+		LongestDrawdown: LongestDrawdown(dates, values),
+	}
+	for _, m := range cal.Monthly {
+		if cal.BestMonth == nil || m.Return > cal.BestMonth.Return {
+			cal.BestMonth = m
+		}
+		if cal.WorstMonth == nil || m.Return < cal.WorstMonth.Return {
+			cal.WorstMonth = m
+		}
+	}
+	return cal
+}
+
+// LongestDrawdown 持续时间最长的一段回撤，持续时间相同时取较早的一段；从未回撤时返回 nil
+func LongestDrawdown(dates []string, values []float64) *DrawdownPeriod {
+	if len(dates) != len(values) {
+		return nil
+	}
+	var longest, current *DrawdownPeriod
+	peak, troughValue := 0, 0.0
+	closePeriod := func(end int, recovered bool) {
+		current.Days = end - peak
+		current.Recovered = recovered
+		if recovered {
+			current.End = dates[end]
+		}
+		if longest == nil || current.Days > longest.Days {
+			longest = current
+		}
+		current = nil
+	}
+
+	for i, v := range values {
+		if v >= values[peak] {
+			if current != nil {
+				closePeriod(i, true)
+			}
+			peak = i
+			continue
+		}
+		if current == nil {
+			current = &DrawdownPeriod{Start: dates[peak], Trough: dates[i]}
+			troughValue = v
+		}
+		if v <= troughValue {
+			troughValue = v
+			current.Trough = dates[i]
+		}
+		if values[peak] > 0 {
+			current.Depth = math.Max(current.Depth, 1-v/values[peak])
+		}
+	}
+	if current != nil {
+		closePeriod(len(values)-1, false)
+	}
+	return longest
+}
+
+// MonthlyReturns 按自然月汇总净值序列的收益率
+// dates 为按时间排序的交易日（YYYY-MM-DD），与 values 一一对应；
+// 每月以上月最后一个净值为基准，首月以首个净值为基准。
+func MonthlyReturns(dates []string, values []float64) []*PeriodReturn {
+	return periodReturns(dates, values, len("2006-01"))
+}
+
+// AnnualReturns 按自然年汇总净值序列的收益率，基准规则同 MonthlyReturns
+func AnnualReturns(dates []string, values []float64) []*PeriodReturn {
+	return periodReturns(dates, values, len("2006"))
+}
+
+// periodReturns 按日期前缀（年或年月）分段计算收益率
+func periodReturns(dates []string, values []float64, prefix int) []*PeriodReturn {
+	if len(dates) != len(values) || len(values) == 0 {
+		return nil
+	}
+	var periods []*PeriodReturn
+	base := values[0]
+	for i, date := range dates {
+		if len(date) < prefix {
+			continue
+		}
+		last := i == len(dates)-1 || len(dates[i+1]) < prefix || dates[i+1][:prefix] != date[:prefix]
+		if !last {
+			continue
+		}
+		p := &PeriodReturn{Period: date[:prefix]}
+		if base > 0 {
+			p.Return = values[i]/base - 1
+		}
+		periods = append(periods, p)
+		base = values[i]
+	}
+	return periods
+}
+
+// AlignedReturns 取两条 交易日 -> 价格 序列的共同交易日并计算各自收益率
+// 返回的 dates 为每个收益率对应的（后一个）交易日。
+func AlignedReturns(a, b map[string]float64) (dates []string, rx, ry []float64) {
+	var common []string
+	for date := range a {
+		if _, ok := b[date]; ok {
+			common = append(common, date)
+		}
+	}
+	sort.Strings(common)
+
+	for i := 1; i < len(common); i++ {
+		prevA, prevB := a[common[i-1]], b[common[i-1]]
+		if prevA <= 0 || prevB <= 0 {
+			continue
+		}
+		dates = append(dates, common[i])
+		rx = append(rx, a[common[i]]/prevA-1)
+		ry = append(ry, b[common[i]]/prevB-1)
+	}
+	return dates, rx, ry
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var sum float64
+	for _, v := range values {
+		sum += (v - m) * (v - m)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
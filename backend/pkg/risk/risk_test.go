@@ -0,0 +1,127 @@
+package risk
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	values := []float64{100, 120, 90, 110, 80, 130}
+	// 高点 120 跌至 80
+	if got := MaxDrawdown(values); !almostEqual(got, 1.0/3) {
+		t.Errorf("最大回撤应为 33.33%%，实际 %v", got)
+	}
+	if got := MaxDrawdown([]float64{1, 2, 3}); got != 0 {
+		t.Errorf("单边上涨最大回撤应为 0，实际 %v", got)
+	}
+}
+
+func TestHistoricalVaR(t *testing.T) {
+	returns := make([]float64, 100)
+	for i := range returns {
+		returns[i] = float64(i-50) / 1000 // -5.0% ~ +4.9%
+	}
+	// 95% 置信度取第 5 个最小值：-4.5%
+	if got := HistoricalVaR(returns, 0.95); !almostEqual(got, 0.045) {
+		t.Errorf("95%% VaR 应为 4.5%%，实际 %v", got)
+	}
+	if got := HistoricalVaR([]float64{0.01, 0.02}, 0.95); got != 0 {
+		t.Errorf("全部为正收益时 VaR 应为 0，实际 %v", got)
+	}
+}
+
+func TestCorrelationMatrix(t *testing.T) {
+	series := map[string]map[string]float64{
+		"A": {"2024-01-01": 10, "2024-01-02": 11, "2024-01-03": 10.5, "2024-01-04": 12},
+		"B": {"2024-01-01": 20, "2024-01-02": 22, "2024-01-03": 21, "2024-01-04": 24},
+		"C": {"2024-01-01": 10, "2024-01-02": 9, "2024-01-03": 10, "2024-01-04": 8},
+	}
+	names, matrix := CorrelationMatrix(series)
+	if len(names) != 3 || names[0] != "A" {
+		t.Fatalf("名称应按字典序排列: %v", names)
+	}
+	if !almostEqual(matrix[0][1], 1) {
+		t.Errorf("同比例变动的序列相关系数应为 1，实际 %v", matrix[0][1])
+	}
+	if matrix[0][2] >= 0 || matrix[2][0] != matrix[0][2] {
+		t.Errorf("反向变动的序列应负相关且矩阵对称: %v", matrix)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	m := Compute([]float64{100, 101, 99, 102}, 0.95)
+	if m.Observations != 3 || !almostEqual(m.TotalReturn, 0.02) {
+		t.Errorf("样本数或区间收益错误: %+v", m)
+	}
+	if m.Volatility <= 0 || m.MaxDrawdown <= 0 {
+		t.Errorf("波动率与最大回撤应大于0: %+v", m)
+	}
+}
+
+func TestBetaAndRolling(t *testing.T) {
+	y := []float64{0.01, -0.02, 0.015, 0.005, -0.01}
+	x := make([]float64, len(y))
+	for i := range y {
+		x[i] = 2*y[i] + 0.001
+	}
+	if got := Beta(x, y); !almostEqual(got, 2) {
+		t.Errorf("Beta 应为 2，实际 %v", got)
+	}
+
+	dates := []string{"d1", "d2", "d3", "d4", "d5"}
+	points := Rolling(dates, x, y, 3)
+	if len(points) != 3 || points[0].Date != "d3" || points[2].Date != "d5" {
+		t.Fatalf("滚动窗口日期错误: %+v", points)
+	}
+	for _, p := range points {
+		if !almostEqual(p.Correlation, 1) || !almostEqual(p.Beta, 2) {
+			t.Errorf("线性相关序列的滚动相关系数应为 1、Beta 应为 2: %+v", p)
+		}
+	}
+}
+
+func TestMonthlyReturns(t *testing.T) {
+	dates := []string{"2024-01-30", "2024-01-31", "2024-02-01", "2024-02-29", "2024-03-01"}
+	values := []float64{100, 110, 99, 121, 133.1}
+	got := MonthlyReturns(dates, values)
+	want := []struct {
+		period string
+		ret    float64
+	}{{"2024-01", 0.1}, {"2024-02", 0.1}, {"2024-03", 0.1}}
+	if len(got) != len(want) {
+		t.Fatalf("应有 %d 个月，实际 %+v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Period != w.period || !almostEqual(got[i].Return, w.ret) {
+			t.Errorf("第 %d 个月应为 %s %v，实际 %+v", i, w.period, w.ret, got[i])
+		}
+	}
+}
+
+func TestCalendarAndLongestDrawdown(t *testing.T) {
+	dates := []string{"2023-12-28", "2023-12-29", "2024-01-02", "2024-01-03", "2024-01-04", "2024-02-01", "2024-02-02"}
+	values := []float64{100, 120, 110, 90, 121, 110, 115}
+	cal := NewCalendar(dates, values)
+
+	if len(cal.Yearly) != 2 || cal.Yearly[0].Period != "2023" || !almostEqual(cal.Yearly[0].Return, 0.2) {
+		t.Errorf("年度收益错误: %+v", cal.Yearly)
+	}
+	if cal.BestMonth.Period != "2023-12" || cal.WorstMonth.Period != "2024-02" {
+		t.Errorf("最佳/最差月份错误: %+v %+v", cal.BestMonth, cal.WorstMonth)
+	}
+
+	dd := cal.LongestDrawdown
+	if dd == nil || dd.Start != "2023-12-29" || dd.Trough != "2024-01-03" || dd.End != "2024-01-04" || dd.Days != 3 || !dd.Recovered {
+		t.Fatalf("最长回撤错误: %+v", dd)
+	}
+	if !almostEqual(dd.Depth, 0.25) {
+		t.Errorf("回撤深度应为 25%%，实际 %v", dd.Depth)
+	}
+	if got := LongestDrawdown(dates[:2], values[:2]); got != nil {
+		t.Errorf("单边上涨不应有回撤: %+v", got)
+	}
+}
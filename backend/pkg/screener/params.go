@@ -0,0 +1,189 @@
+package screener
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// Params 选股参数，区间条件均为可选
+// 同时用于接口查询参数与股票池（universe）保存的筛选条件。
+type Params struct {
+	Exchange    string `form:"exchange" json:"exchange,omitempty"`
+	Industry    string `form:"industry" json:"industry,omitempty"`
+	ST          string `form:"st" json:"st,omitempty"`                               // exclude/only，按 as_of 当日的风险警示状态筛选
+	MinListDays int    `form:"min_list_days" json:"min_list_days,omitempty"`         // 最少上市天数
+	MinQuality  int    `form:"min_quality_score" json:"min_quality_score,omitempty"` // 数据质量评分下限（0~100），使用最近一次评分
+	ReturnDays  int    `form:"return_days" json:"return_days,omitempty"`             // 区间涨跌幅回看交易日数，默认 20
+	Sort        string `form:"sort" json:"sort,omitempty"`                           // amount/return/close/market_cap/float_cap/pe/roe/northbound_ratio/northbound_change/symbol，默认 amount
+	Order       string `form:"order" json:"order,omitempty"`                         // asc/desc，默认 desc
+
+	MinPrice       *float64 `form:"min_price" json:"min_price,omitempty"`
+	MaxPrice       *float64 `form:"max_price" json:"max_price,omitempty"`
+	MinReturn      *float64 `form:"min_return" json:"min_return,omitempty"` // 小数，0.1 表示 10%
+	MaxReturn      *float64 `form:"max_return" json:"max_return,omitempty"`
+	MinAmount      *float64 `form:"min_amount" json:"min_amount,omitempty"` // 日均成交额（元）
+	MaxAmount      *float64 `form:"max_amount" json:"max_amount,omitempty"`
+	MinMarketCap   *float64 `form:"min_market_cap" json:"min_market_cap,omitempty"` // 总市值（元）
+	MaxMarketCap   *float64 `form:"max_market_cap" json:"max_market_cap,omitempty"`
+	MinFloatCap    *float64 `form:"min_float_cap" json:"min_float_cap,omitempty"` // 流通市值（元）
+	MaxFloatCap    *float64 `form:"max_float_cap" json:"max_float_cap,omitempty"`
+	MinPE          *float64 `form:"min_pe" json:"min_pe,omitempty"`
+	MaxPE          *float64 `form:"max_pe" json:"max_pe,omitempty"`
+	MinROE         *float64 `form:"min_roe" json:"min_roe,omitempty"`
+	MaxROE         *float64 `form:"max_roe" json:"max_roe,omitempty"`
+	MinGrossMargin *float64 `form:"min_gross_margin" json:"min_gross_margin,omitempty"`
+	MaxGrossMargin *float64 `form:"max_gross_margin" json:"max_gross_margin,omitempty"`
+	MinDebtRatio   *float64 `form:"min_debt_ratio" json:"min_debt_ratio,omitempty"`
+	MaxDebtRatio   *float64 `form:"max_debt_ratio" json:"max_debt_ratio,omitempty"`
+
+	// 北向持股条件：持股比例为占流通股的百分比，变化为近 northbound_days 个交易日的百分点变化
+	NorthboundDays      int      `form:"northbound_days" json:"northbound_days,omitempty"`     // 默认 20
+	NorthboundRising    bool     `form:"northbound_rising" json:"northbound_rising,omitempty"` // 只保留北向持股比例上升的股票
+	MinNorthboundRatio  *float64 `form:"min_northbound_ratio" json:"min_northbound_ratio,omitempty"`
+	MaxNorthboundRatio  *float64 `form:"max_northbound_ratio" json:"max_northbound_ratio,omitempty"`
+	MinNorthboundChange *float64 `form:"min_northbound_change" json:"min_northbound_change,omitempty"`
+	MaxNorthboundChange *float64 `form:"max_northbound_change" json:"max_northbound_change,omitempty"`
+}
+
+// Normalize 补齐默认值并校验参数
+func (p *Params) Normalize() error {
+	if p.ReturnDays == 0 {
+		p.ReturnDays = DefaultReturnDays
+	}
+	if p.ReturnDays < 1 || p.ReturnDays > MaxReturnDays {
+		return fmt.Errorf("return_days 应在 1~%d 之间", MaxReturnDays)
+	}
+	if p.NorthboundDays == 0 {
+		p.NorthboundDays = DefaultNorthboundDays
+	}
+	if p.NorthboundDays < 1 || p.NorthboundDays > MaxNorthboundDays {
+		return fmt.Errorf("northbound_days 应在 1~%d 之间", MaxNorthboundDays)
+	}
+	if p.Sort == "" {
+		p.Sort = SortAmount
+	}
+	if !ValidSort(p.Sort) {
+		return fmt.Errorf("不支持的排序字段: %s", p.Sort)
+	}
+	if p.MinQuality < 0 || p.MinQuality > 100 {
+		return fmt.Errorf("min_quality_score 应在 0~100 之间")
+	}
+	if p.ST != "" && p.ST != STExclude && p.ST != STOnly {
+		return fmt.Errorf("st 应为 exclude 或 only")
+	}
+	switch p.Order {
+	case "":
+		p.Order = "desc"
+	case "asc", "desc":
+	default:
+		return fmt.Errorf("order 应为 asc 或 desc")
+	}
+	return nil
+}
+
+// Criteria 转换为筛选条件
+func (p *Params) Criteria() *Criteria {
+	return &Criteria{
+		Exchange:    p.Exchange,
+		Industry:    p.Industry,
+		ST:          p.ST,
+		MinListDays: p.MinListDays,
+		MinQuality:  p.MinQuality,
+		Close:       Range{Min: p.MinPrice, Max: p.MaxPrice},
+		Return:      Range{Min: p.MinReturn, Max: p.MaxReturn},
+		AvgAmount:   Range{Min: p.MinAmount, Max: p.MaxAmount},
+		MarketCap:   Range{Min: p.MinMarketCap, Max: p.MaxMarketCap},
+		FloatCap:    Range{Min: p.MinFloatCap, Max: p.MaxFloatCap},
+		PE:          Range{Min: p.MinPE, Max: p.MaxPE},
+		ROE:         Range{Min: p.MinROE, Max: p.MaxROE},
+		GrossMargin: Range{Min: p.MinGrossMargin, Max: p.MaxGrossMargin},
+		DebtRatio:   Range{Min: p.MinDebtRatio, Max: p.MaxDebtRatio},
+
+		NorthboundRising: p.NorthboundRising,
+		NorthboundRatio:  Range{Min: p.MinNorthboundRatio, Max: p.MaxNorthboundRatio},
+		NorthboundChange: Range{Min: p.MinNorthboundChange, Max: p.MaxNorthboundChange},
+	}
+}
+
+// Result 选股结果
+type Result struct {
+	AsOf      string `json:"as_of"`
+	TradeDate string `json:"trade_date"` // 结果中最近的K线日期
+	Universe  int    `json:"universe"`   // 当日已上市的股票数
+	Rows      []*Row `json:"-"`          // 按参数排序的全部结果
+}
+
+// Run 按 as_of 当日的时点数据选股
+// 股票池为当日已上市的股票（含此后退市的），行情只取当日及之前的K线，财报只取当日已过法定披露截止日的最近一期，
+// 风险警示取当日生效的 ST/*ST 记录，北向持股只取当日及之前的记录。
+// params 需先调用 Normalize。
+func Run(ctx context.Context, stockRepo repository.StockRepository, marketRepo repository.MarketRepository,
+	factorRepo repository.FactorRepository, connectRepo repository.StockConnectRepository, params *Params, asOf time.Time) (*Result, error) {
+
+	stocks, err := stockRepo.GetListedAsOf(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("查询股票列表失败: %w", err)
+	}
+
+	// 风险警示按 as_of 当日的历史记录判断，而不是股票当前状态
+	warnings, err := stockRepo.GetRiskWarningsAsOf(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("查询风险警示失败: %w", err)
+	}
+
+	criteria := params.Criteria()
+	var reports map[string]*models.FinancialReport
+	if criteria.NeedsFundamentals() || params.Sort == SortPE || params.Sort == SortROE {
+		if reports, err = factorRepo.GetDisclosedReports(ctx, asOf); err != nil {
+			return nil, fmt.Errorf("查询财报失败: %w", err)
+		}
+	}
+
+	end := asOf.Add(24*time.Hour - time.Nanosecond)
+	start := asOf.AddDate(0, 0, -HistoryDays(params.ReturnDays))
+	bars, err := marketRepo.GetMarketDailyBars(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("查询K线失败: %w", err)
+	}
+
+	var holdings map[string][]*models.NorthboundHolding
+	northbound := criteria.NeedsNorthbound() || NorthboundSort(params.Sort)
+	if northbound {
+		holdingStart := asOf.AddDate(0, 0, -HistoryDays(params.NorthboundDays))
+		if holdings, err = connectRepo.GetMarketHoldings(ctx, holdingStart, end); err != nil {
+			return nil, fmt.Errorf("查询北向持股失败: %w", err)
+		}
+	}
+
+	result := &Result{
+		AsOf:     asOf.Format("2006-01-02"),
+		Universe: len(stocks),
+		Rows:     make([]*Row, 0),
+	}
+	for _, stock := range stocks {
+		key := stock.GetFullCode()
+		row := NewRow(stock, bars[key], reports[key], asOf, params.ReturnDays)
+		if row == nil {
+			continue
+		}
+		row.RiskWarning = warnings[key]
+		if northbound {
+			row.SetNorthbound(holdings[key], asOf, params.NorthboundDays)
+		}
+		if !criteria.Match(row) {
+			continue
+		}
+		if row.TradeDate > result.TradeDate {
+			result.TradeDate = row.TradeDate
+		}
+		result.Rows = append(result.Rows, row)
+	}
+	if err := Sort(result.Rows, params.Sort, params.Order != "asc"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
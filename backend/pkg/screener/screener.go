@@ -0,0 +1,322 @@
+// Package screener 选股器：按指定日期（point-in-time）的行情与已披露财报筛选股票
+// 所有指标只使用 as_of 当日及之前的数据，用于构建无前视偏差的回测股票池。
+package screener
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 默认参数
+const (
+	DefaultReturnDays = 20  // 区间涨跌幅默认回看交易日数
+	MaxReturnDays     = 250 // 区间涨跌幅最大回看交易日数
+	AmountDays        = 20  // 日均成交额统计的交易日数
+
+	// StaleDays 最近一根K线早于 as_of 超过该自然日数时视为停牌或已退市，不参与筛选
+	StaleDays = 10
+
+	DefaultNorthboundDays = 20  // 北向持股比例变化默认回看交易日数
+	MaxNorthboundDays     = 250 // 北向持股比例变化最大回看交易日数
+)
+
+// 排序字段
+const (
+	SortAmount    = "amount"
+	SortReturn    = "return"
+	SortClose     = "close"
+	SortMarketCap = "market_cap"
+	SortFloatCap  = "float_cap"
+	SortPE        = "pe"
+	SortROE       = "roe"
+	SortSymbol    = "symbol"
+
+	SortNorthboundRatio  = "northbound_ratio"
+	SortNorthboundChange = "northbound_change"
+)
+
+// 风险警示筛选
+const (
+	STExclude = "exclude" // 排除 ST/*ST 股票
+	STOnly    = "only"    // 只保留 ST/*ST 股票
+)
+
+var sortFields = map[string]bool{
+	SortAmount: true, SortReturn: true, SortClose: true, SortMarketCap: true, SortFloatCap: true,
+	SortPE: true, SortROE: true, SortSymbol: true, SortNorthboundRatio: true, SortNorthboundChange: true,
+}
+
+// NorthboundSort 排序字段是否使用北向持股
+func NorthboundSort(field string) bool {
+	return field == SortNorthboundRatio || field == SortNorthboundChange
+}
+
+// ValidSort 是否为支持的排序字段
+func ValidSort(field string) bool {
+	return sortFields[field]
+}
+
+// HistoryDays 计算指标所需回看的自然日数
+func HistoryDays(returnDays int) int {
+	days := returnDays
+	if days < AmountDays {
+		days = AmountDays
+	}
+	// 按每周 5 个交易日折算，并预留节假日与停牌余量
+	return days*7/5 + 20
+}
+
+// Row 单只股票在 as_of 日的筛选指标
+type Row struct {
+	Symbol      string     `json:"symbol"`
+	Exchange    string     `json:"exchange"`
+	Name        string     `json:"name"`
+	Industry    string     `json:"industry"`
+	RiskWarning string     `json:"risk_warning,omitempty"` // as_of 当日的风险警示：ST/*ST
+	ListDate    string     `json:"list_date,omitempty"`    // 上市日期，未知时为空
+	ListDays    *int       `json:"list_days,omitempty"`    // 截至 as_of 的上市天数，未知时为空
+	TradeDate   string     `json:"trade_date"`             // 最近一根K线日期
+	Close       float64    `json:"close"`
+	Return      *float64   `json:"return,omitempty"`      // 区间涨跌幅
+	AvgAmount   float64    `json:"avg_amount"`            // 近 AmountDays 个交易日日均成交额（元）
+	TotalShare  int64      `json:"total_share,omitempty"` // 总股本（股），使用当前股本
+	FloatShare  int64      `json:"float_share,omitempty"` // 流通股本（股）
+	MarketCap   *float64   `json:"market_cap,omitempty"`  // 总市值（元），总股本未知时为空
+	FloatCap    *float64   `json:"float_cap,omitempty"`   // 流通市值（元），流通股本未知时为空
+	PE          *float64   `json:"pe,omitempty"`          // 市盈率（年化净利润），亏损或无财报时为空
+	ROE         *float64   `json:"roe,omitempty"`
+	GrossMargin *float64   `json:"gross_margin,omitempty"`
+	DebtRatio   *float64   `json:"debt_ratio,omitempty"`
+	ReportDate  *time.Time `json:"report_date,omitempty"`   // 使用的财报报告期
+	Quality     *int       `json:"quality_score,omitempty"` // 最近一次数据质量评分，未评分时为空
+
+	// 北向持股，只在使用北向条件或排序时计算
+	NorthboundRatio  *float64 `json:"northbound_ratio,omitempty"`  // 北向持股占流通股比例（%）
+	NorthboundChange *float64 `json:"northbound_change,omitempty"` // 北向持股比例的区间变化（百分点）
+}
+
+// NewRow 根据截至 as_of 的日K线与已披露财报计算指标
+// bars 按时间升序，且不晚于 as_of；没有可用K线或最近K线过旧时返回 nil。
+func NewRow(stock *models.Stock, bars []*models.DailyBar, report *models.FinancialReport, asOf time.Time, returnDays int) *Row {
+	if len(bars) == 0 {
+		return nil
+	}
+	last := bars[len(bars)-1]
+	if asOf.Sub(last.Date) > StaleDays*24*time.Hour {
+		return nil
+	}
+
+	row := &Row{
+		Symbol:     stock.Symbol,
+		Exchange:   stock.Exchange,
+		Name:       stock.Name,
+		Industry:   stock.Industry,
+		TradeDate:  last.Date.Format("2006-01-02"),
+		Close:      last.Close,
+		Quality:    stock.QualityScore,
+		TotalShare: stock.TotalShare,
+		FloatShare: stock.FloatShare,
+		MarketCap:  stock.MarketCap(last.Close),
+		FloatCap:   stock.FloatCap(last.Close),
+	}
+	if stock.ListDate != nil {
+		row.ListDate = stock.ListDate.Format("2006-01-02")
+		days := int(asOf.Sub(*stock.ListDate).Hours() / 24)
+		row.ListDays = &days
+	}
+
+	n := len(bars)
+	if n > returnDays {
+		if base := bars[n-1-returnDays].Close; base > 0 {
+			row.Return = ptr(last.Close/base - 1)
+		}
+	}
+
+	window := bars
+	if n > AmountDays {
+		window = bars[n-AmountDays:]
+	}
+	var amount float64
+	for _, bar := range window {
+		amount += bar.Amount
+	}
+	row.AvgAmount = amount / float64(len(window))
+
+	if report != nil {
+		reportDate := report.ReportDate
+		row.ReportDate = &reportDate
+		row.ROE = ptr(report.ROE)
+		row.GrossMargin = ptr(report.GrossMargin)
+		row.DebtRatio = ptr(report.DebtRatio)
+		if profit := report.AnnualizedNetProfit(); row.MarketCap != nil && profit > 0 {
+			row.PE = ptr(*row.MarketCap / profit)
+		}
+	}
+	return row
+}
+
+// SetNorthbound 根据截至 as_of 的北向持股计算持股比例及其近 days 个交易日的变化
+// holdings 按时间升序，且不晚于 as_of；没有持股记录或最近记录过旧（已调出沪深股通）时不设置。
+func (row *Row) SetNorthbound(holdings []*models.NorthboundHolding, asOf time.Time, days int) {
+	n := len(holdings)
+	if n == 0 || asOf.Sub(holdings[n-1].Date) > StaleDays*24*time.Hour {
+		return
+	}
+	row.NorthboundRatio = ptr(holdings[n-1].Ratio)
+	if n > days {
+		row.NorthboundChange = ptr(holdings[n-1].Ratio - holdings[n-1-days].Ratio)
+	}
+}
+
+// Range 数值区间，Min/Max 为空表示不限制
+type Range struct {
+	Min *float64
+	Max *float64
+}
+
+// Active 是否设置了任一边界
+func (r Range) Active() bool {
+	return r.Min != nil || r.Max != nil
+}
+
+// contains 判断值是否在区间内；设置了边界而值缺失时不满足
+func (r Range) contains(v *float64) bool {
+	if !r.Active() {
+		return true
+	}
+	if v == nil {
+		return false
+	}
+	if r.Min != nil && *v < *r.Min {
+		return false
+	}
+	if r.Max != nil && *v > *r.Max {
+		return false
+	}
+	return true
+}
+
+// Criteria 筛选条件
+type Criteria struct {
+	Exchange    string
+	Industry    string
+	ST          string // exclude/only，按 as_of 当日的风险警示状态筛选
+	MinListDays int    // 最少上市天数，排除次新股；上市日期未知时不排除
+	MinQuality  int    // 数据质量评分下限，排除数据有缺陷的股票；未评分时不排除
+	Close       Range
+	Return      Range
+	AvgAmount   Range
+	MarketCap   Range
+	FloatCap    Range
+	PE          Range
+	ROE         Range
+	GrossMargin Range
+	DebtRatio   Range
+
+	NorthboundRising bool // 只保留区间内北向持股比例上升的股票
+	NorthboundRatio  Range
+	NorthboundChange Range
+}
+
+// Match 判断是否满足全部筛选条件
+func (c *Criteria) Match(row *Row) bool {
+	if c.Exchange != "" && row.Exchange != c.Exchange {
+		return false
+	}
+	if c.Industry != "" && row.Industry != c.Industry {
+		return false
+	}
+	if c.ST == STExclude && row.RiskWarning != "" || c.ST == STOnly && row.RiskWarning == "" {
+		return false
+	}
+	if c.MinListDays > 0 && row.ListDays != nil && *row.ListDays < c.MinListDays {
+		return false
+	}
+	if c.MinQuality > 0 && row.Quality != nil && *row.Quality < c.MinQuality {
+		return false
+	}
+	if c.NorthboundRising && (row.NorthboundChange == nil || *row.NorthboundChange <= 0) {
+		return false
+	}
+	return c.Close.contains(&row.Close) &&
+		c.Return.contains(row.Return) &&
+		c.AvgAmount.contains(&row.AvgAmount) &&
+		c.MarketCap.contains(row.MarketCap) &&
+		c.FloatCap.contains(row.FloatCap) &&
+		c.PE.contains(row.PE) &&
+		c.ROE.contains(row.ROE) &&
+		c.GrossMargin.contains(row.GrossMargin) &&
+		c.DebtRatio.contains(row.DebtRatio) &&
+		c.NorthboundRatio.contains(row.NorthboundRatio) &&
+		c.NorthboundChange.contains(row.NorthboundChange)
+}
+
+// NeedsFundamentals 是否使用了财报类条件
+func (c *Criteria) NeedsFundamentals() bool {
+	return c.PE.Active() || c.ROE.Active() || c.GrossMargin.Active() || c.DebtRatio.Active()
+}
+
+// NeedsNorthbound 是否使用了北向持股条件
+func (c *Criteria) NeedsNorthbound() bool {
+	return c.NorthboundRising || c.NorthboundRatio.Active() || c.NorthboundChange.Active()
+}
+
+// Sort 按指定字段排序，缺失值始终排在最后
+func Sort(rows []*Row, field string, desc bool) error {
+	if !ValidSort(field) {
+		return fmt.Errorf("不支持的排序字段: %s", field)
+	}
+	value := func(r *Row) *float64 {
+		switch field {
+		case SortAmount:
+			return &r.AvgAmount
+		case SortReturn:
+			return r.Return
+		case SortClose:
+			return &r.Close
+		case SortMarketCap:
+			return r.MarketCap
+		case SortFloatCap:
+			return r.FloatCap
+		case SortPE:
+			return r.PE
+		case SortROE:
+			return r.ROE
+		case SortNorthboundRatio:
+			return r.NorthboundRatio
+		case SortNorthboundChange:
+			return r.NorthboundChange
+		}
+		return nil
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if field == SortSymbol {
+			a, b := rows[i].Symbol+"."+rows[i].Exchange, rows[j].Symbol+"."+rows[j].Exchange
+			if desc {
+				return a > b
+			}
+			return a < b
+		}
+		a, b := value(rows[i]), value(rows[j])
+		if a == nil || b == nil {
+			return a != nil
+		}
+		if desc {
+			return *a > *b
+		}
+		return *a < *b
+	})
+	return nil
+}
+
+func ptr(v float64) *float64 {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return &v
+}
@@ -0,0 +1,131 @@
+package screener
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// dailyBars 生成截至 end 的连续日K线，成交额固定为 amount
+func dailyBars(end time.Time, amount float64, closes ...float64) []*models.DailyBar {
+	bars := make([]*models.DailyBar, len(closes))
+	for i, c := range closes {
+		bars[i] = &models.DailyBar{Date: end.AddDate(0, 0, i-len(closes)+1), Close: c, Amount: amount}
+	}
+	return bars
+}
+
+func TestNewRow(t *testing.T) {
+	asOf := time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)
+	listDate := asOf.AddDate(0, 0, -100)
+	stock := &models.Stock{Symbol: "600519", Exchange: "SH", TotalShare: 1000, FloatShare: 400, ListDate: &listDate}
+	report := &models.FinancialReport{ReportType: models.ReportTypeQ1, NetProfit: 500, ROE: 0.08}
+
+	row := NewRow(stock, dailyBars(asOf, 1e6, 10, 11, 12), report, asOf, 2)
+	if row == nil {
+		t.Fatal("应返回指标")
+	}
+	if row.Return == nil || math.Abs(*row.Return-0.2) > 1e-9 {
+		t.Errorf("区间涨跌幅应为 20%%，实际 %v", row.Return)
+	}
+	// 市值 12*1000，一季报净利润年化 2000
+	if row.PE == nil || math.Abs(*row.PE-6) > 1e-9 {
+		t.Errorf("市盈率应为 6，实际 %v", row.PE)
+	}
+	if row.MarketCap == nil || *row.MarketCap != 12000 || row.FloatCap == nil || *row.FloatCap != 4800 {
+		t.Errorf("总市值应为 12000、流通市值应为 4800，实际 %v %v", row.MarketCap, row.FloatCap)
+	}
+	if row.ListDays == nil || *row.ListDays != 100 || row.AvgAmount != 1e6 {
+		t.Errorf("上市天数或成交额错误: %+v", row)
+	}
+
+	if row := NewRow(stock, dailyBars(asOf.AddDate(0, 0, -StaleDays-1), 1e6, 10), nil, asOf, 2); row != nil {
+		t.Error("长期停牌的股票不应参与筛选")
+	}
+	if row := NewRow(stock, dailyBars(asOf, 1e6, 10), nil, asOf, 2); row.Return != nil {
+		t.Error("K线不足时区间涨跌幅应为空")
+	}
+}
+
+func TestSetNorthbound(t *testing.T) {
+	asOf := time.Date(2024, 6, 28, 0, 0, 0, 0, time.UTC)
+	holdings := []*models.NorthboundHolding{
+		{Date: asOf.AddDate(0, 0, -2), Ratio: 3.5},
+		{Date: asOf.AddDate(0, 0, -1), Ratio: 3.8},
+		{Date: asOf, Ratio: 4.2},
+	}
+
+	row := &Row{}
+	row.SetNorthbound(holdings, asOf, 2)
+	if row.NorthboundRatio == nil || *row.NorthboundRatio != 4.2 {
+		t.Errorf("北向持股比例应为 4.2，实际 %v", row.NorthboundRatio)
+	}
+	if row.NorthboundChange == nil || math.Abs(*row.NorthboundChange-0.7) > 1e-9 {
+		t.Errorf("北向持股比例变化应为 0.7，实际 %v", row.NorthboundChange)
+	}
+	if !(&Criteria{NorthboundRising: true}).Match(row) {
+		t.Error("持股比例上升的股票应满足 northbound_rising")
+	}
+
+	row = &Row{}
+	row.SetNorthbound(holdings, asOf, 3)
+	if row.NorthboundRatio == nil || row.NorthboundChange != nil {
+		t.Error("持股记录不足时只计算持股比例")
+	}
+	if (&Criteria{NorthboundRising: true}).Match(row) {
+		t.Error("缺少持股比例变化时不应满足 northbound_rising")
+	}
+
+	row = &Row{}
+	row.SetNorthbound(holdings, asOf.AddDate(0, 0, StaleDays+1), 1)
+	if row.NorthboundRatio != nil {
+		t.Error("已调出沪深股通的股票不应有北向持股")
+	}
+}
+
+func TestCriteriaMatch(t *testing.T) {
+	roe := 0.15
+	listDays := 30
+	quality := 70
+	row := &Row{Exchange: "SH", Close: 10, AvgAmount: 5e7, ROE: &roe, ListDays: &listDays, Quality: &quality}
+
+	atLeast := func(v float64) Range { return Range{Min: &v} }
+	tests := []struct {
+		name     string
+		criteria Criteria
+		want     bool
+	}{
+		{"无条件", Criteria{}, true},
+		{"交易所不符", Criteria{Exchange: "SZ"}, false},
+		{"成交额达标", Criteria{AvgAmount: atLeast(1e7)}, true},
+		{"ROE 不足", Criteria{ROE: atLeast(0.2)}, false},
+		{"缺少市值数据", Criteria{MarketCap: atLeast(1)}, false},
+		{"缺少流通市值数据", Criteria{FloatCap: atLeast(1)}, false},
+		{"次新股", Criteria{MinListDays: 60}, false},
+		{"质量评分达标", Criteria{MinQuality: 60}, true},
+		{"质量评分不足", Criteria{MinQuality: 80}, false},
+		{"排除 ST", Criteria{ST: STExclude}, true},
+		{"只保留 ST", Criteria{ST: STOnly}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.criteria.Match(row); got != tt.want {
+			t.Errorf("%s: 期望 %v，实际 %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestSort(t *testing.T) {
+	r1, r2 := 0.1, -0.05
+	rows := []*Row{{Symbol: "A"}, {Symbol: "B", Return: &r2}, {Symbol: "C", Return: &r1}}
+	if err := Sort(rows, SortReturn, true); err != nil {
+		t.Fatal(err)
+	}
+	if rows[0].Symbol != "C" || rows[1].Symbol != "B" || rows[2].Symbol != "A" {
+		t.Errorf("降序排序错误，缺失值应在最后: %s %s %s", rows[0].Symbol, rows[1].Symbol, rows[2].Symbol)
+	}
+	if err := Sort(rows, "unknown", false); err == nil {
+		t.Error("未知排序字段应报错")
+	}
+}
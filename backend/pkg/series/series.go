@@ -0,0 +1,207 @@
+// Package series K线与技术指标序列的列式二进制编码，供量化客户端批量拉取数据。
+// Protobuf 编码与 series.proto 中的消息一致，MessagePack 编码使用相同的字段名。
+package series
+
+import (
+	_ "embed"
+	"math"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// 二进制响应的媒体类型
+const (
+	MIMEProtobuf = "application/x-protobuf"
+	MIMEMsgPack  = "application/x-msgpack"
+)
+
+// Proto 序列消息的 Protobuf 定义，由行情服务对外提供
+//
+//go:embed series.proto
+var Proto string
+
+// Message 可编码为 Protobuf 的序列
+type Message interface {
+	MarshalProto() []byte
+}
+
+// msgpackHandle 按新版规范编码（str/bin 类型），主流 MessagePack 库都能直接解析
+var msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+
+// MarshalMsgPack 编码为 MessagePack，字段名与 series.proto 一致
+func MarshalMsgPack(m Message) ([]byte, error) {
+	var b []byte
+	err := codec.NewEncoderBytes(&b, msgpackHandle).Encode(m)
+	return b, err
+}
+
+// Negotiate 按 Accept 头的先后顺序选择二进制格式，返回 MIMEProtobuf 或 MIMEMsgPack，
+// 客户端未请求二进制格式（含 */*、application/json）时返回空字符串。
+func Negotiate(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case MIMEProtobuf, "application/protobuf", "application/vnd.google.protobuf":
+			return MIMEProtobuf
+		case MIMEMsgPack, "application/msgpack", "application/vnd.msgpack":
+			return MIMEMsgPack
+		case "application/json", "*/*", "application/*":
+			return ""
+		}
+	}
+	return ""
+}
+
+// ============ K线 ============
+
+// Kline K线序列，各列按下标对齐
+type Kline struct {
+	Symbol   string    `codec:"symbol"`
+	Exchange string    `codec:"exchange"`
+	Period   string    `codec:"period"`
+	Time     []int64   `codec:"time"` // Unix 秒
+	Open     []float64 `codec:"open"`
+	High     []float64 `codec:"high"`
+	Low      []float64 `codec:"low"`
+	Close    []float64 `codec:"close"`
+	Volume   []int64   `codec:"volume"`
+	Amount   []float64 `codec:"amount"`
+}
+
+// NewKline 创建K线序列，n 为预分配的条数
+func NewKline(symbol, exchange, period string, n int) *Kline {
+	return &Kline{
+		Symbol: symbol, Exchange: exchange, Period: period,
+		Time: make([]int64, 0, n), Open: make([]float64, 0, n), High: make([]float64, 0, n),
+		Low: make([]float64, 0, n), Close: make([]float64, 0, n), Volume: make([]int64, 0, n),
+		Amount: make([]float64, 0, n),
+	}
+}
+
+// Append 追加一根K线
+func (k *Kline) Append(t time.Time, open, high, low, close float64, volume int64, amount float64) {
+	k.Time = append(k.Time, t.Unix())
+	k.Open = append(k.Open, open)
+	k.High = append(k.High, high)
+	k.Low = append(k.Low, low)
+	k.Close = append(k.Close, close)
+	k.Volume = append(k.Volume, volume)
+	k.Amount = append(k.Amount, amount)
+}
+
+// MarshalProto 编码为 KlineSeries
+func (k *Kline) MarshalProto() []byte {
+	b := make([]byte, 0, 64+len(k.Time)*56)
+	b = appendString(b, 1, k.Symbol)
+	b = appendString(b, 2, k.Exchange)
+	b = appendString(b, 3, k.Period)
+	b = appendInt64s(b, 4, k.Time)
+	b = appendDoubles(b, 5, k.Open)
+	b = appendDoubles(b, 6, k.High)
+	b = appendDoubles(b, 7, k.Low)
+	b = appendDoubles(b, 8, k.Close)
+	b = appendInt64s(b, 9, k.Volume)
+	b = appendDoubles(b, 10, k.Amount)
+	return b
+}
+
+// ============ 技术指标 ============
+
+// Indicators 技术指标序列，每列与 Time 对齐，缺失值为 NaN
+type Indicators struct {
+	Symbol   string    `codec:"symbol"`
+	Exchange string    `codec:"exchange"`
+	Time     []int64   `codec:"time"`
+	Columns  []*Column `codec:"columns"`
+}
+
+// Column 一列指标值
+type Column struct {
+	Name   string    `codec:"name"`
+	Values []float64 `codec:"values"`
+}
+
+// NewIndicators 创建技术指标序列，列按 names 的顺序排列
+func NewIndicators(symbol, exchange string, names []string, n int) *Indicators {
+	s := &Indicators{Symbol: symbol, Exchange: exchange, Time: make([]int64, 0, n)}
+	for _, name := range names {
+		s.Columns = append(s.Columns, &Column{Name: name, Values: make([]float64, 0, n)})
+	}
+	return s
+}
+
+// Append 追加一行，values 按列的顺序排列，缺失值传 math.NaN()
+func (s *Indicators) Append(t time.Time, values ...float64) {
+	s.Time = append(s.Time, t.Unix())
+	for i, col := range s.Columns {
+		v := math.NaN()
+		if i < len(values) {
+			v = values[i]
+		}
+		col.Values = append(col.Values, v)
+	}
+}
+
+// MarshalProto 编码为 IndicatorSeries
+func (s *Indicators) MarshalProto() []byte {
+	b := make([]byte, 0, 64+len(s.Time)*(8+8*len(s.Columns)))
+	b = appendString(b, 1, s.Symbol)
+	b = appendString(b, 2, s.Exchange)
+	b = appendInt64s(b, 3, s.Time)
+	for _, col := range s.Columns {
+		var c []byte
+		c = appendString(c, 1, col.Name)
+		c = appendDoubles(c, 2, col.Values)
+		b = protowire.AppendTag(b, 4, protowire.BytesType)
+		b = protowire.AppendBytes(b, c)
+	}
+	return b
+}
+
+// ============ Protobuf 编码 ============
+
+// appendString 编码 string 字段，空字符串按 proto3 规则省略
+func appendString(b []byte, num protowire.Number, v string) []byte {
+	if v == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, v)
+}
+
+// appendInt64s 编码 packed repeated int64 字段
+func appendInt64s(b []byte, num protowire.Number, vs []int64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	size := 0
+	for _, v := range vs {
+		size += protowire.SizeVarint(uint64(v))
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	b = protowire.AppendVarint(b, uint64(size))
+	for _, v := range vs {
+		b = protowire.AppendVarint(b, uint64(v))
+	}
+	return b
+}
+
+// appendDoubles 编码 packed repeated double 字段
+func appendDoubles(b []byte, num protowire.Number, vs []float64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	b = protowire.AppendVarint(b, uint64(8*len(vs)))
+	for _, v := range vs {
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	}
+	return b
+}
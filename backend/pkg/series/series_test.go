@@ -0,0 +1,128 @@
+package series
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                                      "",
+		"application/json":                      "",
+		"*/*":                                   "",
+		"application/x-protobuf":                MIMEProtobuf,
+		"application/protobuf; q=0.9":           MIMEProtobuf,
+		"application/x-msgpack, */*;q=0.1":      MIMEMsgPack,
+		"application/json, application/msgpack": "",
+		"text/html, application/vnd.msgpack":    MIMEMsgPack,
+	}
+	for accept, want := range cases {
+		if got := Negotiate(accept); got != want {
+			t.Errorf("Negotiate(%q) = %q, 期望 %q", accept, got, want)
+		}
+	}
+}
+
+// fields 解析一层 Protobuf 消息，按字段号收集原始值
+func fields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	t.Helper()
+	out := make(map[protowire.Number][][]byte)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 || typ != protowire.BytesType {
+			t.Fatalf("字段格式错误: num=%d typ=%d", num, typ)
+		}
+		b = b[n:]
+		v, n := protowire.ConsumeBytes(b)
+		if n < 0 {
+			t.Fatal("字段长度错误")
+		}
+		out[num] = append(out[num], v)
+		b = b[n:]
+	}
+	return out
+}
+
+func doubles(b []byte) []float64 {
+	var vs []float64
+	for len(b) > 0 {
+		v, n := protowire.ConsumeFixed64(b)
+		vs = append(vs, math.Float64frombits(v))
+		b = b[n:]
+	}
+	return vs
+}
+
+func varints(b []byte) []int64 {
+	var vs []int64
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		vs = append(vs, int64(v))
+		b = b[n:]
+	}
+	return vs
+}
+
+func TestKlineProto(t *testing.T) {
+	k := NewKline("600000", "SH", "1d", 2)
+	d := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	k.Append(d, 10, 11, 9.5, 10.5, 1000, 10500)
+	k.Append(d.AddDate(0, 0, 1), 10.5, 10.8, 10.1, 10.2, 2000, 20400)
+
+	f := fields(t, k.MarshalProto())
+	if string(f[1][0]) != "600000" || string(f[2][0]) != "SH" || string(f[3][0]) != "1d" {
+		t.Errorf("基本字段错误: %q %q %q", f[1], f[2], f[3])
+	}
+	if ts := varints(f[4][0]); len(ts) != 2 || ts[0] != d.Unix() || ts[1] != d.AddDate(0, 0, 1).Unix() {
+		t.Errorf("time 错误: %v", ts)
+	}
+	if cl := doubles(f[8][0]); len(cl) != 2 || cl[1] != 10.2 {
+		t.Errorf("close 错误: %v", cl)
+	}
+	if vol := varints(f[9][0]); vol[1] != 2000 {
+		t.Errorf("volume 错误: %v", vol)
+	}
+}
+
+func TestIndicatorsProto(t *testing.T) {
+	s := NewIndicators("000001", "SZ", []string{"ma.ma5", "macd.hist"}, 2)
+	d := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	s.Append(d, 10.1, math.NaN())
+	s.Append(d.AddDate(0, 0, 1), 10.2)
+
+	f := fields(t, s.MarshalProto())
+	if len(f[4]) != 2 {
+		t.Fatalf("期望 2 列，实际 %d", len(f[4]))
+	}
+	col := fields(t, f[4][1])
+	values := doubles(col[2][0])
+	if string(col[1][0]) != "macd.hist" || len(values) != 2 || !math.IsNaN(values[0]) || !math.IsNaN(values[1]) {
+		t.Errorf("缺失值应编码为 NaN: %s %v", col[1][0], values)
+	}
+}
+
+func TestKlineMsgPack(t *testing.T) {
+	k := NewKline("600000", "SH", "5m", 1)
+	k.Append(time.Unix(1704159000, 0), 10, 11, 9.5, 10.5, 1000, 10500)
+
+	b, err := MarshalMsgPack(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := new(codec.MsgpackHandle)
+	h.RawToString = true
+	var m map[string]interface{}
+	if err := codec.NewDecoderBytes(b, h).Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	if m["symbol"] != "600000" || m["period"] != "5m" {
+		t.Errorf("字段名应与 proto 一致: %v", m)
+	}
+	if closes, ok := m["close"].([]interface{}); !ok || len(closes) != 1 || closes[0] != 10.5 {
+		t.Errorf("close 错误: %#v", m["close"])
+	}
+}
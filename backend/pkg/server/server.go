@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/internalauth"
+	"stock-analysis-system/backend/pkg/metrics"
+	"stock-analysis-system/backend/pkg/middleware"
+)
+
+// HealthCheck 健康检查函数，返回 nil 表示健康
+type HealthCheck func(ctx context.Context) error
+
+// ShutdownHook 关闭钩子，在 HTTP 服务停止后按注册的逆序执行
+type ShutdownHook func(ctx context.Context)
+
+// Option 服务选项
+type Option func(*Server)
+
+// Server 通用 HTTP 服务：统一路由初始化、中间件、健康检查与优雅退出
+type Server struct {
+	name            string
+	port            string
+	engine          *gin.Engine
+	logger          gin.HandlerFunc
+	middlewares     []gin.HandlerFunc
+	healthChecks    map[string]HealthCheck
+	optionalChecks  map[string]bool // 失败时只报告降级的检查项
+	healthHandler   gin.HandlerFunc
+	shutdownHooks   []ShutdownHook
+	shutdownTimeout time.Duration
+	drainTimeout    time.Duration
+	streams         *Streams
+	readTimeout     time.Duration
+	writeTimeout    time.Duration
+	idleTimeout     time.Duration
+	maxBodySize     int64
+	tls             *TLS
+	internalAuth    bool // 校验网关签名（配置了 INTERNAL_AUTH_SECRET 时默认开启）
+}
+
+// WithPort 设置监听端口
+func WithPort(port string) Option {
+	return func(s *Server) {
+		s.port = port
+	}
+}
+
+// WithMiddleware 追加全局中间件（在 Recovery、请求ID、请求日志和请求体限制之后执行）
+func WithMiddleware(mw ...gin.HandlerFunc) Option {
+	return func(s *Server) {
+		s.middlewares = append(s.middlewares, mw...)
+	}
+}
+
+// WithRequestLogger 替换默认的请求日志中间件
+func WithRequestLogger(logger gin.HandlerFunc) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithHealthCheck 注册健康检查项，任一项失败时 /health 返回 503
+func WithHealthCheck(name string, check HealthCheck) Option {
+	return func(s *Server) {
+		s.healthChecks[name] = check
+	}
+}
+
+// WithOptionalHealthCheck 注册可选的健康检查项，失败时 /health 仍返回 200，状态为 degraded
+func WithOptionalHealthCheck(name string, check HealthCheck) Option {
+	return func(s *Server) {
+		s.healthChecks[name] = check
+		s.optionalChecks[name] = true
+	}
+}
+
+// WithDatabaseHealth 将已连接的 PostgreSQL / InfluxDB 注册为健康检查项
+// InfluxDB 为可选依赖（database.OptionalInflux）时注册为可选检查项。
+func WithDatabaseHealth(m *database.Manager) Option {
+	return func(s *Server) {
+		if m.Postgres != nil {
+			s.healthChecks["postgres"] = m.Postgres.HealthCheck
+		}
+		if m.Influx != nil {
+			s.healthChecks["influxdb"] = m.Influx.HealthCheck
+			s.optionalChecks["influxdb"] = m.InfluxOptional()
+		}
+	}
+}
+
+// WithHealthHandler 使用自定义的 /health 处理函数
+func WithHealthHandler(handler gin.HandlerFunc) Option {
+	return func(s *Server) {
+		s.healthHandler = handler
+	}
+}
+
+// WithShutdownHook 注册关闭钩子
+func WithShutdownHook(hook ShutdownHook) Option {
+	return func(s *Server) {
+		s.shutdownHooks = append(s.shutdownHooks, hook)
+	}
+}
+
+// WithShutdownTimeout 设置等待处理中请求完成的超时时间
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// WithDrainTimeout 设置退出时等待 WebSocket/SSE 长连接重连离开的最长时间
+func WithDrainTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.drainTimeout = timeout
+	}
+}
+
+// WithWriteTimeout 覆盖配置中的写超时，用于存在长耗时接口的服务
+func WithWriteTimeout(timeout time.Duration) Option {
+	return func(s *Server) {
+		s.writeTimeout = timeout
+	}
+}
+
+// WithoutInternalAuth 不校验网关签名，用于直接面向客户端的网关本身
+func WithoutInternalAuth() Option {
+	return func(s *Server) {
+		s.internalAuth = false
+	}
+}
+
+// WithMaxBodySize 覆盖配置中的请求体大小上限
+func WithMaxBodySize(maxBytes int64) Option {
+	return func(s *Server) {
+		s.maxBodySize = maxBytes
+	}
+}
+
+// New 创建服务
+func New(name string, cfg *config.Config, opts ...Option) *Server {
+	s := &Server{
+		name:            name,
+		port:            "8080",
+		logger:          middleware.RequestLogger(),
+		healthChecks:    make(map[string]HealthCheck),
+		optionalChecks:  make(map[string]bool),
+		shutdownTimeout: 5 * time.Second,
+		drainTimeout:    defaultDrainTimeout,
+		readTimeout:     time.Duration(cfg.Server.ReadTimeout) * time.Second,
+		writeTimeout:    time.Duration(cfg.Server.WriteTimeout) * time.Second,
+		idleTimeout:     time.Duration(cfg.Server.IdleTimeout) * time.Second,
+		maxBodySize:     cfg.Server.MaxBodySize,
+		internalAuth:    cfg.Internal.Secret != "",
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.streams = newStreams(name, defaultRetryAfter)
+
+	if cfg.Server.Mode == "production" || cfg.Server.Mode == "release" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	s.engine = gin.New()
+	s.engine.Use(gin.Recovery())
+	s.engine.Use(middleware.RequestID())
+	if s.logger != nil {
+		s.engine.Use(s.logger)
+	}
+	if s.internalAuth {
+		secrets := append([]string{cfg.Internal.Secret}, cfg.Internal.PreviousSecrets...)
+		s.engine.Use(middleware.InternalAuth(internalauth.NewVerifier(secrets, time.Duration(cfg.Internal.MaxSkew)*time.Second)))
+	}
+	s.engine.Use(middleware.BodyLimit(s.maxBodySize))
+	s.engine.Use(s.middlewares...)
+
+	if s.healthHandler != nil {
+		s.engine.GET("/health", s.healthHandler)
+	} else {
+		s.engine.GET("/health", s.health)
+	}
+	s.engine.GET("/metrics", gin.WrapH(metrics.Handler()))
+
+	return s
+}
+
+// Router 返回路由，供服务注册业务接口
+func (s *Server) Router() *gin.Engine {
+	return s.engine
+}
+
+// Streams 长连接登记，流式接口通过 Streams().Middleware 注册后在退出时被通知重连
+func (s *Server) Streams() *Streams {
+	return s.streams
+}
+
+// health 默认健康检查接口
+// 必需的检查项失败时返回 503（unhealthy）；只有可选检查项失败时返回 200（degraded），服务以降级模式继续提供接口。
+// 退出时排空长连接期间返回 503（draining），负载均衡据此停止转发新请求。
+func (s *Server) health(c *gin.Context) {
+	ctx := c.Request.Context()
+	if s.streams.Draining() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "draining",
+			"service":   s.name,
+			"timestamp": time.Now().Unix(),
+		})
+		return
+	}
+
+	status := "healthy"
+	code := http.StatusOK
+	checks := make(map[string]string, len(s.healthChecks))
+	for name, check := range s.healthChecks {
+		if err := check(ctx); err != nil {
+			checks[name] = err.Error()
+			if !s.optionalChecks[name] {
+				status = "unhealthy"
+				code = http.StatusServiceUnavailable
+			} else if status == "healthy" {
+				status = "degraded"
+			}
+		} else {
+			checks[name] = "ok"
+		}
+	}
+
+	c.JSON(code, gin.H{
+		"status":    status,
+		"service":   s.name,
+		"checks":    checks,
+		"timestamp": time.Now().Unix(),
+	})
+}
+
+// Run 启动服务并阻塞，收到 SIGINT/SIGTERM 后优雅退出：
+// 先通知 WebSocket/SSE 长连接重连并等待其结束（最长 drainTimeout），再等待处理中的普通请求完成。
+func (s *Server) Run() error {
+	srv := &http.Server{
+		Addr:              ":" + s.port,
+		Handler:           s.engine,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       s.readTimeout,
+		WriteTimeout:      s.writeTimeout,
+		IdleTimeout:       s.idleTimeout,
+	}
+
+	errChan := make(chan error, 2)
+	redirectSrv := s.listen(srv, errChan)
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
+
+	select {
+	case err := <-errChan:
+		if redirectSrv != nil {
+			redirectSrv.Close()
+		}
+		srv.Close()
+		s.runShutdownHooks(context.Background())
+		return err
+	case <-sigChan:
+	}
+
+	log.Printf("正在关闭 %s...", s.name)
+
+	if n := s.streams.Active(); n > 0 {
+		log.Printf("通知 %d 个长连接重连", n)
+	}
+	if remaining := s.streams.drain(s.drainTimeout); remaining > 0 {
+		log.Printf("%d 个长连接未在 %s 内结束，将被强制关闭", remaining, s.drainTimeout)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(ctx)
+	}
+	err := srv.Shutdown(ctx)
+	if err != nil {
+		log.Printf("服务关闭失败: %v", err)
+	}
+
+	s.runShutdownHooks(context.Background())
+	return err
+}
+
+// runShutdownHooks 按注册的逆序执行关闭钩子
+func (s *Server) runShutdownHooks(ctx context.Context) {
+	for i := len(s.shutdownHooks) - 1; i >= 0; i-- {
+		s.shutdownHooks[i](ctx)
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"stock-analysis-system/backend/pkg/metrics"
+)
+
+// ============ 长连接排空 ============
+
+// CloseServiceRestart WebSocket 关闭码 1012（服务重启），客户端应稍后重连
+const CloseServiceRestart = 1012
+
+// 排空默认值
+const (
+	defaultDrainTimeout = 10 * time.Second // 通知后等待长连接自行结束的最长时间
+	defaultRetryAfter   = 3 * time.Second  // 建议客户端重连的最短等待时间，实际附加同等范围内的随机抖动
+)
+
+// streamKey 当前请求的长连接登记在 gin.Context 中的键
+const streamKey = "server_stream"
+
+// ErrDraining 服务正在关闭，不再接受新的长连接
+var ErrDraining = errors.New("服务正在关闭")
+
+// Reconnect 排空时发给客户端的重连提示
+type Reconnect struct {
+	Reason     string `json:"reason"`
+	RetryAfter int    `json:"retry_after"` // 秒
+}
+
+// Streams 跟踪 WebSocket、SSE 等长连接。收到退出信号后先通知这些连接重连并等待其结束，再关闭 HTTP 服务：
+// http.Server.Shutdown 不会等待已劫持的 WebSocket 连接，SSE 连接则会一直占到关闭超时后被强制断开。
+type Streams struct {
+	service    string
+	retryAfter time.Duration
+
+	mu       sync.Mutex
+	active   map[*Stream]struct{}
+	draining bool
+	drained  chan struct{} // draining 后最后一个连接结束时关闭
+}
+
+func newStreams(service string, retryAfter time.Duration) *Streams {
+	t := &Streams{service: service, retryAfter: retryAfter, active: make(map[*Stream]struct{})}
+	metrics.Register("server_streams", t.collect)
+	return t
+}
+
+// Stream 一个长连接，方法均可在 nil 上调用（处理函数未经 Streams.Middleware 登记时）
+type Stream struct {
+	streams *Streams
+	kind    string
+	drain   chan struct{}
+}
+
+// Open 登记长连接，kind 标识接口类型（如 sse、websocket），服务正在关闭时返回 ErrDraining
+func (t *Streams) Open(kind string) (*Stream, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return nil, ErrDraining
+	}
+	stream := &Stream{streams: t, kind: kind, drain: make(chan struct{})}
+	t.active[stream] = struct{}{}
+	return stream, nil
+}
+
+// Middleware 登记经过的请求为长连接，处理函数通过 StreamFrom 取得；服务正在关闭时返回 503 与 Retry-After
+func (t *Streams) Middleware(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stream, err := t.Open(kind)
+		if err != nil {
+			c.Header("Retry-After", strconv.Itoa(int(t.retryAfter/time.Second)))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "服务正在重启，请稍后重连"})
+			return
+		}
+		defer stream.Close()
+		c.Set(streamKey, stream)
+		c.Next()
+	}
+}
+
+// Draining 是否正在排空
+func (t *Streams) Draining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// Active 当前长连接数
+func (t *Streams) Active() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.active)
+}
+
+// drain 拒绝新连接并通知现有连接重连，等待全部结束或超时，返回超时时仍未结束的连接数
+func (t *Streams) drain(timeout time.Duration) int {
+	t.mu.Lock()
+	if !t.draining {
+		t.draining = true
+		t.drained = make(chan struct{})
+		for stream := range t.active {
+			close(stream.drain)
+		}
+		if len(t.active) == 0 {
+			close(t.drained)
+		}
+	}
+	t.mu.Unlock()
+
+	select {
+	case <-t.drained:
+	case <-time.After(timeout):
+	}
+	return t.Active()
+}
+
+func (t *Streams) remove(stream *Stream) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.active[stream]; !ok {
+		return
+	}
+	delete(t.active, stream)
+	if t.draining && len(t.active) == 0 {
+		close(t.drained)
+	}
+}
+
+// collect 长连接数指标
+func (t *Streams) collect() []metrics.Sample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counts := make(map[string]int)
+	for stream := range t.active {
+		counts[stream.kind]++
+	}
+	samples := make([]metrics.Sample, 0, len(counts))
+	for kind, n := range counts {
+		samples = append(samples, metrics.Sample{
+			Name:   "server_streams_active",
+			Help:   "当前 WebSocket/SSE 长连接数",
+			Type:   metrics.TypeGauge,
+			Labels: map[string]string{"service": t.service, "kind": kind},
+			Value:  float64(n),
+		})
+	}
+	return samples
+}
+
+// StreamFrom 取得 Streams.Middleware 登记的长连接，未登记时返回 nil
+func StreamFrom(c *gin.Context) *Stream {
+	value, _ := c.Get(streamKey)
+	stream, _ := value.(*Stream)
+	return stream
+}
+
+// Draining 服务开始排空时关闭，处理函数应在收到后发送重连提示并结束连接
+func (s *Stream) Draining() <-chan struct{} {
+	if s == nil {
+		return nil
+	}
+	return s.drain
+}
+
+// Close 注销长连接，重复调用无副作用
+func (s *Stream) Close() {
+	if s == nil {
+		return
+	}
+	s.streams.remove(s)
+}
+
+// Hint 重连提示，等待时间附加随机抖动，避免所有客户端同时重连到剩余实例
+func (s *Stream) Hint() Reconnect {
+	retry := defaultRetryAfter
+	if s != nil {
+		retry = s.streams.retryAfter
+	}
+	retry += time.Duration(rand.Int63n(int64(retry) + 1))
+	return Reconnect{Reason: "shutdown", RetryAfter: int((retry + time.Second - 1) / time.Second)}
+}
+
+// WriteSSE 发送 SSE 重连提示：retry 字段设置浏览器 EventSource 的自动重连间隔，随后发送 reconnect 事件
+func (s *Stream) WriteSSE(w gin.ResponseWriter) error {
+	hint := s.Hint()
+	data, err := json.Marshal(hint)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "retry: %d\nevent: reconnect\ndata: %s\n\n", hint.RetryAfter*1000, data); err != nil {
+		return err
+	}
+	w.Flush()
+	return nil
+}
+
+// CloseWebSocket 发送关闭码为 1012 的关闭帧，原因为 JSON 格式的重连提示（不超过关闭帧 123 字节的限制）；
+// 调用方需保证没有并发写入。
+func CloseWebSocket(ws *websocket.Conn, hint Reconnect) error {
+	reason, err := json.Marshal(hint)
+	if err != nil {
+		return err
+	}
+	w, err := ws.NewFrameWriter(websocket.CloseFrame)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+	payload := make([]byte, 2, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, CloseServiceRestart)
+	_, err = w.Write(append(payload, reason...))
+	return err
+}
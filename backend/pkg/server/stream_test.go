@@ -0,0 +1,91 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamsDrain(t *testing.T) {
+	streams := newStreams("test", time.Second)
+	stream, err := streams.Open("sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 连接收到通知后结束
+	go func() {
+		<-stream.Draining()
+		stream.Close()
+	}()
+	if remaining := streams.drain(time.Second); remaining != 0 {
+		t.Fatalf("remaining = %d, 连接收到通知后应全部结束", remaining)
+	}
+	if _, err := streams.Open("sse"); !errors.Is(err, ErrDraining) {
+		t.Errorf("排空期间应拒绝新连接, got %v", err)
+	}
+}
+
+func TestStreamsDrainTimeout(t *testing.T) {
+	streams := newStreams("test", time.Second)
+	stream, _ := streams.Open("websocket")
+	if remaining := streams.drain(50 * time.Millisecond); remaining != 1 {
+		t.Fatalf("remaining = %d, 未结束的连接应计入", remaining)
+	}
+	stream.Close()
+	stream.Close()
+	if streams.Active() != 0 {
+		t.Error("Close 后应注销")
+	}
+}
+
+func TestStreamsMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	streams := newStreams("test", 2*time.Second)
+	router := gin.New()
+	router.GET("/stream", streams.Middleware("sse"), func(c *gin.Context) {
+		stream := StreamFrom(c)
+		if stream == nil {
+			t.Fatal("处理函数应能取得登记的连接")
+		}
+		close(stream.drain) // 模拟排空通知
+		stream.WriteSSE(c.Writer)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	body := w.Body.String()
+	if !strings.HasPrefix(body, "retry: ") || !strings.Contains(body, "event: reconnect\ndata: {\"reason\":\"shutdown\"") {
+		t.Errorf("重连提示 = %q", body)
+	}
+	if streams.Active() != 0 {
+		t.Error("请求结束后应注销连接")
+	}
+
+	streams.drain(0)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("排空期间 code = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+}
+
+func TestStreamHint(t *testing.T) {
+	streams := newStreams("test", 2*time.Second)
+	stream, _ := streams.Open("sse")
+	for i := 0; i < 20; i++ {
+		if hint := stream.Hint(); hint.RetryAfter < 2 || hint.RetryAfter > 4 {
+			t.Fatalf("retry_after = %d, 应在 [2, 4] 秒内", hint.RetryAfter)
+		}
+	}
+	var none *Stream
+	if none.Draining() != nil || none.Hint().RetryAfter < 3 {
+		t.Error("nil 连接应使用默认值")
+	}
+	none.Close()
+}
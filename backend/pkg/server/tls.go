@@ -0,0 +1,125 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// TLS HTTPS 配置：证书文件或通过 Let's Encrypt 自动申请（autocert），二选一
+type TLS struct {
+	CertFile string
+	KeyFile  string
+
+	AutocertDomains  []string // 自动申请证书的域名，需能从公网访问 RedirectPort（HTTP-01 验证）与 HTTPS 端口
+	AutocertEmail    string   // 证书到期等通知的联系邮箱，可选
+	AutocertCacheDir string   // 证书缓存目录，重启后复用已申请的证书
+
+	RedirectPort string // 监听的 HTTP 端口，请求重定向到 HTTPS（autocert 时同时处理验证请求），为空不监听
+	DisableHTTP2 bool   // 只使用 HTTP/1.1
+}
+
+// Validate 检查证书来源
+func (t *TLS) Validate() error {
+	hasFiles := t.CertFile != "" || t.KeyFile != ""
+	if hasFiles && len(t.AutocertDomains) > 0 {
+		return errors.New("TLS 证书文件与 autocert 只能配置一种")
+	}
+	if hasFiles && (t.CertFile == "" || t.KeyFile == "") {
+		return errors.New("TLS 需同时配置证书与私钥文件")
+	}
+	if !hasFiles && len(t.AutocertDomains) == 0 {
+		return errors.New("TLS 需配置证书文件或 autocert 域名")
+	}
+	if len(t.AutocertDomains) > 0 && t.AutocertCacheDir == "" {
+		return errors.New("autocert 需配置证书缓存目录")
+	}
+	return nil
+}
+
+// WithTLS 以 HTTPS 提供服务，默认同时支持 HTTP/2；t 为 nil 时使用 HTTP
+func WithTLS(t *TLS) Option {
+	return func(s *Server) {
+		s.tls = t
+	}
+}
+
+// listen 按配置启动 HTTP 或 HTTPS 服务，HTTPS 时返回重定向服务（未配置时为 nil）
+func (s *Server) listen(srv *http.Server, errChan chan<- error) *http.Server {
+	if s.tls == nil {
+		go func() {
+			log.Printf("%s 启动在端口 %s", s.name, s.port)
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errChan <- err
+			}
+		}()
+		return nil
+	}
+
+	certFile, keyFile := s.tls.CertFile, s.tls.KeyFile
+	srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	redirect := http.Handler(http.HandlerFunc(redirectHTTPS(s.port)))
+	if len(s.tls.AutocertDomains) > 0 {
+		m := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(s.tls.AutocertDomains...),
+			Cache:      autocert.DirCache(s.tls.AutocertCacheDir),
+			Email:      s.tls.AutocertEmail,
+		}
+		srv.TLSConfig = m.TLSConfig()
+		srv.TLSConfig.MinVersion = tls.VersionTLS12
+		redirect = m.HTTPHandler(redirect)
+	}
+	if s.tls.DisableHTTP2 {
+		// 非 nil 的空映射会关闭 net/http 自动启用的 HTTP/2
+		srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
+	go func() {
+		log.Printf("%s 启动在端口 %s（HTTPS）", s.name, s.port)
+		if err := srv.ListenAndServeTLS(certFile, keyFile); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+
+	if s.tls.RedirectPort == "" {
+		return nil
+	}
+	redirectSrv := &http.Server{
+		Addr:              ":" + s.tls.RedirectPort,
+		Handler:           redirect,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+	}
+	go func() {
+		log.Printf("%s 在端口 %s 将 HTTP 请求重定向到 HTTPS", s.name, s.tls.RedirectPort)
+		if err := redirectSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+	return redirectSrv
+}
+
+// redirectHTTPS 将请求永久重定向到同一主机的 HTTPS 端口（443 时省略端口）
+func redirectHTTPS(port string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if port != "443" {
+			host = net.JoinHostPort(host, port)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6 地址
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusMovedPermanently)
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTLSValidate(t *testing.T) {
+	tests := []struct {
+		tls     TLS
+		wantErr bool
+	}{
+		{TLS{CertFile: "cert.pem", KeyFile: "key.pem"}, false},
+		{TLS{AutocertDomains: []string{"example.com"}, AutocertCacheDir: "certs"}, false},
+		{TLS{}, true},
+		{TLS{CertFile: "cert.pem"}, true},
+		{TLS{CertFile: "cert.pem", KeyFile: "key.pem", AutocertDomains: []string{"example.com"}, AutocertCacheDir: "certs"}, true},
+		{TLS{AutocertDomains: []string{"example.com"}}, true},
+	}
+	for i, tt := range tests {
+		if err := tt.tls.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("#%d: err = %v", i, err)
+		}
+	}
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		port, host, want string
+	}{
+		{"443", "example.com", "https://example.com/api/v1/market?x=1"},
+		{"443", "example.com:80", "https://example.com/api/v1/market?x=1"},
+		{"8443", "example.com:8080", "https://example.com:8443/api/v1/market?x=1"},
+		{"443", "[::1]:80", "https://[::1]/api/v1/market?x=1"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "http://"+tt.host+"/api/v1/market?x=1", nil)
+		w := httptest.NewRecorder()
+		redirectHTTPS(tt.port)(w, req)
+		if w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != tt.want {
+			t.Errorf("%s %s: status = %d, location = %s", tt.port, tt.host, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
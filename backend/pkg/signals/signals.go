@@ -0,0 +1,100 @@
+// Package signals 交易信号聚合：在信号保存前按用户规则去重并处理多策略之间的方向冲突
+package signals
+
+import (
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// Entry 参与聚合的信号及其所属策略的优先级
+type Entry struct {
+	Signal   *models.TradeSignal
+	Priority int
+}
+
+// Decision 对新信号的处理结果
+type Decision struct {
+	Accept     bool   // 是否保存新信号
+	Reason     string // 新信号未保存的原因
+	Cancel     *Entry // 需要撤销的已有信号
+	CancelNote string // 已有信号的撤销原因
+}
+
+// Opposes 判断两个信号方向是否相反：买入与卖出、买入与平仓
+func Opposes(a, b string) bool {
+	switch a {
+	case "buy":
+		return b == "sell" || b == "close"
+	case "sell", "close":
+		return b == "buy"
+	}
+	return false
+}
+
+// Decide 根据规则判断新信号的处理方式
+// pool 为同一用户近期的有效信号（含本批已接受的信号）；去重只比较 DedupHours 小时内的信号，
+// 冲突只比较同一自然日内来自其他策略的信号，同一策略自身的反向信号视为正常的开平仓。
+func Decide(policy *models.SignalPolicy, pool []*Entry, incoming *Entry, now time.Time) Decision {
+	sig := incoming.Signal
+
+	if policy.DedupHours > 0 {
+		since := now.Add(-time.Duration(policy.DedupHours) * time.Hour)
+		for _, e := range pool {
+			s := e.Signal
+			if sameSymbol(s, sig) && s.SignalType == sig.SignalType && !s.CreatedAt.Before(since) {
+				return Decision{Reason: fmt.Sprintf("%d 小时内已有相同信号（策略 %d）", policy.DedupHours, s.StrategyID)}
+			}
+		}
+	}
+
+	if policy.ConflictPolicy == models.ConflictPolicyNone || policy.ConflictPolicy == "" {
+		return Decision{Accept: true}
+	}
+
+	day := now.Format("2006-01-02")
+	for _, e := range pool {
+		s := e.Signal
+		if !sameSymbol(s, sig) || s.StrategyID == sig.StrategyID || !Opposes(s.SignalType, sig.SignalType) ||
+			s.CreatedAt.Format("2006-01-02") != day {
+			continue
+		}
+
+		switch policy.ConflictPolicy {
+		case models.ConflictPolicyNet:
+			return Decision{
+				Reason:     fmt.Sprintf("与策略 %d 的 %s 信号对冲抵消", s.StrategyID, s.SignalType),
+				Cancel:     e,
+				CancelNote: fmt.Sprintf("与策略 %d 的 %s 信号对冲抵消", sig.StrategyID, sig.SignalType),
+			}
+		case models.ConflictPolicyPriority:
+			if incoming.Priority > e.Priority {
+				return Decision{
+					Accept:     true,
+					Cancel:     e,
+					CancelNote: fmt.Sprintf("被优先级更高的策略 %d 的 %s 信号覆盖", sig.StrategyID, sig.SignalType),
+				}
+			}
+			return Decision{Reason: fmt.Sprintf("与优先级不低于本策略的策略 %d 的 %s 信号冲突", s.StrategyID, s.SignalType)}
+		}
+	}
+	return Decision{Accept: true}
+}
+
+// ValidPolicy 校验冲突处理策略与去重时长
+func ValidPolicy(policy *models.SignalPolicy) error {
+	switch policy.ConflictPolicy {
+	case models.ConflictPolicyNone, models.ConflictPolicyNet, models.ConflictPolicyPriority:
+	default:
+		return fmt.Errorf("不支持的冲突处理策略: %s", policy.ConflictPolicy)
+	}
+	if policy.DedupHours < 0 || policy.DedupHours > 24*30 {
+		return fmt.Errorf("dedup_hours 应在 0~720 之间")
+	}
+	return nil
+}
+
+func sameSymbol(a, b *models.TradeSignal) bool {
+	return a.Symbol == b.Symbol && a.Exchange == b.Exchange
+}
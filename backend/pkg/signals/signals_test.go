@@ -0,0 +1,46 @@
+package signals
+
+import (
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 0, 0, 0, time.Local)
+	entry := func(strategyID uint, signalType string, priority int, at time.Time) *Entry {
+		return &Entry{
+			Signal:   &models.TradeSignal{StrategyID: strategyID, Symbol: "600519", Exchange: "SH", SignalType: signalType, CreatedAt: at},
+			Priority: priority,
+		}
+	}
+	buy := entry(1, "buy", 1, now.Add(-time.Hour))
+	pool := []*Entry{buy}
+
+	tests := []struct {
+		name       string
+		policy     models.SignalPolicy
+		incoming   *Entry
+		accept     bool
+		cancelsBuy bool
+	}{
+		{"不处理冲突", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyNone}, entry(2, "sell", 0, now), true, false},
+		{"去重", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyNone, DedupHours: 4}, entry(2, "buy", 0, now), false, false},
+		{"去重窗口外", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyNone, DedupHours: 4}, entry(2, "buy", 0, now.Add(5*time.Hour)), true, false},
+		{"对冲抵消", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyNet}, entry(2, "sell", 0, now), false, true},
+		{"同一策略反向不冲突", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyNet}, entry(1, "sell", 0, now), true, false},
+		{"优先级更高覆盖", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyPriority}, entry(2, "sell", 5, now), true, true},
+		{"优先级相同保留先到", models.SignalPolicy{ConflictPolicy: models.ConflictPolicyPriority}, entry(2, "sell", 1, now), false, false},
+	}
+	for _, tt := range tests {
+		at := now
+		if tt.incoming.Signal.CreatedAt.After(now) {
+			at = tt.incoming.Signal.CreatedAt
+		}
+		d := Decide(&tt.policy, pool, tt.incoming, at)
+		if d.Accept != tt.accept || (d.Cancel == buy) != tt.cancelsBuy {
+			t.Errorf("%s: accept=%v cancel=%v reason=%q", tt.name, d.Accept, d.Cancel != nil, d.Reason)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package snapshot 行情数据快照：将日K线与技术指标导出为 Parquet 文件并上传到 S3 兼容的对象存储，
+// 每个快照附带 manifest.json 记录文件清单，供离线研究使用，也作为 InfluxDB 数据的备份。
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// 快照中的数据类型
+const (
+	TypeDailyBars  = "daily_bars"
+	TypeIndicators = "indicators"
+)
+
+// idPattern 快照ID：起止日期，如 20240102-20240131
+var idPattern = regexp.MustCompile(`^\d{8}-\d{8}$`)
+
+// Manifest 快照清单
+type Manifest struct {
+	ID        string      `json:"id"`
+	Start     string      `json:"start"` // 数据起始日 YYYY-MM-DD
+	End       string      `json:"end"`   // 数据截止日 YYYY-MM-DD（含）
+	CreatedAt time.Time   `json:"created_at"`
+	Files     []FileEntry `json:"files"`
+}
+
+// FileEntry 快照中的一个文件
+type FileEntry struct {
+	Name   string `json:"name"` // 如 daily_bars.parquet
+	Type   string `json:"type"`
+	Key    string `json:"key"` // 对象存储中的完整路径
+	Rows   int64  `json:"rows"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// ID 由起止日期生成快照ID，同一区间重复导出时覆盖原快照
+func ID(start, end time.Time) string {
+	return start.Format("20060102") + "-" + end.Format("20060102")
+}
+
+// ValidID 检查快照ID格式，用于拼接对象路径前的校验
+func ValidID(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// File 查找快照中的文件
+func (m *Manifest) File(name string) (*FileEntry, bool) {
+	for i := range m.Files {
+		if m.Files[i].Name == name {
+			return &m.Files[i], true
+		}
+	}
+	return nil, false
+}
+
+// ============ Parquet 行结构 ============
+
+// DailyBarRow 日K线
+type DailyBarRow struct {
+	Symbol   string  `parquet:"symbol,dict"`
+	Exchange string  `parquet:"exchange,dict"`
+	Date     int32   `parquet:"date,date"`
+	Open     float64 `parquet:"open"`
+	High     float64 `parquet:"high"`
+	Low      float64 `parquet:"low"`
+	Close    float64 `parquet:"close"`
+	Volume   int64   `parquet:"volume"`
+	Amount   float64 `parquet:"amount"`
+}
+
+// IndicatorRow 技术指标，一行对应一只股票一天的一类指标，未计算的字段为 0
+type IndicatorRow struct {
+	Symbol        string  `parquet:"symbol,dict"`
+	Exchange      string  `parquet:"exchange,dict"`
+	Date          int32   `parquet:"date,date"`
+	IndicatorType string  `parquet:"indicator_type,dict"`
+	MA5           float64 `parquet:"ma5"`
+	MA10          float64 `parquet:"ma10"`
+	MA20          float64 `parquet:"ma20"`
+	MA60          float64 `parquet:"ma60"`
+	MACD          float64 `parquet:"macd"`
+	MACDSignal    float64 `parquet:"macd_signal"`
+	MACDHist      float64 `parquet:"macd_hist"`
+	RSI6          float64 `parquet:"rsi6"`
+	RSI12         float64 `parquet:"rsi12"`
+	RSI24         float64 `parquet:"rsi24"`
+	K             float64 `parquet:"k"`
+	D             float64 `parquet:"d"`
+	J             float64 `parquet:"j"`
+	BollUpper     float64 `parquet:"boll_upper"`
+	BollMid       float64 `parquet:"boll_mid"`
+	BollLower     float64 `parquet:"boll_lower"`
+	Period        int32   `parquet:"period"` // atr、cci、dmi、wr、bias 的计算周期
+	ATR           float64 `parquet:"atr"`
+	CCI           float64 `parquet:"cci"`
+	OBV           float64 `parquet:"obv"`
+	PDI           float64 `parquet:"pdi"`
+	MDI           float64 `parquet:"mdi"`
+	ADX           float64 `parquet:"adx"`
+	WR            float64 `parquet:"wr"`
+	BIAS          float64 `parquet:"bias"`
+}
+
+// NewDailyBarRow 转换日K线
+func NewDailyBarRow(bar *models.DailyBar) DailyBarRow {
+	return DailyBarRow{
+		Symbol: bar.Symbol, Exchange: bar.Exchange, Date: epochDays(bar.Date),
+		Open: bar.Open, High: bar.High, Low: bar.Low, Close: bar.Close,
+		Volume: bar.Volume, Amount: bar.Amount,
+	}
+}
+
+// NewIndicatorRow 转换技术指标
+func NewIndicatorRow(ind *models.Indicator) IndicatorRow {
+	return IndicatorRow{
+		Symbol: ind.Symbol, Exchange: ind.Exchange, Date: epochDays(ind.Date), IndicatorType: ind.IndicatorType,
+		MA5: ind.MA5, MA10: ind.MA10, MA20: ind.MA20, MA60: ind.MA60,
+		MACD: ind.MACD, MACDSignal: ind.MACDSignal, MACDHist: ind.MACDHist,
+		RSI6: ind.RSI6, RSI12: ind.RSI12, RSI24: ind.RSI24,
+		K: ind.K, D: ind.D, J: ind.J,
+		BollUpper: ind.BollUpper, BollMid: ind.BollMid, BollLower: ind.BollLower,
+		Period: int32(ind.Period), ATR: ind.ATR, CCI: ind.CCI, OBV: ind.OBV,
+		PDI: ind.PDI, MDI: ind.MDI, ADX: ind.ADX, WR: ind.WR, BIAS: ind.BIAS,
+	}
+}
+
+// epochDays Parquet DATE 类型：自 1970-01-01 起的天数，按数据自身的日期计算
+func epochDays(t time.Time) int32 {
+	y, m, d := t.Date()
+	return int32(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// ============ 文件写入 ============
+
+const writeBatch = 1024
+
+// FileWriter 将行写入本地临时 Parquet 文件（zstd 压缩），写完后再整体上传
+type FileWriter[T any] struct {
+	file   *os.File
+	hash   hash.Hash
+	writer *parquet.GenericWriter[T]
+	buf    []T
+	rows   int64
+}
+
+// NewFileWriter 在临时目录创建 Parquet 文件
+func NewFileWriter[T any](pattern string) (*FileWriter[T], error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	h := sha256.New()
+	return &FileWriter[T]{
+		file:   file,
+		hash:   h,
+		writer: parquet.NewGenericWriter[T](io.MultiWriter(file, h), parquet.Compression(&parquet.Zstd)),
+		buf:    make([]T, 0, writeBatch),
+	}, nil
+}
+
+// Write 追加一行
+func (w *FileWriter[T]) Write(row T) error {
+	w.buf = append(w.buf, row)
+	if len(w.buf) < writeBatch {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *FileWriter[T]) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	n, err := w.writer.Write(w.buf)
+	w.rows += int64(n)
+	w.buf = w.buf[:0]
+	if err != nil {
+		return fmt.Errorf("写入 Parquet 失败: %w", err)
+	}
+	return nil
+}
+
+// Close 写入文件尾并返回文件信息，Name/Type/Key 由调用方填写
+func (w *FileWriter[T]) Close() (FileEntry, error) {
+	if err := w.flush(); err != nil {
+		return FileEntry{}, err
+	}
+	if err := w.writer.Close(); err != nil {
+		return FileEntry{}, fmt.Errorf("写入 Parquet 失败: %w", err)
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return FileEntry{}, err
+	}
+	return FileEntry{Rows: w.rows, Size: info.Size(), SHA256: hex.EncodeToString(w.hash.Sum(nil))}, nil
+}
+
+// Path 临时文件路径
+func (w *FileWriter[T]) Path() string {
+	return w.file.Name()
+}
+
+// Remove 关闭并删除临时文件
+func (w *FileWriter[T]) Remove() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
@@ -0,0 +1,68 @@
+package snapshot
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestFileWriterRoundTrip(t *testing.T) {
+	w, err := NewFileWriter[DailyBarRow]("daily-*.parquet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Remove()
+
+	loc := time.FixedZone("CST", 8*3600)
+	const n = 2500 // 跨越多个写入批次
+	for i := 0; i < n; i++ {
+		bar := &models.DailyBar{
+			Symbol: "600000", Exchange: "SH", Date: time.Date(2024, 1, 2, 0, 0, 0, 0, loc).AddDate(0, 0, i),
+			Open: 10, High: 11, Low: 9, Close: 10.5, Volume: int64(i), Amount: 1e6,
+		}
+		if err := w.Write(NewDailyBarRow(bar)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entry, err := w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Rows != n || entry.Size == 0 || len(entry.SHA256) != 64 {
+		t.Fatalf("文件信息错误: %+v", entry)
+	}
+
+	f, err := os.Open(w.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	rows, err := parquet.Read[DailyBarRow](f, entry.Size)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != n {
+		t.Fatalf("期望读回 %d 行，实际 %d", n, len(rows))
+	}
+	// 2024-01-02 距 1970-01-01 为 19724 天，按行情自身日期计算，不受时区影响
+	if rows[0].Date != 19724 || rows[0].Symbol != "600000" || rows[n-1].Volume != n-1 {
+		t.Errorf("读回数据错误: first=%+v last=%+v", rows[0], rows[n-1])
+	}
+}
+
+func TestID(t *testing.T) {
+	start := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	id := ID(start, start.AddDate(0, 0, 29))
+	if id != "20240102-20240131" || !ValidID(id) {
+		t.Errorf("快照ID错误: %s", id)
+	}
+	for _, bad := range []string{"", "../20240102-20240131", "20240102", "20240102-20240131/x"} {
+		if ValidID(bad) {
+			t.Errorf("%q 不应是合法的快照ID", bad)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package snapshot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"stock-analysis-system/backend/pkg/config"
+)
+
+const manifestName = "manifest.json"
+
+// ErrNotFound 快照或文件不存在
+var ErrNotFound = errors.New("快照不存在")
+
+// Store 快照在对象存储中的读写，对象路径为 <prefix>/<id>/<name>
+type Store struct {
+	client *minio.Client
+	bucket string
+	prefix string
+}
+
+// NewStore 连接对象存储，Bucket 不存在时创建
+func NewStore(ctx context.Context, cfg *config.ExportConfig) (*Store, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("创建对象存储客户端失败: %w", err)
+	}
+
+	exists, err := client.BucketExists(ctx, cfg.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("连接对象存储失败: %w", err)
+	}
+	if !exists {
+		if err := client.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region}); err != nil {
+			return nil, fmt.Errorf("创建 Bucket %s 失败: %w", cfg.Bucket, err)
+		}
+	}
+
+	return &Store{client: client, bucket: cfg.Bucket, prefix: strings.Trim(cfg.Prefix, "/")}, nil
+}
+
+// Key 快照文件的对象路径
+func (s *Store) Key(id, name string) string {
+	return path.Join(s.prefix, id, name)
+}
+
+// Upload 上传本地文件
+func (s *Store) Upload(ctx context.Context, key, filePath string) error {
+	_, err := s.client.FPutObject(ctx, s.bucket, key, filePath, minio.PutObjectOptions{
+		ContentType: "application/vnd.apache.parquet",
+	})
+	if err != nil {
+		return fmt.Errorf("上传 %s 失败: %w", key, err)
+	}
+	return nil
+}
+
+// PutManifest 写入快照清单，数据文件全部上传后再写入，清单存在即表示快照完整
+func (s *Store) PutManifest(ctx context.Context, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = s.client.PutObject(ctx, s.bucket, s.Key(m.ID, manifestName), bytes.NewReader(data), int64(len(data)),
+		minio.PutObjectOptions{ContentType: "application/json"})
+	if err != nil {
+		return fmt.Errorf("写入快照清单失败: %w", err)
+	}
+	return nil
+}
+
+// GetManifest 读取快照清单
+func (s *Store) GetManifest(ctx context.Context, id string) (*Manifest, error) {
+	if !ValidID(id) {
+		return nil, ErrNotFound
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, s.Key(id, manifestName), minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	var m Manifest
+	if err := json.NewDecoder(obj).Decode(&m); err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("读取快照清单失败: %w", err)
+	}
+	return &m, nil
+}
+
+// ListManifests 列出全部快照，按截止日倒序
+func (s *Store) ListManifests(ctx context.Context) ([]*Manifest, error) {
+	prefix := s.prefix
+	if prefix != "" {
+		prefix += "/"
+	}
+	var manifests []*Manifest
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix}) {
+		if obj.Err != nil {
+			return nil, fmt.Errorf("列出快照失败: %w", obj.Err)
+		}
+		id := strings.TrimSuffix(strings.TrimPrefix(obj.Key, prefix), "/")
+		if !ValidID(id) {
+			continue
+		}
+		m, err := s.GetManifest(ctx, id)
+		if errors.Is(err, ErrNotFound) {
+			continue // 导出中或导出失败的快照没有清单
+		}
+		if err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, m)
+	}
+	sort.Slice(manifests, func(i, j int) bool {
+		if manifests[i].End != manifests[j].End {
+			return manifests[i].End > manifests[j].End
+		}
+		return manifests[i].Start > manifests[j].Start
+	})
+	return manifests, nil
+}
+
+// Open 读取快照文件
+func (s *Store) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+// PresignedURL 生成限时下载链接，客户端可直接从对象存储下载大文件
+func (s *Store) PresignedURL(ctx context.Context, key, filename string, expiry time.Duration) (*url.URL, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	return s.client.PresignedGetObject(ctx, s.bucket, key, expiry, params)
+}
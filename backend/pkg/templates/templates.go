@@ -0,0 +1,165 @@
+// Package templates 内置策略模板：预设策略类型、策略类与参数说明，按模板创建策略时校验并补齐参数
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 参数取值类型
+const (
+	ParamInt   = "int"
+	ParamFloat = "float"
+)
+
+// Param 模板参数说明
+type Param struct {
+	Name        string  `json:"name"`
+	Label       string  `json:"label"`
+	Type        string  `json:"type"` // int | float
+	Default     float64 `json:"default"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Description string  `json:"description,omitempty"`
+}
+
+// Template 策略模板
+type Template struct {
+	Key         string  `json:"key"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Type        string  `json:"type"`
+	ClassName   string  `json:"class_name"`
+	Params      []Param `json:"params"`
+
+	check func(p map[string]float64) error // 参数之间的约束，单个参数的范围由 Params 校验
+}
+
+// fixedSize 各模板共用的每次开仓数量参数
+var fixedSize = Param{Name: "fixed_size", Label: "每次开仓数量", Type: ParamInt, Default: 100, Min: 100, Max: 1000000,
+	Description: "单位为股，A股按 100 股整数倍下单"}
+
+var builtin = []*Template{
+	{
+		Key:         "dual_ma_cross",
+		Name:        "双均线交叉",
+		Description: "快线上穿慢线买入，下穿卖出",
+		Type:        "trend_following",
+		ClassName:   "DualMAStrategy",
+		Params: []Param{
+			{Name: "fast_window", Label: "快线周期", Type: ParamInt, Default: 5, Min: 2, Max: 60},
+			{Name: "slow_window", Label: "慢线周期", Type: ParamInt, Default: 20, Min: 5, Max: 250},
+			fixedSize,
+		},
+		check: func(p map[string]float64) error {
+			if p["fast_window"] >= p["slow_window"] {
+				return fmt.Errorf("快线周期必须小于慢线周期")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "rsi_mean_reversion",
+		Name:        "RSI 均值回归",
+		Description: "RSI 低于超卖线买入，高于超买线卖出",
+		Type:        "mean_reversion",
+		ClassName:   "RSIStrategy",
+		Params: []Param{
+			{Name: "rsi_period", Label: "RSI 周期", Type: ParamInt, Default: 14, Min: 2, Max: 100},
+			{Name: "oversold", Label: "超卖线", Type: ParamFloat, Default: 30, Min: 1, Max: 50},
+			{Name: "overbought", Label: "超买线", Type: ParamFloat, Default: 70, Min: 50, Max: 99},
+			fixedSize,
+		},
+		check: func(p map[string]float64) error {
+			if p["oversold"] >= p["overbought"] {
+				return fmt.Errorf("超卖线必须低于超买线")
+			}
+			return nil
+		},
+	},
+	{
+		Key:         "bollinger_breakout",
+		Name:        "布林带突破",
+		Description: "收盘价突破上轨买入，跌破中轨卖出",
+		Type:        "trend_following",
+		ClassName:   "BollingerBreakoutStrategy",
+		Params: []Param{
+			{Name: "boll_window", Label: "布林带周期", Type: ParamInt, Default: 20, Min: 5, Max: 120},
+			{Name: "boll_dev", Label: "标准差倍数", Type: ParamFloat, Default: 2, Min: 0.5, Max: 4},
+			fixedSize,
+		},
+	},
+	{
+		Key:         "momentum_rotation",
+		Name:        "动量轮动",
+		Description: "每个调仓周期按过去一段时间的涨幅排序，持有动量最强的若干只股票，需配合股票池使用",
+		Type:        "multi_factor",
+		ClassName:   "MomentumRotationStrategy",
+		Params: []Param{
+			{Name: "lookback", Label: "动量回看周期", Type: ParamInt, Default: 20, Min: 5, Max: 250,
+				Description: "按该周期内的涨幅计算动量（交易日）"},
+			{Name: "hold_count", Label: "持仓数量", Type: ParamInt, Default: 5, Min: 1, Max: 50},
+			{Name: "rebalance_days", Label: "调仓周期", Type: ParamInt, Default: 5, Min: 1, Max: 60,
+				Description: "每隔多少个交易日调仓"},
+		},
+	},
+}
+
+// All 全部内置模板，按定义顺序
+func All() []*Template {
+	return builtin
+}
+
+// Get 按 key 查找模板
+func Get(key string) (*Template, bool) {
+	for _, t := range builtin {
+		if t.Key == key {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// BuildParams 以默认值补齐未指定的参数，校验取值范围后生成策略 params（JSON）
+func (t *Template) BuildParams(overrides map[string]float64) (string, error) {
+	known := make(map[string]bool, len(t.Params))
+	values := make(map[string]float64, len(t.Params))
+	for _, p := range t.Params {
+		known[p.Name] = true
+		v, ok := overrides[p.Name]
+		if !ok {
+			v = p.Default
+		}
+		if p.Type == ParamInt && v != math.Trunc(v) {
+			return "", fmt.Errorf("参数 %s 必须为整数", p.Name)
+		}
+		if v < p.Min || v > p.Max {
+			return "", fmt.Errorf("参数 %s 超出范围 [%g, %g]", p.Name, p.Min, p.Max)
+		}
+		values[p.Name] = v
+	}
+
+	var unknown []string
+	for name := range overrides {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("模板 %s 不支持参数 %v", t.Key, unknown)
+	}
+	if t.check != nil {
+		if err := t.check(values); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
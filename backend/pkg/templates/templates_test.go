@@ -0,0 +1,48 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildParams(t *testing.T) {
+	tmpl, ok := Get("dual_ma_cross")
+	if !ok {
+		t.Fatal("缺少 dual_ma_cross 模板")
+	}
+
+	params, err := tmpl.BuildParams(map[string]float64{"slow_window": 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"fast_window":5,"fixed_size":100,"slow_window":30}`; params != want {
+		t.Errorf("params = %s, want %s", params, want)
+	}
+
+	for _, tc := range []struct {
+		overrides map[string]float64
+		want      string
+	}{
+		{map[string]float64{"fast_window": 5.5}, "必须为整数"},
+		{map[string]float64{"slow_window": 1000}, "超出范围"},
+		{map[string]float64{"fast_window": 30, "slow_window": 20}, "快线周期必须小于慢线周期"},
+		{map[string]float64{"foo": 1}, "不支持参数 [foo]"},
+	} {
+		if _, err := tmpl.BuildParams(tc.overrides); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("BuildParams(%v) err = %v, want %q", tc.overrides, err, tc.want)
+		}
+	}
+}
+
+func TestBuiltinDefaults(t *testing.T) {
+	seen := map[string]bool{}
+	for _, tmpl := range All() {
+		if seen[tmpl.Key] {
+			t.Errorf("模板 key %s 重复", tmpl.Key)
+		}
+		seen[tmpl.Key] = true
+		if _, err := tmpl.BuildParams(nil); err != nil {
+			t.Errorf("%s 默认参数无效: %v", tmpl.Key, err)
+		}
+	}
+}
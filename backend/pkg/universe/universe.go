@@ -0,0 +1,129 @@
+// Package universe 股票池：按交易日的成分快照还原任意日期的股票池，供回测避免幸存者偏差
+package universe
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// Timeline 股票池成分时间线，由按交易日保存的成分快照构成
+type Timeline struct {
+	dates   []string            // 快照日期，升序
+	members map[string][]string // 日期 -> symbol.exchange（升序）
+}
+
+// NewTimeline 由成分快照构建时间线
+func NewTimeline(members []*models.UniverseMember) *Timeline {
+	t := &Timeline{members: make(map[string][]string)}
+	for _, m := range members {
+		date := m.TradeDate.Format("2006-01-02")
+		if _, ok := t.members[date]; !ok {
+			t.dates = append(t.dates, date)
+		}
+		t.members[date] = append(t.members[date], m.Symbol+"."+m.Exchange)
+	}
+	sort.Strings(t.dates)
+	for _, symbols := range t.members {
+		sort.Strings(symbols)
+	}
+	return t
+}
+
+// Len 快照数量
+func (t *Timeline) Len() int {
+	return len(t.dates)
+}
+
+// At 返回指定日期（YYYY-MM-DD）的成分，即不晚于该日的最近一次快照；此前没有快照时返回空
+func (t *Timeline) At(date string) []string {
+	i := sort.SearchStrings(t.dates, date)
+	if i < len(t.dates) && t.dates[i] == date {
+		return t.members[date]
+	}
+	if i == 0 {
+		return nil
+	}
+	return t.members[t.dates[i-1]]
+}
+
+// Ever 时间线内曾经入选的全部股票
+func (t *Timeline) Ever() []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, date := range t.dates {
+		for _, key := range t.members[date] {
+			if !seen[key] {
+				seen[key] = true
+				out = append(out, key)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Changes 相邻快照之间调入、调出的股票次数
+func (t *Timeline) Changes() (added, removed int) {
+	for i := 1; i < len(t.dates); i++ {
+		prev := toSet(t.members[t.dates[i-1]])
+		cur := toSet(t.members[t.dates[i]])
+		for key := range cur {
+			if !prev[key] {
+				added++
+			}
+		}
+		for key := range prev {
+			if !cur[key] {
+				removed++
+			}
+		}
+	}
+	return added, removed
+}
+
+func toSet(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		set[key] = true
+	}
+	return set
+}
+
+// Summary 回测区间内股票池的使用情况
+type Summary struct {
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Snapshots    int    `json:"snapshots"`     // 使用的快照数（含开始日之前最近的一次）
+	StartMembers int    `json:"start_members"` // 回测开始日成分数
+	EndMembers   int    `json:"end_members"`   // 回测结束日成分数
+	EverMembers  int    `json:"ever_members"`  // 区间内曾经入选的股票数
+	Added        int    `json:"added"`         // 调入次数
+	Removed      int    `json:"removed"`       // 调出次数
+}
+
+// Summarize 汇总回测区间内的股票池成分变化
+func Summarize(u *models.Universe, t *Timeline, start, end time.Time) *Summary {
+	added, removed := t.Changes()
+	return &Summary{
+		ID:           u.ID,
+		Name:         u.Name,
+		Snapshots:    t.Len(),
+		StartMembers: len(t.At(start.Format("2006-01-02"))),
+		EndMembers:   len(t.At(end.Format("2006-01-02"))),
+		EverMembers:  len(t.Ever()),
+		Added:        added,
+		Removed:      removed,
+	}
+}
+
+// ValidType 校验股票池类型
+func ValidType(typ string) error {
+	switch typ {
+	case models.UniverseTypeIndex, models.UniverseTypeScreener, models.UniverseTypeManual:
+		return nil
+	}
+	return fmt.Errorf("不支持的股票池类型: %s", typ)
+}
@@ -0,0 +1,51 @@
+package universe
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func member(date, symbol string) *models.UniverseMember {
+	d, _ := time.Parse("2006-01-02", date)
+	return &models.UniverseMember{TradeDate: d, Symbol: symbol, Exchange: "SZ"}
+}
+
+func TestTimeline(t *testing.T) {
+	tl := NewTimeline([]*models.UniverseMember{
+		member("2024-01-02", "000002"),
+		member("2024-01-02", "000001"),
+		member("2024-01-05", "000001"),
+		member("2024-01-05", "000003"),
+	})
+
+	if tl.Len() != 2 {
+		t.Fatalf("Len = %d, want 2", tl.Len())
+	}
+	if got := tl.At("2024-01-01"); got != nil {
+		t.Errorf("At before first snapshot = %v, want nil", got)
+	}
+	want := []string{"000001.SZ", "000002.SZ"}
+	if got := tl.At("2024-01-04"); !reflect.DeepEqual(got, want) {
+		t.Errorf("At(2024-01-04) = %v, want %v", got, want)
+	}
+	want = []string{"000001.SZ", "000003.SZ"}
+	if got := tl.At("2024-01-05"); !reflect.DeepEqual(got, want) {
+		t.Errorf("At(2024-01-05) = %v, want %v", got, want)
+	}
+	if got := tl.Ever(); len(got) != 3 {
+		t.Errorf("Ever = %v, want 3 symbols", got)
+	}
+	if added, removed := tl.Changes(); added != 1 || removed != 1 {
+		t.Errorf("Changes = %d/%d, want 1/1", added, removed)
+	}
+
+	start, _ := time.Parse("2006-01-02", "2024-01-03")
+	end, _ := time.Parse("2006-01-02", "2024-01-31")
+	summary := Summarize(&models.Universe{ID: 7, Name: "test"}, tl, start, end)
+	if summary.StartMembers != 2 || summary.EndMembers != 2 || summary.EverMembers != 3 {
+		t.Errorf("Summarize = %+v", summary)
+	}
+}
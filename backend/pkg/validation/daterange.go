@@ -0,0 +1,112 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/markettime"
+)
+
+// DateLayout 接口日期格式
+const DateLayout = "2006-01-02"
+
+// DateRange 校验后的日期区间，End 为结束日期当天 23:59:59
+// Start、End 按 UTC 表示日期，与日K线的保存方式一致；查询分钟K线时需用 markettime.StartOfDate 换算到交易所时区。
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// RangeRule 日期区间校验规则
+type RangeRule struct {
+	Required    bool // 开始、结束日期是否必填
+	DefaultDays int  // 未传开始日期时，从结束日期向前取的天数
+	MaxDays     int  // 最大跨度（天），0 表示不限制
+}
+
+// 各K线周期允许的最大查询跨度（天）
+var periodMaxDays = map[string]int{
+	"1m":  30,
+	"5m":  90,
+	"15m": 180,
+	"30m": 365,
+	"60m": 730,
+	"1d":  365 * 20,
+}
+
+// PeriodRule 返回K线周期对应的校验规则
+func PeriodRule(period string) (RangeRule, error) {
+	maxDays, ok := periodMaxDays[period]
+	if !ok {
+		return RangeRule{}, fmt.Errorf("不支持的周期: %s", period)
+	}
+	return RangeRule{Required: true, MaxDays: maxDays}, nil
+}
+
+// StreamPeriodRule 返回流式（NDJSON）K线查询的校验规则
+// 流式响应边读边写，内存占用与跨度无关，分钟K线也按日K线的最大跨度限制。
+func StreamPeriodRule(period string) (RangeRule, error) {
+	if _, ok := periodMaxDays[period]; !ok {
+		return RangeRule{}, fmt.Errorf("不支持的周期: %s", period)
+	}
+	return RangeRule{Required: true, MaxDays: periodMaxDays["1d"]}, nil
+}
+
+// ParseDateRange 解析并校验日期区间
+// 开始日期不能晚于结束日期，也不能晚于今天；结束日期晚于今天时按今天处理。
+func ParseDateRange(start, end string, rule RangeRule) (DateRange, error) {
+	if rule.Required {
+		if start == "" {
+			return DateRange{}, errors.New("缺少开始日期")
+		}
+		if end == "" {
+			return DateRange{}, errors.New("缺少结束日期")
+		}
+	}
+
+	today := markettime.Today("") // 按交易所时区判断“今天”，与服务器时区无关
+
+	var r DateRange
+	if end != "" {
+		if _, err := time.Parse(DateLayout, end); err != nil {
+			return DateRange{}, fmt.Errorf("结束日期格式错误，应为 YYYY-MM-DD: %s", end)
+		}
+	}
+	if end == "" || end > today {
+		end = today
+	}
+	endDate, _ := time.Parse(DateLayout, end)
+	r.End = endDate.Add(24 * time.Hour).Add(-time.Second)
+
+	var err error
+
+	if start == "" {
+		r.Start = endDate.AddDate(0, 0, -rule.DefaultDays)
+	} else {
+		r.Start, err = time.Parse(DateLayout, start)
+		if err != nil {
+			return DateRange{}, fmt.Errorf("开始日期格式错误，应为 YYYY-MM-DD: %s", start)
+		}
+		if start > today {
+			return DateRange{}, fmt.Errorf("开始日期 %s 不能晚于今天", start)
+		}
+	}
+
+	if r.Start.After(endDate) {
+		return DateRange{}, fmt.Errorf("开始日期 %s 不能晚于结束日期 %s", r.Start.Format(DateLayout), end)
+	}
+
+	if rule.MaxDays > 0 && endDate.Sub(r.Start) > time.Duration(rule.MaxDays)*24*time.Hour {
+		return DateRange{}, fmt.Errorf("查询区间不能超过 %d 天", rule.MaxDays)
+	}
+
+	return r, nil
+}
+
+// InExchange 区间在交易所时区下的起止时间（开始日零点至结束日 23:59:59），用于查询带时刻的数据（如分钟K线）
+func (r DateRange) InExchange(exchange string) (start, end time.Time) {
+	start = markettime.StartOfDate(r.Start, exchange)
+	end = markettime.StartOfDate(r.End, exchange).AddDate(0, 0, 1).Add(-time.Second)
+	return start, end
+}
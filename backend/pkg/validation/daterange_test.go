@@ -0,0 +1,101 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"stock-analysis-system/backend/pkg/markettime"
+)
+
+func TestParseDateRange(t *testing.T) {
+	// “今天”按交易所时区判断
+	today := markettime.Today("")
+	tomorrow := markettime.Now("").AddDate(0, 0, 1).Format(DateLayout)
+
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		rule    RangeRule
+		wantErr string
+	}{
+		{name: "正常区间", start: "2024-01-01", end: "2024-01-31", rule: RangeRule{Required: true}},
+		{name: "缺少开始日期", end: "2024-01-31", rule: RangeRule{Required: true}, wantErr: "缺少开始日期"},
+		{name: "格式错误", start: "2024/01/01", end: "2024-01-31", wantErr: "开始日期格式错误"},
+		{name: "结束日期格式错误", start: "2024-01-01", end: "abc", wantErr: "结束日期格式错误"},
+		{name: "开始晚于结束", start: "2024-02-01", end: "2024-01-01", wantErr: "不能晚于结束日期"},
+		{name: "开始日期在未来", start: tomorrow, end: tomorrow, wantErr: "不能晚于今天"},
+		{name: "超过最大跨度", start: "2020-01-01", end: "2024-01-01", rule: RangeRule{MaxDays: 30}, wantErr: "不能超过 30 天"},
+		{name: "默认区间", rule: RangeRule{DefaultDays: 30}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := ParseDateRange(tt.start, tt.end, tt.rule)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("期望错误包含 %q，实际: %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("不应返回错误: %v", err)
+			}
+			if r.Start.After(r.End) {
+				t.Errorf("开始时间晚于结束时间: %v > %v", r.Start, r.End)
+			}
+		})
+	}
+
+	// 结束日期在未来时按今天处理
+	r, err := ParseDateRange("2024-01-01", tomorrow, RangeRule{})
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if got := r.End.Format(DateLayout); got != today {
+		t.Errorf("结束日期应截断为今天 %s，实际: %s", today, got)
+	}
+}
+
+func TestPeriodRule(t *testing.T) {
+	rule, err := PeriodRule("1m")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if _, err := ParseDateRange("2000-01-01", "2024-01-01", rule); err == nil {
+		t.Error("1分钟线查询24年数据应被拒绝")
+	}
+	if _, err := PeriodRule("2h"); err == nil {
+		t.Error("不支持的周期应返回错误")
+	}
+}
+
+func TestStreamPeriodRule(t *testing.T) {
+	rule, err := StreamPeriodRule("1m")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if _, err := ParseDateRange("2020-01-01", "2024-01-01", rule); err != nil {
+		t.Errorf("流式查询应允许多年分钟线: %v", err)
+	}
+	if _, err := ParseDateRange("2000-01-01", "2024-01-01", rule); err == nil {
+		t.Error("超过日K线最大跨度应被拒绝")
+	}
+	if _, err := StreamPeriodRule("2h"); err == nil {
+		t.Error("不支持的周期应返回错误")
+	}
+}
+
+func TestDateRangeInExchange(t *testing.T) {
+	r, err := ParseDateRange("2024-06-03", "2024-06-04", RangeRule{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end := r.InExchange("SH")
+	if got := start.UTC().Format("2006-01-02 15:04"); got != "2024-06-02 16:00" {
+		t.Errorf("开始时间应为北京时间 6月3日零点，实际 UTC %s", got)
+	}
+	if got := end.UTC().Format("2006-01-02 15:04:05"); got != "2024-06-04 15:59:59" {
+		t.Errorf("结束时间应为北京时间 6月4日 23:59:59，实际 UTC %s", got)
+	}
+}
@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SyncOverlap 增量同步游标相对服务器时间的回退量
+// 查询期间提交、updated_at 早于查询时刻的记录会在下次同步时返回，客户端按 ID 去重即可。
+const SyncOverlap = 5 * time.Second
+
+// ParseUpdatedSince 解析增量同步的 updated_since 参数（RFC3339 或 Unix 秒），为空时返回 nil
+// 不能晚于 now，客户端应使用上次响应返回的 next_since，避免依赖本地时钟。
+func ParseUpdatedSince(raw string, now time.Time) (*time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	var since time.Time
+	if seconds, err := strconv.ParseInt(raw, 10, 64); err == nil {
+		since = time.Unix(seconds, 0)
+	} else if since, err = time.Parse(time.RFC3339, raw); err != nil {
+		return nil, fmt.Errorf("updated_since 格式错误，应为 RFC3339 时间或 Unix 秒")
+	}
+	if since.After(now) {
+		return nil, fmt.Errorf("updated_since 不能晚于服务器时间")
+	}
+	return &since, nil
+}
+
+// NextSince 下次增量同步使用的 updated_since，应在查询前取得 now
+func NextSince(now time.Time) string {
+	return now.Add(-SyncOverlap).UTC().Format(time.RFC3339)
+}
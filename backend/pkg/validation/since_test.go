@@ -0,0 +1,33 @@
+package validation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseUpdatedSince(t *testing.T) {
+	now := time.Date(2024, 6, 3, 10, 0, 0, 0, time.UTC)
+
+	since, err := ParseUpdatedSince("", now)
+	if err != nil || since != nil {
+		t.Fatalf("空参数应返回 nil, got %v, %v", since, err)
+	}
+	since, err = ParseUpdatedSince("2024-06-03T17:30:00+08:00", now)
+	if err != nil || !since.Equal(time.Date(2024, 6, 3, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("RFC3339 = %v, %v", since, err)
+	}
+	since, err = ParseUpdatedSince("1717405200", now)
+	if err != nil || since.Unix() != 1717405200 {
+		t.Errorf("Unix 秒 = %v, %v", since, err)
+	}
+
+	for _, raw := range []string{"2024-06-03", "yesterday", "2024-06-03T11:00:00Z"} {
+		if _, err := ParseUpdatedSince(raw, now); err == nil {
+			t.Errorf("ParseUpdatedSince(%q) 应返回错误", raw)
+		}
+	}
+
+	if next := NextSince(now); next != "2024-06-03T09:59:55Z" {
+		t.Errorf("NextSince = %s", next)
+	}
+}
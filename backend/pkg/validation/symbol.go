@@ -0,0 +1,41 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 股票代码限制
+const (
+	MaxSymbolLength   = 10 // 代码最大字符数
+	MaxExchangeLength = 4  // 交易所代码最大字符数
+)
+
+// ParseSymbolQuery 解析查询参数中的股票：000001 只有代码时匹配任意交易所，000001.SZ 同时指定交易所
+// 代码只允许字母与数字，交易所统一转为大写；参数为空时返回空字符串。
+func ParseSymbolQuery(raw string) (symbol, exchange string, err error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", "", nil
+	}
+	symbol, exchange, hasExchange := strings.Cut(raw, ".")
+	symbol = strings.ToUpper(symbol)
+	exchange = strings.ToUpper(exchange)
+	if symbol == "" || len(symbol) > MaxSymbolLength || !isAlnum(symbol) {
+		return "", "", fmt.Errorf("股票代码 %q 格式错误", raw)
+	}
+	if hasExchange && (exchange == "" || len(exchange) > MaxExchangeLength || !isAlnum(exchange)) {
+		return "", "", fmt.Errorf("交易所 %q 格式错误", raw)
+	}
+	return symbol, exchange, nil
+}
+
+// isAlnum 是否只包含 ASCII 字母与数字
+func isAlnum(s string) bool {
+	for _, r := range s {
+		if !('0' <= r && r <= '9' || 'A' <= r && r <= 'Z' || 'a' <= r && r <= 'z') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package validation
+
+import "testing"
+
+func TestParseSymbolQuery(t *testing.T) {
+	tests := []struct {
+		raw              string
+		symbol, exchange string
+		wantErr          bool
+	}{
+		{raw: "", symbol: "", exchange: ""},
+		{raw: " 000001 ", symbol: "000001", exchange: ""},
+		{raw: "600519.sh", symbol: "600519", exchange: "SH"},
+		{raw: "000001.", wantErr: true},
+		{raw: ".SZ", wantErr: true},
+		{raw: "0000'01", wantErr: true},
+		{raw: "600519.SH.X", wantErr: true},
+	}
+	for _, tt := range tests {
+		symbol, exchange, err := ParseSymbolQuery(tt.raw)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: err = %v", tt.raw, err)
+			continue
+		}
+		if symbol != tt.symbol || exchange != tt.exchange {
+			t.Errorf("%q: 得到 %q %q，期望 %q %q", tt.raw, symbol, exchange, tt.symbol, tt.exchange)
+		}
+	}
+}
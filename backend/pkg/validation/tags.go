@@ -0,0 +1,43 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// 标签限制
+const (
+	MaxTagLength = 50 // 标签名最大字符数
+	MaxTags      = 20 // 单个对象最多标签数
+)
+
+// ParseTagQuery 解析列表接口的 tags 参数（逗号分隔），去除空白与重复项
+func ParseTagQuery(raw string) []string {
+	if strings.TrimSpace(raw) == "" {
+		return nil
+	}
+	tags, _ := NormalizeTags(strings.Split(raw, ","))
+	return tags
+}
+
+// NormalizeTags 清理标签名：去除首尾空白、空项与重复项，并校验长度与数量
+func NormalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	var tags []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if utf8.RuneCountInString(name) > MaxTagLength {
+			return nil, fmt.Errorf("标签 %q 超过 %d 个字符", name, MaxTagLength)
+		}
+		seen[name] = true
+		tags = append(tags, name)
+	}
+	if len(tags) > MaxTags {
+		return nil, fmt.Errorf("标签数量不能超过 %d 个", MaxTags)
+	}
+	return tags, nil
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{" momentum", "live", "", "momentum", "实盘"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"momentum", "live", "实盘"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("期望 %v，实际 %v", want, tags)
+	}
+
+	if _, err := NormalizeTags([]string{strings.Repeat("标", MaxTagLength+1)}); err == nil {
+		t.Error("超长标签应报错")
+	}
+	many := make([]string, MaxTags+1)
+	for i := range many {
+		many[i] = strings.Repeat("a", i+1)
+	}
+	if _, err := NormalizeTags(many); err == nil {
+		t.Error("超过数量上限应报错")
+	}
+}
+
+func TestParseTagQuery(t *testing.T) {
+	if got := ParseTagQuery(" "); got != nil {
+		t.Errorf("空参数应返回 nil，实际 %v", got)
+	}
+	if got := ParseTagQuery("live, momentum,,live"); !reflect.DeepEqual(got, []string{"live", "momentum"}) {
+		t.Errorf("解析结果错误: %v", got)
+	}
+}
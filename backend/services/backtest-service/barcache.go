@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unsafe"
+
+	"stock-analysis-system/backend/pkg/cache"
+	"stock-analysis-system/backend/pkg/columnar"
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 回测行情缓存 ============
+
+// minuteBarBytes 单根分钟K线估算占用的字节数：结构体、切片中的指针与股票代码等短字符串
+var minuteBarBytes = int64(unsafe.Sizeof(models.MinuteBar{})) + 8 + 16
+
+// barLoader 回测引擎加载行情：日K线以列式（columnar.Daily，实现 columnar.Loader）、分钟K线按原样，
+// 按股票、周期与时间范围缓存在进程内，参数扫描、定期回归等重复回测同一批股票与区间时不再重复查询 InfluxDB。
+// 返回的K线被多个回测共享，调用方不能修改。缓存键带有数据版本（该股票最近一次 restatement 任务的ID），
+// 数据同步服务修正或去重历史K线后版本随之变化，之后的回测立即读到修正后的K线，不依赖缓存有效期。
+type barLoader struct {
+	marketRepo  repository.MarketRepository
+	syncJobRepo repository.SyncJobRepository
+	bars        *cache.Memory[any] // *columnar.Daily 或 []*models.MinuteBar，未启用缓存时为 nil
+}
+
+// newBarLoader 按回测配置创建K线加载器，内存上限不大于 0 时不缓存
+func newBarLoader(repo repository.MarketRepository, syncJobRepo repository.SyncJobRepository, cfg config.BacktestConfig) *barLoader {
+	return &barLoader{
+		marketRepo:  repo,
+		syncJobRepo: syncJobRepo,
+		bars:        cache.NewMemory("backtest_bars", int64(cfg.BarCacheMB)<<20, time.Duration(cfg.BarCacheTTL)*time.Second, barsSize),
+	}
+}
+
+// version 股票K线的数据版本，即最近一次 restatement 任务的ID；未启用缓存时不查询，返回 0
+func (l *barLoader) version(ctx context.Context, symbol, exchange string) (uint, error) {
+	if l.bars == nil {
+		return 0, nil
+	}
+	version, err := l.syncJobRepo.GetLatestID(ctx, models.SyncJobRestatement, symbol, exchange)
+	if err != nil {
+		return 0, fmt.Errorf("查询 %s.%s K线版本失败: %w", symbol, exchange, err)
+	}
+	return version, nil
+}
+
+// DailyColumns 读取列式日K线，未命中或K线已被修正时查询 InfluxDB
+func (l *barLoader) DailyColumns(ctx context.Context, symbol, exchange string, start, end time.Time) (*columnar.Daily, error) {
+	version, err := l.version(ctx, symbol, exchange)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("daily:%s.%s:%d:%d:v%d", symbol, exchange, start.UnixNano(), end.UnixNano(), version)
+	v, err := l.bars.Load(ctx, key, func(ctx context.Context) (any, error) {
+		bars, err := l.marketRepo.GetDailyBars(ctx, symbol, exchange, start, end)
+		if err != nil {
+			return nil, err
+		}
+		return columnar.FromDailyBars(symbol, exchange, bars), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*columnar.Daily), nil
+}
+
+// GetMinuteBars 读取缓存的分钟K线，未命中或K线已被修正时查询 InfluxDB
+func (l *barLoader) GetMinuteBars(ctx context.Context, symbol, exchange, interval string, start, end time.Time) ([]*models.MinuteBar, error) {
+	version, err := l.version(ctx, symbol, exchange)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("minute:%s.%s:%s:%d:%d:v%d", symbol, exchange, interval, start.UnixNano(), end.UnixNano(), version)
+	v, err := l.bars.Load(ctx, key, func(ctx context.Context) (any, error) {
+		return l.marketRepo.GetMinuteBars(ctx, symbol, exchange, interval, start, end)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.([]*models.MinuteBar), nil
+}
+
+// barsSize 估算缓存的K线占用的字节数
+func barsSize(v any) int64 {
+	switch bars := v.(type) {
+	case *columnar.Daily:
+		return bars.Bytes()
+	case []*models.MinuteBar:
+		return int64(len(bars)) * minuteBarBytes
+	}
+	return 0
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// restatedSyncJobRepo 最近一次 restatement 任务的ID可在测试中推进
+type restatedSyncJobRepo struct {
+	repository.SyncJobRepository
+	latest atomic.Uint64
+}
+
+func (r *restatedSyncJobRepo) GetLatestID(_ context.Context, jobType, _, _ string) (uint, error) {
+	if jobType != models.SyncJobRestatement {
+		return 0, nil
+	}
+	return uint(r.latest.Load()), nil
+}
+
+// minuteMarketRepo 返回固定的分钟K线，记录实际查询次数
+type minuteMarketRepo struct {
+	fakeMarketRepo
+	minuteQueries atomic.Int64
+}
+
+func (r *minuteMarketRepo) GetMinuteBars(_ context.Context, symbol, exchange, interval string, start, _ time.Time) ([]*models.MinuteBar, error) {
+	r.minuteQueries.Add(1)
+	return []*models.MinuteBar{{Symbol: symbol, Exchange: exchange, Interval: interval, Time: start, Close: 10}}, nil
+}
+
+// K线被修正后日K线与分钟K线都不再命中修正前的缓存
+func TestBarLoaderVersionedByRestatement(t *testing.T) {
+	ctx := context.Background()
+	start, end := time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC), time.Date(2021, 1, 8, 0, 0, 0, 0, time.UTC)
+	repo := &minuteMarketRepo{fakeMarketRepo: fakeMarketRepo{bars: map[string][]*models.DailyBar{
+		"600519.SH": {{Symbol: "600519", Exchange: "SH", Date: start, Close: 10}},
+	}}}
+	syncJobs := &restatedSyncJobRepo{}
+	l := newBarLoader(repo, syncJobs, config.BacktestConfig{BarCacheMB: 16, BarCacheTTL: 3600})
+
+	load := func() {
+		t.Helper()
+		if _, err := l.DailyColumns(ctx, "600519", "SH", start, end); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := l.GetMinuteBars(ctx, "600519", "SH", "1m", start, end); err != nil {
+			t.Fatal(err)
+		}
+	}
+	load()
+	load()
+	if daily, minute := repo.queries.Load(), repo.minuteQueries.Load(); daily != 1 || minute != 1 {
+		t.Fatalf("版本不变时日K线查询 %d 次、分钟K线查询 %d 次，期望各一次", daily, minute)
+	}
+
+	syncJobs.latest.Store(7)
+	load()
+	if daily, minute := repo.queries.Load(), repo.minuteQueries.Load(); daily != 2 || minute != 2 {
+		t.Errorf("修正后日K线查询 %d 次、分钟K线查询 %d 次，期望各回源一次", daily, minute)
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/basket"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/risk"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 回测基准 ============
+
+// 以用户篮子、股票池作为基准时的前缀，如 basket:12、universe:3
+const (
+	benchmarkBasketPrefix   = "basket:"
+	benchmarkUniversePrefix = "universe:"
+)
+
+// maxBenchmarkSymbols 股票池基准在回测区间内曾入选的股票数上限
+const maxBenchmarkSymbols = 1000
+
+// BenchmarkResult 回测净值与基准的对比
+type BenchmarkResult struct {
+	Benchmark    string    `json:"benchmark"`     // symbol.exchange、basket:{id} 或 universe:{id}
+	Name         string    `json:"name"`          // 篮子、股票池名称，股票为代码
+	Return       float64   `json:"return"`        // 基准区间收益率
+	ExcessReturn float64   `json:"excess_return"` // 策略区间收益率减基准区间收益率
+	Beta         float64   `json:"beta"`          // 策略日收益率相对基准的 Beta
+	Correlation  float64   `json:"correlation"`   // 策略与基准日收益率的相关系数
+	Equity       []float64 `json:"equity"`        // 与回测净值曲线交易日对齐、按初始净值缩放的基准净值
+}
+
+// benchmarkView 回测结果接口返回的基准对比，附带净值曲线以便与基准绘制在同一图表
+type benchmarkView struct {
+	*BenchmarkResult
+	Dates          []string  `json:"dates"`
+	StrategyEquity []float64 `json:"strategy_equity"`
+}
+
+// checkBenchmark 校验回测基准格式与篮子、股票池归属，返回错误时附带 HTTP 状态码
+func (s *BacktestService) checkBenchmark(ctx context.Context, uid uint, spec string) (int, error) {
+	if id, ok := strings.CutPrefix(spec, benchmarkBasketPrefix); ok {
+		basketID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("基准篮子ID错误: %s", spec)
+		}
+		b, err := s.basketRepo.GetByID(ctx, uint(basketID))
+		if err != nil || b.UserID != uid {
+			return http.StatusForbidden, fmt.Errorf("无权使用该篮子作为基准")
+		}
+		return http.StatusOK, nil
+	}
+	if id, ok := strings.CutPrefix(spec, benchmarkUniversePrefix); ok {
+		universeID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return http.StatusBadRequest, fmt.Errorf("基准股票池ID错误: %s", spec)
+		}
+		u, err := s.universeRepo.GetByID(ctx, uint(universeID))
+		if err != nil || u.UserID != uid {
+			return http.StatusForbidden, fmt.Errorf("无权使用该股票池作为基准")
+		}
+		return http.StatusOK, nil
+	}
+	if _, _, ok := pairs.SplitLeg(spec); !ok {
+		return http.StatusBadRequest, fmt.Errorf("基准格式错误，应为 symbol.exchange、basket:{id} 或 universe:{id}")
+	}
+	return http.StatusOK, nil
+}
+
+// benchmarkCloses 基准在区间内的每日收盘价，键为交易日
+// 篮子与股票池由成分股日K线按保存的权重逐日计算点位（起点 1000 点），不依赖已保存的篮子点位，
+// 因此回测区间早于篮子基日时同样可用；股票池按每次快照的时点成分与指数权重调整，没有权重时等权。
+func (s *BacktestService) benchmarkCloses(ctx context.Context, spec string, start, end time.Time) (string, map[string]float64, error) {
+	if id, ok := strings.CutPrefix(spec, benchmarkBasketPrefix); ok {
+		basketID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("基准篮子ID错误: %s", spec)
+		}
+		b, err := s.basketRepo.GetByID(ctx, uint(basketID))
+		if err != nil {
+			return "", nil, fmt.Errorf("查询基准篮子失败: %w", err)
+		}
+		closes, err := s.compositeCloses(ctx, []basket.Rebalance{{Legs: basket.FromModel(b.Legs)}}, start, end)
+		return b.Name, closes, err
+	}
+	if id, ok := strings.CutPrefix(spec, benchmarkUniversePrefix); ok {
+		universeID, err := strconv.ParseUint(id, 10, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("基准股票池ID错误: %s", spec)
+		}
+		u, err := s.universeRepo.GetByID(ctx, uint(universeID))
+		if err != nil {
+			return "", nil, fmt.Errorf("查询基准股票池失败: %w", err)
+		}
+		schedule, err := s.universeSchedule(ctx, u.ID, start, end)
+		if err != nil {
+			return "", nil, err
+		}
+		closes, err := s.compositeCloses(ctx, schedule, start, end)
+		return u.Name, closes, err
+	}
+
+	symbol, exchange, _ := pairs.SplitLeg(spec)
+	bars, err := s.bars.DailyColumns(ctx, symbol, exchange, start, end)
+	if err != nil {
+		return "", nil, fmt.Errorf("查询基准日K线失败: %w", err)
+	}
+	return spec, bars.Closes(), nil
+}
+
+// compositeCloses 按成分调整计划由成分股日K线计算组合每日点位
+func (s *BacktestService) compositeCloses(ctx context.Context, schedule []basket.Rebalance, start, end time.Time) (map[string]float64, error) {
+	seen := make(map[string]bool)
+	var legs []basket.Leg
+	for _, r := range schedule {
+		for _, leg := range r.Legs {
+			if !seen[leg.Key()] {
+				seen[leg.Key()] = true
+				legs = append(legs, leg)
+			}
+		}
+	}
+	if len(legs) > maxBenchmarkSymbols {
+		return nil, fmt.Errorf("基准成分股超过 %d 只", maxBenchmarkSymbols)
+	}
+
+	legCloses, err := basket.LoadCloses(ctx, s.bars, legs, start.AddDate(0, 0, -basket.HistoryLookback), end)
+	if err != nil {
+		return nil, err
+	}
+	points := basket.Rebalanced(schedule, legCloses, start.Format(validation.DateLayout), basket.BaseValue)
+	closes := make(map[string]float64, len(points))
+	for _, p := range points {
+		if p.Coverage > 0 {
+			closes[p.Date] = p.Value
+		}
+	}
+	return closes, nil
+}
+
+// universeSchedule 股票池在回测区间内（含开始日之前最近一次快照）的成分调整计划
+// 快照带有指数权重时按权重归一化，否则等权。
+func (s *BacktestService) universeSchedule(ctx context.Context, universeID uint, start, end time.Time) ([]basket.Rebalance, error) {
+	first, err := s.universeRepo.GetLatestSnapshotDate(ctx, universeID, start)
+	if err != nil {
+		return nil, fmt.Errorf("查询股票池快照失败: %w", err)
+	}
+	if first == nil {
+		return nil, errNoUniverseSnapshot
+	}
+	members, err := s.universeRepo.GetMembersBetween(ctx, universeID, *first, end)
+	if err != nil {
+		return nil, fmt.Errorf("查询股票池成分失败: %w", err)
+	}
+
+	byDate := make(map[string][]*models.UniverseMember)
+	var dates []string
+	for _, m := range members {
+		date := m.TradeDate.Format(validation.DateLayout)
+		if _, ok := byDate[date]; !ok {
+			dates = append(dates, date)
+		}
+		byDate[date] = append(byDate[date], m)
+	}
+	sort.Strings(dates)
+
+	schedule := make([]basket.Rebalance, 0, len(dates))
+	for _, date := range dates {
+		schedule = append(schedule, basket.Rebalance{Date: date, Legs: memberLegs(byDate[date])})
+	}
+	return schedule, nil
+}
+
+// memberLegs 快照成分按指数权重归一化，快照没有权重时等权
+func memberLegs(members []*models.UniverseMember) []basket.Leg {
+	var total float64
+	for _, m := range members {
+		if m.Weight > 0 {
+			total += m.Weight
+		}
+	}
+	legs := make([]basket.Leg, 0, len(members))
+	for _, m := range members {
+		weight := 1 / float64(len(members))
+		if total > 0 {
+			weight = math.Max(m.Weight, 0) / total
+		}
+		legs = append(legs, basket.Leg{Symbol: m.Symbol, Exchange: m.Exchange, Weight: weight})
+	}
+	return legs
+}
+
+// benchmarkResult 计算回测净值相对基准的收益与风险指标，基准在区间内没有数据时返回错误
+func (s *BacktestService) benchmarkResult(ctx context.Context, spec string, start, end time.Time, dates []string, equity []float64) (*BenchmarkResult, error) {
+	if len(dates) == 0 || len(dates) != len(equity) {
+		return nil, fmt.Errorf("回测没有净值曲线")
+	}
+	name, closes, err := s.benchmarkCloses(ctx, spec, start, end)
+	if err != nil {
+		return nil, err
+	}
+	aligned := alignBenchmark(closes, dates)
+	if aligned == nil {
+		return nil, fmt.Errorf("基准 %s 在回测区间内没有行情", spec)
+	}
+
+	result := &BenchmarkResult{
+		Benchmark: spec,
+		Name:      name,
+		Equity:    make([]float64, len(aligned)),
+	}
+	for i, v := range aligned {
+		result.Equity[i] = v / aligned[0] * equity[0]
+	}
+	result.Return = aligned[len(aligned)-1]/aligned[0] - 1
+	if equity[0] > 0 {
+		result.ExcessReturn = equity[len(equity)-1]/equity[0] - 1 - result.Return
+	}
+
+	strategy := make(map[string]float64, len(dates))
+	for i, date := range dates {
+		strategy[date] = equity[i]
+	}
+	_, rx, ry := risk.AlignedReturns(strategy, closes)
+	result.Beta = risk.Beta(rx, ry)
+	result.Correlation = risk.Correlation(rx, ry)
+	return result, nil
+}
+
+// alignBenchmark 将基准收盘价对齐到回测交易日：停牌日沿用之前最近的收盘价，
+// 回测开始时基准尚无数据的交易日取之后第一个收盘价；基准没有有效收盘价时返回 nil
+func alignBenchmark(closes map[string]float64, dates []string) []float64 {
+	days := make([]string, 0, len(closes))
+	for date, price := range closes {
+		if price > 0 {
+			days = append(days, date)
+		}
+	}
+	if len(days) == 0 {
+		return nil
+	}
+	sort.Strings(days)
+
+	aligned := make([]float64, len(dates))
+	j, last := 0, closes[days[0]]
+	for i, date := range dates {
+		for j < len(days) && days[j] <= date {
+			last = closes[days[j]]
+			j++
+		}
+		aligned[i] = last
+	}
+	return aligned
+}
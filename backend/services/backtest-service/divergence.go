@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/divergence"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 实盘信号表现偏离检查 ============
+
+const (
+	divergenceLookback = 90                 // 比较最近 90 天产生的信号（自然日）
+	divergenceCooldown = 7 * 24 * time.Hour // 记录偏离后的冷却期，期间同一策略不重复记录
+)
+
+// CheckDivergences 检查开启偏离检查的策略，已实现信号表现超过阈值时记录偏离，按策略配置停用
+// 在定期回归回测之后执行，使用最新的回归结果作为回测预期。
+func (s *BacktestService) CheckDivergences(ctx context.Context, now time.Time) {
+	strategies, err := s.strategyRepo.GetDivergenceStrategies(ctx)
+	if err != nil {
+		log.Printf("查询偏离检查策略失败: %v", err)
+		return
+	}
+
+	var diverged int
+	for _, strategy := range strategies {
+		if ctx.Err() != nil {
+			return
+		}
+		ok, err := s.checkDivergence(ctx, strategy, now)
+		if err != nil {
+			log.Printf("策略 %d 偏离检查失败: %v", strategy.ID, err)
+			continue
+		}
+		if ok {
+			diverged++
+		}
+	}
+	log.Printf("实盘偏离检查完成: %d 个策略中 %d 个偏离", len(strategies), diverged)
+}
+
+// checkDivergence 检查单个策略，返回是否记录了偏离
+func (s *BacktestService) checkDivergence(ctx context.Context, strategy *models.Strategy, now time.Time) (bool, error) {
+	last, err := s.strategyRepo.GetLatestDivergence(ctx, strategy.ID)
+	if err != nil {
+		return false, err
+	}
+	if last != nil && now.Sub(last.CreatedAt) < divergenceCooldown {
+		return false, nil
+	}
+
+	expected, err := s.expectedPerformance(ctx, strategy.ID)
+	if err != nil || expected == nil {
+		return false, err
+	}
+	signals, err := s.strategyRepo.GetSignalsSince(ctx, strategy.ID, now.AddDate(0, 0, -divergenceLookback))
+	if err != nil {
+		return false, err
+	}
+	live := divergence.Realize(signals, regressionCapital)
+	reasons := divergence.Check(live, expected, strategy.DivergenceRatio, divergence.DefaultMinTrades)
+	if len(reasons) == 0 {
+		return false, nil
+	}
+
+	if err := s.strategyRepo.CreateDivergence(ctx, &models.StrategyDivergence{
+		StrategyID:       strategy.ID,
+		Reasons:          strings.Join(reasons, "\n"),
+		Action:           strategy.DivergenceAction,
+		LiveTrades:       live.Trades,
+		LiveReturn:       live.TotalReturn,
+		LiveDrawdown:     live.MaxDrawdown,
+		LiveWinRate:      live.WinRate,
+		ExpectedDrawdown: expected.MaxDrawdown,
+		ExpectedWinRate:  expected.WinRate,
+	}); err != nil {
+		return false, err
+	}
+	log.Printf("策略 %d 实盘表现偏离回测预期: %s", strategy.ID, strings.Join(reasons, "；"))
+
+	if strategy.DivergenceAction == divergence.ActionDeactivate {
+		if err := s.deactivateStrategy(ctx, strategy); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// expectedPerformance 回测预期：优先使用最近一次定期回归回测，其次为最近一次完成的回测，都没有时返回 nil
+func (s *BacktestService) expectedPerformance(ctx context.Context, strategyID uint) (*divergence.Expected, error) {
+	perf, err := s.strategyRepo.GetLatestPerformance(ctx, strategyID)
+	if err != nil {
+		return nil, err
+	}
+	if perf != nil {
+		return &divergence.Expected{MaxDrawdown: perf.MaxDrawdown, WinRate: perf.WinRate}, nil
+	}
+
+	records, _, err := s.backtestRepo.GetByStrategyID(ctx, strategyID, 1, 10)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range records {
+		if r.Status == "completed" {
+			return &divergence.Expected{MaxDrawdown: r.MaxDrawdown, WinRate: r.WinRate}, nil
+		}
+	}
+	return nil, nil
+}
+
+// deactivateStrategy 停用策略，与用户的修改发生版本冲突时重新读取后重试一次
+func (s *BacktestService) deactivateStrategy(ctx context.Context, strategy *models.Strategy) error {
+	strategy.IsActive = false
+	err := s.strategyRepo.Update(ctx, strategy)
+	if !errors.Is(err, repository.ErrVersionConflict) {
+		return err
+	}
+	current, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), strategy.ID)
+	if err != nil {
+		return err
+	}
+	current.IsActive = false
+	return s.strategyRepo.Update(ctx, current)
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"time"
+
+	"stock-analysis-system/backend/pkg/columnar"
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/pricelimit"
+	"stock-analysis-system/backend/pkg/progress"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// ============ 回测绩效计算 ============
+
+// applyRiskMetrics 根据净值曲线计算回测绩效指标（与风险分析接口使用同一套算法）
+func applyRiskMetrics(record *models.BacktestRecord, equity []float64) {
+	metrics := risk.Compute(equity, 0.95)
+
+	record.FinalCapital = equity[len(equity)-1]
+	record.TotalReturn = metrics.TotalReturn
+	record.MaxDrawdown = metrics.MaxDrawdown
+	record.SharpeRatio = metrics.SharpeRatio
+	if metrics.Observations > 0 {
+		record.AnnualReturn = math.Pow(1+metrics.TotalReturn, float64(risk.TradingDaysPerYear)/float64(metrics.Observations)) - 1
+	}
+}
+
+// applyTradeStats 根据逐笔交易计算交易次数、胜率与盈亏比
+func applyTradeStats(record *models.BacktestRecord, trades []*pairs.Trade) {
+	var wins, losses int
+	var gain, loss float64
+	for _, t := range trades {
+		switch {
+		case t.PnL > 0:
+			wins++
+			gain += t.PnL
+		case t.PnL < 0:
+			losses++
+			loss -= t.PnL
+		}
+	}
+
+	record.TradeCount = len(trades)
+	record.WinRate = 0
+	record.ProfitLossRatio = 0
+	if len(trades) > 0 {
+		record.WinRate = float64(wins) / float64(len(trades))
+	}
+	if wins > 0 && losses > 0 {
+		record.ProfitLossRatio = (gain / float64(wins)) / (loss / float64(losses))
+	}
+}
+
+// runPairBacktest 加载两腿日K线并回测配对交易策略
+// 回测区间之前多取数据用于估计对冲比率与 z-score，区间首日即可交易；每条腿加载完成与每个交易日处理完成时报告进度。
+// 日K线以列式加载，两腿按交易日归并对齐，封板状态与成交基准价按列下标读取；
+// 成交价模型为 vwap/twap 时另外加载回测区间内两腿的1分钟K线计算每日成交基准价。
+func (s *BacktestService) runPairBacktest(ctx context.Context, record *models.BacktestRecord, strategy *models.Strategy, reporter progress.Reporter) (*pairs.Result, error) {
+	cfg, err := pairs.ParseConfig(strategy.Params, strategy.SymbolList())
+	if err != nil {
+		return nil, err
+	}
+
+	fetchStart := record.StartDate.AddDate(0, 0, -cfg.Warmup()*2)
+	end := record.EndDate.Add(24*time.Hour - time.Nanosecond)
+	legs := make([]*columnar.Daily, 2)
+	locks := make([][]int, 2)
+	fills := make([][]float64, 2)
+	for i, leg := range []string{cfg.LegA, cfg.LegB} {
+		symbol, exchange, _ := pairs.SplitLeg(leg)
+		if legs[i], err = s.bars.DailyColumns(ctx, symbol, exchange, fetchStart, end); err != nil {
+			return nil, fmt.Errorf("查询 %s 行情失败: %w", leg, err)
+		}
+		if locks[i], err = s.limitLocks(ctx, symbol, exchange, legs[i]); err != nil {
+			return nil, err
+		}
+		if cfg.FillPrice != pairs.FillClose {
+			if fills[i], err = s.fillBenchmarks(ctx, symbol, exchange, cfg.FillPrice, record.StartDate, record.EndDate, legs[i]); err != nil {
+				return nil, err
+			}
+		}
+		reporter.Symbol(leg, i+1, 2)
+	}
+
+	ia, ib := columnar.IntersectIndex(legs[0], legs[1])
+	closesA := make([]float64, len(ia))
+	closesB := make([]float64, len(ia))
+	for k := range ia {
+		closesA[k], closesB[k] = legs[0].Close[ia[k]], legs[1].Close[ib[k]]
+	}
+	series := pairs.SpreadSeries(closesA, closesB, cfg)
+	offset := len(ia) - len(series)
+	start := columnar.DayOf(record.StartDate)
+	var points []*pairs.Point
+	for k, p := range series {
+		a, b := ia[offset+k], ib[offset+k]
+		if legs[0].Days[a] < start {
+			continue
+		}
+		p.Date = legs[0].Days[a].String()
+		p.LockA, p.LockB = locks[0][a], locks[1][b]
+		if fills[0] != nil {
+			p.FillA, p.FillB = fills[0][a], fills[1][b]
+		}
+		points = append(points, p)
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("%s 与 %s 在回测区间内没有足够的共同交易日", cfg.LegA, cfg.LegB)
+	}
+
+	return pairs.Backtest(points, cfg, record.InitialCapital, reporter), nil
+}
+
+// fillBenchmarks 由1分钟K线计算区间内每个交易日的全天 VWAP 或 TWAP，按下标与 bars 各列对齐
+// 缺少分钟数据的交易日为 0，回测时按收盘价成交。
+func (s *BacktestService) fillBenchmarks(ctx context.Context, symbol, exchange, fillPrice string, start, end time.Time, daily *columnar.Daily) ([]float64, error) {
+	from := markettime.StartOfDate(start, exchange)
+	to := markettime.StartOfDate(end, exchange).AddDate(0, 0, 1).Add(-time.Second)
+	minutes, err := s.bars.GetMinuteBars(ctx, symbol, exchange, "1m", from, to)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s.%s 分钟行情失败: %w", symbol, exchange, err)
+	}
+	fills := make([]float64, daily.Len())
+	for date, price := range indicator.DailyIntraday(fillPrice, indicator.FromMinuteBars(minutes), markettime.Location(exchange)) {
+		day, err := time.Parse("2006-01-02", date)
+		if err != nil {
+			continue
+		}
+		if i, ok := daily.Index(columnar.DayOf(day)); ok {
+			fills[i] = price
+		}
+	}
+	return fills, nil
+}
+
+// limitLocks 计算股票在各交易日是否收于涨停或跌停，按下标与 bars 各列对齐，涨跌幅比例按当日的风险警示状态确定
+func (s *BacktestService) limitLocks(ctx context.Context, symbol, exchange string, bars *columnar.Daily) ([]int, error) {
+	warnings, err := s.stockRepo.GetRiskWarningHistory(ctx, symbol, exchange)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s.%s 风险警示失败: %w", symbol, exchange, err)
+	}
+	locks := make([]int, bars.Len())
+	for i := 1; i < bars.Len(); i++ {
+		date := bars.Days[i].Time()
+		st := false
+		for _, w := range warnings {
+			if w.ActiveOn(date) {
+				st = true
+				break
+			}
+		}
+		locks[i] = pricelimit.Lock(bars.Close[i], bars.Close[i-1], pricelimit.Ratio(symbol, exchange, st))
+	}
+	return locks, nil
+}
+
+// simulatedDuration 模拟回测的总耗时，在此期间逐日报告进度
+const simulatedDuration = 2 * time.Second
+
+// runSimulatedBacktest 模拟回测：生成净值曲线后在 simulatedDuration 内逐个交易日报告进度
+// 回测引擎接入前的占位实现，不加载K线（列式K线目前只用于配对回测与基准）；ctx 取消时中断并返回其错误。
+func runSimulatedBacktest(ctx context.Context, record *models.BacktestRecord, seed string, totalReturn float64, reporter progress.Reporter) ([]string, []float64, error) {
+	dates := tradingDates(record.StartDate, record.EndDate)
+	equity := simulateEquityCurve(record.InitialCapital, totalReturn, len(dates)-1, seed)
+
+	pause := simulatedDuration / time.Duration(len(dates))
+	timer := time.NewTimer(pause)
+	defer timer.Stop()
+	for i, date := range dates {
+		select {
+		case <-timer.C:
+			timer.Reset(pause)
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		reporter.Bar(date, i+1, len(dates), equity[i])
+	}
+	return dates, equity, nil
+}
+
+// simulateEquityCurve 生成模拟的每日净值曲线，期末收益率为 totalReturn
+// 回测引擎接入前的占位实现；同一任务ID生成的曲线固定，便于复现。
+func simulateEquityCurve(initialCapital, totalReturn float64, days int, seed string) []float64 {
+	if days < 1 {
+		days = 1
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+
+	equity := make([]float64, days+1)
+	equity[0] = initialCapital
+	for i := 1; i <= days; i++ {
+		equity[i] = equity[i-1] * (1 + rng.NormFloat64()*0.015)
+	}
+
+	// 按几何平均逐日调整，使期末净值与目标收益一致
+	adjust := math.Pow(initialCapital*(1+totalReturn)/equity[days], 1/float64(days))
+	for i := 1; i <= days; i++ {
+		equity[i] *= math.Pow(adjust, float64(i))
+	}
+	return equity
+}
+
+// tradingDates 区间内的工作日（近似交易日），不足两天时以起止日期补齐
+func tradingDates(start, end time.Time) []string {
+	var dates []string
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+			dates = append(dates, d.Format("2006-01-02"))
+		}
+	}
+	if len(dates) < 2 {
+		dates = []string{start.Format("2006-01-02"), end.Format("2006-01-02")}
+	}
+	return dates
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/columnar"
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/pricelimit"
+	"stock-analysis-system/backend/pkg/progress"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// fakeMarketRepo 按股票返回内存中的日K线，记录实际查询次数
+type fakeMarketRepo struct {
+	repository.MarketRepository
+	bars    map[string][]*models.DailyBar // symbol.exchange -> 按时间升序的日K线
+	queries atomic.Int64
+}
+
+func (r *fakeMarketRepo) GetDailyBars(_ context.Context, symbol, exchange string, start, end time.Time) ([]*models.DailyBar, error) {
+	r.queries.Add(1)
+	var bars []*models.DailyBar
+	for _, bar := range r.bars[symbol+"."+exchange] {
+		if !bar.Date.Before(start) && !bar.Date.After(end) {
+			bars = append(bars, bar)
+		}
+	}
+	return bars, nil
+}
+
+// fakeSyncJobRepo 没有修正过任何K线
+type fakeSyncJobRepo struct {
+	repository.SyncJobRepository
+}
+
+func (fakeSyncJobRepo) GetLatestID(context.Context, string, string, string) (uint, error) {
+	return 0, nil
+}
+
+// fakeStockRepo 没有风险警示记录
+type fakeStockRepo struct {
+	repository.StockRepository
+}
+
+func (fakeStockRepo) GetRiskWarningHistory(context.Context, string, string) ([]*models.StockRiskWarning, error) {
+	return nil, nil
+}
+
+// syntheticMarket 生成 n 只股票的工作日日K线随机游走，每只股票随机停牌约 2% 的交易日，返回各股票的 symbol.exchange
+func syntheticMarket(n, days int) (*fakeMarketRepo, []string) {
+	rng := rand.New(rand.NewSource(42))
+	repo := &fakeMarketRepo{bars: make(map[string][]*models.DailyBar, n)}
+	legs := make([]string, n)
+	start := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	for s := range legs {
+		symbol := fmt.Sprintf("%06d", 600000+s)
+		legs[s] = symbol + ".SH"
+		price := 10 + rng.Float64()*90
+		bars := make([]*models.DailyBar, 0, days)
+		for i, date := 0, start; i < days; date = date.AddDate(0, 0, 1) {
+			if wd := date.Weekday(); wd == time.Saturday || wd == time.Sunday {
+				continue
+			}
+			i++
+			price *= math.Exp(rng.NormFloat64() * 0.02)
+			if rng.Float64() < 0.02 {
+				continue
+			}
+			bars = append(bars, &models.DailyBar{
+				Symbol: symbol, Exchange: "SH", Date: date,
+				Open: price, High: price * 1.01, Low: price * 0.99, Close: price,
+				Volume: 1e6, Amount: price * 1e6,
+			})
+		}
+		repo.bars[legs[s]] = bars
+	}
+	return repo, legs
+}
+
+// newSweepService 只带配对回测所需仓库的回测服务，cacheMB 不大于 0 时不缓存K线
+func newSweepService(repo *fakeMarketRepo, cacheMB int) *BacktestService {
+	return &BacktestService{
+		bars:      newBarLoader(repo, fakeSyncJobRepo{}, config.BacktestConfig{BarCacheMB: cacheMB, BarCacheTTL: 3600}),
+		stockRepo: fakeStockRepo{},
+	}
+}
+
+// sweepParams 扫描的配对参数（z-score 窗口、开仓阈值）
+var sweepParams = []struct {
+	zWindow int
+	entryZ  float64
+}{{10, 1.5}, {20, 2}, {20, 2.5}, {40, 2}}
+
+// pairRunner 回测一个配对策略
+type pairRunner func(ctx context.Context, record *models.BacktestRecord, strategy *models.Strategy) (*pairs.Result, error)
+
+// engineRunner 经过回测服务的列式K线加载与对齐
+func engineRunner(s *BacktestService) pairRunner {
+	return func(ctx context.Context, record *models.BacktestRecord, strategy *models.Strategy) (*pairs.Result, error) {
+		return s.runPairBacktest(ctx, record, strategy, progress.Nop{})
+	}
+}
+
+// barsRunner 改为列式之前的实现，作为对照：逐根日K线（[]*models.DailyBar），收盘价与封板状态按日期字符串索引的 map 对齐
+func barsRunner(repo *fakeMarketRepo) pairRunner {
+	return func(ctx context.Context, record *models.BacktestRecord, strategy *models.Strategy) (*pairs.Result, error) {
+		cfg, err := pairs.ParseConfig(strategy.Params, strategy.SymbolList())
+		if err != nil {
+			return nil, err
+		}
+		fetchStart := record.StartDate.AddDate(0, 0, -cfg.Warmup()*2)
+		end := record.EndDate.Add(24*time.Hour - time.Nanosecond)
+		closes := make([]map[string]float64, 2)
+		locks := make([]map[string]int, 2)
+		for i, leg := range []string{cfg.LegA, cfg.LegB} {
+			symbol, exchange, _ := pairs.SplitLeg(leg)
+			bars, err := repo.GetDailyBars(ctx, symbol, exchange, fetchStart, end)
+			if err != nil {
+				return nil, err
+			}
+			closes[i] = risk.ClosesByDate(bars)
+			locks[i] = pricelimit.Locks(bars, func(time.Time) float64 { return pricelimit.Ratio(symbol, exchange, false) })
+		}
+
+		start := record.StartDate.Format("2006-01-02")
+		var points []*pairs.Point
+		for _, p := range pairs.Spread(closes[0], closes[1], cfg) {
+			if p.Date >= start {
+				p.LockA, p.LockB = locks[0][p.Date], locks[1][p.Date]
+				points = append(points, p)
+			}
+		}
+		return pairs.Backtest(points, cfg, record.InitialCapital, progress.Nop{}), nil
+	}
+}
+
+// sweep 相邻股票两两配对，每对按全部参数各回测一次，按顺序返回回测结果
+func sweep(ctx context.Context, run pairRunner, legs []string, start, end time.Time) ([]*pairs.Result, error) {
+	var results []*pairs.Result
+	for i := 1; i < len(legs); i++ {
+		for _, p := range sweepParams {
+			strategy := &models.Strategy{
+				Type:   "pairs",
+				Params: fmt.Sprintf(`{"leg_a":%q,"leg_b":%q,"z_window":%d,"entry_z":%g}`, legs[i-1], legs[i], p.zWindow, p.entryZ),
+			}
+			record := &models.BacktestRecord{StartDate: start, EndDate: end, InitialCapital: 1e6}
+			result, err := run(ctx, record, strategy)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+func tradeCount(results []*pairs.Result) int {
+	trades := 0
+	for _, result := range results {
+		trades += len(result.Trades)
+	}
+	return trades
+}
+
+// 缓存命中时同一条腿、同一区间只查询一次 InfluxDB，结果与不缓存时相同
+func TestPairSweepCachesBars(t *testing.T) {
+	ctx := context.Background()
+	start, end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	repo, legs := syntheticMarket(4, 3*250)
+	want, err := sweep(ctx, engineRunner(newSweepService(repo, 0)), legs, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := repo.queries.Load(); n != int64(2*(len(legs)-1)*len(sweepParams)) {
+		t.Fatalf("不缓存时查询 %d 次", n)
+	}
+
+	repo.queries.Store(0)
+	got, err := sweep(ctx, engineRunner(newSweepService(repo, 64)), legs, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tradeCount(got) != tradeCount(want) {
+		t.Errorf("缓存后交易数 %d，期望 %d", tradeCount(got), tradeCount(want))
+	}
+	// 预热期不同的参数加载的区间不同，每只股票每个预热期查询一次
+	warmups := make(map[int]bool)
+	for _, p := range sweepParams {
+		warmups[p.zWindow] = true
+	}
+	if n, want := repo.queries.Load(), int64(len(legs)*len(warmups)); n != want {
+		t.Errorf("缓存后查询 %d 次，期望每只股票每个预热期一次即 %d 次", n, want)
+	}
+}
+
+// 按列下标对齐的封板状态与逐根K线按日期 map 对齐的结果一致
+func TestPairBacktestMatchesBars(t *testing.T) {
+	ctx := context.Background()
+	start, end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)
+	repo, legs := syntheticMarket(4, 3*250)
+	// 第二只股票连续两日收于涨停、跌停，其余股票在这两日停牌
+	bars := repo.bars[legs[1]]
+	limitDay := 400
+	bars[limitDay].Close = math.Floor(bars[limitDay-1].Close*110+0.5) / 100
+	bars[limitDay+1].Close = math.Floor(bars[limitDay].Close*90+0.5) / 100
+	for _, leg := range []string{legs[0], legs[2]} {
+		var kept []*models.DailyBar
+		for _, bar := range repo.bars[leg] {
+			if !bar.Date.Equal(bars[limitDay].Date) {
+				kept = append(kept, bar)
+			}
+		}
+		repo.bars[leg] = kept
+	}
+
+	want, err := sweep(ctx, barsRunner(repo), legs, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newSweepService(repo, 64)
+	got, err := sweep(ctx, engineRunner(s), legs, start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Fatalf("第 %d 次回测结果与逐根K线不一致", i+1)
+		}
+	}
+
+	daily, err := s.bars.DailyColumns(ctx, bars[0].Symbol, "SH", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locks, err := s.limitLocks(ctx, bars[0].Symbol, "SH", daily)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, ok := daily.Index(columnar.DayOf(bars[limitDay].Date))
+	if !ok || len(locks) != daily.Len() || locks[i] != 1 || locks[i+1] != -1 {
+		t.Errorf("封板状态未按下标对齐: %v", locks[i:i+2])
+	}
+}
+
+// ============ 基准：5 年、300 只股票相邻两两配对的参数扫描，经过引擎的K线加载、对齐与撮合 ============
+
+var benchTrades int
+
+func benchmarkPairSweep(b *testing.B, run func(*fakeMarketRepo) pairRunner) {
+	ctx := context.Background()
+	start, end := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	repo, legs := syntheticMarket(300, 5*250)
+	runner := run(repo)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		results, err := sweep(ctx, runner, legs, start, end)
+		if err != nil {
+			b.Fatal(err)
+		}
+		benchTrades += tradeCount(results)
+	}
+}
+
+// 对照：逐根日K线、按日期 map 对齐，即改为列式之前的实现
+func BenchmarkPairSweepBars(b *testing.B) {
+	benchmarkPairSweep(b, barsRunner)
+}
+
+// 每次回测都重新查询并转换为列式
+func BenchmarkPairSweepUncached(b *testing.B) {
+	benchmarkPairSweep(b, func(repo *fakeMarketRepo) pairRunner { return engineRunner(newSweepService(repo, 0)) })
+}
+
+// 首轮之后全部命中进程内的列式K线缓存
+func BenchmarkPairSweepCached(b *testing.B) {
+	benchmarkPairSweep(b, func(repo *fakeMarketRepo) pairRunner { return engineRunner(newSweepService(repo, 1024)) })
+}
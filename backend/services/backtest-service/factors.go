@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/factor"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/risk"
+	"stock-analysis-system/backend/pkg/universe"
+)
+
+// ============ 回测因子暴露 ============
+
+// backtestParams 回测参数，保存在回测记录的 params 字段
+type backtestParams struct {
+	Symbols         []string `json:"symbols"`                     // 引用股票池时为回测结束日的时点成分
+	UniverseID      uint     `json:"universe_id,omitempty"`       // 引用的股票池
+	ExcludeST       bool     `json:"exclude_st,omitempty"`        // 策略排除风险警示股票
+	ExcludedST      []string `json:"excluded_st,omitempty"`       // 因处于风险警示被剔除的股票
+	MinQuality      int      `json:"min_quality_score,omitempty"` // 数据质量评分下限
+	ExcludedQuality []string `json:"excluded_quality,omitempty"`  // 因数据质量评分过低被剔除的股票
+	InitialCapital  float64  `json:"initial_capital"`
+	Benchmark       string   `json:"benchmark,omitempty"` // 对比基准：symbol.exchange、basket:{id} 或 universe:{id}
+}
+
+// backtestResultData 回测附加结果，保存在回测记录的 result_data 字段
+type backtestResultData struct {
+	Dates          []string          `json:"dates,omitempty"`     // 净值曲线交易日
+	Equity         []float64         `json:"equity,omitempty"`    // 每日净值
+	Simulated      bool              `json:"simulated,omitempty"` // 净值为模拟曲线（尚未接入回测引擎的策略类型）
+	Calendar       *risk.Calendar    `json:"calendar,omitempty"`  // 月度/年度收益与最长回撤
+	FactorExposure *FactorExposure   `json:"factor_exposure,omitempty"`
+	Universe       *universe.Summary `json:"universe,omitempty"`  // 引用股票池时的成分变化汇总
+	Pair           *pairs.Result     `json:"pair,omitempty"`      // 配对交易净值、交易与信号明细
+	Benchmark      *BenchmarkResult  `json:"benchmark,omitempty"` // 指定基准时的对比结果
+}
+
+// FactorExposure 回测股票池（等权）在回测结束日的因子暴露
+type FactorExposure struct {
+	TradeDate string             `json:"trade_date"` // 使用的因子得分交易日
+	Symbols   []string           `json:"symbols"`
+	Covered   int                `json:"covered"`   // 有因子得分的股票数
+	Exposures map[string]float64 `json:"exposures"` // 因子 -> 加权 z-score
+}
+
+// factorExposure 计算股票池在指定日期（或之前最近有得分的交易日）的因子暴露，无得分时返回 nil
+func (s *BacktestService) factorExposure(ctx context.Context, symbols []string, asOf time.Time) (*FactorExposure, error) {
+	tradeDate, err := s.factorRepo.GetLatestTradeDate(ctx, asOf)
+	if err != nil || tradeDate == nil {
+		return nil, err
+	}
+
+	scores, err := s.factorRepo.GetScoresForSymbols(ctx, *tradeDate, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	weights := make(map[string]float64, len(symbols))
+	for _, key := range symbols {
+		weights[key] = 1
+	}
+	covered := make(map[string]bool)
+	for _, score := range scores {
+		covered[score.Symbol+"."+score.Exchange] = true
+	}
+
+	return &FactorExposure{
+		TradeDate: tradeDate.Format("2006-01-02"),
+		Symbols:   symbols,
+		Covered:   len(covered),
+		Exposures: factor.Exposure(scores, weights),
+	}, nil
+}
+
+// GetBacktestFactors 获取回测股票池的因子暴露
+// 回测完成时已保存的结果优先；历史记录没有保存时按回测结束日即时计算。
+func (s *BacktestService) GetBacktestFactors(c *gin.Context) {
+	record, ok := s.ownedRecord(c)
+	if !ok {
+		return
+	}
+
+	var data backtestResultData
+	if record.ResultData != "" && json.Unmarshal([]byte(record.ResultData), &data) == nil && data.FactorExposure != nil {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": data.FactorExposure})
+		return
+	}
+
+	var params backtestParams
+	if record.Params != "" {
+		_ = json.Unmarshal([]byte(record.Params), &params)
+	}
+	if len(params.Symbols) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "回测未指定股票池"})
+		return
+	}
+
+	exposure, err := s.factorExposure(c.Request.Context(), params.Symbols, record.EndDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询因子得分失败: " + err.Error()})
+		return
+	}
+	if exposure == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "暂无因子得分"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": exposure})
+}
+
+// ownedRecord 读取路径中的回测记录并校验归属，失败时已写入响应
+func (s *BacktestService) ownedRecord(c *gin.Context) (*models.BacktestRecord, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	backtestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "回测ID错误"})
+		return nil, false
+	}
+
+	ctx := c.Request.Context()
+	record, err := s.backtestRepo.GetByID(ctx, uint(backtestID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "回测记录不存在"})
+		return nil, false
+	}
+
+	// 验证权限
+	strategy, _ := s.strategyRepo.GetByID(ctx, record.StrategyID)
+	if strategy == nil || strategy.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权查看"})
+		return nil, false
+	}
+	return record, true
+}
@@ -2,31 +2,64 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"errors"
+	"log"
 	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
-	"syscall"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
+	"stock-analysis-system/backend/pkg/auth"
 	"stock-analysis-system/backend/pkg/config"
 	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/metering"
+	"stock-analysis-system/backend/pkg/middleware"
 	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/progress"
+	"stock-analysis-system/backend/pkg/quota"
 	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/risk"
+	"stock-analysis-system/backend/pkg/server"
+	"stock-analysis-system/backend/pkg/validation"
 )
 
 // BacktestService 回测服务
 type BacktestService struct {
-	cfg            *config.Config
-	dbManager      *database.Manager
-	backtestRepo   repository.BacktestRepository
-	strategyRepo   repository.StrategyRepository
-	jwtSecret      []byte
-	runningJobs    map[string]*BacktestJob
+	cfg           *config.Config
+	dbManager     *database.Manager
+	live          *config.Live
+	backtestRepo  repository.BacktestRepository
+	strategyRepo  repository.StrategyRepository
+	marketRepo    repository.MarketRepository
+	bars          *barLoader // 回测引擎加载行情（列式日K线与进程内缓存）
+	stockRepo     repository.StockRepository
+	portfolioRepo repository.PortfolioRepository
+	factorRepo    repository.FactorRepository
+	universeRepo  repository.UniverseRepository
+	basketRepo    repository.BasketRepository
+	keys          *auth.KeySet
+	quotas        *quota.Checker
+	tasks         *jobs.Tracker   // 回测任务同时登记为异步任务，供 /api/v1/tasks 统一查询
+	locker        *lock.Locker    // 多实例部署时选举定时回归回测的主节点
+	leader        *lock.Leader    // 未启用定期回归时为 nil
+	meter         *metering.Meter // 按用户统计回测计算时长
+	stopMeter     context.CancelFunc
+	reportFont    string // 回测报告 PDF 使用的中文字体文件
+	runningJobs   map[string]*BacktestJob
+	jobsMu        sync.RWMutex
+	jobsWG        sync.WaitGroup
+	jobCtx        context.Context // 服务关闭时取消，通知运行中的回测中断
+	cancelJobs    context.CancelFunc
 }
 
 // BacktestJob 回测任务
@@ -39,82 +72,126 @@ type BacktestJob struct {
 	Result     *models.BacktestRecord `json:"result,omitempty"`
 	CreatedAt  time.Time `json:"created_at"`
 	UpdatedAt  time.Time `json:"updated_at"`
+
+	CurrentDate   string        `json:"current_date,omitempty"` // 当前模拟到的交易日
+	Equity        float64       `json:"equity,omitempty"`       // 当前模拟日期的净值
+	BarsProcessed int           `json:"bars_processed"`         // 已处理的交易日数
+	BarsTotal     int           `json:"bars_total"`             // 回测区间的交易日数，开始模拟前为 0
+	SymbolsLoaded int           `json:"symbols_loaded"`         // 已加载行情的股票数
+	SymbolsTotal  int           `json:"symbols_total"`          // 需要加载行情的股票数，不逐只加载的策略为 0
+	changed       chan struct{} // 状态变化时关闭并替换，唤醒进度推送连接
+	task          *jobs.Task    // 登记的异步任务，ID 与任务ID相同
 }
 
 // NewBacktestService 创建回测服务
 func NewBacktestService(cfg *config.Config) (*BacktestService, error) {
+	keys, err := auth.LoadKeySet(cfg.Auth, cfg.Server.Mode)
+	if err != nil {
+		return nil, err
+	}
+
 	dbManager, err := database.NewManager(&cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
+	// 配置文件热更新
+	live, err := config.NewLive(cfg)
+	if err != nil {
+		dbManager.Close()
+		return nil, err
+	}
+	live.OnChange(func(c *config.Config) { dbManager.SetLogLevel(c.Log.Level) })
+
 	backtestRepo := repository.NewBacktestRepository(dbManager.Postgres.DB)
 	strategyRepo := repository.NewStrategyRepository(dbManager.Postgres.DB)
-	jwtSecret := []byte(getEnv("JWT_SECRET", "your-secret-key"))
+	marketRepo := repository.NewMarketRepository(dbManager.Influx)
+	stockRepo := repository.NewStockRepository(dbManager.Postgres.DB)
+	portfolioRepo := repository.NewPortfolioRepository(dbManager.Postgres.DB)
+	factorRepo := repository.NewFactorRepository(dbManager.Postgres.DB)
+	universeRepo := repository.NewUniverseRepository(dbManager.Postgres.DB)
+	basketRepo := repository.NewBasketRepository(dbManager.Postgres.DB)
+
+	// 上次退出时未完成的回测不会再继续，统一标记为失败
+	if n, err := backtestRepo.FailRunning(context.Background()); err != nil {
+		log.Printf("恢复中断的回测记录失败: %v", err)
+	} else if n > 0 {
+		log.Printf("已将 %d 条中断的回测记录标记为失败", n)
+	}
+
+	jobCtx, cancelJobs := context.WithCancel(context.Background())
+
+	meter := metering.NewMeter(repository.NewUsageRepository(dbManager.Postgres.DB))
+	meterCtx, stopMeter := context.WithCancel(context.Background())
+	go meter.Run(meterCtx, metering.DefaultFlushInterval)
 
 	return &BacktestService{
-		cfg:          cfg,
-		dbManager:    dbManager,
-		backtestRepo: backtestRepo,
-		strategyRepo: strategyRepo,
-		jwtSecret:    jwtSecret,
-		runningJobs:  make(map[string]*BacktestJob),
+		cfg:           cfg,
+		dbManager:     dbManager,
+		live:          live,
+		backtestRepo:  backtestRepo,
+		strategyRepo:  strategyRepo,
+		marketRepo:    marketRepo,
+		bars:          newBarLoader(marketRepo, repository.NewSyncJobRepository(dbManager.Postgres.DB), cfg.Backtest),
+		stockRepo:     stockRepo,
+		portfolioRepo: portfolioRepo,
+		factorRepo:    factorRepo,
+		universeRepo:  universeRepo,
+		basketRepo:    basketRepo,
+		keys:          keys,
+		quotas:        quota.NewChecker(repository.NewQuotaRepository(dbManager.Postgres.DB)),
+		tasks:         jobs.NewTracker(repository.NewTaskRepository(dbManager.Postgres.DB), "backtest-service"),
+		locker:        lock.New(dbManager.Redis.GetClient(), "backtest-service"),
+		meter:         meter,
+		stopMeter:     stopMeter,
+		reportFont:    getEnv("REPORT_FONT_PATH", ""),
+		runningJobs:   make(map[string]*BacktestJob),
+		jobCtx:        jobCtx,
+		cancelJobs:    cancelJobs,
 	}, nil
 }
 
+// Shutdown 等待运行中的回测任务结束；超时后中断剩余任务并将其标记为失败
+func (s *BacktestService) Shutdown(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.jobsWG.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Println("等待回测任务超时，中断剩余任务")
+		s.cancelJobs()
+		<-done
+	}
+}
+
 // Close 关闭服务
 func (s *BacktestService) Close() {
+	s.live.Close()
+	s.stopMeter()
+	if err := s.meter.Flush(context.Background()); err != nil {
+		log.Printf("写入用量统计失败: %v", err)
+	}
 	if s.dbManager != nil {
 		s.dbManager.Close()
 	}
 }
 
-// AuthMiddleware JWT认证中间件
-func (s *BacktestService) AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "缺少认证信息"})
-			c.Abort()
-			return
-		}
-
-		tokenString := ""
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
-		} else {
-			tokenString = authHeader
-		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return s.jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "无效的认证信息"})
-			c.Abort()
-			return
-		}
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userID, ok := claims["user_id"].(float64); ok {
-				c.Set("user_id", uint(userID))
-			}
-		}
-
-		c.Next()
-	}
-}
-
 // ============ 回测任务接口 ============
 
 // RunBacktestRequest 运行回测请求
 type RunBacktestRequest struct {
-	StrategyID    uint     `json:"strategy_id" binding:"required"`
-	StartDate     string   `json:"start_date" binding:"required"` // YYYY-MM-DD
-	EndDate       string   `json:"end_date" binding:"required"`
-	Symbols       []string `json:"symbols"`
-	InitialCapital float64 `json:"initial_capital"` // 默认 100000
+	StrategyID     uint     `json:"strategy_id" binding:"required"`
+	StartDate      string   `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate        string   `json:"end_date" binding:"required"`
+	Symbols        []string `json:"symbols"`
+	UniverseID     uint     `json:"universe_id"`                               // 引用股票池，按时点成分回测；未指定时依次使用 symbols、策略的股票池、策略的股票列表
+	InitialCapital float64  `json:"initial_capital"`                           // 默认 100000
+	MinQuality     int      `json:"min_quality_score" binding:"min=0,max=100"` // 剔除数据质量评分低于该值的股票，0 表示不限制
+	Benchmark      string   `json:"benchmark"`                                 // 对比基准：symbol.exchange、basket:{id} 或 universe:{id}（自己的篮子或股票池）
 }
 
 // RunBacktest 运行回测
@@ -128,9 +205,9 @@ func (s *BacktestService) RunBacktest(c *gin.Context) {
 		return
 	}
 
-	// 验证策略存在且属于当前用户
+	// 验证策略存在且属于当前用户，策略可能刚刚创建，读主库
 	ctx := c.Request.Context()
-	strategy, err := s.strategyRepo.GetByID(ctx, req.StrategyID)
+	strategy, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), req.StrategyID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
 		return
@@ -140,24 +217,114 @@ func (s *BacktestService) RunBacktest(c *gin.Context) {
 		return
 	}
 
-	// 解析日期
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	// 校验回测区间
+	dateRange, err := validation.ParseDateRange(req.StartDate, req.EndDate, validation.RangeRule{
+		Required: true,
+		MaxDays:  365 * 20,
+	})
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "开始日期格式错误"})
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
 		return
 	}
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "结束日期格式错误"})
+	startDate := dateRange.Start
+	endDate := dateRange.End.Truncate(24 * time.Hour)
+
+	// 免费套餐只能回测最近若干年的数据
+	if err := s.quotas.CheckRange(ctx, uid, startDate); err != nil {
+		middleware.AbortQuota(c, err)
 		return
 	}
 
+	// 校验对比基准
+	if req.Benchmark != "" {
+		if status, err := s.checkBenchmark(ctx, uid, req.Benchmark); err != nil {
+			c.JSON(status, gin.H{"code": status, "msg": err.Error()})
+			return
+		}
+	}
+
 	// 设置默认初始资金
 	initialCapital := req.InitialCapital
 	if initialCapital <= 0 {
 		initialCapital = 100000
 	}
 
+	// 股票池：显式指定的股票池或股票列表优先，其次为策略引用的股票池，最后为策略配置的股票
+	universeID := req.UniverseID
+	if universeID == 0 && len(req.Symbols) == 0 && strategy.UniverseID != nil {
+		universeID = *strategy.UniverseID
+	}
+	symbols := req.Symbols
+	if universeID != 0 {
+		if strategy.Type == pairs.StrategyType {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "配对交易策略不支持股票池"})
+			return
+		}
+		u, err := s.universeRepo.GetByID(ctx, universeID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "股票池不存在"})
+			return
+		}
+		if u.UserID != uid {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权使用该股票池"})
+			return
+		}
+		timeline, err := s.universeTimeline(ctx, universeID, startDate, endDate)
+		if err != nil {
+			if errors.Is(err, errNoUniverseSnapshot) {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+			}
+			return
+		}
+		symbols = timeline.At(endDate.Format(validation.DateLayout))
+	} else if len(symbols) == 0 {
+		symbols = strategy.SymbolList()
+	}
+
+	// 策略排除 ST：配对交易两腿在回测开始日不得处于风险警示，其他策略剔除结束日处于风险警示的股票
+	var excludedST []string
+	if strategy.ExcludeST {
+		asOf := endDate
+		if strategy.Type == pairs.StrategyType {
+			asOf = startDate
+		}
+		symbols, excludedST, err = s.excludeST(ctx, symbols, asOf)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+			return
+		}
+		if strategy.Type == pairs.StrategyType && len(excludedST) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "配对股票在回测开始日处于风险警示: " + strings.Join(excludedST, ",")})
+			return
+		}
+	}
+
+	// 剔除数据质量评分过低的股票，配对交易任一腿不达标时拒绝回测
+	var excludedQuality []string
+	if req.MinQuality > 0 {
+		symbols, excludedQuality, err = s.excludeLowQuality(ctx, symbols, req.MinQuality)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+			return
+		}
+		if strategy.Type == pairs.StrategyType && len(excludedQuality) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "配对股票数据质量评分过低: " + strings.Join(excludedQuality, ",")})
+			return
+		}
+	}
+	params, _ := json.Marshal(&backtestParams{
+		Symbols:         symbols,
+		UniverseID:      universeID,
+		ExcludeST:       strategy.ExcludeST,
+		ExcludedST:      excludedST,
+		MinQuality:      req.MinQuality,
+		ExcludedQuality: excludedQuality,
+		InitialCapital:  initialCapital,
+		Benchmark:       req.Benchmark,
+	})
+
 	// 生成任务ID
 	jobID := uuid.New().String()
 
@@ -167,6 +334,8 @@ func (s *BacktestService) RunBacktest(c *gin.Context) {
 		StartDate:      startDate,
 		EndDate:        endDate,
 		InitialCapital: initialCapital,
+		Params:         string(params),
+		ResultData:     "{}",
 		Status:         "running",
 	}
 
@@ -184,10 +353,15 @@ func (s *BacktestService) RunBacktest(c *gin.Context) {
 		Progress:   0,
 		CreatedAt:  time.Now(),
 		UpdatedAt:  time.Now(),
+		changed:    make(chan struct{}),
+		task:       s.tasks.Start(ctx, jobs.Spec{ID: jobID, Type: models.TaskTypeBacktest, Name: strategy.Type, OwnerID: uid}),
 	}
+	s.jobsMu.Lock()
 	s.runningJobs[jobID] = job
+	s.jobsMu.Unlock()
 
 	// 异步执行回测
+	s.jobsWG.Add(1)
 	go s.executeBacktest(job, record, strategy)
 
 	c.JSON(http.StatusOK, gin.H{
@@ -204,44 +378,121 @@ func (s *BacktestService) RunBacktest(c *gin.Context) {
 
 // executeBacktest 执行回测（模拟）
 func (s *BacktestService) executeBacktest(job *BacktestJob, record *models.BacktestRecord, strategy *models.Strategy) {
+	defer s.jobsWG.Done()
 	ctx := context.Background()
 
-	// 模拟回测过程
-	time.Sleep(2 * time.Second)
+	// 无论成功与否都按实际执行时长计入用户的回测用量
+	start := time.Now()
+	defer func() {
+		s.meter.Record(job.UserID, metering.Counters{BacktestSeconds: time.Since(start).Seconds()})
+	}()
+
+	resultData, err := s.runStrategy(s.jobCtx, record, strategy, job.ID, s.jobProgress(job))
+	if err != nil {
+		log.Printf("回测 %d 执行失败: %v", record.ID, err)
+		s.failJob(ctx, job, record, err)
+		return
+	}
+
+	resultData.Calendar = risk.NewCalendar(resultData.Dates, resultData.Equity)
+
+	// 股票池在回测结束日的因子暴露，没有因子得分时跳过
+	var params backtestParams
+	_ = json.Unmarshal([]byte(record.Params), &params)
+	if params.UniverseID != 0 {
+		resultData.Universe = s.universeSummary(ctx, params.UniverseID, record.StartDate, record.EndDate)
+	}
+	if len(params.Symbols) > 0 {
+		exposure, err := s.factorExposure(ctx, params.Symbols, record.EndDate)
+		if err != nil {
+			log.Printf("计算回测 %d 因子暴露失败: %v", record.ID, err)
+		}
+		resultData.FactorExposure = exposure
+	}
+	if params.Benchmark != "" {
+		benchmark, err := s.benchmarkResult(ctx, params.Benchmark, record.StartDate, record.EndDate, resultData.Dates, resultData.Equity)
+		if err != nil {
+			log.Printf("计算回测 %d 基准对比失败: %v", record.ID, err)
+		}
+		resultData.Benchmark = benchmark
+	}
+	if data, err := json.Marshal(resultData); err == nil {
+		record.ResultData = string(data)
+	}
+
+	record.Status = "completed"
+	now := time.Now()
+	record.CompletedAt = &now
+
+	// 更新数据库
+	if err := s.backtestRepo.Update(ctx, record); err != nil {
+		s.updateJob(job, func(job *BacktestJob) { job.Status = "failed" })
+		job.task.Fail(fmt.Errorf("保存回测结果失败: %w", err), "backtest", strconv.FormatUint(uint64(record.ID), 10))
+		return
+	}
+	job.task.Succeed("backtest", strconv.FormatUint(uint64(record.ID), 10))
+
+	// 更新任务状态
+	s.updateJob(job, func(job *BacktestJob) {
+		job.Status = "completed"
+		job.Progress = 100
+		job.Result = record
+	})
+}
+
+// runStrategy 按策略类型执行回测，绩效指标写入 record，返回净值曲线等结果数据
+// seed 决定模拟净值曲线的形状；ctx 取消时中断并返回其错误。
+func (s *BacktestService) runStrategy(ctx context.Context, record *models.BacktestRecord, strategy *models.Strategy, seed string, reporter progress.Reporter) (*backtestResultData, error) {
+	var resultData backtestResultData
+	if strategy.Type == pairs.StrategyType {
+		// 配对交易：按两腿日K线回测价差交易
+		result, err := s.runPairBacktest(ctx, record, strategy, reporter)
+		if err != nil {
+			return nil, err
+		}
+		applyRiskMetrics(record, result.Equity)
+		applyTradeStats(record, result.Trades)
+		resultData.Dates, resultData.Equity = result.Dates, result.Equity
+		resultData.Pair = result
+		return &resultData, nil
+	}
 
 	// 模拟回测结果
 	totalReturn := 0.15 + (float64(time.Now().Unix()%100) / 1000) // 随机收益率 15-25%
 	tradeCount := 50 + int(time.Now().Unix()%50)
 
-	record.FinalCapital = record.InitialCapital * (1 + totalReturn)
-	record.TotalReturn = totalReturn
-	record.AnnualReturn = totalReturn / float64(record.EndDate.Sub(record.StartDate).Days()/365+1)
-	record.MaxDrawdown = 0.08
-	record.SharpeRatio = 1.2
+	dates, equity, err := runSimulatedBacktest(ctx, record, seed, totalReturn, reporter)
+	if err != nil {
+		return nil, err
+	}
+	applyRiskMetrics(record, equity)
+	resultData.Dates, resultData.Equity, resultData.Simulated = dates, equity, true
 	record.WinRate = 0.55
 	record.ProfitLossRatio = 1.8
 	record.TradeCount = tradeCount
-	record.Status = "completed"
+	return &resultData, nil
+}
+
+// failJob 将失败或被中断的回测任务及其记录标记为失败
+func (s *BacktestService) failJob(ctx context.Context, job *BacktestJob, record *models.BacktestRecord, jobErr error) {
 	now := time.Now()
+	record.Status = "failed"
 	record.CompletedAt = &now
-
-	// 更新数据库
 	if err := s.backtestRepo.Update(ctx, record); err != nil {
-		job.Status = "failed"
-		return
+		log.Printf("保存中断的回测记录失败: %v", err)
 	}
 
-	// 更新任务状态
-	job.Status = "completed"
-	job.Progress = 100
-	job.Result = record
-	job.UpdatedAt = time.Now()
+	s.updateJob(job, func(job *BacktestJob) { job.Status = "failed" })
+	job.task.Fail(jobErr, "backtest", strconv.FormatUint(uint64(record.ID), 10))
 }
 
 // GetBacktestStatus 获取回测状态
 func (s *BacktestService) GetBacktestStatus(c *gin.Context) {
 	jobID := c.Param("id")
 
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+
 	job, exists := s.runningJobs[jobID]
 	if !exists {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "任务不存在"})
@@ -251,43 +502,51 @@ func (s *BacktestService) GetBacktestStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
-			"job_id":    job.ID,
-			"status":    job.Status,
-			"progress":  job.Progress,
-			"created_at": job.CreatedAt.Format(time.RFC3339),
-			"updated_at": job.UpdatedAt.Format(time.RFC3339),
+			"job_id":         job.ID,
+			"status":         job.Status,
+			"progress":       job.Progress,
+			"current_date":   job.CurrentDate,
+			"equity":         job.Equity,
+			"bars_processed": job.BarsProcessed,
+			"bars_total":     job.BarsTotal,
+			"symbols_loaded": job.SymbolsLoaded,
+			"symbols_total":  job.SymbolsTotal,
+			"created_at":     job.CreatedAt.Format(time.RFC3339),
+			"updated_at":     job.UpdatedAt.Format(time.RFC3339),
 		},
 	})
 }
 
+// backtestResult 回测结果，在回测记录之外附带解析后的收益日历
+type backtestResult struct {
+	*models.BacktestRecord
+	Calendar  *risk.Calendar `json:"calendar,omitempty"`
+	Benchmark *benchmarkView `json:"benchmark,omitempty"` // 指定基准时与基准的对比
+}
+
 // GetBacktestResult 获取回测结果
+// 早于收益日历上线的记录只要保存了净值曲线，就按净值即时计算。
 func (s *BacktestService) GetBacktestResult(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	uid := userID.(uint)
-
-	backtestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "回测ID错误"})
-		return
-	}
-
-	ctx := c.Request.Context()
-	record, err := s.backtestRepo.GetByID(ctx, uint(backtestID))
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "回测记录不存在"})
+	record, ok := s.ownedRecord(c)
+	if !ok {
 		return
 	}
 
-	// 验证权限
-	strategy, _ := s.strategyRepo.GetByID(ctx, record.StrategyID)
-	if strategy == nil || strategy.UserID != uid {
-		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权查看"})
-		return
+	result := &backtestResult{BacktestRecord: record}
+	var data backtestResultData
+	if record.ResultData != "" && json.Unmarshal([]byte(record.ResultData), &data) == nil {
+		result.Calendar = data.Calendar
+		if result.Calendar == nil && len(data.Equity) > 1 && len(data.Dates) == len(data.Equity) {
+			result.Calendar = risk.NewCalendar(data.Dates, data.Equity)
+		}
+		if data.Benchmark != nil && len(data.Benchmark.Equity) == len(data.Dates) {
+			result.Benchmark = &benchmarkView{BenchmarkResult: data.Benchmark, Dates: data.Dates, StrategyEquity: data.Equity}
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
-		"data": record,
+		"data": result,
 	})
 }
 
@@ -299,6 +558,11 @@ func (s *BacktestService) GetBacktestList(c *gin.Context) {
 	strategyID := c.Query("strategy_id")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	symbol, exchange, err := validation.ParseSymbolQuery(c.Query("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
 
 	if page < 1 {
 		page = 1
@@ -311,16 +575,22 @@ func (s *BacktestService) GetBacktestList(c *gin.Context) {
 
 	var records []*models.BacktestRecord
 	var total int64
-	var err error
 
+	var sid uint64
 	if strategyID != "" {
-		sid, _ := strconv.ParseUint(strategyID, 10, 32)
+		sid, _ = strconv.ParseUint(strategyID, 10, 32)
 		// 验证策略权限
 		strategy, _ := s.strategyRepo.GetByID(ctx, uint(sid))
 		if strategy == nil || strategy.UserID != uid {
 			c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权查看"})
 			return
 		}
+	}
+
+	if symbol != "" {
+		// 按回测股票筛选，可与 strategy_id 同时使用
+		records, total, err = s.backtestRepo.GetBySymbol(ctx, uid, uint(sid), symbol, exchange, page, pageSize)
+	} else if strategyID != "" {
 		records, total, err = s.backtestRepo.GetByStrategyID(ctx, uint(sid), page, pageSize)
 	} else {
 		// 获取用户所有策略的回测记录
@@ -355,60 +625,57 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	defer service.Close()
 
-	if cfg.Server.Mode == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
+	// 定期回归回测
+	ctx, cancel := context.WithCancel(context.Background())
+	service.StartRegressionScheduler(ctx)
 
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(corsMiddleware())
+	port := getEnv("BACKTEST_SERVICE_PORT", "8085")
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"service": "backtest-service",
-		})
-	})
+	srv := server.New("backtest-service", cfg,
+		server.WithPort(port),
+		server.WithWriteTimeout(70*time.Second), // 回测提交接口最长 60s
+		server.WithDatabaseHealth(service.dbManager),
+		server.WithShutdownHook(func(context.Context) { service.Close() }),
+		server.WithShutdownHook(func(ctx context.Context) {
+			// 等待运行中的回测任务，超时后中断并标记为失败
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			service.Shutdown(ctx)
+		}),
+		// 先停止定时回归并释放主节点锁，再关闭 Redis 连接
+		server.WithShutdownHook(func(context.Context) {
+			cancel()
+			service.leader.Wait()
+		}),
+	)
 
 	// API路由
-	api := r.Group("/api/v1")
+	api := srv.Router().Group("/api/v1")
 	{
 		// 回测接口（需要认证）
 		backtest := api.Group("/backtest")
-		backtest.Use(service.AuthMiddleware())
+		backtest.Use(middleware.JWTAuth(service.keys))
 		{
-			backtest.GET("", service.GetBacktestList)
-			backtest.POST("/run", service.RunBacktest)
-			backtest.GET("/status/:id", service.GetBacktestStatus)
-			backtest.GET("/result/:id", service.GetBacktestResult)
+			backtest.GET("", middleware.Timeout(10*time.Second), service.GetBacktestList)
+			backtest.POST("/run", middleware.Timeout(60*time.Second), middleware.Quota(service.quotas, quota.BacktestsPerDay), service.RunBacktest)
+			backtest.GET("/status/:id", middleware.Timeout(5*time.Second), service.GetBacktestStatus)
+			backtest.GET("/status/:id/stream", srv.Streams().Middleware("sse"), service.StreamBacktestStatus)
+			backtest.GET("/result/:id", middleware.Timeout(10*time.Second), service.GetBacktestResult)
+			backtest.GET("/result/:id/factors", middleware.Timeout(10*time.Second), service.GetBacktestFactors)
+			backtest.GET("/result/:id/report", middleware.Timeout(30*time.Second), service.GetBacktestReport)
 		}
-	}
-
-	port := getEnv("BACKTEST_SERVICE_PORT", "8085")
 
-	// 优雅退出
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-	}()
-
-	r.Run(":" + port)
-}
-
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+		// 风险分析接口（需要认证）
+		riskGroup := api.Group("/risk")
+		riskGroup.Use(middleware.JWTAuth(service.keys))
+		{
+			riskGroup.POST("/analyze", middleware.Timeout(30*time.Second), service.AnalyzeRisk)
 		}
-		c.Next()
+	}
+
+	if err := srv.Run(); err != nil {
+		log.Fatalf("服务启动失败: %v", err)
 	}
 }
 
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/server"
+)
+
+// ============ 回测进度推送 ============
+
+const (
+	backtestStreamTimeout   = 10 * time.Minute // 进度推送连接的最长时间，同时放宽该请求的写超时
+	backtestStreamKeepalive = 15 * time.Second // 没有进度变化时发送注释行，避免代理断开空闲连接
+)
+
+// updateJob 在锁内修改任务状态，并唤醒等待进度变化的推送连接
+func (s *BacktestService) updateJob(job *BacktestJob, update func(job *BacktestJob)) {
+	s.jobsMu.Lock()
+	defer s.jobsMu.Unlock()
+	update(job)
+	job.UpdatedAt = time.Now()
+	if job.changed != nil {
+		close(job.changed)
+	}
+	job.changed = make(chan struct{})
+}
+
+// loadWeight 逐只加载行情的策略中，加载阶段占总进度的比例（百分点）
+const loadWeight = 20
+
+// jobProgress 将引擎报告的进度写入回测任务，实现 progress.Reporter
+// 进度百分比取整后有变化时才更新任务，避免逐根K线唤醒推送连接；保存结果前最多到 99。
+type jobProgress struct {
+	s       *BacktestService
+	job     *BacktestJob
+	loaded  bool // 是否经过加载阶段
+	percent int  // 最近一次更新的整数进度
+}
+
+// jobProgress 创建回测任务的进度回调
+func (s *BacktestService) jobProgress(job *BacktestJob) *jobProgress {
+	return &jobProgress{s: s, job: job}
+}
+
+// Symbol 一只股票的行情加载完成，加载阶段按已加载股票数占 0~loadWeight
+func (p *jobProgress) Symbol(symbol string, done, total int) {
+	p.loaded = true
+	p.percent = loadWeight * done / total
+	p.s.updateJob(p.job, func(job *BacktestJob) {
+		job.SymbolsLoaded, job.SymbolsTotal = done, total
+		job.Progress = float64(p.percent)
+	})
+	p.job.task.Progress(float64(p.percent), fmt.Sprintf("已加载 %d/%d 只股票的行情", done, total))
+}
+
+// Bar 一个交易日处理完成，模拟阶段占加载阶段之后到 99 的进度
+func (p *jobProgress) Bar(date string, done, total int, equity float64) {
+	base := 0
+	if p.loaded {
+		base = loadWeight
+	}
+	percent := base + (99-base)*done/total
+	if percent == p.percent && done < total {
+		return
+	}
+	p.percent = percent
+	p.s.updateJob(p.job, func(job *BacktestJob) {
+		job.Progress = float64(percent)
+		job.CurrentDate, job.Equity = date, equity
+		job.BarsProcessed, job.BarsTotal = done, total
+	})
+	p.job.task.Progress(float64(percent), "模拟至 "+date)
+}
+
+// progressEvent 推送的进度事件
+type progressEvent struct {
+	Status        string  `json:"status"`
+	Progress      float64 `json:"progress"`
+	CurrentDate   string  `json:"current_date,omitempty"`
+	Equity        float64 `json:"equity,omitempty"` // 当前模拟日期的净值
+	BarsProcessed int     `json:"bars_processed"`
+	BarsTotal     int     `json:"bars_total"`
+	SymbolsLoaded int     `json:"symbols_loaded"`
+	SymbolsTotal  int     `json:"symbols_total"`
+}
+
+// resultSummary 回测结束时推送的结果摘要
+type resultSummary struct {
+	Status          string  `json:"status"`
+	BacktestID      uint    `json:"backtest_id,omitempty"`
+	FinalCapital    float64 `json:"final_capital,omitempty"`
+	TotalReturn     float64 `json:"total_return,omitempty"`
+	AnnualReturn    float64 `json:"annual_return,omitempty"`
+	MaxDrawdown     float64 `json:"max_drawdown,omitempty"`
+	SharpeRatio     float64 `json:"sharpe_ratio,omitempty"`
+	WinRate         float64 `json:"win_rate,omitempty"`
+	ProfitLossRatio float64 `json:"profit_loss_ratio,omitempty"`
+	TradeCount      int     `json:"trade_count,omitempty"`
+}
+
+// StreamBacktestStatus 以 Server-Sent Events 推送回测进度
+// 进度变化时发送 progress 事件（进度、当前模拟日期与净值），结束时发送 result 事件（结果摘要）并关闭连接。
+func (s *BacktestService) StreamBacktestStatus(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	s.jobsMu.RLock()
+	job, exists := s.runningJobs[c.Param("id")]
+	s.jobsMu.RUnlock()
+	if !exists || job.UserID != uid {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "任务不存在"})
+		return
+	}
+
+	// 服务的写超时按普通接口配置，推送连接单独放宽
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(backtestStreamTimeout))
+	ctx, cancel := context.WithTimeout(c.Request.Context(), backtestStreamTimeout)
+	defer cancel()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // 关闭 Nginx 缓冲
+	stream := server.StreamFrom(c)
+	keepalive := time.NewTicker(backtestStreamKeepalive)
+	defer keepalive.Stop()
+
+	var last progressEvent
+	for {
+		s.jobsMu.RLock()
+		event := progressEvent{
+			Status:        job.Status,
+			Progress:      job.Progress,
+			CurrentDate:   job.CurrentDate,
+			Equity:        job.Equity,
+			BarsProcessed: job.BarsProcessed,
+			BarsTotal:     job.BarsTotal,
+			SymbolsLoaded: job.SymbolsLoaded,
+			SymbolsTotal:  job.SymbolsTotal,
+		}
+		summary := summarize(job)
+		changed := job.changed
+		s.jobsMu.RUnlock()
+
+		if summary != nil {
+			c.SSEvent("result", summary)
+			c.Writer.Flush()
+			return
+		}
+		if event != last {
+			c.SSEvent("progress", event)
+			c.Writer.Flush()
+			last = event
+		}
+
+		select {
+		case <-changed:
+		case <-keepalive.C:
+			c.Writer.WriteString(": keepalive\n\n")
+			c.Writer.Flush()
+		case <-stream.Draining():
+			// 服务正在关闭：提示客户端重连，进度可改为轮询 /api/v1/tasks/:id
+			stream.WriteSSE(c.Writer)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// summarize 已结束任务的结果摘要，未结束时返回 nil，调用方需持有 jobsMu
+func summarize(job *BacktestJob) *resultSummary {
+	switch job.Status {
+	case "completed":
+		r := job.Result
+		return &resultSummary{
+			Status:          job.Status,
+			BacktestID:      r.ID,
+			FinalCapital:    r.FinalCapital,
+			TotalReturn:     r.TotalReturn,
+			AnnualReturn:    r.AnnualReturn,
+			MaxDrawdown:     r.MaxDrawdown,
+			SharpeRatio:     r.SharpeRatio,
+			WinRate:         r.WinRate,
+			ProfitLossRatio: r.ProfitLossRatio,
+			TradeCount:      r.TradeCount,
+		}
+	case "failed":
+		return &resultSummary{Status: job.Status}
+	}
+	return nil
+}
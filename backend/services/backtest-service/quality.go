@@ -0,0 +1,25 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// ============ 数据质量约束 ============
+
+// excludeLowQuality 剔除数据质量评分低于 minScore 的股票，返回保留与剔除的股票
+// 使用最近一次评分；未评分的股票保留。
+func (s *BacktestService) excludeLowQuality(ctx context.Context, symbols []string, minScore int) (kept, excluded []string, err error) {
+	scores, err := s.stockRepo.GetQualityScores(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询数据质量评分失败: %w", err)
+	}
+	for _, key := range symbols {
+		if score, ok := scores[key]; ok && score < minScore {
+			excluded = append(excluded, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	return kept, excluded, nil
+}
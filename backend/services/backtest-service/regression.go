@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/progress"
+)
+
+// ============ 策略定期回归回测 ============
+
+// regressionCapital 回归回测的初始资金，各运行日一致以便比较
+const regressionCapital = 100000
+
+// regressionLeaseTTL 回归回测主节点锁的有效期，主节点异常退出后其他实例最迟在此时间后接替
+const regressionLeaseTTL = 30 * time.Second
+
+// StartRegressionScheduler 每天在配置的时刻按滚动窗口重新回测开启了定期回归的策略，随后检查实盘偏离
+// 多实例部署时只由选举出的主节点执行，每天只执行一次。
+func (s *BacktestService) StartRegressionScheduler(ctx context.Context) {
+	hour := s.cfg.Regression.ScheduleHour
+	if hour < 0 {
+		log.Println("未启用策略定期回归回测与实盘偏离检查")
+		return
+	}
+	s.leader = s.locker.Elect(ctx, "backtest-service:regression", regressionLeaseTTL)
+
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				if now.Hour() != hour {
+					continue
+				}
+				s.leader.RunOnce("daily", now.Format("20060102"), 25*time.Hour, func(ctx context.Context, token int64) {
+					log.Printf("执行策略定期回归回测（主节点令牌 %d）", token)
+					// 回归结果带上令牌保存，主节点切换后旧主节点不能覆盖新主节点的结果
+					ctx = lock.WithFence(ctx, token)
+					s.RunRegressions(ctx, now)
+					s.CheckDivergences(ctx, now)
+				})
+			}
+		}
+	}()
+}
+
+// RunRegressions 回测全部开启定期回归的策略，窗口截至运行日前一天；单个策略失败时记录日志后继续
+func (s *BacktestService) RunRegressions(ctx context.Context, now time.Time) {
+	strategies, err := s.strategyRepo.GetRegressionStrategies(ctx)
+	if err != nil {
+		log.Printf("查询定期回归策略失败: %v", err)
+		return
+	}
+
+	runDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	var done int
+	for _, strategy := range strategies {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := s.runRegression(ctx, strategy, runDate); err != nil {
+			if errors.Is(err, lock.ErrFenced) {
+				log.Printf("已失去主节点身份，停止策略定期回归回测: %v", err)
+				return
+			}
+			log.Printf("策略 %d 回归回测失败: %v", strategy.ID, err)
+			continue
+		}
+		done++
+	}
+	log.Printf("策略定期回归回测完成: %d/%d", done, len(strategies))
+}
+
+// runRegression 按策略的滚动窗口回测并保存当日结果
+// 免费套餐的可回测区间有限，窗口起点早于允许范围时跳过。
+func (s *BacktestService) runRegression(ctx context.Context, strategy *models.Strategy, runDate time.Time) error {
+	window := strategy.RegressionWindow
+	if window <= 0 {
+		window = models.DefaultRegressionWindow
+	}
+	end := runDate.AddDate(0, 0, -1)
+	start := end.AddDate(0, 0, -window)
+	if err := s.quotas.CheckRange(ctx, strategy.UserID, start); err != nil {
+		return err
+	}
+
+	record := &models.BacktestRecord{
+		StrategyID:     strategy.ID,
+		StartDate:      start,
+		EndDate:        end,
+		InitialCapital: regressionCapital,
+	}
+	seed := fmt.Sprintf("regression-%d-%s", strategy.ID, runDate.Format("2006-01-02"))
+	if _, err := s.runStrategy(ctx, record, strategy, seed, progress.Nop{}); err != nil {
+		return err
+	}
+
+	return s.strategyRepo.SavePerformance(ctx, &models.StrategyPerformance{
+		StrategyID:   strategy.ID,
+		RunDate:      runDate,
+		StartDate:    start,
+		EndDate:      end,
+		TotalReturn:  record.TotalReturn,
+		AnnualReturn: record.AnnualReturn,
+		MaxDrawdown:  record.MaxDrawdown,
+		SharpeRatio:  record.SharpeRatio,
+		WinRate:      record.WinRate,
+		TradeCount:   record.TradeCount,
+		Fence:        lock.FenceFrom(ctx),
+	})
+}
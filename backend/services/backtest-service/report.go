@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/report"
+)
+
+// ============ 回测报告 ============
+
+// GetBacktestReport 导出回测报告（format=html|pdf，默认 html）
+// 包含绩效指标、净值与回撤曲线、月度收益热力图与交易明细，供非技术人员查看与分享。
+func (s *BacktestService) GetBacktestReport(c *gin.Context) {
+	format := c.DefaultQuery("format", report.FormatHTML)
+	if format != report.FormatHTML && format != report.FormatPDF {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "format 仅支持 html、pdf"})
+		return
+	}
+
+	record, ok := s.ownedRecord(c)
+	if !ok {
+		return
+	}
+	if record.Status != "completed" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "回测尚未完成"})
+		return
+	}
+
+	var data backtestResultData
+	if record.ResultData != "" {
+		_ = json.Unmarshal([]byte(record.ResultData), &data)
+	}
+	if len(data.Equity) < 2 || len(data.Dates) != len(data.Equity) {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "该回测没有保存净值曲线，请重新运行回测"})
+		return
+	}
+
+	strategy, _ := s.strategyRepo.GetByID(c.Request.Context(), record.StrategyID)
+	r := report.New(record, strategy, data.Dates, data.Equity, reportTrades(data.Pair), data.Simulated)
+
+	var buf bytes.Buffer
+	var contentType, disposition string
+	switch format {
+	case report.FormatPDF:
+		if err := report.PDF(&buf, r, s.reportFont); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "生成报告失败: " + err.Error()})
+			return
+		}
+		contentType, disposition = "application/pdf", "attachment"
+	default:
+		if err := report.HTML(&buf, r); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "生成报告失败: " + err.Error()})
+			return
+		}
+		contentType, disposition = "text/html; charset=utf-8", "inline"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`%s; filename="backtest-%d.%s"`, disposition, record.ID, format))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// reportTrades 将配对交易明细转换为报告交易行
+func reportTrades(result *pairs.Result) []*report.Trade {
+	if result == nil {
+		return nil
+	}
+	trades := make([]*report.Trade, 0, len(result.Trades))
+	for _, t := range result.Trades {
+		side := "long_spread"
+		if t.Direction < 0 {
+			side = "short_spread"
+		}
+		trades = append(trades, &report.Trade{
+			OpenDate:  t.OpenDate,
+			CloseDate: t.CloseDate,
+			Side:      side,
+			Detail:    fmt.Sprintf("A %.0f / B %.0f, z %.2f -> %.2f", t.QuantityA, t.QuantityB, t.EntryZ, t.ExitZ),
+			PnL:       t.PnL,
+			Stopped:   t.Stopped,
+		})
+	}
+	return trades
+}
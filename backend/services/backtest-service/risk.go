@@ -0,0 +1,168 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/portfolio"
+	"stock-analysis-system/backend/pkg/risk"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 风险分析接口 ============
+
+// maxRiskSymbols 单次风险分析最多股票数
+const maxRiskSymbols = 50
+
+// RiskAnalyzeRequest 风险分析请求，symbols 与 portfolio_id 至少提供一个
+type RiskAnalyzeRequest struct {
+	Symbols     []string `json:"symbols"` // symbol.exchange
+	PortfolioID uint     `json:"portfolio_id"`
+	Start       string   `json:"start"` // YYYY-MM-DD，默认最近一年
+	End         string   `json:"end"`
+	Confidence  float64  `json:"confidence"` // VaR 置信度，默认 0.95
+}
+
+// SymbolRisk 单只股票风险指标
+type SymbolRisk struct {
+	Symbol   string `json:"symbol"`
+	Exchange string `json:"exchange"`
+	risk.Metrics
+}
+
+// PortfolioRisk 组合风险指标（基于组合每日总资产）
+type PortfolioRisk struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	risk.Metrics
+}
+
+// AnalyzeRisk 计算历史 VaR、年化波动率、最大回撤与相关系数矩阵
+func (s *BacktestService) AnalyzeRisk(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req RiskAnalyzeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if len(req.Symbols) == 0 && req.PortfolioID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "symbols 与 portfolio_id 至少提供一个"})
+		return
+	}
+	if len(req.Symbols) > maxRiskSymbols {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "单次最多分析 50 只股票"})
+		return
+	}
+	if req.Confidence == 0 {
+		req.Confidence = 0.95
+	}
+	if req.Confidence < 0.5 || req.Confidence >= 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "置信度应在 [0.5, 1) 之间"})
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 365,
+		MaxDays:     365 * 10,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	start := dateRange.Start.Format(validation.DateLayout)
+	end := dateRange.End.Format(validation.DateLayout)
+
+	ctx := c.Request.Context()
+	series := make(map[string]map[string]float64)
+	result := gin.H{
+		"start":      start,
+		"end":        end,
+		"confidence": req.Confidence,
+	}
+
+	// 组合：按每日总资产计算风险指标，当前持仓参与相关系数矩阵
+	if req.PortfolioID != 0 {
+		p, err := s.portfolioRepo.GetByID(ctx, req.PortfolioID)
+		if err != nil || p.UserID != uid {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问该组合"})
+			return
+		}
+		trades, err := s.portfolioRepo.GetTrades(ctx, p.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询成交记录失败"})
+			return
+		}
+		in, err := portfolio.LoadInput(ctx, s.marketRepo, s.stockRepo, p, trades, dateRange.Start, dateRange.End, "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+			return
+		}
+		analysis, err := portfolio.Analyze(in)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "组合分析失败: " + err.Error()})
+			return
+		}
+
+		values := make([]float64, 0, len(analysis.Series))
+		for _, point := range analysis.Series {
+			values = append(values, point.TotalValue)
+		}
+		result["portfolio"] = &PortfolioRisk{ID: p.ID, Name: p.Name, Metrics: risk.Compute(values, req.Confidence)}
+
+		for _, pos := range analysis.Gains.Positions {
+			if pos.Quantity == 0 {
+				continue
+			}
+			key := portfolio.Key(pos.Symbol, pos.Exchange)
+			series[key] = withinRange(in.Closes[key], start, end)
+		}
+	}
+
+	// 指定股票：逐只计算风险指标
+	symbols := make([]*SymbolRisk, 0, len(req.Symbols))
+	for _, key := range req.Symbols {
+		symbol, exchange, ok := portfolio.SplitKey(key)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "股票格式错误，应为 symbol.exchange: " + key})
+			return
+		}
+		bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, dateRange.Start, dateRange.End)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询行情失败: " + err.Error()})
+			return
+		}
+		closes := risk.ClosesByDate(bars)
+		series[key] = closes
+		symbols = append(symbols, &SymbolRisk{
+			Symbol:   symbol,
+			Exchange: exchange,
+			Metrics:  risk.Compute(risk.SortedValues(closes), req.Confidence),
+		})
+	}
+	result["symbols"] = symbols
+
+	names, matrix := risk.CorrelationMatrix(series)
+	result["correlation"] = gin.H{
+		"symbols": names,
+		"matrix":  matrix,
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": result,
+	})
+}
+
+// withinRange 截取区间内的收盘价
+func withinRange(closes map[string]float64, start, end string) map[string]float64 {
+	filtered := make(map[string]float64, len(closes))
+	for date, price := range closes {
+		if date >= start && date <= end {
+			filtered[date] = price
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ============ 风险警示约束 ============
+
+// excludeST 剔除指定日期处于风险警示（ST/*ST）的股票，返回保留与剔除的股票
+// 使用当日生效的历史记录判断，避免用股票当前状态回看历史。
+func (s *BacktestService) excludeST(ctx context.Context, symbols []string, asOf time.Time) (kept, excluded []string, err error) {
+	warnings, err := s.stockRepo.GetRiskWarningsAsOf(ctx, asOf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("查询风险警示失败: %w", err)
+	}
+	for _, key := range symbols {
+		if warnings[key] != "" {
+			excluded = append(excluded, key)
+			continue
+		}
+		kept = append(kept, key)
+	}
+	return kept, excluded, nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/universe"
+)
+
+// ============ 回测股票池 ============
+
+// errNoUniverseSnapshot 回测开始日之前没有股票池成分快照
+var errNoUniverseSnapshot = errors.New("股票池在回测开始日之前没有成分快照，请先回补快照")
+
+// universeTimeline 读取回测区间内的股票池成分时间线，包含开始日之前最近的一次快照，
+// 使回测每个交易日都能取到当日的时点成分（含此后被调出、退市的股票），避免幸存者偏差。
+func (s *BacktestService) universeTimeline(ctx context.Context, universeID uint, start, end time.Time) (*universe.Timeline, error) {
+	first, err := s.universeRepo.GetLatestSnapshotDate(ctx, universeID, start)
+	if err != nil {
+		return nil, fmt.Errorf("查询股票池快照失败: %w", err)
+	}
+	if first == nil {
+		return nil, errNoUniverseSnapshot
+	}
+
+	members, err := s.universeRepo.GetMembersBetween(ctx, universeID, *first, end)
+	if err != nil {
+		return nil, fmt.Errorf("查询股票池成分失败: %w", err)
+	}
+	return universe.NewTimeline(members), nil
+}
+
+// universeSummary 汇总回测区间内股票池的成分变化，查询失败时记录日志并返回 nil
+func (s *BacktestService) universeSummary(ctx context.Context, universeID uint, start, end time.Time) *universe.Summary {
+	u, err := s.universeRepo.GetByID(ctx, universeID)
+	if err != nil {
+		log.Printf("查询股票池 %d 失败: %v", universeID, err)
+		return nil
+	}
+	timeline, err := s.universeTimeline(ctx, universeID, start, end)
+	if err != nil {
+		log.Printf("查询股票池 %d 成分失败: %v", universeID, err)
+		return nil
+	}
+	return universe.Summarize(u, timeline, start, end)
+}
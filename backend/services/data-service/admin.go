@@ -0,0 +1,622 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/middleware"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/quality"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 管理员数据运维接口 ============
+
+const (
+	adminMaxDeleteDays       = 366 // 单次删除日K线/指标的最大跨度（天）
+	adminMaxMinuteDeleteDays = 31  // 单次删除分钟K线的最大跨度（天）
+	adminMaxRepairDays       = 366 // 单次修复/重算的最大跨度（天）
+)
+
+// adminSymbolPattern 股票代码只允许字母数字，避免拼接进 InfluxDB 删除谓词
+var adminSymbolPattern = regexp.MustCompile(`^[0-9A-Za-z]{1,10}$`)
+
+// adminExchanges 支持的交易所
+var adminExchanges = map[string]bool{"SH": true, "SZ": true, "BJ": true}
+
+// adminDeleteTypes 允许删除的数据类型及单次最大跨度
+var adminDeleteTypes = map[string]int{
+	database.DataDailyBars:  adminMaxDeleteDays,
+	database.DataMinuteBars: adminMaxMinuteDeleteDays,
+	database.DataIndicators: adminMaxDeleteDays,
+}
+
+// qualityReportState 最近一次全市场数据质量报告
+type qualityReportState struct {
+	mu        sync.Mutex
+	running   bool
+	startedAt time.Time
+	report    *quality.DataQualityReport
+	err       string
+}
+
+// RegisterAdminRoutes 注册管理员数据运维接口，需登录且角色为 admin
+func (s *DataSyncService) RegisterAdminRoutes(router *gin.Engine) {
+	admin := router.Group("/api/v1/admin", middleware.JWTAuth(s.keys), middleware.RequireRole(s.userRepo, models.RoleAdmin))
+	{
+		admin.GET("/sync/jobs", s.ListSyncJobs)
+		admin.GET("/sync/jobs/:id", s.GetSyncJob)
+		admin.POST("/sync/jobs", s.TriggerSyncJob)
+
+		admin.GET("/quality", s.GetSymbolQuality)
+		admin.GET("/quality/report", s.GetQualityReport)
+		admin.POST("/quality/report", s.RunQualityReport)
+
+		admin.POST("/bars/delete", s.DeleteBars)
+		admin.POST("/bars/repair", s.RepairBars)
+		admin.POST("/bars/correct", s.CorrectBar)
+		admin.POST("/bars/dedupe", s.DedupeBars)
+		admin.POST("/indicators/recompute", s.RecomputeIndicators)
+
+		admin.GET("/audit-logs", s.ListAuditLogs)
+	}
+}
+
+// ============ 同步任务 ============
+
+// ListSyncJobs 同步任务列表，可按 job_type、status、symbol 过滤
+func (s *DataSyncService) ListSyncJobs(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	filter := repository.SyncJobFilter{
+		JobType: c.Query("job_type"),
+		Status:  c.Query("status"),
+		Symbol:  c.Query("symbol"),
+	}
+	jobs, total, err := s.syncJobRepo.List(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询同步任务失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{"list": jobs, "total": total, "page": page, "page_size": pageSize},
+	})
+}
+
+// GetSyncJob 同步任务详情
+func (s *DataSyncService) GetSyncJob(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "无效的任务ID"})
+		return
+	}
+	job, err := s.syncJobRepo.GetByID(c.Request.Context(), uint(id))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "同步任务不存在"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询同步任务失败"})
+		return
+	}
+	if job.Dependents, err = s.syncJobRepo.GetDependents(c.Request.Context(), job.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询下游任务失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": job})
+}
+
+// TriggerSyncJobRequest 手动触发同步任务请求
+type TriggerSyncJobRequest struct {
+	JobType  string `json:"job_type" binding:"required"`
+	Symbol   string `json:"symbol"` // 为空表示全市场（支持的任务类型）
+	Exchange string `json:"exchange"`
+	Start    string `json:"start"` // YYYY-MM-DD，默认最近 30 天
+	End      string `json:"end"`
+	Reason   string `json:"reason"`
+}
+
+// syncJobRunner 按任务类型执行同步，symbol 为空时执行全市场任务
+type syncJobRunner func(s *DataSyncService, ctx context.Context, req *TriggerSyncJobRequest, r validation.DateRange) error
+
+// syncJobRunners 可手动触发的同步任务
+var syncJobRunners = map[string]syncJobRunner{
+	models.SyncJobStockList: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, _ validation.DateRange) error {
+		return s.SyncStockList(ctx)
+	},
+	models.SyncJobStockMeta: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, _ validation.DateRange) error {
+		_, err := s.SyncStockMetadata(ctx)
+		return err
+	},
+	models.SyncJobDailyBars: func(s *DataSyncService, ctx context.Context, req *TriggerSyncJobRequest, r validation.DateRange) error {
+		if req.Symbol == "" {
+			_, err := s.SyncDailyBarsForAllStocks(ctx, r.Start, r.End)
+			return err
+		}
+		_, err := s.SyncDailyBars(ctx, req.Symbol, req.Exchange, r.Start, r.End)
+		return err
+	},
+	models.SyncJobMoneyFlow: func(s *DataSyncService, ctx context.Context, req *TriggerSyncJobRequest, r validation.DateRange) error {
+		if req.Symbol == "" {
+			return errors.New("资金流向同步需要指定股票")
+		}
+		return s.SyncMoneyFlow(ctx, req.Symbol, req.Exchange, r.Start, r.End)
+	},
+	models.SyncJobFinancials: func(s *DataSyncService, ctx context.Context, req *TriggerSyncJobRequest, _ validation.DateRange) error {
+		if req.Symbol == "" {
+			_, err := s.SyncFinancialReportsForAllStocks(ctx)
+			return err
+		}
+		_, err := s.SyncFinancialReports(ctx, req.Symbol, req.Exchange)
+		return err
+	},
+	models.SyncJobFactors: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, r validation.DateRange) error {
+		_, err := s.ComputeFactorScores(ctx, r.End)
+		return err
+	},
+	models.SyncJobStockConnect: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, r validation.DateRange) error {
+		_, err := s.SyncStockConnectRange(ctx, r.Start, r.End)
+		return err
+	},
+	models.SyncJobMacro: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, r validation.DateRange) error {
+		_, err := s.SyncMacroSeries(ctx, r.Start, r.End)
+		return err
+	},
+	models.SyncJobRiskWarnings: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, _ validation.DateRange) error {
+		return s.SyncRiskWarningHistory(ctx)
+	},
+	models.SyncJobDedupeBars: func(s *DataSyncService, ctx context.Context, req *TriggerSyncJobRequest, r validation.DateRange) error {
+		_, err := s.DedupeDailyBars(ctx, req.Symbol, req.Exchange, r.Start, r.End, false)
+		return err
+	},
+	models.SyncJobQualityScore: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, _ validation.DateRange) error {
+		_, err := s.ScoreStockQuality(ctx)
+		return err
+	},
+	models.SyncJobIncremental: func(s *DataSyncService, ctx context.Context, _ *TriggerSyncJobRequest, _ validation.DateRange) error {
+		_, err := s.IncrementalUpdate(ctx)
+		return err
+	},
+}
+
+// TriggerSyncJob 手动触发同步任务，在后台执行，进度通过 GET /sync/jobs 查看
+func (s *DataSyncService) TriggerSyncJob(c *gin.Context) {
+	var req TriggerSyncJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	run, ok := syncJobRunners[req.JobType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "不支持的任务类型: " + req.JobType})
+		return
+	}
+	if req.Symbol != "" {
+		if err := validateAdminSymbol(req.Symbol, req.Exchange); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{DefaultDays: 30, MaxDays: 365 * 20})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	entry := s.newAuditLog(c, models.AuditSyncTrigger, req.Symbol, req.Exchange, req.Reason, &req)
+	taskID := s.runAdminTask(entry, func(ctx context.Context) (string, error) {
+		if err := run(s, ctx, &req, dateRange); err != nil {
+			return "", err
+		}
+		return req.JobType + " 同步完成", nil
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"code": 0, "msg": "同步任务已提交，进度见同步任务列表", "data": gin.H{"task_id": taskID}})
+}
+
+// ============ 数据质量 ============
+
+// GetSymbolQuality 单只股票的数据质量检查（最近 30 天完整性、连续性、异常值与新鲜度）
+func (s *DataSyncService) GetSymbolQuality(c *gin.Context) {
+	symbol, exchange := c.Query("symbol"), c.Query("exchange")
+	if err := validateAdminSymbol(symbol, exchange); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	ctx := c.Request.Context()
+	results, err := s.quality.CheckStock(ctx, symbol, exchange)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "数据质量检查失败: " + err.Error()})
+		return
+	}
+	if freshness, err := s.quality.CheckDataFreshness(ctx, symbol, exchange); err == nil {
+		results = append(results, *freshness)
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": gin.H{"symbol": symbol, "exchange": exchange, "checks": results}})
+}
+
+// GetQualityReport 最近一次全市场数据质量报告的汇总，只列出 warning/error 的检查项
+func (s *DataSyncService) GetQualityReport(c *gin.Context) {
+	st := &s.qualityState
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	data := gin.H{"running": st.running, "error": st.err}
+	if st.running {
+		data["started_at"] = st.startedAt
+	}
+	if r := st.report; r != nil {
+		issues := make([]quality.CheckResult, 0)
+		for _, check := range r.Checks {
+			if check.Status != "pass" {
+				issues = append(issues, check)
+			}
+		}
+		data["generated_at"] = r.GeneratedAt
+		data["total_stocks"] = r.TotalStocks
+		data["summary"] = r.Summary
+		data["issues"] = issues
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": data})
+}
+
+// RunQualityReport 在后台生成全市场数据质量报告，同一时间只运行一次
+func (s *DataSyncService) RunQualityReport(c *gin.Context) {
+	st := &s.qualityState
+	st.mu.Lock()
+	if st.running {
+		st.mu.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"code": 409, "msg": "数据质量报告正在生成"})
+		return
+	}
+	st.running, st.startedAt, st.err = true, time.Now(), ""
+	st.mu.Unlock()
+
+	entry := s.newAuditLog(c, models.AuditQualityReport, "", "", "", nil)
+	taskID := s.runAdminTask(entry, func(ctx context.Context) (string, error) {
+		report, err := s.quality.GenerateReport(ctx)
+		st.mu.Lock()
+		defer st.mu.Unlock()
+		st.running = false
+		if err != nil {
+			st.err = err.Error()
+			return "", err
+		}
+		st.report = report
+		return fmt.Sprintf("%d 只股票：pass %d，warning %d，error %d", report.TotalStocks,
+			report.Summary.PassCount, report.Summary.WarningCount, report.Summary.ErrorCount), nil
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"code": 0, "msg": "数据质量报告生成中", "data": gin.H{"task_id": taskID}})
+}
+
+// ============ 删除与修复 ============
+
+// DeleteBarsRequest 删除一段数据请求
+// 先以 dry_run=true 预览将删除的条数，再以 dry_run=false 并在 confirm_count 中回传该条数执行删除；
+// 两次之间数据有变化时拒绝删除，需重新预览。
+type DeleteBarsRequest struct {
+	DataType     string `json:"data_type" binding:"required"` // daily_bars/minute_bars/indicators
+	Symbol       string `json:"symbol" binding:"required"`
+	Exchange     string `json:"exchange" binding:"required"`
+	Start        string `json:"start" binding:"required"`
+	End          string `json:"end" binding:"required"`
+	DryRun       bool   `json:"dry_run"`
+	ConfirmCount *int64 `json:"confirm_count"`
+	Reason       string `json:"reason"` // 执行删除时必填，写入审计日志
+}
+
+// DeleteBars 删除一只股票一段时间内的K线或指标（InfluxDB delete），带预览、条数确认与审计日志
+func (s *DataSyncService) DeleteBars(c *gin.Context) {
+	var req DeleteBarsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	maxDays, ok := adminDeleteTypes[req.DataType]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "data_type 仅支持 daily_bars、minute_bars、indicators"})
+		return
+	}
+	if err := validateAdminSymbol(req.Symbol, req.Exchange); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{Required: true, MaxDays: maxDays})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := s.marketRepo.CountSeries(ctx, req.DataType, req.Symbol, req.Exchange, dateRange.Start, dateRange.End)
+	if err != nil {
+		respondAdminQueryError(c, err)
+		return
+	}
+	preview := gin.H{
+		"data_type": req.DataType,
+		"symbol":    req.Symbol,
+		"exchange":  req.Exchange,
+		"start":     dateRange.Start.Format(validation.DateLayout),
+		"end":       dateRange.End.Format(validation.DateLayout),
+		"count":     count,
+	}
+	if req.DryRun {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": preview})
+		return
+	}
+
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "执行删除需要填写原因 reason"})
+		return
+	}
+	if req.ConfirmCount == nil || *req.ConfirmCount != count {
+		c.JSON(http.StatusConflict, gin.H{"code": 409, "msg": "confirm_count 与当前数据条数不一致，请先以 dry_run 预览", "data": preview})
+		return
+	}
+
+	entry := s.newAuditLog(c, models.AuditBarsDelete, req.Symbol, req.Exchange, req.Reason, &req)
+	err = s.marketRepo.DeleteSeries(ctx, req.DataType, req.Symbol, req.Exchange, dateRange.Start, dateRange.End)
+	s.finishAuditLog(entry, fmt.Sprintf("删除 %s %d 条", req.DataType, count), err)
+	if err != nil {
+		respondAdminQueryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "msg": "删除成功", "data": preview})
+}
+
+// RepairBarsRequest 修复一段日K线请求
+type RepairBarsRequest struct {
+	Symbol   string `json:"symbol" binding:"required"`
+	Exchange string `json:"exchange" binding:"required"`
+	Start    string `json:"start" binding:"required"`
+	End      string `json:"end" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
+}
+
+// RepairBars 从数据源重新获取一段日K线，删除旧数据后写入，并使依赖这段K线的技术指标、行情缓存与回测失效（见 restate）
+// 数据源没有返回数据时不删除旧数据。
+func (s *DataSyncService) RepairBars(c *gin.Context) {
+	var req RepairBarsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := validateAdminSymbol(req.Symbol, req.Exchange); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{Required: true, MaxDays: adminMaxRepairDays})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	entry := s.newAuditLog(c, models.AuditBarsRepair, req.Symbol, req.Exchange, req.Reason, &req)
+	result, err := s.repairDailyBars(c.Request.Context(), req.Symbol, req.Exchange, dateRange)
+	s.finishAuditLog(entry, fmt.Sprintf("删除 %d 根，写入 %d 根，重算指标 %d 条，过期回测 %d 个", result.Deleted, result.Written, result.Indicators, result.StaleBacktests), err)
+	if err != nil {
+		if errors.Is(err, errNoSourceBars) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+		respondAdminQueryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "msg": "修复完成", "data": result})
+}
+
+// errNoSourceBars 数据源在修复区间内没有返回数据
+var errNoSourceBars = errors.New("数据源在该区间没有返回K线，未删除旧数据")
+
+// repairResult 修复结果
+type repairResult struct {
+	Deleted int64 `json:"deleted"`
+	Written int   `json:"written"`
+	restatementResult
+}
+
+// repairDailyBars 重新同步一段日K线：先从数据源取数，成功后再删除旧数据并写入
+func (s *DataSyncService) repairDailyBars(ctx context.Context, symbol, exchange string, r validation.DateRange) (*repairResult, error) {
+	result := &repairResult{}
+	bars, err := s.fetchDailyBarsFromPython(ctx, symbol, exchange, r.Start, r.End)
+	if err != nil {
+		return result, fmt.Errorf("从 Python 服务获取K线数据失败: %w", err)
+	}
+	if len(bars) == 0 {
+		return result, errNoSourceBars
+	}
+	for _, bar := range bars {
+		if err := quality.ValidateBarData(bar); err != nil {
+			return result, fmt.Errorf("数据源返回的K线校验失败（%s）: %w", bar.Date.Format(validation.DateLayout), err)
+		}
+	}
+
+	if result.Deleted, err = s.marketRepo.CountSeries(ctx, database.DataDailyBars, symbol, exchange, r.Start, r.End); err != nil {
+		return result, err
+	}
+	if err := s.marketRepo.DeleteSeries(ctx, database.DataDailyBars, symbol, exchange, r.Start, r.End); err != nil {
+		return result, err
+	}
+	if err := s.marketRepo.SaveDailyBars(ctx, bars); err != nil {
+		return result, fmt.Errorf("保存K线数据失败: %w", err)
+	}
+	result.Written = len(bars)
+
+	result.restatementResult, err = s.restate(ctx, symbol, exchange, r)
+	return result, err
+}
+
+// RecomputeIndicatorsRequest 重新计算技术指标请求
+type RecomputeIndicatorsRequest struct {
+	Symbol   string `json:"symbol" binding:"required"`
+	Exchange string `json:"exchange" binding:"required"`
+	Start    string `json:"start" binding:"required"`
+	End      string `json:"end" binding:"required"`
+	Reason   string `json:"reason"`
+}
+
+// RecomputeIndicators 按已保存的日K线重新计算一段时间的 MA/MACD/RSI/KDJ/BOLL 指标并覆盖旧值
+func (s *DataSyncService) RecomputeIndicators(c *gin.Context) {
+	var req RecomputeIndicatorsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := validateAdminSymbol(req.Symbol, req.Exchange); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{Required: true, MaxDays: adminMaxRepairDays})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	entry := s.newAuditLog(c, models.AuditIndicatorsRecompute, req.Symbol, req.Exchange, req.Reason, &req)
+	count, err := s.recomputeIndicators(c.Request.Context(), req.Symbol, req.Exchange, dateRange)
+	s.finishAuditLog(entry, fmt.Sprintf("写入指标 %d 条", count), err)
+	if err != nil {
+		respondAdminQueryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "msg": "重新计算完成", "data": gin.H{"indicators": count}})
+}
+
+// recomputeIndicators 向前多取预热数据计算指标，删除区间内的旧指标后写入，返回写入条数
+func (s *DataSyncService) recomputeIndicators(ctx context.Context, symbol, exchange string, r validation.DateRange) (int, error) {
+	bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, r.Start.AddDate(0, 0, -indicator.StandardWarmupDays), r.End)
+	if err != nil {
+		return 0, err
+	}
+	indicators := indicator.Standard(symbol, exchange, indicator.FromDailyBars(bars), r.Start)
+	if err := s.marketRepo.DeleteSeries(ctx, database.DataIndicators, symbol, exchange, r.Start, r.End); err != nil {
+		return 0, err
+	}
+	if err := s.marketRepo.SaveIndicators(ctx, indicators); err != nil {
+		return 0, fmt.Errorf("保存技术指标失败: %w", err)
+	}
+	return len(indicators), nil
+}
+
+// ============ 审计日志 ============
+
+// ListAuditLogs 管理员操作审计日志，可按 action 过滤
+func (s *DataSyncService) ListAuditLogs(c *gin.Context) {
+	page, pageSize := adminPage(c)
+	logs, total, err := s.auditRepo.List(c.Request.Context(), c.Query("action"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询审计日志失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{"list": logs, "total": total, "page": page, "page_size": pageSize},
+	})
+}
+
+// newAuditLog 创建审计日志条目（尚未保存），params 为请求参数
+func (s *DataSyncService) newAuditLog(c *gin.Context, action, symbol, exchange, reason string, params interface{}) *models.AuditLog {
+	entry := &models.AuditLog{
+		UserID:   c.GetUint("user_id"),
+		Username: c.GetString("username"),
+		Action:   action,
+		Symbol:   symbol,
+		Exchange: exchange,
+		Reason:   reason,
+		Params:   "{}",
+	}
+	if params != nil {
+		if data, err := json.Marshal(params); err == nil {
+			entry.Params = string(data)
+		}
+	}
+	return entry
+}
+
+// finishAuditLog 记录操作结果并保存审计日志
+// 使用独立的 context，保证请求被取消时审计日志仍能落库；保存失败只打印日志。
+func (s *DataSyncService) finishAuditLog(entry *models.AuditLog, result string, opErr error) {
+	entry.Status, entry.Result = models.SyncStatusSuccess, result
+	if opErr != nil {
+		entry.Status, entry.Result = models.SyncStatusFailed, opErr.Error()
+	}
+	if err := s.auditRepo.Create(context.Background(), entry); err != nil {
+		log.Printf("保存审计日志失败（%s %s.%s）: %v", entry.Action, entry.Symbol, entry.Exchange, err)
+	}
+}
+
+// runAdminTask 在后台执行管理员触发的任务，结束后写入审计日志；服务关闭或调用取消接口时取消
+// 任务登记为异步任务，返回任务ID（登记失败时为空），结束后结果指向审计日志。
+func (s *DataSyncService) runAdminTask(entry *models.AuditLog, fn func(ctx context.Context) (string, error)) string {
+	task := s.tasks.Start(context.Background(), jobs.Spec{Type: models.TaskTypeAdmin, Name: entry.Action, OwnerID: entry.UserID})
+	s.running.Go(s.adminCtx, task.ID(), func(ctx context.Context) {
+		result, err := fn(jobs.WithTask(ctx, task))
+		if err != nil {
+			log.Printf("管理员任务 %s 失败: %v", entry.Action, err)
+		}
+		s.finishAuditLog(entry, result, err)
+
+		resultType, resultID := "", ""
+		if entry.ID != 0 {
+			resultType, resultID = "audit_log", strconv.FormatUint(uint64(entry.ID), 10)
+		}
+		if err != nil {
+			task.Fail(err, resultType, resultID)
+			return
+		}
+		task.Succeed(resultType, resultID)
+	})
+	return task.ID()
+}
+
+// ============ 工具函数 ============
+
+// validateAdminSymbol 校验股票代码与交易所
+func validateAdminSymbol(symbol, exchange string) error {
+	if !adminSymbolPattern.MatchString(symbol) {
+		return fmt.Errorf("无效的股票代码: %q", symbol)
+	}
+	if !adminExchanges[exchange] {
+		return fmt.Errorf("无效的交易所: %q，应为 SH/SZ/BJ", exchange)
+	}
+	return nil
+}
+
+// adminPage 解析分页参数，page_size 最大 100
+func adminPage(c *gin.Context) (int, int) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// respondAdminQueryError InfluxDB 不可用时返回 503，其余错误返回 500
+func respondAdminQueryError(c *gin.Context, err error) {
+	if errors.Is(err, database.ErrInfluxUnavailable) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "行情数据库暂不可用"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"stock-analysis-system/backend/pkg/alert"
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 用户提醒规则 ============
+
+// alertCatchUpDays 提醒规则最多补查的天数：同步中断较久后恢复时，只检查最近这段时间的K线
+const alertCatchUpDays = 30
+
+// EvaluateAlertRules 增量检查全部启用的提醒规则，返回新触发的事件数
+// 每只股票加载一次日K线（含条件所需的预热数据），各规则只检查游标 last_bar_at 之后的K线，
+// 新规则只检查最新一根；检查完成后推进游标并保存触发记录。单条规则失败只记录日志。
+func (s *DataSyncService) EvaluateAlertRules(ctx context.Context) (triggered int, err error) {
+	rules, err := s.alertRuleRepo.GetEnabled(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取提醒规则失败: %w", err)
+	}
+
+	end := time.Now()
+	for from := 0; from < len(rules); {
+		if err := ctx.Err(); err != nil {
+			return triggered, err
+		}
+		// 规则按股票排序，相邻的同一股票规则共用K线
+		to := from + 1
+		for to < len(rules) && rules[to].Symbol == rules[from].Symbol && rules[to].Exchange == rules[from].Exchange {
+			to++
+		}
+		n, err := s.evaluateSymbolAlerts(ctx, rules[from:to], end)
+		if err != nil {
+			log.Printf("检查 %s.%s 的提醒规则失败: %v", rules[from].Symbol, rules[from].Exchange, err)
+		}
+		triggered += n
+		from = to
+	}
+
+	if len(rules) > 0 {
+		log.Printf("提醒规则检查完成，%d 条规则，新触发 %d 次", len(rules), triggered)
+	}
+	return triggered, nil
+}
+
+// evaluateSymbolAlerts 检查同一只股票的提醒规则，事件类规则按公司事件日历检查，其余按日K线检查
+func (s *DataSyncService) evaluateSymbolAlerts(ctx context.Context, rules []*models.AlertRule, end time.Time) (int, error) {
+	earliest := end.AddDate(0, 0, -alertCatchUpDays)
+	conds := make([]*alert.Condition, len(rules))
+	afters := make([]time.Time, len(rules))
+	start := end
+	hasBarRules, hasEventRules := false, false
+	for i, rule := range rules {
+		cond, err := alert.NewCondition(rule.Condition, rule.Left, rule.Right, rule.Window)
+		if err != nil {
+			log.Printf("提醒规则 %d 条件无效，跳过: %v", rule.ID, err)
+			continue
+		}
+		conds[i] = cond
+		if cond.IsEvent() {
+			hasEventRules = true
+			continue
+		}
+		hasBarRules = true
+		if rule.LastBarAt != nil {
+			afters[i] = *rule.LastBarAt
+			if afters[i].Before(earliest) {
+				afters[i] = earliest
+			}
+		}
+		// 交易日约为自然日的 2/3，按 1.5 倍换算并留出节假日余量
+		from := end
+		if !afters[i].IsZero() {
+			from = afters[i]
+		}
+		if from = from.AddDate(0, 0, -(cond.Lookback()*3/2 + 10)); from.Before(start) {
+			start = from
+		}
+	}
+
+	triggered := 0
+	if hasEventRules {
+		n, err := s.evaluateEventAlerts(ctx, rules, conds)
+		if err != nil {
+			log.Printf("检查 %s.%s 的事件提醒失败: %v", rules[0].Symbol, rules[0].Exchange, err)
+		}
+		triggered += n
+	}
+	if !hasBarRules {
+		return triggered, nil
+	}
+
+	symbol, exchange := rules[0].Symbol, rules[0].Exchange
+	bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, start, end)
+	if err != nil {
+		return triggered, err
+	}
+	series := indicator.FromDailyBars(bars)
+	if series.Len() == 0 {
+		return triggered, nil
+	}
+	lastBar := series.Time[series.Len()-1]
+
+	for i, rule := range rules {
+		cond := conds[i]
+		if cond == nil || cond.IsEvent() || (rule.LastBarAt != nil && !lastBar.After(*rule.LastBarAt)) {
+			continue
+		}
+		var events []*models.AlertEvent
+		for _, t := range cond.Scan(series, afters[i]) {
+			events = append(events, &models.AlertEvent{
+				RuleID:    rule.ID,
+				UserID:    rule.UserID,
+				Symbol:    symbol,
+				Exchange:  exchange,
+				Condition: rule.Condition,
+				TradeDate: t.Time,
+				Value:     t.Value,
+				Reference: t.Reference,
+				Message: fmt.Sprintf("%s: %s.%s %s，当前 %s，基准 %s", rule.Name, symbol, exchange,
+					cond.Describe(), formatAlertValue(t.Value), formatAlertValue(t.Reference)),
+			})
+		}
+		if err := s.alertRuleRepo.Advance(ctx, rule, lastBar, events); err != nil {
+			log.Printf("保存提醒规则 %d 的检查结果失败: %v", rule.ID, err)
+			continue
+		}
+		triggered += len(events)
+	}
+	return triggered, nil
+}
+
+// evaluateEventAlerts 按公司事件日历检查同一只股票的事件类提醒规则，每条规则每天检查一次
+// 触发记录的日期为事件日期，同一事件在提前天数内只保存一次。
+func (s *DataSyncService) evaluateEventAlerts(ctx context.Context, rules []*models.AlertRule, conds []*alert.Condition) (int, error) {
+	symbol, exchange := rules[0].Symbol, rules[0].Exchange
+	today, err := time.Parse("2006-01-02", markettime.Today(exchange))
+	if err != nil {
+		return 0, err
+	}
+	upcoming, err := s.calendarRepo.GetBySymbol(ctx, symbol, exchange, today, today.AddDate(0, 0, alert.MaxEventDays))
+	if err != nil {
+		return 0, err
+	}
+
+	triggered := 0
+	for i, rule := range rules {
+		cond := conds[i]
+		if cond == nil || !cond.IsEvent() || (rule.LastBarAt != nil && !today.After(*rule.LastBarAt)) {
+			continue
+		}
+		var events []*models.AlertEvent
+		for _, t := range cond.Upcoming(upcoming, today) {
+			events = append(events, &models.AlertEvent{
+				RuleID:    rule.ID,
+				UserID:    rule.UserID,
+				Symbol:    symbol,
+				Exchange:  exchange,
+				Condition: rule.Condition,
+				TradeDate: t.Time,
+				Value:     t.Value,
+				Reference: t.Reference,
+				Message: fmt.Sprintf("%s: %s.%s %s「%s」将于 %s 发生，还有 %d 天", rule.Name, symbol, exchange,
+					models.CorporateEventTypes[cond.Left], t.Title, t.Time.Format("2006-01-02"), int(t.Value)),
+			})
+		}
+		if err := s.alertRuleRepo.Advance(ctx, rule, today, events); err != nil {
+			log.Printf("保存提醒规则 %d 的检查结果失败: %v", rule.ID, err)
+			continue
+		}
+		triggered += len(events)
+	}
+	return triggered, nil
+}
+
+// formatAlertValue 保留 4 位小数并去掉末尾的 0
+func formatAlertValue(v float64) string {
+	return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/basket"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 股票篮子每日点位 ============
+
+// UpdateBasketValues 计算全部篮子自最近保存的点位之后到 end 的每日点位，尚未计算过的篮子从基日开始
+// 单个篮子失败只记录日志，不影响其他篮子；返回新增的点位数。
+func (s *DataSyncService) UpdateBasketValues(ctx context.Context, end time.Time) (count int, err error) {
+	job, err := s.startJob(ctx, models.SyncJobBasketValues, "", "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	baskets, err := s.basketRepo.GetAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取篮子列表失败: %w", err)
+	}
+
+	failed := 0
+	for _, b := range baskets {
+		n, err := basket.Update(ctx, s.basketRepo, s.marketRepo, b, end)
+		if err != nil {
+			failed++
+			log.Printf("计算篮子 %d(%s) 点位失败: %v", b.ID, b.Name, err)
+			continue
+		}
+		count += n
+	}
+
+	log.Printf("篮子点位计算完成，共 %d 个篮子 %d 个点位，%d 个失败", len(baskets), count, failed)
+	return count, nil
+}
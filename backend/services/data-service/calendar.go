@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 公司事件日历 ============
+
+// calendarSyncDays 定时任务同步的未来天数
+const calendarSyncDays = 90
+
+// SyncCorporateEvents 同步日期范围内的公司事件（预约披露日、股东大会、限售股解禁）
+func (s *DataSyncService) SyncCorporateEvents(ctx context.Context, start, end time.Time) (err error) {
+	log.Printf("开始同步 %s 至 %s 的公司事件", start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+	var events []*models.CorporateEvent
+	job, err := s.startJob(ctx, models.SyncJobCalendar, "", "")
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, len(events), err) }()
+
+	events, err = s.fetchCorporateEventsFromPython(ctx, start, end)
+	if err != nil {
+		return fmt.Errorf("从 Python 服务获取公司事件失败: %w", err)
+	}
+
+	if err := s.calendarRepo.SaveBatch(ctx, events); err != nil {
+		return fmt.Errorf("保存公司事件失败: %w", err)
+	}
+
+	log.Printf("公司事件同步完成，共 %d 条", len(events))
+	return nil
+}
+
+// fetchCorporateEventsFromPython 从 Python 服务获取公司事件，跳过未知类型与日期无效的记录
+func (s *DataSyncService) fetchCorporateEventsFromPython(ctx context.Context, start, end time.Time) ([]*models.CorporateEvent, error) {
+	url := fmt.Sprintf("%s/api/v1/market/calendar?start=%s&end=%s",
+		s.pythonAPIURL, start.Format("20060102"), end.Format("20060102"))
+
+	var result struct {
+		Code int `json:"code"`
+		Data []struct {
+			Symbol    string `json:"symbol"`
+			Exchange  string `json:"exchange"`
+			Name      string `json:"name"`
+			EventType string `json:"event_type"`
+			EventDate string `json:"event_date"` // YYYY-MM-DD
+			Title     string `json:"title"`
+			Detail    string `json:"detail"`
+		} `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	events := make([]*models.CorporateEvent, 0, len(result.Data))
+	for _, item := range result.Data {
+		if _, ok := models.CorporateEventTypes[item.EventType]; !ok {
+			continue
+		}
+		date, err := time.Parse("2006-01-02", item.EventDate)
+		if err != nil {
+			continue
+		}
+		events = append(events, &models.CorporateEvent{
+			Symbol:    item.Symbol,
+			Exchange:  item.Exchange,
+			Name:      item.Name,
+			EventType: item.EventType,
+			EventDate: date,
+			Title:     item.Title,
+			Detail:    item.Detail,
+			Source:    s.dataSource,
+		})
+	}
+	return events, nil
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/quality"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 单根K线人工修正 ============
+
+// CorrectBarRequest 修正一根日K线请求
+// 先以 dry_run=true 查看与已保存K线的差异，确认后以 dry_run=false 覆盖写入。
+type CorrectBarRequest struct {
+	Symbol   string  `json:"symbol" binding:"required"`
+	Exchange string  `json:"exchange" binding:"required"`
+	Date     string  `json:"date" binding:"required"` // YYYY-MM-DD
+	Open     float64 `json:"open" binding:"required"`
+	High     float64 `json:"high" binding:"required"`
+	Low      float64 `json:"low" binding:"required"`
+	Close    float64 `json:"close" binding:"required"`
+	Volume   int64   `json:"volume"`
+	Amount   float64 `json:"amount"`
+	DryRun   bool    `json:"dry_run"`
+	Reason   string  `json:"reason"` // 执行修正时必填，写入审计日志
+}
+
+// barCorrection 修正前后的K线与字段差异，同时作为审计日志的参数
+type barCorrection struct {
+	Before      *models.DailyBar    `json:"before"`
+	After       *models.DailyBar    `json:"after"`
+	Changes     []quality.BarChange `json:"changes"`
+	Restatement *restatementResult  `json:"restatement,omitempty"` // 覆盖后下游数据的失效结果，预览时为空
+}
+
+// CorrectBar 按给定 OHLCV 修正一根已保存的日K线，覆盖后使依赖它的技术指标、行情缓存与回测失效（见 restate）
+// 修正值须通过 ValidateBarData 校验；只修正已存在的K线，缺失的数据用 /bars/repair 从数据源补齐。
+func (s *DataSyncService) CorrectBar(c *gin.Context) {
+	var req CorrectBarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := validateAdminSymbol(req.Symbol, req.Exchange); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	date, err := time.Parse(validation.DateLayout, req.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "日期格式错误，应为 YYYY-MM-DD"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	stored, err := s.storedDailyBar(ctx, req.Symbol, req.Exchange, date)
+	if err != nil {
+		respondAdminQueryError(c, err)
+		return
+	}
+	if stored == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "该日没有已保存的K线，请使用 /bars/repair 从数据源同步"})
+		return
+	}
+
+	// 沿用已保存K线的时间戳，保证覆盖同一个数据点
+	corrected := &models.DailyBar{
+		Symbol:   req.Symbol,
+		Exchange: req.Exchange,
+		Date:     stored.Date,
+		Open:     req.Open,
+		High:     req.High,
+		Low:      req.Low,
+		Close:    req.Close,
+		Volume:   req.Volume,
+		Amount:   req.Amount,
+	}
+	if err := quality.ValidateBarData(corrected); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "修正值校验失败: " + err.Error()})
+		return
+	}
+
+	diff := &barCorrection{Before: stored, After: corrected, Changes: quality.DiffBars(stored, corrected)}
+	if req.DryRun {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": diff})
+		return
+	}
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "执行修正需要填写原因 reason"})
+		return
+	}
+	if len(diff.Changes) == 0 {
+		c.JSON(http.StatusOK, gin.H{"code": 0, "msg": "与已保存的K线一致，无需修正", "data": diff})
+		return
+	}
+
+	entry := s.newAuditLog(c, models.AuditBarsCorrect, req.Symbol, req.Exchange, req.Reason, diff)
+	result := quality.FormatChanges(diff.Changes)
+	err = s.marketRepo.SaveDailyBar(ctx, corrected)
+	if err == nil {
+		var restated restatementResult
+		restated, err = s.restate(ctx, req.Symbol, req.Exchange, validation.DateRange{Start: date, End: date})
+		diff.Restatement = &restated
+		result += fmt.Sprintf("，重算指标 %d 条，过期回测 %d 个", restated.Indicators, restated.StaleBacktests)
+	}
+	s.finishAuditLog(entry, result, err)
+	if err != nil {
+		respondAdminQueryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "msg": "修正成功", "data": diff})
+}
+
+// storedDailyBar 查询某日已保存的日K线，不存在时返回 nil
+func (s *DataSyncService) storedDailyBar(ctx context.Context, symbol, exchange string, date time.Time) (*models.DailyBar, error) {
+	bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, date, date.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, bar := range bars {
+		if bar.Date.UTC().Format(validation.DateLayout) == date.Format(validation.DateLayout) {
+			return bar, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/quality"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 重复K线清理 ============
+
+// adminMaxDedupeDays 单次清理重复K线的最大跨度（天）
+const adminMaxDedupeDays = 365 * 5
+
+// DedupeReport 重复K线清理报告，只列出存在重复的股票
+type DedupeReport struct {
+	DryRun       bool                    `json:"dry_run"`
+	Start        string                  `json:"start"`
+	End          string                  `json:"end"`
+	Stocks       int                     `json:"stocks"`  // 检查的股票数
+	Removed      int                     `json:"removed"` // 删除（dry_run 时为将删除）的数据点数
+	Failed       []string                `json:"failed"`  // 检查、清理或下游失效失败的股票
+	Results      []*quality.DedupeResult `json:"results"`
+	Restatements []*dedupeRestatement    `json:"restatements"` // 清理后各股票下游数据的失效结果，dry_run 时为空
+}
+
+// dedupeRestatement 一只股票清理重复K线后的下游失效，区间为清理的第一个到最后一个交易日
+type dedupeRestatement struct {
+	Symbol   string `json:"symbol"`
+	Exchange string `json:"exchange"`
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	restatementResult
+}
+
+// DedupeDailyBars 清理重复日K线，symbol 为空时检查全部活跃股票
+// 清理后按 restate 使该股票受影响的下游数据失效（技术指标、因子与质量评分、行情缓存、回测）。
+// 全市场清理时单只股票失败只记录在报告中，不中断其余股票；dryRun 时不记录同步任务。
+func (s *DataSyncService) DedupeDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time, dryRun bool) (report *DedupeReport, err error) {
+	report = &DedupeReport{
+		DryRun:       dryRun,
+		Start:        start.Format(validation.DateLayout),
+		End:          end.Format(validation.DateLayout),
+		Failed:       []string{},
+		Results:      []*quality.DedupeResult{},
+		Restatements: []*dedupeRestatement{},
+	}
+	if !dryRun {
+		var job *syncRun
+		if job, err = s.startJob(ctx, models.SyncJobDedupeBars, symbol, exchange); err != nil {
+			return report, err
+		}
+		defer func() { s.finishJob(job, report.Removed, err) }()
+	}
+
+	stocks := []*models.Stock{{Symbol: symbol, Exchange: exchange}}
+	if symbol == "" {
+		if stocks, err = s.stockRepo.GetActiveStocks(ctx); err != nil {
+			return report, fmt.Errorf("获取股票列表失败: %w", err)
+		}
+	}
+
+	for i, stock := range stocks {
+		if err := s.stockStep(ctx, i, len(stocks), stock); err != nil {
+			return report, err
+		}
+		result, err := s.quality.CleanupDuplicates(ctx, stock.Symbol, stock.Exchange, start, end, dryRun)
+		report.Stocks++
+		if err != nil {
+			if symbol != "" {
+				return report, err
+			}
+			log.Printf("清理 %s.%s 重复K线失败: %v", stock.Symbol, stock.Exchange, err)
+			report.Failed = append(report.Failed, stock.Symbol+"."+stock.Exchange)
+			continue
+		}
+		if len(result.Groups) > 0 {
+			report.Results = append(report.Results, result)
+			report.Removed += result.Removed
+		}
+		if dryRun {
+			continue
+		}
+		restated, err := s.restateDedupe(ctx, result)
+		if err != nil {
+			if symbol != "" {
+				return report, err
+			}
+			log.Printf("%s.%s 清理重复K线后的下游失效失败: %v", stock.Symbol, stock.Exchange, err)
+			report.Failed = append(report.Failed, stock.Symbol+"."+stock.Exchange)
+		}
+		if restated != nil {
+			report.Restatements = append(report.Restatements, restated)
+		}
+	}
+
+	log.Printf("重复K线清理完成（dry_run=%v）：检查 %d 只股票，删除 %d 个数据点", dryRun, report.Stocks, report.Removed)
+	return report, nil
+}
+
+// restateDedupe 使清理重复K线的股票的下游数据失效，没有写回任何交易日时返回 nil
+func (s *DataSyncService) restateDedupe(ctx context.Context, result *quality.DedupeResult) (*dedupeRestatement, error) {
+	var first, last string
+	for _, g := range result.Groups {
+		if g.Keep == nil {
+			continue
+		}
+		if first == "" || g.Date < first {
+			first = g.Date
+		}
+		if g.Date > last {
+			last = g.Date
+		}
+	}
+	if first == "" {
+		return nil, nil
+	}
+	start, err := time.Parse(validation.DateLayout, first)
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(validation.DateLayout, last)
+	if err != nil {
+		return nil, err
+	}
+	restated := &dedupeRestatement{Symbol: result.Symbol, Exchange: result.Exchange, Start: first, End: last}
+	restated.restatementResult, err = s.restate(ctx, result.Symbol, result.Exchange, validation.DateRange{Start: start, End: end})
+	return restated, err
+}
+
+// DedupeBarsRequest 清理重复K线请求
+type DedupeBarsRequest struct {
+	Symbol   string `json:"symbol"` // 为空表示全市场，在后台执行，报告写入审计日志
+	Exchange string `json:"exchange"`
+	Start    string `json:"start"` // 默认最近一年
+	End      string `json:"end"`
+	DryRun   bool   `json:"dry_run"`
+	Reason   string `json:"reason"`
+}
+
+// DedupeBars 检查并清理重复日K线，每个交易日保留时间戳最新且通过校验的一根
+// 单只股票同步返回清理报告；全市场在后台执行，报告见审计日志。
+func (s *DataSyncService) DedupeBars(c *gin.Context) {
+	var req DedupeBarsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if req.Symbol != "" {
+		if err := validateAdminSymbol(req.Symbol, req.Exchange); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{DefaultDays: 365, MaxDays: adminMaxDedupeDays})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if !req.DryRun && req.Reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "执行清理需要填写原因 reason"})
+		return
+	}
+
+	entry := s.newAuditLog(c, models.AuditBarsDedupe, req.Symbol, req.Exchange, req.Reason, &req)
+	if req.Symbol == "" {
+		taskID := s.runAdminTask(entry, func(ctx context.Context) (string, error) {
+			report, err := s.DedupeDailyBars(ctx, "", "", dateRange.Start, dateRange.End, req.DryRun)
+			return dedupeReportJSON(report), err
+		})
+		c.JSON(http.StatusAccepted, gin.H{"code": 0, "msg": "全市场重复K线清理已提交，报告见审计日志", "data": gin.H{"task_id": taskID}})
+		return
+	}
+
+	report, err := s.DedupeDailyBars(c.Request.Context(), req.Symbol, req.Exchange, dateRange.Start, dateRange.End, req.DryRun)
+	if !req.DryRun {
+		s.finishAuditLog(entry, dedupeReportJSON(report), err)
+	}
+	if err != nil {
+		respondAdminQueryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": report})
+}
+
+// dedupeReportJSON 清理报告写入审计日志的 JSON
+func dedupeReportJSON(report *DedupeReport) string {
+	if report == nil {
+		return ""
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return fmt.Sprintf("删除 %d 个数据点", report.Removed)
+	}
+	return string(data)
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// queuedSyncJobRepo 内存中的同步任务记录，按 status 条件开始排队的任务
+type queuedSyncJobRepo struct {
+	repository.SyncJobRepository
+	mu     sync.Mutex
+	jobs   map[uint]models.SyncJob
+	nextID uint
+}
+
+func (r *queuedSyncJobRepo) Enqueue(_ context.Context, job *models.SyncJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	job.ID, job.Status = r.nextID, models.SyncStatusQueued
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *queuedSyncJobRepo) UpdateQueued(_ context.Context, job *models.SyncJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.jobs[job.ID]
+	if !ok || stored.Status != models.SyncStatusQueued {
+		return gorm.ErrRecordNotFound
+	}
+	stored.ParentID, stored.RangeStart, stored.RangeEnd = job.ParentID, job.RangeStart, job.RangeEnd
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *queuedSyncJobRepo) StartQueued(_ context.Context, job *models.SyncJob) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.jobs[job.ID]
+	if !ok || stored.Status != models.SyncStatusQueued {
+		return gorm.ErrRecordNotFound
+	}
+	stored.Status = models.SyncStatusRunning
+	r.jobs[job.ID] = stored
+	return nil
+}
+
+func (r *queuedSyncJobRepo) ListQueued(context.Context) ([]*models.SyncJob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var queued []*models.SyncJob
+	for id := uint(1); id <= r.nextID; id++ {
+		if job, ok := r.jobs[id]; ok && job.Status == models.SyncStatusQueued {
+			queued = append(queued, &job)
+		}
+	}
+	return queued, nil
+}
+
+func (r *queuedSyncJobRepo) Finish(_ context.Context, job *models.SyncJob, records int, jobErr error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job.Status, job.Records = models.SyncStatusSuccess, records
+	if jobErr != nil {
+		job.Status, job.Error = models.SyncStatusFailed, jobErr.Error()
+	}
+	r.jobs[job.ID] = *job
+	return nil
+}
+
+func (r *queuedSyncJobRepo) get(id uint) models.SyncJob {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.jobs[id]
+}
+
+// indicatorMarketRepo 返回固定的日K线，记录重算写入的指标条数
+type indicatorMarketRepo struct {
+	repository.MarketRepository
+	bars  []*models.DailyBar
+	saved chan int
+}
+
+func (r *indicatorMarketRepo) GetDailyBars(context.Context, string, string, time.Time, time.Time) ([]*models.DailyBar, error) {
+	return r.bars, nil
+}
+
+func (r *indicatorMarketRepo) DeleteSeries(context.Context, string, string, string, time.Time, time.Time) error {
+	return nil
+}
+
+func (r *indicatorMarketRepo) SaveIndicators(_ context.Context, indicators []*models.Indicator) error {
+	r.saved <- len(indicators)
+	return nil
+}
+
+// 登记的指标回补写入排队记录；进程退出后重新启动，从排队记录继续执行并在同一条记录上结束
+func TestDependentsSurviveRestart(t *testing.T) {
+	repo := &queuedSyncJobRepo{jobs: make(map[uint]models.SyncJob), nextID: 10}
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	var bars []*models.DailyBar
+	for i := 60; i >= 1; i-- {
+		bars = append(bars, &models.DailyBar{Symbol: "600519", Exchange: "SH", Date: now.AddDate(0, 0, -i), Close: float64(100 + i)})
+	}
+	upstreamID := uint(3)
+	upstream := &syncRun{job: &models.SyncJob{ID: upstreamID}}
+
+	// 第一个进程登记后退出，队列没有执行
+	before := &DataSyncService{syncJobRepo: repo, dataSource: "akshare"}
+	before.dependents = jobs.NewQueue(func(context.Context, jobs.Dependent) {
+		t.Error("退出前不应执行")
+	}, dependentStore{repo: repo, source: before.dataSource})
+	before.enqueueIndicators(upstream, "600519", "SH", bars[50:])
+	before.enqueueIndicators(upstream, "600519", "SH", bars[40:45])
+
+	queued, _ := repo.ListQueued(context.Background())
+	if len(queued) != 1 {
+		t.Fatalf("同一股票的两次登记应合并为一条排队记录: %+v", queued)
+	}
+	job := queued[0]
+	if job.JobType != models.SyncJobIndicators || job.ParentID == nil || *job.ParentID != upstreamID || job.Source != "akshare" {
+		t.Fatalf("排队记录 %+v", job)
+	}
+	if !job.RangeStart.Equal(bars[40].Date) {
+		t.Errorf("合并后的区间从 %v 开始，期望 %v", job.RangeStart, bars[40].Date)
+	}
+
+	// 重启后重新排队并执行
+	market := &indicatorMarketRepo{bars: bars, saved: make(chan int, 1)}
+	after := &DataSyncService{syncJobRepo: repo, marketRepo: market, dataSource: "akshare"}
+	after.dependents = jobs.NewQueue(after.runDependent, dependentStore{repo: repo, source: after.dataSource})
+	after.restoreDependents(context.Background())
+	if n := after.dependents.Pending(); n != 1 {
+		t.Fatalf("重新排队 %d 个任务，期望 1", n)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go after.dependents.Run(ctx)
+
+	var records int
+	select {
+	case records = <-market.saved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("重新排队的任务没有执行")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for repo.get(job.ID).Status != models.SyncStatusSuccess {
+		if time.Now().After(deadline) {
+			t.Fatalf("排队记录 %+v 没有结束", repo.get(job.ID))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := repo.get(job.ID); got.Records != records || repo.nextID != 11 {
+		t.Errorf("应在排队记录上结束、不新建记录: %+v，下一个ID %d", got, repo.nextID+1)
+	}
+
+	// 已由其他实例开始执行的记录不再执行
+	if _, err := after.startQueuedJob(context.Background(), jobs.Dependent{ID: job.ID, Type: job.JobType}); err == nil {
+		t.Error("重复开始同一条排队记录应返回错误")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"stock-analysis-system/backend/pkg/factor"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 财报同步 ============
+
+// SyncFinancialReports 同步个股财务报告，返回写入的报告期数
+func (s *DataSyncService) SyncFinancialReports(ctx context.Context, symbol, exchange string) (count int, err error) {
+	job, err := s.startJob(ctx, models.SyncJobFinancials, symbol, exchange)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	reports, err := s.fetchFinancialReportsFromPython(ctx, symbol, exchange)
+	if err != nil {
+		return 0, fmt.Errorf("从 Python 服务获取财报失败: %w", err)
+	}
+
+	if err := s.factorRepo.SaveFinancialReports(ctx, reports); err != nil {
+		return 0, fmt.Errorf("保存财报失败: %w", err)
+	}
+
+	log.Printf("%s.%s 的财报同步完成，共 %d 期", symbol, exchange, len(reports))
+	return len(reports), nil
+}
+
+// SyncFinancialReportsForAllStocks 为所有股票同步财务报告，返回逐只股票的结果
+// 记录一条全市场 financial_reports 同步任务；全部股票失败时返回错误，部分失败时任务状态为 partial。
+func (s *DataSyncService) SyncFinancialReportsForAllStocks(ctx context.Context) (report *models.SyncReport, err error) {
+	job, err := s.startJob(ctx, models.SyncJobFinancials, "", "")
+	if err != nil {
+		return nil, err
+	}
+	ctx = job.withTask(ctx)
+	defer func() { s.finishReport(job, report, err) }()
+
+	stocks, err := s.stockRepo.GetActiveStocks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取股票列表失败: %w", err)
+	}
+
+	log.Printf("开始为 %d 只股票同步财报", len(stocks))
+	report = models.NewSyncReport(len(stocks))
+
+	for i, stock := range stocks {
+		if err := s.stockStep(ctx, i, len(stocks), stock); err != nil {
+			return report, err
+		}
+		count, err := s.SyncFinancialReports(ctx, stock.Symbol, stock.Exchange)
+		if err != nil {
+			log.Printf("同步 %s.%s 财报失败: %v", stock.Symbol, stock.Exchange, err)
+			report.Fail(stock.Symbol, stock.Exchange, err)
+			continue
+		}
+		report.Succeed(stock.Symbol, stock.Exchange, count)
+
+		// 避免请求过快
+		if err := throttle(ctx, 500*time.Millisecond); err != nil {
+			return report, err
+		}
+	}
+
+	log.Printf("所有股票财报同步完成，%s", report.Summary())
+	return report, report.Err()
+}
+
+// fetchFinancialReportsFromPython 从 Python 服务获取财务报告
+func (s *DataSyncService) fetchFinancialReportsFromPython(ctx context.Context, symbol, exchange string) ([]*models.FinancialReport, error) {
+	url := fmt.Sprintf("%s/api/v1/market/financial_reports?symbol=%s&exchange=%s", s.pythonAPIURL, symbol, exchange)
+
+	var result struct {
+		Code int                       `json:"code"`
+		Data []*models.FinancialReport `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	for _, report := range result.Data {
+		report.Symbol = symbol
+		report.Exchange = exchange
+	}
+
+	return result.Data, nil
+}
+
+// ============ 因子得分计算 ============
+
+// ComputeFactorScores 计算指定交易日全部活跃股票的因子得分
+// 价格类因子使用截至该日的日K线，价值因子使用该日已披露的最近一期财报；
+// 已同步宏观序列时同时计算可选的宏观敏感度因子，日K线回看期相应延长。
+func (s *DataSyncService) ComputeFactorScores(ctx context.Context, date time.Time) (count int, err error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	log.Printf("开始计算 %s 的因子得分", date.Format("2006-01-02"))
+
+	job, err := s.startJob(ctx, models.SyncJobFactors, "", "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	return s.computeFactorScores(ctx, []time.Time{date})
+}
+
+// recomputeFactorScores 重算 [start, end] 内已有因子得分的交易日，日K线被修正后由下游任务队列执行
+func (s *DataSyncService) recomputeFactorScores(ctx context.Context, start, end time.Time) (int, error) {
+	dates, err := s.factorRepo.GetTradeDates(ctx, start, end)
+	if err != nil {
+		return 0, fmt.Errorf("查询因子得分交易日失败: %w", err)
+	}
+	if len(dates) == 0 {
+		return 0, nil
+	}
+	log.Printf("重算 %s ~ %s 的因子得分，共 %d 个交易日", dates[0].Format("2006-01-02"), dates[len(dates)-1].Format("2006-01-02"), len(dates))
+	return s.computeFactorScores(ctx, dates)
+}
+
+// computeFactorScores 计算并保存按时间升序的各交易日（UTC 零点）的因子得分，返回保存的得分条数
+// 每只股票的日K线与宏观序列只查询一次，按交易日截取各自的回看区间。
+func (s *DataSyncService) computeFactorScores(ctx context.Context, dates []time.Time) (int, error) {
+	stocks, err := s.stockRepo.GetActiveStocks(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取股票列表失败: %w", err)
+	}
+
+	first, last := dates[0], dates[len(dates)-1]
+	end := last.Add(24*time.Hour - time.Nanosecond)
+	macro, err := s.loadMacroSeries(ctx, first.AddDate(0, 0, -factor.MacroHistoryDays), end)
+	if err != nil {
+		// 宏观因子可选，查询失败时只计算其余因子
+		log.Printf("查询宏观序列失败，跳过宏观因子: %v", err)
+		macro = nil
+	}
+	history := factor.HistoryDays
+	if len(macro) > 0 {
+		history = factor.MacroHistoryDays
+	}
+
+	type stockBars struct {
+		stock  *models.Stock
+		closes []float64
+		dates  []time.Time
+	}
+	loaded := make([]stockBars, 0, len(stocks))
+	for i, stock := range stocks {
+		if err := s.stockStep(ctx, i, len(stocks), stock); err != nil {
+			return 0, err
+		}
+		bars, err := s.marketRepo.GetDailyBars(ctx, stock.Symbol, stock.Exchange, first.AddDate(0, 0, -history), end)
+		if err != nil {
+			log.Printf("查询 %s.%s 日K线失败: %v", stock.Symbol, stock.Exchange, err)
+			continue
+		}
+		sb := stockBars{stock: stock, closes: make([]float64, 0, len(bars)), dates: make([]time.Time, 0, len(bars))}
+		for _, bar := range bars {
+			sb.closes = append(sb.closes, bar.Close)
+			sb.dates = append(sb.dates, bar.Date)
+		}
+		loaded = append(loaded, sb)
+	}
+
+	count := 0
+	for _, date := range dates {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		reports, err := s.factorRepo.GetDisclosedReports(ctx, date)
+		if err != nil {
+			return count, fmt.Errorf("查询财报失败: %w", err)
+		}
+		from, to := date.AddDate(0, 0, -history), date.Add(24*time.Hour-time.Nanosecond)
+		dayMacro := macroAsOf(macro, to)
+		inputs := make([]*factor.Input, 0, len(loaded))
+		for _, sb := range loaded {
+			i := sort.Search(len(sb.dates), func(i int) bool { return !sb.dates[i].Before(from) })
+			j := sort.Search(len(sb.dates), func(j int) bool { return sb.dates[j].After(to) })
+			inputs = append(inputs, &factor.Input{
+				Symbol:     sb.stock.Symbol,
+				Exchange:   sb.stock.Exchange,
+				Closes:     sb.closes[i:j],
+				Dates:      sb.dates[i:j],
+				Report:     reports[sb.stock.GetFullCode()],
+				TotalShare: sb.stock.TotalShare,
+				Macro:      dayMacro,
+			})
+		}
+
+		scores := factor.Score(date, inputs)
+		if err := s.factorRepo.SaveScores(ctx, date, scores); err != nil {
+			return count, fmt.Errorf("保存 %s 的因子得分失败: %w", date.Format("2006-01-02"), err)
+		}
+		count += len(scores)
+		log.Printf("%s 的因子得分计算完成，共 %d 只股票 %d 条得分", date.Format("2006-01-02"), len(inputs), len(scores))
+	}
+	return count, nil
+}
+
+// macroAsOf 截至 date 已有的宏观序列数据点，没有宏观序列时返回 nil
+func macroAsOf(macro map[string][]*models.MacroPoint, date time.Time) map[string][]*models.MacroPoint {
+	if len(macro) == 0 {
+		return nil
+	}
+	out := make(map[string][]*models.MacroPoint, len(macro))
+	for name, points := range macro {
+		n := sort.Search(len(points), func(i int) bool { return points[i].Date.After(date) })
+		if n > 0 {
+			out[name] = points[:n]
+		}
+	}
+	return out
+}
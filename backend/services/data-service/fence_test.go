@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// fencedSyncJobRepo 记录已写入过的最大令牌，拒绝更旧的令牌
+type fencedSyncJobRepo struct {
+	repository.SyncJobRepository
+	mu     sync.Mutex
+	latest int64
+	nextID uint
+}
+
+func (r *fencedSyncJobRepo) advance(fence int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latest = fence
+}
+
+func (r *fencedSyncJobRepo) CheckFence(_ context.Context, fence int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if fence != 0 && r.latest > fence {
+		return lock.ErrFenced
+	}
+	return nil
+}
+
+func (r *fencedSyncJobRepo) Start(ctx context.Context, job *models.SyncJob) error {
+	if err := r.CheckFence(ctx, job.Fence); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	job.ID = r.nextID
+	return nil
+}
+
+func (r *fencedSyncJobRepo) Finish(context.Context, *models.SyncJob, int, error) error {
+	return nil
+}
+
+func (r *fencedSyncJobRepo) FinishWithReport(context.Context, *models.SyncJob, *models.SyncReport, error) error {
+	return nil
+}
+
+// savingMarketRepo 记录写入日K线的股票
+type savingMarketRepo struct {
+	repository.MarketRepository
+	mu    sync.Mutex
+	saved []string
+}
+
+func (r *savingMarketRepo) SaveDailyBars(_ context.Context, bars []*models.DailyBar) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.saved = append(r.saved, bars[0].Symbol)
+	return nil
+}
+
+type activeStockRepo struct {
+	repository.StockRepository
+	stocks []*models.Stock
+}
+
+func (r activeStockRepo) GetActiveStocks(context.Context) ([]*models.Stock, error) {
+	return r.stocks, nil
+}
+
+// 旧主节点持有过期令牌时不拉取、不写入任何K线
+func TestFencedSyncDoesNotStart(t *testing.T) {
+	syncJobs := &fencedSyncJobRepo{latest: 8}
+	market := &savingMarketRepo{}
+	s := &DataSyncService{syncJobRepo: syncJobs, marketRepo: market}
+
+	ctx := lock.WithFence(context.Background(), 7)
+	if _, err := s.SyncDailyBars(ctx, "600519", "SH", time.Now().AddDate(0, 0, -5), time.Now()); !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("过期令牌同步日K线应返回 ErrFenced，实际 %v", err)
+	}
+	if _, err := s.SyncDailyBarsForAllStocks(ctx, time.Now().AddDate(0, 0, -5), time.Now()); !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("过期令牌全市场同步应返回 ErrFenced，实际 %v", err)
+	}
+	if len(market.saved) != 0 {
+		t.Errorf("过期令牌写入了K线: %v", market.saved)
+	}
+}
+
+// 同步过程中主节点切换：正在拉取的股票不再写入，其余股票不再处理
+func TestFencedSyncStopsMidRun(t *testing.T) {
+	syncJobs := &fencedSyncJobRepo{latest: 7}
+	market := &savingMarketRepo{}
+
+	var mu sync.Mutex
+	fetched := 0
+	python := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched++
+		if fetched == 2 {
+			// 拉取第二只股票期间新主节点以令牌 8 开始任务
+			syncJobs.advance(8)
+		}
+		mu.Unlock()
+		symbol := r.URL.Query().Get("symbol")
+		bar := &models.DailyBar{Symbol: symbol, Exchange: "SH", Date: time.Now().AddDate(0, 0, -1), Close: 10}
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "data": []*models.DailyBar{bar}})
+	}))
+	defer python.Close()
+
+	s := &DataSyncService{
+		syncJobRepo:  syncJobs,
+		marketRepo:   market,
+		stockRepo:    activeStockRepo{stocks: []*models.Stock{{Symbol: "600000", Exchange: "SH"}, {Symbol: "600001", Exchange: "SH"}, {Symbol: "600002", Exchange: "SH"}}},
+		httpClient:   python.Client(),
+		pythonAPIURL: python.URL,
+		dependents:   jobs.NewQueue(func(context.Context, jobs.Dependent) {}, nil),
+	}
+	ctx := lock.WithFence(context.Background(), 7)
+	report, err := s.SyncDailyBarsForAllStocks(ctx, time.Now().AddDate(0, 0, -5), time.Now())
+	if !errors.Is(err, lock.ErrFenced) {
+		t.Fatalf("主节点切换后应返回 ErrFenced，实际 %v", err)
+	}
+	if len(market.saved) != 1 || market.saved[0] != "600000" {
+		t.Errorf("写入K线的股票 %v，期望只有切换前的 600000", market.saved)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fetched != 2 || len(report.Succeeded) != 1 {
+		t.Errorf("拉取 %d 次、成功 %v，切换后不应再处理其余股票", fetched, report.Succeeded)
+	}
+}
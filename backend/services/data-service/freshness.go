@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/quality"
+)
+
+// ============ 全市场数据新鲜度 ============
+
+// freshnessLookbackDays 查找最新数据点的回看天数，更早的数据视为没有数据
+const freshnessLookbackDays = 60
+
+// freshnessStatusRank 状态的严重程度，汇总状态取最严重的一项
+var freshnessStatusRank = map[string]int{"pass": 0, "warning": 1, "error": 2}
+
+// GetDataFreshness 汇总日K线、分钟K线、指标与财报的全市场新鲜度，供运维看板使用
+// 每类数据给出最新数据时间、相对交易日历预期的落后程度，以及统计日期的股票数与数据点数；
+// 单类数据查询失败时该项为 error，不影响其他数据类型。
+func (s *DataSyncService) GetDataFreshness(c *gin.Context) {
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	stocks, err := s.stockRepo.GetActiveStocks(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "获取股票列表失败: " + err.Error()})
+		return
+	}
+
+	interval := time.Duration(s.cfg.Intraday.Interval) * time.Second
+	measurements := []*quality.MeasurementFreshness{
+		s.marketFreshness(ctx, database.DataDailyBars, now, func(latest *time.Time) *quality.MeasurementFreshness {
+			return quality.DailyFreshness(s.calendar, database.DataDailyBars, latest, now)
+		}),
+		s.marketFreshness(ctx, database.DataMinuteBars, now, func(latest *time.Time) *quality.MeasurementFreshness {
+			return quality.MinuteFreshness(s.calendar, latest, now, interval)
+		}),
+		s.marketFreshness(ctx, database.DataIndicators, now, func(latest *time.Time) *quality.MeasurementFreshness {
+			return quality.DailyFreshness(s.calendar, database.DataIndicators, latest, now)
+		}),
+		s.fundamentalsFreshness(ctx, now),
+	}
+
+	status := "pass"
+	for _, m := range measurements {
+		if freshnessStatusRank[m.Status] > freshnessStatusRank[status] {
+			status = m.Status
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"checked_at":   now,
+			"status":       status,
+			"universe":     len(stocks),
+			"measurements": measurements,
+		},
+	})
+}
+
+// marketFreshness 查询一类行情数据的最新数据时间并按 evaluate 判断新鲜度，再统计统计日期当天的数据量
+func (s *DataSyncService) marketFreshness(ctx context.Context, dataType string, now time.Time,
+	evaluate func(latest *time.Time) *quality.MeasurementFreshness) *quality.MeasurementFreshness {
+	latest, err := s.marketRepo.LatestTime(ctx, dataType, now.AddDate(0, 0, -freshnessLookbackDays))
+	if err != nil {
+		return &quality.MeasurementFreshness{Measurement: dataType, Status: "error", Message: err.Error()}
+	}
+	f := evaluate(latest)
+
+	day, err := markettime.ParseDate(f.CountDate, "")
+	if err != nil {
+		return f
+	}
+	if f.Symbols, f.Points, err = s.marketRepo.CountMarket(ctx, dataType, day, day.AddDate(0, 0, 1)); err != nil {
+		f.Status, f.Message = "error", err.Error()
+	}
+	return f
+}
+
+// fundamentalsFreshness 财报的新鲜度，统计日期为已过披露截止日的最近报告期，数据量为该期已同步的财报数
+func (s *DataSyncService) fundamentalsFreshness(ctx context.Context, now time.Time) *quality.MeasurementFreshness {
+	latest, err := s.factorRepo.GetLatestReportDate(ctx, now)
+	if err != nil {
+		return &quality.MeasurementFreshness{Measurement: "fundamentals", Status: "error", Message: "查询财报失败: " + err.Error()}
+	}
+	f := quality.FundamentalsFreshness(latest, now)
+
+	count, err := s.factorRepo.CountReports(ctx, *f.Expected)
+	if err != nil {
+		f.Status, f.Message = "error", "统计财报失败: "+err.Error()
+		return f
+	}
+	f.Symbols, f.Points = int(count), count
+	return f
+}
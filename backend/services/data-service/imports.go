@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/barimport"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 历史K线批量导入 ============
+
+const (
+	maxImportBars      = 200000   // 单次导入的K线数上限
+	maxImportBodyBytes = 64 << 20 // 导入接口的请求体上限
+)
+
+// ImportBarsRequest 批量导入K线请求，type 为 daily 时读取 daily_bars，为 minute 时读取 minute_bars
+type ImportBarsRequest struct {
+	Type        string              `json:"type"`
+	DailyBars   []*models.DailyBar  `json:"daily_bars"`
+	MinuteBars  []*models.MinuteBar `json:"minute_bars"`
+	BatchSize   int                 `json:"batch_size"`
+	MaxRetries  int                 `json:"max_retries"`
+	StopOnError bool                `json:"stop_on_error"`
+}
+
+// ImportBars 导入外部整理好的历史K线
+// 按批同步写入 InfluxDB，失败批次按指数退避重试，响应中返回每批的写入结果，便于只补写失败的区间。
+func (s *DataSyncService) ImportBars(ctx context.Context, req *ImportBarsRequest) (result *repository.BulkImportResult, err error) {
+	opts := repository.BulkImportOptions{
+		BatchSize:   req.BatchSize,
+		MaxRetries:  req.MaxRetries,
+		StopOnError: req.StopOnError,
+	}
+
+	jobType := models.SyncJobDailyBars
+	if req.Type == "minute" {
+		jobType = models.SyncJobMinuteBars
+	}
+	job, err := s.startJob(ctx, jobType, "", "")
+	if err != nil {
+		return nil, err
+	}
+	// 部分批次失败时任务记为失败，明细通过 result 返回给调用方
+	defer func() {
+		records, jobErr := 0, err
+		if result != nil {
+			records = result.Written
+			if jobErr == nil && result.Failed+result.Skipped > 0 {
+				jobErr = fmt.Errorf("%d 条写入失败，%d 条未写入", result.Failed, result.Skipped)
+			}
+		}
+		s.finishJob(job, records, jobErr)
+	}()
+
+	start := time.Now()
+	if req.Type == "minute" {
+		result, err = s.marketRepo.ImportMinuteBars(ctx, req.MinuteBars, opts)
+	} else {
+		result, err = s.marketRepo.ImportDailyBars(ctx, req.DailyBars, opts)
+	}
+	if result != nil {
+		log.Printf("导入 %s K线 %d 条：写入 %d，失败 %d，未写入 %d，共 %d 批，耗时 %v",
+			req.Type, result.Total, result.Written, result.Failed, result.Skipped, len(result.Chunks), time.Since(start))
+	}
+	return result, err
+}
+
+// validateImportRequest 校验导入请求
+func validateImportRequest(req *ImportBarsRequest) error {
+	var count int
+	switch req.Type {
+	case "daily":
+		count = len(req.DailyBars)
+		for i, bar := range req.DailyBars {
+			if bar == nil || bar.Symbol == "" || bar.Exchange == "" || bar.Date.IsZero() {
+				return fmt.Errorf("daily_bars[%d] 缺少 symbol、exchange 或 date", i)
+			}
+		}
+	case "minute":
+		count = len(req.MinuteBars)
+		for i, bar := range req.MinuteBars {
+			if bar == nil || bar.Symbol == "" || bar.Exchange == "" || bar.Interval == "" || bar.Time.IsZero() {
+				return fmt.Errorf("minute_bars[%d] 缺少 symbol、exchange、interval 或 time", i)
+			}
+		}
+	default:
+		return fmt.Errorf("type 应为 daily 或 minute")
+	}
+	if count == 0 {
+		return fmt.Errorf("没有需要导入的K线")
+	}
+	if count > maxImportBars {
+		return fmt.Errorf("单次最多导入 %d 条K线，请拆分后提交", maxImportBars)
+	}
+	return nil
+}
+
+// handleImportBars 批量导入K线接口
+func (s *DataSyncService) handleImportBars(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ImportBarsRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportBodyBytes)).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateImportRequest(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.ImportBars(r.Context(), &req)
+	if err != nil && result == nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	message := "Bars imported successfully"
+	if err != nil {
+		message = "Import interrupted: " + err.Error()
+	} else if result.Failed+result.Skipped > 0 {
+		message = "Bars partially imported"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    0,
+		"message": message,
+		"data":    result,
+	})
+}
+
+// ============ 离线文件导入 ============
+
+const (
+	maxImportFiles       = 500 // 单次导入的文件数上限
+	maxReportedRowErrors = 20  // 每个文件在响应中返回的行级错误数
+)
+
+// FileImportOptions 离线文件导入参数
+type FileImportOptions struct {
+	Path     string `json:"path"`     // 服务器上的文件或目录，需位于 IMPORT_DATA_DIR 下
+	Format   string `json:"format"`   // tdx/tushare/generic，默认自动识别
+	Symbol   string `json:"symbol"`   // 文件中没有代码列时使用，默认从文件名推断
+	Exchange string `json:"exchange"` // 默认按代码段推断
+	Interval string `json:"interval"` // 1d 或 1m/5m/15m/30m/60m，默认从文件推断
+	DryRun   bool   `json:"dry_run"`  // 只解析校验，不写入
+}
+
+// FileImportResult 单个文件的导入结果
+type FileImportResult struct {
+	File       string                       `json:"file"`
+	Format     string                       `json:"format,omitempty"`
+	Interval   string                       `json:"interval,omitempty"`
+	Rows       int                          `json:"rows"`
+	ErrorCount int                          `json:"error_count"`
+	Errors     []*barimport.RowError        `json:"errors,omitempty"`
+	Error      string                       `json:"error,omitempty"` // 文件无法解析或写入中断
+	Write      *repository.BulkImportResult `json:"write,omitempty"`
+}
+
+// ImportFile 解析一个离线K线文件并批量写入 InfluxDB
+func (s *DataSyncService) ImportFile(ctx context.Context, name string, r io.Reader, opts FileImportOptions) *FileImportResult {
+	result := &FileImportResult{File: name}
+	parsed, err := barimport.Parse(r, barimport.Options{
+		Format:   opts.Format,
+		FileName: name,
+		Symbol:   opts.Symbol,
+		Exchange: opts.Exchange,
+		Interval: opts.Interval,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Format, result.Interval = parsed.Format, parsed.Interval
+	result.Rows, result.ErrorCount = parsed.Rows(), len(parsed.Errors)
+	result.Errors = parsed.Errors[:min(len(parsed.Errors), maxReportedRowErrors)]
+	if opts.DryRun || parsed.Rows() == 0 {
+		return result
+	}
+
+	req := &ImportBarsRequest{Type: "daily", DailyBars: parsed.DailyBars}
+	if parsed.Interval != barimport.IntervalDaily {
+		req = &ImportBarsRequest{Type: "minute", MinuteBars: parsed.MinuteBars}
+	}
+	result.Write, err = s.ImportBars(ctx, req)
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// importFilesFromPath 导入服务器目录中的 .txt/.csv 文件（包含子目录）
+// 只允许 IMPORT_DATA_DIR 下的路径，未配置时不支持按路径导入。
+func (s *DataSyncService) importFilesFromPath(ctx context.Context, opts FileImportOptions) ([]*FileImportResult, error) {
+	root := getEnv("IMPORT_DATA_DIR", "")
+	if root == "" {
+		return nil, fmt.Errorf("未配置 IMPORT_DATA_DIR，不支持按路径导入")
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	target := opts.Path
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(root, target)
+	}
+	target = filepath.Clean(target)
+	if rel, err := filepath.Rel(root, target); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("路径必须位于 IMPORT_DATA_DIR 下")
+	}
+
+	var files []string
+	err = filepath.WalkDir(target, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if d.IsDir() || (ext != ".txt" && ext != ".csv") {
+			return nil
+		}
+		if len(files) >= maxImportFiles {
+			return fmt.Errorf("单次最多导入 %d 个文件", maxImportFiles)
+		}
+		files = append(files, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("路径下没有 .txt 或 .csv 文件")
+	}
+
+	results := make([]*FileImportResult, 0, len(files))
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		rel, _ := filepath.Rel(root, path)
+		f, err := os.Open(path)
+		if err != nil {
+			results = append(results, &FileImportResult{File: rel, Error: err.Error()})
+			continue
+		}
+		results = append(results, s.ImportFile(ctx, rel, f, opts))
+		f.Close()
+	}
+	return results, nil
+}
+
+// importUploadedFiles 导入 multipart 表单字段 file 中上传的文件，可上传多个
+func (s *DataSyncService) importUploadedFiles(ctx context.Context, form *multipart.Form, opts FileImportOptions) ([]*FileImportResult, error) {
+	headers := form.File["file"]
+	if len(headers) == 0 {
+		return nil, fmt.Errorf("缺少上传文件 file")
+	}
+	if len(headers) > maxImportFiles {
+		return nil, fmt.Errorf("单次最多导入 %d 个文件", maxImportFiles)
+	}
+
+	results := make([]*FileImportResult, 0, len(headers))
+	for _, header := range headers {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		f, err := header.Open()
+		if err != nil {
+			results = append(results, &FileImportResult{File: header.Filename, Error: err.Error()})
+			continue
+		}
+		results = append(results, s.ImportFile(ctx, header.Filename, f, opts))
+		f.Close()
+	}
+	return results, nil
+}
+
+// handleImportFiles 离线K线文件导入接口：multipart 上传文件，或 JSON 指定服务器上的路径
+func (s *DataSyncService) handleImportFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var opts FileImportOptions
+	var results []*FileImportResult
+	var err error
+	ctx := r.Context()
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts = FileImportOptions{
+			Format:   r.FormValue("format"),
+			Symbol:   r.FormValue("symbol"),
+			Exchange: r.FormValue("exchange"),
+			Interval: r.FormValue("interval"),
+			DryRun:   r.FormValue("dry_run") == "true",
+		}
+		if err := validateFileImportOptions(&opts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results, err = s.importUploadedFiles(ctx, r.MultipartForm, opts)
+	} else {
+		if err := json.NewDecoder(r.Body).Decode(&opts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if opts.Path == "" {
+			http.Error(w, "需要上传文件或指定 path", http.StatusBadRequest)
+			return
+		}
+		if err := validateFileImportOptions(&opts); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		results, err = s.importFilesFromPath(ctx, opts)
+	}
+	if err != nil && len(results) == 0 {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rows, written, failed int
+	for _, result := range results {
+		rows += result.Rows
+		if result.Write != nil {
+			written += result.Write.Written
+		}
+		if result.Error != "" || result.ErrorCount > 0 || (result.Write != nil && result.Write.Failed+result.Write.Skipped > 0) {
+			failed++
+		}
+	}
+	message := "Files imported successfully"
+	switch {
+	case err != nil:
+		message = "Import interrupted: " + err.Error()
+	case opts.DryRun:
+		message = "Files validated"
+	case failed > 0:
+		message = "Files partially imported"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    0,
+		"message": message,
+		"data": map[string]interface{}{
+			"files":        results,
+			"rows":         rows,
+			"written":      written,
+			"failed_files": failed,
+			"dry_run":      opts.DryRun,
+		},
+	})
+}
+
+// validateFileImportOptions 校验格式与周期参数
+func validateFileImportOptions(opts *FileImportOptions) error {
+	switch opts.Format {
+	case barimport.FormatAuto, barimport.FormatTDX, barimport.FormatTushare, barimport.FormatGeneric:
+	default:
+		return fmt.Errorf("format 应为 tdx、tushare 或 generic")
+	}
+	switch opts.Interval {
+	case "", barimport.IntervalDaily, "1m", "5m", "15m", "30m", "60m":
+	default:
+		return fmt.Errorf("interval 应为 1d、1m、5m、15m、30m 或 60m")
+	}
+	opts.Exchange = strings.ToUpper(opts.Exchange)
+	return nil
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+)
+
+// ============ 盘中同步 ============
+
+const (
+	intradayInterval    = "1m"             // 盘中同步的分钟K线周期
+	intradayLookback    = 5 * time.Minute  // 每次至少重新拉取的时间范围，覆盖数据源延迟与上次同步失败
+	intradayConcurrency = 4                // 并发请求 Python 服务的股票数
+	intradayMaxIdle     = 30 * time.Minute // 非交易时段最长休眠时间，避免系统时钟调整后错过开盘
+)
+
+// intradayCode 盘中同步的股票
+type intradayCode struct{ symbol, exchange string }
+
+// startIntradaySync 在各交易所交易时段内每隔 Intraday.Interval 秒同步最新分钟K线，非交易时段休眠到下次开盘
+// 交易时段与休市日由交易日历（calendar）按交易所配置；多实例部署时只有主节点执行，每个间隔只执行一次。
+func (s *DataSyncService) startIntradaySync(ctx context.Context) {
+	interval := time.Duration(s.cfg.Intraday.Interval) * time.Second
+	if interval <= 0 {
+		log.Println("未启用盘中同步（INTRADAY_SYNC_INTERVAL 不大于 0）")
+		return
+	}
+
+	go func() {
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-timer.C:
+				if exchanges := s.openExchanges(now); len(exchanges) > 0 {
+					slot := now.Truncate(interval).Format("20060102150405")
+					s.leader.RunOnce("intraday", slot, 2*interval, func(ctx context.Context, token int64) {
+						if err := s.SyncIntradayBars(lock.WithFence(ctx, token), exchanges, now, interval); err != nil {
+							log.Printf("盘中同步失败: %v", err)
+						}
+					})
+				}
+				timer.Reset(s.nextIntradaySync(time.Now(), interval))
+			}
+		}
+	}()
+}
+
+// openExchanges 当前处于交易时段的交易所
+func (s *DataSyncService) openExchanges(now time.Time) map[string]bool {
+	open := make(map[string]bool)
+	for _, exchange := range []string{"SH", "SZ", "BJ"} {
+		if s.calendar.InSession(exchange, now) {
+			open[exchange] = true
+		}
+	}
+	return open
+}
+
+// nextIntradaySync 距下次同步的等待时间：交易时段内按间隔对齐，否则等到最早开盘的交易所开盘
+func (s *DataSyncService) nextIntradaySync(now time.Time, interval time.Duration) time.Duration {
+	if len(s.openExchanges(now)) > 0 {
+		return now.Truncate(interval).Add(interval).Sub(now)
+	}
+	wait := intradayMaxIdle
+	for _, exchange := range []string{"SH", "SZ", "BJ"} {
+		if open := s.calendar.NextOpen(exchange, now); !open.IsZero() && open.Sub(now) < wait {
+			wait = open.Sub(now)
+		}
+	}
+	return wait
+}
+
+// SyncIntradayBars 同步处于交易时段的交易所的最新分钟K线
+// 每次拉取最近 max(2*interval, 5 分钟) 的K线（不早于本时段开盘），重复写入的K线在 InfluxDB 中覆盖。
+func (s *DataSyncService) SyncIntradayBars(ctx context.Context, exchanges map[string]bool, now time.Time, interval time.Duration) (err error) {
+	codes, err := s.intradayCodes(ctx)
+	if err != nil {
+		return err
+	}
+	var targets []intradayCode
+	for _, code := range codes {
+		if exchanges[code.exchange] {
+			targets = append(targets, code)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	var records, failed int
+	job, err := s.startJob(ctx, models.SyncJobMinuteBars, "", "")
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, records, err) }()
+
+	lookback := 2 * interval
+	if lookback < intradayLookback {
+		lookback = intradayLookback
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, intradayConcurrency)
+	for _, code := range targets {
+		wg.Add(1)
+		go func(code intradayCode) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := now.Add(-lookback)
+			if open, ok := s.calendar.SessionStart(code.exchange, now); ok && open.After(start) {
+				start = open
+			}
+			bars, err := s.fetchMinuteBarsFromPython(ctx, code.symbol, code.exchange, start, now)
+			if err == nil && len(bars) > 0 {
+				// 拉取期间失去主节点身份时不再写入
+				if err = ctx.Err(); err == nil {
+					err = s.marketRepo.SaveMinuteBars(ctx, bars)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				failed++
+				log.Printf("盘中同步 %s.%s 失败: %v", code.symbol, code.exchange, err)
+				return
+			}
+			records += len(bars)
+		}(code)
+	}
+	wg.Wait()
+
+	// 超过半数股票失败时视为数据源故障
+	if failed*2 > len(targets) {
+		return fmt.Errorf("%d/%d 只股票同步失败", failed, len(targets))
+	}
+	return nil
+}
+
+// intradayCodes 盘中同步的股票：配置的股票，未配置时为指数类股票池的最新成分股
+func (s *DataSyncService) intradayCodes(ctx context.Context) ([]intradayCode, error) {
+	seen := make(map[intradayCode]bool)
+	var codes []intradayCode
+	add := func(symbol, exchange string) {
+		code := intradayCode{symbol, strings.ToUpper(exchange)}
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	if len(s.cfg.Intraday.Symbols) > 0 {
+		for _, key := range s.cfg.Intraday.Symbols {
+			symbol, exchange, ok := pairs.SplitLeg(key)
+			if !ok {
+				return nil, fmt.Errorf("INTRADAY_SYNC_SYMBOLS 中的 %q 格式错误，应为 symbol.exchange", key)
+			}
+			add(symbol, exchange)
+		}
+		return codes, nil
+	}
+
+	universes, err := s.universeRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取股票池失败: %w", err)
+	}
+	for _, universe := range universes {
+		if universe.Type != models.UniverseTypeIndex {
+			continue
+		}
+		date, err := s.universeRepo.GetLatestSnapshotDate(ctx, universe.ID, time.Now())
+		if err != nil || date == nil {
+			continue
+		}
+		members, err := s.universeRepo.GetMembers(ctx, universe.ID, *date)
+		if err != nil {
+			log.Printf("获取股票池 %d 成分失败: %v", universe.ID, err)
+			continue
+		}
+		for _, m := range members {
+			add(m.Symbol, m.Exchange)
+		}
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].exchange != codes[j].exchange {
+			return codes[i].exchange < codes[j].exchange
+		}
+		return codes[i].symbol < codes[j].symbol
+	})
+	return codes, nil
+}
+
+// fetchMinuteBarsFromPython 从 Python 服务获取指定时间范围（交易所时区，含两端，精确到分钟）的 1 分钟K线
+func (s *DataSyncService) fetchMinuteBarsFromPython(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.MinuteBar, error) {
+	url := fmt.Sprintf("%s/api/v1/market/minute_bars?symbol=%s&exchange=%s&interval=%s&start=%s&end=%s",
+		s.pythonAPIURL,
+		symbol,
+		exchange,
+		intradayInterval,
+		start.In(markettime.Location(exchange)).Format("200601021504"),
+		end.In(markettime.Location(exchange)).Format("200601021504"),
+	)
+
+	var result struct {
+		Code int                 `json:"code"`
+		Data []*models.MinuteBar `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	// Python 服务可能不返回标签字段，统一补齐
+	for _, bar := range result.Data {
+		bar.Symbol = symbol
+		bar.Exchange = exchange
+		bar.Interval = intradayInterval
+	}
+
+	return result.Data, nil
+}
@@ -0,0 +1,381 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 同步任务记录 ============
+
+// syncRun 一次同步任务的记录及对应的异步任务，任一记录失败时对应字段为 nil
+// 在后台任务中执行时 task 为 nil，parent 为外层任务。
+type syncRun struct {
+	job    *models.SyncJob
+	task   *jobs.Task
+	parent *jobs.Task
+}
+
+// startJob 记录同步任务开始并登记异步任务，记录失败只打印日志，不影响同步本身
+// 在后台任务（runTask、runAdminTask）中执行时由外层任务统一登记与上报进度，不再单独登记异步任务。
+// 定时任务主节点已切换（ctx 携带的隔离令牌已过期）时返回 lock.ErrFenced，调用方应在写入任何数据前中止。
+func (s *DataSyncService) startJob(ctx context.Context, jobType, symbol, exchange string) (*syncRun, error) {
+	return s.startJobAfter(ctx, nil, jobType, symbol, exchange)
+}
+
+// startDependentJob 记录依赖于 upstream 的下游同步任务（如日K线同步后的指标回补），任务记录的 parent_id 指向上游任务
+// 与上游任务同属一个异步任务，进度与结果由上游任务上报。
+func (s *DataSyncService) startDependentJob(ctx context.Context, upstream *syncRun, jobType, symbol, exchange string) (*syncRun, error) {
+	var parentID *uint
+	if upstream.job != nil {
+		parentID = &upstream.job.ID
+	}
+	return s.startJobAfter(upstream.withTask(ctx), parentID, jobType, symbol, exchange)
+}
+
+// startJobAfter 记录同步任务开始，parentID 为上游任务，没有时为 nil
+func (s *DataSyncService) startJobAfter(ctx context.Context, parentID *uint, jobType, symbol, exchange string) (*syncRun, error) {
+	run := &syncRun{parent: jobs.FromContext(ctx)}
+	if run.parent == nil {
+		run.task = s.tasks.Start(ctx, jobs.Spec{Type: models.TaskTypeSync, Name: jobType})
+	}
+	job := &models.SyncJob{
+		JobType:  jobType,
+		Source:   s.dataSource,
+		Symbol:   symbol,
+		Exchange: exchange,
+		ParentID: parentID,
+		Fence:    lock.FenceFrom(ctx),
+	}
+	if err := s.syncJobRepo.Start(ctx, job); err != nil {
+		if errors.Is(err, lock.ErrFenced) {
+			log.Printf("同步任务 %s 未执行：主节点已切换（令牌 %d）", jobType, job.Fence)
+			run.task.Fail(err, "", "")
+			return nil, err
+		}
+		log.Printf("记录同步任务 %s 失败: %v", jobType, err)
+		return run, nil
+	}
+	run.job = job
+	return run, nil
+}
+
+// checkFence 逐只股票处理、写入数据前检查：ctx 已取消，或携带的隔离令牌已过期（主节点已切换）时返回错误
+func (s *DataSyncService) checkFence(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.syncJobRepo.CheckFence(ctx, lock.FenceFrom(ctx))
+}
+
+// withTask 返回携带本次登记的异步任务的 context：逐只股票处理时个股任务不再单独登记，进度上报到本任务
+func (r *syncRun) withTask(ctx context.Context) context.Context {
+	if r.task == nil {
+		return ctx
+	}
+	return jobs.WithTask(ctx, r.task)
+}
+
+// finishJob 记录同步任务结束，异步任务的结果指向同步任务记录
+// 使用独立的 context，保证请求被取消时任务状态仍能落库。
+func (s *DataSyncService) finishJob(run *syncRun, records int, jobErr error) {
+	resultType, resultID := "", ""
+	if run.job != nil {
+		if err := s.syncJobRepo.Finish(context.Background(), run.job, records, jobErr); err != nil {
+			log.Printf("更新同步任务 %d 状态失败: %v", run.job.ID, err)
+		}
+		resultType, resultID = "sync_job", strconv.FormatUint(uint64(run.job.ID), 10)
+	}
+	if jobErr != nil {
+		run.task.Fail(jobErr, resultType, resultID)
+		return
+	}
+	run.task.Succeed(resultType, resultID)
+}
+
+// finishReport 记录逐只股票处理的同步任务结束，结果明细随同步任务记录保存
+// 异步任务的 message 为结果摘要；在后台任务中执行时外层任务的结果指向该同步任务记录。
+func (s *DataSyncService) finishReport(run *syncRun, report *models.SyncReport, jobErr error) {
+	if report == nil {
+		s.finishJob(run, 0, jobErr)
+		return
+	}
+	resultType, resultID := "", ""
+	if run.job != nil {
+		if err := s.syncJobRepo.FinishWithReport(context.Background(), run.job, report, jobErr); err != nil {
+			log.Printf("更新同步任务 %d 状态失败: %v", run.job.ID, err)
+		}
+		resultType, resultID = "sync_job", strconv.FormatUint(uint64(run.job.ID), 10)
+		run.parent.SetResult(resultType, resultID)
+	}
+	if jobErr != nil {
+		run.task.Fail(jobErr, resultType, resultID)
+		return
+	}
+	run.task.SucceedWithMessage(report.Summary(), resultType, resultID)
+}
+
+// ============ 下游任务 ============
+
+// dependentStore 以 queued 状态的同步任务记录保存排队的下游任务，parent_id 指向登记它的上游任务
+// 排队期间即可在上游任务详情的 dependents 中看到，服务重启后由 restoreDependents 重新排队。
+type dependentStore struct {
+	repo   repository.SyncJobRepository
+	source string
+}
+
+// Save 实现 jobs.DependentStore
+func (st dependentStore) Save(ctx context.Context, d *jobs.Dependent) error {
+	start, end := d.Start, d.End
+	job := &models.SyncJob{
+		ID:         d.ID,
+		JobType:    d.Type,
+		Source:     st.source,
+		Symbol:     d.Symbol,
+		Exchange:   d.Exchange,
+		ParentID:   d.ParentID,
+		RangeStart: &start,
+		RangeEnd:   &end,
+	}
+	if job.ID != 0 {
+		err := st.repo.UpdateQueued(ctx, job)
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		// 记录已由其他实例开始执行，合并后的区间另记一条
+		job.ID = 0
+	}
+	if err := st.repo.Enqueue(ctx, job); err != nil {
+		return err
+	}
+	d.ID = job.ID
+	return nil
+}
+
+// restoreDependents 重新排队上次退出前已登记、尚未执行的下游任务
+func (s *DataSyncService) restoreDependents(ctx context.Context) {
+	queued, err := s.syncJobRepo.ListQueued(ctx)
+	if err != nil {
+		log.Printf("读取排队中的下游任务失败: %v", err)
+		return
+	}
+	ds := make([]jobs.Dependent, 0, len(queued))
+	for _, job := range queued {
+		d := jobs.Dependent{ID: job.ID, Type: job.JobType, Symbol: job.Symbol, Exchange: job.Exchange, ParentID: job.ParentID}
+		if job.RangeStart != nil && job.RangeEnd != nil {
+			d.Start, d.End = *job.RangeStart, *job.RangeEnd
+		}
+		ds = append(ds, d)
+	}
+	if len(ds) > 0 {
+		log.Printf("重新排队 %d 个未执行的下游任务", len(ds))
+	}
+	s.dependents.Restore(ds)
+}
+
+// startQueuedJob 已保存的下游任务开始执行，排队记录改为 running
+// 记录已由其他实例开始执行时返回错误；其他记录失败只打印日志，任务照常执行、记录保持排队，下次启动时重新执行。
+func (s *DataSyncService) startQueuedJob(ctx context.Context, d jobs.Dependent) (*syncRun, error) {
+	start, end := d.Start, d.End
+	job := &models.SyncJob{
+		ID:         d.ID,
+		JobType:    d.Type,
+		Source:     s.dataSource,
+		Symbol:     d.Symbol,
+		Exchange:   d.Exchange,
+		ParentID:   d.ParentID,
+		RangeStart: &start,
+		RangeEnd:   &end,
+	}
+	err := s.syncJobRepo.StartQueued(ctx, job)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("下游任务 %d 已由其他实例执行", d.ID)
+		return nil, err
+	}
+	run := &syncRun{parent: jobs.FromContext(ctx)}
+	if run.parent == nil {
+		run.task = s.tasks.Start(ctx, jobs.Spec{Type: models.TaskTypeSync, Name: d.Type})
+	}
+	if err != nil {
+		log.Printf("记录同步任务 %s 失败: %v", d.Type, err)
+		return run, nil
+	}
+	run.job = job
+	return run, nil
+}
+
+// runDependent 执行排队的下游任务，记录为依赖于登记它的上游任务的同步任务
+// 已保存的任务沿用排队记录，保存失败的任务开始执行时新建记录。
+func (s *DataSyncService) runDependent(ctx context.Context, d jobs.Dependent) {
+	var count int
+	var err error
+	var job *syncRun
+	if d.ID != 0 {
+		job, err = s.startQueuedJob(ctx, d)
+	} else {
+		job, err = s.startJobAfter(ctx, d.ParentID, d.Type, d.Symbol, d.Exchange)
+	}
+	if err != nil {
+		return
+	}
+	defer func() { s.finishJob(job, count, err) }()
+	ctx = job.withTask(ctx)
+
+	switch d.Type {
+	case models.SyncJobIndicators:
+		count, err = s.recomputeIndicators(ctx, d.Symbol, d.Exchange, validation.DateRange{Start: d.Start, End: d.End})
+	case models.SyncJobFactors:
+		count, err = s.recomputeFactorScores(ctx, d.Start, d.End)
+	default:
+		err = fmt.Errorf("未知的下游任务 %s", d.Type)
+	}
+	if err != nil {
+		log.Printf("下游任务 %s（%s.%s）失败: %v", d.Type, d.Symbol, d.Exchange, err)
+	}
+}
+
+// ============ 后台同步任务 ============
+
+// runTask 在后台执行 HTTP 触发的同步，返回异步任务ID（登记失败时为空）
+// 任务的 context 与请求无关：客户端断开不影响执行，服务关闭或调用取消接口时取消；结果摘要写入任务的 message。
+func (s *DataSyncService) runTask(name string, fn func(ctx context.Context) (string, error)) string {
+	task := s.tasks.Start(context.Background(), jobs.Spec{Type: models.TaskTypeSync, Name: name})
+	s.running.Go(s.adminCtx, task.ID(), func(ctx context.Context) {
+		summary, err := fn(jobs.WithTask(ctx, task))
+		if err != nil {
+			log.Printf("后台同步任务 %s 失败: %v", name, err)
+			task.Fail(err, "", "")
+			return
+		}
+		task.SucceedWithMessage(summary, "", "")
+	})
+	return task.ID()
+}
+
+// reportSummary 逐只股票处理的同步结果摘要，用作后台任务的 message；未开始处理（report 为 nil）时为空
+func reportSummary(report *models.SyncReport, err error) (string, error) {
+	if report == nil {
+		return "", err
+	}
+	return report.Summary(), err
+}
+
+// stockStep 逐只股票处理前调用：任务被取消、服务关闭或主节点已切换时返回错误，否则上报进度
+func (s *DataSyncService) stockStep(ctx context.Context, i, total int, stock *models.Stock) error {
+	if err := s.checkFence(ctx); err != nil {
+		return err
+	}
+	jobs.FromContext(ctx).Progress(float64(i)*100/float64(total), stock.GetFullCode())
+	return nil
+}
+
+// throttle 两只股票之间的间隔，避免请求数据源过快；等待期间被取消时返回 context 错误
+func throttle(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// startSyncTask 提交后台同步并返回 202 与异步任务ID
+func (s *DataSyncService) startSyncTask(w http.ResponseWriter, name string, fn func(ctx context.Context) (string, error)) {
+	taskID := s.runTask(name, fn)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    0,
+		"message": "Sync task started",
+		"data":    map[string]interface{}{"task_id": taskID},
+	})
+}
+
+// handleSyncTask 后台任务状态 GET /api/v1/sync/tasks/{id} 与取消 POST /api/v1/sync/tasks/{id}/cancel
+// 全部成功或全部失败返回 200（以 status 区分），部分股票失败返回 207 Multi-Status。
+// 取消只对本实例运行中的任务有效，任务在处理完当前股票后结束并标记为 canceled。
+func (s *DataSyncService) handleSyncTask(w http.ResponseWriter, r *http.Request) {
+	id, action, _ := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/sync/tasks/"), "/"), "/")
+	switch {
+	case id == "":
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	case action == "" && r.Method != http.MethodGet, action == "cancel" && r.Method != http.MethodPost:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	case action != "" && action != "cancel":
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+
+	// 客户端在任务创建后立即轮询，任务与同步任务记录读主库
+	ctx := database.WithPrimary(r.Context())
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && task.Service != "data-service") {
+		http.Error(w, "task not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	message := ""
+	if action == "cancel" {
+		switch {
+		case task.Finished():
+			http.Error(w, "task already finished", http.StatusConflict)
+			return
+		case !s.running.Cancel(id):
+			// 任务在其他实例运行，或所在实例已退出
+			http.Error(w, "task is not running on this instance", http.StatusConflict)
+			return
+		}
+		message = "Cancel requested"
+	}
+
+	// 结果指向同步任务记录时一并返回逐只股票的结果，部分股票失败时返回 207
+	view := syncTaskView{Task: task}
+	status := http.StatusOK
+	if task.ResultType == "sync_job" {
+		if id, err := strconv.ParseUint(task.ResultID, 10, 64); err == nil {
+			if job, err := s.syncJobRepo.GetByID(ctx, uint(id)); err == nil {
+				view.SyncJob = job
+			}
+		}
+	}
+	if view.SyncJob != nil && view.SyncJob.Status == models.SyncStatusPartial {
+		status = http.StatusMultiStatus
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    0,
+		"message": message,
+		"data":    view,
+	})
+}
+
+// syncTaskView 后台任务状态及其产出的同步任务记录
+type syncTaskView struct {
+	*models.Task
+	SyncJob *models.SyncJob `json:"sync_job,omitempty"`
+}
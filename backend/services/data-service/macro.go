@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 宏观序列 ============
+
+// macroSyncDays 定时任务同步的回看天数：月度数据会被修订，每次重新同步最近一年
+const macroSyncDays = 366
+
+// SyncMacroSeries 同步全部宏观序列（CPI、PMI、LPR、M2）在日期范围内的数据，返回写入的数据点数
+// 单个序列失败不影响其他序列，全部完成后返回汇总的错误。
+func (s *DataSyncService) SyncMacroSeries(ctx context.Context, start, end time.Time) (count int, err error) {
+	job, err := s.startJob(ctx, models.SyncJobMacro, "", "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	var errs []error
+	for _, series := range models.MacroSeries {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		points, err := s.fetchMacroSeriesFromPython(ctx, series.Name, start, end)
+		if err != nil {
+			log.Printf("同步宏观序列 %s 失败: %v", series.Name, err)
+			errs = append(errs, fmt.Errorf("%s: %w", series.Name, err))
+			continue
+		}
+		if err := s.macroRepo.SavePoints(ctx, points); err != nil {
+			errs = append(errs, fmt.Errorf("保存 %s 失败: %w", series.Name, err))
+			continue
+		}
+		count += len(points)
+	}
+
+	log.Printf("宏观序列同步完成，共 %d 个数据点，失败 %d 个序列", count, len(errs))
+	return count, errors.Join(errs...)
+}
+
+// fetchMacroSeriesFromPython 从 Python 服务获取宏观序列，跳过日期无效的数据点
+func (s *DataSyncService) fetchMacroSeriesFromPython(ctx context.Context, name string, start, end time.Time) ([]*models.MacroPoint, error) {
+	url := fmt.Sprintf("%s/api/v1/macro/%s?start=%s&end=%s",
+		s.pythonAPIURL, name, start.Format("20060102"), end.Format("20060102"))
+
+	var result struct {
+		Code int `json:"code"`
+		Data []struct {
+			Date  string  `json:"date"` // YYYY-MM-DD，月度数据为统计月份第一天，LPR 为报价日
+			Value float64 `json:"value"`
+		} `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	points := make([]*models.MacroPoint, 0, len(result.Data))
+	for _, item := range result.Data {
+		date, err := time.Parse("2006-01-02", item.Date)
+		if err != nil {
+			continue
+		}
+		points = append(points, &models.MacroPoint{Series: name, Date: date, Value: item.Value})
+	}
+	return points, nil
+}
+
+// loadMacroSeries 加载截至 date 的全部宏观序列供因子计算，没有数据的序列不返回
+func (s *DataSyncService) loadMacroSeries(ctx context.Context, start, date time.Time) (map[string][]*models.MacroPoint, error) {
+	macro := make(map[string][]*models.MacroPoint)
+	for _, series := range models.MacroSeries {
+		// 多取一个月，保证区间第一个月也有已公布的值
+		points, err := s.macroRepo.GetSeries(ctx, series.Name, start.AddDate(0, -2, 0), date)
+		if err != nil {
+			return nil, err
+		}
+		if len(points) > 0 {
+			macro[series.Name] = points
+		}
+	}
+	return macro, nil
+}
@@ -7,50 +7,168 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/alert"
+	"stock-analysis-system/backend/pkg/auth"
+	"stock-analysis-system/backend/pkg/cache"
+	"stock-analysis-system/backend/pkg/calendar"
 	"stock-analysis-system/backend/pkg/config"
 	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/lock"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/middleware"
 	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/notify"
+	"stock-analysis-system/backend/pkg/quality"
 	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/server"
 )
 
 // DataSyncService 数据同步服务
 type DataSyncService struct {
-	cfg            *config.Config
-	dbManager      *database.Manager
-	stockRepo      repository.StockRepository
-	marketRepo     repository.MarketRepository
-	httpClient     *http.Client
-	pythonAPIURL   string
+	cfg             *config.Config
+	dbManager       *database.Manager
+	live            *config.Live
+	stockRepo       repository.StockRepository
+	marketRepo      repository.MarketRepository
+	dragonTigerRepo repository.DragonTigerRepository
+	newsRepo        repository.NewsRepository
+	syncJobRepo     repository.SyncJobRepository
+	tasks           *jobs.Tracker // 同步任务同时登记为异步任务，供 /api/v1/tasks 统一查询
+	taskRepo        repository.TaskRepository
+	running         *jobs.Registry // 本实例后台运行的同步与运维任务，供取消接口使用
+	dependents      *jobs.Queue    // 日K线写入后的指标回补等下游任务，在后台逐个执行
+	locker          *lock.Locker   // 多实例部署时选举定时任务主节点
+	calendar        *calendar.Calendar
+	leader          *lock.Leader // StartScheduler 后有效
+	factorRepo      repository.FactorRepository
+	universeRepo    repository.UniverseRepository
+	basketRepo      repository.BasketRepository
+	httpClient      *http.Client
+	pythonAPIURL    string
+	dataSource      string
+	newsFeeds       []string
+	snapshots       snapshotExporter
+
+	// 管理员数据运维
+	keys          *auth.KeySet
+	userRepo      repository.UserRepository
+	auditRepo     repository.AuditRepository
+	quality       *quality.DataQualityChecker
+	qualityState  qualityReportState
+	alerts        *alert.Monitor                    // 数据管道告警
+	alertRuleRepo repository.AlertRuleRepository    // 用户提醒规则，增量更新后检查
+	calendarRepo  repository.CalendarRepository     // 公司事件日历，供同步与事件类提醒规则使用
+	macroRepo     repository.MacroRepository        // 宏观序列（CPI、PMI、LPR、M2）
+	connectRepo   repository.StockConnectRepository // 沪深港通资金与北向持股
+	backtestRepo  repository.BacktestRepository     // K线修正后标记过期的回测
+	marketCache   *cache.Cache                      // 行情服务的缓存，K线修正后删除受影响的键；未配置 Redis 时为 nil
+	adminCtx      context.Context                   // 后台同步与运维任务的 context，Close 时取消
+	cancelAdmin   context.CancelFunc
 }
 
 // NewDataSyncService 创建数据同步服务
 func NewDataSyncService(cfg *config.Config) (*DataSyncService, error) {
+	// 管理员接口需要校验登录 Token
+	keys, err := auth.LoadKeySet(cfg.Auth, cfg.Server.Mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := markettime.Configure(cfg.Calendar); err != nil {
+		return nil, err
+	}
+	tradingCalendar, err := calendar.New(cfg.Calendar)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建数据库管理器
 	dbManager, err := database.NewManager(&cfg.Database)
 	if err != nil {
 		return nil, fmt.Errorf("初始化数据库管理器失败: %w", err)
 	}
 
+	// 配置文件热更新
+	live, err := config.NewLive(cfg)
+	if err != nil {
+		dbManager.Close()
+		return nil, err
+	}
+	live.OnChange(func(c *config.Config) { dbManager.SetLogLevel(c.Log.Level) })
+
 	// 创建仓库
 	stockRepo := repository.NewStockRepository(dbManager.Postgres.DB)
 	marketRepo := repository.NewMarketRepository(dbManager.Influx)
+	dragonTigerRepo := repository.NewDragonTigerRepository(dbManager.Postgres.DB)
+	newsRepo := repository.NewNewsRepository(dbManager.Postgres.DB)
+	syncJobRepo := repository.NewSyncJobRepository(dbManager.Postgres.DB)
+	factorRepo := repository.NewFactorRepository(dbManager.Postgres.DB)
+	universeRepo := repository.NewUniverseRepository(dbManager.Postgres.DB)
+	userRepo := repository.NewUserRepository(dbManager.Postgres.DB)
+	auditRepo := repository.NewAuditRepository(dbManager.Postgres.DB)
+	taskRepo := repository.NewTaskRepository(dbManager.Postgres.DB)
+
+	// RSS 新闻源，多个以逗号分隔
+	var newsFeeds []string
+	for _, feed := range strings.Split(getEnv("NEWS_RSS_FEEDS", ""), ",") {
+		if feed = strings.TrimSpace(feed); feed != "" {
+			newsFeeds = append(newsFeeds, feed)
+		}
+	}
 
-	return &DataSyncService{
-		cfg:          cfg,
-		dbManager:    dbManager,
-		stockRepo:    stockRepo,
-		marketRepo:   marketRepo,
-		httpClient:   &http.Client{Timeout: 30 * time.Second},
-		pythonAPIURL: getEnv("PYTHON_API_URL", "http://localhost:5000"),
-	}, nil
+	adminCtx, cancelAdmin := context.WithCancel(context.Background())
+	s := &DataSyncService{
+		cfg:             cfg,
+		dbManager:       dbManager,
+		live:            live,
+		stockRepo:       stockRepo,
+		marketRepo:      marketRepo,
+		dragonTigerRepo: dragonTigerRepo,
+		newsRepo:        newsRepo,
+		syncJobRepo:     syncJobRepo,
+		tasks:           jobs.NewTracker(taskRepo, "data-service"),
+		taskRepo:        taskRepo,
+		running:         jobs.NewRegistry(),
+		locker:          lock.New(dbManager.Redis.GetClient(), "data-service"),
+		calendar:        tradingCalendar,
+		factorRepo:      factorRepo,
+		universeRepo:    universeRepo,
+		basketRepo:      repository.NewBasketRepository(dbManager.Postgres.DB),
+		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		pythonAPIURL:    getEnv("PYTHON_API_URL", "http://localhost:5000"),
+		dataSource:      getEnv("DATA_SOURCE_NAME", "akshare"),
+		newsFeeds:       newsFeeds,
+		keys:            keys,
+		userRepo:        userRepo,
+		auditRepo:       auditRepo,
+		quality:         quality.NewDataQualityChecker(stockRepo, marketRepo),
+		alerts:          alert.NewMonitor(notify.FromConfig(&cfg.Notify), cfg.Alert),
+		alertRuleRepo:   repository.NewAlertRuleRepository(dbManager.Postgres.DB),
+		calendarRepo:    repository.NewCalendarRepository(dbManager.Postgres.DB),
+		macroRepo:       repository.NewMacroRepository(dbManager.Influx),
+		connectRepo:     repository.NewStockConnectRepository(dbManager.Influx),
+		backtestRepo:    repository.NewBacktestRepository(dbManager.Postgres.DB),
+		marketCache:     cache.New(dbManager.Redis.GetClient(), cache.MarketPrefix),
+		adminCtx:        adminCtx,
+		cancelAdmin:     cancelAdmin,
+	}
+	s.dependents = jobs.NewQueue(s.runDependent, dependentStore{repo: syncJobRepo, source: s.dataSource})
+	s.restoreDependents(adminCtx)
+	go s.dependents.Run(adminCtx)
+	return s, nil
 }
 
 // Close 关闭服务
 func (s *DataSyncService) Close() {
+	s.cancelAdmin()
+	s.live.Close()
 	if s.dbManager != nil {
 		s.dbManager.Close()
 	}
@@ -59,9 +177,16 @@ func (s *DataSyncService) Close() {
 // ============ 股票列表同步 ============
 
 // SyncStockList 同步股票列表
-func (s *DataSyncService) SyncStockList(ctx context.Context) error {
+func (s *DataSyncService) SyncStockList(ctx context.Context) (err error) {
 	log.Println("开始同步股票列表...")
 
+	var records int
+	job, err := s.startJob(ctx, models.SyncJobStockList, "", "")
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, records, err) }()
+
 	// 调用 Python 数据采集服务获取股票列表
 	stocks, err := s.fetchStockListFromPython(ctx)
 	if err != nil {
@@ -70,6 +195,11 @@ func (s *DataSyncService) SyncStockList(ctx context.Context) error {
 
 	log.Printf("从 Python 服务获取到 %d 只股票", len(stocks))
 
+	// 按简称识别 ST/*ST 状态变化，保存前写入股票当前状态
+	if _, err := s.UpdateRiskWarningsFromNames(ctx, stocks, time.Now()); err != nil {
+		log.Printf("更新风险警示失败: %v", err)
+	}
+
 	// 批量保存到 PostgreSQL
 	batchSize := 100
 	for i := 0; i < len(stocks); i += batchSize {
@@ -85,6 +215,7 @@ func (s *DataSyncService) SyncStockList(ctx context.Context) error {
 		}
 	}
 
+	records = len(stocks)
 	log.Printf("股票列表同步完成，共 %d 只", len(stocks))
 	return nil
 }
@@ -128,56 +259,109 @@ func (s *DataSyncService) fetchStockListFromPython(ctx context.Context) ([]*mode
 
 // ============ K线数据同步 ============
 
-// SyncDailyBars 同步日K线数据
-func (s *DataSyncService) SyncDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time) error {
+// SyncDailyBars 同步日K线数据，返回写入的K线条数
+func (s *DataSyncService) SyncDailyBars(ctx context.Context, symbol, exchange string, start, end time.Time) (records int, err error) {
 	log.Printf("开始同步 %s.%s 的日K线数据 (%s ~ %s)", symbol, exchange, start.Format("2006-01-02"), end.Format("2006-01-02"))
 
+	job, err := s.startJob(ctx, models.SyncJobDailyBars, symbol, exchange)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, records, err) }()
+
 	// 从 Python 服务获取K线数据
 	bars, err := s.fetchDailyBarsFromPython(ctx, symbol, exchange, start, end)
 	if err != nil {
-		return fmt.Errorf("从 Python 服务获取K线数据失败: %w", err)
+		return 0, fmt.Errorf("从 Python 服务获取K线数据失败: %w", err)
 	}
 
 	if len(bars) == 0 {
 		log.Printf("未获取到 %s.%s 的K线数据", symbol, exchange)
-		return nil
+		return 0, nil
 	}
 
 	log.Printf("获取到 %d 条K线数据", len(bars))
 
-	// 保存到 InfluxDB
+	// 保存到 InfluxDB；拉取期间主节点可能已切换
+	if err := s.checkFence(ctx); err != nil {
+		return 0, err
+	}
 	if err := s.marketRepo.SaveDailyBars(ctx, bars); err != nil {
-		return fmt.Errorf("保存K线数据失败: %w", err)
+		return 0, fmt.Errorf("保存K线数据失败: %w", err)
 	}
 
+	records = len(bars)
 	log.Printf("%s.%s 的日K线数据同步完成", symbol, exchange)
-	return nil
+
+	// 日K线写入后登记技术指标回补，在后台执行，不占用同步本身的时间；失败只记录在指标任务中
+	s.enqueueIndicators(job, symbol, exchange, bars)
+	return records, nil
+}
+
+// enqueueIndicators 登记依赖于日K线同步任务的 indicators 下游任务，重算写入的K线影响的区间（见 indicator.AffectedRange）
+// 全市场同步与定时增量更新中同一股票尚未执行的回补会合并为一次。
+func (s *DataSyncService) enqueueIndicators(upstream *syncRun, symbol, exchange string, bars []*models.DailyBar) {
+	first, last := bars[0].Date, bars[0].Date
+	for _, bar := range bars[1:] {
+		if bar.Date.Before(first) {
+			first = bar.Date
+		}
+		if bar.Date.After(last) {
+			last = bar.Date
+		}
+	}
+	start, end, ok := indicator.AffectedRange(first, last, time.Now())
+	if !ok {
+		return
+	}
+	d := jobs.Dependent{Type: models.SyncJobIndicators, Symbol: symbol, Exchange: exchange, Start: start, End: end}
+	if upstream.job != nil {
+		d.ParentID = &upstream.job.ID
+	}
+	s.dependents.Enqueue(d)
 }
 
-// SyncDailyBarsForAllStocks 为所有股票同步日K线数据
-func (s *DataSyncService) SyncDailyBarsForAllStocks(ctx context.Context, start, end time.Time) error {
+// SyncDailyBarsForAllStocks 为所有股票同步日K线数据，返回逐只股票的结果
+// 记录一条全市场 daily_bars 同步任务；全部股票失败时返回错误，部分失败时任务状态为 partial。
+func (s *DataSyncService) SyncDailyBarsForAllStocks(ctx context.Context, start, end time.Time) (report *models.SyncReport, err error) {
+	job, err := s.startJob(ctx, models.SyncJobDailyBars, "", "")
+	if err != nil {
+		return nil, err
+	}
+	ctx = job.withTask(ctx)
+	defer func() { s.finishReport(job, report, err) }()
+
 	// 获取所有活跃股票
 	stocks, err := s.stockRepo.GetActiveStocks(ctx)
 	if err != nil {
-		return fmt.Errorf("获取股票列表失败: %w", err)
+		return nil, fmt.Errorf("获取股票列表失败: %w", err)
 	}
 
 	log.Printf("开始为 %d 只股票同步日K线数据", len(stocks))
+	report = models.NewSyncReport(len(stocks))
 
 	for i, stock := range stocks {
+		if err := s.stockStep(ctx, i, len(stocks), stock); err != nil {
+			return report, err
+		}
 		log.Printf("[%d/%d] 同步 %s.%s...", i+1, len(stocks), stock.Symbol, stock.Exchange)
-		
-		if err := s.SyncDailyBars(ctx, stock.Symbol, stock.Exchange, start, end); err != nil {
+
+		records, err := s.SyncDailyBars(ctx, stock.Symbol, stock.Exchange, start, end)
+		if err != nil {
 			log.Printf("同步 %s.%s 失败: %v", stock.Symbol, stock.Exchange, err)
+			report.Fail(stock.Symbol, stock.Exchange, err)
 			continue
 		}
+		report.Succeed(stock.Symbol, stock.Exchange, records)
 
 		// 避免请求过快
-		time.Sleep(500 * time.Millisecond)
+		if err := throttle(ctx, 500*time.Millisecond); err != nil {
+			return report, err
+		}
 	}
 
-	log.Println("所有股票日K线数据同步完成")
-	return nil
+	log.Printf("所有股票日K线数据同步完成，%s", report.Summary())
+	return report, report.Err()
 }
 
 // fetchDailyBarsFromPython 从 Python 服务获取日K线数据
@@ -220,53 +404,92 @@ func (s *DataSyncService) fetchDailyBarsFromPython(ctx context.Context, symbol,
 
 // ============ 增量更新 ============
 
-// IncrementalUpdate 执行增量更新
-func (s *DataSyncService) IncrementalUpdate(ctx context.Context) error {
+// IncrementalUpdate 执行增量更新，返回逐只股票的结果
+// 记录一条 incremental 同步任务；全部股票失败时返回错误，部分失败时任务状态为 partial。
+// 超过半数股票同步失败时视为一次失败，连续失败达到阈值时通知运维。
+func (s *DataSyncService) IncrementalUpdate(ctx context.Context) (report *models.SyncReport, err error) {
 	log.Println("开始执行增量更新...")
+	job, err := s.startJob(ctx, models.SyncJobIncremental, "", "")
+	if err != nil {
+		return nil, err
+	}
+	ctx = job.withTask(ctx)
+	defer func() {
+		s.finishReport(job, report, err)
+		if ctx.Err() != nil {
+			return
+		}
+		alertErr := err
+		if alertErr == nil && len(report.Failed)*2 > report.Total {
+			alertErr = fmt.Errorf("增量更新 %d/%d 只股票失败", len(report.Failed), report.Total)
+		}
+		s.alerts.SyncResult(ctx, alertErr)
+	}()
 
 	// 获取所有活跃股票
 	stocks, err := s.stockRepo.GetActiveStocks(ctx)
 	if err != nil {
-		return fmt.Errorf("获取股票列表失败: %w", err)
+		return nil, fmt.Errorf("获取股票列表失败: %w", err)
 	}
 
 	end := time.Now()
-	start := end.AddDate(0, 0, -7) // 更新最近7天的数据
+	report = models.NewSyncReport(len(stocks))
+
+	for i, stock := range stocks {
+		// 任务被取消或服务关闭时在两只股票之间停止，已同步的数据保留
+		if err := s.stockStep(ctx, i, len(stocks), stock); err != nil {
+			log.Printf("增量更新已取消，完成 %d/%d 只股票", i, len(stocks))
+			return report, err
+		}
 
-	for _, stock := range stocks {
 		// 查询该股票最新的数据日期
 		latestBar, err := s.marketRepo.GetLatestDailyBar(ctx, stock.Symbol, stock.Exchange)
 		if err != nil {
 			log.Printf("获取 %s.%s 最新数据失败: %v", stock.Symbol, stock.Exchange, err)
+			report.Fail(stock.Symbol, stock.Exchange, err)
 			continue
 		}
 
+		// 没有历史数据时同步最近30天，否则从最新数据日期的下一天开始更新
+		updateStart := end.AddDate(0, 0, -30)
 		if latestBar != nil {
-			// 从最新数据日期的下一天开始更新
-			updateStart := latestBar.Date.AddDate(0, 0, 1)
-			if updateStart.Before(end) {
-				if err := s.SyncDailyBars(ctx, stock.Symbol, stock.Exchange, updateStart, end); err != nil {
-					log.Printf("增量更新 %s.%s 失败: %v", stock.Symbol, stock.Exchange, err)
-				}
-			}
-		} else {
-			// 没有历史数据，同步最近30天
-			updateStart := end.AddDate(0, 0, -30)
-			if err := s.SyncDailyBars(ctx, stock.Symbol, stock.Exchange, updateStart, end); err != nil {
-				log.Printf("同步 %s.%s 历史数据失败: %v", stock.Symbol, stock.Exchange, err)
-			}
+			updateStart = latestBar.Date.AddDate(0, 0, 1)
+		}
+		if !updateStart.Before(end) {
+			report.Succeed(stock.Symbol, stock.Exchange, 0)
+			continue
+		}
+		records, err := s.SyncDailyBars(ctx, stock.Symbol, stock.Exchange, updateStart, end)
+		if err != nil {
+			log.Printf("增量更新 %s.%s 失败: %v", stock.Symbol, stock.Exchange, err)
+			report.Fail(stock.Symbol, stock.Exchange, err)
+			continue
 		}
+		report.Succeed(stock.Symbol, stock.Exchange, records)
 	}
 
-	log.Println("增量更新完成")
-	return nil
+	log.Printf("增量更新完成，%s", report.Summary())
+
+	// 日K线更新后增量检查用户提醒规则，失败不影响同步结果
+	if _, err := s.EvaluateAlertRules(ctx); err != nil {
+		log.Printf("检查提醒规则失败: %v", err)
+	}
+	return report, report.Err()
 }
 
 // ============ 定时任务 ============
 
+// schedulerLeaseTTL 定时任务主节点锁的有效期，主节点异常退出后其他实例最迟在此时间后接替
+const schedulerLeaseTTL = 30 * time.Second
+
 // StartScheduler 启动定时任务
+// 多实例部署时通过 Redis 选举主节点，只有主节点执行；每个整点时段只执行一次，主节点切换后新主节点不会重复执行同一时段。
 func (s *DataSyncService) StartScheduler(ctx context.Context) {
 	log.Println("启动数据同步定时任务...")
+	s.leader = s.locker.Elect(ctx, "data-service:scheduler", schedulerLeaseTTL)
+
+	// 交易时段内定期同步最新分钟K线
+	s.startIntradaySync(ctx)
 
 	// 每天凌晨 2:00 执行增量更新
 	go func() {
@@ -278,12 +501,77 @@ func (s *DataSyncService) StartScheduler(ctx context.Context) {
 			case <-ctx.Done():
 				return
 			case now := <-ticker.C:
-				// 检查是否是凌晨 2:00
-				if now.Hour() == 2 {
-					if err := s.IncrementalUpdate(ctx); err != nil {
-						log.Printf("定时增量更新失败: %v", err)
+				// 定时任务按交易所时区（默认北京时间）判断时刻与日期，与服务器时区无关
+				now = now.In(markettime.Location(""))
+				s.leader.RunOnce("hourly", now.Format("2006010215"), 2*time.Hour, func(ctx context.Context, token int64) {
+					log.Printf("执行定时任务（%s，主节点令牌 %d）", now.Format("2006-01-02 15:00"), token)
+					// 同步任务记录带上令牌，主节点切换后旧主节点的迟到写入被拒绝
+					ctx = lock.WithFence(ctx, token)
+					// 每小时同步一次新闻公告
+					if _, err := s.SyncNews(ctx, now.Add(-2*time.Hour)); err != nil {
+						log.Printf("定时同步新闻失败: %v", err)
 					}
-				}
+
+					// 检查是否是凌晨 2:00
+					if now.Hour() == 2 {
+						// 先同步未来的公司事件，增量更新后的提醒规则检查会用到
+						if err := s.SyncCorporateEvents(ctx, now, now.AddDate(0, 0, calendarSyncDays)); err != nil {
+							log.Printf("定时同步公司事件失败: %v", err)
+						}
+						if _, err := s.IncrementalUpdate(ctx); err != nil {
+							log.Printf("定时增量更新失败: %v", err)
+						}
+						// 同步上市日期与股本，供市值计算与因子使用
+						if _, err := s.SyncStockMetadata(ctx); err != nil {
+							log.Printf("定时同步股票资料失败: %v", err)
+						}
+						// 同步宏观序列，月度数据会被修订，每次同步最近一年
+						if _, err := s.SyncMacroSeries(ctx, now.AddDate(0, 0, -macroSyncDays), now); err != nil {
+							log.Printf("定时同步宏观序列失败: %v", err)
+						}
+						// 同步上一交易日龙虎榜
+						if err := s.SyncDragonTiger(ctx, now.AddDate(0, 0, -1)); err != nil {
+							log.Printf("定时同步龙虎榜失败: %v", err)
+						}
+						// 同步上一交易日沪深港通资金与北向持股
+						if yesterday := now.AddDate(0, 0, -1); s.calendar.IsTradingDay(yesterday) {
+							if _, err := s.SyncStockConnect(ctx, yesterday); err != nil {
+								log.Printf("定时同步沪深港通资金失败: %v", err)
+							}
+						}
+						// 每周日同步财报（按季度披露，无需每日更新）
+						if now.Weekday() == time.Sunday {
+							if _, err := s.SyncFinancialReportsForAllStocks(ctx); err != nil {
+								log.Printf("定时同步财报失败: %v", err)
+							}
+						}
+						// 每周六清理最近 30 天重复同步产生的重复日K线
+						if now.Weekday() == time.Saturday {
+							if _, err := s.DedupeDailyBars(ctx, "", "", now.AddDate(0, 0, -30), now, false); err != nil {
+								log.Printf("定时清理重复K线失败: %v", err)
+							}
+						}
+						// 行情更新与重复清理后重新计算数据质量评分
+						if _, err := s.ScoreStockQuality(ctx); err != nil {
+							log.Printf("定时计算数据质量评分失败: %v", err)
+						}
+						// 行情更新后计算上一交易日因子得分
+						if _, err := s.ComputeFactorScores(ctx, now.AddDate(0, 0, -1)); err != nil {
+							log.Printf("定时计算因子得分失败: %v", err)
+						}
+						// 保存上一交易日股票池成分快照
+						if _, err := s.SnapshotUniverses(ctx, now.AddDate(0, 0, -1)); err != nil {
+							log.Printf("定时保存股票池快照失败: %v", err)
+						}
+						// 行情更新后计算自定义篮子的每日点位
+						if _, err := s.UpdateBasketValues(ctx, now); err != nil {
+							log.Printf("定时计算篮子点位失败: %v", err)
+						}
+					}
+
+					// 导出上一自然日的数据快照（默认凌晨 3:00，错开增量更新）
+					s.scheduledSnapshot(ctx, now)
+				})
 			}
 		}
 	}()
@@ -291,18 +579,10 @@ func (s *DataSyncService) StartScheduler(ctx context.Context) {
 
 // ============ HTTP API ============
 
-// StartHTTPServer 启动 HTTP 服务
-func (s *DataSyncService) StartHTTPServer(port string) error {
+// RegisterRoutes 注册同步接口
+// 同步、快照与数据新鲜度接口需 admin 角色或 DATA_API_KEYS 中的 API Key，经网关 /api/v1/data 访问时路径改写为 /api/v1/sync、/api/v1/snapshots、/api/v1/freshness。
+func (s *DataSyncService) RegisterRoutes(router *gin.Engine) {
 	mux := http.NewServeMux()
-	
-	// 健康检查
-	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"status": "ok",
-			"time":   time.Now().Format(time.RFC3339),
-		})
-	})
 
 	// 同步股票列表
 	mux.HandleFunc("/api/v1/sync/stocks", func(w http.ResponseWriter, r *http.Request) {
@@ -311,16 +591,21 @@ func (s *DataSyncService) StartHTTPServer(port string) error {
 			return
 		}
 
-		ctx := r.Context()
-		if err := s.SyncStockList(ctx); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.startSyncTask(w, models.SyncJobStockList, func(ctx context.Context) (string, error) {
+			return "", s.SyncStockList(ctx)
+		})
+	})
+
+	// 同步股票资料（上市日期、总股本、流通股本）
+	mux.HandleFunc("/api/v1/sync/stock-metadata", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"code":    0,
-			"message": "Stock list synced successfully",
+		s.startSyncTask(w, models.SyncJobStockMeta, func(ctx context.Context) (string, error) {
+			count, err := s.SyncStockMetadata(ctx)
+			return fmt.Sprintf("更新 %d 只股票的资料", count), err
 		})
 	})
 
@@ -346,41 +631,359 @@ func (s *DataSyncService) StartHTTPServer(port string) error {
 		start, _ := time.Parse("2006-01-02", req.Start)
 		end, _ := time.Parse("2006-01-02", req.End)
 
-		ctx := r.Context()
-		if err := s.SyncDailyBars(ctx, req.Symbol, req.Exchange, start, end); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		s.startSyncTask(w, models.SyncJobDailyBars, func(ctx context.Context) (string, error) {
+			records, err := s.SyncDailyBars(ctx, req.Symbol, req.Exchange, start, end)
+			return fmt.Sprintf("写入 %d 条K线", records), err
+		})
+	})
+
+	// 同步个股资金流向
+	mux.HandleFunc("/api/v1/sync/moneyflow", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"code":    0,
-			"message": "Bars synced successfully",
+		var req struct {
+			Symbol   string `json:"symbol"`
+			Exchange string `json:"exchange"`
+			Start    string `json:"start"`
+			End      string `json:"end"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		start, err := time.Parse("2006-01-02", req.Start)
+		if err != nil {
+			http.Error(w, "invalid start date", http.StatusBadRequest)
+			return
+		}
+		end, err := time.Parse("2006-01-02", req.End)
+		if err != nil {
+			http.Error(w, "invalid end date", http.StatusBadRequest)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobMoneyFlow, func(ctx context.Context) (string, error) {
+			return "", s.SyncMoneyFlow(ctx, req.Symbol, req.Exchange, start, end)
 		})
 	})
 
-	// 执行增量更新
-	mux.HandleFunc("/api/v1/sync/incremental", func(w http.ResponseWriter, r *http.Request) {
+	// 同步龙虎榜
+	mux.HandleFunc("/api/v1/sync/dragon-tiger", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 			return
 		}
 
-		ctx := r.Context()
-		if err := s.IncrementalUpdate(ctx); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		var req struct {
+			Date string `json:"date"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobDragonTiger, func(ctx context.Context) (string, error) {
+			return "", s.SyncDragonTiger(ctx, date)
+		})
+	})
+
+	// 同步公司事件日历
+	mux.HandleFunc("/api/v1/sync/calendar", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 默认从今天起同步未来 90 天
+		start := time.Now()
+		if req.Start != "" {
+			t, err := time.Parse("2006-01-02", req.Start)
+			if err != nil {
+				http.Error(w, "invalid start date", http.StatusBadRequest)
+				return
+			}
+			start = t
+		}
+		end := start.AddDate(0, 0, calendarSyncDays)
+		if req.End != "" {
+			t, err := time.Parse("2006-01-02", req.End)
+			if err != nil {
+				http.Error(w, "invalid end date", http.StatusBadRequest)
+				return
+			}
+			end = t
+		}
+		if end.Before(start) {
+			http.Error(w, "end must not be before start", http.StatusBadRequest)
 			return
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"code":    0,
-			"message": "Incremental update completed",
+		s.startSyncTask(w, models.SyncJobCalendar, func(ctx context.Context) (string, error) {
+			return "", s.SyncCorporateEvents(ctx, start, end)
 		})
 	})
 
-	log.Printf("数据同步服务启动在端口 %s", port)
-	return http.ListenAndServe(":"+port, mux)
+	// 同步沪深港通资金与北向持股
+	mux.HandleFunc("/api/v1/sync/stock-connect", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Date string `json:"date"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			http.Error(w, "invalid date", http.StatusBadRequest)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobStockConnect, func(ctx context.Context) (string, error) {
+			count, err := s.SyncStockConnect(ctx, date)
+			return fmt.Sprintf("写入 %d 条记录", count), err
+		})
+	})
+
+	// 同步宏观序列
+	mux.HandleFunc("/api/v1/sync/macro", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// 默认同步最近一年
+		end := time.Now()
+		if req.End != "" {
+			t, err := time.Parse("2006-01-02", req.End)
+			if err != nil {
+				http.Error(w, "invalid end date", http.StatusBadRequest)
+				return
+			}
+			end = t
+		}
+		start := end.AddDate(0, 0, -macroSyncDays)
+		if req.Start != "" {
+			t, err := time.Parse("2006-01-02", req.Start)
+			if err != nil {
+				http.Error(w, "invalid start date", http.StatusBadRequest)
+				return
+			}
+			start = t
+		}
+		if end.Before(start) {
+			http.Error(w, "end must not be before start", http.StatusBadRequest)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobMacro, func(ctx context.Context) (string, error) {
+			count, err := s.SyncMacroSeries(ctx, start, end)
+			return fmt.Sprintf("写入 %d 个数据点", count), err
+		})
+	})
+
+	// 同步新闻公告
+	mux.HandleFunc("/api/v1/sync/news", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		// 默认同步最近24小时
+		since := time.Now().Add(-24 * time.Hour)
+		if v := r.URL.Query().Get("since"); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				http.Error(w, "invalid since date", http.StatusBadRequest)
+				return
+			}
+			since = t
+		}
+
+		s.startSyncTask(w, models.SyncJobNews, func(ctx context.Context) (string, error) {
+			created, err := s.SyncNews(ctx, since)
+			return fmt.Sprintf("新增 %d 条新闻公告", created), err
+		})
+	})
+
+	// 同步财报，未指定股票时同步全部活跃股票
+	mux.HandleFunc("/api/v1/sync/financials", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req struct {
+			Symbol   string `json:"symbol"`
+			Exchange string `json:"exchange"`
+		}
+
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		s.startSyncTask(w, models.SyncJobFinancials, func(ctx context.Context) (string, error) {
+			if req.Symbol != "" {
+				count, err := s.SyncFinancialReports(ctx, req.Symbol, req.Exchange)
+				return fmt.Sprintf("写入 %d 期财报", count), err
+			}
+			return reportSummary(s.SyncFinancialReportsForAllStocks(ctx))
+		})
+	})
+
+	// 计算因子得分，默认上一自然日
+	mux.HandleFunc("/api/v1/sync/factors", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		date := markettime.Now("").AddDate(0, 0, -1)
+		if v := r.URL.Query().Get("date"); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				http.Error(w, "invalid date", http.StatusBadRequest)
+				return
+			}
+			date = t
+		}
+
+		s.startSyncTask(w, models.SyncJobFactors, func(ctx context.Context) (string, error) {
+			count, err := s.ComputeFactorScores(ctx, date)
+			return fmt.Sprintf("计算 %d 只股票的因子得分", count), err
+		})
+	})
+
+	// 同步风险警示（ST/*ST）历史
+	mux.HandleFunc("/api/v1/sync/risk-warnings", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobRiskWarnings, func(ctx context.Context) (string, error) {
+			return "", s.SyncRiskWarningHistory(ctx)
+		})
+	})
+
+	// 保存股票池成分快照，默认上一自然日；指定 start/end 时按工作日回补
+	mux.HandleFunc("/api/v1/sync/universes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		var universeID uint
+		if v := query.Get("universe_id"); v != "" {
+			id, err := strconv.ParseUint(v, 10, 32)
+			if err != nil {
+				http.Error(w, "invalid universe_id", http.StatusBadRequest)
+				return
+			}
+			universeID = uint(id)
+		}
+
+		dates, err := snapshotDates(query.Get("date"), query.Get("start"), query.Get("end"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobUniverses, func(ctx context.Context) (string, error) {
+			task := jobs.FromContext(ctx)
+			total := 0
+			for i, date := range dates {
+				var count int
+				var err error
+				if universeID != 0 {
+					count, err = s.SnapshotUniverseByID(ctx, universeID, date)
+				} else {
+					count, err = s.SnapshotUniverses(ctx, date)
+				}
+				if err != nil {
+					return "", err
+				}
+				total += count
+				task.Progress(float64(i+1)*100/float64(len(dates)), date.Format(markettime.DateLayout))
+			}
+			return fmt.Sprintf("保存 %d 个交易日、%d 条成分", len(dates), total), nil
+		})
+	})
+
+	// 批量导入历史K线
+	mux.HandleFunc("/api/v1/sync/import/bars", s.handleImportBars)
+
+	// 导入离线K线文件（通达信/tushare 导出）
+	mux.HandleFunc("/api/v1/sync/import", s.handleImportFiles)
+
+	// 执行增量更新
+	mux.HandleFunc("/api/v1/sync/incremental", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		s.startSyncTask(w, models.SyncJobIncremental, func(ctx context.Context) (string, error) {
+			return reportSummary(s.IncrementalUpdate(ctx))
+		})
+	})
+
+	// 后台同步任务：查询状态、取消
+	mux.HandleFunc("/api/v1/sync/tasks/", s.handleSyncTask)
+
+	// 数据快照：列表/手动导出、清单、文件下载
+	mux.HandleFunc("/api/v1/snapshots", s.handleSnapshots)
+	mux.HandleFunc("/api/v1/snapshots/", s.handleSnapshot)
+
+	ops := router.Group("/api/v1", middleware.RoleOrAPIKey(s.keys, s.userRepo, models.RoleAdmin, s.cfg.Auth.APIKeys))
+	ops.Any("/sync/*path", gin.WrapH(mux))
+	ops.Any("/snapshots", gin.WrapH(mux))
+	ops.Any("/snapshots/*path", gin.WrapH(mux))
+	ops.GET("/freshness", s.GetDataFreshness)
+
+	s.RegisterAdminRoutes(router)
 }
 
 // ============ 主函数 ============
@@ -394,28 +997,28 @@ func main() {
 	if err != nil {
 		log.Fatalf("创建数据同步服务失败: %v", err)
 	}
-	defer service.Close()
-
-	// 创建上下文
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
 
 	// 启动定时任务
+	ctx, cancel := context.WithCancel(context.Background())
 	service.StartScheduler(ctx)
 
-	// 启动 HTTP 服务
 	port := getEnv("DATA_SERVICE_PORT", "8081")
-	
-	// 优雅退出
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-		log.Println("正在关闭服务...")
-		cancel()
-	}()
 
-	if err := service.StartHTTPServer(port); err != nil {
+	srv := server.New("data-service", cfg,
+		server.WithPort(port),
+		server.WithWriteTimeout(10*time.Minute), // 同步接口同步执行，全量同步耗时较长
+		server.WithMaxBodySize(maxImportBodyBytes),
+		server.WithDatabaseHealth(service.dbManager),
+		server.WithShutdownHook(func(context.Context) { service.Close() }),
+		// 先停止定时任务并释放主节点锁，再关闭 Redis 连接
+		server.WithShutdownHook(func(context.Context) {
+			cancel()
+			service.leader.Wait()
+		}),
+	)
+	service.RegisterRoutes(srv.Router())
+
+	if err := srv.Run(); err != nil {
 		log.Fatalf("HTTP服务启动失败: %v", err)
 	}
 }
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 股票资料（上市日期、股本） ============
+
+// stockMetadata 数据源返回的股票资料
+type stockMetadata struct {
+	Symbol     string `json:"symbol"`
+	Exchange   string `json:"exchange"`
+	ListDate   string `json:"list_date"`   // YYYY-MM-DD，未知时为空
+	TotalShare int64  `json:"total_share"` // 总股本（股）
+	FloatShare int64  `json:"float_share"` // 流通股本（股）
+}
+
+// SyncStockMetadata 从 Python 服务同步全部股票的上市日期、总股本与流通股本，返回更新的股票数
+// 只更新已在股票列表中的股票；数据源缺失的字段保持原值。总市值、流通市值由查询接口按最新收盘价计算。
+func (s *DataSyncService) SyncStockMetadata(ctx context.Context) (count int, err error) {
+	job, err := s.startJob(ctx, models.SyncJobStockMeta, "", "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	items, err := s.fetchStockMetadataFromPython(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("从 Python 服务获取股票资料失败: %w", err)
+	}
+
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+		var listDate *time.Time
+		if item.ListDate != "" {
+			t, err := time.Parse(markettime.DateLayout, item.ListDate)
+			if err != nil {
+				log.Printf("%s.%s 上市日期格式错误: %s", item.Symbol, item.Exchange, item.ListDate)
+			} else {
+				listDate = &t
+			}
+		}
+		found, err := s.stockRepo.UpdateMetadata(ctx, item.Symbol, item.Exchange, listDate, item.TotalShare, item.FloatShare)
+		if err != nil {
+			return count, fmt.Errorf("保存 %s.%s 股票资料失败: %w", item.Symbol, item.Exchange, err)
+		}
+		if found {
+			count++
+		}
+	}
+
+	log.Printf("股票资料同步完成，数据源 %d 只，更新 %d 只", len(items), count)
+	return count, nil
+}
+
+// fetchStockMetadataFromPython 从 Python 服务获取股票资料
+func (s *DataSyncService) fetchStockMetadataFromPython(ctx context.Context) ([]*stockMetadata, error) {
+	url := fmt.Sprintf("%s/api/v1/market/stock_info", s.pythonAPIURL)
+
+	var result struct {
+		Code int              `json:"code"`
+		Data []*stockMetadata `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
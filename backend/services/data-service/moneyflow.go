@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 资金流向同步 ============
+
+// SyncMoneyFlow 同步个股资金流向
+func (s *DataSyncService) SyncMoneyFlow(ctx context.Context, symbol, exchange string, start, end time.Time) (err error) {
+	log.Printf("开始同步 %s.%s 的资金流向 (%s ~ %s)", symbol, exchange, start.Format("2006-01-02"), end.Format("2006-01-02"))
+
+	var records int
+	job, err := s.startJob(ctx, models.SyncJobMoneyFlow, symbol, exchange)
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, records, err) }()
+
+	flows, err := s.fetchMoneyFlowFromPython(ctx, symbol, exchange, start, end)
+	if err != nil {
+		return fmt.Errorf("从 Python 服务获取资金流向失败: %w", err)
+	}
+
+	if len(flows) == 0 {
+		log.Printf("未获取到 %s.%s 的资金流向数据", symbol, exchange)
+		return nil
+	}
+
+	if err := s.marketRepo.SaveMoneyFlows(ctx, flows); err != nil {
+		return fmt.Errorf("保存资金流向失败: %w", err)
+	}
+
+	records = len(flows)
+	log.Printf("%s.%s 的资金流向同步完成，共 %d 条", symbol, exchange, len(flows))
+	return nil
+}
+
+// fetchMoneyFlowFromPython 从 Python 服务获取资金流向
+func (s *DataSyncService) fetchMoneyFlowFromPython(ctx context.Context, symbol, exchange string, start, end time.Time) ([]*models.MoneyFlow, error) {
+	url := fmt.Sprintf("%s/api/v1/market/money_flow?symbol=%s&exchange=%s&start=%s&end=%s",
+		s.pythonAPIURL,
+		symbol,
+		exchange,
+		start.Format("20060102"),
+		end.Format("20060102"),
+	)
+
+	var result struct {
+		Code int                 `json:"code"`
+		Data []*models.MoneyFlow `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	// Python 服务可能不返回标签字段，统一补齐
+	for _, flow := range result.Data {
+		flow.Symbol = symbol
+		flow.Exchange = exchange
+	}
+
+	return result.Data, nil
+}
+
+// ============ 龙虎榜同步 ============
+
+// SyncDragonTiger 同步指定交易日的龙虎榜
+func (s *DataSyncService) SyncDragonTiger(ctx context.Context, date time.Time) (err error) {
+	log.Printf("开始同步 %s 的龙虎榜", date.Format("2006-01-02"))
+
+	var records []*models.DragonTiger
+	job, err := s.startJob(ctx, models.SyncJobDragonTiger, "", "")
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, len(records), err) }()
+
+	records, err = s.fetchDragonTigerFromPython(ctx, date)
+	if err != nil {
+		return fmt.Errorf("从 Python 服务获取龙虎榜失败: %w", err)
+	}
+
+	if err := s.dragonTigerRepo.SaveBatch(ctx, records); err != nil {
+		return fmt.Errorf("保存龙虎榜失败: %w", err)
+	}
+
+	log.Printf("%s 的龙虎榜同步完成，共 %d 条", date.Format("2006-01-02"), len(records))
+	return nil
+}
+
+// fetchDragonTigerFromPython 从 Python 服务获取龙虎榜
+func (s *DataSyncService) fetchDragonTigerFromPython(ctx context.Context, date time.Time) ([]*models.DragonTiger, error) {
+	url := fmt.Sprintf("%s/api/v1/market/dragon_tiger?date=%s", s.pythonAPIURL, date.Format("20060102"))
+
+	var result struct {
+		Code int                   `json:"code"`
+		Data []*models.DragonTiger `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	for _, record := range result.Data {
+		record.TradeDate = date
+		if record.Seats == "" {
+			record.Seats = "[]"
+		}
+	}
+
+	return result.Data, nil
+}
+
+// getJSONFromPython 请求 Python 服务并解析 JSON 响应
+func (s *DataSyncService) getJSONFromPython(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
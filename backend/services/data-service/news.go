@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 新闻公告同步 ============
+
+// SyncNews 同步新闻公告（Python 采集服务 + RSS 源），并打上股票标签
+func (s *DataSyncService) SyncNews(ctx context.Context, since time.Time) (created int, err error) {
+	log.Printf("开始同步新闻公告 (since %s)...", since.Format("2006-01-02 15:04"))
+
+	job, err := s.startJob(ctx, models.SyncJobNews, "", "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, created, err) }()
+
+	var articles []*models.NewsArticle
+
+	fromPython, err := s.fetchNewsFromPython(ctx, since)
+	if err != nil {
+		log.Printf("从 Python 服务获取新闻失败: %v", err)
+	} else {
+		articles = append(articles, fromPython...)
+	}
+
+	for _, feed := range s.newsFeeds {
+		items, err := s.fetchNewsFromRSS(ctx, feed)
+		if err != nil {
+			log.Printf("抓取 RSS %s 失败: %v", feed, err)
+			continue
+		}
+		for _, item := range items {
+			if item.PublishedAt.After(since) {
+				articles = append(articles, item)
+			}
+		}
+	}
+
+	if len(articles) == 0 {
+		return 0, nil
+	}
+
+	stocks, err := s.stockRepo.GetActiveStocks(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取股票列表失败: %w", err)
+	}
+	for _, article := range articles {
+		tagArticleSymbols(article, stocks)
+	}
+
+	created, err = s.newsRepo.SaveArticles(ctx, articles)
+	if err != nil {
+		return 0, fmt.Errorf("保存新闻失败: %w", err)
+	}
+
+	log.Printf("新闻公告同步完成，获取 %d 条，新增 %d 条", len(articles), created)
+	return created, nil
+}
+
+// fetchNewsFromPython 从 Python 服务获取新闻公告
+func (s *DataSyncService) fetchNewsFromPython(ctx context.Context, since time.Time) ([]*models.NewsArticle, error) {
+	url := fmt.Sprintf("%s/api/v1/news?start=%s", s.pythonAPIURL, since.Format("20060102150405"))
+
+	var result struct {
+		Code int `json:"code"`
+		Data []struct {
+			Title       string    `json:"title"`
+			Summary     string    `json:"summary"`
+			Content     string    `json:"content"`
+			Source      string    `json:"source"`
+			Category    string    `json:"category"`
+			URL         string    `json:"url"`
+			PublishedAt time.Time `json:"published_at"`
+			Symbols     []string  `json:"symbols"` // 采集端已识别的代码，格式 000001.SZ
+		} `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+
+	articles := make([]*models.NewsArticle, 0, len(result.Data))
+	for _, item := range result.Data {
+		article := &models.NewsArticle{
+			Title:       item.Title,
+			Summary:     item.Summary,
+			Content:     item.Content,
+			Source:      item.Source,
+			Category:    item.Category,
+			URL:         item.URL,
+			PublishedAt: item.PublishedAt,
+		}
+		if article.Category == "" {
+			article.Category = "news"
+		}
+		for _, code := range item.Symbols {
+			parts := strings.SplitN(code, ".", 2)
+			if len(parts) == 2 {
+				article.Symbols = append(article.Symbols, &models.NewsSymbol{Symbol: parts[0], Exchange: parts[1]})
+			}
+		}
+		articles = append(articles, article)
+	}
+
+	return articles, nil
+}
+
+// rssFeed RSS 2.0 文档结构
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// fetchNewsFromRSS 抓取并解析 RSS 源
+func (s *DataSyncService) fetchNewsFromRSS(ctx context.Context, feedURL string) ([]*models.NewsArticle, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	return parseRSS(resp.Body)
+}
+
+// parseRSS 解析 RSS 文档，缺少链接或标题的条目跳过；发布时间无法解析的条目也跳过，
+// 不以抓取时间代替，否则旧新闻会被当作最新新闻排在前面
+func parseRSS(body io.Reader) ([]*models.NewsArticle, error) {
+	var feed rssFeed
+	if err := xml.NewDecoder(body).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("解析 RSS 失败: %w", err)
+	}
+
+	articles := make([]*models.NewsArticle, 0, len(feed.Channel.Items))
+	for _, item := range feed.Channel.Items {
+		if item.Link == "" || item.Title == "" {
+			continue
+		}
+		publishedAt, err := parseRSSTime(item.PubDate)
+		if err != nil {
+			log.Printf("跳过 RSS 条目 %s: %v", item.Link, err)
+			continue
+		}
+		articles = append(articles, &models.NewsArticle{
+			Title:       strings.TrimSpace(item.Title),
+			Summary:     strings.TrimSpace(item.Description),
+			Source:      "rss:" + feed.Channel.Title,
+			Category:    "news",
+			URL:         item.Link,
+			PublishedAt: publishedAt,
+		})
+	}
+
+	return articles, nil
+}
+
+// rssTimeLayouts RSS 中常见的时间格式，RFC 822 的日期可以是一位数
+var rssTimeLayouts = []string{
+	time.RFC1123Z, time.RFC1123, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", time.RFC3339,
+}
+
+// rssLocalLayouts 不带时区的时间格式，按北京时间解析
+var rssLocalLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04"}
+
+// parseRSSTime 解析 RSS 中常见的时间格式
+func parseRSSTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range rssTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range rssLocalLayouts {
+		if t, err := time.ParseInLocation(layout, value, markettime.Location("")); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间: %q", value)
+}
+
+// tagArticleSymbols 根据标题和正文中出现的股票代码或名称为新闻打标签
+func tagArticleSymbols(article *models.NewsArticle, stocks []*models.Stock) {
+	seen := make(map[string]bool)
+	for _, sym := range article.Symbols {
+		seen[sym.Symbol+"."+sym.Exchange] = true
+	}
+
+	text := article.Title + " " + article.Summary + " " + article.Content
+	for _, stock := range stocks {
+		key := stock.GetFullCode()
+		if seen[key] {
+			continue
+		}
+		if mentionsCode(text, stock.Symbol, stock.Exchange) || (stock.Name != "" && strings.Contains(text, stock.Name)) {
+			article.Symbols = append(article.Symbols, &models.NewsSymbol{Symbol: stock.Symbol, Exchange: stock.Exchange})
+			seen[key] = true
+		}
+	}
+}
+
+// mentionsCode 文本中是否出现股票代码
+// 代码前后不能紧邻数字，避免匹配金额、日期等更长数字的一部分；带交易所前缀或后缀（SH600519、600519.SH）时交易所须一致。
+func mentionsCode(text, symbol, exchange string) bool {
+	if symbol == "" {
+		return false
+	}
+	for from := 0; ; {
+		i := strings.Index(text[from:], symbol)
+		if i < 0 {
+			return false
+		}
+		start := from + i
+		end := start + len(symbol)
+		from = start + 1
+		if start > 0 && isDigit(text[start-1]) || end < len(text) && isDigit(text[end]) {
+			continue
+		}
+		if ex := codeExchange(text, start, end); ex != "" && ex != exchange {
+			continue
+		}
+		return true
+	}
+}
+
+// codeExchange 代码 text[start:end] 的交易所前缀（SH600519）或后缀（600519.SH），不区分大小写；没有时返回空
+func codeExchange(text string, start, end int) string {
+	if start >= 2 {
+		if ex := strings.ToUpper(text[start-2 : start]); isExchange(ex) && (start == 2 || !isLetter(text[start-3])) {
+			return ex
+		}
+	}
+	if end+3 <= len(text) && text[end] == '.' {
+		if ex := strings.ToUpper(text[end+1 : end+3]); isExchange(ex) && (end+3 == len(text) || !isLetter(text[end+3])) {
+			return ex
+		}
+	}
+	return ""
+}
+
+func isExchange(s string) bool {
+	return s == "SH" || s == "SZ" || s == "BJ"
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+func TestTagArticleSymbols(t *testing.T) {
+	stocks := []*models.Stock{
+		{Symbol: "600519", Exchange: "SH", Name: "贵州茅台"},
+		{Symbol: "000001", Exchange: "SZ", Name: "平安银行"},
+		{Symbol: "000858", Exchange: "SZ", Name: "五粮液"},
+	}
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"括号中的代码", "白酒龙头(600519)发布年报", []string{"600519.SH"}},
+		{"交易所前缀", "SH600519 放量上涨", []string{"600519.SH"}},
+		{"小写前缀", "sz000858收涨", []string{"000858.SZ"}},
+		{"交易所后缀", "关注 000001.SZ 与 000858.SZ", []string{"000001.SZ", "000858.SZ"}},
+		{"前缀交易所不一致", "上证指数 SH000001 收涨", nil},
+		{"后缀交易所不一致", "600519.SZ 不是茅台的代码", nil},
+		{"更长数字的一部分", "成交额 16005190 元，订单号 2000001", nil},
+		{"金额中的代码", "净利润 1000001 万元", nil},
+		{"名称", "五粮液与贵州茅台同日公告", []string{"600519.SH", "000858.SZ"}},
+		{"代码与名称只打一次", "贵州茅台（600519）", []string{"600519.SH"}},
+		{"文本开头与结尾", "000001", []string{"000001.SZ"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := &models.NewsArticle{Title: tt.text}
+			tagArticleSymbols(article, stocks)
+			var got []string
+			for _, sym := range article.Symbols {
+				got = append(got, sym.Symbol+"."+sym.Exchange)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("标签 %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+
+	// 已有的标签不重复添加
+	article := &models.NewsArticle{Title: "600519", Symbols: []*models.NewsSymbol{{Symbol: "600519", Exchange: "SH"}}}
+	tagArticleSymbols(article, stocks)
+	if len(article.Symbols) != 1 {
+		t.Errorf("已有标签重复添加: %d", len(article.Symbols))
+	}
+}
+
+func TestParseRSSTime(t *testing.T) {
+	beijing := time.FixedZone("CST", 8*3600)
+	tests := []struct {
+		value string
+		want  time.Time
+		ok    bool
+	}{
+		{"Fri, 16 Oct 2026 09:30:00 +0800", time.Date(2026, 10, 16, 9, 30, 0, 0, beijing), true},
+		{"Fri, 6 Oct 2026 09:30:00 +0800", time.Date(2026, 10, 6, 9, 30, 0, 0, beijing), true},
+		{"Fri, 16 Oct 2026 01:30:00 GMT", time.Date(2026, 10, 16, 1, 30, 0, 0, time.UTC), true},
+		{"2026-10-16T09:30:00+08:00", time.Date(2026, 10, 16, 9, 30, 0, 0, beijing), true},
+		{"  2026-10-16T01:30:00Z\n", time.Date(2026, 10, 16, 1, 30, 0, 0, time.UTC), true},
+		// 不带时区时按北京时间
+		{"2026-10-16 09:30:00", time.Date(2026, 10, 16, 9, 30, 0, 0, beijing), true},
+		{"2026-10-16 09:30", time.Date(2026, 10, 16, 9, 30, 0, 0, beijing), true},
+		{"", time.Time{}, false},
+		{"昨天 09:30", time.Time{}, false},
+		{"16/10/2026", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		got, err := parseRSSTime(tt.value)
+		if (err == nil) != tt.ok {
+			t.Errorf("parseRSSTime(%q) err = %v，期望成功 = %v", tt.value, err, tt.ok)
+			continue
+		}
+		if tt.ok && !got.Equal(tt.want) {
+			t.Errorf("parseRSSTime(%q) = %v，期望 %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// 发布时间无法解析的条目跳过，不以抓取时间代替
+func TestParseRSSSkipsBadTime(t *testing.T) {
+	feed := `<?xml version="1.0"?>
+<rss version="2.0"><channel><title>财经</title>
+<item><title>有效</title><link>https://example.com/1</link><pubDate>Fri, 16 Oct 2026 09:30:00 +0800</pubDate></item>
+<item><title>时间错误</title><link>https://example.com/2</link><pubDate>昨天</pubDate></item>
+<item><title>没有时间</title><link>https://example.com/3</link></item>
+<item><title>没有链接</title><pubDate>Fri, 16 Oct 2026 09:30:00 +0800</pubDate></item>
+</channel></rss>`
+	articles, err := parseRSS(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(articles) != 1 || articles[0].URL != "https://example.com/1" || articles[0].Source != "rss:财经" {
+		t.Fatalf("解析结果 %+v，期望只保留第一条", articles)
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"stock-analysis-system/backend/pkg/cache"
+	"stock-analysis-system/backend/pkg/jobs"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/restatement"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 历史K线修正后的下游失效 ============
+
+// restatementResult 日K线修正后下游数据的失效与重算结果
+type restatementResult struct {
+	JobID              uint  `json:"restatement_job_id,omitempty"` // restatement 同步任务，各项下游任务见任务详情的 dependents
+	Indicators         int   `json:"indicators"`                   // 重算的技术指标条数
+	StaleBacktests     int64 `json:"stale_backtests"`              // 标记为过期的回测数
+	Caches             int   `json:"caches"`                       // 删除的行情缓存键数
+	QualityScores      int   `json:"quality_scores"`               // 重算数据质量评分的股票数
+	FactorScoresQueued bool  `json:"factor_scores_queued"`         // 已登记在后台重算受影响交易日的因子得分
+}
+
+// restate 按 restatement.Plan 使被修正的一段日K线的下游数据失效：重算技术指标与质量评分、删除行情缓存、标记过期回测，
+// 登记因子得分重算（全市场截面，在后台由下游任务队列执行）。
+// 记录一条 restatement 同步任务，每项下游数据记录为依赖于它的任务；某项失败时跳过依赖于它的下游，其余照常执行。
+func (s *DataSyncService) restate(ctx context.Context, symbol, exchange string, r validation.DateRange) (result restatementResult, err error) {
+	job, err := s.startJob(ctx, models.SyncJobRestatement, symbol, exchange)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.finishJob(job, result.Indicators+int(result.StaleBacktests)+result.Caches+result.QualityScores, err)
+	}()
+	if job.job != nil {
+		result.JobID = job.job.ID
+	}
+
+	change := restatement.Change{Symbol: symbol, Exchange: exchange, Start: r.Start, End: r.End}
+	impacts := restatement.Plan(change, time.Now())
+	failed := make(map[string]bool)
+	var errs []error
+	for _, impact := range impacts {
+		if restatement.Blocked(impacts, impact, failed) {
+			failed[impact.Artifact] = true
+			continue
+		}
+		if impact.Artifact == models.SyncJobFactors {
+			// 因子得分按全市场截面重算，耗时与受影响的交易日数成正比，登记为后台下游任务
+			d := jobs.Dependent{Type: impact.Artifact, Start: impact.Start, End: impact.End}
+			if job.job != nil {
+				d.ParentID = &job.job.ID
+			}
+			s.dependents.Enqueue(d)
+			result.FactorScoresQueued = true
+			continue
+		}
+		if err := s.invalidate(ctx, job, change, impact, &result); err != nil {
+			failed[impact.Artifact] = true
+			errs = append(errs, fmt.Errorf("%s: %w", impact.Artifact, err))
+		}
+	}
+	return result, errors.Join(errs...)
+}
+
+// invalidate 执行一项下游失效，记录为依赖于 restatement 任务的同步任务
+func (s *DataSyncService) invalidate(ctx context.Context, upstream *syncRun, c restatement.Change, impact restatement.Impact, result *restatementResult) (err error) {
+	var count int
+	job, err := s.startDependentJob(ctx, upstream, impact.Artifact, c.Symbol, c.Exchange)
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	switch impact.Artifact {
+	case models.SyncJobIndicators:
+		count, err = s.recomputeIndicators(ctx, c.Symbol, c.Exchange, validation.DateRange{Start: impact.Start, End: impact.End})
+		result.Indicators = count
+	case models.SyncJobMarketCache:
+		// 全市场最近行情与由它汇总的行业数据、该股票的行情，下次查询时回源
+		keys := []string{cache.KeyMarketBars, cache.KeyIndustries, cache.QuoteKey(c.Symbol, c.Exchange)}
+		if err = s.marketCache.Delete(ctx, keys...); err == nil && s.marketCache.Enabled() {
+			count = len(keys)
+		}
+		result.Caches = count
+	case models.SyncJobStaleBacktests:
+		reason := fmt.Sprintf("%s.%s 的日K线 %s ~ %s 已修正", c.Symbol, c.Exchange,
+			c.Start.Format(validation.DateLayout), c.End.Format(validation.DateLayout))
+		var n int64
+		n, err = s.backtestRepo.MarkStale(ctx, c.Symbol, c.Exchange, impact.Start, impact.End, reason)
+		count, result.StaleBacktests = int(n), n
+	case models.SyncJobQualityScore:
+		score, ok, scoreErr := s.quality.ScoreStock(ctx, c.Symbol, c.Exchange)
+		if err = scoreErr; err == nil && ok {
+			if err = s.stockRepo.UpdateQualityScore(ctx, c.Symbol, c.Exchange, score, time.Now()); err == nil {
+				count = 1
+			}
+		}
+		result.QualityScores = count
+	default:
+		err = fmt.Errorf("未知的下游数据 %s", impact.Artifact)
+	}
+	return err
+}
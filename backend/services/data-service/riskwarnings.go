@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 风险警示（ST/*ST） ============
+
+// UpdateRiskWarningsFromNames 根据最新证券简称识别 ST/*ST 状态变化，并自指定日期记录新的警示区间
+// 交易所在实施或撤销风险警示时同步变更简称前缀，因此简称是每日更新状态的可靠来源。
+func (s *DataSyncService) UpdateRiskWarningsFromNames(ctx context.Context, stocks []*models.Stock, date time.Time) (int, error) {
+	current, err := s.stockRepo.GetRiskWarningsAsOf(ctx, date)
+	if err != nil {
+		return 0, fmt.Errorf("查询风险警示失败: %w", err)
+	}
+
+	changed := 0
+	for _, stock := range stocks {
+		warning := models.RiskWarningFromName(stock.Name)
+		stock.RiskWarning = warning
+		if current[stock.GetFullCode()] == warning {
+			continue
+		}
+		if err := s.stockRepo.UpdateRiskWarning(ctx, stock.Symbol, stock.Exchange, warning, date); err != nil {
+			log.Printf("更新 %s.%s 风险警示失败: %v", stock.Symbol, stock.Exchange, err)
+			continue
+		}
+		changed++
+	}
+
+	if changed > 0 {
+		log.Printf("风险警示状态变化 %d 只股票", changed)
+	}
+	return changed, nil
+}
+
+// SyncRiskWarningHistory 从 Python 服务同步全部股票的风险警示历史（含实施、撤销日期），用于回补历史时点状态
+func (s *DataSyncService) SyncRiskWarningHistory(ctx context.Context) (err error) {
+	var warnings []*models.StockRiskWarning
+	job, err := s.startJob(ctx, models.SyncJobRiskWarnings, "", "")
+	if err != nil {
+		return err
+	}
+	defer func() { s.finishJob(job, len(warnings), err) }()
+
+	warnings, err = s.fetchRiskWarningsFromPython(ctx)
+	if err != nil {
+		return fmt.Errorf("从 Python 服务获取风险警示历史失败: %w", err)
+	}
+
+	if err := s.stockRepo.ReplaceRiskWarnings(ctx, warnings); err != nil {
+		return fmt.Errorf("保存风险警示历史失败: %w", err)
+	}
+
+	log.Printf("风险警示历史同步完成，共 %d 条", len(warnings))
+	return nil
+}
+
+// fetchRiskWarningsFromPython 从 Python 服务获取风险警示历史
+func (s *DataSyncService) fetchRiskWarningsFromPython(ctx context.Context) ([]*models.StockRiskWarning, error) {
+	url := fmt.Sprintf("%s/api/v1/market/risk_warnings", s.pythonAPIURL)
+
+	var result struct {
+		Code int                        `json:"code"`
+		Data []*models.StockRiskWarning `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, err
+	}
+	return result.Data, nil
+}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/snapshot"
+)
+
+// ============ 数据快照导出 ============
+
+const (
+	maxSnapshotDays    = 366 * 30         // 单个快照最长覆盖的天数
+	snapshotLinkExpiry = 15 * time.Minute // 下载链接有效期
+	snapshotDateLayout = "2006-01-02"
+)
+
+// snapshotExporter 延迟连接对象存储，启动时对象存储不可用不影响其他同步任务
+type snapshotExporter struct {
+	mu    sync.Mutex
+	store *snapshot.Store
+	busy  bool // 同一时间只运行一个导出
+}
+
+// snapshotStore 获取对象存储连接，未配置时返回错误
+func (s *DataSyncService) snapshotStore(ctx context.Context) (*snapshot.Store, error) {
+	if !s.cfg.Export.Enabled() {
+		return nil, errors.New("未配置 EXPORT_S3_ENDPOINT，快照导出未启用")
+	}
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+	if s.snapshots.store == nil {
+		store, err := snapshot.NewStore(ctx, &s.cfg.Export)
+		if err != nil {
+			return nil, err
+		}
+		s.snapshots.store = store
+	}
+	return s.snapshots.store, nil
+}
+
+// ExportSnapshot 将 [start, end] 的日K线与技术指标导出为 Parquet 快照
+// 数据文件全部上传后才写入 manifest.json，同一区间重复导出时覆盖原快照。
+func (s *DataSyncService) ExportSnapshot(ctx context.Context, start, end time.Time) (manifest *snapshot.Manifest, err error) {
+	store, err := s.snapshotStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.snapshots.mu.Lock()
+	if s.snapshots.busy {
+		s.snapshots.mu.Unlock()
+		return nil, errors.New("已有快照正在导出")
+	}
+	s.snapshots.busy = true
+	s.snapshots.mu.Unlock()
+	defer func() {
+		s.snapshots.mu.Lock()
+		s.snapshots.busy = false
+		s.snapshots.mu.Unlock()
+	}()
+
+	var records int
+	job, err := s.startJob(ctx, models.SyncJobSnapshot, "", "")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { s.finishJob(job, records, err) }()
+
+	manifest = &snapshot.Manifest{
+		ID:        snapshot.ID(start, end),
+		Start:     start.Format(snapshotDateLayout),
+		End:       end.Format(snapshotDateLayout),
+		CreatedAt: time.Now(),
+	}
+	log.Printf("开始导出数据快照 %s", manifest.ID)
+	stop := end.AddDate(0, 0, 1)
+
+	daily, err := exportFile(ctx, store, manifest.ID, snapshot.TypeDailyBars, func(w *snapshot.FileWriter[snapshot.DailyBarRow]) error {
+		return s.marketRepo.ScanDailyBars(ctx, start, stop, func(bar *models.DailyBar) error {
+			return w.Write(snapshot.NewDailyBarRow(bar))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	indicators, err := exportFile(ctx, store, manifest.ID, snapshot.TypeIndicators, func(w *snapshot.FileWriter[snapshot.IndicatorRow]) error {
+		return s.marketRepo.ScanIndicators(ctx, start, stop, func(ind *models.Indicator) error {
+			return w.Write(snapshot.NewIndicatorRow(ind))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.Files = []snapshot.FileEntry{daily, indicators}
+	if err := store.PutManifest(ctx, manifest); err != nil {
+		return nil, err
+	}
+	records = int(daily.Rows + indicators.Rows)
+	log.Printf("数据快照 %s 导出完成：日K线 %d 行，技术指标 %d 行", manifest.ID, daily.Rows, indicators.Rows)
+	return manifest, nil
+}
+
+// exportFile 写入本地临时 Parquet 文件后上传，临时文件在返回前删除
+func exportFile[T any](ctx context.Context, store *snapshot.Store, id, dataType string, fill func(w *snapshot.FileWriter[T]) error) (snapshot.FileEntry, error) {
+	w, err := snapshot.NewFileWriter[T](dataType + "-*.parquet")
+	if err != nil {
+		return snapshot.FileEntry{}, err
+	}
+	defer w.Remove()
+
+	if err := fill(w); err != nil {
+		return snapshot.FileEntry{}, fmt.Errorf("导出 %s 失败: %w", dataType, err)
+	}
+	entry, err := w.Close()
+	if err != nil {
+		return snapshot.FileEntry{}, err
+	}
+	entry.Name = dataType + ".parquet"
+	entry.Type = dataType
+	entry.Key = store.Key(id, entry.Name)
+	if err := store.Upload(ctx, entry.Key, w.Path()); err != nil {
+		return snapshot.FileEntry{}, err
+	}
+	return entry, nil
+}
+
+// scheduledSnapshot 定时导出上一自然日的数据，未配置对象存储或 EXPORT_SCHEDULE_HOUR 为负数时跳过
+func (s *DataSyncService) scheduledSnapshot(ctx context.Context, now time.Time) {
+	if !s.cfg.Export.Enabled() || s.cfg.Export.ScheduleHour < 0 || now.Hour() != s.cfg.Export.ScheduleHour {
+		return
+	}
+	y, m, d := now.AddDate(0, 0, -1).Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	if _, err := s.ExportSnapshot(ctx, day, day); err != nil {
+		log.Printf("定时导出数据快照失败: %v", err)
+	}
+}
+
+// ============ 快照接口 ============
+
+// handleSnapshots 快照列表（GET）与手动导出（POST）
+func (s *DataSyncService) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		store, err := s.snapshotStore(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		manifests, err := store.ListManifests(r.Context())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 0,
+			"data": map[string]interface{}{"list": manifests, "total": len(manifests)},
+		})
+
+	case http.MethodPost:
+		// 默认导出上一自然日；指定 start/end 可回补或做全量备份
+		var req struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		start, end, err := snapshotRange(req.Start, req.End)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.startSyncTask(w, models.SyncJobSnapshot, func(ctx context.Context) (string, error) {
+			manifest, err := s.ExportSnapshot(ctx, start, end)
+			if err != nil {
+				return "", err
+			}
+			return "快照 " + manifest.ID + " 导出完成", nil
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleSnapshot 快照清单 /api/v1/snapshots/{id} 与文件下载 /api/v1/snapshots/{id}/files/{name}
+// 下载时 redirect=true 返回对象存储的限时链接，否则由服务转发文件内容。
+func (s *DataSyncService) handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/snapshots/"), "/"), "/")
+	if !snapshot.ValidID(parts[0]) || (len(parts) != 1 && (len(parts) != 3 || parts[1] != "files")) {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+
+	ctx := r.Context()
+	store, err := s.snapshotStore(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	manifest, err := store.GetManifest(ctx, parts[0])
+	if errors.Is(err, snapshot.ErrNotFound) {
+		http.Error(w, "snapshot not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(parts) == 1 {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"code": 0, "data": manifest})
+		return
+	}
+
+	file, ok := manifest.File(parts[2])
+	if !ok {
+		http.Error(w, "file not found", http.StatusNotFound)
+		return
+	}
+	if r.URL.Query().Get("redirect") == "true" {
+		link, err := store.PresignedURL(ctx, file.Key, manifest.ID+"-"+file.Name, snapshotLinkExpiry)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		http.Redirect(w, r, link.String(), http.StatusFound)
+		return
+	}
+
+	body, err := store.Open(ctx, file.Key)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+	w.Header().Set("Content-Type", "application/vnd.apache.parquet")
+	w.Header().Set("Content-Length", fmt.Sprint(file.Size))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", manifest.ID+"-"+file.Name))
+	w.Header().Set("X-Checksum-SHA256", file.SHA256)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("下载快照文件 %s 中断: %v", file.Key, err)
+	}
+}
+
+// snapshotRange 解析导出区间，默认上一自然日
+func snapshotRange(startValue, endValue string) (time.Time, time.Time, error) {
+	loc := markettime.Location("")
+	y, m, d := time.Now().In(loc).AddDate(0, 0, -1).Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, loc)
+	end := start
+	var err error
+	if startValue != "" {
+		if start, err = time.ParseInLocation(snapshotDateLayout, startValue, loc); err != nil {
+			return start, end, errors.New("invalid start date")
+		}
+		end = start
+	}
+	if endValue != "" {
+		if end, err = time.ParseInLocation(snapshotDateLayout, endValue, loc); err != nil {
+			return start, end, errors.New("invalid end date")
+		}
+	}
+	if end.Before(start) {
+		return start, end, errors.New("end must not be before start")
+	}
+	if end.Sub(start) > maxSnapshotDays*24*time.Hour {
+		return start, end, fmt.Errorf("a snapshot covers at most %d days", maxSnapshotDays)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/screener"
+)
+
+// maxSnapshotBackfillDays 单次回补股票池快照的最大自然日跨度
+const maxSnapshotBackfillDays = 366
+
+// ============ 股票池成分快照 ============
+
+// SnapshotUniverses 保存全部启用股票池在指定交易日的成分快照
+// 周末不生成快照；单个股票池失败只记录日志，不影响其他股票池。
+func (s *DataSyncService) SnapshotUniverses(ctx context.Context, date time.Time) (count int, err error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if isWeekend(date) {
+		return 0, nil
+	}
+
+	job, err := s.startJob(ctx, models.SyncJobUniverses, "", "")
+	if err != nil {
+		return 0, err
+	}
+	defer func() { s.finishJob(job, count, err) }()
+
+	universes, err := s.universeRepo.GetActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("获取股票池列表失败: %w", err)
+	}
+
+	for _, universe := range universes {
+		n, err := s.snapshotUniverse(ctx, universe, date)
+		if err != nil {
+			log.Printf("保存股票池 %d(%s) %s 快照失败: %v", universe.ID, universe.Name, date.Format("2006-01-02"), err)
+			continue
+		}
+		count += n
+	}
+
+	log.Printf("%s 的股票池快照完成，共 %d 个股票池 %d 条成分", date.Format("2006-01-02"), len(universes), count)
+	return count, nil
+}
+
+// SnapshotUniverseByID 保存单个股票池在指定交易日的成分快照，用于新建股票池后回补历史
+func (s *DataSyncService) SnapshotUniverseByID(ctx context.Context, universeID uint, date time.Time) (int, error) {
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	if isWeekend(date) {
+		return 0, nil
+	}
+
+	universe, err := s.universeRepo.GetByID(ctx, universeID)
+	if err != nil {
+		return 0, fmt.Errorf("股票池不存在: %w", err)
+	}
+	return s.snapshotUniverse(ctx, universe, date)
+}
+
+// snapshotUniverse 按股票池类型计算当日成分并保存
+func (s *DataSyncService) snapshotUniverse(ctx context.Context, universe *models.Universe, date time.Time) (int, error) {
+	var members []*models.UniverseMember
+	var err error
+
+	switch universe.Type {
+	case models.UniverseTypeIndex:
+		members, err = s.fetchIndexConstituentsFromPython(ctx, universe.Source, date)
+	case models.UniverseTypeScreener:
+		members, err = s.screenUniverseMembers(ctx, universe, date)
+	case models.UniverseTypeManual:
+		members, err = manualUniverseMembers(universe)
+	default:
+		err = fmt.Errorf("不支持的股票池类型: %s", universe.Type)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	for _, member := range members {
+		member.UniverseID = universe.ID
+		member.TradeDate = date
+	}
+	if err := s.universeRepo.SaveSnapshot(ctx, universe.ID, date, members); err != nil {
+		return 0, fmt.Errorf("保存成分快照失败: %w", err)
+	}
+	return len(members), nil
+}
+
+// screenUniverseMembers 按保存的选股条件在当日做时点选股，按排序取前 MaxMembers 只
+func (s *DataSyncService) screenUniverseMembers(ctx context.Context, universe *models.Universe, date time.Time) ([]*models.UniverseMember, error) {
+	var params screener.Params
+	if universe.Criteria != "" {
+		if err := json.Unmarshal([]byte(universe.Criteria), &params); err != nil {
+			return nil, fmt.Errorf("选股条件格式错误: %w", err)
+		}
+	}
+	if err := params.Normalize(); err != nil {
+		return nil, err
+	}
+
+	result, err := screener.Run(ctx, s.stockRepo, s.marketRepo, s.factorRepo, s.connectRepo, &params, date)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := result.Rows
+	if universe.MaxMembers > 0 && len(rows) > universe.MaxMembers {
+		rows = rows[:universe.MaxMembers]
+	}
+	members := make([]*models.UniverseMember, 0, len(rows))
+	for _, row := range rows {
+		members = append(members, &models.UniverseMember{Symbol: row.Symbol, Exchange: row.Exchange})
+	}
+	return members, nil
+}
+
+// manualUniverseMembers 手工股票池按当前股票列表生成快照
+func manualUniverseMembers(universe *models.Universe) ([]*models.UniverseMember, error) {
+	symbols := universe.SymbolList()
+	members := make([]*models.UniverseMember, 0, len(symbols))
+	for _, key := range symbols {
+		parts := strings.SplitN(key, ".", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("股票代码格式错误: %s", key)
+		}
+		members = append(members, &models.UniverseMember{Symbol: parts[0], Exchange: parts[1]})
+	}
+	return members, nil
+}
+
+// fetchIndexConstituentsFromPython 从 Python 服务获取指数在指定日期的成分股及权重
+func (s *DataSyncService) fetchIndexConstituentsFromPython(ctx context.Context, index string, date time.Time) ([]*models.UniverseMember, error) {
+	parts := strings.SplitN(index, ".", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("指数代码格式错误: %s", index)
+	}
+	url := fmt.Sprintf("%s/api/v1/market/index_constituents?index=%s&exchange=%s&date=%s",
+		s.pythonAPIURL, parts[0], parts[1], date.Format("2006-01-02"))
+
+	var result struct {
+		Code int                      `json:"code"`
+		Data []*models.UniverseMember `json:"data"`
+	}
+	if err := s.getJSONFromPython(ctx, url, &result); err != nil {
+		return nil, fmt.Errorf("从 Python 服务获取指数成分失败: %w", err)
+	}
+	return result.Data, nil
+}
+
+// snapshotDates 解析快照日期：date 指定单日，start/end 指定回补区间（跳过周末），都为空时取上一自然日
+func snapshotDates(date, start, end string) ([]time.Time, error) {
+	if start == "" && end == "" {
+		d := markettime.Now("").AddDate(0, 0, -1)
+		if date != "" {
+			t, err := time.Parse("2006-01-02", date)
+			if err != nil {
+				return nil, fmt.Errorf("invalid date")
+			}
+			d = t
+		}
+		return []time.Time{d}, nil
+	}
+
+	from, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start")
+	}
+	to, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end")
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("end must not be before start")
+	}
+	if to.Sub(from) > maxSnapshotBackfillDays*24*time.Hour {
+		return nil, fmt.Errorf("range must not exceed %d days", maxSnapshotBackfillDays)
+	}
+
+	var dates []time.Time
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if !isWeekend(d) {
+			dates = append(dates, d)
+		}
+	}
+	return dates, nil
+}
+
+// isWeekend 是否周末
+func isWeekend(date time.Time) bool {
+	return date.Weekday() == time.Saturday || date.Weekday() == time.Sunday
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/basket"
+	"stock-analysis-system/backend/pkg/cache"
+	"stock-analysis-system/backend/pkg/markettime"
+)
+
+// ============ 篮子行情接口 ============
+
+// basketConcurrency 计算篮子行情时并发查询成分股行情的数量
+const basketConcurrency = 8
+
+// BasketQuoteRequest 篮子行情查询参数，symbols 与 industry 二选一
+type BasketQuoteRequest struct {
+	Symbols  string `form:"symbols"`  // 600519.SH:0.3,000001.SZ:0.7，省略权重时等权
+	Industry string `form:"industry"` // 行业等权指数：该行业全部上市股票
+}
+
+// BasketLegRequest 篮子成分股
+type BasketLegRequest struct {
+	Symbol string  `json:"symbol" binding:"required"` // symbol.exchange
+	Weight float64 `json:"weight"`                    // 省略时等权，需全部指定或全部省略
+}
+
+// BasketQuoteBody 以请求体提交的篮子
+type BasketQuoteBody struct {
+	Symbols []BasketLegRequest `json:"symbols" binding:"required,min=1"`
+}
+
+// BasketQuoteResponse 篮子行情
+type BasketQuoteResponse struct {
+	*basket.Quote
+	Basket      string `json:"basket"`             // 篮子标识，成分股与权重相同的篮子共用缓存
+	Degraded    bool   `json:"degraded,omitempty"` // 部分成分股使用的是最近一次成功查询的行情
+	Timestamp   int64  `json:"timestamp"`          // Unix 秒
+	TimestampMs int64  `json:"timestamp_ms"`       // 毫秒时间戳
+	UpdateTime  string `json:"update_time"`        // 带时区偏移的 RFC3339 时间
+}
+
+// GetBasketQuote 由成分股最新价实时计算篮子（自定义组合或行业等权指数）的指数行情
+// GET 通过查询参数指定成分股，成分股较多时使用 POST 提交；结果按篮子缓存，有效期与个股行情相同。
+func (s *MarketService) GetBasketQuote(c *gin.Context) {
+	legs, err := s.basketLegs(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	id := basket.Hash(legs)
+	quote, stale, err := cache.GetOrLoadStale(ctx, s.cache, "basket:"+id, s.quoteTTL(time.Now()),
+		func(ctx context.Context) (*BasketQuoteResponse, error) {
+			return s.loadBasketQuote(ctx, legs)
+		})
+	if errors.Is(err, basket.ErrNoPrices) {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": err.Error()})
+		return
+	}
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+	quote.Basket = id
+	quote.Degraded = quote.Degraded || stale
+	now := time.Now()
+	quote.Timestamp = now.Unix()
+	quote.TimestampMs = now.UnixMilli()
+	quote.UpdateTime = markettime.Format(now, "")
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": quote,
+	})
+}
+
+// basketLegs 解析请求中的篮子成分股
+func (s *MarketService) basketLegs(c *gin.Context) ([]basket.Leg, error) {
+	if c.Request.Method == http.MethodPost {
+		var body BasketQuoteBody
+		if err := c.ShouldBindJSON(&body); err != nil {
+			return nil, errors.New("参数错误: " + err.Error())
+		}
+		legs := make([]basket.Leg, 0, len(body.Symbols))
+		for _, item := range body.Symbols {
+			leg, err := basket.NewLeg(item.Symbol, item.Weight)
+			if err != nil {
+				return nil, err
+			}
+			legs = append(legs, leg)
+		}
+		return basket.Normalize(legs)
+	}
+
+	var req BasketQuoteRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		return nil, errors.New("参数错误: " + err.Error())
+	}
+	switch {
+	case req.Symbols != "" && req.Industry != "":
+		return nil, errors.New("symbols 与 industry 不能同时指定")
+	case req.Symbols != "":
+		return basket.Parse(req.Symbols)
+	case req.Industry != "":
+		return s.industryBasket(c.Request.Context(), strings.TrimSpace(req.Industry))
+	}
+	return nil, errors.New("需要指定 symbols 或 industry")
+}
+
+// industryBasket 行业内全部上市股票的等权篮子
+func (s *MarketService) industryBasket(ctx context.Context, industry string) ([]basket.Leg, error) {
+	stocks, err := s.allStocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, stock := range stocks {
+		if stock.Industry == industry && stock.IsActive() {
+			keys = append(keys, stock.Symbol+"."+stock.Exchange)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("行业不存在或没有上市股票: " + industry)
+	}
+	return basket.EqualWeight(keys)
+}
+
+// loadBasketQuote 查询成分股行情（与个股行情共用缓存）并计算篮子行情
+// 不存在的股票计入 missing；全部成分股查询失败时返回错误，避免把不完整的行情写入缓存。
+func (s *MarketService) loadBasketQuote(ctx context.Context, legs []basket.Leg) (*BasketQuoteResponse, error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var degraded bool
+	var lastErr error
+	failed := 0
+	prices := make(map[string]basket.Price, len(legs))
+	sem := make(chan struct{}, basketConcurrency)
+	for _, leg := range legs {
+		wg.Add(1)
+		go func(leg basket.Leg) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			quote, stale, err := cache.GetOrLoadStale(ctx, s.cache, quoteCacheKey(leg.Symbol, leg.Exchange), s.quoteTTL(time.Now()),
+				func(ctx context.Context) (*QuoteResponse, error) {
+					return s.loadQuote(ctx, leg.Symbol, leg.Exchange)
+				})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if errors.Is(err, errStockNotFound) {
+				return
+			}
+			if err != nil {
+				failed++
+				lastErr = err
+				return
+			}
+			degraded = degraded || stale
+			prices[leg.Key()] = basket.Price{
+				Name:     quote.Name,
+				Price:    quote.Price,
+				PreClose: quote.PreClose,
+				DataDate: quote.DataDate,
+			}
+		}(leg)
+	}
+	wg.Wait()
+
+	if failed == len(legs) {
+		return nil, lastErr
+	}
+	quote, err := basket.Compute(legs, prices)
+	if err != nil {
+		return nil, err
+	}
+	// 部分成分股查询失败时结果不完整，标记为降级
+	return &BasketQuoteResponse{Quote: quote, Degraded: degraded || failed > 0}, nil
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/cache"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 行情缓存与预热 ============
+
+// 缓存有效期的默认值，可在配置文件的 cache.ttls 中按缓存名（见 cacheTTL）覆盖并热更新
+// 日线数据只在同步后变化，同步完成后会重新预热。
+const (
+	stockListCacheTTL  = time.Hour
+	marketBarsCacheTTL = time.Hour
+	industryCacheTTL   = time.Hour
+)
+
+// 个股行情缓存有效期：交易时段行情随时变化，只短暂缓存以合并热点股票的并发查询
+const (
+	quoteCacheTTL        = 10 * time.Minute
+	quoteCacheTradingTTL = 3 * time.Second
+)
+
+const (
+	cacheWarmCheckInterval = time.Minute     // 检查同步任务的间隔
+	cacheWarmSettle        = 2 * time.Minute // 最近一次同步完成后等待的时间，增量同步逐只股票记录任务，避免同步期间反复预热
+	cacheWarmConcurrency   = 8               // 预热行情的并发查询数
+)
+
+const (
+	cacheKeyStocks     = cache.KeyStocks
+	cacheKeyMarketBars = cache.KeyMarketBars
+	cacheKeyIndustries = cache.KeyIndustries
+)
+
+// cacheWarmJobTypes 完成后需要重新预热的同步任务
+var cacheWarmJobTypes = []string{
+	models.SyncJobStockList,
+	models.SyncJobStockMeta,
+	models.SyncJobDailyBars,
+	models.SyncJobRiskWarnings,
+	models.SyncJobUniverses,
+}
+
+// inTradingSession 是否处于A股交易时段（工作日 9:15-11:30、13:00-15:00，含集合竞价），不区分节假日
+func inTradingSession(t time.Time) bool {
+	t = t.In(markettime.Location(""))
+	if t.Weekday() == time.Saturday || t.Weekday() == time.Sunday {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	return (minute >= 9*60+15 && minute < 11*60+30) || (minute >= 13*60 && minute < 15*60)
+}
+
+// cacheTTL 缓存的当前有效期，name 为 stocks、market_bars、industries、quote、quote_trading
+func (s *MarketService) cacheTTL(name string, defaultTTL time.Duration) time.Duration {
+	return s.live.Get().Cache.TTL(name, defaultTTL)
+}
+
+// quoteTTL 个股行情的缓存有效期
+func (s *MarketService) quoteTTL(now time.Time) time.Duration {
+	if inTradingSession(now) {
+		return s.cacheTTL("quote_trading", quoteCacheTradingTTL)
+	}
+	return s.cacheTTL("quote", quoteCacheTTL)
+}
+
+// quoteCacheKey 个股行情缓存键
+func quoteCacheKey(symbol, exchange string) string {
+	return cache.QuoteKey(symbol, exchange)
+}
+
+// allStocks 全部股票，按代码排序
+func (s *MarketService) allStocks(ctx context.Context) ([]*models.Stock, error) {
+	return cache.GetOrLoad(ctx, s.cache, cacheKeyStocks, s.cacheTTL("stocks", stockListCacheTTL), s.loadAllStocks)
+}
+
+func (s *MarketService) loadAllStocks(ctx context.Context) ([]*models.Stock, error) {
+	stocks, _, err := s.stockRepo.ListStocks(ctx, repository.StockListQuery{})
+	return stocks, err
+}
+
+// latestMarketBars 全市场最近两个交易日的日K线，按 symbol.exchange 分组
+// 全市场查询是行情排序与行业汇总的冷路径，结果只保留计算涨跌幅所需的两根K线；查询失败时返回过期副本，stale 为 true。
+func (s *MarketService) latestMarketBars(ctx context.Context) (map[string][]*models.DailyBar, bool, error) {
+	return cache.GetOrLoadStale(ctx, s.cache, cacheKeyMarketBars, s.cacheTTL("market_bars", marketBarsCacheTTL), s.loadLatestMarketBars)
+}
+
+func (s *MarketService) loadLatestMarketBars(ctx context.Context) (map[string][]*models.DailyBar, error) {
+	end := time.Now()
+	bars, err := s.marketRepo.GetMarketDailyBars(ctx, end.AddDate(0, 0, -quoteLookbackDays), end)
+	if err != nil {
+		return nil, err
+	}
+	for key, series := range bars {
+		if len(series) > 2 {
+			bars[key] = series[len(series)-2:]
+		}
+	}
+	return bars, nil
+}
+
+// StartCacheWarmer 启动时预热缓存，之后定期检查同步任务，数据更新后重新预热
+// 未配置 Redis 时不启动。
+func (s *MarketService) StartCacheWarmer(ctx context.Context) {
+	if !s.cache.Enabled() {
+		return
+	}
+
+	go func() {
+		warmedAt := time.Now()
+		influxConnected := s.dbManager.Influx.Connected()
+		s.warmCache(ctx)
+
+		ticker := time.NewTicker(cacheWarmCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				// InfluxDB 恢复连接后立即重新预热
+				if connected := s.dbManager.Influx.Connected(); connected && !influxConnected {
+					influxConnected = true
+					warmedAt = time.Now()
+					s.warmCache(ctx)
+					continue
+				}
+				finishedAt, err := s.syncJobRepo.GetLatestFinishedAt(ctx, cacheWarmJobTypes...)
+				if err != nil {
+					log.Printf("查询同步任务失败: %v", err)
+					continue
+				}
+				if finishedAt == nil || !finishedAt.After(warmedAt) || time.Since(*finishedAt) < cacheWarmSettle {
+					continue
+				}
+				warmedAt = time.Now()
+				s.warmCache(ctx)
+			}
+		}
+	}()
+}
+
+// warmCache 预热股票列表、全市场最近行情、行业汇总与指数成分股的个股行情，完成后刷新实时行情推送
+func (s *MarketService) warmCache(ctx context.Context) {
+	start := time.Now()
+
+	stocks, err := cache.Refresh(ctx, s.cache, cacheKeyStocks, s.cacheTTL("stocks", stockListCacheTTL), s.loadAllStocks)
+	if err != nil {
+		log.Printf("预热股票列表失败: %v", err)
+		return
+	}
+	bars, err := cache.Refresh(ctx, s.cache, cacheKeyMarketBars, s.cacheTTL("market_bars", marketBarsCacheTTL), s.loadLatestMarketBars)
+	if err != nil {
+		log.Printf("预热全市场行情失败: %v", err)
+		return
+	}
+	if err := s.cache.Set(ctx, cacheKeyIndustries, aggregateIndustries(stocks, bars), s.cacheTTL("industries", industryCacheTTL)); err != nil {
+		log.Printf("预热行业汇总失败: %v", err)
+		return
+	}
+	quotes := s.warmQuotes(ctx)
+
+	log.Printf("缓存预热完成：%d 只股票，%d 只指数成分股行情，耗时 %s", len(stocks), quotes, time.Since(start).Round(time.Millisecond))
+
+	// 数据同步后立即推送有变化的订阅行情，不等待下次检查
+	s.quotes.Refresh()
+}
+
+// warmQuotes 预热指数类股票池最新成分股的个股行情，返回成功的数量
+func (s *MarketService) warmQuotes(ctx context.Context) int {
+	universes, err := s.universeRepo.GetActive(ctx)
+	if err != nil {
+		log.Printf("获取股票池失败: %v", err)
+		return 0
+	}
+
+	type stockCode struct{ symbol, exchange string }
+	var codes []stockCode
+	seen := make(map[stockCode]bool)
+	for _, universe := range universes {
+		if universe.Type != models.UniverseTypeIndex {
+			continue
+		}
+		date, err := s.universeRepo.GetLatestSnapshotDate(ctx, universe.ID, time.Now())
+		if err != nil || date == nil {
+			continue
+		}
+		members, err := s.universeRepo.GetMembers(ctx, universe.ID, *date)
+		if err != nil {
+			log.Printf("获取股票池 %d 成分失败: %v", universe.ID, err)
+			continue
+		}
+		for _, m := range members {
+			code := stockCode{m.Symbol, m.Exchange}
+			if !seen[code] {
+				seen[code] = true
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	var warmed atomic.Int64
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cacheWarmConcurrency)
+	for _, code := range codes {
+		wg.Add(1)
+		go func(code stockCode) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			_, err := cache.Refresh(ctx, s.cache, quoteCacheKey(code.symbol, code.exchange), s.quoteTTL(time.Now()),
+				func(ctx context.Context) (*QuoteResponse, error) {
+					return s.loadQuote(ctx, code.symbol, code.exchange)
+				})
+			if err == nil {
+				warmed.Add(1)
+			}
+		}(code)
+	}
+	wg.Wait()
+	return int(warmed.Load())
+}
+
+// ============ 行业汇总接口 ============
+
+// IndustryStat 行业最近一个交易日的汇总行情
+type IndustryStat struct {
+	Industry     string   `json:"industry"`
+	Stocks       int      `json:"stocks"`                   // 上市股票数
+	Up           int      `json:"up"`                       // 上涨家数
+	Down         int      `json:"down"`                     // 下跌家数
+	Flat         int      `json:"flat"`                     // 平盘家数
+	AvgChangePct *float64 `json:"avg_change_pct,omitempty"` // 等权平均涨跌幅（%），没有行情时为空
+	Amount       float64  `json:"amount"`                   // 成交额合计（元）
+	MarketCap    float64  `json:"market_cap"`               // 总市值合计（元），总股本未知的股票不计入
+}
+
+// aggregateIndustries 按行业汇总最近一个交易日的涨跌家数、成交额与市值，按成交额降序
+// 只统计上市状态的股票，未分类行业的股票不计入。
+func aggregateIndustries(stocks []*models.Stock, bars map[string][]*models.DailyBar) []*IndustryStat {
+	stats := make(map[string]*IndustryStat)
+	sums := make(map[string]float64)
+	counts := make(map[string]int)
+	for _, stock := range stocks {
+		if stock.Industry == "" || !stock.IsActive() {
+			continue
+		}
+		stat, ok := stats[stock.Industry]
+		if !ok {
+			stat = &IndustryStat{Industry: stock.Industry}
+			stats[stock.Industry] = stat
+		}
+		stat.Stocks++
+
+		snapshot := newStockSnapshot(stock, bars[stock.Symbol+"."+stock.Exchange])
+		if snapshot == nil {
+			continue
+		}
+		stat.Amount += snapshot.Amount
+		if snapshot.MarketCap != nil {
+			stat.MarketCap += *snapshot.MarketCap
+		}
+		if snapshot.ChangePct == nil {
+			continue
+		}
+		switch pct := *snapshot.ChangePct; {
+		case pct > 0:
+			stat.Up++
+		case pct < 0:
+			stat.Down++
+		default:
+			stat.Flat++
+		}
+		sums[stock.Industry] += *snapshot.ChangePct
+		counts[stock.Industry]++
+	}
+
+	list := make([]*IndustryStat, 0, len(stats))
+	for industry, stat := range stats {
+		if n := counts[industry]; n > 0 {
+			avg := sums[industry] / float64(n)
+			stat.AvgChangePct = &avg
+		}
+		list = append(list, stat)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Amount != list[j].Amount {
+			return list[i].Amount > list[j].Amount
+		}
+		return list[i].Industry < list[j].Industry
+	})
+	return list
+}
+
+// GetIndustries 行业汇总行情
+func (s *MarketService) GetIndustries(c *gin.Context) {
+	ctx := c.Request.Context()
+	list, stale, err := cache.GetOrLoadStale(ctx, s.cache, cacheKeyIndustries, s.cacheTTL("industries", industryCacheTTL), func(ctx context.Context) ([]*IndustryStat, error) {
+		stocks, err := s.allStocks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		bars, stale, err := s.latestMarketBars(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if stale {
+			return nil, errMarketDataStale
+		}
+		return aggregateIndustries(stocks, bars), nil
+	})
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	data := gin.H{
+		"list":  list,
+		"total": len(list),
+	}
+	if stale {
+		data["degraded"] = true
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": data,
+	})
+}
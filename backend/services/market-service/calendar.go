@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 公司事件日历接口 ============
+
+// 日历查询跨度（天）
+const (
+	defaultCalendarDays = 30
+	maxCalendarDays     = 366
+)
+
+// CalendarRequest 公司事件日历查询请求
+type CalendarRequest struct {
+	From     string `form:"from"`   // YYYY-MM-DD，默认今天
+	To       string `form:"to"`     // 默认 from 之后 30 天
+	Symbol   string `form:"symbol"` // 000001 或 000001.SZ
+	Type     string `form:"type"`   // 事件类型，多个用逗号分隔
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// GetCalendar 查询日期范围内的公司事件（预约披露日、股东大会、限售股解禁），按日期升序
+func (s *MarketService) GetCalendar(c *gin.Context) {
+	var req CalendarRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	q := repository.CalendarQuery{Page: req.Page, PageSize: req.PageSize}
+	var err error
+	if q.Start, err = time.Parse(validation.DateLayout, markettime.Today("")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "获取当前日期失败"})
+		return
+	}
+	if req.From != "" {
+		if q.Start, err = time.Parse(validation.DateLayout, req.From); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "from 格式错误，应为 YYYY-MM-DD"})
+			return
+		}
+	}
+	q.End = q.Start.AddDate(0, 0, defaultCalendarDays)
+	if req.To != "" {
+		if q.End, err = time.Parse(validation.DateLayout, req.To); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "to 格式错误，应为 YYYY-MM-DD"})
+			return
+		}
+	}
+	if q.End.Before(q.Start) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "to 不能早于 from"})
+		return
+	}
+	if q.End.Sub(q.Start) > maxCalendarDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "查询跨度不能超过 366 天"})
+		return
+	}
+
+	if q.Symbol, q.Exchange, err = validation.ParseSymbolQuery(req.Symbol); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	for _, t := range strings.Split(req.Type, ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if _, ok := models.CorporateEventTypes[t]; !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "不支持的事件类型: " + t})
+			return
+		}
+		q.Types = append(q.Types, t)
+	}
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PageSize < 1 || q.PageSize > 100 {
+		q.PageSize = 20
+	}
+
+	events, total, err := s.calendarRepo.Query(c.Request.Context(), q)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"from":      q.Start.Format(validation.DateLayout),
+			"to":        q.End.Format(validation.DateLayout),
+			"list":      events,
+			"total":     total,
+			"page":      q.Page,
+			"page_size": q.PageSize,
+		},
+	})
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 沪深港通接口 ============
+
+// 沪深港通资金与北向持股查询区间（天）
+var connectRangeRule = validation.RangeRule{DefaultDays: 90, MaxDays: 365 * 5}
+
+// ConnectFlowRequest 沪深港通资金请求
+type ConnectFlowRequest struct {
+	Direction string `form:"direction,default=north"` // north/south
+	Start     string `form:"start"`                   // YYYY-MM-DD，默认最近90天
+	End       string `form:"end"`
+}
+
+// ConnectFlowDay 某一方向的单日资金汇总
+type ConnectFlowDay struct {
+	Date         string             `json:"date"`
+	NetInflow    float64            `json:"net_inflow"`     // 各通道成交净买入合计（元）
+	BuyAmount    float64            `json:"buy_amount"`     // 买入成交额合计（元）
+	SellAmount   float64            `json:"sell_amount"`    // 卖出成交额合计（元）
+	CumNetInflow float64            `json:"cum_net_inflow"` // 区间内累计净买入（元）
+	Channels     map[string]float64 `json:"channels"`       // 各通道成交净买入，sh/sz
+}
+
+// GetConnectFlow 获取沪深港通南向或北向的每日成交资金，按日期升序汇总各通道并计算区间累计净买入
+func (s *MarketService) GetConnectFlow(c *gin.Context) {
+	var req ConnectFlowRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if req.Direction != models.ConnectNorthbound && req.Direction != models.ConnectSouthbound {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "direction 只支持 north 或 south"})
+		return
+	}
+	r, err := validation.ParseDateRange(req.Start, req.End, connectRangeRule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	flows, err := s.connectRepo.GetFlows(ctx, req.Direction, r.Start, r.End)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	days := []*ConnectFlowDay{}
+	for _, flow := range flows {
+		date := flow.Date.Format(validation.DateLayout)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, &ConnectFlowDay{Date: date, Channels: map[string]float64{}})
+		}
+		day := days[len(days)-1]
+		day.NetInflow += flow.NetInflow
+		day.BuyAmount += flow.BuyAmount
+		day.SellAmount += flow.SellAmount
+		day.Channels[flow.Channel] += flow.NetInflow
+	}
+	cum := 0.0
+	for _, day := range days {
+		cum += day.NetInflow
+		day.CumNetInflow = cum
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"direction": req.Direction,
+			"start":     r.Start.Format(validation.DateLayout),
+			"end":       r.End.Format(validation.DateLayout),
+			"list":      days,
+			"count":     len(days),
+			"meta":      s.provenance(ctx, models.SyncJobStockConnect, "", ""),
+		},
+	})
+}
+
+// NorthboundHoldingRequest 北向个股持股请求
+type NorthboundHoldingRequest struct {
+	Symbol   string `uri:"symbol" binding:"required"`
+	Exchange string `form:"exchange,default=SZ"`
+	Start    string `form:"start"` // YYYY-MM-DD，默认最近90天
+	End      string `form:"end"`
+}
+
+// NorthboundHoldingDay 北向个股单日持股及较上一交易日的变化
+type NorthboundHoldingDay struct {
+	*models.NorthboundHolding
+	SharesChange *int64   `json:"shares_change"` // 持股数变化，区间首日为 null
+	RatioChange  *float64 `json:"ratio_change"`  // 持股比例变化（百分点），区间首日为 null
+}
+
+// GetNorthboundHoldings 获取北向资金个股持股明细，按日期升序并给出逐日增减
+func (s *MarketService) GetNorthboundHoldings(c *gin.Context) {
+	var req NorthboundHoldingRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	r, err := validation.ParseDateRange(req.Start, req.End, connectRangeRule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	holdings, err := s.connectRepo.GetHoldings(ctx, req.Symbol, req.Exchange, r.Start, r.End)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	list := make([]NorthboundHoldingDay, len(holdings))
+	for i, h := range holdings {
+		list[i].NorthboundHolding = h
+		if i > 0 {
+			shares := h.Shares - holdings[i-1].Shares
+			ratio := h.Ratio - holdings[i-1].Ratio
+			list[i].SharesChange = &shares
+			list[i].RatioChange = &ratio
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"symbol":   req.Symbol,
+			"exchange": req.Exchange,
+			"list":     list,
+			"count":    len(list),
+			"meta":     s.provenance(ctx, models.SyncJobStockConnect, "", ""),
+		},
+	})
+}
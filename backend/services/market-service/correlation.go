@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/risk"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 相关性接口 ============
+
+// CorrelationRequest 两只股票相关系数与 Beta 请求
+type CorrelationRequest struct {
+	Symbols string `form:"symbols" binding:"required"` // A,B，格式 symbol.exchange
+	Window  int    `form:"window,default=120"`         // 滚动窗口（交易日）
+	Start   string `form:"start"`                      // YYYY-MM-DD，默认最近一年
+	End     string `form:"end"`
+}
+
+// GetCorrelation 计算两只股票日收益率的相关系数与 Beta（A 相对 B），并给出滚动序列
+func (s *MarketService) GetCorrelation(c *gin.Context) {
+	var req CorrelationRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	keys := strings.Split(req.Symbols, ",")
+	if len(keys) != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "symbols 需要两只股票，如 600519.SH,000858.SZ"})
+		return
+	}
+	if req.Window < 20 || req.Window > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "window 应在 20~500 之间"})
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 365,
+		MaxDays:     365 * 5,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	start := dateRange.Start.Format(validation.DateLayout)
+
+	// 向前多取数据，使区间首日即有完整的滚动窗口（交易日约为自然日的 70%）
+	ctx := c.Request.Context()
+	fetchStart := dateRange.Start.AddDate(0, 0, -req.Window*2)
+	closes := make([]map[string]float64, 2)
+	for i, key := range keys {
+		symbol, exchange, ok := strings.Cut(strings.TrimSpace(key), ".")
+		if !ok || symbol == "" || exchange == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "股票格式错误，应为 symbol.exchange: " + key})
+			return
+		}
+		keys[i] = symbol + "." + exchange
+
+		bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, fetchStart, dateRange.End)
+		if err != nil {
+			respondQueryError(c, err)
+			return
+		}
+		closes[i] = risk.ClosesByDate(bars)
+	}
+
+	dates, ra, rb := risk.AlignedReturns(closes[0], closes[1])
+
+	// 区间内的整体统计
+	from := len(dates)
+	for i, date := range dates {
+		if date >= start {
+			from = i
+			break
+		}
+	}
+	if len(dates)-from < 2 {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": fmt.Sprintf("%s 与 %s 在区间内没有足够的共同交易日", keys[0], keys[1])})
+		return
+	}
+
+	var rolling []*risk.RollingPoint
+	for _, point := range risk.Rolling(dates, ra, rb, req.Window) {
+		if point.Date >= start {
+			rolling = append(rolling, point)
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"symbols":      keys,
+			"window":       req.Window,
+			"start":        start,
+			"end":          dateRange.End.Format(validation.DateLayout),
+			"observations": len(dates) - from,
+			"correlation":  risk.Correlation(ra[from:], rb[from:]),
+			"beta":         risk.Beta(ra[from:], rb[from:]),
+			"rolling":      rolling,
+		},
+	})
+}
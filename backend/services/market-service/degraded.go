@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/database"
+)
+
+// ============ 降级 ============
+//
+// InfluxDB 是可选依赖：启动时连接失败不影响服务启动，后台重连期间查询直接返回 database.ErrInfluxUnavailable。
+// 有缓存的接口（个股行情、按行情排序的股票列表、行业汇总）回源失败时返回最近一次成功的结果，并在响应中标记 degraded；
+// 没有可用结果时返回 503。
+
+// errMarketDataStale 全市场行情只有过期副本，不用于计算新的汇总结果
+var errMarketDataStale = errors.New("全市场行情暂不可用")
+
+// respondQueryError 查询失败的响应：InfluxDB 不可用时返回 503，其余返回 500
+func respondQueryError(c *gin.Context, err error) {
+	if errors.Is(err, database.ErrInfluxUnavailable) || errors.Is(err, errMarketDataStale) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "msg": "行情数据暂不可用，请稍后重试"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败: " + err.Error()})
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/factor"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 因子接口 ============
+
+// GetFactors 获取因子定义及最近一次计算的交易日
+func (s *MarketService) GetFactors(c *gin.Context) {
+	latest, err := s.factorRepo.GetLatestTradeDate(c.Request.Context(), time.Now())
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	var latestDate string
+	if latest != nil {
+		latestDate = latest.Format("2006-01-02")
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"factors":     factor.Definitions(),
+			"latest_date": latestDate,
+		},
+	})
+}
+
+// FactorRankingRequest 因子排名请求
+type FactorRankingRequest struct {
+	Factors  string `form:"factors,default=momentum"` // 逗号分隔，多个因子时按权重合成
+	Weights  string `form:"weights"`                  // 逗号分隔，与 factors 一一对应，默认等权
+	Date     string `form:"date"`                     // YYYY-MM-DD，默认最近一次计算的交易日
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=50"`
+}
+
+// GetFactorRanking 获取单因子或多因子合成排名
+func (s *MarketService) GetFactorRanking(c *gin.Context) {
+	var req FactorRankingRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 200 {
+		req.PageSize = 50
+	}
+
+	names := strings.Split(req.Factors, ",")
+	weights := make(map[string]float64, len(names))
+	var weightValues []string
+	if req.Weights != "" {
+		weightValues = strings.Split(req.Weights, ",")
+		if len(weightValues) != len(names) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "weights 数量应与 factors 一致"})
+			return
+		}
+	}
+	for i, name := range names {
+		name = strings.TrimSpace(name)
+		if _, ok := factor.Lookup(name); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "不支持的因子: " + name})
+			return
+		}
+		names[i] = name
+		weights[name] = 1 / float64(len(names))
+		if weightValues != nil {
+			w, err := strconv.ParseFloat(strings.TrimSpace(weightValues[i]), 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "权重格式错误: " + weightValues[i]})
+				return
+			}
+			weights[name] = w
+		}
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "日期格式错误"})
+			return
+		}
+		date = t
+	}
+
+	ctx := c.Request.Context()
+	tradeDate, err := s.factorRepo.GetLatestTradeDate(ctx, date)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+	if tradeDate == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "暂无因子得分"})
+		return
+	}
+
+	data := gin.H{
+		"trade_date": tradeDate.Format("2006-01-02"),
+		"factors":    names,
+		"weights":    weights,
+		"page":       req.Page,
+		"page_size":  req.PageSize,
+	}
+
+	// 单因子直接分页查询排名
+	if len(names) == 1 && req.Weights == "" {
+		scores, total, err := s.factorRepo.GetRanking(ctx, *tradeDate, names[0], req.Page, req.PageSize)
+		if err != nil {
+			respondQueryError(c, err)
+			return
+		}
+		data["list"] = scores
+		data["total"] = total
+		c.JSON(http.StatusOK, gin.H{"code": 0, "data": data})
+		return
+	}
+
+	// 多因子按权重合成后排名
+	scores, err := s.factorRepo.GetScores(ctx, *tradeDate, names)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+	composite := factor.Composite(scores, weights)
+
+	start := (req.Page - 1) * req.PageSize
+	if start > len(composite) {
+		start = len(composite)
+	}
+	end := start + req.PageSize
+	if end > len(composite) {
+		end = len(composite)
+	}
+	data["list"] = composite[start:end]
+	data["total"] = len(composite)
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": data})
+}
+
+// StockFactorsRequest 个股因子得分请求
+type StockFactorsRequest struct {
+	Symbol   string `uri:"symbol" binding:"required"`
+	Exchange string `form:"exchange,default=SZ"`
+	Start    string `form:"start"` // YYYY-MM-DD，默认最近90天
+	End      string `form:"end"`
+}
+
+// GetStockFactors 获取个股因子得分历史
+func (s *MarketService) GetStockFactors(c *gin.Context) {
+	var req StockFactorsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 90,
+		MaxDays:     365 * 5,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	scores, err := s.factorRepo.GetSymbolHistory(c.Request.Context(), req.Symbol, req.Exchange, dateRange.Start, dateRange.End)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"symbol":   req.Symbol,
+			"exchange": req.Exchange,
+			"scores":   scores,
+			"count":    len(scores),
+		},
+	})
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 多股票指标对比接口 ============
+
+const (
+	maxCompareSymbols  = 20 // 单次对比的股票数上限
+	compareConcurrency = 8  // 并发查询的股票数
+)
+
+// CompareIndicatorsRequest 多股票同一指标对比请求
+type CompareIndicatorsRequest struct {
+	Symbols       string `form:"symbols" binding:"required"` // 逗号分隔的 symbol.exchange，如 600519.SH,000858.SZ
+	IndicatorType string `form:"type,default=rsi"`           // 日线指标类型，不支持 vwap、twap
+	Periods       string `form:"periods"`                    // 与单只股票指标接口相同
+	Period        int    `form:"period"`
+	Start         string `form:"start"`
+	End           string `form:"end"`
+}
+
+// CompareIndicators 返回多只股票同一指标按交易日对齐的矩阵
+// 每只股票并发查询，参数为默认参数时读取预计算结果，否则实时计算；某股票在该日无数据时对应字段为 null。
+func (s *MarketService) CompareIndicators(c *gin.Context) {
+	var req CompareIndicatorsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	keys, err := parseCompareSymbols(req.Symbols)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	req.IndicatorType = strings.ToLower(strings.TrimSpace(req.IndicatorType))
+	if indicator.IsIntraday(req.IndicatorType) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "对比接口只支持日线指标: " + strings.Join(indicatorTypes, ",")})
+		return
+	}
+	params, err := parseIndicatorParams(req.IndicatorType, req.Periods, req.Period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 120,
+		MaxDays:     365 * 5,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tables := make([]*indicatorTable, len(keys))
+	errs := make([]error, len(keys))
+	sem := make(chan struct{}, compareConcurrency)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key [2]string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			tables[i], _, errs[i] = s.loadIndicatorTable(ctx, key[0], key[1], req.IndicatorType, params, dateRange.Start, dateRange.End)
+		}(i, key)
+	}
+	wg.Wait()
+
+	symbols := make([]string, len(keys))
+	for i, key := range keys {
+		symbols[i] = key[0] + "." + key[1]
+		if errs[i] != nil {
+			respondQueryError(c, fmt.Errorf("%s: %w", symbols[i], errs[i]))
+			return
+		}
+	}
+
+	// 各股票使用同一组参数，数据来源相同
+	source := "computed"
+	if defaults, _ := indicator.DefaultParams(req.IndicatorType); indicator.SameParams(params, defaults) {
+		source = "stored"
+	}
+	columns, _ := indicator.Columns(req.IndicatorType, params)
+	series := alignCompareTables(symbols, tables)
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"type":    req.IndicatorType,
+			"params":  indicator.DescribeParams(req.IndicatorType, params),
+			"source":  source,
+			"columns": columns,
+			"symbols": symbols,
+			"series":  series,
+			"count":   len(series),
+		},
+	})
+}
+
+// parseCompareSymbols 解析并去重 symbol.exchange 列表，交易所必填
+func parseCompareSymbols(value string) ([][2]string, error) {
+	var keys [][2]string
+	seen := make(map[string]bool)
+	for _, raw := range strings.Split(value, ",") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		symbol, exchange, err := validation.ParseSymbolQuery(raw)
+		if err != nil {
+			return nil, err
+		}
+		if exchange == "" {
+			return nil, fmt.Errorf("股票格式错误，应为 symbol.exchange: %s", strings.TrimSpace(raw))
+		}
+		if key := symbol + "." + exchange; !seen[key] {
+			seen[key] = true
+			keys = append(keys, [2]string{symbol, exchange})
+		}
+	}
+	if len(keys) < 2 || len(keys) > maxCompareSymbols {
+		return nil, fmt.Errorf("symbols 需要 2-%d 只股票，如 600519.SH,000858.SZ", maxCompareSymbols)
+	}
+	return keys, nil
+}
+
+// alignCompareTables 将各股票的指标按交易日合并，每行包含 time 与以 symbol.exchange 为键的子对象（列名 -> 值），日期升序
+func alignCompareTables(symbols []string, tables []*indicatorTable) []gin.H {
+	rows := make(map[string]gin.H)
+	for i, t := range tables {
+		for k, row := range t.rows {
+			date := t.times[k].Format(validation.DateLayout)
+			out, ok := rows[date]
+			if !ok {
+				out = gin.H{"time": date}
+				for _, symbol := range symbols {
+					out[symbol] = nil
+				}
+				rows[date] = out
+			}
+			values := make(gin.H, len(t.names))
+			for j, name := range t.names {
+				values[name] = jsonFloat(row[j])
+			}
+			out[symbols[i]] = values
+		}
+	}
+
+	dates := make([]string, 0, len(rows))
+	for date := range rows {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	series := make([]gin.H, len(dates))
+	for i, date := range dates {
+		series[i] = rows[date]
+	}
+	return series
+}
@@ -0,0 +1,364 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/series"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 批量技术指标接口 ============
+
+// indicatorTypes 支持的指标类型
+var indicatorTypes = []string{"ma", "macd", "rsi", "kdj", "boll", "atr", "cci", "obv", "dmi", "wr", "bias"}
+
+// AllIndicatorsRequest 批量技术指标请求
+type AllIndicatorsRequest struct {
+	Symbol   string `uri:"symbol" binding:"required"`
+	Exchange string `form:"exchange,default=SZ"`
+	Types    string `form:"types"` // 逗号分隔，默认全部类型
+	Start    string `form:"start"`
+	End      string `form:"end"`
+}
+
+// GetAllIndicators 一次返回多种技术指标
+// 每种类型并发查询一次 InfluxDB，结果按交易日对齐；某类型在该日无数据时对应字段为 null。
+func (s *MarketService) GetAllIndicators(c *gin.Context) {
+	var req AllIndicatorsRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	types, err := parseIndicatorTypes(req.Types)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 120,
+		MaxDays:     365 * 20,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	results := make([][]*models.Indicator, len(types))
+	errs := make([]error, len(types))
+	var wg sync.WaitGroup
+	for i, t := range types {
+		wg.Add(1)
+		go func(i int, t string) {
+			defer wg.Done()
+			results[i], errs[i] = s.marketRepo.GetIndicators(ctx, req.Symbol, req.Exchange, t, dateRange.Start, dateRange.End)
+		}(i, t)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			respondQueryError(c, fmt.Errorf("%s: %w", types[i], err))
+			return
+		}
+	}
+
+	if format := negotiateSeries(c); format != "" {
+		respondSeries(c, format, alignIndicatorSeries(req.Symbol, req.Exchange, types, results))
+		return
+	}
+
+	series := alignIndicators(types, results)
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"symbol":   req.Symbol,
+			"exchange": req.Exchange,
+			"types":    types,
+			"series":   series,
+			"count":    len(series),
+		},
+	})
+}
+
+// parseIndicatorTypes 解析并去重指标类型，为空时返回全部类型
+func parseIndicatorTypes(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return indicatorTypes, nil
+	}
+	var types []string
+	seen := make(map[string]bool)
+	for _, t := range strings.Split(value, ",") {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if indicatorFields(t, &models.Indicator{}) == nil {
+			return nil, fmt.Errorf("不支持的指标类型: %s，可选 %s", t, strings.Join(indicatorTypes, ","))
+		}
+		seen[t] = true
+		types = append(types, t)
+	}
+	return types, nil
+}
+
+// alignIndicators 将各类型的指标序列按交易日合并，日期升序
+func alignIndicators(types []string, results [][]*models.Indicator) []gin.H {
+	rows := make(map[string]gin.H)
+	for i, t := range types {
+		for _, ind := range results[i] {
+			date := ind.Date.Format(validation.DateLayout)
+			row, ok := rows[date]
+			if !ok {
+				row = gin.H{"time": date}
+				for _, name := range types {
+					row[name] = nil
+				}
+				rows[date] = row
+			}
+			row[t] = indicatorFields(t, ind)
+		}
+	}
+
+	dates := make([]string, 0, len(rows))
+	for date := range rows {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	series := make([]gin.H, len(dates))
+	for i, date := range dates {
+		series[i] = rows[date]
+	}
+	return series
+}
+
+// indicatorFields 取出指定类型的指标字段，类型不支持时返回 nil
+func indicatorFields(indicatorType string, ind *models.Indicator) map[string]float64 {
+	switch indicatorType {
+	case "ma":
+		return map[string]float64{"ma5": ind.MA5, "ma10": ind.MA10, "ma20": ind.MA20, "ma60": ind.MA60}
+	case "macd":
+		return map[string]float64{"macd": ind.MACD, "signal": ind.MACDSignal, "hist": ind.MACDHist}
+	case "rsi":
+		return map[string]float64{"rsi6": ind.RSI6, "rsi12": ind.RSI12, "rsi24": ind.RSI24}
+	case "kdj":
+		return map[string]float64{"k": ind.K, "d": ind.D, "j": ind.J}
+	case "boll":
+		return map[string]float64{"upper": ind.BollUpper, "mid": ind.BollMid, "lower": ind.BollLower}
+	case "atr":
+		return map[string]float64{"atr": ind.ATR}
+	case "cci":
+		return map[string]float64{"cci": ind.CCI}
+	case "obv":
+		return map[string]float64{"obv": ind.OBV}
+	case "dmi":
+		return map[string]float64{"pdi": ind.PDI, "mdi": ind.MDI, "adx": ind.ADX}
+	case "wr":
+		return map[string]float64{"wr": ind.WR}
+	case "bias":
+		return map[string]float64{"bias": ind.BIAS}
+	}
+	return nil
+}
+
+// ============ 单类型指标参数 ============
+
+// parseIndicatorParams 解析 periods（逗号分隔）或 period，均未传时使用类型的默认参数
+func parseIndicatorParams(indicatorType, periods string, period int) ([]int, error) {
+	params, ok := indicator.DefaultParams(indicatorType)
+	if !ok {
+		return nil, fmt.Errorf("不支持的指标类型: %s", indicatorType)
+	}
+	switch {
+	case strings.TrimSpace(periods) != "":
+		params = nil
+		for _, v := range strings.Split(periods, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("periods 应为逗号分隔的整数: %s", periods)
+			}
+			params = append(params, n)
+		}
+	case period != 0:
+		params = []int{period}
+	}
+	if _, err := indicator.Columns(indicatorType, params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// indicatorTable 单类型指标按列名对齐的结果，rows[i] 与 names 一一对应
+type indicatorTable struct {
+	indicatorType string
+	names         []string
+	times         []time.Time
+	rows          [][]float64
+}
+
+// rowsJSON 每行包含 time 与以指标类型为键的子对象（列名 -> 值），如 {"time": ..., "macd": {"macd": ..., "signal": ..., "hist": ...}}，
+// 与批量指标接口的结构一致；缺失的值为 null
+func (t *indicatorTable) rowsJSON() []gin.H {
+	out := make([]gin.H, len(t.times))
+	for i, row := range t.rows {
+		values := make(gin.H, len(t.names))
+		for j, name := range t.names {
+			values[name] = jsonFloat(row[j])
+		}
+		out[i] = gin.H{"time": t.times[i].Format(validation.DateLayout), t.indicatorType: values}
+	}
+	return out
+}
+
+// jsonFloat NaN 与无穷大无法编码为 JSON，以 null 表示
+func jsonFloat(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil
+	}
+	return v
+}
+
+// loadIndicatorTable 参数与预计算保存的默认参数相同时读取 InfluxDB（source 为 stored），否则由日K线实时计算（computed）
+func (s *MarketService) loadIndicatorTable(ctx context.Context, symbol, exchange, indicatorType string, params []int, start, end time.Time) (table *indicatorTable, source string, err error) {
+	defaults, _ := indicator.DefaultParams(indicatorType)
+	if indicator.SameParams(params, defaults) {
+		table, err = s.storedIndicatorTable(ctx, symbol, exchange, indicatorType, params, start, end)
+		return table, "stored", err
+	}
+	table, err = s.computeIndicatorTable(ctx, symbol, exchange, indicatorType, params, start, end)
+	return table, "computed", err
+}
+
+// storedIndicatorTable 读取按默认参数预计算保存的指标
+func (s *MarketService) storedIndicatorTable(ctx context.Context, symbol, exchange, indicatorType string, params []int, start, end time.Time) (*indicatorTable, error) {
+	names, err := indicator.Columns(indicatorType, params)
+	if err != nil {
+		return nil, err
+	}
+	indicators, err := s.marketRepo.GetIndicators(ctx, symbol, exchange, indicatorType, start, end)
+	if err != nil {
+		return nil, err
+	}
+	t := &indicatorTable{indicatorType: indicatorType, names: names}
+	for _, ind := range indicators {
+		fields := indicatorFields(indicatorType, ind)
+		row := make([]float64, len(names))
+		for j, name := range names {
+			row[j] = fields[name]
+		}
+		t.times = append(t.times, ind.Date)
+		t.rows = append(t.rows, row)
+	}
+	return t, nil
+}
+
+// computeIndicatorTable 按指定参数由日K线实时计算指标，向前多取预热数据，只保留各列均有值的交易日
+func (s *MarketService) computeIndicatorTable(ctx context.Context, symbol, exchange, indicatorType string, params []int, start, end time.Time) (*indicatorTable, error) {
+	bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, start.AddDate(0, 0, -indicator.WarmupDays(indicatorType, params)), end)
+	if err != nil {
+		return nil, err
+	}
+	bs := indicator.FromDailyBars(bars)
+	names, columns, err := indicator.Evaluate(indicatorType, params, bs)
+	if err != nil {
+		return nil, err
+	}
+	t := &indicatorTable{indicatorType: indicatorType, names: names}
+	for i, date := range bs.Time {
+		if date.Before(start) {
+			continue
+		}
+		row := make([]float64, len(names))
+		ready := true
+		for j := range names {
+			row[j] = columns[j][i]
+			ready = ready && !math.IsNaN(row[j]) && !math.IsInf(row[j], 0)
+		}
+		if ready {
+			t.times = append(t.times, date)
+			t.rows = append(t.rows, row)
+		}
+	}
+	return t, nil
+}
+
+// ============ 分时均价指标 ============
+
+// getIntradayIndicator 由分钟K线计算分时 VWAP/TWAP，每个交易日从开盘重新累计
+// 未传开始日期时取结束日期当天，最大跨度与同周期的分钟K线查询相同。
+func (s *MarketService) getIntradayIndicator(c *gin.Context, req *IndicatorRequest) {
+	rule, err := validation.PeriodRule(req.Interval)
+	if err != nil || req.Interval == "1d" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "interval 应为分钟周期: 1m, 5m, 15m, 30m, 60m"})
+		return
+	}
+	rule.Required = false
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if req.Start == "" {
+		dateRange.Start = dateRange.End.Truncate(24 * time.Hour)
+	}
+	start, end := dateRange.InExchange(req.Exchange)
+
+	bars, err := s.marketRepo.GetMinuteBars(c.Request.Context(), req.Symbol, req.Exchange, req.Interval, start, end)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+	bs := indicator.FromMinuteBars(bars)
+	values := indicator.Intraday(req.IndicatorType, bs, markettime.Location(req.Exchange))
+
+	if format := negotiateSeries(c); format != "" {
+		out := series.NewIndicators(req.Symbol, req.Exchange, []string{req.IndicatorType}, len(values))
+		for i, v := range values {
+			out.Append(bs.Time[i], v)
+		}
+		respondSeries(c, format, out)
+		return
+	}
+
+	data := make([]gin.H, 0, len(values))
+	for i, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+		data = append(data, gin.H{
+			"time":            markettime.FormatMinute(bs.Time[i], req.Exchange),
+			req.IndicatorType: gin.H{req.IndicatorType: v},
+		})
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"symbol":     req.Symbol,
+			"exchange":   req.Exchange,
+			"type":       req.IndicatorType,
+			"interval":   req.Interval,
+			"columns":    []string{req.IndicatorType},
+			"indicators": data,
+			"count":      len(data),
+		},
+	})
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 宏观序列接口 ============
+
+// 宏观序列查询区间（天）
+var macroRangeRule = validation.RangeRule{DefaultDays: 365 * 3, MaxDays: 365 * 30}
+
+// MacroSeriesItem 宏观序列定义与最新值
+type MacroSeriesItem struct {
+	models.MacroSeriesInfo
+	Latest *models.MacroPoint `json:"latest"` // 未同步时为 null
+}
+
+// GetMacroSeriesList 获取支持的宏观序列及各序列最新值
+func (s *MarketService) GetMacroSeriesList(c *gin.Context) {
+	latest, err := s.macroRepo.GetLatest(c.Request.Context())
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	items := make([]MacroSeriesItem, 0, len(models.MacroSeries))
+	for _, info := range models.MacroSeries {
+		items = append(items, MacroSeriesItem{MacroSeriesInfo: info, Latest: latest[info.Name]})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": items,
+	})
+}
+
+// MacroSeriesRequest 宏观序列查询请求
+type MacroSeriesRequest struct {
+	Names string `form:"names" binding:"required"` // 逗号分隔，如 cpi_yoy,pmi
+	Start string `form:"start"`                    // YYYY-MM-DD，默认最近 3 年
+	End   string `form:"end"`
+}
+
+// GetMacroSeries 查询一个或多个宏观序列，各序列按日期升序分别返回，供图表叠加与策略使用
+func (s *MarketService) GetMacroSeries(c *gin.Context) {
+	var req MacroSeriesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	r, err := validation.ParseDateRange(req.Start, req.End, macroRangeRule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	var names []string
+	for _, name := range strings.Split(req.Names, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if _, ok := models.LookupMacroSeries(name); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "不支持的宏观序列: " + name})
+			return
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "names 不能为空"})
+		return
+	}
+
+	series := make(map[string][]*models.MacroPoint, len(names))
+	for _, name := range names {
+		points, err := s.macroRepo.GetSeries(c.Request.Context(), name, r.Start, r.End)
+		if err != nil {
+			respondQueryError(c, err)
+			return
+		}
+		if points == nil {
+			points = []*models.MacroPoint{}
+		}
+		series[name] = points
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"start":  r.Start.Format(validation.DateLayout),
+			"end":    r.End.Format(validation.DateLayout),
+			"series": series,
+		},
+	})
+}
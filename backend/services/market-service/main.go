@@ -2,55 +2,110 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
-	"os/signal"
-	"strconv"
-	"syscall"
+	"sort"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/gin-gonic/gin/binding"
-	"github.com/go-playground/validator/v10"
 
+	"stock-analysis-system/backend/pkg/cache"
 	"stock-analysis-system/backend/pkg/config"
 	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/middleware"
 	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pricelimit"
+	"stock-analysis-system/backend/pkg/quotestream"
 	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/screener"
+	"stock-analysis-system/backend/pkg/series"
+	"stock-analysis-system/backend/pkg/server"
+	"stock-analysis-system/backend/pkg/validation"
 )
 
 // MarketService 行情服务
 type MarketService struct {
-	cfg        *config.Config
-	dbManager  *database.Manager
-	stockRepo  repository.StockRepository
-	marketRepo repository.MarketRepository
+	cfg             *config.Config
+	dbManager       *database.Manager
+	stockRepo       repository.StockRepository
+	marketRepo      repository.MarketRepository
+	dragonTigerRepo repository.DragonTigerRepository
+	newsRepo        repository.NewsRepository
+	syncJobRepo     repository.SyncJobRepository
+	factorRepo      repository.FactorRepository
+	universeRepo    repository.UniverseRepository
+	calendarRepo    repository.CalendarRepository
+	macroRepo       repository.MacroRepository
+	connectRepo     repository.StockConnectRepository
+	cache           *cache.Cache // 未配置 Redis 时为 nil
+	klines          *cache.Coalescer
+	live            *config.Live // 可热更新的日志级别与缓存有效期
+	quotes          *quotestream.Hub
 }
 
 // NewMarketService 创建行情服务
 func NewMarketService(cfg *config.Config) (*MarketService, error) {
+	// 行情时间按交易所时区计算
+	if err := markettime.Configure(cfg.Calendar); err != nil {
+		return nil, err
+	}
+
 	// 创建数据库管理器
-	dbManager, err := database.NewManager(&cfg.Database)
+	// InfluxDB 故障时仍然启动，以降级模式提供缓存中的行情
+	dbManager, err := database.NewManager(&cfg.Database, database.OptionalInflux())
 	if err != nil {
 		return nil, err
 	}
 
+	// 配置文件热更新
+	live, err := config.NewLive(cfg)
+	if err != nil {
+		dbManager.Close()
+		return nil, err
+	}
+	live.OnChange(func(c *config.Config) { dbManager.SetLogLevel(c.Log.Level) })
+
 	// 创建仓库
 	stockRepo := repository.NewStockRepository(dbManager.Postgres.DB)
 	marketRepo := repository.NewMarketRepository(dbManager.Influx)
-
-	return &MarketService{
-		cfg:        cfg,
-		dbManager:  dbManager,
-		stockRepo:  stockRepo,
-		marketRepo: marketRepo,
-	}, nil
+	dragonTigerRepo := repository.NewDragonTigerRepository(dbManager.Postgres.DB)
+	newsRepo := repository.NewNewsRepository(dbManager.Postgres.DB)
+	syncJobRepo := repository.NewSyncJobRepository(dbManager.Postgres.DB)
+	factorRepo := repository.NewFactorRepository(dbManager.Postgres.DB)
+	universeRepo := repository.NewUniverseRepository(dbManager.Postgres.DB)
+
+	service := &MarketService{
+		cfg:             cfg,
+		dbManager:       dbManager,
+		stockRepo:       stockRepo,
+		marketRepo:      marketRepo,
+		dragonTigerRepo: dragonTigerRepo,
+		newsRepo:        newsRepo,
+		syncJobRepo:     syncJobRepo,
+		factorRepo:      factorRepo,
+		universeRepo:    universeRepo,
+		calendarRepo:    repository.NewCalendarRepository(dbManager.Postgres.DB),
+		macroRepo:       repository.NewMacroRepository(dbManager.Influx),
+		connectRepo:     repository.NewStockConnectRepository(dbManager.Influx),
+		klines:          cache.NewCoalescer("kline"),
+		live:            live,
+	}
+	if dbManager.Redis != nil {
+		service.cache = cache.New(dbManager.Redis.GetClient(), cache.MarketPrefix)
+	}
+	service.quotes = quotestream.NewHub("market-service", service.streamQuote, quotePollInterval)
+	return service, nil
 }
 
 // Close 关闭服务
 func (s *MarketService) Close() {
+	s.quotes.Close()
+	s.live.Close()
 	if s.dbManager != nil {
 		s.dbManager.Close()
 	}
@@ -58,12 +113,22 @@ func (s *MarketService) Close() {
 
 // ============ 股票列表接口 ============
 
-// StockListRequest 股票列表请求
+// StockListRequest 股票列表请求，筛选条件可以组合使用
 type StockListRequest struct {
-	Exchange string `form:"exchange"` // 交易所筛选
-	Industry string `form:"industry"` // 行业筛选
-	Page     int    `form:"page,default=1"`
-	PageSize int    `form:"page_size,default=20"`
+	Exchange     string `form:"exchange"`                                            // 交易所筛选
+	Industry     string `form:"industry"`                                            // 行业筛选
+	Status       string `form:"status" binding:"max=10"`                             // 上市状态筛选，如 active
+	ST           string `form:"st" binding:"omitempty,oneof=exclude only"`           // 风险警示筛选：exclude 排除 ST/*ST，only 只看 ST/*ST
+	ListedAfter  string `form:"listed_after"`                                        // 上市日期不早于该日期（YYYY-MM-DD）
+	Keyword      string `form:"keyword" binding:"max=20"`                            // 匹配代码、名称或公司全称
+	MinQuality   *int   `form:"min_quality_score" binding:"omitempty,min=0,max=100"` // 数据质量评分下限，未评分的股票不排除
+	Sort         string `form:"sort"`                                                // 排序字段：symbol/name/list_date/total_share/float_share/quality_score/change_pct/volume/amount/market_cap/float_cap，默认 symbol
+	WithQuotes   bool   `form:"with_quotes"`                                         // 按数据库字段排序时也返回当前页的最近行情（含总市值、流通市值）
+	Order        string `form:"order" binding:"omitempty,oneof=asc desc"`            // 排序方向，默认 asc
+	Cursor       string `form:"cursor"`                                              // 游标翻页：传入上一页返回的 next_cursor，此时忽略 page
+	UpdatedSince string `form:"updated_since"`                                       // 增量同步：只返回之后基本信息有变化的股票（RFC3339 或 Unix 秒）
+	Page         int    `form:"page,default=1"`
+	PageSize     int    `form:"page_size,default=20"`
 }
 
 // StockListResponse 股票列表响应
@@ -71,15 +136,21 @@ type StockListResponse struct {
 	Code int    `json:"code"`
 	Msg  string `json:"msg,omitempty"`
 	Data struct {
-		List       []*models.Stock `json:"list"`
-		Total      int64           `json:"total"`
-		Page       int             `json:"page"`
-		PageSize   int             `json:"page_size"`
-		TotalPages int             `json:"total_pages"`
+		List       []*models.Stock           `json:"list"`
+		Total      int64                     `json:"total"`
+		Page       int                       `json:"page"`
+		PageSize   int                       `json:"page_size"`
+		TotalPages int                       `json:"total_pages"`
+		NextCursor string                    `json:"next_cursor,omitempty"` // 下一页游标，没有更多数据时为空
+		Quotes     map[string]*StockSnapshot `json:"quotes,omitempty"`      // 按行情排序或 with_quotes 时返回的最近行情，键为 symbol.exchange
+		Degraded   bool                      `json:"degraded,omitempty"`    // 数据源故障，排序使用的是最近一次成功查询的行情
+		NextSince  string                    `json:"next_since"`            // 下次增量同步使用的 updated_since
 	} `json:"data"`
 }
 
 // GetStockList 获取股票列表
+// 支持页码翻页与游标翻页，深度翻页时应使用游标；按行情字段排序时使用最近一个交易日的日K线。
+// 客户端增量同步时携带上次响应的 next_since 作为 updated_since，只拉取有变化的股票。
 func (s *MarketService) GetStockList(c *gin.Context) {
 	var req StockListRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
@@ -94,40 +165,108 @@ func (s *MarketService) GetStockList(c *gin.Context) {
 	if req.PageSize < 1 || req.PageSize > 100 {
 		req.PageSize = 20
 	}
+	if req.Sort == "" {
+		req.Sort = "symbol"
+	}
+	_, byQuote := quoteSortFields[req.Sort]
+	if !byQuote && !repository.StockSortFields(req.Sort) {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "不支持的排序字段: " + req.Sort})
+		return
+	}
+
+	query := repository.StockListQuery{
+		StockFilter: repository.StockFilter{
+			Exchange:   req.Exchange,
+			Industry:   req.Industry,
+			Status:     req.Status,
+			Keyword:    req.Keyword,
+			MinQuality: req.MinQuality,
+		},
+		Sort: req.Sort,
+		Desc: req.Order == "desc",
+	}
+	if req.ST != "" {
+		st := req.ST == screener.STOnly
+		query.ST = &st
+	}
+	if req.ListedAfter != "" {
+		listedAfter, err := time.Parse(validation.DateLayout, req.ListedAfter)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "listed_after 格式错误，应为 YYYY-MM-DD"})
+			return
+		}
+		query.ListedAfter = &listedAfter
+	}
+	now := time.Now()
+	updatedSince, err := validation.ParseUpdatedSince(req.UpdatedSince, now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	query.UpdatedSince = updatedSince
+	if req.Cursor != "" {
+		cursor, err := repository.DecodeStockCursor(req.Cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+		if cursor.Sort != query.Sort || cursor.Desc != query.Desc {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "游标与排序条件不一致"})
+			return
+		}
+		query.Cursor = cursor
+	}
 
 	offset := (req.Page - 1) * req.PageSize
 
 	ctx := c.Request.Context()
-	var stocks []*models.Stock
-	var total int64
-	var err error
-
-	// 根据筛选条件查询
-	if req.Exchange != "" {
-		stocks, total, err = s.stockRepo.GetByExchange(ctx, req.Exchange, offset, req.PageSize)
-	} else if req.Industry != "" {
-		stocks, total, err = s.stockRepo.GetByIndustry(ctx, req.Industry, offset, req.PageSize)
+	var page *stockPage
+	if byQuote {
+		page, err = s.listStocksByQuote(ctx, query, offset, req.PageSize)
 	} else {
-		stocks, total, err = s.stockRepo.GetAll(ctx, offset, req.PageSize)
+		page, err = s.listStocks(ctx, query, offset, req.PageSize)
 	}
-
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败: " + err.Error()})
+		respondQueryError(c, err)
 		return
 	}
+	if req.WithQuotes && page.quotes == nil {
+		if err := s.attachQuotes(ctx, page); err != nil {
+			respondQueryError(c, err)
+			return
+		}
+	}
 
-	totalPages := int((total + int64(req.PageSize) - 1) / int64(req.PageSize))
+	totalPages := int((page.total + int64(req.PageSize) - 1) / int64(req.PageSize))
 
 	resp := StockListResponse{Code: 0}
-	resp.Data.List = stocks
-	resp.Data.Total = total
+	resp.Data.List = page.stocks
+	resp.Data.Total = page.total
 	resp.Data.Page = req.Page
 	resp.Data.PageSize = req.PageSize
 	resp.Data.TotalPages = totalPages
+	resp.Data.NextCursor = page.nextCursor
+	resp.Data.Quotes = page.quotes
+	resp.Data.Degraded = page.degraded
+	resp.Data.NextSince = validation.NextSince(now)
 
 	c.JSON(http.StatusOK, resp)
 }
 
+// listStocks 按数据库字段排序并分页，返回满页时附带下一页游标
+func (s *MarketService) listStocks(ctx context.Context, query repository.StockListQuery, offset, limit int) (*stockPage, error) {
+	query.Offset, query.Limit = offset, limit
+	stocks, total, err := s.stockRepo.ListStocks(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	page := &stockPage{stocks: stocks, total: total}
+	if len(stocks) == limit {
+		page.nextCursor = repository.StockCursorAt(stocks[len(stocks)-1], query.Sort, query.Desc).Encode()
+	}
+	return page, nil
+}
+
 // ============ 实时行情接口 ============
 
 // QuoteRequest 实时行情请求
@@ -138,24 +277,32 @@ type QuoteRequest struct {
 
 // QuoteResponse 实时行情响应
 type QuoteResponse struct {
-	Symbol      string  `json:"symbol"`
-	Exchange    string  `json:"exchange"`
-	Name        string  `json:"name"`
-	Price       float64 `json:"price"`
-	Change      float64 `json:"change"`
-	ChangePct   float64 `json:"change_pct"`
-	Volume      int64   `json:"volume"`
-	Amount      float64 `json:"amount"`
-	Open        float64 `json:"open"`
-	High        float64 `json:"high"`
-	Low         float64 `json:"low"`
-	PreClose    float64 `json:"pre_close"`
-	BidPrice    float64 `json:"bid_price"`
-	BidVolume   int64   `json:"bid_volume"`
-	AskPrice    float64 `json:"ask_price"`
-	AskVolume   int64   `json:"ask_volume"`
-	Timestamp   int64   `json:"timestamp"`
-	UpdateTime  string  `json:"update_time"`
+	Symbol      string      `json:"symbol"`
+	Exchange    string      `json:"exchange"`
+	Name        string      `json:"name"`
+	Price       float64     `json:"price"`
+	Change      float64     `json:"change"`
+	ChangePct   float64     `json:"change_pct"`
+	Volume      int64       `json:"volume"`
+	Amount      float64     `json:"amount"`
+	Open        float64     `json:"open"`
+	High        float64     `json:"high"`
+	Low         float64     `json:"low"`
+	PreClose    float64     `json:"pre_close"`
+	LimitUp     float64     `json:"limit_up,omitempty"`     // 涨停价
+	LimitDown   float64     `json:"limit_down,omitempty"`   // 跌停价
+	LimitRatio  float64     `json:"limit_ratio,omitempty"`  // 涨跌幅限制比例，按板块与 ST 状态确定
+	LimitLock   string      `json:"limit_locked,omitempty"` // up 涨停封板 / down 跌停封板，未封板时为空
+	BidPrice    float64     `json:"bid_price"`
+	BidVolume   int64       `json:"bid_volume"`
+	AskPrice    float64     `json:"ask_price"`
+	AskVolume   int64       `json:"ask_volume"`
+	Timestamp   int64       `json:"timestamp"`           // Unix 秒
+	TimestampMs int64       `json:"timestamp_ms"`        // 毫秒时间戳
+	UpdateTime  string      `json:"update_time"`         // 带时区偏移的 RFC3339 时间（交易所时区）
+	DataDate    string      `json:"data_date,omitempty"` // 行情数据所属交易日
+	Degraded    bool        `json:"degraded,omitempty"`  // 数据源故障，返回的是最近一次成功查询的行情
+	Meta        *Provenance `json:"meta,omitempty"`
 }
 
 // GetRealtimeQuote 获取实时行情
@@ -165,39 +312,75 @@ func (s *MarketService) GetRealtimeQuote(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
 		return
 	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
 
-	// 查询股票信息
+	// 优先读取缓存，交易时段只缓存几秒；同一股票的并发请求合并为一次查询
 	ctx := c.Request.Context()
-	stock, err := s.stockRepo.GetBySymbol(ctx, req.Symbol, req.Exchange)
-	if err != nil {
+	quote, stale, err := cache.GetOrLoadStale(ctx, s.cache, quoteCacheKey(req.Symbol, req.Exchange), s.quoteTTL(time.Now()),
+		func(ctx context.Context) (*QuoteResponse, error) {
+			return s.loadQuote(ctx, req.Symbol, req.Exchange)
+		})
+	if errors.Is(err, errStockNotFound) {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "股票不存在"})
 		return
 	}
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+	quote.Degraded = stale
+	now := time.Now()
+	quote.Timestamp = now.Unix()
+	quote.TimestampMs = now.UnixMilli()
+	quote.UpdateTime = markettime.Format(now, req.Exchange)
+	quote.Meta = s.provenance(ctx, models.SyncJobDailyBars, req.Symbol, req.Exchange)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": quote,
+	})
+}
+
+// errStockNotFound 股票不存在
+var errStockNotFound = errors.New("股票不存在")
+
+// loadQuote 由最近的日K线计算个股行情，不含时间戳与数据来源信息
+// 查询K线失败时返回错误，避免把不完整的行情写入缓存。
+func (s *MarketService) loadQuote(ctx context.Context, symbol, exchange string) (*QuoteResponse, error) {
+	// 查询股票信息
+	stock, err := s.stockRepo.GetBySymbol(ctx, symbol, exchange)
+	if err != nil {
+		return nil, errStockNotFound
+	}
 
 	// 查询最新K线数据
-	latestBar, err := s.marketRepo.GetLatestDailyBar(ctx, req.Symbol, req.Exchange)
+	latestBar, err := s.marketRepo.GetLatestDailyBar(ctx, symbol, exchange)
 	if err != nil {
-		log.Printf("查询最新K线失败: %v", err)
+		return nil, err
 	}
 
-	// 获取昨收（前一天收盘价）
+	// 获取昨收（最新K线前一交易日收盘价）
 	var preClose float64
-	yesterday := time.Now().AddDate(0, 0, -1)
-	yesterdayBars, err := s.marketRepo.GetDailyBars(ctx, req.Symbol, req.Exchange, yesterday.AddDate(0, 0, -5), yesterday)
-	if err == nil && len(yesterdayBars) > 0 {
-		preClose = yesterdayBars[len(yesterdayBars)-1].Close
+	if latestBar != nil {
+		prevEnd := latestBar.Date.Add(-time.Nanosecond)
+		prevBars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, prevEnd.AddDate(0, 0, -10), prevEnd)
+		if err == nil && len(prevBars) > 0 {
+			preClose = prevBars[len(prevBars)-1].Close
+		}
 	}
 
 	// 构建响应
-	quote := QuoteResponse{
-		Symbol:     req.Symbol,
-		Exchange:   req.Exchange,
-		Name:       stock.Name,
-		Timestamp:  time.Now().Unix(),
-		UpdateTime: time.Now().Format("2006-01-02 15:04:05"),
+	quote := &QuoteResponse{
+		Symbol:   symbol,
+		Exchange: exchange,
+		Name:     stock.Name,
 	}
 
 	if latestBar != nil {
+		quote.DataDate = latestBar.Date.Format("2006-01-02")
 		quote.Price = latestBar.Close
 		quote.Open = latestBar.Open
 		quote.High = latestBar.High
@@ -210,12 +393,19 @@ func (s *MarketService) GetRealtimeQuote(c *gin.Context) {
 		quote.PreClose = preClose
 		quote.Change = quote.Price - preClose
 		quote.ChangePct = (quote.Change / preClose) * 100
+
+		// 涨跌停价与封板状态
+		quote.LimitRatio = pricelimit.Ratio(stock.Symbol, stock.Exchange, stock.IsST())
+		quote.LimitUp, quote.LimitDown = pricelimit.Prices(preClose, quote.LimitRatio)
+		switch pricelimit.Lock(quote.Price, preClose, quote.LimitRatio) {
+		case pricelimit.LockedUp:
+			quote.LimitLock = "up"
+		case pricelimit.LockedDown:
+			quote.LimitLock = "down"
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"code": 0,
-		"data": quote,
-	})
+	return quote, nil
 }
 
 // ============ K线数据接口 ============
@@ -227,19 +417,29 @@ type KlineRequest struct {
 	Period   string `form:"period,default=1d"` // 1d, 1m, 5m, 15m, 30m, 60m
 	Start    string `form:"start" binding:"required"` // YYYY-MM-DD
 	End      string `form:"end" binding:"required"`
+	Extended bool   `form:"extended"` // 附带换手率、振幅与成交均价，只对 JSON 响应生效
 }
 
 // KlineData K线数据点
 type KlineData struct {
-	Time   string  `json:"time"`
-	Open   float64 `json:"open"`
-	High   float64 `json:"high"`
-	Low    float64 `json:"low"`
-	Close  float64 `json:"close"`
-	Volume int64   `json:"volume"`
-	Amount float64 `json:"amount"`
+	Time      string  `json:"time"`      // 日K为 YYYY-MM-DD，分钟K线为交易所时区的 YYYY-MM-DD HH:MM
+	Timestamp int64   `json:"timestamp"` // K线开始时间的毫秒时间戳，日K为交易所时区当日零点
+	Open      float64 `json:"open"`
+	High      float64 `json:"high"`
+	Low       float64 `json:"low"`
+	Close     float64 `json:"close"`
+	Volume    int64   `json:"volume"`
+	Amount    float64 `json:"amount"`
+
+	// extended=true 时返回，无法计算时省略
+	TurnoverRate *float64 `json:"turnover_rate,omitempty"` // 换手率（%），按当前流通股本计算
+	Amplitude    *float64 `json:"amplitude,omitempty"`     // 振幅（%）
+	VWAP         *float64 `json:"vwap,omitempty"`          // 成交均价
 }
 
+// derivedLookbackDays extended=true 时日K线向前多取的自然日数，用于计算首根K线的振幅
+const derivedLookbackDays = 15
+
 // GetKlineData 获取K线数据
 func (s *MarketService) GetKlineData(c *gin.Context) {
 	var req KlineRequest
@@ -252,46 +452,87 @@ func (s *MarketService) GetKlineData(c *gin.Context) {
 		return
 	}
 
-	// 解析时间
-	start, err := time.Parse("2006-01-02", req.Start)
+	// 校验时间区间（不同周期允许的最大跨度不同）
+	rule, err := validation.PeriodRule(req.Period)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "开始日期格式错误"})
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
 		return
 	}
-	end, err := time.Parse("2006-01-02", req.End)
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, rule)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "结束日期格式错误"})
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
 		return
 	}
-
-	// 调整结束时间到当天结束
-	end = end.Add(24 * time.Hour).Add(-time.Second)
+	start, end := dateRange.Start, dateRange.End
+	if req.Period != "1d" {
+		// 分钟K线按交易所时区的自然日查询
+		start, end = dateRange.InExchange(req.Exchange)
+	}
 
 	ctx := c.Request.Context()
+	format := negotiateSeries(c)
+	extended := req.Extended && format == ""
 	var klines []KlineData
+	var bin *series.Kline
+	jobType := models.SyncJobDailyBars
+
+	// 计算首根K线的振幅需要前一交易日收盘价，日K线向前多取几天，计算后去掉
+	from := start
+	if extended && req.Period == "1d" {
+		from = start.AddDate(0, 0, -derivedLookbackDays)
+	}
+
+	// 同一图表的并发请求（相同股票、周期与区间）合并为一次查询，查询结果只读
+	key := fmt.Sprintf("%s:%s.%s:%d:%d", req.Period, req.Symbol, req.Exchange, from.Unix(), end.Unix())
 
 	switch req.Period {
 	case "1d":
-		bars, err := s.marketRepo.GetDailyBars(ctx, req.Symbol, req.Exchange, start, end)
+		bars, err := cache.Coalesce(ctx, s.klines, key, func(ctx context.Context) ([]*models.DailyBar, error) {
+			return s.marketRepo.GetDailyBars(ctx, req.Symbol, req.Exchange, from, end)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败: " + err.Error()})
+			respondQueryError(c, err)
 			return
 		}
-		klines = convertDailyBarsToKline(bars)
+		switch {
+		case format != "":
+			bin = dailyBarsToSeries(req.Symbol, req.Exchange, bars)
+		case extended:
+			klines = s.extendKlines(ctx, req.Symbol, req.Exchange, convertDailyBarsToKline(bars), indicator.FromDailyBars(bars))
+			skip := sort.Search(len(bars), func(i int) bool { return !bars[i].Date.Before(start) })
+			klines = klines[skip:]
+		default:
+			klines = convertDailyBarsToKline(bars)
+		}
 
 	case "1m", "5m", "15m", "30m", "60m":
-		bars, err := s.marketRepo.GetMinuteBars(ctx, req.Symbol, req.Exchange, req.Period, start, end)
+		bars, err := cache.Coalesce(ctx, s.klines, key, func(ctx context.Context) ([]*models.MinuteBar, error) {
+			return s.marketRepo.GetMinuteBars(ctx, req.Symbol, req.Exchange, req.Period, start, end)
+		})
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败: " + err.Error()})
+			respondQueryError(c, err)
 			return
 		}
-		klines = convertMinuteBarsToKline(bars)
+		switch {
+		case format != "":
+			bin = minuteBarsToSeries(req.Symbol, req.Exchange, req.Period, bars)
+		case extended:
+			klines = s.extendKlines(ctx, req.Symbol, req.Exchange, convertMinuteBarsToKline(bars), indicator.FromMinuteBars(bars))
+		default:
+			klines = convertMinuteBarsToKline(bars)
+		}
+		jobType = models.SyncJobMinuteBars
 
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "不支持的周期"})
 		return
 	}
 
+	if format != "" {
+		respondSeries(c, format, bin)
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
@@ -302,22 +543,28 @@ func (s *MarketService) GetKlineData(c *gin.Context) {
 			"end":      req.End,
 			"bars":     klines,
 			"count":    len(klines),
+			"meta":     s.provenance(ctx, jobType, req.Symbol, req.Exchange),
 		},
 	})
 }
 
+// extendKlines 为K线填充换手率、振幅与成交均价
+// 换手率按股票当前的流通股本计算，股票不存在或流通股本未同步时省略；序列第一根K线没有前收盘，振幅省略。
+func (s *MarketService) extendKlines(ctx context.Context, symbol, exchange string, klines []KlineData, bars *indicator.Series) []KlineData {
+	var floatShare int64
+	if stock, err := s.stockRepo.GetBySymbol(ctx, symbol, exchange); err == nil {
+		floatShare = stock.FloatShare
+	}
+	for i, d := range indicator.DerivedMetrics(bars, floatShare, 0) {
+		klines[i].TurnoverRate, klines[i].Amplitude, klines[i].VWAP = d.TurnoverRate, d.Amplitude, d.VWAP
+	}
+	return klines
+}
+
 func convertDailyBarsToKline(bars []*models.DailyBar) []KlineData {
 	klines := make([]KlineData, len(bars))
 	for i, bar := range bars {
-		klines[i] = KlineData{
-			Time:   bar.Date.Format("2006-01-02"),
-			Open:   bar.Open,
-			High:   bar.High,
-			Low:    bar.Low,
-			Close:  bar.Close,
-			Volume: bar.Volume,
-			Amount: bar.Amount,
-		}
+		klines[i] = dailyBarToKline(bar)
 	}
 	return klines
 }
@@ -325,42 +572,53 @@ func convertDailyBarsToKline(bars []*models.DailyBar) []KlineData {
 func convertMinuteBarsToKline(bars []*models.MinuteBar) []KlineData {
 	klines := make([]KlineData, len(bars))
 	for i, bar := range bars {
-		klines[i] = KlineData{
-			Time:   bar.Time.Format("2006-01-02 15:04"),
-			Open:   bar.Open,
-			High:   bar.High,
-			Low:    bar.Low,
-			Close:  bar.Close,
-			Volume: bar.Volume,
-			Amount: bar.Amount,
-		}
+		klines[i] = minuteBarToKline(bar)
 	}
 	return klines
 }
 
+func dailyBarToKline(bar *models.DailyBar) KlineData {
+	return KlineData{
+		Time:      bar.Date.Format(markettime.DateLayout),
+		Timestamp: markettime.StartOfDate(bar.Date, bar.Exchange).UnixMilli(),
+		Open:      bar.Open,
+		High:      bar.High,
+		Low:       bar.Low,
+		Close:     bar.Close,
+		Volume:    bar.Volume,
+		Amount:    bar.Amount,
+	}
+}
+
+func minuteBarToKline(bar *models.MinuteBar) KlineData {
+	return KlineData{
+		Time:      markettime.FormatMinute(bar.Time, bar.Exchange),
+		Timestamp: bar.Time.UnixMilli(),
+		Open:      bar.Open,
+		High:      bar.High,
+		Low:       bar.Low,
+		Close:     bar.Close,
+		Volume:    bar.Volume,
+		Amount:    bar.Amount,
+	}
+}
+
 // ============ 技术指标接口 ============
 
 // IndicatorRequest 技术指标请求
 type IndicatorRequest struct {
-	Symbol       string `uri:"symbol" binding:"required"`
-	Exchange     string `form:"exchange,default=SZ"`
-	IndicatorType string `form:"type,default=ma"` // ma, macd, rsi, kdj, boll
-	Period       int    `form:"period,default=20"` // 计算周期
-	Start        string `form:"start"`
-	End          string `form:"end"`
-}
-
-// IndicatorData 指标数据点
-type IndicatorData struct {
-	Time string  `json:"time"`
-	Value float64 `json:"value,omitempty"`
-	MA5  float64 `json:"ma5,omitempty"`
-	MA10 float64 `json:"ma10,omitempty"`
-	MA20 float64 `json:"ma20,omitempty"`
-	MA60 float64 `json:"ma60,omitempty"`
+	Symbol        string `uri:"symbol" binding:"required"`
+	Exchange      string `form:"exchange,default=SZ"`
+	IndicatorType string `form:"type,default=ma"`     // ma, macd, rsi, kdj, boll, atr, cci, obv, dmi, wr, bias, vwap, twap
+	Periods       string `form:"periods"`             // 逗号分隔的参数，如 ma 的 5,10,20、macd 的 12,26,9；未传时使用 period 或类型的默认参数
+	Period        int    `form:"period"`              // 单个周期，等同于 periods 只传一个值
+	Interval      string `form:"interval,default=1m"` // vwap、twap 使用的分钟K线周期
+	Start         string `form:"start"`
+	End           string `form:"end"`
 }
 
 // GetIndicators 获取技术指标
+// 参数与预计算保存的默认参数相同时读取 InfluxDB，否则向前多取预热数据、由日K线实时计算。
 func (s *MarketService) GetIndicators(c *gin.Context) {
 	var req IndicatorRequest
 	if err := c.ShouldBindUri(&req); err != nil {
@@ -372,59 +630,49 @@ func (s *MarketService) GetIndicators(c *gin.Context) {
 		return
 	}
 
-	// 解析时间
-	start, _ := time.Parse("2006-01-02", req.Start)
-	end, _ := time.Parse("2006-01-02", req.End)
-
-	if start.IsZero() {
-		start = time.Now().AddDate(0, 0, -req.Period)
+	if indicator.IsIntraday(req.IndicatorType) {
+		s.getIntradayIndicator(c, &req)
+		return
 	}
-	if end.IsZero() {
-		end = time.Now()
+
+	params, err := parseIndicatorParams(req.IndicatorType, req.Periods, req.Period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
 	}
-	end = end.Add(24 * time.Hour).Add(-time.Second)
 
-	ctx := c.Request.Context()
+	// 校验时间区间，未传开始日期时取最近 20 天
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 20,
+		MaxDays:     365 * 20,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
 
-	// 查询指标数据
-	indicators, err := s.marketRepo.GetIndicators(ctx, req.Symbol, req.Exchange, req.IndicatorType, start, end)
+	table, source, err := s.loadIndicatorTable(c.Request.Context(), req.Symbol, req.Exchange, req.IndicatorType, params, dateRange.Start, dateRange.End)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败: " + err.Error()})
+		respondQueryError(c, err)
 		return
 	}
 
-	// 转换数据格式
-	data := make([]IndicatorData, len(indicators))
-	for i, ind := range indicators {
-		d := IndicatorData{Time: ind.Date.Format("2006-01-02")}
-		
-		switch req.IndicatorType {
-		case "ma":
-			d.MA5 = ind.MA5
-			d.MA10 = ind.MA10
-			d.MA20 = ind.MA20
-			d.MA60 = ind.MA60
-		case "macd":
-			d.Value = ind.MACD
-		case "rsi":
-			d.Value = ind.RSI6
-		case "kdj":
-			d.Value = ind.K
-		case "boll":
-			d.Value = ind.BollMid
-		}
-		
-		data[i] = d
+	if format := negotiateSeries(c); format != "" {
+		respondSeries(c, format, indicatorTableToSeries(req.Symbol, req.Exchange, table))
+		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
-			"symbol":    req.Symbol,
-			"exchange":  req.Exchange,
-			"type":      req.IndicatorType,
-			"indicators": data,
-			"count":     len(data),
+			"symbol":     req.Symbol,
+			"exchange":   req.Exchange,
+			"type":       req.IndicatorType,
+			"params":     indicator.DescribeParams(req.IndicatorType, params),
+			"source":     source,
+			"columns":    table.names,
+			"indicators": table.rowsJSON(),
+			"count":      len(table.times),
 		},
 	})
 }
@@ -472,51 +720,6 @@ func main() {
 	if err != nil {
 		log.Fatalf("创建行情服务失败: %v", err)
 	}
-	defer service.Close()
-
-	// 设置Gin模式
-	if cfg.Server.Mode == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
-
-	// 创建路由
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(corsMiddleware())
-	r.Use(requestLogger())
-
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		ctx := c.Request.Context()
-		healthy := service.dbManager.IsHealthy(ctx)
-		
-		status := "healthy"
-		code := 200
-		if !healthy {
-			status = "unhealthy"
-			code = 503
-		}
-
-		c.JSON(code, gin.H{
-			"status":    status,
-			"service":   "market-service",
-			"timestamp": time.Now().Unix(),
-		})
-	})
-
-	// API路由组
-	api := r.Group("/api/v1")
-	{
-		// 行情接口
-		market := api.Group("/market")
-		{
-			market.GET("/stocks", service.GetStockList)
-			market.GET("/stocks/search", service.SearchStocks)
-			market.GET("/quote/:symbol", service.GetRealtimeQuote)
-			market.GET("/kline/:symbol", service.GetKlineData)
-			market.GET("/indicators/:symbol", service.GetIndicators)
-		}
-	}
 
 	// 获取端口
 	port := os.Getenv("MARKET_SERVICE_PORT")
@@ -524,67 +727,56 @@ func main() {
 		port = "8082"
 	}
 
-	// 启动服务
-	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: r,
-	}
-
-	// 优雅退出
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-		log.Println("正在关闭服务...")
-		
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		
-		if err := srv.Shutdown(ctx); err != nil {
-			log.Printf("服务关闭失败: %v", err)
-		}
-	}()
-
-	log.Printf("行情服务启动在端口 %s", port)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("服务启动失败: %v", err)
-	}
-}
+	// 预热缓存
+	ctx, cancel := context.WithCancel(context.Background())
+	service.StartCacheWarmer(ctx)
 
-// corsMiddleware CORS中间件
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+	srv := server.New("market-service", cfg,
+		server.WithPort(port),
+		server.WithDatabaseHealth(service.dbManager),
+		server.WithShutdownHook(func(context.Context) { service.Close() }),
+		server.WithShutdownHook(func(context.Context) { cancel() }),
+	)
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+	// API路由组
+	api := srv.Router().Group("/api/v1")
+	{
+		// 行情接口
+		market := api.Group("/market")
+		{
+			market.GET("/stocks", middleware.Timeout(10*time.Second), service.GetStockList)
+			market.GET("/industries", middleware.Timeout(10*time.Second), service.GetIndustries)
+			market.GET("/stocks/search", middleware.Timeout(5*time.Second), service.SearchStocks)
+			market.GET("/stocks/:symbol", middleware.Timeout(10*time.Second), service.GetStockDetail)
+			market.GET("/quote/:symbol", middleware.Timeout(5*time.Second), service.GetRealtimeQuote)
+			market.GET("/quotes/ws", srv.Streams().Middleware("websocket"), service.QuoteWS)
+			market.GET("/basket/quote", middleware.Timeout(15*time.Second), service.GetBasketQuote)
+			market.POST("/basket/quote", middleware.Timeout(15*time.Second), service.GetBasketQuote)
+			market.GET("/kline/:symbol", middleware.Timeout(15*time.Second), service.GetKlineData)
+			market.GET("/kline/:symbol/stream", middleware.Timeout(klineStreamTimeout), service.StreamKlineData)
+			market.GET("/indicators/compare", middleware.Timeout(30*time.Second), service.CompareIndicators)
+			market.GET("/indicators/:symbol", middleware.Timeout(15*time.Second), service.GetIndicators)
+			market.GET("/indicators/:symbol/all", middleware.Timeout(15*time.Second), service.GetAllIndicators)
+			market.GET("/schema/series.proto", service.GetSeriesSchema)
+			market.GET("/moneyflow/rank", middleware.Timeout(10*time.Second), service.GetMoneyFlowRank)
+			market.GET("/moneyflow/:symbol", middleware.Timeout(10*time.Second), service.GetMoneyFlow)
+			market.GET("/dragon-tiger", middleware.Timeout(10*time.Second), service.GetDragonTiger)
+			market.GET("/news", middleware.Timeout(10*time.Second), service.GetNews)
+			market.GET("/calendar", middleware.Timeout(10*time.Second), service.GetCalendar)
+			market.GET("/macro", middleware.Timeout(5*time.Second), service.GetMacroSeriesList)
+			market.GET("/macro/series", middleware.Timeout(15*time.Second), service.GetMacroSeries)
+			market.GET("/connect/flow", middleware.Timeout(10*time.Second), service.GetConnectFlow)
+			market.GET("/connect/holdings/:symbol", middleware.Timeout(10*time.Second), service.GetNorthboundHoldings)
+			market.GET("/correlation", middleware.Timeout(15*time.Second), service.GetCorrelation)
+			market.GET("/spread", middleware.Timeout(15*time.Second), service.GetSpread)
+			market.GET("/screener", middleware.Timeout(30*time.Second), service.Screen)
+			market.GET("/factors", middleware.Timeout(5*time.Second), service.GetFactors)
+			market.GET("/factors/ranking", middleware.Timeout(15*time.Second), service.GetFactorRanking)
+			market.GET("/factors/:symbol", middleware.Timeout(10*time.Second), service.GetStockFactors)
 		}
-
-		c.Next()
 	}
-}
-
-// requestLogger 请求日志中间件
-func requestLogger() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		start := time.Now()
-		path := c.Request.URL.Path
-		raw := c.Request.URL.RawQuery
-
-		c.Next()
 
-		latency := time.Since(start)
-		clientIP := c.ClientIP()
-		method := c.Request.Method
-		statusCode := c.Writer.Status()
-
-		if raw != "" {
-			path = path + "?" + raw
-		}
-
-		log.Printf("[%s] %s %s %d %v", clientIP, method, path, statusCode, latency)
+	if err := srv.Run(); err != nil {
+		log.Fatalf("服务启动失败: %v", err)
 	}
 }
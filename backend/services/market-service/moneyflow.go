@@ -0,0 +1,185 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 资金流向接口 ============
+
+// MoneyFlowRequest 个股资金流向请求
+type MoneyFlowRequest struct {
+	Symbol   string `uri:"symbol" binding:"required"`
+	Exchange string `form:"exchange,default=SZ"`
+	Start    string `form:"start"` // YYYY-MM-DD，默认最近30天
+	End      string `form:"end"`
+}
+
+// GetMoneyFlow 获取个股资金流向
+func (s *MarketService) GetMoneyFlow(c *gin.Context) {
+	var req MoneyFlowRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 30,
+		MaxDays:     365 * 5,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	flows, err := s.marketRepo.GetMoneyFlows(ctx, req.Symbol, req.Exchange, dateRange.Start, dateRange.End)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"symbol":   req.Symbol,
+			"exchange": req.Exchange,
+			"flows":    flows,
+			"count":    len(flows),
+			"meta":     s.provenance(ctx, models.SyncJobMoneyFlow, req.Symbol, req.Exchange),
+		},
+	})
+}
+
+// MoneyFlowRankRequest 资金流向排名请求
+type MoneyFlowRankRequest struct {
+	Date  string `form:"date"`               // YYYY-MM-DD，默认当天
+	Order string `form:"order,default=desc"` // desc: 净流入, asc: 净流出
+	Limit int    `form:"limit,default=50"`
+}
+
+// GetMoneyFlowRank 获取主力净流入排名
+func (s *MarketService) GetMoneyFlowRank(c *gin.Context) {
+	var req MoneyFlowRankRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "日期格式错误"})
+			return
+		}
+		date = t
+	}
+	if req.Order != "asc" && req.Order != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "排序方式只支持 asc 或 desc"})
+		return
+	}
+	if req.Limit < 1 || req.Limit > 200 {
+		req.Limit = 50
+	}
+
+	ctx := c.Request.Context()
+	flows, err := s.marketRepo.GetMoneyFlowRanking(ctx, date, req.Limit, req.Order == "desc")
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"date":  date.Format("2006-01-02"),
+			"order": req.Order,
+			"list":  flows,
+			"count": len(flows),
+		},
+	})
+}
+
+// ============ 龙虎榜接口 ============
+
+// DragonTigerRequest 龙虎榜请求
+type DragonTigerRequest struct {
+	Date     string `form:"date"`   // YYYY-MM-DD，按日期查询
+	Symbol   string `form:"symbol"` // 按个股查询（最近一年）
+	Exchange string `form:"exchange,default=SZ"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// GetDragonTiger 获取龙虎榜
+func (s *MarketService) GetDragonTiger(c *gin.Context) {
+	var req DragonTigerRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if req.Symbol != "" {
+		end := time.Now()
+		records, err := s.dragonTigerRepo.GetBySymbol(ctx, req.Symbol, req.Exchange, end.AddDate(-1, 0, 0), end)
+		if err != nil {
+			respondQueryError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"code": 0,
+			"data": gin.H{
+				"symbol":   req.Symbol,
+				"exchange": req.Exchange,
+				"list":     records,
+				"count":    len(records),
+			},
+		})
+		return
+	}
+
+	date := time.Now()
+	if req.Date != "" {
+		t, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "日期格式错误"})
+			return
+		}
+		date = t
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	records, total, err := s.dragonTigerRepo.GetByDate(ctx, date, req.Page, req.PageSize)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"date":      date.Format("2006-01-02"),
+			"list":      records,
+			"total":     total,
+			"page":      req.Page,
+			"page_size": req.PageSize,
+		},
+	})
+}
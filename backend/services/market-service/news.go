@@ -0,0 +1,141 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 新闻公告接口 ============
+
+// NewsRequest 新闻查询请求
+type NewsRequest struct {
+	Symbol   string `form:"symbol"`
+	Exchange string `form:"exchange"`
+	Keyword  string `form:"q"`        // 全文检索
+	Category string `form:"category"` // news, announcement
+	From     string `form:"from"`     // YYYY-MM-DD
+	To       string `form:"to"`
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+// GetNews 查询新闻公告
+func (s *MarketService) GetNews(c *gin.Context) {
+	var req NewsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	filter := repository.NewsFilter{
+		Symbol:   req.Symbol,
+		Exchange: req.Exchange,
+		Keyword:  req.Keyword,
+		Category: req.Category,
+	}
+	if req.From != "" {
+		t, err := time.Parse("2006-01-02", req.From)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "开始日期格式错误"})
+			return
+		}
+		filter.From = t
+	}
+	if req.To != "" {
+		t, err := time.Parse("2006-01-02", req.To)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "结束日期格式错误"})
+			return
+		}
+		filter.To = t.Add(24 * time.Hour).Add(-time.Second)
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+
+	ctx := c.Request.Context()
+	articles, total, err := s.newsRepo.List(ctx, filter, req.Page, req.PageSize)
+	if err != nil {
+		respondQueryError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":      articles,
+			"total":     total,
+			"page":      req.Page,
+			"page_size": req.PageSize,
+		},
+	})
+}
+
+// ============ 股票详情接口 ============
+
+// StockDetailRequest 股票详情请求
+type StockDetailRequest struct {
+	Symbol   string `uri:"symbol" binding:"required"`
+	Exchange string `form:"exchange,default=SZ"`
+}
+
+// GetStockDetail 获取股票详情（基础信息、最新K线、相关新闻、风险警示历史）
+func (s *MarketService) GetStockDetail(c *gin.Context) {
+	var req StockDetailRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	stock, err := s.stockRepo.GetBySymbol(ctx, req.Symbol, req.Exchange)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "股票不存在"})
+		return
+	}
+
+	latestBar, err := s.marketRepo.GetLatestDailyBar(ctx, req.Symbol, req.Exchange)
+	if err != nil {
+		log.Printf("查询最新K线失败: %v", err)
+	}
+
+	news, err := s.newsRepo.GetLatestBySymbol(ctx, req.Symbol, req.Exchange, 10)
+	if err != nil {
+		log.Printf("查询相关新闻失败: %v", err)
+	}
+
+	warnings, err := s.stockRepo.GetRiskWarningHistory(ctx, req.Symbol, req.Exchange)
+	if err != nil {
+		log.Printf("查询风险警示历史失败: %v", err)
+	}
+
+	// 按最新收盘价计算总市值与流通市值，股本未知或没有K线时为空
+	var marketCap, floatCap *float64
+	if latestBar != nil {
+		marketCap, floatCap = stock.MarketCap(latestBar.Close), stock.FloatCap(latestBar.Close)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"stock":         stock,
+			"latest_bar":    latestBar,
+			"market_cap":    marketCap,
+			"float_cap":     floatCap,
+			"news":          news,
+			"risk_warnings": warnings,
+		},
+	})
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 数据来源信息 ============
+
+// 各类数据超过该时长未同步即视为延迟数据
+// 日线数据每天凌晨增量同步一次，留出两小时余量。
+var freshnessThreshold = map[string]time.Duration{
+	models.SyncJobDailyBars:  26 * time.Hour,
+	models.SyncJobMinuteBars: 5 * time.Minute,
+	models.SyncJobMoneyFlow:  26 * time.Hour,
+}
+
+// Provenance 行情数据来源信息，附加在行情响应的 meta 字段中
+type Provenance struct {
+	Source           string     `json:"source"`            // 数据来源，无同步记录时为空
+	LastSyncAt       *time.Time `json:"last_sync_at"`      // 最近一次成功同步时间
+	StalenessSeconds *int64     `json:"staleness_seconds"` // 距最近一次同步的秒数
+	Delayed          bool       `json:"delayed"`           // 是否为延迟数据
+}
+
+// provenance 根据最近一次成功的同步任务生成数据来源信息
+// 查询同步记录失败时返回 nil，不影响行情数据本身的返回。
+func (s *MarketService) provenance(ctx context.Context, jobType, symbol, exchange string) *Provenance {
+	job, err := s.syncJobRepo.GetLatestSuccess(ctx, jobType, symbol, exchange)
+	if err != nil {
+		log.Printf("查询 %s.%s 同步记录失败: %v", symbol, exchange, err)
+		return nil
+	}
+	if job == nil || job.FinishedAt == nil {
+		return &Provenance{Delayed: true}
+	}
+
+	staleness := time.Since(*job.FinishedAt)
+	seconds := int64(staleness.Seconds())
+	return &Provenance{
+		Source:           job.Source,
+		LastSyncAt:       job.FinishedAt,
+		StalenessSeconds: &seconds,
+		Delayed:          staleness > freshnessThreshold[jobType],
+	}
+}
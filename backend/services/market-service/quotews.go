@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"stock-analysis-system/backend/pkg/cache"
+	"stock-analysis-system/backend/pkg/quotestream"
+	"stock-analysis-system/backend/pkg/server"
+)
+
+// ============ 实时行情推送 ============
+
+const (
+	quotePollTradingInterval = 3 * time.Second  // 交易时段每只股票的行情源检查变化的间隔，与行情缓存有效期一致
+	quotePollIdleInterval    = 30 * time.Second // 非交易时段的检查间隔
+)
+
+// QuoteWS 实时行情推送（WebSocket），消息协议见 pkg/quotestream
+// 同一股票的全部连接共用一个行情源（s.quotes），连接本身不查询行情。
+// 可通过 symbols 参数（逗号分隔的 symbol.exchange）在连接时订阅，之后发送 subscribe/unsubscribe/resync/ping 请求。
+// 服务关闭时推送 reconnect 消息并以关闭码 1012 关闭连接，客户端重连后重新订阅即可。
+func (s *MarketService) QuoteWS(c *gin.Context) {
+	var symbols []string
+	for _, key := range strings.Split(c.Query("symbols"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			symbols = append(symbols, key)
+		}
+	}
+
+	stream := server.StreamFrom(c)
+	wsServer := websocket.Server{
+		Handler: func(ws *websocket.Conn) {
+			s.runQuoteStream(ws, symbols, stream)
+		},
+	}
+	wsServer.ServeHTTP(c.Writer, c.Request)
+}
+
+// runQuoteStream 读取客户端请求并定期发送心跳，所有消息由当前 goroutine 写出
+func (s *MarketService) runQuoteStream(ws *websocket.Conn, symbols []string, stream *server.Stream) {
+	// 劫持后的连接沿用服务器读写超时，推送连接需要长期保持
+	ws.SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	session := quotestream.NewSession(s.quotes, quotestream.DefaultMaxSymbols, quotestream.DefaultBuffer)
+	defer session.Close()
+	if len(symbols) > 0 {
+		session.Handle(ctx, quotestream.Request{Op: quotestream.OpSubscribe, Symbols: symbols})
+	}
+
+	go func() {
+		defer cancel()
+		for {
+			var raw []byte
+			if err := websocket.Message.Receive(ws, &raw); err != nil {
+				return
+			}
+			var req quotestream.Request
+			if err := json.Unmarshal(raw, &req); err != nil {
+				session.Reject("请求格式错误，应为 JSON")
+				continue
+			}
+			session.Handle(ctx, req)
+		}
+	}()
+
+	heartbeat := time.NewTicker(quotestream.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-heartbeat.C:
+			session.Heartbeat()
+		case msg := <-session.Messages():
+			if err := websocket.JSON.Send(ws, msg); err != nil {
+				return
+			}
+		case <-stream.Draining():
+			hint := stream.Hint()
+			if websocket.JSON.Send(ws, session.Notice(quotestream.TypeReconnect, hint)) == nil {
+				server.CloseWebSocket(ws, hint)
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// streamQuote 推送用的个股行情，与 /quote/{symbol} 共用缓存，不含查询时间与数据来源
+func (s *MarketService) streamQuote(ctx context.Context, symbol, exchange string) (interface{}, error) {
+	quote, stale, err := cache.GetOrLoadStale(ctx, s.cache, quoteCacheKey(symbol, exchange), s.quoteTTL(time.Now()),
+		func(ctx context.Context) (*QuoteResponse, error) {
+			return s.loadQuote(ctx, symbol, exchange)
+		})
+	if errors.Is(err, errStockNotFound) {
+		return nil, quotestream.ErrUnknownSymbol
+	}
+	if err != nil {
+		return nil, err
+	}
+	quote.Degraded = stale
+	return quote, nil
+}
+
+// quotePollInterval 行情源检查变化的间隔
+func quotePollInterval(now time.Time) time.Duration {
+	if inTradingSession(now) {
+		return quotePollTradingInterval
+	}
+	return quotePollIdleInterval
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/screener"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 选股器 ============
+
+// ScreenerRequest 选股请求，筛选条件见 screener.Params
+type ScreenerRequest struct {
+	AsOf     string `form:"as_of"` // YYYY-MM-DD，默认今天；只使用该日及之前的行情与已披露财报
+	Page     int    `form:"page,default=1"`
+	PageSize int    `form:"page_size,default=50"`
+}
+
+// Screen 按条件选股
+// 指定 as_of 时按当日的时点数据筛选：股票池为当日已上市的股票（含此后退市的），
+// 行情只取当日及之前的K线，财报只取当日已过法定披露截止日的最近一期，北向持股只取当日及之前的记录。
+func (s *MarketService) Screen(c *gin.Context) {
+	var req ScreenerRequest
+	var params screener.Params
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := params.Normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.PageSize < 1 || req.PageSize > 500 {
+		req.PageSize = 50
+	}
+
+	today := markettime.Today("")
+	if req.AsOf == "" {
+		req.AsOf = today
+	}
+	asOf, err := time.Parse(validation.DateLayout, req.AsOf)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "as_of 格式错误，应为 YYYY-MM-DD"})
+		return
+	}
+	if req.AsOf > today {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "as_of 不能晚于今天"})
+		return
+	}
+
+	result, err := screener.Run(c.Request.Context(), s.stockRepo, s.marketRepo, s.factorRepo, s.connectRepo, &params, asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	total := len(result.Rows)
+	from := (req.Page - 1) * req.PageSize
+	if from > total {
+		from = total
+	}
+	to := from + req.PageSize
+	if to > total {
+		to = total
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"as_of":      result.AsOf,
+			"trade_date": result.TradeDate,
+			"universe":   result.Universe,
+			"list":       result.Rows[from:to],
+			"total":      total,
+			"page":       req.Page,
+			"page_size":  req.PageSize,
+		},
+	})
+}
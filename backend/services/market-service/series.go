@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/series"
+)
+
+// ============ 序列二进制编码 ============
+
+// negotiateSeries 按 Accept 头选择K线/指标序列的响应格式，返回空字符串时使用 JSON
+// 同一地址按 Accept 返回不同内容，响应需带 Vary 头，避免下游缓存混用。
+func negotiateSeries(c *gin.Context) string {
+	c.Header("Vary", "Accept")
+	return series.Negotiate(c.GetHeader("Accept"))
+}
+
+// respondSeries 以 format 编码返回序列
+func respondSeries(c *gin.Context, format string, msg series.Message) {
+	if format == series.MIMEProtobuf {
+		c.Data(http.StatusOK, series.MIMEProtobuf, msg.MarshalProto())
+		return
+	}
+	body, err := series.MarshalMsgPack(msg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "编码失败: " + err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, series.MIMEMsgPack, body)
+}
+
+// GetSeriesSchema 序列二进制编码的 Protobuf 定义
+func (s *MarketService) GetSeriesSchema(c *gin.Context) {
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(series.Proto))
+}
+
+func dailyBarsToSeries(symbol, exchange string, bars []*models.DailyBar) *series.Kline {
+	k := series.NewKline(symbol, exchange, "1d", len(bars))
+	for _, bar := range bars {
+		k.Append(bar.Date, bar.Open, bar.High, bar.Low, bar.Close, bar.Volume, bar.Amount)
+	}
+	return k
+}
+
+func minuteBarsToSeries(symbol, exchange, period string, bars []*models.MinuteBar) *series.Kline {
+	k := series.NewKline(symbol, exchange, period, len(bars))
+	for _, bar := range bars {
+		k.Append(bar.Time, bar.Open, bar.High, bar.Low, bar.Close, bar.Volume, bar.Amount)
+	}
+	return k
+}
+
+// indicatorTableToSeries 单类型指标接口的序列，列名与 JSON 子对象中的字段一致
+func indicatorTableToSeries(symbol, exchange string, t *indicatorTable) *series.Indicators {
+	s := series.NewIndicators(symbol, exchange, t.names, len(t.times))
+	for i, row := range t.rows {
+		s.Append(t.times[i], row...)
+	}
+	return s
+}
+
+// alignIndicatorSeries 将各类型的指标按交易日合并为列式序列，列名为 类型.字段，该日无此类指标时为 NaN
+func alignIndicatorSeries(symbol, exchange string, types []string, results [][]*models.Indicator) *series.Indicators {
+	var names []string
+	fields := make([][]string, len(types))
+	offsets := make([]int, len(types))
+	for i, t := range types {
+		for field := range indicatorFields(t, &models.Indicator{}) {
+			fields[i] = append(fields[i], field)
+		}
+		sort.Strings(fields[i])
+		offsets[i] = len(names)
+		for _, field := range fields[i] {
+			names = append(names, t+"."+field)
+		}
+	}
+
+	rows := make(map[int64][]float64)
+	for i, t := range types {
+		for _, ind := range results[i] {
+			ts := ind.Date.Unix()
+			row, ok := rows[ts]
+			if !ok {
+				row = make([]float64, len(names))
+				for j := range row {
+					row[j] = math.NaN()
+				}
+				rows[ts] = row
+			}
+			values := indicatorFields(t, ind)
+			for j, field := range fields[i] {
+				row[offsets[i]+j] = values[field]
+			}
+		}
+	}
+
+	times := make([]int64, 0, len(rows))
+	for ts := range rows {
+		times = append(times, ts)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	s := series.NewIndicators(symbol, exchange, names, len(times))
+	for _, ts := range times {
+		s.Append(time.Unix(ts, 0), rows[ts]...)
+	}
+	return s
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 股票列表按行情排序 ============
+
+// quoteLookbackDays 查询最近行情时回看的自然日数，覆盖长假与短期停牌
+const quoteLookbackDays = 15
+
+// StockSnapshot 股票最近一个交易日的行情，按行情排序时随列表返回
+type StockSnapshot struct {
+	TradeDate string   `json:"trade_date"`
+	Close     float64  `json:"close"`
+	ChangePct *float64 `json:"change_pct,omitempty"` // 较前一交易日涨跌幅（%），缺少前一交易日时为空
+	Volume    int64    `json:"volume"`
+	Amount    float64  `json:"amount"`
+	MarketCap *float64 `json:"market_cap,omitempty"` // 总市值（元），总股本未知时为空
+	FloatCap  *float64 `json:"float_cap,omitempty"`  // 流通市值（元），流通股本未知时为空
+}
+
+// quoteSortFields 按行情排序的字段，取值为空表示缺少数据
+var quoteSortFields = map[string]func(*StockSnapshot) *float64{
+	"change_pct": func(q *StockSnapshot) *float64 { return q.ChangePct },
+	"volume":     func(q *StockSnapshot) *float64 { v := float64(q.Volume); return &v },
+	"amount":     func(q *StockSnapshot) *float64 { return &q.Amount },
+	"market_cap": func(q *StockSnapshot) *float64 { return q.MarketCap },
+	"float_cap":  func(q *StockSnapshot) *float64 { return q.FloatCap },
+}
+
+// stockPage 股票列表的一页
+type stockPage struct {
+	stocks     []*models.Stock
+	total      int64
+	quotes     map[string]*StockSnapshot
+	nextCursor string
+	degraded   bool // 行情为过期副本
+}
+
+// sortKey 行情排序键，valid 为 false 的记录无论升降序都排在最后
+type sortKey struct {
+	valid    bool
+	value    float64
+	symbol   string
+	exchange string
+}
+
+// compareSortKey 比较两条记录在列表中的先后，返回负数表示 a 在前
+func compareSortKey(a, b sortKey, desc bool) int {
+	if a.valid != b.valid {
+		if a.valid {
+			return -1
+		}
+		return 1
+	}
+	c := 0
+	switch {
+	case a.valid && a.value < b.value:
+		c = -1
+	case a.valid && a.value > b.value:
+		c = 1
+	default:
+		c = strings.Compare(a.symbol, b.symbol)
+		if c == 0 {
+			c = strings.Compare(a.exchange, b.exchange)
+		}
+	}
+	if desc {
+		return -c
+	}
+	return c
+}
+
+// listStocksByQuote 按最近交易日行情排序并分页
+// 行情保存在 InfluxDB，无法在数据库中排序：先按筛选条件取出全部股票，再一次查询全市场最近的日K线，在内存中排序。
+func (s *MarketService) listStocksByQuote(ctx context.Context, query repository.StockListQuery, offset, limit int) (*stockPage, error) {
+	value := quoteSortFields[query.Sort]
+	cursor := query.Cursor
+
+	stocks, total, err := s.filterStocks(ctx, query.StockFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	bars, stale, err := s.latestMarketBars(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[*models.Stock]sortKey, len(stocks))
+	snapshots := make(map[*models.Stock]*StockSnapshot, len(stocks))
+	for _, stock := range stocks {
+		key := sortKey{symbol: stock.Symbol, exchange: stock.Exchange}
+		if snapshot := newStockSnapshot(stock, bars[stock.Symbol+"."+stock.Exchange]); snapshot != nil {
+			snapshots[stock] = snapshot
+			if v := value(snapshot); v != nil {
+				key.valid, key.value = true, *v
+			}
+		}
+		keys[stock] = key
+	}
+	sort.Slice(stocks, func(i, j int) bool {
+		return compareSortKey(keys[stocks[i]], keys[stocks[j]], query.Desc) < 0
+	})
+
+	// 游标翻页：跳过排在游标及其之前的记录
+	start := offset
+	if cursor != nil {
+		after := sortKey{symbol: cursor.Symbol, exchange: cursor.Exchange}
+		if cursor.Value != "" {
+			v, err := strconv.ParseFloat(cursor.Value, 64)
+			if err != nil {
+				return nil, fmt.Errorf("游标格式错误")
+			}
+			after.valid, after.value = true, v
+		}
+		start = sort.Search(len(stocks), func(i int) bool {
+			return compareSortKey(after, keys[stocks[i]], query.Desc) < 0
+		})
+	}
+	start = min(start, len(stocks))
+	stop := min(start+limit, len(stocks))
+
+	page := &stockPage{
+		stocks:   stocks[start:stop],
+		total:    total,
+		quotes:   make(map[string]*StockSnapshot, stop-start),
+		degraded: stale,
+	}
+	for _, stock := range page.stocks {
+		if snapshot, ok := snapshots[stock]; ok {
+			page.quotes[stock.Symbol+"."+stock.Exchange] = snapshot
+		}
+	}
+	if stop < len(stocks) && stop > start {
+		last := stocks[stop-1]
+		next := &repository.StockCursor{Sort: query.Sort, Desc: query.Desc, Symbol: last.Symbol, Exchange: last.Exchange}
+		if key := keys[last]; key.valid {
+			next.Value = strconv.FormatFloat(key.value, 'g', -1, 64)
+		}
+		page.nextCursor = next.Encode()
+	}
+	return page, nil
+}
+
+// attachQuotes 为当前页的股票附带最近一个交易日的行情（含总市值、流通市值）
+func (s *MarketService) attachQuotes(ctx context.Context, page *stockPage) error {
+	bars, stale, err := s.latestMarketBars(ctx)
+	if err != nil {
+		return err
+	}
+	page.quotes = make(map[string]*StockSnapshot, len(page.stocks))
+	page.degraded = stale
+	for _, stock := range page.stocks {
+		key := stock.Symbol + "." + stock.Exchange
+		if snapshot := newStockSnapshot(stock, bars[key]); snapshot != nil {
+			page.quotes[key] = snapshot
+		}
+	}
+	return nil
+}
+
+// filterStocks 按筛选条件取出全部股票，不带筛选条件时使用缓存的股票列表
+// 列表在内存中重新排序，复制一份避免修改缓存读出的切片。
+func (s *MarketService) filterStocks(ctx context.Context, filter repository.StockFilter) ([]*models.Stock, int64, error) {
+	if filter != (repository.StockFilter{}) {
+		return s.stockRepo.ListStocks(ctx, repository.StockListQuery{StockFilter: filter})
+	}
+	stocks, err := s.allStocks(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append([]*models.Stock(nil), stocks...), int64(len(stocks)), nil
+}
+
+// newStockSnapshot 由最近的日K线生成行情快照，没有K线时返回 nil
+func newStockSnapshot(stock *models.Stock, bars []*models.DailyBar) *StockSnapshot {
+	if len(bars) == 0 {
+		return nil
+	}
+	last := bars[len(bars)-1]
+	snapshot := &StockSnapshot{
+		TradeDate: last.Date.Format(validation.DateLayout),
+		Close:     last.Close,
+		Volume:    last.Volume,
+		Amount:    last.Amount,
+		MarketCap: stock.MarketCap(last.Close),
+		FloatCap:  stock.FloatCap(last.Close),
+	}
+	if len(bars) > 1 {
+		if prev := bars[len(bars)-2].Close; prev > 0 {
+			pct := (last.Close - prev) / prev * 100
+			snapshot.ChangePct = &pct
+		}
+	}
+	return snapshot
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+func TestCompareSortKey(t *testing.T) {
+	key := func(value float64, symbol, exchange string) sortKey {
+		return sortKey{valid: true, value: value, symbol: symbol, exchange: exchange}
+	}
+	missing := func(symbol, exchange string) sortKey {
+		return sortKey{symbol: symbol, exchange: exchange}
+	}
+	tests := []struct {
+		name string
+		a, b sortKey
+		desc bool
+		want int
+	}{
+		{"升序按取值", key(1, "600519", "SH"), key(2, "000001", "SZ"), false, -1},
+		{"降序按取值", key(1, "600519", "SH"), key(2, "000001", "SZ"), true, 1},
+		{"取值相同按代码", key(1, "000001", "SZ"), key(1, "600519", "SH"), false, -1},
+		{"降序时取值相同按代码倒序", key(1, "000001", "SZ"), key(1, "600519", "SH"), true, 1},
+		{"代码相同按交易所", key(1, "000001", "SH"), key(1, "000001", "SZ"), false, -1},
+		{"降序时代码相同按交易所倒序", key(1, "000001", "SH"), key(1, "000001", "SZ"), true, 1},
+		{"同一记录", key(1, "600519", "SH"), key(1, "600519", "SH"), true, 0},
+		{"升序时缺少数据排在最后", missing("000001", "SZ"), key(-1e9, "600519", "SH"), false, 1},
+		{"降序时缺少数据同样排在最后", key(-1e9, "600519", "SH"), missing("000001", "SZ"), true, -1},
+		{"缺少数据时按代码", missing("000001", "SZ"), missing("600519", "SH"), false, -1},
+		{"缺少数据时忽略取值", sortKey{value: 9, symbol: "000001", exchange: "SZ"}, missing("600519", "SH"), false, -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareSortKey(tt.a, tt.b, tt.desc); got != tt.want {
+				t.Errorf("compareSortKey = %d，期望 %d", got, tt.want)
+			}
+			if got := compareSortKey(tt.b, tt.a, tt.desc); got != -tt.want {
+				t.Errorf("交换参数后 compareSortKey = %d，期望 %d", got, -tt.want)
+			}
+		})
+	}
+}
+
+// fakeStockRepo 返回固定的股票列表，忽略筛选条件
+type fakeStockRepo struct {
+	repository.StockRepository
+	stocks []*models.Stock
+}
+
+func (r *fakeStockRepo) ListStocks(context.Context, repository.StockListQuery) ([]*models.Stock, int64, error) {
+	return append([]*models.Stock(nil), r.stocks...), int64(len(r.stocks)), nil
+}
+
+// fakeMarketRepo 返回固定的全市场最近日K线
+type fakeMarketRepo struct {
+	repository.MarketRepository
+	bars map[string][]*models.DailyBar
+}
+
+func (r *fakeMarketRepo) GetMarketDailyBars(context.Context, time.Time, time.Time) (map[string][]*models.DailyBar, error) {
+	bars := make(map[string][]*models.DailyBar, len(r.bars))
+	for key, series := range r.bars {
+		bars[key] = series
+	}
+	return bars, nil
+}
+
+// quoteSortService 涨跌幅、成交量、成交额、市值都有相同取值与缺少数据的股票
+//
+//	600519.SH  10 -> 11（+10%） 成交量 500 成交额 5000 总市值 1100 流通市值 1100
+//	000001.SZ  20 -> 22（+10%） 成交量 500 成交额 8000 总市值 4400 流通股本未知
+//	000858.SZ  只有一根K线      成交量 300 成交额 5000 股本未知
+//	300750.SZ  40 -> 36（-10%） 成交量 800 成交额 9000 总市值 1800 流通市值 1440
+//	000001.SH、830799.BJ 没有行情
+func quoteSortService(t *testing.T) *MarketService {
+	t.Helper()
+	live, err := config.NewLive(&config.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(d int) time.Time { return time.Date(2026, 10, d, 0, 0, 0, 0, time.UTC) }
+	bar := func(d int, close float64, volume int64, amount float64) *models.DailyBar {
+		return &models.DailyBar{Date: day(d), Close: close, Volume: volume, Amount: amount}
+	}
+	return &MarketService{
+		live: live,
+		stockRepo: &fakeStockRepo{stocks: []*models.Stock{
+			{Symbol: "000001", Exchange: "SH"},
+			{Symbol: "300750", Exchange: "SZ", TotalShare: 50, FloatShare: 40},
+			{Symbol: "000858", Exchange: "SZ"},
+			{Symbol: "830799", Exchange: "BJ", TotalShare: 10, FloatShare: 10},
+			{Symbol: "600519", Exchange: "SH", TotalShare: 100, FloatShare: 100},
+			{Symbol: "000001", Exchange: "SZ", TotalShare: 200},
+		}},
+		marketRepo: &fakeMarketRepo{bars: map[string][]*models.DailyBar{
+			"600519.SH": {bar(15, 10, 400, 4000), bar(16, 11, 500, 5000)},
+			"000001.SZ": {bar(14, 19, 100, 1900), bar(15, 20, 100, 2000), bar(16, 22, 500, 8000)},
+			"000858.SZ": {bar(16, 30, 300, 5000)},
+			"300750.SZ": {bar(15, 40, 700, 2800), bar(16, 36, 800, 9000)},
+		}},
+	}
+}
+
+func TestListStocksByQuote(t *testing.T) {
+	s := quoteSortService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		sort string
+		desc bool
+		want string
+	}{
+		{"change_pct", false, "300750.SZ,000001.SZ,600519.SH,000001.SH,000858.SZ,830799.BJ"},
+		{"change_pct", true, "600519.SH,000001.SZ,300750.SZ,830799.BJ,000858.SZ,000001.SH"},
+		{"volume", false, "000858.SZ,000001.SZ,600519.SH,300750.SZ,000001.SH,830799.BJ"},
+		{"volume", true, "300750.SZ,600519.SH,000001.SZ,000858.SZ,830799.BJ,000001.SH"},
+		{"amount", false, "000858.SZ,600519.SH,000001.SZ,300750.SZ,000001.SH,830799.BJ"},
+		{"amount", true, "300750.SZ,000001.SZ,600519.SH,000858.SZ,830799.BJ,000001.SH"},
+		{"market_cap", false, "600519.SH,300750.SZ,000001.SZ,000001.SH,000858.SZ,830799.BJ"},
+		{"market_cap", true, "000001.SZ,300750.SZ,600519.SH,830799.BJ,000858.SZ,000001.SH"},
+		{"float_cap", false, "600519.SH,300750.SZ,000001.SH,000001.SZ,000858.SZ,830799.BJ"},
+		{"float_cap", true, "300750.SZ,600519.SH,830799.BJ,000858.SZ,000001.SZ,000001.SH"},
+	}
+	for _, tt := range tests {
+		name := tt.sort
+		if tt.desc {
+			name += "/desc"
+		}
+		t.Run(name, func(t *testing.T) {
+			query := repository.StockListQuery{Sort: tt.sort, Desc: tt.desc}
+			page, err := s.listStocksByQuote(ctx, query, 0, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stockKeys(page.stocks); got != tt.want {
+				t.Fatalf("排序 %s，期望 %s", got, tt.want)
+			}
+			if page.total != 6 || page.nextCursor != "" {
+				t.Errorf("total = %d，nextCursor = %q", page.total, page.nextCursor)
+			}
+			if _, ok := page.quotes["000001.SH"]; ok || len(page.quotes) != 4 {
+				t.Errorf("没有行情的股票不应附带行情: %v", page.quotes)
+			}
+
+			// 每页两条：相同取值、有无数据的分界跨页时既不重复也不遗漏
+			var keys []string
+			for pages := 0; ; pages++ {
+				if pages > 6 {
+					t.Fatal("翻页没有结束")
+				}
+				page, err := s.listStocksByQuote(ctx, query, 0, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				keys = append(keys, stockKeys(page.stocks))
+				if page.nextCursor == "" {
+					break
+				}
+				if query.Cursor, err = repository.DecodeStockCursor(page.nextCursor); err != nil {
+					t.Fatal(err)
+				}
+				if query.Cursor.Sort != tt.sort || query.Cursor.Desc != tt.desc {
+					t.Fatalf("游标 %+v 与查询不一致", query.Cursor)
+				}
+			}
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("游标翻页 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// 游标指向的记录已不在列表中时，从排在它之后的第一条开始；游标忽略 offset
+func TestListStocksByQuoteCursor(t *testing.T) {
+	s := quoteSortService(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		cursor repository.StockCursor
+		want   string
+	}{
+		{"取值相同时按代码继续", repository.StockCursor{Sort: "change_pct", Value: "10", Symbol: "000001", Exchange: "SZ"}, "600519.SH,000001.SH,000858.SZ,830799.BJ"},
+		{"已删除的记录", repository.StockCursor{Sort: "volume", Value: "500", Symbol: "300001", Exchange: "SZ"}, "600519.SH,300750.SZ,000001.SH,830799.BJ"},
+		{"缺少数据的记录", repository.StockCursor{Sort: "market_cap", Value: "", Symbol: "000001", Exchange: "SH"}, "000858.SZ,830799.BJ"},
+		{"最后一条", repository.StockCursor{Sort: "amount", Desc: true, Value: "", Symbol: "000001", Exchange: "SH"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor := tt.cursor
+			query := repository.StockListQuery{Sort: cursor.Sort, Desc: cursor.Desc, Cursor: &cursor}
+			page, err := s.listStocksByQuote(ctx, query, 3, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := stockKeys(page.stocks); got != tt.want {
+				t.Errorf("游标之后 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+
+	query := repository.StockListQuery{Sort: "volume", Cursor: &repository.StockCursor{Sort: "volume", Value: "abc", Symbol: "600519", Exchange: "SH"}}
+	if _, err := s.listStocksByQuote(ctx, query, 0, 10); err == nil {
+		t.Error("游标取值不是数字时应返回错误")
+	}
+}
+
+func stockKeys(stocks []*models.Stock) string {
+	keys := make([]string, len(stocks))
+	for i, stock := range stocks {
+		keys[i] = stock.Symbol + "." + stock.Exchange
+	}
+	return strings.Join(keys, ",")
+}
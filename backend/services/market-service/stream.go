@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ K线流式接口 ============
+
+// MIMENDJSON 流式响应的媒体类型，每行一个 JSON 对象
+const MIMENDJSON = "application/x-ndjson"
+
+const (
+	klineStreamTimeout   = 10 * time.Minute // 流式K线请求的最长耗时，同时放宽该请求的写超时
+	klineStreamFlushRows = 1000             // 每写出多少行向客户端刷新一次
+)
+
+// StreamKlineData 以 NDJSON 流式返回K线，每行一根K线，字段与 /kline/{symbol} 的 bars 相同
+// 边读取 InfluxDB 结果边写出，不在内存中汇总，用于多年分钟K线等大区间查询。
+// 开始写出后出错时无法再修改状态码，最后一行写出 {"error": "..."}，客户端据此判断数据不完整。
+func (s *MarketService) StreamKlineData(c *gin.Context) {
+	var req KlineRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	rule, err := validation.StreamPeriodRule(req.Period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, rule)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	// 服务的写超时按普通接口配置，流式请求单独放宽
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(klineStreamTimeout))
+
+	ctx := c.Request.Context()
+	w := &ndjsonWriter{c: c, enc: json.NewEncoder(c.Writer)}
+	if req.Period == "1d" {
+		err = s.marketRepo.StreamDailyBars(ctx, req.Symbol, req.Exchange, dateRange.Start, dateRange.End, func(bar *models.DailyBar) error {
+			return w.write(dailyBarToKline(bar))
+		})
+	} else {
+		start, end := dateRange.InExchange(req.Exchange)
+		err = s.marketRepo.StreamMinuteBars(ctx, req.Symbol, req.Exchange, req.Period, start, end, func(bar *models.MinuteBar) error {
+			return w.write(minuteBarToKline(bar))
+		})
+	}
+	w.finish(err)
+}
+
+// ndjsonWriter 逐行写出 NDJSON，首行写出前出错时仍可返回普通的 JSON 错误
+type ndjsonWriter struct {
+	c    *gin.Context
+	enc  *json.Encoder
+	rows int
+}
+
+func (w *ndjsonWriter) start() {
+	w.c.Header("Content-Type", MIMENDJSON)
+	w.c.Status(http.StatusOK)
+}
+
+func (w *ndjsonWriter) write(v interface{}) error {
+	if w.rows == 0 {
+		w.start()
+	}
+	if err := w.enc.Encode(v); err != nil {
+		return err
+	}
+	w.rows++
+	if w.rows%klineStreamFlushRows == 0 {
+		w.c.Writer.Flush()
+	}
+	return nil
+}
+
+// finish 结束响应，err 为读取或写出过程中的错误
+func (w *ndjsonWriter) finish(err error) {
+	if err == nil {
+		if w.rows == 0 {
+			w.start()
+			w.c.Writer.WriteHeaderNow()
+		}
+		return
+	}
+	if w.rows == 0 {
+		respondQueryError(w.c, err)
+		return
+	}
+	log.Printf("K线流式输出中断（已写出 %d 行）: %v", w.rows, err)
+	w.enc.Encode(gin.H{"error": err.Error()})
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/alert"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 提醒规则 ============
+
+// AlertRuleRequest 创建/更新提醒规则请求
+type AlertRuleRequest struct {
+	Name      string `json:"name" binding:"required,max=50"`
+	Symbol    string `json:"symbol" binding:"required"` // symbol.exchange
+	Condition string `json:"condition" binding:"required"`
+	Left      string `json:"left" binding:"max=500"`  // 指标表达式，new_high/new_low 省略时为最高价/最低价；before_event 为事件类型
+	Right     string `json:"right" binding:"max=500"` // 比较对象，表达式或常量
+	Window    int    `json:"window"`                  // new_high/new_low 的回看K线数，默认 250（约 52 周）；before_event 的提前天数，默认 3
+	Enabled   *bool  `json:"enabled"`                 // 省略时启用
+}
+
+// AlertEventsRequest 触发记录查询参数
+type AlertEventsRequest struct {
+	RuleID uint   `form:"rule_id"`
+	Start  string `form:"start"` // YYYY-MM-DD，按K线日期过滤
+	Limit  int    `form:"limit,default=50" binding:"min=1,max=500"`
+}
+
+// GetAlertRules 获取当前用户的提醒规则
+func (s *StrategyService) GetAlertRules(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	rules, err := s.alertRepo.GetByUserID(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": rules,
+	})
+}
+
+// CreateAlertRule 创建提醒规则，保存前校验条件；新规则从下一次同步的最新K线开始检查
+func (s *StrategyService) CreateAlertRule(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	rule := &models.AlertRule{UserID: uid, Enabled: true}
+	if err := applyAlertRuleRequest(rule, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if err := s.alertRepo.Create(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功",
+		"data": rule,
+	})
+}
+
+// GetAlertRule 获取提醒规则详情
+func (s *StrategyService) GetAlertRule(c *gin.Context) {
+	rule, ok := s.loadOwnAlertRule(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": rule,
+	})
+}
+
+// UpdateAlertRule 更新提醒规则
+// 条件或股票变化、由停用改为启用时清空检查游标，从下一次同步的最新K线重新开始，不补发停用期间的事件。
+func (s *StrategyService) UpdateAlertRule(c *gin.Context) {
+	rule, ok := s.loadOwnAlertRule(c)
+	if !ok {
+		return
+	}
+
+	var req AlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	before := *rule
+	if err := applyAlertRuleRequest(rule, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if rule.Symbol != before.Symbol || rule.Exchange != before.Exchange || rule.Condition != before.Condition ||
+		rule.Left != before.Left || rule.Right != before.Right || rule.Window != before.Window ||
+		(rule.Enabled && !before.Enabled) {
+		rule.LastBarAt = nil
+	}
+	if err := s.alertRepo.Update(c.Request.Context(), rule); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": rule,
+	})
+}
+
+// DeleteAlertRule 删除提醒规则及其触发记录
+func (s *StrategyService) DeleteAlertRule(c *gin.Context) {
+	rule, ok := s.loadOwnAlertRule(c)
+	if !ok {
+		return
+	}
+
+	if err := s.alertRepo.Delete(c.Request.Context(), rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// GetAlertEvents 获取当前用户的提醒触发记录，按K线日期倒序
+func (s *StrategyService) GetAlertEvents(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req AlertEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	var since time.Time
+	if req.Start != "" {
+		t, err := time.Parse(validation.DateLayout, req.Start)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "start 格式错误，应为 YYYY-MM-DD"})
+			return
+		}
+		since = t
+	}
+
+	events, err := s.alertRepo.GetEvents(c.Request.Context(), uid, req.RuleID, since, req.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": events,
+	})
+}
+
+// loadOwnAlertRule 读取路径中的提醒规则并检查归属，失败时已写入响应
+func (s *StrategyService) loadOwnAlertRule(c *gin.Context) (*models.AlertRule, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "规则ID错误"})
+		return nil, false
+	}
+
+	rule, err := s.alertRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "提醒规则不存在"})
+		return nil, false
+	}
+	if rule.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return nil, false
+	}
+	return rule, true
+}
+
+// applyAlertRuleRequest 校验股票与条件并写入规则定义，条件按规范化后的形式保存（如补全默认的 left、window）
+func applyAlertRuleRequest(rule *models.AlertRule, req *AlertRuleRequest) error {
+	symbol, exchange, ok := pairs.SplitLeg(req.Symbol)
+	if !ok {
+		return errors.New("symbol 格式错误，应为 symbol.exchange")
+	}
+	cond, err := alert.NewCondition(req.Condition, req.Left, req.Right, req.Window)
+	if err != nil {
+		return err
+	}
+	rule.Name = req.Name
+	rule.Symbol = symbol
+	rule.Exchange = exchange
+	rule.Condition = cond.Kind
+	rule.Left = cond.Left
+	rule.Right = cond.Right
+	rule.Window = cond.Window
+	if req.Enabled != nil {
+		rule.Enabled = *req.Enabled
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 策略批量操作 ============
+
+// maxBatchStrategies 单次批量操作的策略数上限
+const maxBatchStrategies = 100
+
+// 批量操作类型
+const (
+	batchActivate   = "activate"
+	batchDeactivate = "deactivate"
+	batchDelete     = "delete"
+	batchRetag      = "retag"
+)
+
+// BatchStrategiesRequest 批量操作请求，retag 以 tags 替换每个策略的全部标签（传空数组清除标签）
+type BatchStrategiesRequest struct {
+	Action string   `json:"action" binding:"required,oneof=activate deactivate delete retag"`
+	IDs    []uint   `json:"ids" binding:"required,min=1"`
+	Tags   []string `json:"tags"`
+}
+
+// BatchItemResult 单个策略的操作结果
+type BatchItemResult struct {
+	ID      uint   `json:"id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// BatchStrategies 批量启用、停用、删除策略或替换标签（仅策略所有者）
+// 各策略独立执行，单个失败不影响其余策略，结果按请求中的顺序逐个返回（重复的ID只执行一次）。
+func (s *StrategyService) BatchStrategies(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req BatchStrategiesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	ids := uniqueIDs(req.IDs)
+	if len(ids) > maxBatchStrategies {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": fmt.Sprintf("单次最多操作 %d 个策略", maxBatchStrategies)})
+		return
+	}
+	var names []string
+	if req.Action == batchRetag {
+		var err error
+		if names, err = validation.NormalizeTags(req.Tags); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	results := make([]BatchItemResult, 0, len(ids))
+	succeeded := 0
+	for _, id := range ids {
+		result := BatchItemResult{ID: id, Success: true}
+		if err := s.batchApply(ctx, uid, id, req.Action, names); err != nil {
+			result.Success, result.Error = false, err.Error()
+		} else {
+			succeeded++
+		}
+		results = append(results, result)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"succeeded": succeeded,
+			"failed":    len(results) - succeeded,
+			"results":   results,
+		},
+	})
+}
+
+// batchApply 对单个策略执行批量操作，返回的错误信息直接展示给用户
+func (s *StrategyService) batchApply(ctx context.Context, uid, id uint, action string, names []string) error {
+	// 读取结果决定是否写入以及写入时的版本号，读主库
+	strategy, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), id)
+	if err != nil {
+		return errors.New("策略不存在")
+	}
+	if strategy.UserID != uid {
+		return errors.New("无权修改")
+	}
+
+	switch action {
+	case batchActivate, batchDeactivate:
+		return s.batchSetActive(ctx, strategy, action == batchActivate)
+	case batchDelete:
+		if err := s.strategyRepo.Delete(ctx, id); err != nil {
+			return errors.New("删除失败")
+		}
+	case batchRetag:
+		if err := s.applyStrategyTags(ctx, strategy, names); err != nil {
+			return errors.New("保存标签失败")
+		}
+	}
+	return nil
+}
+
+// batchSetActive 启用或停用策略，状态未变化时不写库
+func (s *StrategyService) batchSetActive(ctx context.Context, strategy *models.Strategy, active bool) error {
+	if strategy.IsActive == active {
+		return nil
+	}
+	strategy.IsActive = active
+	if err := s.strategyRepo.Update(ctx, strategy); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			return err
+		}
+		return errors.New("更新失败")
+	}
+	return nil
+}
+
+// uniqueIDs 去重并保持原有顺序
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// fakeStrategyRepo 内存中的策略，updateErrs 与 deleteErrs 指定写入失败的策略
+type fakeStrategyRepo struct {
+	repository.StrategyRepository
+	strategies map[uint]*models.Strategy
+	updateErrs map[uint]error
+	deleteErrs map[uint]error
+	updates    []uint
+}
+
+func (r *fakeStrategyRepo) GetByID(_ context.Context, id uint) (*models.Strategy, error) {
+	strategy, ok := r.strategies[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *strategy
+	return &copied, nil
+}
+
+func (r *fakeStrategyRepo) Update(_ context.Context, strategy *models.Strategy) error {
+	r.updates = append(r.updates, strategy.ID)
+	if err := r.updateErrs[strategy.ID]; err != nil {
+		return err
+	}
+	copied := *strategy
+	r.strategies[strategy.ID] = &copied
+	return nil
+}
+
+func (r *fakeStrategyRepo) Delete(_ context.Context, id uint) error {
+	if err := r.deleteErrs[id]; err != nil {
+		return err
+	}
+	delete(r.strategies, id)
+	return nil
+}
+
+// fakeTagRepo 记录每个策略替换后的标签名，failFor 中的策略保存失败
+type fakeTagRepo struct {
+	repository.TagRepository
+	failFor map[uint]bool
+	tags    map[uint][]string
+}
+
+func (r *fakeTagRepo) EnsureByNames(_ context.Context, userID uint, names []string) ([]*models.Tag, error) {
+	tags := make([]*models.Tag, len(names))
+	for i, name := range names {
+		tags[i] = &models.Tag{ID: uint(i + 1), UserID: userID, Name: name}
+	}
+	return tags, nil
+}
+
+func (r *fakeTagRepo) SetStrategyTags(_ context.Context, strategyID uint, tags []*models.Tag) error {
+	if r.failFor[strategyID] {
+		return errors.New("connection reset")
+	}
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	r.tags[strategyID] = names
+	return nil
+}
+
+// batchFixture 用户 1 拥有策略 1、2、4、5，策略 3 属于用户 2；策略 4 更新时版本冲突，策略 5 写库失败
+func batchFixture() (*StrategyService, *fakeStrategyRepo, *fakeTagRepo) {
+	strategies := &fakeStrategyRepo{
+		strategies: map[uint]*models.Strategy{
+			1: {ID: 1, UserID: 1, IsActive: true},
+			2: {ID: 2, UserID: 1, IsActive: false},
+			3: {ID: 3, UserID: 2, IsActive: true},
+			4: {ID: 4, UserID: 1, IsActive: true},
+			5: {ID: 5, UserID: 1, IsActive: false},
+		},
+		updateErrs: map[uint]error{4: repository.ErrVersionConflict, 5: errors.New("connection reset")},
+		deleteErrs: map[uint]error{5: errors.New("connection reset")},
+	}
+	tags := &fakeTagRepo{failFor: map[uint]bool{5: true}, tags: make(map[uint][]string)}
+	return &StrategyService{strategyRepo: strategies, tagRepo: tags}, strategies, tags
+}
+
+type batchResponse struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Succeeded int               `json:"succeeded"`
+		Failed    int               `json:"failed"`
+		Results   []BatchItemResult `json:"results"`
+	} `json:"data"`
+}
+
+// postBatch 以用户 1 的身份调用批量接口
+func postBatch(t *testing.T, s *StrategyService, req gin.H) (int, batchResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/strategies/batch", func(c *gin.Context) { c.Set("user_id", uint(1)) }, s.BatchStrategies)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/strategies/batch", bytes.NewReader(body)))
+	var resp batchResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应 %s: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// 部分失败：每个策略独立执行并按请求顺序返回结果，重复的ID只执行一次
+func TestBatchStrategiesPartialFailure(t *testing.T) {
+	tests := []struct {
+		name   string
+		action string
+		tags   []string
+		ids    []uint
+		want   []BatchItemResult
+		check  func(t *testing.T, strategies *fakeStrategyRepo, tags *fakeTagRepo)
+	}{
+		{
+			name:   "停用",
+			action: batchDeactivate,
+			ids:    []uint{4, 1, 3, 99, 2, 1, 5},
+			want: []BatchItemResult{
+				{ID: 4, Error: repository.ErrVersionConflict.Error()},
+				{ID: 1, Success: true},
+				{ID: 3, Error: "无权修改"},
+				{ID: 99, Error: "策略不存在"},
+				{ID: 2, Success: true}, // 已停用，不写库
+				{ID: 5, Success: true},
+			},
+			check: func(t *testing.T, strategies *fakeStrategyRepo, _ *fakeTagRepo) {
+				if strategies.strategies[1].IsActive || !strategies.strategies[3].IsActive || !strategies.strategies[4].IsActive {
+					t.Errorf("停用后状态 1=%v 3=%v 4=%v", strategies.strategies[1].IsActive, strategies.strategies[3].IsActive, strategies.strategies[4].IsActive)
+				}
+				if fmt.Sprint(strategies.updates) != "[4 1]" {
+					t.Errorf("写库的策略 %v，期望只有状态变化的 [4 1]", strategies.updates)
+				}
+			},
+		},
+		{
+			name:   "启用",
+			action: batchActivate,
+			ids:    []uint{2, 5, 3},
+			want: []BatchItemResult{
+				{ID: 2, Success: true},
+				{ID: 5, Error: "更新失败"},
+				{ID: 3, Error: "无权修改"},
+			},
+			check: func(t *testing.T, strategies *fakeStrategyRepo, _ *fakeTagRepo) {
+				if !strategies.strategies[2].IsActive || strategies.strategies[5].IsActive {
+					t.Errorf("启用后状态 2=%v 5=%v", strategies.strategies[2].IsActive, strategies.strategies[5].IsActive)
+				}
+			},
+		},
+		{
+			name:   "删除",
+			action: batchDelete,
+			ids:    []uint{3, 1, 5, 99},
+			want: []BatchItemResult{
+				{ID: 3, Error: "无权修改"},
+				{ID: 1, Success: true},
+				{ID: 5, Error: "删除失败"},
+				{ID: 99, Error: "策略不存在"},
+			},
+			check: func(t *testing.T, strategies *fakeStrategyRepo, _ *fakeTagRepo) {
+				_, kept3 := strategies.strategies[3]
+				_, kept1 := strategies.strategies[1]
+				_, kept5 := strategies.strategies[5]
+				if !kept3 || kept1 || !kept5 {
+					t.Errorf("删除后保留 3=%v 1=%v 5=%v，期望只删除策略 1", kept3, kept1, kept5)
+				}
+			},
+		},
+		{
+			name:   "替换标签",
+			action: batchRetag,
+			tags:   []string{" 趋势 ", "动量", "趋势"},
+			ids:    []uint{1, 3, 5, 2},
+			want: []BatchItemResult{
+				{ID: 1, Success: true},
+				{ID: 3, Error: "无权修改"},
+				{ID: 5, Error: "保存标签失败"},
+				{ID: 2, Success: true},
+			},
+			check: func(t *testing.T, _ *fakeStrategyRepo, tags *fakeTagRepo) {
+				if len(tags.tags) != 2 || fmt.Sprint(tags.tags[1]) != "[趋势 动量]" || fmt.Sprint(tags.tags[2]) != "[趋势 动量]" {
+					t.Errorf("替换后的标签 %v，期望只有策略 1、2 为 [趋势 动量]", tags.tags)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, strategies, tags := batchFixture()
+			code, resp := postBatch(t, s, gin.H{"action": tt.action, "ids": tt.ids, "tags": tt.tags})
+			if code != http.StatusOK {
+				t.Fatalf("状态码 %d: %s", code, resp.Msg)
+			}
+			succeeded := 0
+			for _, result := range tt.want {
+				if result.Success {
+					succeeded++
+				}
+			}
+			if resp.Data.Succeeded != succeeded || resp.Data.Failed != len(tt.want)-succeeded {
+				t.Errorf("成功 %d 失败 %d，期望 %d 与 %d", resp.Data.Succeeded, resp.Data.Failed, succeeded, len(tt.want)-succeeded)
+			}
+			if fmt.Sprint(resp.Data.Results) != fmt.Sprint(tt.want) {
+				t.Errorf("结果 %+v\n期望 %+v", resp.Data.Results, tt.want)
+			}
+			tt.check(t, strategies, tags)
+		})
+	}
+}
+
+// 依次执行不同操作，前一次操作的结果影响后一次：删除后的策略不能再修改，他人的策略始终不变
+func TestBatchStrategiesMixedOperations(t *testing.T) {
+	s, strategies, tags := batchFixture()
+	steps := []struct {
+		action string
+		tags   []string
+		ids    []uint
+		want   string
+	}{
+		{batchRetag, []string{"价值"}, []uint{1, 2, 3}, "1:ok 2:ok 3:无权修改"},
+		{batchDelete, nil, []uint{2, 3}, "2:ok 3:无权修改"},
+		{batchActivate, nil, []uint{1, 2, 3}, "1:ok 2:策略不存在 3:无权修改"},
+		{batchRetag, []string{}, []uint{1, 2}, "1:ok 2:策略不存在"},
+		{batchDeactivate, nil, []uint{1, 3}, "1:ok 3:无权修改"},
+	}
+	for _, step := range steps {
+		code, resp := postBatch(t, s, gin.H{"action": step.action, "ids": step.ids, "tags": step.tags})
+		if code != http.StatusOK {
+			t.Fatalf("%s: 状态码 %d: %s", step.action, code, resp.Msg)
+		}
+		var got []string
+		for _, result := range resp.Data.Results {
+			status := "ok"
+			if !result.Success {
+				status = result.Error
+			}
+			got = append(got, fmt.Sprintf("%d:%s", result.ID, status))
+		}
+		if fmt.Sprint(got) != "["+step.want+"]" {
+			t.Errorf("%s %v 的结果 %v，期望 [%s]", step.action, step.ids, got, step.want)
+		}
+	}
+
+	if strategies.strategies[1].IsActive {
+		t.Error("策略 1 最后应为停用")
+	}
+	if len(tags.tags[1]) != 0 || fmt.Sprint(tags.tags[2]) != "[价值]" {
+		t.Errorf("标签 %v，期望策略 1 已清除、策略 2 删除前为 [价值]", tags.tags)
+	}
+	if other := strategies.strategies[3]; other == nil || !other.IsActive {
+		t.Errorf("他人的策略被修改: %+v", other)
+	}
+	if _, ok := tags.tags[3]; ok {
+		t.Error("他人的策略不应被打标签")
+	}
+}
+
+// 去重后超过上限时整个请求被拒绝，提示中的上限与 maxBatchStrategies 一致
+func TestBatchStrategiesLimit(t *testing.T) {
+	s, _, _ := batchFixture()
+	ids := make([]uint, 0, 2*maxBatchStrategies)
+	for id := uint(1); id <= maxBatchStrategies+1; id++ {
+		ids = append(ids, id)
+	}
+	code, resp := postBatch(t, s, gin.H{"action": batchDeactivate, "ids": ids})
+	if want := fmt.Sprintf("单次最多操作 %d 个策略", maxBatchStrategies); code != http.StatusBadRequest || resp.Msg != want {
+		t.Errorf("超过上限: 状态码 %d，提示 %q，期望 %q", code, resp.Msg, want)
+	}
+
+	// 重复的ID不计入上限
+	ids = ids[:maxBatchStrategies]
+	ids = append(ids, ids...)
+	code, resp = postBatch(t, s, gin.H{"action": batchDeactivate, "ids": ids})
+	if code != http.StatusOK || len(resp.Data.Results) != maxBatchStrategies {
+		t.Errorf("去重后不超过上限: 状态码 %d，结果 %d 条", code, len(resp.Data.Results))
+	}
+}
+
+// 副本尚未复制刚才的停用时，批量启用按主库中的状态与版本号写入
+func TestBatchStrategiesReadsFromPrimary(t *testing.T) {
+	stored := models.Strategy{ID: 1, UserID: 1, IsActive: false, Version: 2}
+	repo := &laggingStrategyRepo{primary: &stored, replica: models.Strategy{ID: 1, UserID: 1, IsActive: true, Version: 1}}
+	s := &StrategyService{strategyRepo: repo}
+
+	code, resp := postBatch(t, s, gin.H{"action": batchActivate, "ids": []uint{1}})
+	if code != http.StatusOK || resp.Data.Succeeded != 1 {
+		t.Fatalf("返回 %d: %+v", code, resp)
+	}
+	if !repo.primary.IsActive || repo.primary.Version != 3 {
+		t.Errorf("主库 %+v，期望已启用且版本号为 3", repo.primary)
+	}
+}
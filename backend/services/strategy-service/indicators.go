@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/indicator"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 自定义指标 ============
+
+// indicatorNamePattern 自定义指标名称：字母开头，字母、数字、下划线，便于在策略参数中引用
+var indicatorNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{0,49}$`)
+
+// CustomIndicatorRequest 创建/更新自定义指标请求
+type CustomIndicatorRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Expression  string `json:"expression" binding:"required"`
+	Description string `json:"description" binding:"max=200"`
+}
+
+// IndicatorValuesRequest 计算自定义指标请求
+type IndicatorValuesRequest struct {
+	Symbol string `form:"symbol" binding:"required"` // symbol.exchange
+	Start  string `form:"start"`
+	End    string `form:"end"`
+}
+
+// GetCustomIndicators 获取当前用户的自定义指标
+func (s *StrategyService) GetCustomIndicators(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	indicators, err := s.indicatorRepo.GetByUserID(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": indicators,
+	})
+}
+
+// CreateCustomIndicator 创建自定义指标，保存前校验表达式
+func (s *StrategyService) CreateCustomIndicator(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req CustomIndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	ind := &models.CustomIndicator{UserID: uid}
+	if err := applyIndicatorRequest(ind, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if existing, err := s.indicatorRepo.GetByNames(ctx, uid, []string{ind.Name}); err == nil && len(existing) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"code": 409, "msg": "指标名称已存在"})
+		return
+	}
+	if err := s.indicatorRepo.Create(ctx, ind); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功",
+		"data": ind,
+	})
+}
+
+// GetCustomIndicator 获取自定义指标详情
+func (s *StrategyService) GetCustomIndicator(c *gin.Context) {
+	ind, ok := s.loadOwnIndicator(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": ind,
+	})
+}
+
+// UpdateCustomIndicator 更新自定义指标；改名后引用旧名称的策略需同步修改参数
+func (s *StrategyService) UpdateCustomIndicator(c *gin.Context) {
+	ind, ok := s.loadOwnIndicator(c)
+	if !ok {
+		return
+	}
+
+	var req CustomIndicatorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	oldName := ind.Name
+	if err := applyIndicatorRequest(ind, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if ind.Name != oldName {
+		if existing, err := s.indicatorRepo.GetByNames(ctx, ind.UserID, []string{ind.Name}); err == nil && len(existing) > 0 {
+			c.JSON(http.StatusConflict, gin.H{"code": 409, "msg": "指标名称已存在"})
+			return
+		}
+	}
+	if err := s.indicatorRepo.Update(ctx, ind); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": ind,
+	})
+}
+
+// DeleteCustomIndicator 删除自定义指标
+func (s *StrategyService) DeleteCustomIndicator(c *gin.Context) {
+	ind, ok := s.loadOwnIndicator(c)
+	if !ok {
+		return
+	}
+
+	if err := s.indicatorRepo.Delete(c.Request.Context(), ind.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// GetCustomIndicatorValues 按日K线计算自定义指标
+// 开始日之前按表达式所需的K线数多取数据预热，返回区间内的值，数据不足的交易日为 null。
+func (s *StrategyService) GetCustomIndicatorValues(c *gin.Context) {
+	ind, ok := s.loadOwnIndicator(c)
+	if !ok {
+		return
+	}
+
+	var req IndicatorValuesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	symbol, exchange, ok := pairs.SplitLeg(req.Symbol)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "symbol 格式错误，应为 symbol.exchange"})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(req.Start, req.End, validation.RangeRule{
+		DefaultDays: 120,
+		MaxDays:     365 * 10,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	expr, err := indicator.Compile(ind.Expression)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	// 交易日约为自然日的 2/3，按 1.5 倍换算并留出节假日余量
+	warmupDays := expr.Lookback()*3/2 + 10
+	ctx := c.Request.Context()
+	bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, dateRange.Start.AddDate(0, 0, -warmupDays), dateRange.End)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询行情失败: " + err.Error()})
+		return
+	}
+
+	series := indicator.FromDailyBars(bars)
+	values := expr.Eval(series)
+	points := make([]gin.H, 0, len(values))
+	for i, v := range values {
+		if series.Time[i].Before(dateRange.Start) {
+			continue
+		}
+		point := gin.H{"time": series.Time[i].Format(validation.DateLayout), "value": nil}
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			point["value"] = v
+		}
+		points = append(points, point)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"name":       ind.Name,
+			"expression": ind.Expression,
+			"symbol":     symbol,
+			"exchange":   exchange,
+			"lookback":   expr.Lookback(),
+			"values":     points,
+			"count":      len(points),
+		},
+	})
+}
+
+// loadOwnIndicator 读取路径中的自定义指标并检查归属，失败时已写入响应
+func (s *StrategyService) loadOwnIndicator(c *gin.Context) (*models.CustomIndicator, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "指标ID错误"})
+		return nil, false
+	}
+
+	ind, err := s.indicatorRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "指标不存在"})
+		return nil, false
+	}
+	if ind.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return nil, false
+	}
+	return ind, true
+}
+
+// applyIndicatorRequest 校验名称与表达式并写入指标定义
+func applyIndicatorRequest(ind *models.CustomIndicator, req *CustomIndicatorRequest) error {
+	if !indicatorNamePattern.MatchString(req.Name) {
+		return fmt.Errorf("指标名称只能包含字母、数字和下划线，以字母开头，最多 50 个字符")
+	}
+	if _, err := indicator.Compile(req.Expression); err != nil {
+		return fmt.Errorf("表达式错误: %w", err)
+	}
+	ind.Name = req.Name
+	ind.Expression = strings.TrimSpace(req.Expression)
+	ind.Description = req.Description
+	return nil
+}
+
+// strategyIndicatorNames 解析策略参数中引用的自定义指标名称（params.custom_indicators）
+func strategyIndicatorNames(params string) ([]string, error) {
+	if strings.TrimSpace(params) == "" {
+		return nil, nil
+	}
+	var parsed struct {
+		CustomIndicators []string `json:"custom_indicators"`
+	}
+	if err := json.Unmarshal([]byte(params), &parsed); err != nil {
+		return nil, fmt.Errorf("custom_indicators 应为指标名称数组")
+	}
+	return parsed.CustomIndicators, nil
+}
+
+// loadStrategyIndicators 编译策略引用的自定义指标，指标归策略所有者所有；引用不存在的指标时返回错误
+func (s *StrategyService) loadStrategyIndicators(ctx context.Context, userID uint, params string) (map[string]*indicator.Expr, error) {
+	names, err := strategyIndicatorNames(params)
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+
+	indicators, err := s.indicatorRepo.GetByNames(ctx, userID, names)
+	if err != nil {
+		return nil, fmt.Errorf("查询自定义指标失败: %w", err)
+	}
+	exprs := make(map[string]*indicator.Expr, len(indicators))
+	for _, ind := range indicators {
+		expr, err := indicator.Compile(ind.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("自定义指标 %s 表达式错误: %w", ind.Name, err)
+		}
+		exprs[ind.Name] = expr
+	}
+	for _, name := range names {
+		if _, ok := exprs[name]; !ok {
+			return nil, fmt.Errorf("自定义指标不存在: %s", name)
+		}
+	}
+	return exprs, nil
+}
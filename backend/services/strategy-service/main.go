@@ -2,103 +2,118 @@ package main
 
 import (
 	"context"
+	"errors"
+	"log"
 	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
-	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 
+	"stock-analysis-system/backend/pkg/auth"
 	"stock-analysis-system/backend/pkg/config"
 	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/divergence"
+	"stock-analysis-system/backend/pkg/features"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/middleware"
 	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/quota"
 	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/server"
+	"stock-analysis-system/backend/pkg/validation"
 )
 
 // StrategyService 策略服务
 type StrategyService struct {
-	cfg          *config.Config
-	dbManager    *database.Manager
-	strategyRepo repository.StrategyRepository
-	jwtSecret    []byte
+	cfg           *config.Config
+	dbManager     *database.Manager
+	live          *config.Live
+	strategyRepo  repository.StrategyRepository
+	marketRepo    repository.MarketRepository
+	stockRepo     repository.StockRepository
+	tagRepo       repository.TagRepository
+	universeRepo  repository.UniverseRepository
+	indicatorRepo repository.CustomIndicatorRepository
+	alertRepo     repository.AlertRuleRepository
+	keys          *auth.KeySet
+	quotas        *quota.Checker
+	features      *features.Store
 }
 
 // NewStrategyService 创建策略服务
 func NewStrategyService(cfg *config.Config) (*StrategyService, error) {
+	keys, err := auth.LoadKeySet(cfg.Auth, cfg.Server.Mode)
+	if err != nil {
+		return nil, err
+	}
+	// 行情回放按交易所时区划分交易日
+	if err := markettime.Configure(cfg.Calendar); err != nil {
+		return nil, err
+	}
+
 	dbManager, err := database.NewManager(&cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
+	// 配置文件热更新
+	live, err := config.NewLive(cfg)
+	if err != nil {
+		dbManager.Close()
+		return nil, err
+	}
+	live.OnChange(func(c *config.Config) { dbManager.SetLogLevel(c.Log.Level) })
+
 	strategyRepo := repository.NewStrategyRepository(dbManager.Postgres.DB)
-	jwtSecret := []byte(getEnv("JWT_SECRET", "your-secret-key"))
+	marketRepo := repository.NewMarketRepository(dbManager.Influx)
+	stockRepo := repository.NewStockRepository(dbManager.Postgres.DB)
+	tagRepo := repository.NewTagRepository(dbManager.Postgres.DB)
+	universeRepo := repository.NewUniverseRepository(dbManager.Postgres.DB)
+	indicatorRepo := repository.NewCustomIndicatorRepository(dbManager.Postgres.DB)
 
 	return &StrategyService{
-		cfg:          cfg,
-		dbManager:    dbManager,
-		strategyRepo: strategyRepo,
-		jwtSecret:    jwtSecret,
+		cfg:           cfg,
+		dbManager:     dbManager,
+		live:          live,
+		strategyRepo:  strategyRepo,
+		marketRepo:    marketRepo,
+		stockRepo:     stockRepo,
+		tagRepo:       tagRepo,
+		universeRepo:  universeRepo,
+		indicatorRepo: indicatorRepo,
+		alertRepo:     repository.NewAlertRuleRepository(dbManager.Postgres.DB),
+		keys:          keys,
+		quotas:        quota.NewChecker(repository.NewQuotaRepository(dbManager.Postgres.DB)),
+		features:      features.New(live, dbManager.Redis.GetClient()),
 	}, nil
 }
 
 // Close 关闭服务
 func (s *StrategyService) Close() {
+	s.live.Close()
 	if s.dbManager != nil {
 		s.dbManager.Close()
 	}
 }
 
-// AuthMiddleware JWT认证中间件
-func (s *StrategyService) AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "缺少认证信息"})
-			c.Abort()
-			return
-		}
-
-		tokenString := ""
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
-		} else {
-			tokenString = authHeader
-		}
-
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return s.jwtSecret, nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "无效的认证信息"})
-			c.Abort()
-			return
-		}
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			if userID, ok := claims["user_id"].(float64); ok {
-				c.Set("user_id", uint(userID))
-			}
-		}
-
-		c.Next()
-	}
-}
-
 // ============ 策略 CRUD ============
 
 // CreateStrategyRequest 创建策略请求
 type CreateStrategyRequest struct {
 	Name        string   `json:"name" binding:"required,max=100"`
 	Description string   `json:"description"`
-	Type        string   `json:"type" binding:"required,oneof=trend_following mean_reversion multi_factor"`
+	Type        string   `json:"type" binding:"required,oneof=trend_following mean_reversion multi_factor pair_trading"`
 	ClassName   string   `json:"class_name" binding:"required"`
 	Params      string   `json:"params"` // JSON string
 	Symbols     []string `json:"symbols"`
 	IsPublic    bool     `json:"is_public"`
+	Tags        []string `json:"tags"`        // 标签名，不存在的自动创建
+	UniverseID  *uint    `json:"universe_id"` // 引用的股票池，回测按时点成分选股，配对交易策略不支持
+	ExcludeST   bool     `json:"exclude_st"`  // 排除风险警示（ST/*ST）股票
+	Priority    int      `json:"priority"`    // 信号冲突按优先级处理时使用，数值越大优先级越高
 }
 
 // CreateStrategy 创建策略
@@ -111,8 +126,42 @@ func (s *StrategyService) CreateStrategy(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
 		return
 	}
+	s.createStrategy(c, uid, &req)
+}
+
+// createStrategy 校验请求并创建策略，直接写入响应
+func (s *StrategyService) createStrategy(c *gin.Context, uid uint, req *CreateStrategyRequest) {
+	// 配对交易策略：校验参数并以两腿作为股票列表
+	if req.Type == pairs.StrategyType {
+		params, legs, err := normalizePairParams(req.Params, req.Symbols)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+		req.Params, req.Symbols = params, legs
+	}
+	tags, err := validation.NormalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
 
 	ctx := c.Request.Context()
+	if req.UniverseID != nil {
+		if req.Type == pairs.StrategyType {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "配对交易策略不支持股票池"})
+			return
+		}
+		if err := s.checkStrategyUniverse(ctx, uid, *req.UniverseID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
+	}
+
+	if _, err := s.loadStrategyIndicators(ctx, uid, req.Params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
 
 	strategy := &models.Strategy{
 		UserID:      uid,
@@ -123,25 +172,22 @@ func (s *StrategyService) CreateStrategy(c *gin.Context) {
 		Params:      req.Params,
 		IsPublic:    req.IsPublic,
 		IsActive:    true,
-	}
-
-	// 转换 symbols
-	if len(req.Symbols) > 0 {
-		symbolsStr := "{"
-		for i, s := range req.Symbols {
-			if i > 0 {
-				symbolsStr += ","
-			}
-			symbolsStr += s
-		}
-		symbolsStr += "}"
-		strategy.Symbols = symbolsStr
+		UniverseID:  req.UniverseID,
+		ExcludeST:   req.ExcludeST,
+		Priority:    req.Priority,
+		Symbols:     req.Symbols,
 	}
 
 	if err := s.strategyRepo.Create(ctx, strategy); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
 		return
 	}
+	if len(tags) > 0 {
+		if err := s.applyStrategyTags(ctx, strategy, tags); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "保存标签失败"})
+			return
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
@@ -151,6 +197,9 @@ func (s *StrategyService) CreateStrategy(c *gin.Context) {
 }
 
 // GetStrategies 获取策略列表
+// tags 参数（逗号分隔）用于筛选带有全部指定标签的策略。
+// updated_since 参数用于增量同步：只返回之后有变化的策略，并通过 deleted_ids 返回已删除或不再公开的策略，
+// 客户端保存响应中的 next_since 作为下次同步的 updated_since。
 func (s *StrategyService) GetStrategies(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	uid := userID.(uint)
@@ -158,6 +207,22 @@ func (s *StrategyService) GetStrategies(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	strategyType := c.Query("type")
+	tags := validation.ParseTagQuery(c.Query("tags"))
+	symbol, exchange, err := validation.ParseSymbolQuery(c.Query("symbol"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	now := time.Now()
+	updatedSince, err := validation.ParseUpdatedSince(c.Query("updated_since"), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	if updatedSince != nil && symbol != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "updated_since 不能与 symbol 同时使用"})
+		return
+	}
 
 	if page < 1 {
 		page = 1
@@ -168,7 +233,13 @@ func (s *StrategyService) GetStrategies(c *gin.Context) {
 
 	ctx := c.Request.Context()
 
-	strategies, total, err := s.strategyRepo.GetByUserID(ctx, uid, strategyType, page, pageSize)
+	// 按股票查询：只返回自己的策略，并附带最近对该股票产生的信号
+	if symbol != "" {
+		s.getStrategiesBySymbol(c, uid, symbol, exchange, strategyType, tags, page, pageSize)
+		return
+	}
+
+	strategies, total, err := s.strategyRepo.GetByUserID(ctx, uid, strategyType, tags, updatedSince, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
 		return
@@ -176,15 +247,29 @@ func (s *StrategyService) GetStrategies(c *gin.Context) {
 
 	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
 
+	data := gin.H{
+		"list":        strategies,
+		"total":       total,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": totalPages,
+	}
+	if updatedSince != nil {
+		deletedIDs, err := s.strategyRepo.GetDeletedIDs(ctx, uid, *updatedSince)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+			return
+		}
+		if deletedIDs == nil {
+			deletedIDs = []uint{}
+		}
+		data["deleted_ids"] = deletedIDs
+	}
+	data["next_since"] = validation.NextSince(now)
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
-		"data": gin.H{
-			"list":        strategies,
-			"total":       total,
-			"page":        page,
-			"page_size":   pageSize,
-			"total_pages": totalPages,
-		},
+		"data": data,
 	})
 }
 
@@ -225,9 +310,20 @@ type UpdateStrategyRequest struct {
 	Params      string `json:"params"`
 	IsActive    *bool  `json:"is_active,omitempty"`
 	IsPublic    *bool  `json:"is_public,omitempty"`
+	UniverseID  *uint  `json:"universe_id,omitempty"` // 0 表示取消引用股票池
+	ExcludeST   *bool  `json:"exclude_st,omitempty"`
+	Priority    *int   `json:"priority,omitempty"`
+	Version     *int   `json:"version,omitempty"` // 读取策略时的版本号，与当前版本不一致时返回 409
+
+	RegressionEnabled *bool `json:"regression_enabled,omitempty"`                                    // 开启每晚按滚动窗口重新回测
+	RegressionWindow  *int  `json:"regression_window,omitempty" binding:"omitempty,min=30,max=3650"` // 回归回测的滚动窗口（自然日）
+
+	DivergenceAction *string  `json:"divergence_action,omitempty"`                                // 实盘偏离回测预期时的处理：空字符串关闭、notify、deactivate
+	DivergenceRatio  *float64 `json:"divergence_ratio,omitempty" binding:"omitempty,gt=1,lte=10"` // 偏离阈值倍数
 }
 
 // UpdateStrategy 更新策略
+// 以乐观锁保存：请求中的 version 已过期，或读取后被其他请求修改时返回 409，data.current_version 为当前版本号。
 func (s *StrategyService) UpdateStrategy(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	uid := userID.(uint)
@@ -245,7 +341,8 @@ func (s *StrategyService) UpdateStrategy(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	strategy, err := s.strategyRepo.GetByID(ctx, uint(strategyID))
+	// 版本号比较决定是否写入，读主库：副本延迟时会把刚保存过的新版本误判为冲突
+	strategy, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), uint(strategyID))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
 		return
@@ -256,6 +353,10 @@ func (s *StrategyService) UpdateStrategy(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权修改"})
 		return
 	}
+	if req.Version != nil && *req.Version != strategy.Version {
+		middleware.AbortVersionConflict(c, strategy.Version)
+		return
+	}
 
 	// 更新字段
 	if req.Name != "" {
@@ -266,6 +367,19 @@ func (s *StrategyService) UpdateStrategy(c *gin.Context) {
 	}
 	if req.Params != "" {
 		strategy.Params = req.Params
+		if strategy.Type == pairs.StrategyType {
+			params, legs, err := normalizePairParams(req.Params, strategy.SymbolList())
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+				return
+			}
+			strategy.Params = params
+			strategy.Symbols = legs
+		}
+		if _, err := s.loadStrategyIndicators(ctx, uid, strategy.Params); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return
+		}
 	}
 	if req.IsActive != nil {
 		strategy.IsActive = *req.IsActive
@@ -273,8 +387,61 @@ func (s *StrategyService) UpdateStrategy(c *gin.Context) {
 	if req.IsPublic != nil {
 		strategy.IsPublic = *req.IsPublic
 	}
+	if req.ExcludeST != nil {
+		strategy.ExcludeST = *req.ExcludeST
+	}
+	if req.Priority != nil {
+		strategy.Priority = *req.Priority
+	}
+	if req.RegressionEnabled != nil {
+		strategy.RegressionEnabled = *req.RegressionEnabled
+	}
+	if req.RegressionWindow != nil {
+		strategy.RegressionWindow = *req.RegressionWindow
+	}
+	if strategy.RegressionEnabled && (req.RegressionEnabled != nil || req.RegressionWindow != nil) {
+		// 滚动窗口不能超出套餐可回测的历史深度
+		if err := s.quotas.CheckRange(ctx, uid, time.Now().AddDate(0, 0, -strategy.RegressionWindow)); err != nil {
+			middleware.AbortQuota(c, err)
+			return
+		}
+	}
+	if req.DivergenceAction != nil {
+		switch *req.DivergenceAction {
+		case "", divergence.ActionNotify, divergence.ActionDeactivate:
+			strategy.DivergenceAction = *req.DivergenceAction
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "divergence_action 只能为空、notify 或 deactivate"})
+			return
+		}
+	}
+	if req.DivergenceRatio != nil {
+		strategy.DivergenceRatio = *req.DivergenceRatio
+	}
+	if req.UniverseID != nil {
+		if *req.UniverseID == 0 {
+			strategy.UniverseID = nil
+		} else {
+			if strategy.Type == pairs.StrategyType {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "配对交易策略不支持股票池"})
+				return
+			}
+			if err := s.checkStrategyUniverse(ctx, uid, *req.UniverseID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+				return
+			}
+			strategy.UniverseID = req.UniverseID
+		}
+	}
 
 	if err := s.strategyRepo.Update(ctx, strategy); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			// 冲突的修改刚写入主库，读副本可能取到旧版本号
+			if current, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), strategy.ID); err == nil {
+				middleware.AbortVersionConflict(c, current.Version)
+				return
+			}
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
 		return
 	}
@@ -298,7 +465,8 @@ func (s *StrategyService) DeleteStrategy(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	strategy, err := s.strategyRepo.GetByID(ctx, uint(strategyID))
+	// 刚创建的策略可能尚未复制到副本，读主库
+	strategy, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), uint(strategyID))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
 		return
@@ -331,6 +499,7 @@ func (s *StrategyService) GetTradeSignals(c *gin.Context) {
 	strategyID := c.Query("strategy_id")
 	symbol := c.Query("symbol")
 	signalType := c.Query("type")
+	status := c.Query("status")
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
@@ -350,7 +519,7 @@ func (s *StrategyService) GetTradeSignals(c *gin.Context) {
 		}
 		signals, total, err = s.strategyRepo.GetSignalsByStrategyID(ctx, uint(sid), page, pageSize)
 	} else {
-		signals, total, err = s.strategyRepo.GetSignalsByUserID(ctx, uid, symbol, signalType, page, pageSize)
+		signals, total, err = s.strategyRepo.GetSignalsByUserID(ctx, uid, symbol, signalType, status, page, pageSize)
 	}
 
 	if err != nil {
@@ -378,68 +547,97 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	defer service.Close()
 
-	if cfg.Server.Mode == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
+	// 维护模式与功能开关（Redis 中的配置定期刷新）
+	ctx, cancel := context.WithCancel(context.Background())
+	go service.features.Run(ctx, features.DefaultPollInterval)
 
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(corsMiddleware())
+	port := getEnv("STRATEGY_SERVICE_PORT", "8084")
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"service": "strategy-service",
-		})
-	})
+	srv := server.New("strategy-service", cfg,
+		server.WithPort(port),
+		server.WithMiddleware(middleware.Maintenance(service.features)),
+		server.WithDatabaseHealth(service.dbManager),
+		server.WithShutdownHook(func(context.Context) { service.Close() }),
+		server.WithShutdownHook(func(context.Context) { cancel() }),
+	)
 
 	// API路由
-	api := r.Group("/api/v1")
+	api := srv.Router().Group("/api/v1", middleware.Timeout(10*time.Second))
 	{
 		// 策略接口（需要认证）
 		strategy := api.Group("/strategy")
-		strategy.Use(service.AuthMiddleware())
+		strategy.Use(middleware.JWTAuth(service.keys))
 		{
 			strategy.GET("", service.GetStrategies)
-			strategy.POST("", service.CreateStrategy)
+			strategy.POST("", middleware.Quota(service.quotas, quota.Strategies), service.CreateStrategy)
+			strategy.POST("/batch", service.BatchStrategies)
+			strategy.GET("/templates", service.GetStrategyTemplates)
+			strategy.POST("/from-template", middleware.Quota(service.quotas, quota.Strategies), service.CreateStrategyFromTemplate)
 			strategy.GET("/:id", service.GetStrategy)
 			strategy.PUT("/:id", service.UpdateStrategy)
 			strategy.DELETE("/:id", service.DeleteStrategy)
+			strategy.PUT("/:id/tags", service.SetStrategyTags)
+			strategy.GET("/:id/performance-history", service.GetPerformanceHistory)
+			strategy.GET("/:id/divergences", service.GetDivergences)
+			strategy.POST("/:id/signals/generate", service.GenerateSignals)
+		}
+
+		// 股票池接口（需要认证）
+		universes := api.Group("/universes")
+		universes.Use(middleware.JWTAuth(service.keys))
+		{
+			universes.GET("", service.GetUniverses)
+			universes.POST("", service.CreateUniverse)
+			universes.GET("/:id", service.GetUniverse)
+			universes.PUT("/:id", service.UpdateUniverse)
+			universes.DELETE("/:id", service.DeleteUniverse)
+			universes.GET("/:id/members", service.GetUniverseMembers)
+		}
+
+		// 自定义指标接口（需要认证）
+		indicators := api.Group("/indicators")
+		indicators.Use(middleware.JWTAuth(service.keys))
+		{
+			indicators.GET("", service.GetCustomIndicators)
+			indicators.POST("", service.CreateCustomIndicator)
+			indicators.GET("/:id", service.GetCustomIndicator)
+			indicators.PUT("/:id", service.UpdateCustomIndicator)
+			indicators.DELETE("/:id", service.DeleteCustomIndicator)
+			indicators.GET("/:id/values", service.GetCustomIndicatorValues)
+		}
+
+		// 提醒规则接口（需要认证）
+		alerts := api.Group("/alerts")
+		alerts.Use(middleware.JWTAuth(service.keys))
+		{
+			alerts.GET("/rules", service.GetAlertRules)
+			alerts.POST("/rules", middleware.Quota(service.quotas, quota.AlertRules), service.CreateAlertRule)
+			alerts.GET("/rules/:id", service.GetAlertRule)
+			alerts.PUT("/rules/:id", service.UpdateAlertRule)
+			alerts.DELETE("/rules/:id", service.DeleteAlertRule)
+			alerts.GET("/events", service.GetAlertEvents)
 		}
 
 		// 交易信号接口（需要认证）
 		signals := api.Group("/signals")
-		signals.Use(service.AuthMiddleware())
+		signals.Use(middleware.JWTAuth(service.keys))
 		{
 			signals.GET("", service.GetTradeSignals)
+			signals.GET("/policy", service.GetSignalPolicy)
+			signals.PUT("/policy", service.UpdateSignalPolicy)
 		}
 	}
 
-	port := getEnv("STRATEGY_SERVICE_PORT", "8084")
-
-	// 优雅退出
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-	}()
-
-	r.Run(":" + port)
-}
+	// 分钟K线回放（WebSocket 长连接，不设置接口超时），按功能开关 replay_ws 灰度开放
+	replayGroup := srv.Router().Group("/api/v1/replay")
+	replayGroup.Use(middleware.WebSocketBearer(), middleware.JWTAuth(service.keys), middleware.FeatureGate(service.features, "replay_ws"))
+	{
+		replayGroup.GET("/ws", srv.Streams().Middleware("websocket"), service.ReplayWS)
+	}
 
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
+	if err := srv.Run(); err != nil {
+		log.Fatalf("服务启动失败: %v", err)
 	}
 }
 
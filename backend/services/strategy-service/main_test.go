@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// laggingStrategyRepo 写入只到主库，副本停留在写入前的状态；只有 WithPrimary 标记的读取能看到新版本
+type laggingStrategyRepo struct {
+	repository.StrategyRepository
+	primary *models.Strategy
+	replica models.Strategy
+}
+
+func (r *laggingStrategyRepo) GetByID(ctx context.Context, _ uint) (*models.Strategy, error) {
+	copied := r.replica
+	if database.IsPrimary(ctx) {
+		copied = *r.primary
+	}
+	return &copied, nil
+}
+
+func (r *laggingStrategyRepo) Update(_ context.Context, strategy *models.Strategy) error {
+	if strategy.Version != r.primary.Version {
+		return repository.ErrVersionConflict
+	}
+	strategy.Version++
+	copied := *strategy
+	r.primary = &copied
+	return nil
+}
+
+func putStrategy(t *testing.T, s *StrategyService, req gin.H) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/strategies/:id", func(c *gin.Context) { c.Set("user_id", uint(1)) }, s.UpdateStrategy)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/strategies/1", bytes.NewReader(body)))
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应 %s: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// 副本尚未复制上一次保存时，携带最新版本号的连续修改不应返回 409
+func TestUpdateStrategyReadsVersionFromPrimary(t *testing.T) {
+	stored := models.Strategy{ID: 1, UserID: 1, Name: "均线", Version: 1}
+	repo := &laggingStrategyRepo{primary: &stored, replica: stored}
+	s := &StrategyService{strategyRepo: repo}
+
+	for version, name := range []string{"均线v2", "均线v3"} {
+		code, resp := putStrategy(t, s, gin.H{"name": name, "version": version + 1})
+		if code != http.StatusOK {
+			t.Fatalf("第 %d 次修改返回 %d: %v", version+1, code, resp)
+		}
+	}
+	if repo.primary.Version != 3 || repo.primary.Name != "均线v3" || repo.replica.Version != 1 {
+		t.Errorf("主库 %+v，副本版本 %d", repo.primary, repo.replica.Version)
+	}
+
+	// 过期的版本号仍返回 409，current_version 取自主库
+	code, resp := putStrategy(t, s, gin.H{"name": "均线", "version": 2})
+	if code != http.StatusConflict {
+		t.Fatalf("过期版本号返回 %d: %v", code, resp)
+	}
+	if data, _ := resp["data"].(map[string]interface{}); data["current_version"] != float64(3) {
+		t.Errorf("current_version = %v，期望 3", data["current_version"])
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/risk"
+)
+
+// pairSignalHistoryDays 生成配对交易信号时回放的自然日数，用于还原当前持仓状态
+const pairSignalHistoryDays = 365
+
+// ============ 配对交易 ============
+
+// normalizePairParams 校验配对交易参数，返回补齐默认值后的参数与两腿股票
+func normalizePairParams(params string, symbols []string) (string, []string, error) {
+	cfg, err := pairs.ParseConfig(params, symbols)
+	if err != nil {
+		return "", nil, err
+	}
+	normalized, err := json.Marshal(cfg)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(normalized), []string{cfg.LegA, cfg.LegB}, nil
+}
+
+// GenerateSignals 按最新行情运行配对交易策略
+// 回放近一年的价差得到当前持仓状态；最后一个交易日产生开平仓信号时为两腿各生成一条交易信号，
+// 经用户的信号聚合规则（去重、冲突处理）后保存。
+func (s *StrategyService) GenerateSignals(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	strategyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "策略ID错误"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	strategy, err := s.strategyRepo.GetByID(ctx, uint(strategyID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
+		return
+	}
+	if strategy.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权操作"})
+		return
+	}
+	if strategy.Type != pairs.StrategyType {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "仅支持配对交易策略"})
+		return
+	}
+
+	cfg, err := pairs.ParseConfig(strategy.Params, strategy.SymbolList())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	// 策略排除 ST 时，任一腿当前处于风险警示则不生成信号
+	if strategy.ExcludeST {
+		for _, leg := range []string{cfg.LegA, cfg.LegB} {
+			symbol, exchange, _ := pairs.SplitLeg(leg)
+			if stock, err := s.stockRepo.GetBySymbol(ctx, symbol, exchange); err == nil && stock.IsST() {
+				c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": fmt.Sprintf("%s 处于风险警示（%s），策略已设置排除 ST", leg, stock.RiskWarning)})
+				return
+			}
+		}
+	}
+
+	end := time.Now()
+	start := end.AddDate(0, 0, -pairSignalHistoryDays-cfg.Warmup()*2)
+	closes := make([]map[string]float64, 2)
+	for i, leg := range []string{cfg.LegA, cfg.LegB} {
+		symbol, exchange, _ := pairs.SplitLeg(leg)
+		bars, err := s.marketRepo.GetDailyBars(ctx, symbol, exchange, start, end)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询行情失败: " + err.Error()})
+			return
+		}
+		closes[i] = risk.ClosesByDate(bars)
+	}
+
+	points := pairs.Spread(closes[0], closes[1], cfg)
+	if len(points) == 0 || points[len(points)-1].ZScore == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "共同交易日不足，无法计算价差 z-score"})
+		return
+	}
+	latest := points[len(points)-1]
+
+	// 当前持仓由最近一次信号决定
+	signals := pairs.Signals(points, cfg)
+	position := 0
+	var signal *pairs.Signal
+	if len(signals) > 0 {
+		last := signals[len(signals)-1]
+		if last.Action == pairs.ActionOpenLong || last.Action == pairs.ActionOpenShort {
+			position = last.Direction
+		}
+		if last.Date == latest.Date {
+			signal = last
+		}
+	}
+
+	var records []*models.TradeSignal
+	if signal != nil {
+		reason := fmt.Sprintf("配对交易 %s %s/%s z=%.2f 对冲比率=%.4f",
+			signal.Action, cfg.LegA, cfg.LegB, signal.ZScore, signal.HedgeRatio)
+		for _, leg := range signal.Legs {
+			symbol, exchange, _ := pairs.SplitLeg(leg.Symbol)
+			records = append(records, &models.TradeSignal{
+				StrategyID: strategy.ID,
+				Symbol:     symbol,
+				Exchange:   exchange,
+				SignalType: leg.Side,
+				Price:      leg.Price,
+				Reason:     reason,
+				Status:     models.SignalStatusActive,
+			})
+		}
+	}
+
+	// 保存前按用户的聚合规则去重并处理与其他策略的冲突
+	outcome, err := s.persistSignals(ctx, strategy, records)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"date":        latest.Date,
+			"zscore":      *latest.ZScore,
+			"hedge_ratio": latest.HedgeRatio,
+			"spread":      latest.Spread,
+			"position":    position,
+			"signal":      signal,
+			"created":     outcome.Created,
+			"suppressed":  outcome.Suppressed,
+			"cancelled":   outcome.Cancelled,
+		},
+	})
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 策略定期回归表现 ============
+
+// 表现对比：最近 recentRuns 次回归回测与之前的回归回测（历史预期）比较，两段都至少 minCompareRuns 次才给出结论
+const (
+	recentRuns     = 20
+	minCompareRuns = 5
+)
+
+// PerformanceSummary 最近表现与历史预期的对比
+type PerformanceSummary struct {
+	Runs                 int     `json:"runs"`
+	Sufficient           bool    `json:"sufficient"` // 回归次数是否足以比较
+	ExpectedSharpe       float64 `json:"expected_sharpe"`
+	ExpectedSharpeStd    float64 `json:"expected_sharpe_std"`
+	ExpectedAnnualReturn float64 `json:"expected_annual_return"`
+	RecentSharpe         float64 `json:"recent_sharpe"`
+	RecentAnnualReturn   float64 `json:"recent_annual_return"`
+	Degrading            bool    `json:"degrading"` // 最近平均夏普比率低于历史预期一个标准差以上
+}
+
+// GetPerformanceHistory 策略每晚回归回测的结果序列，以及最近表现与历史预期的对比
+func (s *StrategyService) GetPerformanceHistory(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	strategyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "策略ID错误"})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(c.Query("start_date"), c.Query("end_date"), validation.RangeRule{
+		DefaultDays: 365,
+		MaxDays:     365 * 5,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	strategy, err := s.strategyRepo.GetByID(ctx, uint(strategyID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
+		return
+	}
+	if strategy.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return
+	}
+
+	history, err := s.strategyRepo.GetPerformanceHistory(ctx, strategy.ID, dateRange.Start, dateRange.End)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"strategy_id":        strategy.ID,
+			"regression_enabled": strategy.RegressionEnabled,
+			"regression_window":  strategy.RegressionWindow,
+			"history":            history,
+			"summary":            summarizePerformance(history),
+		},
+	})
+}
+
+// summarizePerformance 以最近 recentRuns 次之前的回归结果作为历史预期，比较最近的平均表现
+func summarizePerformance(history []*models.StrategyPerformance) *PerformanceSummary {
+	summary := &PerformanceSummary{Runs: len(history)}
+	split := len(history) - recentRuns
+	if split < minCompareRuns {
+		split = len(history) / 2
+	}
+	expected, recent := history[:split], history[split:]
+	if len(expected) < minCompareRuns || len(recent) < minCompareRuns {
+		return summary
+	}
+
+	summary.Sufficient = true
+	summary.ExpectedSharpe, summary.ExpectedSharpeStd = meanStd(expected, func(p *models.StrategyPerformance) float64 { return p.SharpeRatio })
+	summary.ExpectedAnnualReturn, _ = meanStd(expected, func(p *models.StrategyPerformance) float64 { return p.AnnualReturn })
+	summary.RecentSharpe, _ = meanStd(recent, func(p *models.StrategyPerformance) float64 { return p.SharpeRatio })
+	summary.RecentAnnualReturn, _ = meanStd(recent, func(p *models.StrategyPerformance) float64 { return p.AnnualReturn })
+	summary.Degrading = summary.RecentSharpe < summary.ExpectedSharpe-summary.ExpectedSharpeStd
+	return summary
+}
+
+// meanStd 均值与样本标准差
+func meanStd(history []*models.StrategyPerformance, value func(p *models.StrategyPerformance) float64) (float64, float64) {
+	var sum float64
+	for _, p := range history {
+		sum += value(p)
+	}
+	mean := sum / float64(len(history))
+	if len(history) < 2 {
+		return mean, 0
+	}
+	var sq float64
+	for _, p := range history {
+		d := value(p) - mean
+		sq += d * d
+	}
+	return mean, math.Sqrt(sq / float64(len(history)-1))
+}
+
+// ============ 实盘偏离记录 ============
+
+// GetDivergences 策略实盘信号表现偏离回测预期的记录，按时间倒序分页
+// 偏离检查由回测服务在每晚定期回归回测之后执行，见更新策略的 divergence_action / divergence_ratio。
+func (s *StrategyService) GetDivergences(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	strategyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "策略ID错误"})
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	ctx := c.Request.Context()
+	strategy, err := s.strategyRepo.GetByID(ctx, uint(strategyID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
+		return
+	}
+	if strategy.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return
+	}
+
+	list, total, err := s.strategyRepo.GetDivergences(ctx, strategy.ID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":      list,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
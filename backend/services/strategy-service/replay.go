@@ -0,0 +1,224 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/replay"
+	"stock-analysis-system/backend/pkg/server"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// replayWarmupDays 回放日之前用于预热策略指标的自然日数
+const replayWarmupDays = 7
+
+// replayControl 客户端发送的回放控制消息
+type replayControl struct {
+	Action string  `json:"action"` // pause | resume | speed | stop
+	Speed  float64 `json:"speed"`
+}
+
+// ReplayWS 分钟K线回放（WebSocket）
+// 参数：strategy_id、date（YYYY-MM-DD）、symbol（默认策略的第一只股票）、interval（默认 1m）、speed（默认 60 倍速）。
+// 连接建立后依次推送 start、bar/signal、done 事件；客户端可发送 pause/resume/speed/stop 控制回放。
+// 服务关闭时推送 reconnect 事件（data 为重连提示）并以关闭码 1012 关闭连接。
+func (s *StrategyService) ReplayWS(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	strategyID, err := strconv.ParseUint(c.Query("strategy_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "策略ID错误"})
+		return
+	}
+	date, err := time.Parse(validation.DateLayout, c.Query("date"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "date 格式错误，应为 YYYY-MM-DD"})
+		return
+	}
+	interval := c.DefaultQuery("interval", "1m")
+	barInterval, err := replay.ParseInterval(interval)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	speed, err := strconv.ParseFloat(c.DefaultQuery("speed", strconv.Itoa(replay.DefaultSpeed)), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "speed 参数错误"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	strategy, err := s.strategyRepo.GetByID(ctx, uint(strategyID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
+		return
+	}
+	if strategy.UserID != uid && !strategy.IsPublic {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return
+	}
+
+	key := c.Query("symbol")
+	if key == "" {
+		if symbols := strategy.SymbolList(); len(symbols) > 0 {
+			key = symbols[0]
+		}
+	}
+	symbol, exchange, ok := pairs.SplitLeg(key)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "symbol 格式错误，应为 symbol.exchange"})
+		return
+	}
+
+	engine, size, err := replay.NewStrategy(strategy.ClassName, strategy.Params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	custom, err := s.loadStrategyIndicators(ctx, strategy.UserID, strategy.Params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	engine = replay.WithCustomIndicators(engine, custom)
+
+	// 回放交易所时区的整个交易日
+	date = markettime.StartOfDate(date, exchange)
+	nextDay := date.AddDate(0, 0, 1)
+	bars, err := s.marketRepo.GetMinuteBars(ctx, symbol, exchange, interval, date, nextDay)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询分钟K线失败: " + err.Error()})
+		return
+	}
+	if len(bars) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "该交易日没有分钟K线数据"})
+		return
+	}
+	warmup, err := s.marketRepo.GetMinuteBars(ctx, symbol, exchange, interval, date.AddDate(0, 0, -replayWarmupDays), date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询分钟K线失败: " + err.Error()})
+		return
+	}
+
+	player, err := replay.NewPlayer(engine, size, warmup, bars, barInterval, speed)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	start := gin.H{
+		"strategy_id": strategy.ID,
+		"class_name":  strategy.ClassName,
+		"symbol":      symbol,
+		"exchange":    exchange,
+		"date":        date.Format(validation.DateLayout),
+		"interval":    interval,
+		"total":       player.Total(),
+		"warmup":      len(warmup),
+		"state":       player.State(),
+	}
+
+	stream := server.StreamFrom(c)
+	wsServer := websocket.Server{
+		Handshake: selectBearerProtocol,
+		Handler: func(ws *websocket.Conn) {
+			s.runReplay(ws, player, start, stream)
+		},
+	}
+	wsServer.ServeHTTP(c.Writer, c.Request)
+}
+
+// runReplay 在 WebSocket 连接上执行回放，读取客户端控制消息直到连接关闭
+// 服务关闭时停止回放，推送 reconnect 事件后以关闭码 1012 关闭连接。
+func (s *StrategyService) runReplay(ws *websocket.Conn, player *replay.Player, start gin.H, stream *server.Stream) {
+	// 劫持后的连接沿用服务器读写超时，回放可能持续数小时，需要清除
+	ws.SetDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	emit := func(e *replay.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		return websocket.JSON.Send(ws, e)
+	}
+
+	go func() {
+		defer cancel()
+		for {
+			var msg replayControl
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+
+			var state replay.State
+			switch strings.ToLower(msg.Action) {
+			case "pause":
+				state = player.Pause()
+			case "resume":
+				state = player.Resume()
+			case "speed":
+				var err error
+				if state, err = player.SetSpeed(msg.Speed); err != nil {
+					emit(&replay.Event{Type: replay.EventError, Msg: err.Error()})
+					continue
+				}
+			case "stop":
+				return
+			default:
+				emit(&replay.Event{Type: replay.EventError, Msg: "未知的控制指令: " + msg.Action})
+				continue
+			}
+			emit(&replay.Event{Type: replay.EventState, Data: state})
+		}
+	}()
+
+	go func() {
+		select {
+		case <-stream.Draining():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := emit(&replay.Event{Type: replay.EventStart, Data: start}); err != nil {
+		return
+	}
+	player.Run(ctx, emit)
+
+	select {
+	case <-stream.Draining():
+		hint := stream.Hint()
+		mu.Lock()
+		defer mu.Unlock()
+		if websocket.JSON.Send(ws, &replay.Event{Type: replay.EventReconnect, Data: hint}) == nil {
+			server.CloseWebSocket(ws, hint)
+		}
+	default:
+	}
+}
+
+// selectBearerProtocol 客户端通过 "bearer, <token>" 子协议传递 Token 时，应答选择 bearer 子协议
+// 浏览器要求服务端从客户端提供的子协议中选择一个，否则会关闭连接。
+func selectBearerProtocol(config *websocket.Config, _ *http.Request) error {
+	var selected []string
+	for _, p := range config.Protocol {
+		if p == "bearer" {
+			selected = []string{p}
+			break
+		}
+	}
+	config.Protocol = selected
+	return nil
+}
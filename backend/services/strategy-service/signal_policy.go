@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/signals"
+)
+
+// ============ 交易信号聚合 ============
+
+// SignalPolicyRequest 更新信号聚合规则请求
+type SignalPolicyRequest struct {
+	ConflictPolicy string `json:"conflict_policy" binding:"required,oneof=none net priority"`
+	DedupHours     int    `json:"dedup_hours" binding:"min=0,max=720"`
+}
+
+// SuppressedSignal 未保存的信号及原因
+type SuppressedSignal struct {
+	Signal *models.TradeSignal `json:"signal"`
+	Reason string              `json:"reason"`
+}
+
+// signalOutcome 一批信号经过聚合规则后的结果
+type signalOutcome struct {
+	Created    []*models.TradeSignal `json:"created"`
+	Suppressed []*SuppressedSignal   `json:"suppressed"`
+	Cancelled  []*models.TradeSignal `json:"cancelled"`
+}
+
+// GetSignalPolicy 获取当前用户的信号聚合规则
+func (s *StrategyService) GetSignalPolicy(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	policy, err := s.strategyRepo.GetSignalPolicy(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": policy,
+	})
+}
+
+// UpdateSignalPolicy 更新当前用户的信号聚合规则，只影响之后生成的信号
+func (s *StrategyService) UpdateSignalPolicy(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req SignalPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	policy := &models.SignalPolicy{
+		UserID:         uid,
+		ConflictPolicy: req.ConflictPolicy,
+		DedupHours:     req.DedupHours,
+		UpdatedAt:      time.Now(),
+	}
+	if err := signals.ValidPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	if err := s.strategyRepo.SaveSignalPolicy(c.Request.Context(), policy); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "保存失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "保存成功",
+		"data": policy,
+	})
+}
+
+// persistSignals 按用户的聚合规则处理策略新产生的信号：去重、处理与其他策略的冲突，
+// 撤销被抵消或覆盖的已有信号后保存其余信号。同一批信号依次处理，先保存的信号参与后续判断。
+func (s *StrategyService) persistSignals(ctx context.Context, strategy *models.Strategy, records []*models.TradeSignal) (*signalOutcome, error) {
+	outcome := &signalOutcome{
+		Created:    make([]*models.TradeSignal, 0, len(records)),
+		Suppressed: make([]*SuppressedSignal, 0),
+		Cancelled:  make([]*models.TradeSignal, 0),
+	}
+	if len(records) == 0 {
+		return outcome, nil
+	}
+
+	policy, err := s.strategyRepo.GetSignalPolicy(ctx, strategy.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("查询信号聚合规则失败: %w", err)
+	}
+
+	// 冲突按自然日判断，去重按小时窗口判断，取两者中更早的时间加载已有信号
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	if window := now.Add(-time.Duration(policy.DedupHours) * time.Hour); window.Before(since) {
+		since = window
+	}
+	recent, err := s.strategyRepo.GetRecentSignalsByUserID(ctx, strategy.UserID, since)
+	if err != nil {
+		return nil, fmt.Errorf("查询近期信号失败: %w", err)
+	}
+	priorities, err := s.strategyRepo.GetPriorities(ctx, strategy.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("查询策略优先级失败: %w", err)
+	}
+
+	pool := make([]*signals.Entry, 0, len(recent)+len(records))
+	for _, sig := range recent {
+		pool = append(pool, &signals.Entry{Signal: sig, Priority: priorities[sig.StrategyID]})
+	}
+
+	for _, record := range records {
+		record.CreatedAt = now
+		incoming := &signals.Entry{Signal: record, Priority: strategy.Priority}
+		decision := signals.Decide(policy, pool, incoming, now)
+
+		if decision.Cancel != nil {
+			cancelled := decision.Cancel.Signal
+			if err := s.strategyRepo.CancelSignal(ctx, cancelled.ID, decision.CancelNote); err != nil {
+				return nil, fmt.Errorf("撤销交易信号失败: %w", err)
+			}
+			cancelled.Status = models.SignalStatusCancelled
+			cancelled.Note = decision.CancelNote
+			outcome.Cancelled = append(outcome.Cancelled, cancelled)
+			pool = removeEntry(pool, decision.Cancel)
+		}
+
+		if !decision.Accept {
+			outcome.Suppressed = append(outcome.Suppressed, &SuppressedSignal{Signal: record, Reason: decision.Reason})
+			continue
+		}
+		if err := s.strategyRepo.CreateSignal(ctx, record); err != nil {
+			return nil, fmt.Errorf("保存交易信号失败: %w", err)
+		}
+		outcome.Created = append(outcome.Created, record)
+		pool = append(pool, incoming)
+	}
+	return outcome, nil
+}
+
+// removeEntry 从信号池中移除指定信号
+func removeEntry(pool []*signals.Entry, target *signals.Entry) []*signals.Entry {
+	for i, e := range pool {
+		if e == target {
+			return append(pool[:i], pool[i+1:]...)
+		}
+	}
+	return pool
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+)
+
+// ============ 按股票查询策略 ============
+
+// 按股票查询时附带的最近信号
+const (
+	symbolSignalDays  = 30 // 最近 30 天（自然日）
+	symbolSignalLimit = 5  // 每个策略最多 5 条
+)
+
+// SymbolStrategy 股票列表包含指定股票的策略及其最近对该股票产生的信号
+type SymbolStrategy struct {
+	*models.Strategy
+	RecentSignals []*models.TradeSignal `json:"recent_signals"` // 按时间倒序
+}
+
+// getStrategiesBySymbol 查询用户股票列表中包含指定股票的策略，回答"哪些策略在交易这只股票、最近给出了什么信号"
+func (s *StrategyService) getStrategiesBySymbol(c *gin.Context, uid uint, symbol, exchange, strategyType string, tags []string, page, pageSize int) {
+	ctx := c.Request.Context()
+
+	strategies, total, err := s.strategyRepo.GetBySymbol(ctx, uid, symbol, exchange, strategyType, tags, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	ids := make([]uint, len(strategies))
+	for i, strategy := range strategies {
+		ids[i] = strategy.ID
+	}
+	signals, err := s.strategyRepo.GetRecentSignalsBySymbol(ctx, ids, symbol, exchange, time.Now().AddDate(0, 0, -symbolSignalDays))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+	byStrategy := make(map[uint][]*models.TradeSignal, len(strategies))
+	for _, signal := range signals {
+		if len(byStrategy[signal.StrategyID]) < symbolSignalLimit {
+			byStrategy[signal.StrategyID] = append(byStrategy[signal.StrategyID], signal)
+		}
+	}
+
+	list := make([]*SymbolStrategy, len(strategies))
+	for i, strategy := range strategies {
+		recent := byStrategy[strategy.ID]
+		if recent == nil {
+			recent = []*models.TradeSignal{}
+		}
+		list[i] = &SymbolStrategy{Strategy: strategy, RecentSignals: recent}
+	}
+
+	totalPages := int((total + int64(pageSize) - 1) / int64(pageSize))
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"list":        list,
+			"total":       total,
+			"page":        page,
+			"page_size":   pageSize,
+			"total_pages": totalPages,
+		},
+	})
+}
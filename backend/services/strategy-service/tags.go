@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 策略标签 ============
+
+// SetStrategyTagsRequest 设置策略标签请求，按名称指定，不存在的标签自动创建
+type SetStrategyTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// SetStrategyTags 替换策略的标签（仅策略所有者）
+func (s *StrategyService) SetStrategyTags(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	strategyID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "策略ID错误"})
+		return
+	}
+
+	var req SetStrategyTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误"})
+		return
+	}
+	names, err := validation.NormalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	// 刚创建的策略可能尚未复制到副本，读主库
+	strategy, err := s.strategyRepo.GetByID(database.WithPrimary(ctx), uint(strategyID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "策略不存在"})
+		return
+	}
+
+	// 检查权限
+	if strategy.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权修改"})
+		return
+	}
+
+	if err := s.applyStrategyTags(ctx, strategy, names); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "保存标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": strategy,
+	})
+}
+
+// applyStrategyTags 以策略所有者的标签替换策略的全部标签
+func (s *StrategyService) applyStrategyTags(ctx context.Context, strategy *models.Strategy, names []string) error {
+	tags, err := s.tagRepo.EnsureByNames(ctx, strategy.UserID, names)
+	if err != nil {
+		return err
+	}
+	if err := s.tagRepo.SetStrategyTags(ctx, strategy.ID, tags); err != nil {
+		return err
+	}
+	strategy.Tags = tags
+	return nil
+}
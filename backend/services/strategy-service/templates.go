@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/templates"
+)
+
+// ============ 策略模板 ============
+
+// GetStrategyTemplates 内置策略模板列表（策略类型、策略类与参数说明）
+func (s *StrategyService) GetStrategyTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": templates.All(),
+	})
+}
+
+// CreateFromTemplateRequest 按模板创建策略请求
+// params 覆盖模板参数的默认值，未指定的参数使用默认值；name 为空时使用模板名称。
+type CreateFromTemplateRequest struct {
+	Template    string             `json:"template" binding:"required"`
+	Name        string             `json:"name" binding:"max=100"`
+	Description string             `json:"description"`
+	Params      map[string]float64 `json:"params"`
+	Symbols     []string           `json:"symbols"`
+	IsPublic    bool               `json:"is_public"`
+	Tags        []string           `json:"tags"`
+	UniverseID  *uint              `json:"universe_id"`
+	ExcludeST   bool               `json:"exclude_st"`
+	Priority    int                `json:"priority"`
+}
+
+// CreateStrategyFromTemplate 按内置模板创建策略，策略类型与策略类取自模板
+func (s *StrategyService) CreateStrategyFromTemplate(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req CreateFromTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	tmpl, ok := templates.Get(req.Template)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "模板不存在"})
+		return
+	}
+	params, err := tmpl.BuildParams(req.Params)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	name, description := req.Name, req.Description
+	if name == "" {
+		name = tmpl.Name
+	}
+	if description == "" {
+		description = tmpl.Description
+	}
+	s.createStrategy(c, uid, &CreateStrategyRequest{
+		Name:        name,
+		Description: description,
+		Type:        tmpl.Type,
+		ClassName:   tmpl.ClassName,
+		Params:      params,
+		Symbols:     req.Symbols,
+		IsPublic:    req.IsPublic,
+		Tags:        req.Tags,
+		UniverseID:  req.UniverseID,
+		ExcludeST:   req.ExcludeST,
+		Priority:    req.Priority,
+	})
+}
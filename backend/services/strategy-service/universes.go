@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/screener"
+	"stock-analysis-system/backend/pkg/universe"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 股票池 ============
+
+// UniverseRequest 创建/更新股票池请求
+// index 类型需指定 source（指数代码 symbol.exchange）；screener 类型需指定 criteria（选股参数，同选股器接口）；
+// manual 类型需指定 symbols。成分由数据服务每日收盘后生成快照，新建后可调用 /api/v1/sync/universes 回补历史。
+type UniverseRequest struct {
+	Name        string           `json:"name" binding:"required,max=100"`
+	Description string           `json:"description"`
+	Type        string           `json:"type" binding:"required,oneof=index screener manual"`
+	Source      string           `json:"source"`
+	Criteria    *screener.Params `json:"criteria"`
+	MaxMembers  int              `json:"max_members" binding:"min=0"`
+	Symbols     []string         `json:"symbols"`
+	IsActive    *bool            `json:"is_active,omitempty"`
+}
+
+// GetUniverses 获取当前用户的股票池列表
+func (s *StrategyService) GetUniverses(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	universes, err := s.universeRepo.GetByUserID(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": universes,
+	})
+}
+
+// CreateUniverse 创建股票池
+func (s *StrategyService) CreateUniverse(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req UniverseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	u := &models.Universe{UserID: uid, IsActive: true}
+	if err := applyUniverseRequest(u, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	if err := s.universeRepo.Create(c.Request.Context(), u); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功",
+		"data": u,
+	})
+}
+
+// GetUniverse 获取股票池详情，附带最近一次成分快照
+func (s *StrategyService) GetUniverse(c *gin.Context) {
+	u, ok := s.loadOwnUniverse(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	date, err := s.universeRepo.GetLatestSnapshotDate(ctx, u.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询快照失败"})
+		return
+	}
+
+	var snapshotDate string
+	members := make([]*models.UniverseMember, 0)
+	if date != nil {
+		snapshotDate = date.Format(validation.DateLayout)
+		if members, err = s.universeRepo.GetMembers(ctx, u.ID, *date); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询成分失败"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"universe":      u,
+			"snapshot_date": snapshotDate,
+			"members":       members,
+		},
+	})
+}
+
+// UpdateUniverse 更新股票池；类型变化后历史快照保留，新快照按新定义生成
+func (s *StrategyService) UpdateUniverse(c *gin.Context) {
+	u, ok := s.loadOwnUniverse(c)
+	if !ok {
+		return
+	}
+
+	var req UniverseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := applyUniverseRequest(u, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	if err := s.universeRepo.Update(c.Request.Context(), u); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": u,
+	})
+}
+
+// DeleteUniverse 删除股票池及其成分快照，引用它的策略改为使用自身股票列表
+func (s *StrategyService) DeleteUniverse(c *gin.Context) {
+	u, ok := s.loadOwnUniverse(c)
+	if !ok {
+		return
+	}
+
+	if err := s.universeRepo.Delete(c.Request.Context(), u.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// GetUniverseMembers 获取股票池在指定日期的成分（不晚于该日的最近一次快照）
+func (s *StrategyService) GetUniverseMembers(c *gin.Context) {
+	u, ok := s.loadOwnUniverse(c)
+	if !ok {
+		return
+	}
+
+	date := time.Now()
+	if v := c.Query("date"); v != "" {
+		t, err := time.Parse(validation.DateLayout, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "date 格式错误，应为 YYYY-MM-DD"})
+			return
+		}
+		date = t
+	}
+
+	ctx := c.Request.Context()
+	snapshot, err := s.universeRepo.GetLatestSnapshotDate(ctx, u.ID, date)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询快照失败"})
+		return
+	}
+	if snapshot == nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "该日期之前没有成分快照"})
+		return
+	}
+
+	members, err := s.universeRepo.GetMembers(ctx, u.ID, *snapshot)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询成分失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"snapshot_date": snapshot.Format(validation.DateLayout),
+			"list":          members,
+			"total":         len(members),
+		},
+	})
+}
+
+// loadOwnUniverse 读取路径中的股票池并检查归属，失败时已写入响应
+func (s *StrategyService) loadOwnUniverse(c *gin.Context) (*models.Universe, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "股票池ID错误"})
+		return nil, false
+	}
+
+	u, err := s.universeRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "股票池不存在"})
+		return nil, false
+	}
+	if u.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return nil, false
+	}
+	return u, true
+}
+
+// checkStrategyUniverse 校验策略引用的股票池属于策略所有者
+func (s *StrategyService) checkStrategyUniverse(ctx context.Context, userID, universeID uint) error {
+	u, err := s.universeRepo.GetByID(ctx, universeID)
+	if err != nil {
+		return fmt.Errorf("股票池不存在")
+	}
+	if u.UserID != userID {
+		return fmt.Errorf("无权使用该股票池")
+	}
+	return nil
+}
+
+// applyUniverseRequest 校验请求并写入股票池定义，只保留与类型相关的字段
+func applyUniverseRequest(u *models.Universe, req *UniverseRequest) error {
+	if err := universe.ValidType(req.Type); err != nil {
+		return err
+	}
+
+	u.Name = req.Name
+	u.Description = req.Description
+	u.Type = req.Type
+	u.Source = ""
+	u.Criteria = "{}" // jsonb 列不接受空字符串
+	u.MaxMembers = 0
+	u.Symbols = nil
+	if req.IsActive != nil {
+		u.IsActive = *req.IsActive
+	}
+
+	switch req.Type {
+	case models.UniverseTypeIndex:
+		if !isFullCode(req.Source) {
+			return fmt.Errorf("指数股票池需指定 source，格式为 symbol.exchange")
+		}
+		u.Source = strings.ToUpper(req.Source)
+	case models.UniverseTypeScreener:
+		if req.Criteria == nil {
+			return fmt.Errorf("选股股票池需指定 criteria")
+		}
+		if err := req.Criteria.Normalize(); err != nil {
+			return err
+		}
+		criteria, err := json.Marshal(req.Criteria)
+		if err != nil {
+			return err
+		}
+		u.Criteria = string(criteria)
+		u.MaxMembers = req.MaxMembers
+	case models.UniverseTypeManual:
+		if len(req.Symbols) == 0 {
+			return fmt.Errorf("手工股票池需指定 symbols")
+		}
+		for _, symbol := range req.Symbols {
+			if !isFullCode(symbol) {
+				return fmt.Errorf("股票代码格式错误: %s，应为 symbol.exchange", symbol)
+			}
+		}
+		u.Symbols = models.StringArray(req.Symbols)
+	}
+	return nil
+}
+
+// isFullCode 是否为 symbol.exchange 格式
+func isFullCode(code string) bool {
+	parts := strings.Split(code, ".")
+	return len(parts) == 2 && parts[0] != "" && parts[1] != ""
+}
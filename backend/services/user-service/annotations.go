@@ -0,0 +1,277 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/pairs"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 图表标注接口 ============
+
+// maxAnnotationsPerChart 同一用户在同一股票、同一周期上的标注数量上限
+const maxAnnotationsPerChart = 200
+
+// AnnotationPoint 标注锚点，time 为K线时间（日期或日期时间）
+type AnnotationPoint struct {
+	Time  string  `json:"time"`
+	Price float64 `json:"price"`
+}
+
+// AnnotationRequest 创建/更新标注请求
+type AnnotationRequest struct {
+	Symbol string                 `json:"symbol" binding:"required"` // symbol.exchange
+	Period string                 `json:"period" binding:"required"`
+	Type   string                 `json:"type" binding:"required"`
+	Points []AnnotationPoint      `json:"points"`
+	Text   string                 `json:"text" binding:"max=500"`
+	Style  map[string]interface{} `json:"style"`
+}
+
+// AnnotationQuery 查询/清除标注参数，period 为空时表示全部周期
+type AnnotationQuery struct {
+	Symbol string `form:"symbol" binding:"required"` // symbol.exchange
+	Period string `form:"period"`
+}
+
+// GetAnnotations 获取当前用户在某只股票上的标注
+func (s *UserService) GetAnnotations(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	symbol, exchange, period, ok := bindAnnotationQuery(c)
+	if !ok {
+		return
+	}
+
+	annotations, err := s.annotationRepo.List(c.Request.Context(), uid, symbol, exchange, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": annotations,
+	})
+}
+
+// CreateAnnotation 创建标注
+func (s *UserService) CreateAnnotation(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req AnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	annotation := &models.ChartAnnotation{UserID: uid}
+	if err := applyAnnotationRequest(annotation, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	count, err := s.annotationRepo.Count(ctx, uid, annotation.Symbol, annotation.Exchange, annotation.Period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+	if count >= maxAnnotationsPerChart {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": fmt.Sprintf("同一图表最多 %d 个标注", maxAnnotationsPerChart)})
+		return
+	}
+	if err := s.annotationRepo.Create(ctx, annotation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功",
+		"data": annotation,
+	})
+}
+
+// UpdateAnnotation 更新标注，可移动到其他周期
+func (s *UserService) UpdateAnnotation(c *gin.Context) {
+	annotation, ok := s.loadOwnAnnotation(c)
+	if !ok {
+		return
+	}
+
+	var req AnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if err := applyAnnotationRequest(annotation, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	if err := s.annotationRepo.Update(c.Request.Context(), annotation); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": annotation,
+	})
+}
+
+// DeleteAnnotation 删除标注
+func (s *UserService) DeleteAnnotation(c *gin.Context) {
+	annotation, ok := s.loadOwnAnnotation(c)
+	if !ok {
+		return
+	}
+
+	if err := s.annotationRepo.Delete(c.Request.Context(), annotation.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// ClearAnnotations 清除当前用户在某只股票（某周期）上的全部标注
+func (s *UserService) ClearAnnotations(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	symbol, exchange, period, ok := bindAnnotationQuery(c)
+	if !ok {
+		return
+	}
+
+	deleted, err := s.annotationRepo.DeleteBySymbol(c.Request.Context(), uid, symbol, exchange, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+		"data": gin.H{"deleted": deleted},
+	})
+}
+
+// bindAnnotationQuery 解析查询参数中的股票与周期，失败时已写入响应
+func bindAnnotationQuery(c *gin.Context) (symbol, exchange, period string, ok bool) {
+	var query AnnotationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return "", "", "", false
+	}
+	symbol, exchange, ok = pairs.SplitLeg(query.Symbol)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "symbol 格式错误，应为 symbol.exchange"})
+		return "", "", "", false
+	}
+	if query.Period != "" {
+		if _, err := validation.PeriodRule(query.Period); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+			return "", "", "", false
+		}
+	}
+	return symbol, exchange, query.Period, true
+}
+
+// loadOwnAnnotation 读取路径中的标注并检查归属，失败时已写入响应
+func (s *UserService) loadOwnAnnotation(c *gin.Context) (*models.ChartAnnotation, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "标注ID错误"})
+		return nil, false
+	}
+
+	annotation, err := s.annotationRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "标注不存在"})
+		return nil, false
+	}
+	if annotation.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+		return nil, false
+	}
+	return annotation, true
+}
+
+// applyAnnotationRequest 按标注类型校验锚点与文字，并写入标注
+func applyAnnotationRequest(annotation *models.ChartAnnotation, req *AnnotationRequest) error {
+	symbol, exchange, ok := pairs.SplitLeg(req.Symbol)
+	if !ok {
+		return fmt.Errorf("symbol 格式错误，应为 symbol.exchange")
+	}
+	if _, err := validation.PeriodRule(req.Period); err != nil {
+		return err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	switch req.Type {
+	case models.AnnotationTrendLine:
+		if len(req.Points) != 2 {
+			return fmt.Errorf("趋势线需要 2 个锚点")
+		}
+	case models.AnnotationHorizontal:
+		if len(req.Points) != 1 {
+			return fmt.Errorf("水平线需要 1 个锚点")
+		}
+	case models.AnnotationText:
+		if len(req.Points) != 1 {
+			return fmt.Errorf("文字标注需要 1 个锚点")
+		}
+		if text == "" {
+			return fmt.Errorf("文字标注内容不能为空")
+		}
+	default:
+		return fmt.Errorf("不支持的标注类型: %s，可选 trend_line/horizontal/text", req.Type)
+	}
+	for _, p := range req.Points {
+		// 水平线只按价格定位，其余类型锚点需要时间
+		if p.Time == "" && req.Type != models.AnnotationHorizontal {
+			return fmt.Errorf("锚点缺少时间")
+		}
+		if p.Price <= 0 {
+			return fmt.Errorf("锚点价格必须大于0")
+		}
+	}
+
+	points, err := json.Marshal(req.Points)
+	if err != nil {
+		return err
+	}
+	style := []byte("{}")
+	if len(req.Style) > 0 {
+		if style, err = json.Marshal(req.Style); err != nil {
+			return fmt.Errorf("样式格式错误")
+		}
+	}
+
+	annotation.Symbol = symbol
+	annotation.Exchange = exchange
+	annotation.Period = req.Period
+	annotation.Type = req.Type
+	annotation.Points = string(points)
+	annotation.Text = text
+	annotation.Style = string(style)
+	return nil
+}
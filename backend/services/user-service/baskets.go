@@ -0,0 +1,288 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/basket"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 股票篮子（虚拟指数）接口 ============
+
+const (
+	basketDefaultBaseDays = 365             // 未指定基日时从一年前开始计算点位
+	basketMaxBaseYears    = 10              // 基日最早为十年前
+	basketBackfillTimeout = 5 * time.Minute // 后台计算历史点位的超时时间
+)
+
+// BasketLegRequest 篮子成分股
+type BasketLegRequest struct {
+	Symbol string  `json:"symbol" binding:"required"` // symbol.exchange
+	Weight float64 `json:"weight"`                    // 省略时等权，需全部指定或全部省略
+}
+
+// BasketRequest 创建或修改篮子请求
+type BasketRequest struct {
+	Name        string             `json:"name" binding:"required,max=50"`
+	Description string             `json:"description"`
+	Symbols     []BasketLegRequest `json:"symbols" binding:"required,min=1"`
+	BaseDate    string             `json:"base_date"` // YYYY-MM-DD，默认一年前
+}
+
+// parse 校验并归一化成分股，解析基日
+func (r *BasketRequest) parse(now time.Time) (models.BasketLegs, time.Time, error) {
+	legs := make([]basket.Leg, 0, len(r.Symbols))
+	for _, item := range r.Symbols {
+		leg, err := basket.NewLeg(item.Symbol, item.Weight)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		legs = append(legs, leg)
+	}
+	legs, err := basket.Normalize(legs)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	local := now.In(markettime.Location(""))
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	baseDate := today.AddDate(0, 0, -basketDefaultBaseDays)
+	if r.BaseDate != "" {
+		baseDate, err = time.Parse(validation.DateLayout, r.BaseDate)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("base_date 格式错误，应为 YYYY-MM-DD")
+		}
+		if baseDate.After(today) || baseDate.Before(today.AddDate(-basketMaxBaseYears, 0, 0)) {
+			return nil, time.Time{}, fmt.Errorf("base_date 应在最近 %d 年内且不晚于今天", basketMaxBaseYears)
+		}
+	}
+	return basket.ToModel(legs), baseDate, nil
+}
+
+// GetBaskets 获取篮子列表
+func (s *UserService) GetBaskets(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	baskets, err := s.basketRepo.GetByUserID(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": baskets,
+	})
+}
+
+// CreateBasket 创建篮子，历史点位在后台计算
+func (s *UserService) CreateBasket(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req BasketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	legs, baseDate, err := req.parse(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	b := &models.Basket{
+		UserID:      uid,
+		Name:        req.Name,
+		Description: req.Description,
+		Legs:        legs,
+		BaseDate:    baseDate,
+	}
+	if err := s.basketRepo.Create(c.Request.Context(), b); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+	go s.backfillBasket(b)
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功，历史点位正在计算",
+		"data": b,
+	})
+}
+
+// GetBasket 获取篮子详情，附带最近一个交易日的点位
+func (s *UserService) GetBasket(c *gin.Context) {
+	b, ok := s.ownedBasket(c)
+	if !ok {
+		return
+	}
+
+	latest, err := s.basketRepo.GetLatestValue(c.Request.Context(), b.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"basket": b,
+			"latest": latest,
+		},
+	})
+}
+
+// UpdateBasket 修改篮子，成分、权重或基日变化时按新的成分重新计算全部历史点位
+func (s *UserService) UpdateBasket(c *gin.Context) {
+	b, ok := s.ownedBasket(c)
+	if !ok {
+		return
+	}
+
+	var req BasketRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+	if req.BaseDate == "" {
+		req.BaseDate = b.BaseDate.Format(validation.DateLayout)
+	}
+	legs, baseDate, err := req.parse(time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	reset := !baseDate.Equal(b.BaseDate) || basket.Hash(basket.FromModel(legs)) != basket.Hash(basket.FromModel(b.Legs))
+	b.Name = req.Name
+	b.Description = req.Description
+	b.Legs = legs
+	b.BaseDate = baseDate
+	if err := s.basketRepo.Update(c.Request.Context(), b, reset); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
+		return
+	}
+	msg := "更新成功"
+	if reset {
+		go s.backfillBasket(b)
+		msg = "更新成功，历史点位正在重新计算"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  msg,
+		"data": b,
+	})
+}
+
+// DeleteBasket 删除篮子及其点位
+func (s *UserService) DeleteBasket(c *gin.Context) {
+	b, ok := s.ownedBasket(c)
+	if !ok {
+		return
+	}
+
+	if err := s.basketRepo.Delete(c.Request.Context(), b.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// BasketPoint 篮子每日点位，time/close 与K线接口一致，便于按股票的方式绘制
+type BasketPoint struct {
+	Time      string  `json:"time"`
+	Close     float64 `json:"close"`
+	ChangePct float64 `json:"change_pct"`
+	Coverage  float64 `json:"coverage"`
+}
+
+// GetBasketHistory 获取篮子的每日收盘点位，默认最近一年
+func (s *UserService) GetBasketHistory(c *gin.Context) {
+	b, ok := s.ownedBasket(c)
+	if !ok {
+		return
+	}
+
+	dateRange, err := validation.ParseDateRange(c.Query("start"), c.Query("end"), validation.RangeRule{
+		DefaultDays: 365,
+		MaxDays:     365 * basketMaxBaseYears,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	values, err := s.basketRepo.GetValues(c.Request.Context(), b.ID, dateRange.Start, dateRange.End)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+	points := make([]*BasketPoint, len(values))
+	for i, v := range values {
+		points[i] = &BasketPoint{
+			Time:      v.TradeDate.Format(validation.DateLayout),
+			Close:     v.Value,
+			ChangePct: v.ChangePct,
+			Coverage:  v.Coverage,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"basket_id": b.ID,
+			"name":      b.Name,
+			"start":     dateRange.Start.Format(validation.DateLayout),
+			"end":       dateRange.End.Format(validation.DateLayout),
+			"list":      points,
+		},
+	})
+}
+
+// backfillBasket 在后台计算篮子的历史点位，失败时由数据同步服务每日计算时补齐
+func (s *UserService) backfillBasket(b *models.Basket) {
+	ctx, cancel := context.WithTimeout(context.Background(), basketBackfillTimeout)
+	defer cancel()
+
+	n, err := basket.Update(ctx, s.basketRepo, s.marketRepo, b, time.Now())
+	if err != nil {
+		log.Printf("计算篮子 %d 历史点位失败: %v", b.ID, err)
+		return
+	}
+	log.Printf("篮子 %d 历史点位计算完成，共 %d 个交易日", b.ID, n)
+}
+
+// ownedBasket 读取路径中的篮子并校验归属，失败时已写入响应
+func (s *UserService) ownedBasket(c *gin.Context) (*models.Basket, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "篮子ID错误"})
+		return nil, false
+	}
+
+	b, err := s.basketRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil || b.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问该篮子"})
+		return nil, false
+	}
+	return b, true
+}
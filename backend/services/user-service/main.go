@@ -3,53 +3,103 @@ package main
 import (
 	"context"
 	"errors"
+	"log"
 	"net/http"
 	"os"
-	"os/signal"
 	"strconv"
-	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
 
+	"stock-analysis-system/backend/pkg/auth"
 	"stock-analysis-system/backend/pkg/config"
 	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/markettime"
+	"stock-analysis-system/backend/pkg/middleware"
 	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/quota"
 	"stock-analysis-system/backend/pkg/repository"
+	"stock-analysis-system/backend/pkg/server"
+	"stock-analysis-system/backend/pkg/validation"
 )
 
 // UserService 用户服务
 type UserService struct {
-	cfg       *config.Config
-	dbManager *database.Manager
-	userRepo  repository.UserRepository
-	jwtSecret []byte
+	cfg            *config.Config
+	dbManager      *database.Manager
+	live           *config.Live
+	userRepo       repository.UserRepository
+	stockRepo      repository.StockRepository
+	marketRepo     repository.MarketRepository
+	portfolioRepo  repository.PortfolioRepository
+	tagRepo        repository.TagRepository
+	annotationRepo repository.AnnotationRepository
+	basketRepo     repository.BasketRepository
+	analytics      *analyticsCache
+	keys           *auth.KeySet
+	quotas         *quota.Checker
+	usageRepo      repository.UsageRepository
+	taskRepo       repository.TaskRepository
 }
 
 // NewUserService 创建用户服务
 func NewUserService(cfg *config.Config) (*UserService, error) {
+	keys, err := auth.LoadKeySet(cfg.Auth, cfg.Server.Mode)
+	if err != nil {
+		return nil, err
+	}
+	// 用户服务负责签发 Token，使用 RS256 时必须配置私钥
+	if !keys.CanIssue() {
+		return nil, auth.ErrCannotIssue
+	}
+	// 篮子基日等按交易所时区的日期计算
+	if err := markettime.Configure(cfg.Calendar); err != nil {
+		return nil, err
+	}
+
 	dbManager, err := database.NewManager(&cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
-	userRepo := repository.NewUserRepository(dbManager.Postgres.DB)
+	// 配置文件热更新
+	live, err := config.NewLive(cfg)
+	if err != nil {
+		dbManager.Close()
+		return nil, err
+	}
+	live.OnChange(func(c *config.Config) { dbManager.SetLogLevel(c.Log.Level) })
 
-	jwtSecret := []byte(getEnv("JWT_SECRET", "your-secret-key"))
+	userRepo := repository.NewUserRepository(dbManager.Postgres.DB)
+	stockRepo := repository.NewStockRepository(dbManager.Postgres.DB)
+	marketRepo := repository.NewMarketRepository(dbManager.Influx)
+	portfolioRepo := repository.NewPortfolioRepository(dbManager.Postgres.DB)
+	tagRepo := repository.NewTagRepository(dbManager.Postgres.DB)
+	annotationRepo := repository.NewAnnotationRepository(dbManager.Postgres.DB)
 
 	return &UserService{
-		cfg:       cfg,
-		dbManager: dbManager,
-		userRepo:  userRepo,
-		jwtSecret: jwtSecret,
+		cfg:            cfg,
+		dbManager:      dbManager,
+		live:           live,
+		userRepo:       userRepo,
+		stockRepo:      stockRepo,
+		marketRepo:     marketRepo,
+		portfolioRepo:  portfolioRepo,
+		tagRepo:        tagRepo,
+		annotationRepo: annotationRepo,
+		basketRepo:     repository.NewBasketRepository(dbManager.Postgres.DB),
+		analytics:      newAnalyticsCache(analyticsCacheTTL),
+		keys:           keys,
+		quotas:         quota.NewChecker(repository.NewQuotaRepository(dbManager.Postgres.DB)),
+		usageRepo:      repository.NewUsageRepository(dbManager.Postgres.DB),
+		taskRepo:       repository.NewTaskRepository(dbManager.Postgres.DB),
 	}, nil
 }
 
 // Close 关闭服务
 func (s *UserService) Close() {
+	s.live.Close()
 	if s.dbManager != nil {
 		s.dbManager.Close()
 	}
@@ -57,75 +107,9 @@ func (s *UserService) Close() {
 
 // ============ JWT 相关 ============
 
-// Claims JWT声明
-type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
-	jwt.RegisteredClaims
-}
-
 // GenerateToken 生成JWT Token
 func (s *UserService) GenerateToken(user *models.User) (string, error) {
-	claims := Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "stock-analysis-system",
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(s.jwtSecret)
-}
-
-// ParseToken 解析JWT Token
-func (s *UserService) ParseToken(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return s.jwtSecret, nil
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
-	if claims, ok := token.Claims.(*Claims); ok && token.Valid {
-		return claims, nil
-	}
-
-	return nil, errors.New("invalid token")
-}
-
-// AuthMiddleware JWT认证中间件
-func (s *UserService) AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "缺少认证信息"})
-			c.Abort()
-			return
-		}
-
-		// Bearer Token
-		tokenString := ""
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			tokenString = authHeader[7:]
-		} else {
-			tokenString = authHeader
-		}
-
-		claims, err := s.ParseToken(tokenString)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "msg": "无效的认证信息"})
-			c.Abort()
-			return
-		}
-
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Next()
-	}
+	return s.keys.Issue(user.ID, user.Username)
 }
 
 // ============ 认证接口 ============
@@ -243,9 +227,7 @@ func (s *UserService) Login(c *gin.Context) {
 	}
 
 	// 更新最后登录时间
-	now := time.Now()
-	user.LastLoginAt = &now
-	s.userRepo.Update(ctx, user)
+	s.userRepo.UpdateLastLogin(ctx, user.ID, time.Now())
 
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
@@ -275,6 +257,11 @@ func (s *UserService) GetUserProfile(c *gin.Context) {
 		return
 	}
 
+	lastLogin := ""
+	if user.LastLoginAt != nil {
+		lastLogin = user.LastLoginAt.Format("2006-01-02 15:04:05")
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": gin.H{
@@ -285,18 +272,34 @@ func (s *UserService) GetUserProfile(c *gin.Context) {
 			"phone":       user.Phone,
 			"status":      user.Status,
 			"created_at":  user.CreatedAt.Format("2006-01-02 15:04:05"),
-			"last_login":  func() string { if user.LastLoginAt != nil { return user.LastLoginAt.Format("2006-01-02 15:04:05") } return "" }(),
+			"last_login":  lastLogin,
+			"version":     user.Version,
 		},
 	})
 }
 
+// GetUserUsage 当前套餐、配额与各资源用量
+func (s *UserService) GetUserUsage(c *gin.Context) {
+	uid := c.GetUint("user_id")
+
+	usage, err := s.quotas.Usage(c.Request.Context(), uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询套餐用量失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": usage})
+}
+
 // UpdateUserProfileRequest 更新用户信息请求
 type UpdateUserProfileRequest struct {
 	AvatarURL string `json:"avatar_url"`
 	Phone     string `json:"phone"`
+	Version   *int   `json:"version,omitempty"` // 读取资料时的版本号，与当前版本不一致时返回 409
 }
 
 // UpdateUserProfile 更新用户信息
+// 以乐观锁保存：请求中的 version 已过期，或读取后被其他请求修改时返回 409，data.current_version 为当前版本号。
 func (s *UserService) UpdateUserProfile(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	uid := userID.(uint)
@@ -308,16 +311,29 @@ func (s *UserService) UpdateUserProfile(c *gin.Context) {
 	}
 
 	ctx := c.Request.Context()
-	user, err := s.userRepo.GetByID(ctx, uid)
+	// 版本号比较决定是否写入，读主库：副本延迟时会把刚保存过的新版本误判为冲突
+	user, err := s.userRepo.GetByID(database.WithPrimary(ctx), uid)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "用户不存在"})
 		return
 	}
 
+	if req.Version != nil && *req.Version != user.Version {
+		middleware.AbortVersionConflict(c, user.Version)
+		return
+	}
+
 	user.AvatarURL = req.AvatarURL
 	user.Phone = req.Phone
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			// 冲突的修改刚写入主库，读副本可能取到旧版本号
+			if current, err := s.userRepo.GetByID(database.WithPrimary(ctx), uid); err == nil {
+				middleware.AbortVersionConflict(c, current.Version)
+				return
+			}
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
 		return
 	}
@@ -325,23 +341,44 @@ func (s *UserService) UpdateUserProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"msg":  "更新成功",
+		"data": gin.H{"version": user.Version},
 	})
 }
 
 // ============ 自选股接口 ============
 
 // GetWatchlists 获取自选股列表
+// tags 参数（逗号分隔）用于筛选带有全部指定标签的分组。
+// 携带 updated_since 参数时为增量同步，data 为 {list, next_since}，只包含之后有变化的分组。
 func (s *UserService) GetWatchlists(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	uid := userID.(uint)
 
+	now := time.Now()
+	updatedSince, err := validation.ParseUpdatedSince(c.Query("updated_since"), now)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
 	ctx := c.Request.Context()
-	watchlists, err := s.userRepo.GetWatchlists(ctx, uid)
+	watchlists, err := s.userRepo.GetWatchlists(ctx, uid, validation.ParseTagQuery(c.Query("tags")), updatedSince)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
 		return
 	}
 
+	if updatedSince != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"code": 0,
+			"data": gin.H{
+				"list":       watchlists,
+				"next_since": validation.NextSince(now),
+			},
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"code": 0,
 		"data": watchlists,
@@ -476,26 +513,17 @@ func main() {
 	if err != nil {
 		panic(err)
 	}
-	defer service.Close()
 
-	if cfg.Server.Mode == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
-
-	r := gin.New()
-	r.Use(gin.Recovery())
-	r.Use(corsMiddleware())
+	port := getEnv("USER_SERVICE_PORT", "8083")
 
-	// 健康检查
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"status":  "healthy",
-			"service": "user-service",
-		})
-	})
+	srv := server.New("user-service", cfg,
+		server.WithPort(port),
+		server.WithDatabaseHealth(service.dbManager),
+		server.WithShutdownHook(func(context.Context) { service.Close() }),
+	)
 
 	// API路由
-	api := r.Group("/api/v1")
+	api := srv.Router().Group("/api/v1", middleware.Timeout(10*time.Second))
 	{
 		// 认证接口（公开）
 		auth := api.Group("/auth")
@@ -506,45 +534,88 @@ func main() {
 
 		// 用户接口（需要认证）
 		user := api.Group("/user")
-		user.Use(service.AuthMiddleware())
+		user.Use(middleware.JWTAuth(service.keys))
 		{
 			user.GET("/profile", service.GetUserProfile)
 			user.PUT("/profile", service.UpdateUserProfile)
+			user.GET("/usage", service.GetUserUsage)
+			user.GET("/usage/daily", service.GetUsageDaily)
 		}
 
 		// 自选股接口（需要认证）
 		watchlist := api.Group("/watchlist")
-		watchlist.Use(service.AuthMiddleware())
+		watchlist.Use(middleware.JWTAuth(service.keys))
 		{
 			watchlist.GET("", service.GetWatchlists)
 			watchlist.POST("", service.CreateWatchlist)
-			watchlist.POST("/:id/items", service.AddToWatchlist)
+			watchlist.POST("/:id/items", middleware.Quota(service.quotas, quota.WatchlistItems), service.AddToWatchlist)
 			watchlist.DELETE("/:id/items/:symbol", service.RemoveFromWatchlist)
+			watchlist.PUT("/:id/tags", service.SetWatchlistTags)
 		}
-	}
 
-	port := getEnv("USER_SERVICE_PORT", "8083")
+		// 标签接口（需要认证）
+		tags := api.Group("/tags")
+		tags.Use(middleware.JWTAuth(service.keys))
+		{
+			tags.GET("", service.GetTags)
+			tags.POST("", service.CreateTag)
+			tags.PUT("/:id", service.UpdateTag)
+			tags.DELETE("/:id", service.DeleteTag)
+		}
+
+		// 异步任务状态接口（需要认证）
+		tasks := api.Group("/tasks")
+		tasks.Use(middleware.JWTAuth(service.keys))
+		{
+			tasks.GET("", service.GetTasks)
+			tasks.GET("/:id", service.GetTask)
+		}
 
-	// 优雅退出
-	go func() {
-		sigChan := make(chan os.Signal, 1)
-		signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-		<-sigChan
-	}()
+		// 图表标注接口（需要认证）
+		annotations := api.Group("/annotations")
+		annotations.Use(middleware.JWTAuth(service.keys))
+		{
+			annotations.GET("", service.GetAnnotations)
+			annotations.POST("", service.CreateAnnotation)
+			annotations.DELETE("", service.ClearAnnotations)
+			annotations.PUT("/:id", service.UpdateAnnotation)
+			annotations.DELETE("/:id", service.DeleteAnnotation)
+		}
 
-	r.Run(":" + port)
-}
+		// 模拟交易组合接口（需要认证）
+		portfolio := api.Group("/portfolio")
+		portfolio.Use(middleware.JWTAuth(service.keys))
+		{
+			portfolio.GET("", service.GetPortfolios)
+			portfolio.POST("", service.CreatePortfolio)
+			portfolio.GET("/:id", service.GetPortfolio)
+			portfolio.DELETE("/:id", service.DeletePortfolio)
+			portfolio.POST("/:id/trades", service.AddTrade)
+			portfolio.POST("/:id/import", service.ImportTrades)
+
+			// 组合分析
+			portfolio.GET("/:id/analytics", service.GetPortfolioAnalytics)
+			portfolio.GET("/:id/analytics/returns", service.GetPortfolioReturns)
+			portfolio.GET("/:id/analytics/allocation", service.GetPortfolioAllocation)
+			portfolio.GET("/:id/analytics/gains", service.GetPortfolioGains)
+			portfolio.GET("/:id/analytics/exposure", service.GetPortfolioExposure)
+		}
 
-func corsMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
+		// 股票篮子（虚拟指数）接口（需要认证）
+		baskets := api.Group("/baskets")
+		baskets.Use(middleware.JWTAuth(service.keys))
+		{
+			baskets.GET("", service.GetBaskets)
+			baskets.POST("", service.CreateBasket)
+			baskets.GET("/:id", service.GetBasket)
+			baskets.PUT("/:id", service.UpdateBasket)
+			baskets.DELETE("/:id", service.DeleteBasket)
+			baskets.GET("/:id/history", service.GetBasketHistory)
 		}
-		c.Next()
+	}
+
+	if err := srv.Run(); err != nil {
+		log.Fatalf("服务启动失败: %v", err)
 	}
 }
 
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// laggingUserRepo 写入只到主库，副本停留在写入前的状态；只有 WithPrimary 标记的读取能看到新版本
+type laggingUserRepo struct {
+	repository.UserRepository
+	primary *models.User
+	replica models.User
+}
+
+func (r *laggingUserRepo) GetByID(ctx context.Context, _ uint) (*models.User, error) {
+	copied := r.replica
+	if database.IsPrimary(ctx) {
+		copied = *r.primary
+	}
+	return &copied, nil
+}
+
+func (r *laggingUserRepo) Update(_ context.Context, user *models.User) error {
+	if user.Version != r.primary.Version {
+		return repository.ErrVersionConflict
+	}
+	user.Version++
+	copied := *user
+	r.primary = &copied
+	return nil
+}
+
+func putProfile(t *testing.T, s *UserService, req gin.H) (int, map[string]interface{}) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PUT("/user/profile", func(c *gin.Context) { c.Set("user_id", uint(1)) }, s.UpdateUserProfile)
+
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/user/profile", bytes.NewReader(body)))
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("响应 %s: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+// 副本尚未复制上一次保存时，携带最新版本号的连续修改不应返回 409
+func TestUpdateUserProfileReadsVersionFromPrimary(t *testing.T) {
+	stored := models.User{ID: 1, Username: "alice", Version: 1}
+	repo := &laggingUserRepo{primary: &stored, replica: stored}
+	s := &UserService{userRepo: repo}
+
+	for version, phone := range []string{"13800000001", "13800000002"} {
+		code, resp := putProfile(t, s, gin.H{"phone": phone, "version": version + 1})
+		if code != http.StatusOK {
+			t.Fatalf("第 %d 次修改返回 %d: %v", version+1, code, resp)
+		}
+	}
+	if repo.primary.Version != 3 || repo.primary.Phone != "13800000002" || repo.replica.Version != 1 {
+		t.Errorf("主库 %+v，副本版本 %d", repo.primary, repo.replica.Version)
+	}
+
+	// 过期的版本号仍返回 409，current_version 取自主库
+	code, resp := putProfile(t, s, gin.H{"phone": "13800000003", "version": 2})
+	if code != http.StatusConflict {
+		t.Fatalf("过期版本号返回 %d: %v", code, resp)
+	}
+	if data, _ := resp["data"].(map[string]interface{}); data["current_version"] != float64(3) {
+		t.Errorf("current_version = %v，期望 3", data["current_version"])
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/portfolio"
+)
+
+// ============ 模拟交易组合接口 ============
+
+// GetPortfolios 获取组合列表
+func (s *UserService) GetPortfolios(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	ctx := c.Request.Context()
+	portfolios, err := s.portfolioRepo.GetByUserID(ctx, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": portfolios,
+	})
+}
+
+// CreatePortfolioRequest 创建组合请求
+type CreatePortfolioRequest struct {
+	Name        string  `json:"name" binding:"required,max=50"`
+	Description string  `json:"description"`
+	InitialCash float64 `json:"initial_cash" binding:"required,gt=0"`
+	Benchmark   string  `json:"benchmark"` // symbol.exchange，默认沪深300
+}
+
+// CreatePortfolio 创建组合
+func (s *UserService) CreatePortfolio(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req CreatePortfolioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误"})
+		return
+	}
+	if req.Benchmark == "" {
+		req.Benchmark = defaultBenchmark
+	}
+	if _, _, ok := portfolio.SplitKey(req.Benchmark); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "基准格式错误，应为 symbol.exchange"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	p := &models.Portfolio{
+		UserID:      uid,
+		Name:        req.Name,
+		Description: req.Description,
+		InitialCash: req.InitialCash,
+		Benchmark:   req.Benchmark,
+	}
+
+	if err := s.portfolioRepo.Create(ctx, p); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功",
+		"data": p,
+	})
+}
+
+// GetPortfolio 获取组合详情（含成交记录）
+func (s *UserService) GetPortfolio(c *gin.Context) {
+	p, ok := s.ownedPortfolio(c)
+	if !ok {
+		return
+	}
+
+	trades, err := s.portfolioRepo.GetTrades(c.Request.Context(), p.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+	p.Trades = trades
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": p,
+	})
+}
+
+// DeletePortfolio 删除组合
+func (s *UserService) DeletePortfolio(c *gin.Context) {
+	p, ok := s.ownedPortfolio(c)
+	if !ok {
+		return
+	}
+
+	if err := s.portfolioRepo.Delete(c.Request.Context(), p.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// AddTradeRequest 添加模拟成交请求
+type AddTradeRequest struct {
+	Symbol   string  `json:"symbol" binding:"required"`
+	Exchange string  `json:"exchange" binding:"required"`
+	Side     string  `json:"side" binding:"required,oneof=buy sell"`
+	Quantity int64   `json:"quantity" binding:"required,gt=0"`
+	Price    float64 `json:"price" binding:"required,gt=0"`
+	Fee      float64 `json:"fee" binding:"gte=0"`
+	TradedAt string  `json:"traded_at"` // YYYY-MM-DD 或 RFC3339，默认当前时间
+}
+
+// AddTrade 添加模拟成交
+// 按成交时间回放已有记录，校验资金与持仓是否足够。
+func (s *UserService) AddTrade(c *gin.Context) {
+	p, ok := s.ownedPortfolio(c)
+	if !ok {
+		return
+	}
+
+	var req AddTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误: " + err.Error()})
+		return
+	}
+
+	tradedAt := time.Now()
+	if req.TradedAt != "" {
+		var err error
+		if tradedAt, err = parseTradeTime(req.TradedAt); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "成交时间格式错误"})
+			return
+		}
+		if tradedAt.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "成交时间不能晚于当前时间"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	if exists, err := s.stockRepo.SymbolExists(ctx, req.Symbol, req.Exchange); err != nil || !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "股票不存在"})
+		return
+	}
+
+	trade := &models.PortfolioTrade{
+		PortfolioID: p.ID,
+		Symbol:      req.Symbol,
+		Exchange:    req.Exchange,
+		Side:        req.Side,
+		Quantity:    req.Quantity,
+		Price:       req.Price,
+		Fee:         req.Fee,
+		TradedAt:    tradedAt,
+	}
+
+	trades, err := s.portfolioRepo.GetTrades(ctx, p.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+	if err := replayTrades(p.InitialCash, append(trades, trade)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	if err := s.portfolioRepo.AddTrade(ctx, trade); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "添加失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "添加成功",
+		"data": trade,
+	})
+}
+
+// ownedPortfolio 读取路径中的组合并校验归属，失败时已写入响应
+func (s *UserService) ownedPortfolio(c *gin.Context) (*models.Portfolio, bool) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "组合ID错误"})
+		return nil, false
+	}
+
+	p, err := s.portfolioRepo.GetByID(c.Request.Context(), uint(id))
+	if err != nil || p.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问该组合"})
+		return nil, false
+	}
+	return p, true
+}
+
+// replayTrades 按成交时间顺序记账，校验每一笔成交资金与持仓是否足够
+func replayTrades(initialCash float64, trades []*models.PortfolioTrade) error {
+	sorted := make([]*models.PortfolioTrade, len(trades))
+	copy(sorted, trades)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].TradedAt.Before(sorted[j].TradedAt) })
+
+	book := portfolio.NewBook(initialCash)
+	for _, t := range sorted {
+		if err := book.Apply(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTradeTime 解析成交时间，支持日期或 RFC3339
+func parseTradeTime(value string) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
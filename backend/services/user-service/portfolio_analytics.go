@@ -0,0 +1,189 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/portfolio"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// defaultBenchmark 默认业绩基准（沪深300）
+const defaultBenchmark = "000300.SH"
+
+// analyticsCacheTTL 组合分析结果缓存时间
+// 行情按日同步，新增成交会刷新组合更新时间使缓存键失效。
+const analyticsCacheTTL = 5 * time.Minute
+
+// ============ 组合分析缓存 ============
+
+// analyticsCache 组合分析结果缓存
+type analyticsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]analyticsEntry
+}
+
+type analyticsEntry struct {
+	result  *portfolio.Result
+	expires time.Time
+}
+
+// newAnalyticsCache 创建组合分析结果缓存
+func newAnalyticsCache(ttl time.Duration) *analyticsCache {
+	return &analyticsCache{ttl: ttl, entries: make(map[string]analyticsEntry)}
+}
+
+// get 读取未过期的缓存
+func (c *analyticsCache) get(key string) (*portfolio.Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+	return entry.result, true
+}
+
+// put 写入缓存，并顺带清理已过期的条目
+func (c *analyticsCache) put(key string, result *portfolio.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = analyticsEntry{result: result, expires: now.Add(c.ttl)}
+}
+
+// ============ 组合分析接口 ============
+
+// GetPortfolioAnalytics 组合分析汇总（收益、每日盈亏、配置、盈亏构成、基准敞口）
+func (s *UserService) GetPortfolioAnalytics(c *gin.Context) {
+	if result, ok := s.analyze(c); ok {
+		respondCacheable(c, result)
+	}
+}
+
+// GetPortfolioReturns 时间加权收益与每日盈亏序列
+func (s *UserService) GetPortfolioReturns(c *gin.Context) {
+	if result, ok := s.analyze(c); ok {
+		respondCacheable(c, gin.H{
+			"start":                result.Start,
+			"end":                  result.End,
+			"time_weighted_return": result.TimeWeightedReturn,
+			"annualized_return":    result.AnnualizedReturn,
+			"series":               result.Series,
+		})
+	}
+}
+
+// GetPortfolioAllocation 按行业的资产配置
+func (s *UserService) GetPortfolioAllocation(c *gin.Context) {
+	if result, ok := s.analyze(c); ok {
+		respondCacheable(c, result.Allocation)
+	}
+}
+
+// GetPortfolioGains 已实现与未实现盈亏
+func (s *UserService) GetPortfolioGains(c *gin.Context) {
+	if result, ok := s.analyze(c); ok {
+		respondCacheable(c, result.Gains)
+	}
+}
+
+// GetPortfolioExposure 相对基准的敞口
+func (s *UserService) GetPortfolioExposure(c *gin.Context) {
+	if result, ok := s.analyze(c); ok {
+		respondCacheable(c, result.Exposure)
+	}
+}
+
+// analyze 校验参数并返回组合分析结果（优先读取缓存），失败时已写入响应
+func (s *UserService) analyze(c *gin.Context) (*portfolio.Result, bool) {
+	p, ok := s.ownedPortfolio(c)
+	if !ok {
+		return nil, false
+	}
+
+	dateRange, err := validation.ParseDateRange(c.Query("start"), c.Query("end"), validation.RangeRule{
+		DefaultDays: 365,
+		MaxDays:     365 * 10,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return nil, false
+	}
+
+	benchmark := c.DefaultQuery("benchmark", p.Benchmark)
+	if benchmark == "" {
+		benchmark = defaultBenchmark
+	}
+	if _, _, ok := portfolio.SplitKey(benchmark); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "基准格式错误，应为 symbol.exchange"})
+		return nil, false
+	}
+
+	start := dateRange.Start.Format(validation.DateLayout)
+	end := dateRange.End.Format(validation.DateLayout)
+	key := fmt.Sprintf("%d|%s|%s|%s|%d", p.ID, start, end, benchmark, p.UpdatedAt.UnixNano())
+	if result, ok := s.analytics.get(key); ok {
+		return result, true
+	}
+
+	result, err := s.computeAnalytics(c, p, dateRange, benchmark)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "组合分析失败: " + err.Error()})
+		return nil, false
+	}
+	s.analytics.put(key, result)
+	return result, true
+}
+
+// computeAnalytics 加载成交记录、行情与基准数据并计算组合分析结果
+func (s *UserService) computeAnalytics(c *gin.Context, p *models.Portfolio, dateRange validation.DateRange, benchmark string) (*portfolio.Result, error) {
+	ctx := c.Request.Context()
+
+	trades, err := s.portfolioRepo.GetTrades(ctx, p.ID)
+	if err != nil {
+		return nil, fmt.Errorf("查询成交记录失败: %w", err)
+	}
+
+	in, err := portfolio.LoadInput(ctx, s.marketRepo, s.stockRepo, p, trades, dateRange.Start, dateRange.End, benchmark)
+	if err != nil {
+		return nil, err
+	}
+	return portfolio.Analyze(in)
+}
+
+// respondCacheable 返回可缓存的分析结果，支持 ETag 协商缓存
+func respondCacheable(c *gin.Context, data interface{}) {
+	body, err := json.Marshal(gin.H{"code": 0, "data": data})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "序列化失败"})
+		return
+	}
+
+	sum := sha1.Sum(body)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", int(analyticsCacheTTL.Seconds())))
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/portfolio"
+)
+
+// ============ 成交记录导入 ============
+
+// importEntry 回放用的成交记录，row 为 0 表示组合中已有的成交
+type importEntry struct {
+	row   int
+	trade *models.PortfolioTrade
+}
+
+// ImportTrades 通过 CSV 导入历史成交
+// 支持 multipart 表单字段 file 或直接以 text/csv 作为请求体；dry_run=true 时只校验不写入。
+// 任一行存在错误时不导入任何记录，并返回全部行级错误。
+func (s *UserService) ImportTrades(c *gin.Context) {
+	p, ok := s.ownedPortfolio(c)
+	if !ok {
+		return
+	}
+	dryRun := c.Query("dry_run") == "true"
+
+	reader, err := importReader(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+	defer reader.Close()
+
+	rows, rowErrors, err := portfolio.ParseTradesCSV(reader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "解析 CSV 失败: " + err.Error()})
+		return
+	}
+	if len(rows) == 0 && len(rowErrors) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "没有可导入的记录"})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// 校验股票代码
+	known := make(map[string]bool)
+	var entries []importEntry
+	for _, row := range rows {
+		key := portfolio.Key(row.Trade.Symbol, row.Trade.Exchange)
+		exists, checked := known[key]
+		if !checked {
+			var err error
+			if exists, err = s.stockRepo.SymbolExists(ctx, row.Trade.Symbol, row.Trade.Exchange); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "校验股票代码失败"})
+				return
+			}
+			known[key] = exists
+		}
+		if !exists {
+			rowErrors = append(rowErrors, &portfolio.RowError{Row: row.Row, Error: "股票不存在: " + key})
+			continue
+		}
+		entries = append(entries, importEntry{row: row.Row, trade: row.Trade})
+	}
+
+	// 与已有成交合并，按成交时间回放计算持仓与成本
+	existing, err := s.portfolioRepo.GetTrades(ctx, p.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+	book, replayErrors := replayImport(p.InitialCash, existing, entries)
+	rowErrors = append(rowErrors, replayErrors...)
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Row < rowErrors[j].Row })
+
+	result := gin.H{
+		"dry_run":   dryRun,
+		"parsed":    len(rows),
+		"imported":  0,
+		"errors":    rowErrors,
+		"cash":      book.Cash,
+		"positions": book.Positions(),
+	}
+
+	if len(rowErrors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code": 400,
+			"msg":  fmt.Sprintf("导入失败，%d 行存在错误", len(rowErrors)),
+			"data": result,
+		})
+		return
+	}
+
+	if !dryRun {
+		trades := make([]*models.PortfolioTrade, 0, len(entries))
+		for _, e := range entries {
+			trades = append(trades, e.trade)
+		}
+		if err := s.portfolioRepo.AddTrades(ctx, p.ID, trades); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "导入失败"})
+			return
+		}
+		result["imported"] = len(trades)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "导入成功",
+		"data": result,
+	})
+}
+
+// replayImport 将已有成交与导入记录按时间合并回放，返回账本与回放中的行级错误
+// 同一时间的成交，已有记录在前，导入记录按文件顺序在后。
+func replayImport(initialCash float64, existing []*models.PortfolioTrade, entries []importEntry) (*portfolio.Book, []*portfolio.RowError) {
+	merged := make([]importEntry, 0, len(existing)+len(entries))
+	for _, t := range existing {
+		merged = append(merged, importEntry{trade: t})
+	}
+	merged = append(merged, entries...)
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].trade.TradedAt.Before(merged[j].trade.TradedAt) })
+
+	book := portfolio.NewBook(initialCash)
+	var rowErrors []*portfolio.RowError
+	for _, e := range merged {
+		if err := book.Apply(e.trade); err != nil {
+			msg := err.Error()
+			if e.row == 0 {
+				msg = fmt.Sprintf("已有成交 #%d 与导入记录冲突: %v", e.trade.ID, err)
+			}
+			rowErrors = append(rowErrors, &portfolio.RowError{Row: e.row, Error: msg})
+		}
+	}
+	return book, rowErrors
+}
+
+// importReader 读取上传的 CSV：multipart 表单字段 file，或请求体本身
+func importReader(c *gin.Context) (io.ReadCloser, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/form-data") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, fmt.Errorf("缺少上传文件 file")
+		}
+		return header.Open()
+	}
+	return c.Request.Body, nil
+}
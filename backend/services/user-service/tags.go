@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 标签接口 ============
+
+// GetTags 获取标签列表（含策略、自选股分组的使用次数）
+func (s *UserService) GetTags(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	ctx := c.Request.Context()
+	tags, err := s.tagRepo.GetByUserID(ctx, uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": tags,
+	})
+}
+
+// TagRequest 创建/更新标签请求
+type TagRequest struct {
+	Name  string `json:"name" binding:"required,max=50"`
+	Color string `json:"color" binding:"max=20"`
+}
+
+// CreateTag 创建标签
+func (s *UserService) CreateTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "标签名不能为空"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, err := s.tagRepo.GetByName(ctx, uid, name); err == nil {
+		c.JSON(http.StatusConflict, gin.H{"code": 409, "msg": "标签已存在"})
+		return
+	}
+
+	tag := &models.Tag{UserID: uid, Name: name, Color: req.Color}
+	if err := s.tagRepo.Create(ctx, tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "创建失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "创建成功",
+		"data": tag,
+	})
+}
+
+// UpdateTag 重命名标签或修改颜色
+func (s *UserService) UpdateTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "标签ID错误"})
+		return
+	}
+
+	var req TagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误"})
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "标签名不能为空"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tag, err := s.tagRepo.GetByID(ctx, uint(tagID))
+	if err != nil || tag.UserID != uid {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "标签不存在"})
+		return
+	}
+	if existing, err := s.tagRepo.GetByName(ctx, uid, name); err == nil && existing.ID != tag.ID {
+		c.JSON(http.StatusConflict, gin.H{"code": 409, "msg": "标签已存在"})
+		return
+	}
+
+	tag.Name = name
+	tag.Color = req.Color
+	if err := s.tagRepo.Update(ctx, tag); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "更新失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": tag,
+	})
+}
+
+// DeleteTag 删除标签，同时移除其在策略与自选股分组上的关联
+func (s *UserService) DeleteTag(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "标签ID错误"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	tag, err := s.tagRepo.GetByID(ctx, uint(tagID))
+	if err != nil || tag.UserID != uid {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "标签不存在"})
+		return
+	}
+
+	if err := s.tagRepo.Delete(ctx, tag.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "删除失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "删除成功",
+	})
+}
+
+// SetTagsRequest 设置标签请求，按名称指定，不存在的标签自动创建
+type SetTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// SetWatchlistTags 替换自选股分组的标签
+func (s *UserService) SetWatchlistTags(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	uid := userID.(uint)
+
+	watchlistID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "分组ID错误"})
+		return
+	}
+
+	var req SetTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "参数错误"})
+		return
+	}
+	names, err := validation.NormalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// 验证分组属于当前用户
+	watchlist, err := s.userRepo.GetWatchlistByID(ctx, uint(watchlistID))
+	if err != nil || watchlist.UserID != uid {
+		c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问该分组"})
+		return
+	}
+
+	tags, err := s.tagRepo.EnsureByNames(ctx, uid, names)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "保存标签失败"})
+		return
+	}
+	if err := s.tagRepo.SetWatchlistTags(ctx, watchlist.ID, tags); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "保存标签失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"msg":  "更新成功",
+		"data": tags,
+	})
+}
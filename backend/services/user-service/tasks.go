@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/models"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+// ============ 异步任务 ============
+
+// taskTypes 可按 type 过滤的任务类型
+var taskTypes = map[string]bool{
+	models.TaskTypeSync:     true,
+	models.TaskTypeBacktest: true,
+	models.TaskTypeAdmin:    true,
+}
+
+// GetTask 异步任务状态（回测、数据同步、管理员运维任务等），只能查看自己发起的任务，管理员可查看全部
+func (s *UserService) GetTask(c *gin.Context) {
+	// 客户端在任务创建后立即轮询，读主库
+	task, err := s.taskRepo.GetByID(database.WithPrimary(c.Request.Context()), c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "任务不存在"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询任务失败"})
+		return
+	}
+	if task.OwnerID != c.GetUint("user_id") && !s.isAdmin(c) {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "msg": "任务不存在"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"code": 0, "data": task})
+}
+
+// GetTasks 当前用户的异步任务列表，可按 type、status 过滤；管理员传 owner=all 时查看全部任务（含系统任务）
+func (s *UserService) GetTasks(c *gin.Context) {
+	filter := repository.TaskFilter{Type: c.Query("type"), Status: c.Query("status")}
+	if filter.Type != "" && !taskTypes[filter.Type] {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "type 仅支持 sync、backtest、admin"})
+		return
+	}
+	switch filter.Status {
+	case "", models.TaskStatusRunning, models.TaskStatusSucceeded, models.TaskStatusFailed, models.TaskStatusCanceled:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "status 仅支持 running、succeeded、failed、canceled"})
+		return
+	}
+	switch owner := c.Query("owner"); owner {
+	case "":
+		uid := c.GetUint("user_id")
+		filter.OwnerID = &uid
+	case "all":
+		if !s.isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "msg": "无权访问"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "owner 仅支持 all"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	tasks, total, err := s.taskRepo.List(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询任务失败"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{"list": tasks, "total": total, "page": page, "page_size": pageSize},
+	})
+}
+
+// isAdmin 当前用户是否为管理员，角色每次从数据库读取
+func (s *UserService) isAdmin(c *gin.Context) bool {
+	role, err := s.userRepo.GetRole(c.Request.Context(), c.GetUint("user_id"))
+	return err == nil && role == models.RoleAdmin
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"stock-analysis-system/backend/pkg/metering"
+	"stock-analysis-system/backend/pkg/validation"
+)
+
+// ============ 用量报表 ============
+
+// usageTotals 区间内的用量合计
+type usageTotals struct {
+	APICalls        int64   `json:"api_calls"`
+	BytesDownloaded int64   `json:"bytes_downloaded"`
+	BacktestSeconds float64 `json:"backtest_seconds"`
+}
+
+// GetUsageDaily 当前用户的每日用量（API 调用次数、下载数据量、回测计算时长）
+// 默认最近 30 天，最长一年；format=csv 时以 CSV 文件下载。
+func (s *UserService) GetUsageDaily(c *gin.Context) {
+	uid := c.GetUint("user_id")
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": "format 仅支持 json、csv"})
+		return
+	}
+	dateRange, err := validation.ParseDateRange(c.Query("start"), c.Query("end"), validation.RangeRule{
+		DefaultDays: 30,
+		MaxDays:     366,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "msg": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	usage, err := s.usageRepo.ListDaily(ctx, uid, dateRange.Start, dateRange.End)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "查询用量失败"})
+		return
+	}
+
+	start := dateRange.Start.Format(validation.DateLayout)
+	end := dateRange.End.Format(validation.DateLayout)
+	if format == "csv" {
+		var buf bytes.Buffer
+		if err := metering.WriteCSV(&buf, usage, map[uint]string{uid: c.GetString("username")}); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "msg": "生成 CSV 失败"})
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="usage-%s-%s.csv"`, start, end))
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+		return
+	}
+
+	var totals usageTotals
+	for _, u := range usage {
+		totals.APICalls += u.APICalls
+		totals.BytesDownloaded += u.BytesDownloaded
+		totals.BacktestSeconds += u.BacktestSeconds
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"code": 0,
+		"data": gin.H{
+			"start":  start,
+			"end":    end,
+			"daily":  usage,
+			"totals": totals,
+		},
+	})
+}
@@ -0,0 +1,122 @@
+// influx-migrate 将默认 Bucket 中的历史数据按数据类型迁移到独立的 Bucket。
+//
+// 目标 Bucket 读取自与服务相同的环境变量（INFLUXDB_BUCKET_DAILY_BARS 等），不存在时按保留策略创建。
+// 按时间分段执行 Flux to()，每段完成后输出写入条数；可重复执行，重复写入的数据点会覆盖旧值。
+//
+// 用法：
+//
+//	go run ./tools/influx-migrate -types daily_bars,minute_bars -start 2015-01-01
+//	go run ./tools/influx-migrate -types minute_bars -delete-source   # 迁移完成后删除默认 Bucket 中的数据
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/database"
+)
+
+func main() {
+	types := flag.String("types", strings.Join(database.DataTypes, ","), "要迁移的数据类型，逗号分隔")
+	start := flag.String("start", "1990-01-01", "迁移起始日期")
+	end := flag.String("end", "", "迁移结束日期，默认今天")
+	chunkDays := flag.Int("chunk-days", 90, "每次迁移的时间跨度（天）")
+	dryRun := flag.Bool("dry-run", false, "只打印迁移计划，不写入数据")
+	deleteSource := flag.Bool("delete-source", false, "迁移完成后删除默认 Bucket 中对应的数据")
+	flag.Parse()
+
+	from, err := time.Parse("2006-01-02", *start)
+	if err != nil {
+		log.Fatalf("起始日期格式错误: %v", err)
+	}
+	to := time.Now()
+	if *end != "" {
+		if to, err = time.Parse("2006-01-02", *end); err != nil {
+			log.Fatalf("结束日期格式错误: %v", err)
+		}
+		to = to.AddDate(0, 0, 1)
+	}
+	if *chunkDays < 1 {
+		log.Fatalf("chunk-days 必须大于 0")
+	}
+
+	cfg := config.LoadFromEnv()
+	client, err := database.NewInfluxClient(&cfg.Database.InfluxDB)
+	if err != nil {
+		log.Fatalf("连接 InfluxDB 失败: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	source := client.GetBucket()
+	for _, dataType := range strings.Split(*types, ",") {
+		dataType = strings.TrimSpace(dataType)
+		target := client.Bucket(dataType)
+		if target == source {
+			log.Printf("%s 未配置独立 Bucket，跳过", dataType)
+			continue
+		}
+
+		log.Printf("迁移 %s: %s -> %s（%s ~ %s）", dataType, source, target, from.Format("2006-01-02"), to.Format("2006-01-02"))
+		if *dryRun {
+			continue
+		}
+		var total int64
+		for chunkStart := from; chunkStart.Before(to); chunkStart = chunkStart.AddDate(0, 0, *chunkDays) {
+			chunkEnd := chunkStart.AddDate(0, 0, *chunkDays)
+			if chunkEnd.After(to) {
+				chunkEnd = to
+			}
+			n, err := copyChunk(ctx, client, dataType, source, target, chunkStart, chunkEnd)
+			if err != nil {
+				log.Fatalf("迁移 %s %s ~ %s 失败: %v", dataType, chunkStart.Format("2006-01-02"), chunkEnd.Format("2006-01-02"), err)
+			}
+			total += n
+			if n > 0 {
+				log.Printf("  %s ~ %s: %d 条", chunkStart.Format("2006-01-02"), chunkEnd.Format("2006-01-02"), n)
+			}
+		}
+		log.Printf("%s 迁移完成，共 %d 条", dataType, total)
+
+		if *deleteSource {
+			predicate := fmt.Sprintf(`_measurement="%s"`, dataType)
+			if err := client.DeleteFrom(ctx, "", from, to, predicate); err != nil {
+				log.Fatalf("删除默认 Bucket 中的 %s 失败: %v", dataType, err)
+			}
+			log.Printf("已删除默认 Bucket 中的 %s", dataType)
+		}
+	}
+}
+
+// copyChunk 复制一个时间段的数据，返回写入的数据点数
+// 数据类型与 measurement 同名。
+func copyChunk(ctx context.Context, client *database.InfluxClient, measurement, source, target string, start, end time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		from(bucket: "%s")
+		|> range(start: %s, stop: %s)
+		|> filter(fn: (r) => r._measurement == "%s")
+		|> to(bucket: "%s", org: "%s")
+		|> count()
+		|> group()
+		|> sum()
+	`, source, start.Format(time.RFC3339), end.Format(time.RFC3339), measurement, target, client.GetOrg())
+
+	result, err := client.Query(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer result.Close()
+
+	var total int64
+	for result.Next() {
+		if v, ok := result.Record().Value().(int64); ok {
+			total += v
+		}
+	}
+	return total, result.Err()
+}
@@ -0,0 +1,121 @@
+// openapi-merge 将 api/openapi 下各服务的 OpenAPI 片段合并为一份完整规范（JSON）。
+//
+// 用法（由 gateway 的 go generate 调用）：
+//
+//	go run ./tools/openapi-merge -in api/openapi -out gateway/docs/openapi.json
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fragment 单个服务的规范片段
+type fragment struct {
+	Tags       []map[string]interface{}          `yaml:"tags"`
+	Paths      map[string]interface{}            `yaml:"paths"`
+	Components map[string]map[string]interface{} `yaml:"components"`
+}
+
+func main() {
+	in := flag.String("in", "api/openapi", "规范片段目录")
+	out := flag.String("out", "gateway/docs/openapi.json", "输出文件")
+	title := flag.String("title", "股票分析系统 API", "文档标题")
+	version := flag.String("version", "1.0.0", "API 版本")
+	flag.Parse()
+
+	spec, err := Merge(*in, *title, *version)
+	if err != nil {
+		log.Fatalf("合并 OpenAPI 规范失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(*out), 0o755); err != nil {
+		log.Fatalf("创建输出目录失败: %v", err)
+	}
+	if err := os.WriteFile(*out, spec, 0o644); err != nil {
+		log.Fatalf("写入 %s 失败: %v", *out, err)
+	}
+	log.Printf("已生成 %s", *out)
+}
+
+// Merge 读取目录下所有 *.yaml 片段并合并，返回格式化后的 JSON
+// 同一路径或同名组件在多个片段中定义且内容不同时返回错误。
+func Merge(dir, title, version string) ([]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%s 下没有规范文件", dir)
+	}
+	sort.Strings(files)
+
+	var tags []map[string]interface{}
+	paths := make(map[string]interface{})
+	components := make(map[string]map[string]interface{})
+	pathSource := make(map[string]string)
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		var frag fragment
+		if err := yaml.Unmarshal(data, &frag); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", file, err)
+		}
+
+		tags = append(tags, frag.Tags...)
+
+		for path, item := range frag.Paths {
+			if src, exists := pathSource[path]; exists {
+				return nil, fmt.Errorf("路径 %s 同时定义于 %s 和 %s", path, src, file)
+			}
+			paths[path] = item
+			pathSource[path] = file
+		}
+
+		for section, items := range frag.Components {
+			if components[section] == nil {
+				components[section] = make(map[string]interface{})
+			}
+			for name, item := range items {
+				if existing, exists := components[section][name]; exists && !reflect.DeepEqual(existing, item) {
+					return nil, fmt.Errorf("组件 %s/%s 在 %s 中重复定义且内容不一致", section, name, file)
+				}
+				components[section][name] = item
+			}
+		}
+	}
+
+	spec := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"servers":    []map[string]string{{"url": "/"}},
+		"tags":       tags,
+		"paths":      paths,
+		"components": components,
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(spec); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestGeneratedSpecUpToDate 确保提交的 gateway/docs/openapi.json 与规范片段一致
+func TestGeneratedSpecUpToDate(t *testing.T) {
+	spec, err := Merge("../../api/openapi", "股票分析系统 API", "1.0.0")
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+
+	committed, err := os.ReadFile("../../gateway/docs/openapi.json")
+	if err != nil {
+		t.Fatalf("读取已生成的规范失败: %v", err)
+	}
+
+	if !bytes.Equal(spec, committed) {
+		t.Fatal("gateway/docs/openapi.json 已过期，请执行 go generate ./gateway")
+	}
+}
+
+// TestRefsResolve 确保所有 $ref 都指向已定义的组件
+func TestRefsResolve(t *testing.T) {
+	spec, err := Merge("../../api/openapi", "test", "0")
+	if err != nil {
+		t.Fatalf("合并失败: %v", err)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	components := doc["components"].(map[string]interface{})
+
+	refs := regexp.MustCompile(`"\$ref": "#/components/([^/"]+)/([^"]+)"`).FindAllStringSubmatch(string(spec), -1)
+	if len(refs) == 0 {
+		t.Fatal("未找到任何 $ref")
+	}
+	for _, ref := range refs {
+		section, _ := components[ref[1]].(map[string]interface{})
+		if _, ok := section[ref[2]]; !ok {
+			t.Errorf("未定义的引用: %s", strings.TrimPrefix(ref[0], `"$ref": `))
+		}
+	}
+}
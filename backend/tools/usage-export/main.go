@@ -0,0 +1,88 @@
+// usage-export 导出全部用户的每日用量（API 调用次数、下载数据量、回测计算时长）CSV，用于计费与分析。
+//
+// 用量由网关与回测服务按用户、自然日累加写入 usage_daily 表，数据库连接读取自与服务相同的环境变量。
+//
+// 用法：
+//
+//	go run ./tools/usage-export -start 2024-06-01 -end 2024-06-30 > usage-2024-06.csv
+//	go run ./tools/usage-export -start 2024-06-01 -o usage.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"stock-analysis-system/backend/pkg/config"
+	"stock-analysis-system/backend/pkg/database"
+	"stock-analysis-system/backend/pkg/metering"
+	"stock-analysis-system/backend/pkg/repository"
+)
+
+func main() {
+	start := flag.String("start", "", "起始日期（含），默认本月 1 日")
+	end := flag.String("end", "", "结束日期（含），默认今天")
+	output := flag.String("o", "", "输出文件，默认标准输出")
+	flag.Parse()
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	to := now
+	var err error
+	if *start != "" {
+		if from, err = time.ParseInLocation("2006-01-02", *start, time.Local); err != nil {
+			log.Fatalf("起始日期格式错误: %v", err)
+		}
+	}
+	if *end != "" {
+		if to, err = time.ParseInLocation("2006-01-02", *end, time.Local); err != nil {
+			log.Fatalf("结束日期格式错误: %v", err)
+		}
+	}
+	if to.Before(from) {
+		log.Fatalf("结束日期不能早于起始日期")
+	}
+
+	cfg := config.LoadFromEnv()
+	pg, err := database.NewPostgresClient(&cfg.Database.Postgres)
+	if err != nil {
+		log.Fatalf("连接 PostgreSQL 失败: %v", err)
+	}
+	defer pg.Close()
+
+	ctx := context.Background()
+	repo := repository.NewUsageRepository(pg.DB)
+	usage, err := repo.ListAllDaily(ctx, from, to)
+	if err != nil {
+		log.Fatalf("查询用量失败: %v", err)
+	}
+	ids := make([]uint, 0, len(usage))
+	seen := make(map[uint]bool)
+	for _, u := range usage {
+		if !seen[u.UserID] {
+			seen[u.UserID] = true
+			ids = append(ids, u.UserID)
+		}
+	}
+	usernames, err := repo.Usernames(ctx, ids)
+	if err != nil {
+		log.Fatalf("查询用户名失败: %v", err)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("创建输出文件失败: %v", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := metering.WriteCSV(w, usage, usernames); err != nil {
+		log.Fatalf("写入 CSV 失败: %v", err)
+	}
+	log.Printf("已导出 %d 个用户 %s ~ %s 的 %d 条用量记录", len(ids), from.Format("2006-01-02"), to.Format("2006-01-02"), len(usage))
+}
@@ -15,9 +15,9 @@ CREATE TABLE IF NOT EXISTS stocks (
     exchange VARCHAR(10) NOT NULL,            -- 交易所 (SH/SZ)
     industry VARCHAR(50),                     -- 所属行业
     full_name VARCHAR(200),                   -- 公司全称
-    list_date DATE,                           -- 上市日期
-    total_share BIGINT,                       -- 总股本
-    float_share BIGINT,                       -- 流通股本
+    list_date DATE,                           -- 上市日期，由股票资料同步（stock_metadata）每晚更新
+    total_share BIGINT,                       -- 总股本（股），与最新收盘价计算总市值
+    float_share BIGINT,                       -- 流通股本（股），与最新收盘价计算流通市值
     status VARCHAR(10) DEFAULT 'active',      -- 状态
     created_at TIMESTAMP DEFAULT NOW(),
     updated_at TIMESTAMP DEFAULT NOW(),
@@ -76,7 +76,7 @@ CREATE INDEX idx_strategies_type ON strategies(type);
 CREATE INDEX idx_strategies_is_active ON strategies(is_active);
 
 COMMENT ON TABLE strategies IS '策略配置表';
-COMMENT ON COLUMN strategies.type IS '策略类型：trend_following/mean_reversion/multi_factor';
+COMMENT ON COLUMN strategies.type IS '策略类型：trend_following/mean_reversion/multi_factor/pair_trading';
 
 -- ============================================
 -- 4. 交易信号表
@@ -303,6 +303,698 @@ LEFT JOIN trade_signals sig ON s.id = sig.strategy_id
 LEFT JOIN backtest_records br ON s.id = br.strategy_id
 GROUP BY s.id, s.name, s.type;
 
+-- ============================================
+-- 11. 龙虎榜表
+-- ============================================
+CREATE TABLE IF NOT EXISTS dragon_tiger_lists (
+    id SERIAL PRIMARY KEY,
+    symbol VARCHAR(10) NOT NULL,              -- 股票代码
+    exchange VARCHAR(10) NOT NULL,            -- 交易所
+    name VARCHAR(100),                        -- 股票名称
+    trade_date DATE NOT NULL,                 -- 上榜日期
+    reason VARCHAR(200) NOT NULL,             -- 上榜原因
+    close DECIMAL(12, 4),                     -- 收盘价
+    change_pct DECIMAL(8, 4),                 -- 涨跌幅(%)
+    buy_amount DECIMAL(18, 2),                -- 龙虎榜买入额
+    sell_amount DECIMAL(18, 2),               -- 龙虎榜卖出额
+    net_amount DECIMAL(18, 2),                -- 龙虎榜净买额
+    total_amount DECIMAL(18, 2),              -- 当日总成交额
+    seats JSONB DEFAULT '[]',                 -- 买卖席位明细
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(symbol, exchange, trade_date, reason)
+);
+
+CREATE INDEX idx_dragon_tiger_symbol ON dragon_tiger_lists(symbol);
+CREATE INDEX idx_dragon_tiger_trade_date ON dragon_tiger_lists(trade_date);
+
+COMMENT ON TABLE dragon_tiger_lists IS '龙虎榜数据表';
+
+-- ============================================
+-- 12. 新闻公告表
+-- ============================================
+CREATE TABLE IF NOT EXISTS news_articles (
+    id SERIAL PRIMARY KEY,
+    title VARCHAR(500) NOT NULL,              -- 标题
+    summary TEXT,                             -- 摘要
+    content TEXT,                             -- 正文
+    source VARCHAR(100),                      -- 来源
+    category VARCHAR(20) DEFAULT 'news',      -- 类别：news/announcement
+    url VARCHAR(1000) NOT NULL UNIQUE,        -- 原文链接
+    published_at TIMESTAMP NOT NULL,          -- 发布时间
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        to_tsvector('simple', coalesce(title, '') || ' ' || coalesce(summary, '') || ' ' || coalesce(content, ''))
+    ) STORED,                                 -- 全文检索向量
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_news_published_at ON news_articles(published_at);
+CREATE INDEX idx_news_category ON news_articles(category);
+CREATE INDEX idx_news_search ON news_articles USING GIN(search_vector);
+
+CREATE TABLE IF NOT EXISTS news_symbols (
+    id SERIAL PRIMARY KEY,
+    news_id INTEGER REFERENCES news_articles(id) ON DELETE CASCADE,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    UNIQUE(news_id, symbol, exchange)
+);
+
+CREATE INDEX idx_news_symbols_symbol ON news_symbols(symbol, exchange);
+
+COMMENT ON TABLE news_articles IS '新闻公告表';
+COMMENT ON TABLE news_symbols IS '新闻股票标签表';
+COMMENT ON COLUMN news_articles.search_vector IS '全文检索向量，中文分词可替换为 zhparser 配置';
+
+-- ============================================
+-- 13. 数据同步任务表
+-- ============================================
+CREATE TABLE IF NOT EXISTS data_sync_jobs (
+    id SERIAL PRIMARY KEY,
+    job_type VARCHAR(30) NOT NULL,            -- 任务类型：stock_list/daily_bars/indicators/minute_bars/money_flow/dragon_tiger/news/incremental/restatement 等
+    source VARCHAR(50) NOT NULL,              -- 数据来源，如 akshare
+    symbol VARCHAR(10) DEFAULT '',            -- 股票代码，为空表示全市场任务
+    exchange VARCHAR(10) DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'running', -- queued/running/success/partial/failed，queued 为排队中的下游任务，partial 表示部分股票失败
+    records INTEGER DEFAULT 0,                -- 写入记录数
+    error TEXT,                               -- 失败原因
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_sync_jobs_lookup ON data_sync_jobs(job_type, symbol, exchange);
+CREATE INDEX idx_sync_jobs_finished_at ON data_sync_jobs(finished_at DESC);
+
+COMMENT ON TABLE data_sync_jobs IS '数据同步任务记录表，行情接口据此返回数据来源与新鲜度';
+
+-- ============================================
+-- 14. 模拟交易组合表
+-- ============================================
+CREATE TABLE IF NOT EXISTS portfolios (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,                -- 组合名称
+    description TEXT,
+    initial_cash DECIMAL(15, 2) NOT NULL,     -- 初始资金
+    benchmark VARCHAR(20) DEFAULT '000300.SH', -- 业绩基准 symbol.exchange
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_portfolios_user_id ON portfolios(user_id);
+
+CREATE TABLE IF NOT EXISTS portfolio_trades (
+    id SERIAL PRIMARY KEY,
+    portfolio_id INTEGER REFERENCES portfolios(id) ON DELETE CASCADE,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    side VARCHAR(10) NOT NULL,                -- buy/sell
+    quantity BIGINT NOT NULL,
+    price DECIMAL(12, 4) NOT NULL,
+    fee DECIMAL(12, 2) DEFAULT 0,
+    traded_at TIMESTAMP NOT NULL,             -- 成交时间
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_portfolio_trades_portfolio ON portfolio_trades(portfolio_id, traded_at);
+
+COMMENT ON TABLE portfolios IS '模拟交易组合表';
+COMMENT ON TABLE portfolio_trades IS '模拟交易成交记录表';
+
+-- ============================================
+-- 15. 因子得分表
+-- ============================================
+CREATE TABLE IF NOT EXISTS factor_scores (
+    id BIGSERIAL PRIMARY KEY,
+    trade_date DATE NOT NULL,                 -- 计算日
+    factor VARCHAR(20) NOT NULL,              -- momentum/value/volatility/size
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,          -- 因子原始值
+    zscore DOUBLE PRECISION NOT NULL,         -- 截面标准化得分（按因子方向调整，越大越好）
+    rank INTEGER NOT NULL,                    -- 截面排名，1 为最优
+    percentile DOUBLE PRECISION NOT NULL,     -- 截面分位 0~1
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(trade_date, factor, symbol, exchange)
+);
+
+CREATE INDEX idx_factor_scores_ranking ON factor_scores(trade_date, factor, rank);
+CREATE INDEX idx_factor_scores_symbol ON factor_scores(symbol, exchange, trade_date);
+
+COMMENT ON TABLE factor_scores IS '多因子截面得分表';
+
+-- ============================================
+-- 16. 标签表
+-- ============================================
+CREATE TABLE IF NOT EXISTS tags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,                -- 标签名，如 momentum/live/experimental
+    color VARCHAR(20),                        -- 前端展示颜色
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS strategy_tags (
+    strategy_id INTEGER REFERENCES strategies(id) ON DELETE CASCADE,
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (strategy_id, tag_id)
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_tags (
+    watchlist_id INTEGER REFERENCES watchlists(id) ON DELETE CASCADE,
+    tag_id INTEGER REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (watchlist_id, tag_id)
+);
+
+CREATE INDEX idx_strategy_tags_tag ON strategy_tags(tag_id);
+CREATE INDEX idx_watchlist_tags_tag ON watchlist_tags(tag_id);
+
+COMMENT ON TABLE tags IS '用户标签表，用于组织策略与自选股分组';
+COMMENT ON TABLE strategy_tags IS '策略标签关联表';
+COMMENT ON TABLE watchlist_tags IS '自选股分组标签关联表';
+
+-- ============================================
+-- 17. 股票池表
+-- ============================================
+CREATE TABLE IF NOT EXISTS universes (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    type VARCHAR(20) NOT NULL,                -- index/screener/manual
+    source VARCHAR(20),                       -- 指数代码 symbol.exchange（index 类型）
+    criteria JSONB,                           -- 选股条件（screener 类型）
+    max_members INTEGER DEFAULT 0,            -- 选股结果取前 N 只，0 表示不限制
+    symbols TEXT[],                           -- 股票列表（manual 类型）
+    is_active BOOLEAN DEFAULT TRUE,           -- 是否参与每日快照
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS universe_members (
+    id BIGSERIAL PRIMARY KEY,
+    universe_id INTEGER NOT NULL REFERENCES universes(id) ON DELETE CASCADE,
+    trade_date DATE NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    weight DECIMAL(10, 6) DEFAULT 0,          -- 指数权重
+    UNIQUE(universe_id, trade_date, symbol, exchange)
+);
+
+CREATE INDEX idx_universes_user ON universes(user_id);
+CREATE INDEX idx_universe_members_symbol ON universe_members(symbol, exchange, trade_date);
+
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS universe_id INTEGER REFERENCES universes(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE universes IS '股票池定义表（指数成分、选股结果、手工列表）';
+COMMENT ON TABLE universe_members IS '股票池每日成分快照，回测按时点成分选股以避免幸存者偏差';
+
+-- ============================================
+-- 18. 风险警示（ST/*ST）表
+-- ============================================
+CREATE TABLE IF NOT EXISTS stock_risk_warnings (
+    id SERIAL PRIMARY KEY,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    warning VARCHAR(10) NOT NULL,             -- ST / *ST
+    start_date DATE NOT NULL,                 -- 实施日期
+    end_date DATE,                            -- 撤销或变更日期，为空表示仍在警示中
+    reason VARCHAR(200),                      -- 实施原因
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_risk_warning_symbol ON stock_risk_warnings(symbol, exchange);
+CREATE INDEX idx_risk_warning_dates ON stock_risk_warnings(start_date, end_date);
+
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS risk_warning VARCHAR(10) DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_stocks_risk_warning ON stocks(risk_warning);
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS exclude_st BOOLEAN DEFAULT FALSE;
+
+COMMENT ON TABLE stock_risk_warnings IS '股票风险警示历史，按生效区间记录 ST/*ST 的实施与撤销';
+
+-- ============================================
+-- 19. 交易信号聚合规则表
+-- ============================================
+CREATE TABLE IF NOT EXISTS signal_policies (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    conflict_policy VARCHAR(20) NOT NULL DEFAULT 'none',  -- none / net / priority
+    dedup_hours INTEGER DEFAULT 0,                         -- 同方向重复信号的抑制窗口（小时），0 表示不去重
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+ALTER TABLE trade_signals ADD COLUMN IF NOT EXISTS status VARCHAR(20) DEFAULT 'active';
+ALTER TABLE trade_signals ADD COLUMN IF NOT EXISTS note VARCHAR(200);
+CREATE INDEX IF NOT EXISTS idx_trade_signals_status ON trade_signals(status);
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS priority INTEGER DEFAULT 0;
+
+COMMENT ON TABLE signal_policies IS '用户交易信号聚合规则，信号保存前按规则去重并处理多策略冲突';
+
+-- ============================================
+-- 20. 自定义指标表
+-- ============================================
+CREATE TABLE IF NOT EXISTS custom_indicators (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,                -- 策略参数 custom_indicators 中按名称引用
+    expression VARCHAR(500) NOT NULL,         -- 如 (CLOSE - MA(20)) / ATR(14)
+    description VARCHAR(200),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, name)
+);
+
+COMMENT ON TABLE custom_indicators IS '用户自定义指标，表达式基于 OHLCV 与内置指标函数';
+
+-- ============================================
+-- 21. 图表标注表
+-- ============================================
+CREATE TABLE IF NOT EXISTS chart_annotations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    period VARCHAR(10) NOT NULL,              -- K线周期 1m/5m/15m/30m/60m/1d
+    type VARCHAR(20) NOT NULL,                -- trend_line/horizontal/text
+    points JSONB NOT NULL DEFAULT '[]',       -- 锚点 [{"time":..., "price":...}]
+    text VARCHAR(500),
+    style JSONB DEFAULT '{}',                 -- 前端样式（颜色、线宽等）
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotation_user_symbol ON chart_annotations(user_id, symbol, exchange, period);
+
+COMMENT ON TABLE chart_annotations IS '用户K线图标注（趋势线、水平线、文字），按股票与周期保存';
+
+-- ============================================
+-- 22. 订阅套餐
+-- ============================================
+ALTER TABLE users ADD COLUMN IF NOT EXISTS plan VARCHAR(20) DEFAULT 'free';   -- free / pro，配额见 pkg/quota
+ALTER TABLE users ADD COLUMN IF NOT EXISTS plan_expires_at TIMESTAMP;         -- 付费套餐到期时间，为空表示长期有效
+
+-- ============================================
+-- 23. 用户每日用量表
+-- ============================================
+CREATE TABLE IF NOT EXISTS usage_daily (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,                 -- 不设外键，注销用户的用量仍保留用于计费
+    date DATE NOT NULL,
+    api_calls BIGINT NOT NULL DEFAULT 0,      -- 经网关的已认证请求数
+    bytes_downloaded BIGINT NOT NULL DEFAULT 0, -- 网关返回的响应体字节数
+    backtest_seconds DOUBLE PRECISION NOT NULL DEFAULT 0, -- 回测任务执行时长（秒）
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_daily_date ON usage_daily(date);
+
+COMMENT ON TABLE usage_daily IS '用户每日用量，网关与回测服务按用户、自然日累加写入，用于用量报表与计费导出';
+
+-- ============================================
+-- 24. 管理员角色与数据运维审计日志
+-- ============================================
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(20) DEFAULT 'user';   -- user / admin，admin 可访问 /api/v1/admin
+
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,                 -- 操作人
+    username VARCHAR(50),
+    action VARCHAR(30) NOT NULL,              -- sync_trigger / bars_delete / bars_repair / bars_correct / bars_dedupe / indicators_recompute / quality_report
+    symbol VARCHAR(10),                       -- 为空表示全市场操作
+    exchange VARCHAR(10),
+    params JSONB,                             -- 请求参数
+    reason VARCHAR(200),
+    status VARCHAR(20) NOT NULL,              -- success / failed
+    result TEXT,                              -- 执行结果摘要或错误信息
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_user_id ON admin_audit_logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_action ON admin_audit_logs(action);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created_at ON admin_audit_logs(created_at);
+
+COMMENT ON TABLE admin_audit_logs IS '管理员数据运维操作审计日志（手动同步、删除/修复K线、重算指标等）';
+
+-- ============================================
+-- 25. 股票数据质量评分
+-- ============================================
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS quality_score INTEGER;         -- 0~100，每晚由数据同步服务计算，未评分时为空
+ALTER TABLE stocks ADD COLUMN IF NOT EXISTS quality_scored_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_stocks_quality_score ON stocks(quality_score);
+
+-- ============================================
+-- 26. 乐观锁版本号
+-- ============================================
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;  -- 每次更新加 1，过期版本的更新返回 409
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+-- ============================================
+-- 27. 策略定期回归回测
+-- ============================================
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS regression_enabled BOOLEAN DEFAULT FALSE;  -- 每晚按滚动窗口重新回测
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS regression_window INTEGER DEFAULT 365;      -- 滚动窗口（自然日）
+
+CREATE TABLE IF NOT EXISTS strategy_performance (
+    id SERIAL PRIMARY KEY,
+    strategy_id INTEGER NOT NULL REFERENCES strategies(id) ON DELETE CASCADE,
+    run_date DATE NOT NULL,
+    start_date DATE NOT NULL,
+    end_date DATE NOT NULL,
+    total_return DECIMAL(10, 4),
+    annual_return DECIMAL(10, 4),
+    max_drawdown DECIMAL(10, 4),
+    sharpe_ratio DECIMAL(10, 4),
+    win_rate DECIMAL(10, 4),
+    trade_count INTEGER,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_strategy_performance_run ON strategy_performance(strategy_id, run_date);
+
+-- ============================================
+-- 28. 实盘信号表现偏离检查
+-- ============================================
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS divergence_action VARCHAR(20) DEFAULT '';  -- 空（不检查）、notify、deactivate
+ALTER TABLE strategies ADD COLUMN IF NOT EXISTS divergence_ratio DOUBLE PRECISION DEFAULT 2;  -- 偏离阈值倍数
+
+CREATE TABLE IF NOT EXISTS strategy_divergences (
+    id SERIAL PRIMARY KEY,
+    strategy_id INTEGER NOT NULL REFERENCES strategies(id) ON DELETE CASCADE,
+    reasons TEXT,                             -- 偏离原因，多条以换行分隔
+    action VARCHAR(20),                       -- notify / deactivate
+    live_trades INTEGER,
+    live_return DECIMAL(10, 4),
+    live_drawdown DECIMAL(10, 4),
+    live_win_rate DECIMAL(10, 4),
+    expected_drawdown DECIMAL(10, 4),
+    expected_win_rate DECIMAL(10, 4),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_strategy_divergences_strategy ON strategy_divergences(strategy_id, created_at DESC);
+
+-- ============================================
+-- 29. 策略股票列表数组
+-- ============================================
+-- 旧版本手工拼接数组字面量，元素可能残留引号、空格或小写交易所后缀，统一规范化
+UPDATE strategies SET symbols = ARRAY(
+    SELECT upper(btrim(s, ' "')) FROM unnest(symbols) AS s WHERE btrim(s, ' "') <> ''
+) WHERE symbols IS NOT NULL;
+UPDATE strategies SET symbols = '{}' WHERE symbols IS NULL;
+UPDATE universes SET symbols = ARRAY(
+    SELECT upper(btrim(s, ' "')) FROM unnest(symbols) AS s WHERE btrim(s, ' "') <> ''
+) WHERE symbols IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_strategies_symbols ON strategies USING GIN(symbols);  -- 按股票查询策略
+
+-- ============================================
+-- 30. 异步任务
+-- ============================================
+CREATE TABLE IF NOT EXISTS tasks (
+    id VARCHAR(36) PRIMARY KEY,               -- 回测任务与 job_id 相同，其余为 UUID
+    type VARCHAR(30) NOT NULL,                -- sync / backtest / admin
+    name VARCHAR(50),
+    service VARCHAR(30) NOT NULL,
+    owner_id INTEGER NOT NULL DEFAULT 0,      -- 0 表示系统任务
+    status VARCHAR(20) NOT NULL,              -- running / succeeded / failed / canceled
+    progress DOUBLE PRECISION NOT NULL DEFAULT 0,
+    message TEXT,
+    result_type VARCHAR(30),                  -- 结果所在的资源类型，如 backtest、sync_job、audit_log
+    result_id VARCHAR(64),
+    error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_owner ON tasks(owner_id, type);
+CREATE INDEX IF NOT EXISTS idx_tasks_created ON tasks(created_at DESC);
+
+-- ============================================
+-- 31. 增量同步（updated_since）
+-- ============================================
+-- 股票只在返回给客户端的字段变化时更新 updated_at，重复同步股票列表、每晚重算质量评分时间不会使全部股票变为“已更新”
+-- 新增对外返回的字段时需要同步加入比较
+CREATE OR REPLACE FUNCTION update_stocks_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF (NEW.symbol, NEW.exchange, NEW.name, NEW.industry, NEW.full_name, NEW.list_date, NEW.total_share,
+        NEW.float_share, NEW.status, NEW.risk_warning, NEW.quality_score)
+       IS DISTINCT FROM
+       (OLD.symbol, OLD.exchange, OLD.name, OLD.industry, OLD.full_name, OLD.list_date, OLD.total_share,
+        OLD.float_share, OLD.status, OLD.risk_warning, OLD.quality_score) THEN
+        NEW.updated_at = NOW();
+    ELSE
+        NEW.updated_at = OLD.updated_at;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS update_stocks_updated_at ON stocks;
+CREATE TRIGGER update_stocks_updated_at BEFORE UPDATE ON stocks
+    FOR EACH ROW EXECUTE FUNCTION update_stocks_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_stocks_updated_at ON stocks(updated_at);
+CREATE INDEX IF NOT EXISTS idx_strategies_user_updated ON strategies(user_id, updated_at);
+CREATE INDEX IF NOT EXISTS idx_strategies_public_updated ON strategies(updated_at) WHERE is_public;
+
+-- 自选股分组：分组信息、明细或标签变化时更新 updated_at
+ALTER TABLE watchlists ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
+CREATE INDEX IF NOT EXISTS idx_watchlists_user_updated ON watchlists(user_id, updated_at);
+
+DROP TRIGGER IF EXISTS update_watchlists_updated_at ON watchlists;
+CREATE TRIGGER update_watchlists_updated_at BEFORE UPDATE ON watchlists
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE OR REPLACE FUNCTION touch_watchlist()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE watchlists SET updated_at = NOW() WHERE id = OLD.watchlist_id;
+        RETURN OLD;
+    END IF;
+    UPDATE watchlists SET updated_at = NOW() WHERE id = NEW.watchlist_id;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS touch_watchlist_items ON watchlist_items;
+CREATE TRIGGER touch_watchlist_items AFTER INSERT OR UPDATE OR DELETE ON watchlist_items
+    FOR EACH ROW EXECUTE FUNCTION touch_watchlist();
+
+DROP TRIGGER IF EXISTS touch_watchlist_tags ON watchlist_tags;
+CREATE TRIGGER touch_watchlist_tags AFTER INSERT OR DELETE ON watchlist_tags
+    FOR EACH ROW EXECUTE FUNCTION touch_watchlist();
+
+-- 策略：标签变化时更新 updated_at（不修改乐观锁版本号）
+CREATE OR REPLACE FUNCTION touch_strategy()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        UPDATE strategies SET updated_at = NOW() WHERE id = OLD.strategy_id;
+        RETURN OLD;
+    END IF;
+    UPDATE strategies SET updated_at = NOW() WHERE id = NEW.strategy_id;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS touch_strategy_tags ON strategy_tags;
+CREATE TRIGGER touch_strategy_tags AFTER INSERT OR DELETE ON strategy_tags
+    FOR EACH ROW EXECUTE FUNCTION touch_strategy();
+
+-- 标签改名时更新引用它的策略与分组
+CREATE OR REPLACE FUNCTION touch_tagged()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF NEW.name IS DISTINCT FROM OLD.name THEN
+        UPDATE strategies SET updated_at = NOW() WHERE id IN (SELECT strategy_id FROM strategy_tags WHERE tag_id = NEW.id);
+        UPDATE watchlists SET updated_at = NOW() WHERE id IN (SELECT watchlist_id FROM watchlist_tags WHERE tag_id = NEW.id);
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS touch_tagged_on_rename ON tags;
+CREATE TRIGGER touch_tagged_on_rename AFTER UPDATE ON tags
+    FOR EACH ROW EXECUTE FUNCTION touch_tagged();
+
+-- 墓碑记录：策略删除或公开策略改为私有时写入，客户端增量同步时据此移除本地数据
+CREATE TABLE IF NOT EXISTS sync_tombstones (
+    id BIGSERIAL PRIMARY KEY,
+    entity VARCHAR(20) NOT NULL,              -- strategy
+    entity_id INTEGER NOT NULL,
+    owner_id INTEGER NOT NULL,
+    public BOOLEAN NOT NULL DEFAULT false,    -- 事件发生前其他用户是否可见
+    reason VARCHAR(10) NOT NULL,              -- deleted / unshared
+    deleted_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tombstones_entity ON sync_tombstones(entity, owner_id, deleted_at);
+CREATE INDEX IF NOT EXISTS idx_tombstones_public ON sync_tombstones(entity, deleted_at) WHERE public;
+
+CREATE OR REPLACE FUNCTION record_strategy_tombstone()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO sync_tombstones (entity, entity_id, owner_id, public, reason)
+        VALUES ('strategy', OLD.id, OLD.user_id, OLD.is_public, 'deleted');
+        RETURN OLD;
+    END IF;
+    IF OLD.is_public AND NOT NEW.is_public THEN
+        INSERT INTO sync_tombstones (entity, entity_id, owner_id, public, reason)
+        VALUES ('strategy', OLD.id, OLD.user_id, true, 'unshared');
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS record_strategy_tombstone ON strategies;
+CREATE TRIGGER record_strategy_tombstone AFTER UPDATE OR DELETE ON strategies
+    FOR EACH ROW EXECUTE FUNCTION record_strategy_tombstone();
+
+COMMENT ON TABLE sync_tombstones IS '增量同步墓碑记录表';
+
+-- ============================================
+-- 32. 股票篮子（虚拟指数）
+-- ============================================
+CREATE TABLE IF NOT EXISTS baskets (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    legs JSONB NOT NULL DEFAULT '[]',         -- [{"symbol","exchange","weight"}]，权重合计为 1
+    base_date DATE NOT NULL,                  -- 基日收盘为 1000 点
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_baskets_user ON baskets(user_id);
+
+DROP TRIGGER IF EXISTS update_baskets_updated_at ON baskets;
+CREATE TRIGGER update_baskets_updated_at BEFORE UPDATE ON baskets
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- 每日收盘后由数据同步服务计算，成分或基日修改时清空重算
+CREATE TABLE IF NOT EXISTS basket_values (
+    basket_id INTEGER NOT NULL REFERENCES baskets(id) ON DELETE CASCADE,
+    trade_date DATE NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    change_pct DOUBLE PRECISION,
+    coverage DOUBLE PRECISION,                -- 当日已上市成分股的权重之和
+    PRIMARY KEY (basket_id, trade_date)
+);
+
+COMMENT ON TABLE baskets IS '用户自定义股票篮子表';
+COMMENT ON TABLE basket_values IS '股票篮子每日点位表';
+
+-- ============================================
+-- 33. 同步任务结果明细
+-- ============================================
+-- 逐只股票处理的任务（全市场K线、增量更新、全市场财报）的结果：成功/失败的股票、失败原因、写入条数、耗时
+ALTER TABLE data_sync_jobs ADD COLUMN IF NOT EXISTS report JSONB;
+
+-- ============================================
+-- 34. 用户提醒规则
+-- ============================================
+-- 条件 above/below 为静态阈值，cross_above/cross_below、new_high/new_low 为由指标序列得出的事件；
+-- 数据同步服务每次增量更新日K线后只检查 last_bar_at 之后的新K线
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    condition VARCHAR(20) NOT NULL,           -- above/below/cross_above/cross_below/new_high/new_low/before_event
+    left_expr VARCHAR(500) NOT NULL,          -- 指标表达式，如 EMA(12) - EMA(26)，接口字段为 left
+    right_expr VARCHAR(500),                  -- 比较对象（表达式或常量），新高/新低为空，接口字段为 right
+    window_size INTEGER NOT NULL DEFAULT 0,   -- 新高/新低的回看K线数，接口字段为 window
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_bar_at TIMESTAMP,                    -- 已检查到的最后一根日K线，为空时只检查最新一根
+    last_triggered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_rules_user_id ON alert_rules(user_id);
+CREATE INDEX IF NOT EXISTS idx_alert_rule_symbol ON alert_rules(symbol, exchange);
+
+CREATE TABLE IF NOT EXISTS alert_events (
+    id SERIAL PRIMARY KEY,
+    rule_id INTEGER NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    condition VARCHAR(20) NOT NULL,
+    trade_date DATE NOT NULL,                 -- 触发的日K线日期
+    value DOUBLE PRECISION,                   -- 触发时左侧的值
+    reference DOUBLE PRECISION,               -- 右侧的值，或新高/新低前的最高/最低值
+    message VARCHAR(500),
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(rule_id, trade_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_events_user_id ON alert_events(user_id, trade_date DESC);
+
+COMMENT ON TABLE alert_rules IS '用户提醒规则表';
+COMMENT ON TABLE alert_events IS '提醒规则触发记录表';
+
+-- ============================================
+-- 35. 公司事件日历
+-- ============================================
+-- 预约披露日、股东大会、限售股解禁等即将发生的事件，数据同步服务每天同步未来 90 天；
+-- 提醒规则 before_event 据此在事件前 window 天触发
+CREATE TABLE IF NOT EXISTS corporate_events (
+    id SERIAL PRIMARY KEY,
+    symbol VARCHAR(10) NOT NULL,
+    exchange VARCHAR(10) NOT NULL,
+    name VARCHAR(100),
+    event_type VARCHAR(30) NOT NULL,          -- earnings/shareholder_meeting/lockup_expiry
+    event_date DATE NOT NULL,
+    title VARCHAR(200),
+    detail VARCHAR(500),                      -- 补充说明，如解禁股数与占总股本比例
+    source VARCHAR(50),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_corporate_event_unique ON corporate_events(symbol, exchange, event_type, event_date);
+CREATE INDEX IF NOT EXISTS idx_corporate_events_event_date ON corporate_events(event_date);
+
+COMMENT ON TABLE corporate_events IS '公司事件日历表';
+
+-- ============================================
+-- 36. 同步任务依赖链
+-- ============================================
+-- 依赖于其他任务的下游任务（如日K线同步后回补技术指标的 indicators 任务）记录上游任务
+ALTER TABLE data_sync_jobs ADD COLUMN IF NOT EXISTS parent_id INTEGER REFERENCES data_sync_jobs(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_parent_id ON data_sync_jobs(parent_id);
+
+-- ============================================
+-- 37. 回测结果过期标记
+-- ============================================
+-- 回测使用的日K线被修正（人工修正、从数据源修复）后标记为过期，需重新回测
+ALTER TABLE backtest_records ADD COLUMN IF NOT EXISTS stale_at TIMESTAMP;
+ALTER TABLE backtest_records ADD COLUMN IF NOT EXISTS stale_reason VARCHAR(200);
+
+-- ============================================
+-- 38. 定时任务隔离令牌
+-- ============================================
+-- 多实例部署时定时任务主节点的隔离令牌，已有更新令牌的写入时拒绝旧主节点的迟到写入
+ALTER TABLE data_sync_jobs ADD COLUMN IF NOT EXISTS fence BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE strategy_performance ADD COLUMN IF NOT EXISTS fence BIGINT NOT NULL DEFAULT 0;
+
+-- ============================================
+-- 39. 排队中的下游任务
+-- ============================================
+-- 指标回补、因子重算等下游任务登记时即写入 status = 'queued' 的记录（parent_id 指向上游任务），
+-- 服务重启后重新排队；range_start/range_end 为需要重算的区间，排队期间再次登记时取并集
+ALTER TABLE data_sync_jobs ADD COLUMN IF NOT EXISTS range_start TIMESTAMP;
+ALTER TABLE data_sync_jobs ADD COLUMN IF NOT EXISTS range_end TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_sync_jobs_queued ON data_sync_jobs(id) WHERE status = 'queued';
+
 -- ============================================
 -- 完成初始化
 -- ============================================